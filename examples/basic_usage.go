@@ -3,9 +3,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"untitled/internal/translator/deeplx"
 )
@@ -15,10 +18,10 @@ func printSeparator() {
 	fmt.Println(strings.Repeat("=", 70))
 }
 
-// printResult 打印翻译结果，参数: 名称与结果，返回: 无
-func printResult(name string, result *deeplx.TranslationResult) {
+// printResult 打印翻译结果，参数: 名称、结果与错误，返回: 无
+func printResult(name string, result *deeplx.TranslationResult, err error) {
 	fmt.Printf("【%s】\n", name)
-	if result.Success {
+	if err == nil {
 		fmt.Printf("  ✅ 成功！\n")
 		fmt.Printf("  译文: %s\n", result.TranslatedText)
 		fmt.Printf("  源语言: %s → 目标语言: %s\n", result.SourceLang, result.TargetLang)
@@ -27,7 +30,10 @@ func printResult(name string, result *deeplx.TranslationResult) {
 		}
 	} else {
 		fmt.Printf("  ❌ 失败！\n")
-		fmt.Printf("  错误: %s\n", result.ErrorMessage)
+		fmt.Printf("  错误: %v\n", err)
+		if errors.Is(err, deeplx.ErrUnauthorized) {
+			fmt.Printf("  提示: 请检查 API 密钥是否有效\n")
+		}
 	}
 	fmt.Println(strings.Repeat("-", 70))
 }
@@ -56,42 +62,46 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 请求超时或取消时翻译立即中止
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
 	printSeparator()
 	fmt.Println("🐱 DeepLX API 使用示例 (浮浮酱为您演示喵～)")
 	printSeparator()
 	fmt.Println()
 
 	// 示例 1: 基本翻译（指定源语言）
-	result1 := translator.Translate("Hello, world!", "ZH", "EN")
-	printResult("示例 1: 英译中", result1)
+	result1, err := translator.Translate(ctx, deeplx.Request{Text: "Hello, world!", Source: "EN", Target: "ZH"})
+	printResult("示例 1: 英译中", result1, err)
 	fmt.Println()
 
 	//// 示例 2: 自动检测源语言
-	//result2 := translator.Translate("你好，世界！", "EN")
-	//printResult("示例 2: 自动检测源语言（中译英）", result2)
+	//result2, err := translator.Translate(ctx, deeplx.Request{Text: "你好，世界！", Target: "EN"})
+	//printResult("示例 2: 自动检测源语言（中译英）", result2, err)
 	//fmt.Println()
 	//
 	//// 示例 3: 长文本翻译
 	//longText := "Machine learning is a subset of artificial intelligence that " +
 	//	"provides systems the ability to automatically learn and improve " +
 	//	"from experience without being explicitly programmed."
-	//result3 := translator.Translate(longText, "ZH", "EN")
-	//printResult("示例 3: 长文本翻译", result3)
+	//result3, err := translator.Translate(ctx, deeplx.Request{Text: longText, Source: "EN", Target: "ZH"})
+	//printResult("示例 3: 长文本翻译", result3, err)
 	//fmt.Println()
 	//
 	//// 示例 4: 多语言翻译（法译日）
-	//result4 := translator.Translate("Bonjour, comment allez-vous?", "JA", "FR")
-	//printResult("示例 4: 多语言翻译（法译日）", result4)
+	//result4, err := translator.Translate(ctx, deeplx.Request{Text: "Bonjour, comment allez-vous?", Source: "FR", Target: "JA"})
+	//printResult("示例 4: 多语言翻译（法译日）", result4, err)
 	//fmt.Println()
 	//
 	//// 示例 5: 使用指定模型翻译（如果支持）
-	//result5 := translator.TranslateWithModel(
-	//	"Artificial Intelligence is transforming our world.",
-	//	"ZH",
-	//	"gpt-4", // 模型名称
-	//	"EN",
-	//)
-	//printResult("示例 5: 使用指定模型翻译", result5)
+	//result5, err := translator.Translate(ctx, deeplx.Request{
+	//	Text:   "Artificial Intelligence is transforming our world.",
+	//	Source: "EN",
+	//	Target: "ZH",
+	//	Model:  "gpt-4", // 模型名称
+	//})
+	//printResult("示例 5: 使用指定模型翻译", result5, err)
 	//fmt.Println()
 
 	printSeparator()
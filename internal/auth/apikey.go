@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateKey 密钥名称或密钥值已存在
+var ErrDuplicateKey = errors.New("密钥名称或密钥值已存在")
+
+// 密钥来源
+const (
+	KeySourceConfig  = "config"  // 来自配置文件或环境变量
+	KeySourceRuntime = "runtime" // 通过管理接口创建
+	KeySourceJWT     = "jwt"     // 由 JWT 认证得到的用户，Name 为令牌中的用户标识
+	KeySourceCert    = "cert"    // 由客户端证书认证得到的客户端，Name 为证书 CN
+)
+
+// APIKey 下游客户端密钥及其限制
+type APIKey struct {
+	Name       string    // 密钥名称，作为客户端标识
+	Key        string    // 密钥值
+	RPS        float64   // 每秒请求数，0 表示只受全局限流约束
+	Burst      int       // 突发容量
+	DailyChars int64     // 每日字符额度，0 表示不限
+	Endpoints  []string  // 允许访问的路由，为空表示全部
+	Source     string    // 密钥来源
+	CreatedAt  time.Time // 创建时间
+}
+
+// Identity 返回用于额度、偏好与日志的客户端标识，参数: 无，返回: 标识 (key:<名称>、user:<用户> 或 cert:<CN>)
+func (k APIKey) Identity() string {
+	switch k.Source {
+	case KeySourceJWT:
+		return "user:" + k.Name
+	case KeySourceCert:
+		return "cert:" + k.Name
+	default:
+		return "key:" + k.Name
+	}
+}
+
+// AllowsEndpoint 判断密钥是否允许访问指定路由，参数: 路由路径，返回: 布尔
+func (k APIKey) AllowsEndpoint(path string) bool {
+	if len(k.Endpoints) == 0 {
+		return true
+	}
+	for _, endpoint := range k.Endpoints {
+		if endpoint == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Hint 返回用于展示的密钥前缀，参数: 无，返回: 脱敏后的密钥
+func (k APIKey) Hint() string {
+	if len(k.Key) <= 8 {
+		return "****"
+	}
+	return k.Key[:4] + "****"
+}
+
+// KeyStore 客户端密钥集合，查找使用常量时间比较，并发安全
+type KeyStore struct {
+	mu   sync.RWMutex
+	keys []APIKey
+	now  func() time.Time
+}
+
+// NewKeyStore 创建密钥集合，参数: 初始密钥列表，返回: KeyStore 指针
+func NewKeyStore(keys []APIKey) *KeyStore {
+	return &KeyStore{keys: append([]APIKey(nil), keys...), now: time.Now}
+}
+
+// Lookup 按密钥值查找客户端，参数: 客户端提交的密钥，返回: 匹配的密钥与是否找到
+// 会与全部密钥逐一比较，耗时不泄露匹配位置
+func (s *KeyStore) Lookup(secret string) (APIKey, bool) {
+	if secret == "" {
+		return APIKey{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found APIKey
+	ok := false
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(k.Key)) == 1 {
+			found, ok = k, true
+		}
+	}
+	return found, ok
+}
+
+// Add 添加密钥，密钥值为空时自动生成，参数: 密钥，返回: 实际保存的密钥与错误
+func (s *KeyStore) Add(key APIKey) (APIKey, error) {
+	if key.Key == "" {
+		secret, err := randomToken(24)
+		if err != nil {
+			return APIKey{}, err
+		}
+		key.Key = secret
+	}
+	if key.Source == "" {
+		key.Source = KeySourceRuntime
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if k.Name == key.Name || subtle.ConstantTimeCompare([]byte(k.Key), []byte(key.Key)) == 1 {
+			return APIKey{}, ErrDuplicateKey
+		}
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = s.now()
+	}
+	s.keys = append(s.keys, key)
+	return key, nil
+}
+
+// Revoke 按名称吊销密钥，参数: 密钥名称，返回: 是否存在并已吊销
+func (s *KeyStore) Revoke(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, k := range s.keys {
+		if k.Name == name {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// List 返回按名称排序的全部密钥，参数: 无，返回: 密钥副本
+func (s *KeyStore) List() []APIKey {
+	s.mu.RLock()
+	keys := append([]APIKey(nil), s.keys...)
+	s.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// Len 返回密钥数量，参数: 无，返回: 数量
+func (s *KeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
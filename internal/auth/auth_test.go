@@ -0,0 +1,401 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testTOTPSecret RFC 6238 测试向量使用的密钥 ("12345678901234567890" 的 Base32)
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestTOTPVerify 测试 TOTP 校验与防重放，参数: 测试实例，返回: 无
+func TestTOTPVerify(t *testing.T) {
+	v, err := NewTOTPVerifier(testTOTPSecret)
+	if err != nil {
+		t.Fatalf("NewTOTPVerifier() error = %v", err)
+	}
+	// RFC 6238 附录 B: T=59s 时 SHA1 的 8 位验证码为 94287082，6 位即 287082
+	v.now = func() time.Time { return time.Unix(59, 0) }
+
+	if v.Verify("000000") {
+		t.Error("错误的验证码不应通过")
+	}
+	if !v.Verify("287082") {
+		t.Fatal("RFC 6238 测试向量应通过")
+	}
+	if v.Verify("287082") {
+		t.Error("同一验证码不可重放")
+	}
+}
+
+// TestNewTOTPVerifierInvalid 测试非法密钥，参数: 测试实例，返回: 无
+func TestNewTOTPVerifierInvalid(t *testing.T) {
+	for _, secret := range []string{"not-base32!", "GEZDGNBV"} {
+		if _, err := NewTOTPVerifier(secret); err == nil {
+			t.Errorf("NewTOTPVerifier(%q) 期望报错", secret)
+		}
+	}
+}
+
+// TestSessionStore 测试会话创建、过期与注销，参数: 测试实例，返回: 无
+func TestSessionStore(t *testing.T) {
+	store := NewSessionStore(time.Hour)
+	now := time.Unix(1000, 0)
+	store.now = func() time.Time { return now }
+
+	session, err := store.Create("admin")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got, ok := store.Get(session.Token); !ok || got.Subject != "admin" {
+		t.Fatalf("Get() = %+v, %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := store.Get(session.Token); ok {
+		t.Error("过期会话不应有效")
+	}
+
+	now = time.Unix(1000, 0)
+	session, _ = store.Create("admin")
+	store.Revoke(session.Token)
+	if _, ok := store.Get(session.Token); ok {
+		t.Error("注销后会话不应有效")
+	}
+}
+
+// TestLockout 测试连续失败锁定，参数: 测试实例，返回: 无
+func TestLockout(t *testing.T) {
+	l := NewLockout(3, time.Minute)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		l.Fail("1.2.3.4")
+	}
+	if locked, _ := l.Locked("1.2.3.4"); locked {
+		t.Fatal("未达阈值不应锁定")
+	}
+	l.Fail("1.2.3.4")
+	if locked, _ := l.Locked("1.2.3.4"); !locked {
+		t.Fatal("达到阈值应锁定")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if locked, _ := l.Locked("1.2.3.4"); locked {
+		t.Error("冷却期后应解除锁定")
+	}
+}
+
+// TestLockoutDecay 测试失败计数在冷却时间后衰减，过期记录被回收，参数: 测试实例，返回: 无
+func TestLockoutDecay(t *testing.T) {
+	l := NewLockout(3, time.Minute)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	l.Fail("1.2.3.4")
+	l.Fail("1.2.3.4")
+	now = now.Add(2 * time.Minute)
+	if l.Fail("1.2.3.4") {
+		t.Fatal("窗口外的失败不应累计到锁定")
+	}
+	if locked, _ := l.Locked("1.2.3.4"); locked {
+		t.Fatal("失败计数应已衰减")
+	}
+
+	for i := range lockoutCleanupEvery * 2 {
+		l.Fail(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+	now = now.Add(2 * time.Minute)
+	for i := range lockoutCleanupEvery {
+		l.Fail(fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+	}
+	if size := l.Size(); size > lockoutCleanupEvery+1 {
+		t.Errorf("Size() = %d, 过期记录未回收", size)
+	}
+}
+
+// TestKeyStoreLookup 测试客户端密钥查找，参数: 测试实例，返回: 无
+func TestKeyStoreLookup(t *testing.T) {
+	store := NewKeyStore([]APIKey{
+		{Name: "ide", Key: "k-ide"},
+		{Name: "bot", Key: "k-bot"},
+	})
+
+	if key, ok := store.Lookup("k-bot"); !ok || key.Name != "bot" {
+		t.Fatalf("Lookup(k-bot) = %+v, %v", key, ok)
+	}
+	for _, secret := range []string{"", "k-", "k-bot2"} {
+		if _, ok := store.Lookup(secret); ok {
+			t.Errorf("Lookup(%q) 不应匹配", secret)
+		}
+	}
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d", store.Len())
+	}
+}
+
+// TestKeyStoreAddRevoke 测试运行时创建与吊销密钥，参数: 测试实例，返回: 无
+func TestKeyStoreAddRevoke(t *testing.T) {
+	store := NewKeyStore([]APIKey{{Name: "ide", Key: "k-ide", Source: KeySourceConfig}})
+
+	created, err := store.Add(APIKey{Name: "bot"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if created.Key == "" || created.Source != KeySourceRuntime || created.CreatedAt.IsZero() {
+		t.Fatalf("Add() 未补全字段: %+v", created)
+	}
+	if _, ok := store.Lookup(created.Key); !ok {
+		t.Fatal("新建密钥应可查找")
+	}
+
+	if _, err := store.Add(APIKey{Name: "ide", Key: "other"}); err != ErrDuplicateKey {
+		t.Errorf("名称重复 Add() error = %v", err)
+	}
+	if _, err := store.Add(APIKey{Name: "other", Key: "k-ide"}); err != ErrDuplicateKey {
+		t.Errorf("密钥重复 Add() error = %v", err)
+	}
+
+	if names := store.List(); len(names) != 2 || names[0].Name != "bot" {
+		t.Fatalf("List() = %+v", names)
+	}
+	if !store.Revoke("ide") || store.Revoke("ide") {
+		t.Fatal("Revoke() 结果不符合预期")
+	}
+	if _, ok := store.Lookup("k-ide"); ok {
+		t.Error("吊销后的密钥不应可用")
+	}
+}
+
+// TestAPIKeyAllowsEndpoint 测试密钥路由限制，参数: 测试实例，返回: 无
+func TestAPIKeyAllowsEndpoint(t *testing.T) {
+	open := APIKey{Name: "open"}
+	if !open.AllowsEndpoint("/translate_a/single") {
+		t.Error("未限制路由的密钥应允许全部接口")
+	}
+
+	limited := APIKey{Name: "doc", Endpoints: []string{"/translate_a/t"}}
+	if limited.AllowsEndpoint("/translate_a/single") || !limited.AllowsEndpoint("/translate_a/t") {
+		t.Error("路由限制未生效")
+	}
+}
+
+// TestAPIKeyIdentity 测试不同来源的客户端标识互不冲突，参数: 测试实例，返回: 无
+func TestAPIKeyIdentity(t *testing.T) {
+	tests := map[string]APIKey{
+		"key:ci":  {Name: "ci", Source: KeySourceConfig},
+		"user:ci": {Name: "ci", Source: KeySourceJWT},
+		"cert:ci": {Name: "ci", Source: KeySourceCert},
+	}
+	for want, key := range tests {
+		if got := key.Identity(); got != want {
+			t.Errorf("Identity() = %q, want %q", got, want)
+		}
+	}
+}
+
+// TestJWTVerifierHS256 测试 HS256 令牌的签名、过期与声明校验，参数: 测试实例，返回: 无
+func TestJWTVerifierHS256(t *testing.T) {
+	v, err := NewJWTVerifier(JWTConfig{Secret: "secret", Issuer: "idp"})
+	if err != nil {
+		t.Fatalf("创建校验器失败: %v", err)
+	}
+	sign := func(key string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		if err != nil {
+			t.Fatalf("签发令牌失败: %v", err)
+		}
+		return token
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	subject, err := v.Verify(context.Background(), sign("secret", jwt.MapClaims{"sub": "alice", "iss": "idp", "exp": exp}))
+	if err != nil || subject != "alice" {
+		t.Fatalf("有效令牌校验失败: subject=%q err=%v", subject, err)
+	}
+
+	invalid := map[string]string{
+		"签名错误":   sign("other", jwt.MapClaims{"sub": "alice", "iss": "idp", "exp": exp}),
+		"已过期":    sign("secret", jwt.MapClaims{"sub": "alice", "iss": "idp", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"缺少 exp": sign("secret", jwt.MapClaims{"sub": "alice", "iss": "idp"}),
+		"签发方错误":  sign("secret", jwt.MapClaims{"sub": "alice", "iss": "evil", "exp": exp}),
+		"缺少 sub": sign("secret", jwt.MapClaims{"iss": "idp", "exp": exp}),
+	}
+	for name, token := range invalid {
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s: 期望校验失败", name)
+		}
+	}
+
+	if _, err := NewJWTVerifier(JWTConfig{}); err == nil {
+		t.Error("未配置 secret 与 jwks_url 时应返回错误")
+	}
+}
+
+// TestJWTVerifierJWKS 测试通过 JWKS 校验 RS256 令牌，参数: 测试实例，返回: 无
+func TestJWTVerifierJWKS(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+
+	v, err := NewJWTVerifier(JWTConfig{JWKSURL: srv.URL, SubjectClaim: "email"})
+	if err != nil {
+		t.Fatalf("创建校验器失败: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"email": "bob@example.com", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	subject, err := v.Verify(context.Background(), signed)
+	if err != nil || subject != "bob@example.com" {
+		t.Fatalf("RS256 令牌校验失败: subject=%q err=%v", subject, err)
+	}
+
+	// 未配置 secret 时不接受 HS256，防止算法混淆
+	hs, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"email": "x", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("guess"))
+	if _, err := v.Verify(context.Background(), hs); err == nil {
+		t.Error("仅配置 JWKS 时应拒绝 HS256 令牌")
+	}
+}
+
+// TestJWKSCacheRefresh 测试刷新失败时继续使用旧公钥并退避重试，超过最长过期时间后拒绝，参数: 测试实例，返回: 无
+func TestJWKSCacheRefresh(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+	var hits atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+
+	j := newJWKSCache(srv.URL, nil, time.Hour)
+	now := time.Unix(1_700_000_000, 0)
+	j.now = func() time.Time { return now }
+	ctx := context.Background()
+	// idle 等待进行中的后台刷新结束
+	idle := func() { _, _, _ = j.group.Do(jwksFetchKey, func() (interface{}, error) { return nil, nil }) }
+	check := func(step string, wantErr bool, wantHits int32) {
+		t.Helper()
+		_, err := j.key(ctx, "k1")
+		idle()
+		if (err != nil) != wantErr {
+			t.Errorf("%s: err = %v, want error = %v", step, err, wantErr)
+		}
+		if got := hits.Load(); got != wantHits {
+			t.Errorf("%s: 拉取次数 = %d, want %d", step, got, wantHits)
+		}
+	}
+
+	check("首次拉取", false, 1)
+	check("缓存有效", false, 1)
+
+	failing.Store(true)
+	now = now.Add(2 * time.Hour)
+	check("刷新失败仍使用旧公钥", false, 2)
+	check("失败后退避", false, 2)
+	now = now.Add(2 * time.Minute)
+	check("退避结束后重试", false, 3)
+
+	now = now.Add(maxJWKSStale)
+	check("超过最长过期时间", true, 4)
+	check("过期且退避中", true, 4)
+
+	failing.Store(false)
+	now = now.Add(2 * time.Minute)
+	check("恢复后重新拉取", false, 5)
+}
+
+// TestJWKSCacheRefreshUnlocked 测试后台刷新进行中时已缓存的公钥不等待拉取完成，参数: 测试实例，返回: 无
+func TestJWKSCacheRefreshUnlocked(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+	var hits atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	j := newJWKSCache(srv.URL, nil, time.Hour)
+	var mu sync.Mutex
+	now := time.Unix(1_700_000_000, 0)
+	j.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	if _, err := j.key(context.Background(), "k1"); err != nil {
+		t.Fatalf("首次拉取失败: %v", err)
+	}
+	mu.Lock()
+	now = now.Add(2 * time.Hour)
+	mu.Unlock()
+
+	if _, err := j.key(context.Background(), "k1"); err != nil {
+		t.Fatalf("刷新期间应返回旧公钥: %v", err)
+	}
+	<-started
+	// 拉取阻塞时并发请求不应被锁住，也不应发起新的拉取
+	for range 5 {
+		if _, err := j.key(context.Background(), "k1"); err != nil {
+			t.Fatalf("刷新期间应返回旧公钥: %v", err)
+		}
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("拉取次数 = %d, want 2", got)
+	}
+}
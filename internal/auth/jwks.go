@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// JWKS 拉取参数
+const (
+	defaultJWKSRefresh  = time.Hour        // 定期刷新间隔
+	minJWKSRefetchDelay = time.Minute      // 遇到未知 kid 或拉取失败后两次拉取的最小间隔
+	jwksFetchTimeout    = 10 * time.Second // 单次拉取超时
+	maxJWKSStale        = 24 * time.Hour   // 刷新持续失败时，超过刷新间隔后继续使用旧公钥的最长时间
+	jwksFetchKey        = "jwks"           // singleflight 合并拉取使用的键
+)
+
+// jwksCache 缓存远端 JWKS 中的 RSA 公钥，按需刷新
+// 拉取在锁外进行并通过 singleflight 合并，刷新失败时在 maxStale 内继续使用旧公钥
+type jwksCache struct {
+	url      string
+	client   *http.Client
+	refresh  time.Duration
+	maxStale time.Duration
+	now      func() time.Time
+	group    singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	failedAt  time.Time
+	lastErr   error
+}
+
+// jsonWebKey JWKS 中的单个公钥 (仅解析 RSA 所需字段)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// newJWKSCache 创建 JWKS 缓存，参数: 地址、HTTP 客户端、刷新间隔，返回: 缓存指针
+func newJWKSCache(url string, client *http.Client, refresh time.Duration) *jwksCache {
+	if client == nil {
+		client = &http.Client{Timeout: jwksFetchTimeout}
+	}
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	return &jwksCache{url: url, client: client, refresh: refresh, maxStale: refresh + maxJWKSStale, now: time.Now}
+}
+
+// key 按 kid 获取公钥，缓存过期或出现未知 kid 时重新拉取，参数: 上下文与 kid，返回: 公钥或错误
+// 已缓存且未超过 maxStale 的公钥不等待刷新完成；拉取失败后至少间隔 minJWKSRefetchDelay 才重试
+func (j *jwksCache) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	now := j.now()
+	_, known := lookupKey(j.keys, kid)
+	usable := known && now.Sub(j.fetchedAt) <= j.maxStale
+	// 未知 kid 通常意味着签发方轮换了密钥，但需限制强制刷新频率，避免被伪造 kid 放大请求
+	due := now.Sub(j.fetchedAt) > j.refresh || (!known && now.Sub(j.fetchedAt) > minJWKSRefetchDelay)
+	backoff := now.Sub(j.failedAt) < minJWKSRefetchDelay
+	j.mu.Unlock()
+
+	if due && !backoff {
+		// 刷新与发起请求的上下文解耦，避免首个请求取消后所有等待者一同失败
+		ch := j.group.DoChan(jwksFetchKey, func() (interface{}, error) {
+			j.update(j.fetch(context.WithoutCancel(ctx)))
+			return nil, nil
+		})
+		if !usable {
+			select {
+			case <-ch:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return j.cached(kid)
+}
+
+// update 记录一次拉取结果，失败时保留旧公钥并记录失败时间用于退避，参数: 拉取结果与错误，返回: 无
+func (j *jwksCache) update(keys map[string]*rsa.PublicKey, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.failedAt = j.now()
+		j.lastErr = err
+		return
+	}
+	j.keys = keys
+	j.fetchedAt = j.now()
+	j.failedAt = time.Time{}
+	j.lastErr = nil
+}
+
+// cached 从缓存中查找公钥，超过 maxStale 的公钥不再使用，参数: kid，返回: 公钥或错误
+func (j *jwksCache) cached(kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.keys == nil || j.now().Sub(j.fetchedAt) > j.maxStale {
+		if j.lastErr != nil {
+			return nil, j.lastErr
+		}
+		return nil, fmt.Errorf("JWKS 公钥已超过 %s 未能刷新", j.maxStale)
+	}
+	if k, ok := lookupKey(j.keys, kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("JWKS 中不存在 kid=%q 的公钥", kid)
+}
+
+// lookupKey 按 kid 查找公钥，令牌未指定 kid 且只有一个公钥时直接使用，参数: 公钥映射与 kid，返回: 公钥与是否找到
+func lookupKey(keys map[string]*rsa.PublicKey, kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+// fetch 拉取并解析 JWKS，参数: 上下文，返回: kid 到公钥的映射或错误
+func (j *jwksCache) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建 JWKS 请求失败: %w", err)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("拉取 JWKS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("拉取 JWKS 失败: HTTP %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("解析 JWKS 失败: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		pub, err := parseRSAKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("解析 JWKS 公钥 %q 失败: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+// parseRSAKey 将 JWK 转换为 RSA 公钥，参数: JWK，返回: 公钥或错误
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("无效的公钥指数")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig JWT 校验参数
+type JWTConfig struct {
+	Secret       string        // HS256 共享密钥 (可选)
+	JWKSURL      string        // RS256 公钥集合地址 (可选)
+	Issuer       string        // 期望的 iss，为空不校验
+	Audience     string        // 期望的 aud，为空不校验
+	SubjectClaim string        // 作为用户标识的声明，默认 sub
+	JWKSRefresh  time.Duration // 公钥集合刷新间隔，默认 1 小时
+	HTTPClient   *http.Client  // 拉取 JWKS 使用的客户端 (可选)
+	Leeway       time.Duration // exp/nbf 允许的时钟偏差，默认 30 秒
+}
+
+// JWTVerifier 校验 Bearer JWT 并提取用户标识，令牌必须携带 exp，并发安全
+type JWTVerifier struct {
+	secret       []byte
+	jwks         *jwksCache
+	parser       *jwt.Parser
+	subjectClaim string
+}
+
+// NewJWTVerifier 创建 JWT 校验器，参数: 校验参数，返回: 校验器或错误
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	secret := strings.TrimSpace(cfg.Secret)
+	jwksURL := strings.TrimSpace(cfg.JWKSURL)
+	if secret == "" && jwksURL == "" {
+		return nil, errors.New("JWT 认证需要配置 secret 或 jwks_url")
+	}
+
+	var methods []string
+	v := &JWTVerifier{subjectClaim: cfg.SubjectClaim}
+	if v.subjectClaim == "" {
+		v.subjectClaim = "sub"
+	}
+	if secret != "" {
+		v.secret = []byte(secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if jwksURL != "" {
+		v.jwks = newJWKSCache(jwksURL, cfg.HTTPClient, cfg.JWKSRefresh)
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	leeway := cfg.Leeway
+	if leeway <= 0 {
+		leeway = 30 * time.Second
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify 校验令牌签名与声明，参数: 上下文与令牌，返回: 用户标识与错误
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (string, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			return v.secret, nil
+		case jwt.SigningMethodRS256.Alg():
+			kid, _ := t.Header["kid"].(string)
+			return v.jwks.key(ctx, kid)
+		default:
+			return nil, fmt.Errorf("不支持的签名算法: %s", t.Method.Alg())
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	subject, _ := claims[v.subjectClaim].(string)
+	if strings.TrimSpace(subject) == "" {
+		return "", fmt.Errorf("令牌缺少 %s 声明", v.subjectClaim)
+	}
+	return subject, nil
+}
+
+// LooksLikeJWT 粗略判断凭据是否为 JWT (三段以点分隔)，参数: 凭据，返回: 布尔
+func LooksLikeJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
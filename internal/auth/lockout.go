@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// lockoutCleanupEvery 每隔多少次失败回收一次过期记录
+const lockoutCleanupEvery = 64
+
+// Lockout 登录失败锁定器，某来源在冷却时间内连续失败达到阈值后在冷却期内拒绝尝试，并发安全
+// 失败计数在冷却时间后衰减，过期记录定期回收，来源数量不会无限增长
+type Lockout struct {
+	mu          sync.Mutex
+	failures    map[string]*lockoutEntry
+	maxFailures int
+	cooldown    time.Duration
+	now         func() time.Time
+	calls       int
+}
+
+type lockoutEntry struct {
+	count        int
+	firstFailure time.Time
+	lockedUntil  time.Time
+}
+
+// NewLockout 创建锁定器，参数: 最大失败次数与冷却时间 (同时是失败计数的统计窗口)，返回: Lockout 指针
+func NewLockout(maxFailures int, cooldown time.Duration) *Lockout {
+	return &Lockout{
+		failures:    make(map[string]*lockoutEntry),
+		maxFailures: max(maxFailures, 1),
+		cooldown:    cooldown,
+		now:         time.Now,
+	}
+}
+
+// Locked 判断来源是否处于锁定状态，参数: 来源标识，返回: 是否锁定与剩余时间
+func (l *Lockout) Locked(source string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.failures[source]
+	if !ok {
+		return false, 0
+	}
+	remaining := entry.lockedUntil.Sub(l.now())
+	if remaining <= 0 {
+		return false, 0
+	}
+	return true, remaining
+}
+
+// Fail 记录一次失败，参数: 来源标识，返回: 本次是否触发锁定
+// 距第一次失败超过冷却时间后重新计数
+func (l *Lockout) Fail(source string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.calls++
+	if l.calls%lockoutCleanupEvery == 0 {
+		l.cleanupLocked(now)
+	}
+
+	entry, ok := l.failures[source]
+	if !ok {
+		entry = &lockoutEntry{}
+		l.failures[source] = entry
+	}
+	if entry.count == 0 || now.Sub(entry.firstFailure) > l.cooldown {
+		entry.count = 0
+		entry.firstFailure = now
+	}
+	entry.count++
+	if entry.count < l.maxFailures {
+		return false
+	}
+	entry.lockedUntil = now.Add(l.cooldown)
+	entry.count = 0
+	return true
+}
+
+// Reset 清除来源的失败记录，参数: 来源标识，返回: 无
+func (l *Lockout) Reset(source string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, source)
+}
+
+// Size 返回当前跟踪的来源数量，参数: 无，返回: 数量
+func (l *Lockout) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.failures)
+}
+
+// cleanupLocked 回收锁定已过期且失败计数已衰减的记录 (调用方需持有锁)，参数: 当前时间，返回: 无
+func (l *Lockout) cleanupLocked(now time.Time) {
+	for source, entry := range l.failures {
+		if !now.Before(entry.lockedUntil) && now.Sub(entry.firstFailure) > l.cooldown {
+			delete(l.failures, source)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// Session 已登录的管理会话
+type Session struct {
+	Token     string    `json:"-"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionStore 进程内会话存储，并发安全
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewSessionStore 创建会话存储，参数: 会话有效期，返回: SessionStore 指针
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	}
+	return &SessionStore{
+		sessions: make(map[string]*Session),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Create 为主体创建新会话，参数: 主体标识，返回: 会话与错误
+func (s *SessionStore) Create(subject string) (*Session, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.pruneLocked(now)
+	session := &Session{
+		Token:     token,
+		Subject:   subject,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	s.sessions[token] = session
+	return session, nil
+}
+
+// Get 获取有效会话，参数: 会话令牌，返回: 会话副本与是否有效
+func (s *SessionStore) Get(token string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return Session{}, false
+	}
+	if s.now().After(session.ExpiresAt) {
+		delete(s.sessions, token)
+		return Session{}, false
+	}
+	return *session, true
+}
+
+// Revoke 注销会话，参数: 会话令牌，返回: 无
+func (s *SessionStore) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, token)
+}
+
+// TTL 返回会话有效期，参数: 无，返回: 时长
+func (s *SessionStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// pruneLocked 清理过期会话 (调用方需持有锁)，参数: 当前时间，返回: 无
+func (s *SessionStore) pruneLocked(now time.Time) {
+	for token, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// randomToken 生成 URL 安全的随机令牌，参数: 随机字节数，返回: 令牌与错误
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
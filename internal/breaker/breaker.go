@@ -0,0 +1,272 @@
+// Package breaker 为上游调用提供熔断：最近调用的失败率或慢调用率超过阈值时断开，
+// 断开期间直接拒绝调用，冷却后进入半开状态放行少量探测，探测全部成功才恢复
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed   State = iota // 正常放行并统计结果
+	StateOpen                  // 断开，直接拒绝调用
+	StateHalfOpen              // 半开，放行少量探测调用
+)
+
+// String 返回状态名称，参数: 无，返回: 名称 (closed/open/half_open)
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrOpen 熔断器断开 (或半开探测名额已满) 时拒绝调用
+var ErrOpen = errors.New("熔断器已断开")
+
+// OpenError 熔断器拒绝调用时返回的错误，携带建议的重试等待时间
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口，参数: 无，返回: 错误字符串
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, ErrOpen)
+}
+
+// Unwrap 返回底层错误，参数: 无，返回: ErrOpen
+func (e *OpenError) Unwrap() error {
+	return ErrOpen
+}
+
+// Settings 熔断阈值，零值字段使用默认值
+type Settings struct {
+	Window         int           // 统计最近多少次调用，默认 20
+	MinCalls       int           // 窗口内至少多少次调用才判断阈值，默认 10
+	ErrorRate      float64       // 失败率阈值 (0, 1]，默认 0.5
+	SlowCall       time.Duration // 慢调用阈值，<=0 不统计慢调用
+	SlowRate       float64       // 慢调用率阈值 (0, 1]，默认 0.5
+	OpenDuration   time.Duration // 断开后多久进入半开，默认 30 秒
+	HalfOpenProbes int           // 半开状态放行的探测次数，全部成功后恢复，默认 1
+}
+
+// withDefaults 填充未设置的字段，参数: 无，返回: 填充后的 Settings
+func (s Settings) withDefaults() Settings {
+	if s.Window <= 0 {
+		s.Window = 20
+	}
+	if s.MinCalls <= 0 {
+		s.MinCalls = 10
+	}
+	s.MinCalls = min(s.MinCalls, s.Window)
+	if s.ErrorRate <= 0 {
+		s.ErrorRate = 0.5
+	}
+	if s.SlowRate <= 0 {
+		s.SlowRate = 0.5
+	}
+	if s.OpenDuration <= 0 {
+		s.OpenDuration = 30 * time.Second
+	}
+	if s.HalfOpenProbes <= 0 {
+		s.HalfOpenProbes = 1
+	}
+	return s
+}
+
+// outcome 一次调用的统计结果
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+// Breaker 熔断器，并发安全
+type Breaker struct {
+	name     string
+	settings Settings
+	onChange func(name string, from, to State)
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64 // 每次状态变化递增，旧状态下发起的调用结果不再统计
+	openUntil  time.Time
+
+	// 闭合状态的滑动窗口
+	outcomes []outcome
+	next     int
+	count    int
+	failures int
+	slows    int
+
+	// 半开状态的探测计数
+	probes    int
+	successes int
+}
+
+// New 创建熔断器，参数: 名称、阈值与状态变化回调 (可为 nil，在持有锁时调用，不应阻塞)，返回: Breaker 指针
+func New(name string, settings Settings, onChange func(name string, from, to State)) *Breaker {
+	settings = settings.withDefaults()
+	return &Breaker{
+		name:     name,
+		settings: settings,
+		onChange: onChange,
+		now:      time.Now,
+		outcomes: make([]outcome, settings.Window),
+	}
+}
+
+// Name 返回熔断器名称，参数: 无，返回: 名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 返回当前状态 (断开且冷却结束时视为半开)，参数: 无，返回: 状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !b.now().Before(b.openUntil) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow 申请一次调用，参数: 无，返回: 调用结束后必须执行的回调 (传入调用的错误) 与错误
+// 断开期间或半开探测名额已满时返回 *OpenError；回调按耗时与错误统计结果，context.Canceled 不计入统计
+func (b *Breaker) Allow() (func(err error), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.state == StateOpen {
+		if now.Before(b.openUntil) {
+			return nil, &OpenError{Name: b.name, RetryAfter: b.openUntil.Sub(now)}
+		}
+		b.transitionLocked(StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		if b.probes >= b.settings.HalfOpenProbes {
+			return nil, &OpenError{Name: b.name, RetryAfter: time.Second}
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	return func(err error) {
+		b.record(generation, now, err)
+	}, nil
+}
+
+// Trip 立即断开熔断器 (如后台健康检查发现上游不可用)，已断开且仍在冷却期时不延长，参数: 无，返回: 无
+// 冷却结束后照常进入半开，由真实请求探测恢复
+func (b *Breaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Before(b.openUntil) {
+		return
+	}
+	b.tripLocked()
+}
+
+// record 统计一次调用的结果，参数: 发起时的状态代数、开始时间与调用错误，返回: 无
+func (b *Breaker) record(generation uint64, start time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+	ignored := errors.Is(err, context.Canceled)
+	result := outcome{
+		failed: err != nil,
+		slow:   b.settings.SlowCall > 0 && b.now().Sub(start) >= b.settings.SlowCall,
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		if ignored {
+			b.probes--
+			return
+		}
+		if result.failed || result.slow {
+			b.tripLocked()
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenProbes {
+			b.transitionLocked(StateClosed)
+		}
+	case StateClosed:
+		if ignored {
+			return
+		}
+		b.pushLocked(result)
+		if b.count < b.settings.MinCalls {
+			return
+		}
+		if rate(b.failures, b.count) >= b.settings.ErrorRate ||
+			(b.settings.SlowCall > 0 && rate(b.slows, b.count) >= b.settings.SlowRate) {
+			b.tripLocked()
+		}
+	}
+}
+
+// pushLocked 把结果写入滑动窗口并淘汰最旧的结果 (调用方需持有锁)，参数: 结果，返回: 无
+func (b *Breaker) pushLocked(result outcome) {
+	if b.count == len(b.outcomes) {
+		old := b.outcomes[b.next]
+		if old.failed {
+			b.failures--
+		}
+		if old.slow {
+			b.slows--
+		}
+	} else {
+		b.count++
+	}
+	b.outcomes[b.next] = result
+	b.next = (b.next + 1) % len(b.outcomes)
+	if result.failed {
+		b.failures++
+	}
+	if result.slow {
+		b.slows++
+	}
+}
+
+// tripLocked 断开熔断器 (调用方需持有锁)，参数: 无，返回: 无
+func (b *Breaker) tripLocked() {
+	b.openUntil = b.now().Add(b.settings.OpenDuration)
+	b.transitionLocked(StateOpen)
+}
+
+// transitionLocked 切换状态并重置统计 (调用方需持有锁)，参数: 目标状态，返回: 无
+func (b *Breaker) transitionLocked(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.next, b.count, b.failures, b.slows = 0, 0, 0, 0
+	b.probes, b.successes = 0, 0
+	if b.onChange != nil && from != to {
+		b.onChange(b.name, from, to)
+	}
+}
+
+// rate 计算比例，参数: 分子与分母，返回: 比例
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,185 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// newTestBreaker 创建使用假时钟的熔断器，参数: 阈值与状态变化记录，返回: 熔断器与时钟
+func newTestBreaker(settings Settings, changes *[]State) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := New("test", settings, func(_ string, _, to State) {
+		*changes = append(*changes, to)
+	})
+	b.now = clock.Now
+	return b, clock
+}
+
+// call 经熔断器执行一次调用，参数: 熔断器与调用结果，返回: Allow 的错误
+func call(b *Breaker, err error) error {
+	done, allowErr := b.Allow()
+	if allowErr != nil {
+		return allowErr
+	}
+	done(err)
+	return nil
+}
+
+// TestBreakerErrorRate 测试失败率触发断开、半开探测与恢复，参数: 测试实例，返回: 无
+func TestBreakerErrorRate(t *testing.T) {
+	var changes []State
+	b, clock := newTestBreaker(Settings{Window: 4, MinCalls: 4, ErrorRate: 0.5, OpenDuration: time.Minute}, &changes)
+	upstream := errors.New("upstream failed")
+
+	for _, err := range []error{nil, upstream, nil} {
+		if allowErr := call(b, err); allowErr != nil {
+			t.Fatalf("未达到最少调用数前不应断开: %v", allowErr)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("State = %v, 期望 closed", b.State())
+	}
+	// 第 4 次调用后失败率 2/4 达到阈值
+	if err := call(b, upstream); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("State = %v, 期望 open", b.State())
+	}
+
+	err := call(b, nil)
+	var openErr *OpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrOpen) {
+		t.Fatalf("断开期间 err = %v, 期望 *OpenError", err)
+	}
+	if openErr.RetryAfter != time.Minute {
+		t.Errorf("RetryAfter = %v, 期望 1m", openErr.RetryAfter)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("冷却结束后 State = %v, 期望 half_open", b.State())
+	}
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("半开状态应放行探测: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("探测名额已满时 err = %v, 期望 ErrOpen", err)
+	}
+	done(nil)
+	if b.State() != StateClosed {
+		t.Fatalf("探测成功后 State = %v, 期望 closed", b.State())
+	}
+
+	want := []State{StateOpen, StateHalfOpen, StateClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("状态变化 = %v, 期望 %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("状态变化 = %v, 期望 %v", changes, want)
+			break
+		}
+	}
+}
+
+// TestBreakerSlowCalls 测试慢调用触发断开与半开探测失败重新断开，参数: 测试实例，返回: 无
+func TestBreakerSlowCalls(t *testing.T) {
+	var changes []State
+	b, clock := newTestBreaker(Settings{Window: 2, MinCalls: 2, SlowCall: time.Second, SlowRate: 1, OpenDuration: time.Minute}, &changes)
+
+	for i := 0; i < 2; i++ {
+		done, err := b.Allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock.now = clock.now.Add(2 * time.Second)
+		done(nil)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("慢调用率达到阈值后 State = %v, 期望 open", b.State())
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if err := call(b, errors.New("still failing")); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != StateOpen {
+		t.Errorf("探测失败后 State = %v, 期望 open", b.State())
+	}
+}
+
+// TestBreakerIgnoresCanceled 测试客户端取消不计入统计，也不占用半开探测名额，参数: 测试实例，返回: 无
+func TestBreakerIgnoresCanceled(t *testing.T) {
+	var changes []State
+	b, clock := newTestBreaker(Settings{Window: 2, MinCalls: 2, OpenDuration: time.Minute}, &changes)
+
+	for i := 0; i < 4; i++ {
+		if err := call(b, context.Canceled); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("取消的调用不应触发断开, State = %v", b.State())
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call(b, errors.New("upstream failed")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if err := call(b, context.Canceled); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(b, nil); err != nil {
+		t.Fatalf("取消的探测应归还名额: %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("State = %v, 期望 closed", b.State())
+	}
+}
+
+// TestBreakerTrip 测试强制断开与冷却结束后经半开恢复，参数: 测试实例，返回: 无
+func TestBreakerTrip(t *testing.T) {
+	var changes []State
+	b, clock := newTestBreaker(Settings{Window: 10, MinCalls: 10, OpenDuration: time.Minute}, &changes)
+
+	b.Trip()
+	if b.State() != StateOpen {
+		t.Fatalf("Trip 后 State = %v, 期望 open", b.State())
+	}
+	var open *OpenError
+	if err := call(b, nil); !errors.As(err, &open) {
+		t.Fatalf("断开期间应拒绝调用, err = %v", err)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	b.Trip()
+	clock.now = clock.now.Add(30 * time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("冷却期内重复 Trip 不应延长断开, State = %v", b.State())
+	}
+	if err := call(b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("State = %v, 期望 closed", b.State())
+	}
+	if want := []State{StateOpen, StateHalfOpen, StateClosed}; !slices.Equal(changes, want) {
+		t.Errorf("状态变化 = %v, 期望 %v", changes, want)
+	}
+}
@@ -0,0 +1,98 @@
+// Package bulkhead 限制对上游的并发调用数，超出部分在有界队列中等待
+// 上游变慢时请求在此排队或快速失败，而不是无限堆积 goroutine 与内存
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// 获取并发槽位失败的原因
+var (
+	ErrQueueFull    = errors.New("并发已满且等待队列已满")
+	ErrQueueTimeout = errors.New("等待并发槽位超时")
+)
+
+// Bulkhead 并发隔离舱：最多 maxInFlight 个调用同时进行，最多 maxQueue 个调用排队等待，并发安全
+type Bulkhead struct {
+	name    string
+	slots   chan struct{}
+	queue   chan struct{}
+	timeout time.Duration
+	waiting atomic.Int64
+}
+
+// New 创建隔离舱，参数: 名称、最大并发数、最大排队数与排队超时 (<=0 表示只受请求上下文约束)，返回: Bulkhead 指针
+func New(name string, maxInFlight, maxQueue int, timeout time.Duration) *Bulkhead {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &Bulkhead{
+		name:    name,
+		slots:   make(chan struct{}, maxInFlight),
+		queue:   make(chan struct{}, maxQueue),
+		timeout: timeout,
+	}
+}
+
+// Name 返回隔离舱名称，参数: 无，返回: 名称
+func (b *Bulkhead) Name() string {
+	return b.name
+}
+
+// Acquire 获取一个并发槽位，必要时排队等待，参数: 上下文，返回: 释放函数与错误
+// 队列已满立即返回 ErrQueueFull；排队超时返回 ErrQueueTimeout；上下文结束返回 ctx.Err()
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	select {
+	case b.queue <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+	b.waiting.Add(1)
+	defer func() {
+		b.waiting.Add(-1)
+		<-b.queue
+	}()
+
+	var expired <-chan time.Time
+	if b.timeout > 0 {
+		timer := time.NewTimer(b.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	case <-expired:
+		return nil, ErrQueueTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release 归还并发槽位，参数: 无，返回: 无
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+// InFlight 返回正在进行的调用数，参数: 无，返回: 数量
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Queued 返回正在排队的调用数，参数: 无，返回: 数量
+func (b *Bulkhead) Queued() int {
+	return int(b.waiting.Load())
+}
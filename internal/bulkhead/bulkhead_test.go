@@ -0,0 +1,77 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestBulkheadQueue 测试并发上限、排队等待与队列已满，参数: 测试实例，返回: 无
+func TestBulkheadQueue(t *testing.T) {
+	b := New("test", 1, 1, time.Second)
+
+	release, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("首个调用应直接获得槽位: %v", err)
+	}
+	if b.InFlight() != 1 {
+		t.Errorf("InFlight = %d, 期望 1", b.InFlight())
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		r, err := b.Acquire(context.Background())
+		if err == nil {
+			r()
+		}
+		acquired <- err
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for b.Queued() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("第二个调用应进入等待队列")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := b.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("队列已满时 err = %v, 期望 ErrQueueFull", err)
+	}
+
+	release()
+	if err := <-acquired; err != nil {
+		t.Errorf("槽位释放后排队调用应获得槽位: %v", err)
+	}
+	if b.InFlight() != 0 || b.Queued() != 0 {
+		t.Errorf("InFlight = %d, Queued = %d, 期望均为 0", b.InFlight(), b.Queued())
+	}
+}
+
+// TestBulkheadTimeout 测试排队超时、上下文取消与不排队模式，参数: 测试实例，返回: 无
+func TestBulkheadTimeout(t *testing.T) {
+	b := New("test", 1, 2, 20*time.Millisecond)
+	release, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("首个调用应直接获得槽位: %v", err)
+	}
+	defer release()
+
+	if _, err := b.Acquire(context.Background()); !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("err = %v, 期望 ErrQueueTimeout", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, 期望 context.Canceled", err)
+	}
+
+	noQueue := New("no-queue", 1, 0, time.Second)
+	r, _ := noQueue.Acquire(context.Background())
+	defer r()
+	if _, err := noQueue.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("不排队时 err = %v, 期望 ErrQueueFull", err)
+	}
+}
@@ -27,13 +27,28 @@ type Cache interface {
 	Close() error
 }
 
+// Stats 缓存条目统计
+type Stats struct {
+	Entries    int64 // 缓存条目数
+	ValueBytes int64 // 缓存值的总字节数
+	Exact      bool  // 是否为精确值 (为 false 时按抽样估算)
+}
+
+// StatsReporter 可统计缓存条目的后端实现此接口 (可选)
+// 目前只有 RedisCache 实现 (按抽样估算)，未实现的后端只统计命中率
+type StatsReporter interface {
+	// Stats 统计缓存条目数与值大小
+	Stats(ctx context.Context) (Stats, error)
+}
+
 // CachedTranslation 统一的缓存值结构
 // 支持所有翻译服务提供商的结果存储
 type CachedTranslation struct {
 	// ========== 原始请求信息 ==========
-	OriginalText string `json:"original_text"`  // 翻译前的原始文本
-	SourceLang   string `json:"source_lang"`    // 源语言代码 (可能是 auto 检测后的结果)
-	TargetLang   string `json:"target_lang"`    // 目标语言代码
+	OriginalText string  `json:"original_text"`               // 翻译前的原始文本
+	SourceLang   string  `json:"source_lang"`                 // 源语言代码 (可能是 auto 检测后的结果)
+	SourceScore  float64 `json:"source_confidence,omitempty"` // 源语言检测置信度 (提供商返回 ld_result 时记录)
+	TargetLang   string  `json:"target_lang"`                 // 目标语言代码
 
 	// ========== 翻译结果 ==========
 	TranslatedText string   `json:"translated_text"`        // 主要翻译结果
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// memCache 测试用内存缓存，不处理过期
+type memCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemCache() *memCache {
+	return &memCache{data: make(map[string][]byte)}
+}
+
+func (m *memCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memCache) Ping(ctx context.Context) error { return nil }
+
+func (m *memCache) Close() error { return nil }
+
+// stubService 测试用翻译服务，自动检测时报告源语言 fr，译文带上语气，并记录收到的源语言
+type stubService struct {
+	mu      sync.Mutex
+	sources []string
+	detects int
+}
+
+func (s *stubService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	return s.TranslateWithModel(ctx, q, sl, tl, dt, "")
+}
+
+func (s *stubService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	s.mu.Lock()
+	s.sources = append(s.sources, sl)
+	s.mu.Unlock()
+	src := sl
+	if isAutoLanguage(sl) {
+		src = "fr"
+	}
+	trans := "T:" + q
+	if formality := deeplx.FormalityFromContext(ctx); formality != "" {
+		trans += "(" + formality + ")"
+	}
+	return &translation.Response{Src: src, Sentences: []translation.Sentence{{Orig: q, Trans: trans}}}, nil
+}
+
+func (s *stubService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	return deeplx.TranslateSequential(ctx, s, texts, sl, tl, dt, model)
+}
+
+func (s *stubService) DetectLanguage(ctx context.Context, text string) (*deeplx.Detection, error) {
+	s.mu.Lock()
+	s.detects++
+	s.mu.Unlock()
+	return &deeplx.Detection{Language: "fr", Confidence: 0.9}, nil
+}
+
+func (s *stubService) Healthcheck(ctx context.Context) error { return nil }
+
+func (s *stubService) GetName() string { return "stub" }
+
+func (s *stubService) IsAvailable() bool { return true }
+
+func (s *stubService) Capabilities() deeplx.Capabilities { return deeplx.DefaultCapabilities() }
+
+// calls 返回收到的源语言 (按调用顺序)，参数: 无，返回: 源语言列表
+func (s *stubService) calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sources...)
+}
+
+// TestDetectionCachingService 测试自动检测的请求写入检测缓存，再次请求时以缓存的语言调用下游，指定源语言时不读写，参数: 测试实例，返回: 无
+func TestDetectionCachingService(t *testing.T) {
+	svc := &stubService{}
+	mc := newMemCache()
+	d := NewDetectionCachingService(svc, mc, 0, nil)
+	ctx := context.Background()
+
+	for _, sl := range []string{"auto", "", "auto"} {
+		if _, err := d.Translate(ctx, "Bonjour", sl, "en", nil); err != nil {
+			t.Fatalf("Translate(%q) error = %v", sl, err)
+		}
+	}
+	if got := strings.Join(svc.calls(), ","); got != "auto,fr,fr" {
+		t.Errorf("下游收到的源语言 = %s, want auto,fr,fr", got)
+	}
+	if got := d.Detected(ctx, "  Bonjour "); got != "fr" {
+		t.Errorf("Detected() = %q, want fr (按去除首尾空白的文本)", got)
+	}
+
+	if _, err := d.Translate(ctx, "Hallo", "de", "en", nil); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if got := d.Detected(ctx, "Hallo"); got != "" {
+		t.Errorf("Detected() = %q, want 空 (指定源语言时不写入)", got)
+	}
+	if len(mc.data) != 1 {
+		t.Errorf("缓存条目 = %d, want 1", len(mc.data))
+	}
+}
+
+// TestDetectionCachingServiceDetectLanguage 测试语言检测共用检测缓存，命中时不调用下游，参数: 测试实例，返回: 无
+func TestDetectionCachingServiceDetectLanguage(t *testing.T) {
+	svc := &stubService{}
+	d := NewDetectionCachingService(svc, newMemCache(), time.Hour, nil)
+	ctx := context.Background()
+
+	first, err := d.DetectLanguage(ctx, "Salut")
+	if err != nil || first.Language != "fr" || first.Confidence != 0.9 {
+		t.Fatalf("DetectLanguage() = %+v, %v", first, err)
+	}
+	second, err := d.DetectLanguage(ctx, "Salut")
+	if err != nil || second.Language != "fr" || second.Confidence != 0 {
+		t.Errorf("DetectLanguage() = %+v, %v, want 命中缓存 (置信度未知)", second, err)
+	}
+	if svc.detects != 1 {
+		t.Errorf("下游检测次数 = %d, want 1", svc.detects)
+	}
+
+	// 检测结果同样用于翻译请求
+	if _, err := d.Translate(ctx, "Salut", "auto", "en", nil); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if got := strings.Join(svc.calls(), ","); got != "fr" {
+		t.Errorf("下游收到的源语言 = %s, want fr", got)
+	}
+}
+
+// TestModelVariant 测试语气并入缓存键的模型段，未设置时保持原有缓存键，参数: 测试实例，返回: 无
+func TestModelVariant(t *testing.T) {
+	if got := ModelVariant("gpt", ""); got != "gpt" {
+		t.Errorf("ModelVariant() = %q, want gpt", got)
+	}
+	base := GenerateCacheKey("stub", "Hello", "en", "de", "gpt")
+	formal := GenerateCacheKey("stub", "Hello", "en", "de", ModelVariant("gpt", "more"))
+	informal := GenerateCacheKey("stub", "Hello", "en", "de", ModelVariant("gpt", "less"))
+	if base == formal || formal == informal || base == informal {
+		t.Errorf("缓存键未按语气区分: %s %s %s", base, formal, informal)
+	}
+}
+
+// TestCachedServiceFormality 测试单条与批量翻译按语气区分缓存条目，相同语气互相命中，参数: 测试实例，返回: 无
+func TestCachedServiceFormality(t *testing.T) {
+	svc := &stubService{}
+	mc := newMemCache()
+	c := NewCachedTranslationService(svc, mc, CachedServiceConfig{Enabled: true})
+	ctx := context.Background()
+	next := func(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+		return svc.TranslateWithModel(deeplx.WithFormality(ctx, req.Formality), req.Text, req.Source, req.Target, req.DT, req.Model)
+	}
+	process := func(formality string) string {
+		t.Helper()
+		resp, err := c.Process(ctx, &pipeline.Request{Text: "Hello", Source: "en", Target: "de", Formality: formality}, next)
+		if err != nil {
+			t.Fatalf("Process(%q) error = %v", formality, err)
+		}
+		return resp.Sentences[0].Trans
+	}
+
+	for _, step := range []struct{ formality, want string }{
+		{"", "T:Hello"},
+		{"more", "T:Hello(more)"},
+		{"", "T:Hello"},
+		{"more", "T:Hello(more)"},
+	} {
+		if got := process(step.formality); got != step.want {
+			t.Errorf("Process(%q) = %q, want %q", step.formality, got, step.want)
+		}
+	}
+	if got := len(svc.calls()); got != 2 {
+		t.Errorf("upstream calls = %d, want 2 (每种语气一次)", got)
+	}
+
+	// 批量翻译从上下文读取语气：less 未命中，more 命中单条请求写入的条目
+	batch := func(formality string) []*translation.Response {
+		t.Helper()
+		responses, err := c.TranslateBatch(deeplx.WithFormality(ctx, formality), []string{"Hello", "World"}, "en", "de", nil, "")
+		if err != nil {
+			t.Fatalf("TranslateBatch(%q) error = %v", formality, err)
+		}
+		return responses
+	}
+	less := batch("less")
+	if less[0].Sentences[0].Trans != "T:Hello(less)" || less[1].Sentences[0].Trans != "T:World(less)" {
+		t.Errorf("TranslateBatch(less) = %q, %q", less[0].Sentences[0].Trans, less[1].Sentences[0].Trans)
+	}
+	if got := len(svc.calls()); got != 4 {
+		t.Errorf("upstream calls = %d, want 4", got)
+	}
+	more := batch("more")
+	if more[0].Sentences[0].Trans != "T:Hello(more)" || more[1].Sentences[0].Trans != "T:World(more)" {
+		t.Errorf("TranslateBatch(more) = %q, %q", more[0].Sentences[0].Trans, more[1].Sentences[0].Trans)
+	}
+	if got := len(svc.calls()); got != 5 {
+		t.Errorf("upstream calls = %d, want 5 (Hello 命中缓存)", got)
+	}
+	if len(mc.data) != 5 {
+		t.Errorf("缓存条目 = %d, want 5", len(mc.data))
+	}
+}
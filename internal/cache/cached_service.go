@@ -3,8 +3,12 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/requestid"
 	"github.com/XgzK/translate-services/internal/translation"
 	"github.com/XgzK/translate-services/internal/translator/deeplx"
 	"github.com/rs/zerolog"
@@ -32,6 +36,7 @@ type CachedTranslationService struct {
 	enabled      bool                      // 是否启用缓存
 	writeTimeout time.Duration             // 缓存写入超时时间
 	logger       *zerolog.Logger           // 日志器 (修复: 注入 Logger，保持一致性喵～)
+	onLookup     func(hit bool)            // 每次读取缓存后的回调 (可为 nil)
 }
 
 // CachedServiceOption 缓存服务可选配置函数类型
@@ -51,6 +56,14 @@ func WithWriteTimeout(timeout time.Duration) CachedServiceOption {
 	}
 }
 
+// WithLookupObserver 设置读取缓存后的回调，用于统计命中率，参数: 回调 (参数为是否命中)，返回: 配置函数
+// 客户端要求跳过读取 (no-cache、no-store) 时不回调
+func WithLookupObserver(observe func(hit bool)) CachedServiceOption {
+	return func(c *CachedTranslationService) {
+		c.onLookup = observe
+	}
+}
+
 // NewCachedTranslationService 创建缓存翻译服务
 func NewCachedTranslationService(
 	service deeplx.TranslationService,
@@ -97,37 +110,128 @@ func (c *CachedTranslationService) TranslateWithModel(
 	dt []string,
 	model string,
 ) (*translation.Response, error) {
-	// 缓存未启用或缓存实例为空，直接调用底层服务
+	req := &pipeline.Request{Text: q, Source: sl, Target: tl, DT: dt, Model: model}
+	return c.Process(ctx, req, pipeline.ServiceHandler(c.service))
+}
+
+// TranslateBatch 实现 TranslationService 接口
+// 逐段查询缓存，未命中的文本合并为一次批量调用，结果异步写回缓存；语气取自上下文
+func (c *CachedTranslationService) TranslateBatch(
+	ctx context.Context,
+	texts []string,
+	sl, tl string,
+	dt []string,
+	model string,
+) ([]*translation.Response, error) {
 	if !c.enabled || c.cache == nil {
-		return c.service.TranslateWithModel(ctx, q, sl, tl, dt, model)
+		return c.service.TranslateBatch(ctx, texts, sl, tl, dt, model)
 	}
 
-	// 生成缓存键
 	serviceName := c.service.GetName()
-	key := c.keyGenerator.Generate(serviceName, q, sl, tl, model)
+	variant := ModelVariant(model, deeplx.FormalityFromContext(ctx))
+	responses := make([]*translation.Response, len(texts))
+	keys := make([]string, len(texts))
+	var missed []int
+	for i, text := range texts {
+		keys[i] = c.keyGenerator.Generate(serviceName, text, sl, tl, variant)
+		if cached, err := c.getFromCache(ctx, keys[i]); err == nil && cached != nil {
+			c.observeLookup(true)
+			responses[i] = c.buildResponseFromCache(cached, dt)
+			continue
+		}
+		c.observeLookup(false)
+		missed = append(missed, i)
+	}
+	c.logDebug(ctx).
+		Int("texts", len(texts)).
+		Int("missed", len(missed)).
+		Str("service", serviceName).
+		Msg("batch cache lookup")
+	if len(missed) == 0 {
+		return responses, nil
+	}
+
+	missedTexts := make([]string, len(missed))
+	for j, i := range missed {
+		missedTexts[j] = texts[i]
+	}
+	translated, err := c.service.TranslateBatch(ctx, missedTexts, sl, tl, dt, model)
+	if err != nil {
+		return nil, err
+	}
+	if len(translated) != len(missed) {
+		return nil, errors.New("批量翻译返回的结果数与请求数不一致")
+	}
+	for j, i := range missed {
+		resp := translated[j]
+		responses[i] = resp
+		if resp == nil {
+			continue
+		}
+		key, text := keys[i], texts[i]
+		pipeline.Detach(ctx, "cache_write", func(ctx context.Context) error {
+			c.saveToCacheWithTimeout(ctx, key, text, sl, tl, model, resp)
+			return nil
+		})
+	}
+	return responses, nil
+}
+
+// Name 实现 pipeline.Stage 接口
+func (c *CachedTranslationService) Name() string {
+	return "cache"
+}
 
-	// 尝试从缓存获取
-	if cached, err := c.getFromCache(ctx, key); err == nil && cached != nil {
-		c.logDebug().
-			Str("key", key).
-			Str("service", serviceName).
-			Msg("cache hit")
-		return c.buildResponseFromCache(cached), nil
+// Process 实现 pipeline.Stage 接口，命中缓存时不再调用下游
+func (c *CachedTranslationService) Process(
+	ctx context.Context,
+	req *pipeline.Request,
+	next pipeline.Handler,
+) (*translation.Response, error) {
+	// 缓存未启用、缓存实例为空或客户端要求不使用缓存，直接调用下游
+	if !c.enabled || c.cache == nil || req.Cache == pipeline.CacheNoStore {
+		return next(ctx, req)
+	}
+
+	// 生成缓存键，请求指定了提供商时按该提供商区分
+	serviceName := c.service.GetName()
+	if req.Provider != "" {
+		serviceName = req.Provider
+	}
+	key := c.keyGenerator.Generate(serviceName, req.Text, req.Source, req.Target, ModelVariant(req.Model, req.Formality))
+
+	// 尝试从缓存获取 (no-cache 时跳过读取，结果仍写回以刷新缓存)
+	if req.Cache == pipeline.CacheNoCache {
+		c.logDebug(ctx).Str("key", key).Msg("cache read skipped by client directive")
+	} else {
+		cached, err := c.getFromCache(ctx, key)
+		hit := err == nil && cached != nil
+		c.observeLookup(hit)
+		if hit {
+			c.logDebug(ctx).
+				Str("key", key).
+				Str("service", serviceName).
+				Msg("cache hit")
+			return c.buildResponseFromCache(cached, req.DT), nil
+		}
 	}
 
-	// 缓存未命中，调用翻译服务
-	c.logDebug().
+	// 缓存未命中，调用下游
+	c.logDebug(ctx).
 		Str("key", key).
 		Str("service", serviceName).
 		Msg("cache miss, calling translation service")
 
-	resp, err := c.service.TranslateWithModel(ctx, q, sl, tl, dt, model)
+	resp, err := next(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// 异步写入缓存（带超时控制，不阻塞响应喵～）
-	go c.saveToCacheWithTimeout(key, q, sl, tl, model, resp)
+	// 异步写入缓存（交由管道后台任务组，带超时控制，不阻塞响应喵～）
+	pipeline.Detach(ctx, "cache_write", func(ctx context.Context) error {
+		c.saveToCacheWithTimeout(ctx, key, req.Text, req.Source, req.Target, req.Model, resp)
+		return nil
+	})
 
 	return resp, nil
 }
@@ -142,11 +246,33 @@ func (c *CachedTranslationService) IsAvailable() bool {
 	return c.service.IsAvailable()
 }
 
+// Healthcheck 调用被包装服务的健康检查
+func (c *CachedTranslationService) Healthcheck(ctx context.Context) error {
+	return c.service.Healthcheck(ctx)
+}
+
+// DetectLanguage 调用被包装服务的语言检测
+func (c *CachedTranslationService) DetectLanguage(ctx context.Context, text string) (*deeplx.Detection, error) {
+	return c.service.DetectLanguage(ctx, text)
+}
+
+// Capabilities 返回被包装服务的能力
+func (c *CachedTranslationService) Capabilities() deeplx.Capabilities {
+	return c.service.Capabilities()
+}
+
+// observeLookup 回调一次缓存读取结果，参数: 是否命中，返回: 无
+func (c *CachedTranslationService) observeLookup(hit bool) {
+	if c.onLookup != nil {
+		c.onLookup(hit)
+	}
+}
+
 // getFromCache 从缓存获取翻译结果
 func (c *CachedTranslationService) getFromCache(ctx context.Context, key string) (*CachedTranslation, error) {
 	data, err := c.cache.Get(ctx, key)
 	if err != nil {
-		c.logWarn().Err(err).Str("key", key).Msg("cache get failed")
+		c.logWarn(ctx).Err(err).Str("key", key).Msg("cache get failed")
 		return nil, err
 	}
 	if data == nil {
@@ -155,13 +281,13 @@ func (c *CachedTranslationService) getFromCache(ctx context.Context, key string)
 
 	var cached CachedTranslation
 	if err := json.Unmarshal(data, &cached); err != nil {
-		c.logWarn().Err(err).Str("key", key).Msg("cache unmarshal failed, ignoring corrupted data")
+		c.logWarn(ctx).Err(err).Str("key", key).Msg("cache unmarshal failed, ignoring corrupted data")
 		return nil, err
 	}
 
 	// 检查缓存版本兼容性
 	if cached.Version != CacheFormatVersion {
-		c.logDebug().
+		c.logDebug(ctx).
 			Int("cached_version", cached.Version).
 			Int("current_version", CacheFormatVersion).
 			Msg("cache version mismatch, ignoring old data")
@@ -173,11 +299,12 @@ func (c *CachedTranslationService) getFromCache(ctx context.Context, key string)
 
 // saveToCacheWithTimeout 带超时控制的缓存保存 (修复: 添加超时控制喵～)
 func (c *CachedTranslationService) saveToCacheWithTimeout(
+	ctx context.Context,
 	key, originalText, sourceLang, targetLang, model string,
 	resp *translation.Response,
 ) {
 	// 创建带超时的 context
-	ctx, cancel := context.WithTimeout(context.Background(), c.writeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.writeTimeout)
 	defer cancel()
 
 	c.saveToCache(ctx, key, originalText, sourceLang, targetLang, model, resp)
@@ -193,21 +320,21 @@ func (c *CachedTranslationService) saveToCache(
 
 	data, err := json.Marshal(cached)
 	if err != nil {
-		c.logWarn().Err(err).Str("key", key).Msg("cache marshal failed")
+		c.logWarn(ctx).Err(err).Str("key", key).Msg("cache marshal failed")
 		return
 	}
 
 	if err := c.cache.Set(ctx, key, data, c.ttl); err != nil {
 		// 检查是否为超时错误
 		if ctx.Err() == context.DeadlineExceeded {
-			c.logWarn().Str("key", key).Dur("timeout", c.writeTimeout).Msg("cache write timeout")
+			c.logWarn(ctx).Str("key", key).Dur("timeout", c.writeTimeout).Msg("cache write timeout")
 		} else {
-			c.logWarn().Err(err).Str("key", key).Msg("cache set failed")
+			c.logWarn(ctx).Err(err).Str("key", key).Msg("cache set failed")
 		}
 		return
 	}
 
-	c.logDebug().
+	c.logDebug(ctx).
 		Str("key", key).
 		Str("service", c.service.GetName()).
 		Dur("ttl", c.ttl).
@@ -232,6 +359,8 @@ func (c *CachedTranslationService) buildCachedTranslation(
 	// 如果 Src 为空，使用请求的源语言
 	if cached.SourceLang == "" {
 		cached.SourceLang = sourceLang
+	} else if ld := resp.LDResult; ld != nil && len(ld.Srclangs) > 0 && ld.Srclangs[0] == resp.Src && len(ld.SrclangsConfidences) > 0 {
+		cached.SourceScore = ld.SrclangsConfidences[0]
 	}
 
 	// 提取主翻译结果
@@ -261,20 +390,23 @@ func (c *CachedTranslationService) buildCachedTranslation(
 	return cached
 }
 
-// buildResponseFromCache 从缓存构建 Response
-func (c *CachedTranslationService) buildResponseFromCache(cached *CachedTranslation) *translation.Response {
+// buildResponseFromCache 从缓存构建 Response，备选翻译只在 dt 包含 at 或 bd 时返回 (与提供商行为一致)
+func (c *CachedTranslationService) buildResponseFromCache(cached *CachedTranslation, dt []string) *translation.Response {
 	resp := &translation.Response{
-		Src: cached.SourceLang,
-		Sentences: []translation.Sentence{
-			{
-				Orig:  cached.OriginalText,
-				Trans: cached.TranslatedText,
-			},
-		},
+		Src:       cached.SourceLang,
+		Sentences: translation.AlignSentences(cached.OriginalText, cached.TranslatedText),
+		Model:     cached.Model,
+		Cached:    true,
+	}
+	if cached.SourceScore > 0 {
+		resp.LDResult = &translation.LanguageDetectionResult{
+			Srclangs:            []string{cached.SourceLang},
+			SrclangsConfidences: []float64{cached.SourceScore},
+		}
 	}
 
 	// 如果有备选翻译，构建 AlternativeTranslations
-	if len(cached.Alternatives) > 0 {
+	if len(cached.Alternatives) > 0 && (langutil.Includes(dt, "at") || langutil.Includes(dt, "bd")) {
 		alternatives := make([]translation.Alternative, 0, len(cached.Alternatives))
 		for _, alt := range cached.Alternatives {
 			alternatives = append(alternatives, translation.Alternative{
@@ -283,8 +415,9 @@ func (c *CachedTranslationService) buildResponseFromCache(cached *CachedTranslat
 		}
 		resp.AlternativeTranslations = []translation.AlternativeTranslation{
 			{
-				SrcPhrase:   cached.OriginalText,
-				Alternative: alternatives,
+				SrcPhrase:     cached.OriginalText,
+				RawSrcSegment: cached.OriginalText,
+				Alternative:   alternatives,
 			},
 		}
 	}
@@ -305,18 +438,26 @@ func (c *CachedTranslationService) Close() error {
 // nopLogger 空日志器（用于未注入 logger 时）
 var nopLogger = zerolog.Nop()
 
-// logDebug 返回 Debug 级别日志事件
-func (c *CachedTranslationService) logDebug() *zerolog.Event {
+// withRequestID 为日志事件附加请求 ID，参数: 上下文与日志事件，返回: 日志事件
+func withRequestID(ctx context.Context, event *zerolog.Event) *zerolog.Event {
+	if id := requestid.FromContext(ctx); id != "" {
+		return event.Str("request_id", id)
+	}
+	return event
+}
+
+// logDebug 返回带请求 ID 的 Debug 级别日志事件
+func (c *CachedTranslationService) logDebug(ctx context.Context) *zerolog.Event {
 	if c.logger != nil {
-		return c.logger.Debug()
+		return withRequestID(ctx, c.logger.Debug())
 	}
 	return nopLogger.Debug()
 }
 
-// logWarn 返回 Warn 级别日志事件
-func (c *CachedTranslationService) logWarn() *zerolog.Event {
+// logWarn 返回带请求 ID 的 Warn 级别日志事件
+func (c *CachedTranslationService) logWarn(ctx context.Context) *zerolog.Event {
 	if c.logger != nil {
-		return c.logger.Warn()
+		return withRequestID(ctx, c.logger.Warn())
 	}
 	return nopLogger.Warn()
 }
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+	"github.com/rs/zerolog"
+)
+
+// 语言检测缓存默认值
+const (
+	defaultDetectionTTL = 30 * 24 * time.Hour // 语言检测结果极少变化，默认保留 30 天
+)
+
+// DetectionCachingService 包装 TranslationService，单独缓存文本的检测语言
+// 条目只保存语言代码，即使完整译文不可缓存 (如 no-store 租户) 也能复用检测结果
+type DetectionCachingService struct {
+	service      deeplx.TranslationService // 被包装的翻译服务
+	cache        Cache                     // 缓存实现
+	ttl          time.Duration             // 检测结果过期时间
+	writeTimeout time.Duration             // 缓存写入超时时间
+	logger       *zerolog.Logger
+}
+
+// NewDetectionCachingService 创建语言检测缓存服务，参数: 被包装服务、缓存实现、过期时间、日志器，返回: 服务指针
+func NewDetectionCachingService(
+	service deeplx.TranslationService,
+	cache Cache,
+	ttl time.Duration,
+	logger *zerolog.Logger,
+) *DetectionCachingService {
+	if ttl <= 0 {
+		ttl = defaultDetectionTTL
+	}
+	return &DetectionCachingService{
+		service:      service,
+		cache:        cache,
+		ttl:          ttl,
+		writeTimeout: defaultCacheWriteTimeout,
+		logger:       logger,
+	}
+}
+
+// Translate 实现 TranslationService 接口
+func (d *DetectionCachingService) Translate(
+	ctx context.Context,
+	q, sl, tl string,
+	dt []string,
+) (*translation.Response, error) {
+	return d.TranslateWithModel(ctx, q, sl, tl, dt, "")
+}
+
+// TranslateWithModel 实现 TranslationService 接口
+func (d *DetectionCachingService) TranslateWithModel(
+	ctx context.Context,
+	q, sl, tl string,
+	dt []string,
+	model string,
+) (*translation.Response, error) {
+	req := &pipeline.Request{Text: q, Source: sl, Target: tl, DT: dt, Model: model}
+	return d.Process(ctx, req, pipeline.ServiceHandler(d.service))
+}
+
+// TranslateBatch 实现 TranslationService 接口
+// 一批文本共用一个源语言且上游只返回整批的检测结果，因此直接调用被包装服务，不读写检测缓存
+func (d *DetectionCachingService) TranslateBatch(
+	ctx context.Context,
+	texts []string,
+	sl, tl string,
+	dt []string,
+	model string,
+) ([]*translation.Response, error) {
+	return d.service.TranslateBatch(ctx, texts, sl, tl, dt, model)
+}
+
+// Name 实现 pipeline.Stage 接口
+func (d *DetectionCachingService) Name() string {
+	return "detection_cache"
+}
+
+// Process 实现 pipeline.Stage 接口
+// 源语言为自动检测时先查询检测缓存，命中则直接以该语言请求下游
+func (d *DetectionCachingService) Process(
+	ctx context.Context,
+	req *pipeline.Request,
+	next pipeline.Handler,
+) (*translation.Response, error) {
+	if !isAutoLanguage(req.Source) {
+		return next(ctx, req)
+	}
+
+	key := GenerateDetectionKey(req.Text)
+	if detected := d.lookup(ctx, key); detected != "" {
+		d.logDebug(ctx).Str("key", key).Str("detected", detected).Msg("detection cache hit")
+		resolved := *req
+		resolved.Source = detected
+		return next(ctx, &resolved)
+	}
+
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil || strings.TrimSpace(resp.Src) == "" {
+		return resp, err
+	}
+
+	pipeline.Detach(ctx, "detection_cache_write", func(ctx context.Context) error {
+		d.store(ctx, key, resp.Src)
+		return nil
+	})
+	return resp, nil
+}
+
+// Detected 返回检测缓存中文本的源语言，供不调用上游的判断使用，参数: 上下文与文本，返回: 语言代码 (未命中为空)
+func (d *DetectionCachingService) Detected(ctx context.Context, text string) string {
+	return d.lookup(ctx, GenerateDetectionKey(text))
+}
+
+// GetName 返回服务名称
+func (d *DetectionCachingService) GetName() string {
+	return d.service.GetName()
+}
+
+// IsAvailable 检查服务是否可用
+func (d *DetectionCachingService) IsAvailable() bool {
+	return d.service.IsAvailable()
+}
+
+// Healthcheck 调用被包装服务的健康检查
+func (d *DetectionCachingService) Healthcheck(ctx context.Context) error {
+	return d.service.Healthcheck(ctx)
+}
+
+// DetectLanguage 先查询检测缓存，未命中时调用被包装服务的语言检测并写入缓存，参数: 上下文与文本，返回: 检测结果 (命中缓存时置信度未知) 与错误
+func (d *DetectionCachingService) DetectLanguage(ctx context.Context, text string) (*deeplx.Detection, error) {
+	key := GenerateDetectionKey(text)
+	if detected := d.lookup(ctx, key); detected != "" {
+		return &deeplx.Detection{Language: detected}, nil
+	}
+	detection, err := d.service.DetectLanguage(ctx, text)
+	if err != nil || detection == nil || strings.TrimSpace(detection.Language) == "" {
+		return detection, err
+	}
+	pipeline.Detach(ctx, "detection_cache_write", func(ctx context.Context) error {
+		d.store(ctx, key, detection.Language)
+		return nil
+	})
+	return detection, nil
+}
+
+// Capabilities 返回被包装服务的能力
+func (d *DetectionCachingService) Capabilities() deeplx.Capabilities {
+	return d.service.Capabilities()
+}
+
+// lookup 查询检测缓存，参数: 上下文与缓存键，返回: 检测语言 (未命中为空)
+func (d *DetectionCachingService) lookup(ctx context.Context, key string) string {
+	data, err := d.cache.Get(ctx, key)
+	if err != nil {
+		d.logWarn(ctx).Err(err).Str("key", key).Msg("detection cache get failed")
+		return ""
+	}
+	return string(data)
+}
+
+// store 写入检测缓存 (带超时控制)，参数: 上下文、缓存键与语言代码，返回: 无
+func (d *DetectionCachingService) store(ctx context.Context, key, lang string) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	if err := d.cache.Set(ctx, key, []byte(lang), d.ttl); err != nil {
+		d.logWarn(ctx).Err(err).Str("key", key).Msg("detection cache set failed")
+	}
+}
+
+// logDebug 返回 Debug 级别日志事件
+func (d *DetectionCachingService) logDebug(ctx context.Context) *zerolog.Event {
+	if d.logger != nil {
+		return withRequestID(ctx, d.logger.Debug())
+	}
+	return nopLogger.Debug()
+}
+
+// logWarn 返回 Warn 级别日志事件
+func (d *DetectionCachingService) logWarn(ctx context.Context) *zerolog.Event {
+	if d.logger != nil {
+		return withRequestID(ctx, d.logger.Warn())
+	}
+	return nopLogger.Warn()
+}
+
+// isAutoLanguage 判断源语言是否为自动检测，参数: 源语言代码，返回: 布尔
+func isAutoLanguage(sl string) bool {
+	sl = strings.TrimSpace(sl)
+	return sl == "" || strings.EqualFold(sl, "auto")
+}
@@ -12,6 +12,8 @@ const (
 	KeyPrefix = "translate"
 	// SharedServiceName 共享缓存的服务名
 	SharedServiceName = "shared"
+	// DetectionKeySegment 语言检测缓存的键段
+	DetectionKeySegment = "detect"
 )
 
 // KeyGenerator 缓存键生成器
@@ -63,6 +65,15 @@ func (g *KeyGenerator) computeHash(text, sourceLang, targetLang, model string) s
 	return hex.EncodeToString(hash[:8])
 }
 
+// ModelVariant 把影响译文的附加参数并入模型段，使不同语气等结果使用不同的缓存键，参数: 模型与语气，返回: 用于缓存键的模型段
+// 未设置附加参数时原样返回模型，已有缓存键保持不变
+func ModelVariant(model, formality string) string {
+	if formality == "" {
+		return model
+	}
+	return model + "#formality=" + formality
+}
+
 // GenerateCacheKey 便捷函数：生成缓存键 (默认隔离模式)
 func GenerateCacheKey(service, text, sourceLang, targetLang, model string) string {
 	return NewKeyGenerator(false).Generate(service, text, sourceLang, targetLang, model)
@@ -72,3 +83,10 @@ func GenerateCacheKey(service, text, sourceLang, targetLang, model string) strin
 func GenerateSharedCacheKey(text, sourceLang, targetLang, model string) string {
 	return NewKeyGenerator(true).Generate("", text, sourceLang, targetLang, model)
 }
+
+// GenerateDetectionKey 便捷函数：生成语言检测缓存键
+// 返回格式: translate:detect:{hash}，与服务和目标语言无关
+func GenerateDetectionKey(text string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(text)))
+	return fmt.Sprintf("%s:%s:%s", KeyPrefix, DetectionKeySegment, hex.EncodeToString(hash[:8]))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -124,6 +125,71 @@ func (r *RedisCache) Close() error {
 	return nil
 }
 
+// 缓存统计的抽样上限
+const (
+	statsScanLimit   = 10000 // 单次统计最多扫描的键数，超过后按比例估算
+	statsScanCount   = 1000  // 每次 SCAN 的 COUNT 提示
+	statsSampleLimit = 200   // 估算值大小时最多抽样的缓存键数
+)
+
+// Stats 按 SCAN 抽样统计缓存条目数与值大小
+// 同一数据库中还有用量、额度等其他键，只统计 KeyPrefix 开头的缓存键 (含检测缓存)；
+// 键数超过扫描上限时按已扫描键中缓存键的比例乘以 DBSIZE 估算，值大小按抽样键的平均 STRLEN 估算
+func (r *RedisCache) Stats(ctx context.Context) (Stats, error) {
+	var (
+		cursor  uint64
+		scanned int
+		matched []string
+	)
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, "", statsScanCount).Result()
+		if err != nil {
+			return Stats{}, fmt.Errorf("redis scan failed: %w", err)
+		}
+		scanned += len(keys)
+		for _, key := range keys {
+			if strings.HasPrefix(key, KeyPrefix+":") {
+				matched = append(matched, key)
+			}
+		}
+		cursor = next
+		if cursor == 0 || scanned >= statsScanLimit {
+			break
+		}
+	}
+
+	stats := Stats{Entries: int64(len(matched)), Exact: cursor == 0}
+	if !stats.Exact {
+		total, err := r.client.DBSize(ctx).Result()
+		if err != nil {
+			return Stats{}, fmt.Errorf("redis dbsize failed: %w", err)
+		}
+		stats.Entries = total * int64(len(matched)) / int64(scanned)
+	}
+
+	sample := matched[:min(len(matched), statsSampleLimit)]
+	if len(sample) == 0 {
+		return stats, nil
+	}
+	pipe := r.client.Pipeline()
+	lengths := make([]*redis.IntCmd, len(sample))
+	for i, key := range sample {
+		lengths[i] = pipe.StrLen(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Stats{}, fmt.Errorf("redis strlen failed: %w", err)
+	}
+	var sampleBytes int64
+	for _, length := range lengths {
+		sampleBytes += length.Val()
+	}
+	if len(sample) < len(matched) {
+		stats.Exact = false
+	}
+	stats.ValueBytes = sampleBytes * stats.Entries / int64(len(sample))
+	return stats, nil
+}
+
 // Client 返回底层 Redis 客户端（用于高级操作）
 func (r *RedisCache) Client() *redis.Client {
 	return r.client
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis 在客户端钩子中模拟 SCAN、DBSIZE 与 STRLEN，不建立网络连接
+// SCAN 按写入顺序分页，游标为下一页起始下标
+type fakeRedis struct {
+	keys   []string
+	values map[string]string
+	scans  int
+}
+
+// newFakeRedis 创建模拟数据，参数: 按写入顺序排列的键值对，返回: 模拟数据
+func newFakeRedis(entries [][2]string) *fakeRedis {
+	f := &fakeRedis{values: make(map[string]string, len(entries))}
+	for _, entry := range entries {
+		f.keys = append(f.keys, entry[0])
+		f.values[entry[0]] = entry[1]
+	}
+	return f
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("fakeRedis 不建立连接")
+	}
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return f.process(cmd)
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := f.process(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// process 执行一条命令，参数: 命令，返回: 不支持的命令错误
+func (f *fakeRedis) process(cmd redis.Cmder) error {
+	args := cmd.Args()
+	switch c := cmd.(type) {
+	case *redis.ScanCmd:
+		f.scans++
+		cursor := int(args[1].(uint64))
+		count := 10
+		if len(args) == 4 {
+			count = int(args[3].(int64))
+		}
+		end := min(cursor+count, len(f.keys))
+		next := uint64(end)
+		if end == len(f.keys) {
+			next = 0
+		}
+		c.SetVal(f.keys[cursor:end], next)
+	case *redis.IntCmd:
+		switch strings.ToLower(cmd.Name()) {
+		case "dbsize":
+			c.SetVal(int64(len(f.keys)))
+		case "strlen":
+			c.SetVal(int64(len(f.values[args[1].(string)])))
+		default:
+			return fmt.Errorf("不支持的命令 %s", cmd.Name())
+		}
+	default:
+		return fmt.Errorf("不支持的命令 %s", cmd.Name())
+	}
+	return nil
+}
+
+// newFakeRedisCache 创建使用模拟数据的 Redis 缓存，参数: 测试实例与模拟数据，返回: 缓存
+func newFakeRedisCache(t *testing.T, f *fakeRedis) *RedisCache {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	client.AddHook(f)
+	t.Cleanup(func() { client.Close() })
+	return &RedisCache{client: client}
+}
+
+// TestRedisStats 测试缓存条目统计：少量键时精确计数，超过扫描上限按 DBSIZE 比例估算，抽样之外的值大小按平均长度估算，参数: 测试实例，返回: 无
+func TestRedisStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   func() [][2]string
+		want      Stats
+		wantScans int
+	}{
+		{
+			name: "精确统计",
+			entries: func() [][2]string {
+				return [][2]string{
+					{KeyPrefix + ":deeplx:a", "12345"},
+					{"usage:2026-10-16", "1234567890"}, // 不是缓存键
+					{KeyPrefix + ":deeplx:b", "123"},
+					{"translate", "x"}, // 没有分隔符
+					{KeyPrefix + ":detect:c", "fr"},
+				}
+			},
+			want:      Stats{Entries: 3, ValueBytes: 10, Exact: true},
+			wantScans: 1,
+		},
+		{
+			name: "抽样估算值大小",
+			entries: func() [][2]string {
+				var entries [][2]string
+				for i := range statsSampleLimit + 100 {
+					entries = append(entries, [2]string{fmt.Sprintf("%s:deeplx:%d", KeyPrefix, i), "0123456789"})
+				}
+				return entries
+			},
+			want:      Stats{Entries: statsSampleLimit + 100, ValueBytes: (statsSampleLimit + 100) * 10},
+			wantScans: 1,
+		},
+		{
+			name: "超过扫描上限按比例估算",
+			entries: func() [][2]string {
+				var entries [][2]string
+				for i := range 25000 {
+					if i%2 == 0 {
+						entries = append(entries, [2]string{fmt.Sprintf("%s:deeplx:%d", KeyPrefix, i), "01234567"})
+					} else {
+						entries = append(entries, [2]string{fmt.Sprintf("quota:%d", i), "1"})
+					}
+				}
+				return entries
+			},
+			// 扫描的前 10000 个键中一半是缓存键，DBSIZE 为 25000
+			want:      Stats{Entries: 12500, ValueBytes: 12500 * 8},
+			wantScans: statsScanLimit / statsScanCount,
+		},
+		{
+			name:      "没有缓存键",
+			entries:   func() [][2]string { return [][2]string{{"quota:a", "1"}} },
+			want:      Stats{Exact: true},
+			wantScans: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis(tt.entries())
+			got, err := newFakeRedisCache(t, f).Stats(context.Background())
+			if err != nil {
+				t.Fatalf("Stats() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.want)
+			}
+			if f.scans != tt.wantScans {
+				t.Errorf("SCAN 次数 = %d, want %d", f.scans, tt.wantScans)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"io/fs"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -30,22 +35,1218 @@ type Config struct {
 
 	// 缓存配置
 	Cache CacheConfig `yaml:"cache"`
+
+	// 额度配置
+	Quota QuotaConfig `yaml:"quota"`
+
+	// 用量统计配置
+	Usage UsageConfig `yaml:"usage"`
+
+	// 管理接口配置
+	Admin AdminConfig `yaml:"admin"`
+
+	// 语言偏好学习配置
+	Preferences PreferencesConfig `yaml:"preferences"`
+
+	// 下游客户端认证配置
+	Auth AuthConfig `yaml:"auth"`
+
+	// 分布式追踪配置
+	Tracing TracingConfig `yaml:"tracing"`
+
+	// 日志输出配置
+	Logging LoggingConfig `yaml:"logging"`
+
+	// 指标端点配置
+	Metrics MetricsConfig `yaml:"metrics"`
+
+	// 当前激活的 profile (来自 APP_ENV，不从文件读取)
+	Profile string `yaml:"-"`
+}
+
+// ServerConfig 服务器配置 (超时与性能相关喵～)
+type ServerConfig struct {
+	RequestTimeout    int    `yaml:"request_timeout"`    // 翻译请求超时 (秒)，默认 8
+	MiddlewareTimeout int    `yaml:"middleware_timeout"` // 中间件超时 (秒)，默认 12
+	ShutdownTimeout   int    `yaml:"shutdown_timeout"`   // 优雅停机超时 (秒)，默认 15
+	MaxBodySize       string `yaml:"max_body_size"`      // 请求体大小上限 (如 2M、10M、512K)，默认 2M
+
+	// 可信反向代理的 CIDR 或 IP，只有来自这些地址的请求才采信 X-Forwarded-For；为空时直接使用连接对端地址
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// 在翻译响应中加入 X-Translate-Provider、X-Translate-Model、X-Cache 与 X-Upstream-Latency 头，便于查看译文来源
+	MetadataHeaders bool `yaml:"metadata_headers"`
+
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`     // 按客户端 IP 的限流配置
+	AccessControl AccessControlConfig `yaml:"access_control"` // 按客户端 IP 的访问控制
+	AutoBan       AutoBanConfig       `yaml:"auto_ban"`       // 按客户端 IP 自动临时封禁
+	TLS           TLSConfig           `yaml:"tls"`            // HTTPS 监听配置
+	Ops           OpsConfig           `yaml:"ops"`            // 运维端点 (/metrics、/healthz、/admin) 的独立监听
+	Compression   CompressionConfig   `yaml:"compression"`    // 响应压缩
+	Concurrency   ConcurrencyConfig   `yaml:"concurrency"`    // 上游调用并发隔离
+	Maintenance   MaintenanceConfig   `yaml:"maintenance"`    // 维护模式 (运行时可通过管理接口切换)
+}
+
+// MaintenanceConfig 维护模式配置，开启期间翻译接口返回 503
+type MaintenanceConfig struct {
+	Enabled    bool   `yaml:"enabled"`     // 启动时即进入维护模式
+	Message    string `yaml:"message"`     // 返回给客户端的说明
+	RetryAfter int    `yaml:"retry_after"` // 建议客户端重试间隔 (秒)，默认 60
+}
+
+// GetRetryAfter 获取重试间隔，参数: 无，返回: 秒数 (默认 60)
+func (c *MaintenanceConfig) GetRetryAfter() int {
+	if c.RetryAfter <= 0 {
+		return 60
+	}
+	return c.RetryAfter
+}
+
+// ConcurrencyConfig 上游调用并发隔离配置 (全局与按提供商)
+type ConcurrencyConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	MaxInFlight  int    `yaml:"max_in_flight"` // 全局最大并发上游调用数，默认 64
+	MaxQueue     int    `yaml:"max_queue"`     // 并发已满时最多排队的请求数，0 表示不排队直接拒绝
+	QueueTimeout string `yaml:"queue_timeout"` // 排队最长等待时间，为空时只受请求超时约束
+	RetryAfter   int    `yaml:"retry_after"`   // 拒绝时建议客户端重试间隔 (秒)，默认 1
+
+	// 按提供商名称覆盖，在全局限制之外单独限制该提供商
+	Providers map[string]ConcurrencyLimit `yaml:"providers"`
+}
+
+// ConcurrencyLimit 单个提供商的并发限制
+type ConcurrencyLimit struct {
+	MaxInFlight int `yaml:"max_in_flight"`
+	MaxQueue    int `yaml:"max_queue"`
+}
+
+// GetMaxInFlight 获取全局最大并发数，参数: 无，返回: 并发数 (默认 64)
+func (c *ConcurrencyConfig) GetMaxInFlight() int {
+	if c.MaxInFlight <= 0 {
+		return 64
+	}
+	return c.MaxInFlight
+}
+
+// GetQueueTimeout 获取排队超时，参数: 无，返回: 时长 (未配置或无效时为 0)
+func (c *ConcurrencyConfig) GetQueueTimeout() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.QueueTimeout))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// GetRetryAfter 获取拒绝时的重试间隔，参数: 无，返回: 秒数 (默认 1)
+func (c *ConcurrencyConfig) GetRetryAfter() int {
+	if c.RetryAfter <= 0 {
+		return 1
+	}
+	return c.RetryAfter
+}
+
+// CompressionConfig 响应压缩配置，仅压缩白名单内且超过阈值的响应
+type CompressionConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	MinLength    int      `yaml:"min_length"`    // 触发压缩的最小响应字节数，默认 1024
+	Level        int      `yaml:"level"`         // gzip 压缩级别 1-9，0 表示默认级别
+	Brotli       bool     `yaml:"brotli"`        // 客户端支持时优先使用 brotli
+	ContentTypes []string `yaml:"content_types"` // 允许压缩的媒体类型，默认 JSON、JavaScript、HTML 与纯文本
+}
+
+// GetMinLength 获取触发压缩的最小响应字节数，参数: 无，返回: 字节数 (默认 1024)
+func (c *CompressionConfig) GetMinLength() int {
+	if c.MinLength <= 0 {
+		return 1024
+	}
+	return c.MinLength
+}
+
+// GetContentTypes 获取允许压缩的媒体类型，参数: 无，返回: 小写的媒体类型列表
+func (c *CompressionConfig) GetContentTypes() []string {
+	if len(c.ContentTypes) == 0 {
+		return []string{"application/json", "text/javascript", "application/javascript", "text/html", "text/plain"}
+	}
+	types := make([]string, 0, len(c.ContentTypes))
+	for _, t := range c.ContentTypes {
+		types = append(types, strings.ToLower(strings.TrimSpace(t)))
+	}
+	return types
+}
+
+// OpsConfig 运维端点独立监听配置，启用后这些端点不再出现在翻译端口上
+type OpsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"` // 监听地址，默认 127.0.0.1:9090 (仅本机可访问)
+}
+
+// GetAddr 获取运维端点监听地址，参数: 无，返回: 地址 (默认 127.0.0.1:9090)
+func (c *OpsConfig) GetAddr() string {
+	if addr := strings.TrimSpace(c.Addr); addr != "" {
+		return addr
+	}
+	return "127.0.0.1:9090"
+}
+
+// TLSConfig HTTPS 监听配置，启用后服务只提供 HTTPS
+type TLSConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	CertFile     string   `yaml:"cert_file"`     // PEM 证书 (可包含中间证书链)
+	KeyFile      string   `yaml:"key_file"`      // PEM 私钥
+	MinVersion   string   `yaml:"min_version"`   // 最低 TLS 版本: 1.2 或 1.3，默认 1.2
+	CipherSuites []string `yaml:"cipher_suites"` // 可选：TLS 1.2 密码套件名称，为空使用 Go 默认值
+
+	ACME ACMEConfig `yaml:"acme"` // 自动申请证书，启用后不再读取 cert_file/key_file
+
+	// 客户端证书 (mTLS)，适合仅内部访问的部署
+	ClientAuth     string `yaml:"client_auth"`     // none (默认)、optional (提供则校验) 或 require (必须提供)
+	ClientCAFile   string `yaml:"client_ca_file"`  // 校验客户端证书的 CA 证书 (PEM，可包含多个)
+	ClientIdentity bool   `yaml:"client_identity"` // 以证书 CN 作为客户端标识，用于日志与额度
+}
+
+// tlsClientAuthModes 支持的客户端证书模式
+var tlsClientAuthModes = map[string]tls.ClientAuthType{
+	"":         tls.NoClientCert,
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"require":  tls.RequireAndVerifyClientCert,
+}
+
+// GetClientAuth 获取客户端证书校验模式，参数: 无，返回: crypto/tls 模式常量 (默认不要求证书)
+func (c *TLSConfig) GetClientAuth() tls.ClientAuthType {
+	return tlsClientAuthModes[strings.ToLower(strings.TrimSpace(c.ClientAuth))]
+}
+
+// MetricsConfig /metrics 端点配置，用户名密码与令牌都为空时端点不需要认证
+type MetricsConfig struct {
+	Username string `yaml:"username"` // Basic Auth 用户名
+	Password string `yaml:"password"` // Basic Auth 密码
+	Token    string `yaml:"token"`    // Bearer 令牌
+}
+
+// BasicAuthEnabled 是否配置了 Basic Auth，参数: 无，返回: 布尔
+func (c *MetricsConfig) BasicAuthEnabled() bool {
+	return strings.TrimSpace(c.Username) != "" && c.Password != ""
+}
+
+// AuthEnabled 是否需要认证，参数: 无，返回: 布尔
+func (c *MetricsConfig) AuthEnabled() bool {
+	return c.BasicAuthEnabled() || strings.TrimSpace(c.Token) != ""
+}
+
+// TracingConfig OpenTelemetry 分布式追踪配置，通过 OTLP/HTTP 导出
+type TracingConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	Endpoint    string            `yaml:"endpoint"`     // OTLP/HTTP 地址，如 http://otel-collector:4318；为空时读取 OTEL_EXPORTER_OTLP_ENDPOINT
+	ServiceName string            `yaml:"service_name"` // 上报的服务名，默认 translate-services
+	SampleRatio float64           `yaml:"sample_ratio"` // 根采样比例 (0, 1]，默认 1；已带采样决定的上游请求沿用其决定
+	Headers     map[string]string `yaml:"headers"`      // 导出时附加的请求头 (如认证令牌)
+}
+
+// GetServiceName 获取上报的服务名，参数: 无，返回: 服务名 (默认 translate-services)
+func (c *TracingConfig) GetServiceName() string {
+	if name := strings.TrimSpace(c.ServiceName); name != "" {
+		return name
+	}
+	return "translate-services"
+}
+
+// GetSampleRatio 获取根采样比例，参数: 无，返回: 比例 (默认 1)
+func (c *TracingConfig) GetSampleRatio() float64 {
+	if c.SampleRatio <= 0 {
+		return 1
+	}
+	return c.SampleRatio
+}
+
+// LoggingConfig 日志输出配置
+type LoggingConfig struct {
+	Format    string          `yaml:"format"`     // console 或 json，默认 console；接入 Loki/ELK 时使用 json
+	Output    string          `yaml:"output"`     // stdout、stderr 或文件路径，默认 stdout
+	AccessLog AccessLogConfig `yaml:"access_log"` // 访问日志单独写入文件
+
+	// 按组件覆盖日志级别 (access、cache、provider)，如 {access: warn, cache: debug}；运行时可通过管理接口调整
+	Levels map[string]string `yaml:"levels"`
+	// debug 事件采样：每 N 条保留 1 条，0 或 1 表示全部保留
+	SampleDebug int `yaml:"sample_debug"`
+}
+
+// logComponents 可单独设置级别的日志组件
+var logComponents = map[string]bool{"access": true, "cache": true, "provider": true}
+
+// logLevels 支持的日志级别名称
+var logLevels = map[string]bool{"trace": true, "debug": true, "info": true, "warn": true, "error": true, "disabled": true, "off": true}
+
+// AccessLogConfig 访问日志文件配置，按大小与保留天数轮转
+type AccessLogConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	File       string `yaml:"file"`         // 日志文件路径，默认 logs/access.log
+	MaxSizeMB  int    `yaml:"max_size_mb"`  // 单个文件达到该大小 (MB) 后轮转，默认 100
+	MaxAgeDays int    `yaml:"max_age_days"` // 轮转文件保留天数，0 表示不按天数清理
+	MaxBackups int    `yaml:"max_backups"`  // 最多保留的轮转文件数，0 表示不按数量清理
+	Compress   bool   `yaml:"compress"`     // 是否 gzip 压缩轮转后的文件
+}
+
+// GetFile 获取访问日志文件路径，参数: 无，返回: 路径 (默认 logs/access.log)
+func (c *AccessLogConfig) GetFile() string {
+	if file := strings.TrimSpace(c.File); file != "" {
+		return file
+	}
+	return "logs/access.log"
+}
+
+// GetMaxSizeMB 获取单个文件大小上限，参数: 无，返回: MB 数 (默认 100)
+func (c *AccessLogConfig) GetMaxSizeMB() int {
+	if c.MaxSizeMB <= 0 {
+		return 100
+	}
+	return c.MaxSizeMB
+}
+
+// ACMEConfig ACME (Let's Encrypt) 自动证书配置，通过 TLS-ALPN-01 在 HTTPS 端口上完成验证
+type ACMEConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Hosts        []string `yaml:"hosts"`         // 允许申请证书的域名白名单
+	CacheDir     string   `yaml:"cache_dir"`     // 证书缓存目录，默认 certs
+	Email        string   `yaml:"email"`         // 可选：证书到期等通知邮箱
+	DirectoryURL string   `yaml:"directory_url"` // 可选：ACME 目录地址，默认 Let's Encrypt 生产环境
+}
+
+// GetCacheDir 获取证书缓存目录，参数: 无，返回: 目录路径 (默认 certs)
+func (c *ACMEConfig) GetCacheDir() string {
+	if dir := strings.TrimSpace(c.CacheDir); dir != "" {
+		return dir
+	}
+	return "certs"
+}
+
+// RateLimitConfig 令牌桶限流配置 (按客户端 IP 独立计数喵～)
+type RateLimitConfig struct {
+	Enabled bool    `yaml:"enabled"` // 是否启用限流
+	RPS     float64 `yaml:"rps"`     // 每秒允许的请求数
+	Burst   int     `yaml:"burst"`   // 突发容量，默认取 rps 向上取整
+
+	// 按路由覆盖，键为路由路径 (如 /translate_a/single)，rps <= 0 表示该路由不限流
+	Routes map[string]RouteRateLimit `yaml:"routes"`
+}
+
+// RouteRateLimit 单个路由的限流覆盖
+type RouteRateLimit struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// AccessControlConfig 按客户端 IP 的访问控制，条目为 CIDR 或单个 IP
+// 命中 deny 的请求总是拒绝；allow 非空时只放行命中 allow 的请求
+type AccessControlConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Allow   []string `yaml:"allow"`
+	Deny    []string `yaml:"deny"`
+}
+
+// AutoBanConfig 自动临时封禁配置，客户端 IP 在窗口内产生过多 4xx 响应后被封禁一段时间
+type AutoBanConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Threshold int    `yaml:"threshold"` // 窗口内触发封禁的 4xx 响应数 (不含 429)，默认 20
+	Window    string `yaml:"window"`    // 计数窗口，默认 1m
+	Cooldown  string `yaml:"cooldown"`  // 封禁时长，默认 10m
+}
+
+// GetThreshold 获取触发封禁的违规次数，参数: 无，返回: 次数 (默认 20)
+func (c *AutoBanConfig) GetThreshold() int {
+	if c.Threshold <= 0 {
+		return 20
+	}
+	return c.Threshold
+}
+
+// GetWindow 获取计数窗口，参数: 无，返回: 时长 (默认 1 分钟)
+func (c *AutoBanConfig) GetWindow() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Window))
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// GetCooldown 获取封禁时长，参数: 无，返回: 时长 (默认 10 分钟)
+func (c *AutoBanConfig) GetCooldown() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Cooldown))
+	if err != nil || d <= 0 {
+		return 10 * time.Minute
+	}
+	return d
+}
+
+// GetAllow 解析允许的网段，参数: 无，返回: 网段列表与错误
+func (c *AccessControlConfig) GetAllow() ([]netip.Prefix, error) {
+	return ParsePrefixes(c.Allow)
+}
+
+// GetDeny 解析拒绝的网段，参数: 无，返回: 网段列表与错误
+func (c *AccessControlConfig) GetDeny() ([]netip.Prefix, error) {
+	return ParsePrefixes(c.Deny)
+}
+
+// ParsePrefixes 将 CIDR 或单个 IP 解析为网段，单个 IP 视为 /32 或 /128，参数: 条目列表，返回: 网段列表与错误
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 CIDR: %q", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 IP: %q", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// TranslationConfig 翻译服务配置 (灵活选择 API 地址与类型喵)
+type TranslationConfig struct {
+	ServiceType string `yaml:"service_type"`
+	APIKey      string `yaml:"api_key"`
+	BaseURL     string `yaml:"base_url"`
+	Model       string `yaml:"model"`   // 默认使用的模型 (如: gpt-3.5-turbo, gemini-1.5-pro-latest 等)
+	Timeout     int    `yaml:"timeout"` // 翻译请求超时 (秒)，默认 10
+
+	// 多个上游密钥轮换使用，与 api_key 合并
+	KeyRotation KeyRotationConfig `yaml:"key_rotation"`
+
+	// 上游密钥格式 (正则表达式)，为空时不校验；不接受空字符串时必须配置密钥
+	KeyPattern string `yaml:"key_pattern"`
+
+	// 多个中继地址，与 base_url 合并，按健康探测与延迟选择
+	Endpoints EndpointsConfig `yaml:"endpoints"`
+
+	// 提供商能力 (支持的语言、文本长度上限)，超出能力的请求不发往上游
+	Capabilities CapabilitiesConfig `yaml:"capabilities"`
+
+	// 按文本长度计算超时，取代 timeout 与 server.request_timeout
+	AdaptiveTimeout AdaptiveTimeoutConfig `yaml:"adaptive_timeout"`
+
+	// 提示词模板 (仅 openai 类型的大模型提供商使用)
+	Prompt PromptConfig `yaml:"prompt"`
+
+	// 署名配置 (部分提供商许可条款要求标注来源喵)
+	Attribution AttributionConfig `yaml:"attribution"`
+
+	// 语言代码别名 (如 cn → zh-CN)，在请求解析阶段应用，键不区分大小写
+	LanguageAliases map[string]string `yaml:"language_aliases"`
+
+	// 语言限制：只允许或禁止指定的源语言与目标语言
+	LanguagePolicy LanguagePolicyConfig `yaml:"language_policy"`
+
+	// 源语言与目标语言相同时直接返回原文，不调用上游也不写缓存
+	SkipSameLanguage bool `yaml:"skip_same_language"`
+
+	// 输入规范化：翻译与缓存之前统一几乎相同的输入，提高缓存命中率
+	Normalize NormalizeConfig `yaml:"normalize"`
+
+	// 额外的翻译提供商，顶层字段构成默认提供商 (名称为 service_type)
+	Providers []ProviderConfig `yaml:"providers"`
+
+	// 故障转移：主提供商出错或超时时依次改用后续提供商
+	Failover FailoverConfig `yaml:"failover"`
+
+	// 批量合并：短时间内到达的多段文本合并为一次上游调用
+	Batching BatchingConfig `yaml:"batching"`
+
+	// 术语表：产品名与固定术语在翻译前后保持指定译法
+	Glossary GlossaryConfig `yaml:"glossary"`
+
+	// 脏词过滤：按目标语言词表掩码或拒绝译文
+	Profanity ProfanityConfig `yaml:"profanity"`
+
+	// 翻译记忆：命中已确认的句段时不调用提供商
+	Memory TranslationMemoryConfig `yaml:"memory"`
+
+	// 中文标点规范化：目标语言为中文时把半角标点转换为全角
+	Punctuation PunctuationConfig `yaml:"punctuation"`
+
+	// 后处理规则：按语言对对译文依次做正则查找/替换
+	Rewrite RewriteConfig `yaml:"rewrite"`
+
+	// 对冲请求：主提供商迟迟未返回时同时请求备用提供商
+	Hedging HedgingConfig `yaml:"hedging"`
+
+	// 对比模式：请求带 compare 参数时同时请求对比提供商，返回两份译文与相似度
+	Comparison ComparisonConfig `yaml:"comparison"`
+
+	// 影子流量：按比例把请求异步镜像到影子提供商，记录两份译文与相似度，不影响响应
+	Shadow ShadowConfig `yaml:"shadow"`
+
+	// 按请求指定提供商：请求带 provider 参数时改用允许列表中的提供商
+	ProviderOverride ProviderOverrideConfig `yaml:"provider_override"`
+
+	// 规则路由：按语言对、文本长度与 dt 为请求选择提供商与模型
+	Routing RoutingConfig `yaml:"routing"`
+
+	// 金丝雀分流：按客户端把一定比例的请求分给新的提供商
+	Canary CanaryConfig `yaml:"canary"`
+
+	// 熔断：每个提供商单独统计，失败率或慢调用率过高时暂停调用
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// 重试预算：全部提供商共享，上游故障时把重试量限制在流量的一小部分
+	RetryBudget RetryBudgetConfig `yaml:"retry_budget"`
+
+	// 健康检查：后台定期探测各提供商，结果用于 /readyz、熔断与故障转移
+	HealthCheck HealthCheckConfig `yaml:"health_check"`
+}
+
+// BatchingConfig 批量合并配置
+type BatchingConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	MaxSize int    `yaml:"max_size"` // 每次上游调用最多包含的文本段数，默认 16
+	MaxWait string `yaml:"max_wait"` // 收集同批文本的最长等待时间，默认 10ms
+}
+
+// GetMaxSize 获取每批最多段数，参数: 无，返回: 段数 (默认 16)
+func (c *BatchingConfig) GetMaxSize() int {
+	if c.MaxSize <= 0 {
+		return 16
+	}
+	return c.MaxSize
+}
+
+// GetMaxWait 获取最长等待时间，参数: 无，返回: 时长 (默认 10 毫秒)
+func (c *BatchingConfig) GetMaxWait() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.MaxWait))
+	if err != nil || d <= 0 {
+		return 10 * time.Millisecond
+	}
+	return d
+}
+
+// GlossaryConfig 术语表配置，术语来源依次为 sets、file 与 Redis，后者覆盖前者的同名术语
+type GlossaryConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Sets        []GlossarySet `yaml:"sets"`         // 内联术语集
+	File        string        `yaml:"file"`         // 术语集 YAML 文件 (格式同 sets)
+	Redis       bool          `yaml:"redis"`        // 同时读取 Redis 哈希中的术语 (需启用 cache)
+	RedisPrefix string        `yaml:"redis_prefix"` // Redis 哈希键前缀，默认 glossary:
+}
+
+// 脏词过滤模式
+const (
+	ProfanityModeMask   = "mask"   // 命中的词语替换为 *
+	ProfanityModeReject = "reject" // 拒绝整个译文
+)
+
+// ProfanityConfig 译文脏词过滤配置，词表来源为 words 与 file，两者合并
+type ProfanityConfig struct {
+	Enabled bool                `yaml:"enabled"`
+	Mode    string              `yaml:"mode"`  // mask (默认) 或 reject
+	Words   map[string][]string `yaml:"words"` // 目标语言 → 词语列表，* 表示所有语言
+	File    string              `yaml:"file"`  // 词表 YAML 文件 (格式同 words)
+}
+
+// GetMode 获取过滤模式，参数: 无，返回: 模式 (默认 mask)
+func (c *ProfanityConfig) GetMode() string {
+	if mode := strings.ToLower(strings.TrimSpace(c.Mode)); mode != "" {
+		return mode
+	}
+	return ProfanityModeMask
+}
+
+// emoji 处理方式
+const (
+	EmojiKeep  = "keep"  // 原样交给提供商
+	EmojiStrip = "strip" // 翻译前去掉
+)
+
+// NormalizeConfig 输入规范化配置，在翻译记忆、术语表与缓存之前应用，各项可单独开启
+type NormalizeConfig struct {
+	NFC                bool   `yaml:"nfc"`                 // Unicode NFC 规范化
+	CollapseWhitespace bool   `yaml:"collapse_whitespace"` // 行内连续空白折叠为一个空格并去掉行尾空白
+	StripZeroWidth     bool   `yaml:"strip_zero_width"`    // 去掉零宽空格、BOM 与词连接符
+	Emoji              string `yaml:"emoji"`               // keep (默认) 或 strip
+}
+
+// GetEmoji 获取 emoji 处理方式，参数: 无，返回: 处理方式 (默认 keep)
+func (c *NormalizeConfig) GetEmoji() string {
+	if emoji := strings.ToLower(strings.TrimSpace(c.Emoji)); emoji != "" {
+		return emoji
+	}
+	return EmojiKeep
+}
+
+// PunctuationConfig 中文标点规范化配置，目标语言为 zh、zh-CN、zh-TW 等中文时对译文做后处理
+type PunctuationConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	CJKSpacing bool `yaml:"cjk_spacing"` // 同时在汉字与英文字母、数字之间插入空格
+}
+
+// RewriteConfig 译文后处理规则配置，规则按顺序应用，前一条的结果作为后一条的输入
+type RewriteConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Rules   []RewriteRule `yaml:"rules"`
+}
+
+// RewriteRule 一条正则查找/替换规则
+type RewriteRule struct {
+	Source  string `yaml:"source"`  // 源语言代码，* 或留空表示任意源语言
+	Target  string `yaml:"target"`  // 目标语言代码，* 或留空表示任意目标语言
+	Pattern string `yaml:"pattern"` // 正则表达式 (Go RE2 语法)
+	Replace string `yaml:"replace"` // 替换文本，可用 $1、${name} 引用分组
+}
+
+// TranslationMemoryConfig 翻译记忆配置，记忆保存在进程内，启动时从 file 载入，运行期间可通过管理接口导入与导出 TMX
+type TranslationMemoryConfig struct {
+	Enabled  bool    `yaml:"enabled"`
+	File     string  `yaml:"file"`      // 启动时载入的 TMX 文件
+	MinScore float64 `yaml:"min_score"` // 模糊匹配的最低相似度 (0-1]，默认 1 即只做完全匹配
+}
+
+// GetMinScore 获取模糊匹配阈值，参数: 无，返回: 阈值 (默认 1)
+func (c *TranslationMemoryConfig) GetMinScore() float64 {
+	if c.MinScore <= 0 {
+		return 1
+	}
+	return c.MinScore
+}
+
+// GlossarySet 一个语言对的术语集
+type GlossarySet struct {
+	Source string            `yaml:"source"` // 源语言代码，* 或留空表示任意源语言 (包括自动检测)
+	Target string            `yaml:"target"` // 目标语言代码
+	Terms  map[string]string `yaml:"terms"`  // 术语 → 译法
+}
+
+// HedgingConfig 对冲请求配置，适合对延迟敏感的部署
+type HedgingConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"` // 备用提供商名称，必须是 providers 中的一个
+	Delay    string `yaml:"delay"`    // 主提供商超过该时长未返回时发起对冲，默认 300ms
+}
+
+// GetDelay 获取对冲延迟，参数: 无，返回: 时长 (默认 300 毫秒)
+func (c *HedgingConfig) GetDelay() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Delay))
+	if err != nil || d <= 0 {
+		return 300 * time.Millisecond
+	}
+	return d
+}
+
+// ComparisonConfig 双提供商对比配置，用于切换提供商前评估译文差异
+type ComparisonConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"` // 对比提供商名称，必须是 providers 中的一个
+}
+
+// ShadowConfig 影子流量配置，用于积累提供商译文质量的对比数据
+// 主提供商成功返回后，按比例在后台把同一请求发给影子提供商，客户端的响应与延迟不受影响；缓存命中的请求不镜像
+type ShadowConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Provider    string  `yaml:"provider"`      // 影子提供商名称，必须是 providers 中的一个
+	Model       string  `yaml:"model"`         // 影子请求使用的模型，为空时使用提供商默认模型
+	SampleRate  float64 `yaml:"sample_rate"`   // 镜像比例 (0-1]，默认 0.1
+	MaxInFlight int     `yaml:"max_in_flight"` // 同时进行的影子请求上限，超出时丢弃本次镜像，默认 4
+	Output      string  `yaml:"output"`        // 对比数据集文件 (JSON Lines，追加写入)，为空时只写日志
+}
+
+// GetSampleRate 获取镜像比例，参数: 无，返回: 比例 (默认 0.1)
+func (c *ShadowConfig) GetSampleRate() float64 {
+	if c.SampleRate > 0 {
+		return c.SampleRate
+	}
+	return 0.1
+}
+
+// GetMaxInFlight 获取同时进行的影子请求上限，参数: 无，返回: 上限 (默认 4)
+func (c *ShadowConfig) GetMaxInFlight() int {
+	if c.MaxInFlight > 0 {
+		return c.MaxInFlight
+	}
+	return 4
+}
+
+// ProviderOverrideConfig 按请求指定提供商的配置
+type ProviderOverrideConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Allow   []string `yaml:"allow"` // 允许指定的提供商名称，为空时允许全部已配置的提供商
+}
+
+// OverrideProviders 返回请求可以指定的提供商配置，参数: 无，返回: 提供商配置切片 (未启用时为空)
+func (t *TranslationConfig) OverrideProviders() []ProviderConfig {
+	if !t.ProviderOverride.Enabled {
+		return nil
+	}
+	if len(t.ProviderOverride.Allow) == 0 {
+		return t.ProviderConfigs()
+	}
+	var providers []ProviderConfig
+	for _, name := range t.ProviderOverride.Allow {
+		if p, ok := t.FindProvider(name); ok {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// RoutingConfig 规则路由配置，规则按顺序匹配，第一条匹配的规则生效，都不匹配时使用默认提供商
+type RoutingConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Rules   []RoutingRule `yaml:"rules"`
+}
+
+// RoutingRule 一条路由规则，未设置的条件视为满足
+type RoutingRule struct {
+	Name      string   `yaml:"name"`       // 规则名称，用于日志与指标，默认 rules[序号]
+	Sources   []string `yaml:"sources"`    // 源语言列表，只写主语言时匹配其地区变体
+	Targets   []string `yaml:"targets"`    // 目标语言列表
+	MinLength int      `yaml:"min_length"` // 文本最少字符数
+	MaxLength int      `yaml:"max_length"` // 文本最多字符数，0 表示不限
+	DT        []string `yaml:"dt"`         // 请求的 dt 包含其中任一类型时匹配
+	Provider  string   `yaml:"provider"`   // 使用的提供商名称 (service_type 或 providers 中的 name)
+	Model     string   `yaml:"model"`      // 使用的模型，为空时使用提供商默认模型
+}
+
+// GetName 获取规则名称，参数: 规则序号，返回: 名称
+func (r *RoutingRule) GetName(index int) string {
+	if name := strings.TrimSpace(r.Name); name != "" {
+		return name
+	}
+	return fmt.Sprintf("rules[%d]", index)
+}
+
+// Match 返回第一条匹配请求的规则，参数: 源语言 (无法确定时为空，此时限定源语言的规则不匹配)、目标语言、文本字符数与 dt，返回: 规则序号与是否匹配
+func (c *RoutingConfig) Match(source, target string, length int, dt []string) (int, bool) {
+	if !c.Enabled {
+		return 0, false
+	}
+	source, target = normalizePolicyCode(source), normalizePolicyCode(target)
+	for i, rule := range c.Rules {
+		if len(rule.Sources) > 0 && !matchesAny(rule.Sources, source) {
+			continue
+		}
+		if len(rule.Targets) > 0 && !matchesAny(rule.Targets, target) {
+			continue
+		}
+		if length < rule.MinLength || (rule.MaxLength > 0 && length > rule.MaxLength) {
+			continue
+		}
+		if len(rule.DT) > 0 && !containsAnyFold(rule.DT, dt) {
+			continue
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// RoutingProviders 返回路由规则引用的提供商配置 (去重，保持规则顺序)，参数: 无，返回: 提供商配置切片 (未启用时为空)
+func (t *TranslationConfig) RoutingProviders() []ProviderConfig {
+	if !t.Routing.Enabled {
+		return nil
+	}
+	var providers []ProviderConfig
+	seen := make(map[string]bool)
+	for _, rule := range t.Routing.Rules {
+		p, ok := t.FindProvider(rule.Provider)
+		if !ok || seen[p.GetName()] {
+			continue
+		}
+		seen[p.GetName()] = true
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// CanaryConfig 金丝雀分流配置，正式切换默认提供商前按比例把部分客户端的请求分给新的提供商评估
+// 按客户端身份 (API Key 或 IP) 哈希分桶，同一客户端总是落在同一侧；指定提供商或匹配路由规则的请求不参与分流
+type CanaryConfig struct {
+	Enabled  bool    `yaml:"enabled"`
+	Provider string  `yaml:"provider"` // 接收分流的提供商名称，必须是 providers 中的一个
+	Model    string  `yaml:"model"`    // 分流请求使用的模型，为空时使用提供商默认模型
+	Percent  float64 `yaml:"percent"`  // 分流比例 (0-100)，如 5 表示约 5% 的客户端
+	Salt     string  `yaml:"salt"`     // 分桶的盐，修改后客户端重新分桶
+}
+
+// canaryBuckets 分桶数量，分流比例精确到 0.01%
+const canaryBuckets = 10000
+
+// Includes 判断客户端是否落在分流比例内，参数: 客户端身份，返回: 布尔 (未启用时为 false)
+func (c *CanaryConfig) Includes(client string) bool {
+	if !c.Enabled || c.Percent <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(c.Salt))
+	h.Write([]byte{0})
+	h.Write([]byte(client))
+	return float64(h.Sum32()%canaryBuckets) < c.Percent*canaryBuckets/100
+}
+
+// CanaryProviders 返回接收金丝雀分流的提供商配置，参数: 无，返回: 提供商配置切片 (未启用时为空)
+func (t *TranslationConfig) CanaryProviders() []ProviderConfig {
+	if !t.Canary.Enabled {
+		return nil
+	}
+	if p, ok := t.FindProvider(t.Canary.Provider); ok {
+		return []ProviderConfig{p}
+	}
+	return nil
+}
+
+// matchesAny 判断语言代码是否匹配列表中的任一条目，参数: 列表与规范化的代码，返回: 布尔
+func matchesAny(entries []string, code string) bool {
+	for _, entry := range entries {
+		if policyMatch(entry, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAnyFold 判断两个列表是否有相同的元素 (不区分大小写)，参数: 两个列表，返回: 布尔
+func containsAnyFold(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(strings.TrimSpace(w), strings.TrimSpace(h)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CircuitBreakerConfig 提供商熔断配置，每个提供商各有一个熔断器
+type CircuitBreakerConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	Window         int     `yaml:"window"`           // 统计最近多少次调用，默认 20
+	MinCalls       int     `yaml:"min_calls"`        // 窗口内至少多少次调用才判断阈值，默认 10
+	ErrorRate      float64 `yaml:"error_rate"`       // 失败率阈值 (0, 1]，默认 0.5
+	SlowCall       string  `yaml:"slow_call"`        // 慢调用阈值 (如 2s)，为空不统计慢调用
+	SlowRate       float64 `yaml:"slow_rate"`        // 慢调用率阈值 (0, 1]，默认 0.5
+	OpenDuration   string  `yaml:"open_duration"`    // 断开后多久进入半开探测，默认 30s
+	HalfOpenProbes int     `yaml:"half_open_probes"` // 半开状态放行的探测次数，全部成功后恢复，默认 1
+}
+
+// GetSlowCall 获取慢调用阈值，参数: 无，返回: 时长 (未配置或无效时为 0)
+func (c *CircuitBreakerConfig) GetSlowCall() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.SlowCall))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// GetOpenDuration 获取断开时长，参数: 无，返回: 时长 (默认 30 秒)
+func (c *CircuitBreakerConfig) GetOpenDuration() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.OpenDuration))
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// HealthCheckConfig 提供商后台健康检查配置
+// 连续失败达到阈值的提供商被标记为不健康：熔断器立即断开、故障转移时跳过，/readyz 返回 503；一次探测成功即恢复
+type HealthCheckConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	Interval         string `yaml:"interval"`          // 探测间隔，默认 30s
+	Timeout          string `yaml:"timeout"`           // 单次探测超时，默认 5s
+	FailureThreshold int    `yaml:"failure_threshold"` // 连续失败多少次后标记为不健康，默认 2
+}
+
+// GetInterval 获取探测间隔，参数: 无，返回: 时长 (默认 30 秒)
+func (c *HealthCheckConfig) GetInterval() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Interval))
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// GetTimeout 获取单次探测超时，参数: 无，返回: 时长 (默认 5 秒)
+func (c *HealthCheckConfig) GetTimeout() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Timeout))
+	if err != nil || d <= 0 {
+		return 5 * time.Second
+	}
+	return d
+}
+
+// GetFailureThreshold 获取标记为不健康所需的连续失败次数，参数: 无，返回: 次数 (默认 2)
+func (c *HealthCheckConfig) GetFailureThreshold() int {
+	if c.FailureThreshold <= 0 {
+		return 2
+	}
+	return c.FailureThreshold
+}
+
+// RetryBudgetConfig 跨请求共享的重试预算 (令牌桶)：每个请求存入 ratio 个令牌，每次重试消耗一个
+type RetryBudgetConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	Ratio        float64 `yaml:"ratio"`          // 每个请求存入的令牌数，即重试占流量的比例 [0, 1]，默认 0.1
+	MinPerSecond float64 `yaml:"min_per_second"` // 每秒额外补充的令牌数，保证低流量时仍可重试，默认 1
+	MaxTokens    float64 `yaml:"max_tokens"`     // 令牌上限 (允许的突发重试数)，默认 10
+}
+
+// GetRatio 获取每个请求存入的令牌数，参数: 无，返回: 比例 (默认 0.1)
+func (c *RetryBudgetConfig) GetRatio() float64 {
+	if c.Ratio <= 0 {
+		return 0.1
+	}
+	return c.Ratio
+}
+
+// GetMinPerSecond 获取每秒补充的令牌数，参数: 无，返回: 令牌数 (默认 1)
+func (c *RetryBudgetConfig) GetMinPerSecond() float64 {
+	if c.MinPerSecond <= 0 {
+		return 1
+	}
+	return c.MinPerSecond
+}
+
+// GetMaxTokens 获取令牌上限，参数: 无，返回: 令牌数 (默认 10)
+func (c *RetryBudgetConfig) GetMaxTokens() float64 {
+	if c.MaxTokens <= 0 {
+		return 10
+	}
+	return c.MaxTokens
+}
+
+// FailoverConfig 提供商故障转移配置
+type FailoverConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Order          []string `yaml:"order"`           // 尝试顺序 (提供商名称)，为空时按默认提供商在前、providers 依次在后
+	AttemptTimeout string   `yaml:"attempt_timeout"` // 单个提供商的尝试超时，为空时只受 server.request_timeout 约束
+}
+
+// GetAttemptTimeout 获取单次尝试超时，参数: 无，返回: 时长 (未配置或无效时为 0)
+func (c *FailoverConfig) GetAttemptTimeout() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.AttemptTimeout))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
 }
 
-// ServerConfig 服务器配置 (超时与性能相关喵～)
-type ServerConfig struct {
-	RequestTimeout  int `yaml:"request_timeout"`  // 翻译请求超时 (秒)，默认 8
-	MiddlewareTimeout int `yaml:"middleware_timeout"` // 中间件超时 (秒)，默认 12
-	ShutdownTimeout int `yaml:"shutdown_timeout"` // 优雅停机超时 (秒)，默认 15
+// FailoverProviders 返回故障转移链中的提供商配置，参数: 无，返回: 按尝试顺序排列的提供商配置 (名称无效的条目被忽略)
+func (t *TranslationConfig) FailoverProviders() []ProviderConfig {
+	if len(t.Failover.Order) == 0 {
+		return t.ProviderConfigs()
+	}
+	providers := make([]ProviderConfig, 0, len(t.Failover.Order))
+	for _, name := range t.Failover.Order {
+		if p, ok := t.FindProvider(name); ok {
+			providers = append(providers, p)
+		}
+	}
+	return providers
 }
 
-// TranslationConfig 翻译服务配置 (灵活选择 API 地址与类型喵)
-type TranslationConfig struct {
-	ServiceType string `yaml:"service_type"`
+// ProviderConfig 单个翻译提供商配置
+type ProviderConfig struct {
+	Name        string `yaml:"name"`         // 提供商名称 (唯一)，默认取 service_type
+	ServiceType string `yaml:"service_type"` // 提供商类型，如 deeplx
 	APIKey      string `yaml:"api_key"`
 	BaseURL     string `yaml:"base_url"`
-	Model       string `yaml:"model"`   // 默认使用的模型 (如: gpt-3.5-turbo, gemini-1.5-pro-latest 等)
-	Timeout     int    `yaml:"timeout"` // 翻译请求超时 (秒)，默认 10
+	Model       string `yaml:"model"`   // 该提供商的默认模型，未设置时使用 translation.model
+	Timeout     int    `yaml:"timeout"` // 翻译请求超时 (秒)
+
+	KeyRotation     KeyRotationConfig     `yaml:"key_rotation"`     // 多个上游密钥轮换使用
+	KeyPattern      string                `yaml:"key_pattern"`      // 上游密钥格式 (正则表达式)
+	Endpoints       EndpointsConfig       `yaml:"endpoints"`        // 多个中继地址
+	Capabilities    CapabilitiesConfig    `yaml:"capabilities"`     // 提供商能力
+	AdaptiveTimeout AdaptiveTimeoutConfig `yaml:"adaptive_timeout"` // 按文本长度计算超时
+	Prompt          PromptConfig          `yaml:"prompt"`           // 提示词模板 (仅 openai 类型)
+}
+
+// PromptConfig 大模型提供商的提示词模板 (Go text/template 语法)，可用 {{.Text}}、{{.SourceLang}}、{{.TargetLang}} 等占位符，为空时使用内置模板
+type PromptConfig struct {
+	System string `yaml:"system"` // 系统提示词
+	User   string `yaml:"user"`   // 用户提示词，默认为 {{.Text}}
+}
+
+// CapabilitiesConfig 提供商能力配置，覆盖提供商类型的默认能力，调用上游前按此校验请求
+type CapabilitiesConfig struct {
+	Languages     []string `yaml:"languages"`       // 支持的语言 (主语言代码)，为空时使用提供商类型的默认列表，含 "*" 时不限制
+	MaxTextLength int      `yaml:"max_text_length"` // 单次请求文本的最大字符数，0 表示不限制
+}
+
+// AdaptiveTimeoutConfig 按文本长度计算的超时：base + 每 KB (UTF-8) 增加 per_kb，不超过 max
+type AdaptiveTimeoutConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Base    string `yaml:"base"`   // 基础超时，默认 2s
+	PerKB   string `yaml:"per_kb"` // 每 KB 增加的超时，默认 1s
+	Max     string `yaml:"max"`    // 上限，默认 60s
+}
+
+// GetBase 获取基础超时，参数: 无，返回: 时长 (默认 2 秒)
+func (c *AdaptiveTimeoutConfig) GetBase() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Base))
+	if err != nil || d <= 0 {
+		return 2 * time.Second
+	}
+	return d
+}
+
+// GetPerKB 获取每 KB 增加的超时，参数: 无，返回: 时长 (默认 1 秒)
+func (c *AdaptiveTimeoutConfig) GetPerKB() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.PerKB))
+	if err != nil || d <= 0 {
+		return time.Second
+	}
+	return d
+}
+
+// GetMax 获取超时上限，参数: 无，返回: 时长 (默认 60 秒)
+func (c *AdaptiveTimeoutConfig) GetMax() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Max))
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// HasAPIKey 是否配置了至少一个上游密钥，参数: 无，返回: 布尔
+func (p *ProviderConfig) HasAPIKey() bool {
+	return strings.TrimSpace(p.APIKey) != "" || len(p.KeyRotation.Keys) > 0
+}
+
+// HasCustomEndpoint 是否配置了自定义的上游地址 (base_url 或 endpoints.urls)，参数: 无，返回: 布尔
+// 自建中继可能无需密钥；使用默认公共地址时必须配置密钥
+func (p *ProviderConfig) HasCustomEndpoint() bool {
+	return strings.TrimSpace(p.BaseURL) != "" || len(p.Endpoints.URLs) > 0
+}
+
+// KeyRotationConfig 同一提供商多个上游密钥的轮换配置
+// 返回鉴权或额度错误 (401/403/429/456) 的密钥被隔离一段时间，期间请求落在其他密钥上
+type KeyRotationConfig struct {
+	Keys       []UpstreamKey `yaml:"keys"`
+	Strategy   string        `yaml:"strategy"`   // round_robin (默认) 或 weighted
+	Quarantine string        `yaml:"quarantine"` // 被拒绝密钥的隔离时长，默认 5m
+}
+
+// UpstreamKey 上游密钥及其权重
+type UpstreamKey struct {
+	Key    string `yaml:"key"`
+	Weight int    `yaml:"weight"` // weighted 策略下的权重，默认 1
+}
+
+// GetQuarantine 获取隔离时长，参数: 无，返回: 时长 (默认 5 分钟)
+func (c *KeyRotationConfig) GetQuarantine() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Quarantine))
+	if err != nil || d <= 0 {
+		return 5 * time.Minute
+	}
+	return d
+}
+
+// EndpointsConfig 同一提供商多个中继地址的配置 (公共 DeepLX 中继不稳定时使用)
+// 每次请求选择未被剔除且延迟最低的地址；连接失败、超时或 5xx 连续达到上限的地址被剔除一段时间，探测成功后恢复
+type EndpointsConfig struct {
+	URLs          []string `yaml:"urls"`           // 中继地址，与 base_url 合并去重
+	ProbeInterval string   `yaml:"probe_interval"` // 健康探测间隔，默认 30s
+	ProbeTimeout  string   `yaml:"probe_timeout"`  // 单次探测超时，默认 5s
+	MaxFailures   int      `yaml:"max_failures"`   // 连续失败多少次后剔除，默认 3
+	Eviction      string   `yaml:"eviction"`       // 剔除时长，默认 1m
+}
+
+// GetProbeInterval 获取探测间隔，参数: 无，返回: 时长 (默认 30 秒)
+func (c *EndpointsConfig) GetProbeInterval() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.ProbeInterval))
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// GetProbeTimeout 获取探测超时，参数: 无，返回: 时长 (默认 5 秒)
+func (c *EndpointsConfig) GetProbeTimeout() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.ProbeTimeout))
+	if err != nil || d <= 0 {
+		return 5 * time.Second
+	}
+	return d
+}
+
+// GetMaxFailures 获取剔除前的连续失败次数，参数: 无，返回: 次数 (默认 3)
+func (c *EndpointsConfig) GetMaxFailures() int {
+	if c.MaxFailures <= 0 {
+		return 3
+	}
+	return c.MaxFailures
+}
+
+// GetEviction 获取剔除时长，参数: 无，返回: 时长 (默认 1 分钟)
+func (c *EndpointsConfig) GetEviction() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Eviction))
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// GetName 获取提供商名称，未设置时使用服务类型，返回: 小写名称
+func (p *ProviderConfig) GetName() string {
+	name := strings.TrimSpace(p.Name)
+	if name == "" {
+		name = strings.TrimSpace(p.ServiceType)
+	}
+	return strings.ToLower(name)
+}
+
+// DefaultProvider 返回由顶层字段构成的默认提供商配置，参数: 无，返回: 提供商配置
+func (t *TranslationConfig) DefaultProvider() ProviderConfig {
+	return ProviderConfig{
+		Name:        t.ServiceType,
+		ServiceType: t.ServiceType,
+		APIKey:      t.APIKey,
+		BaseURL:     t.BaseURL,
+		Model:       t.Model,
+		Timeout:     t.Timeout,
+		KeyRotation: t.KeyRotation,
+		KeyPattern:  t.KeyPattern,
+		Endpoints:   t.Endpoints,
+
+		Capabilities:    t.Capabilities,
+		AdaptiveTimeout: t.AdaptiveTimeout,
+		Prompt:          t.Prompt,
+	}
+}
+
+// ProviderConfigs 返回全部提供商配置 (默认提供商在首位)，参数: 无，返回: 提供商配置切片
+func (t *TranslationConfig) ProviderConfigs() []ProviderConfig {
+	providers := make([]ProviderConfig, 0, len(t.Providers)+1)
+	providers = append(providers, t.DefaultProvider())
+	return append(providers, t.Providers...)
+}
+
+// FindProvider 按名称查找提供商配置，参数: 提供商名称，返回: 提供商配置与是否找到
+func (t *TranslationConfig) FindProvider(name string) (ProviderConfig, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range t.ProviderConfigs() {
+		if p.GetName() == name {
+			return p, true
+		}
+	}
+	return ProviderConfig{}, false
+}
+
+// ResolveLanguageAlias 将自定义别名解析为标准语言代码，参数: 客户端传入的代码，返回: 解析后的代码 (无别名时原样返回)
+func (t *TranslationConfig) ResolveLanguageAlias(code string) string {
+	if len(t.LanguageAliases) == 0 {
+		return code
+	}
+	trimmed := strings.TrimSpace(code)
+	if target, ok := t.LanguageAliases[trimmed]; ok {
+		return target
+	}
+	for alias, target := range t.LanguageAliases {
+		if strings.EqualFold(alias, trimmed) {
+			return target
+		}
+	}
+	return code
+}
+
+// LanguagePolicyConfig 语言限制配置，列表为空表示不限制，同时设置 allow 与 deny 时两者都需满足
+// 条目不区分大小写，只写主语言 (如 zh) 时匹配其所有地区变体 (zh-CN、zh-TW)
+type LanguagePolicyConfig struct {
+	AllowSources []string `yaml:"allow_sources"` // 允许的源语言，自动检测 (auto 或留空) 不受限制
+	DenySources  []string `yaml:"deny_sources"`  // 禁止的源语言
+	AllowTargets []string `yaml:"allow_targets"` // 允许的目标语言
+	DenyTargets  []string `yaml:"deny_targets"`  // 禁止的目标语言
+}
+
+// AllowsSource 判断源语言是否允许，参数: 源语言代码，返回: 布尔 (自动检测始终允许)
+func (p *LanguagePolicyConfig) AllowsSource(code string) bool {
+	normalized := normalizePolicyCode(code)
+	if normalized == "" || normalized == "auto" {
+		return true
+	}
+	return allowedByLists(normalized, p.AllowSources, p.DenySources)
+}
+
+// AllowsTarget 判断目标语言是否允许，参数: 目标语言代码，返回: 布尔
+func (p *LanguagePolicyConfig) AllowsTarget(code string) bool {
+	return allowedByLists(normalizePolicyCode(code), p.AllowTargets, p.DenyTargets)
+}
+
+// allowedByLists 按允许与禁止列表判断语言，参数: 规范化的代码、允许列表与禁止列表，返回: 布尔
+func allowedByLists(code string, allow, deny []string) bool {
+	for _, entry := range deny {
+		if policyMatch(entry, code) {
+			return false
+		}
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, entry := range allow {
+		if policyMatch(entry, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyMatch 判断列表条目是否匹配语言代码，主语言条目匹配其地区变体，参数: 条目与规范化的代码，返回: 布尔
+func policyMatch(entry, code string) bool {
+	entry = normalizePolicyCode(entry)
+	return entry != "" && (code == entry || strings.HasPrefix(code, entry+"-"))
+}
+
+// normalizePolicyCode 规范化语言代码 (小写，下划线改为连字符)，参数: 语言代码，返回: 规范化的代码
+func normalizePolicyCode(code string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(code)), "_", "-")
+}
+
+// ResolveModel 解析实际使用的模型，参数: 提供商名称与请求指定的模型，返回: 模型名称
+// 优先级: 请求指定 > 提供商默认 > translation.model
+func (t *TranslationConfig) ResolveModel(provider, requested string) string {
+	if strings.TrimSpace(requested) != "" {
+		return requested
+	}
+	if p, ok := t.FindProvider(provider); ok && strings.TrimSpace(p.Model) != "" {
+		return p.Model
+	}
+	return t.Model
+}
+
+// AttributionConfig 翻译署名配置，可注入响应头、JSON 字段或 HTML 注释
+type AttributionConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用署名
+	Text    string `yaml:"text"`    // 署名文本，支持 {provider} 占位符
+	Header  string `yaml:"header"`  // 响应头名称，默认 X-Translated-By
+	Field   bool   `yaml:"field"`   // 是否在 JSON 响应中加入 attribution 字段
+	HTML    bool   `yaml:"html"`    // 是否在文档翻译 HTML 末尾追加注释
+}
+
+// 署名默认值
+const (
+	defaultAttributionText   = "translated by {provider} via translate-services"
+	defaultAttributionHeader = "X-Translated-By"
+)
+
+// Render 渲染署名文本，参数: 提供商名称，返回: 替换占位符后的文本
+func (a *AttributionConfig) Render(provider string) string {
+	text := strings.TrimSpace(a.Text)
+	if text == "" {
+		text = defaultAttributionText
+	}
+	return strings.ReplaceAll(text, "{provider}", provider)
+}
+
+// GetHeader 获取署名响应头名称，返回: 头名称
+func (a *AttributionConfig) GetHeader() string {
+	if strings.TrimSpace(a.Header) == "" {
+		return defaultAttributionHeader
+	}
+	return a.Header
 }
 
 // CacheConfig Redis 缓存配置 (提升性能，减少 API 调用喵～)
@@ -57,8 +1258,11 @@ type CacheConfig struct {
 	DB       int    `yaml:"db"`       // 数据库编号
 
 	// 缓存策略
-	TTL                 string `yaml:"ttl"`                    // 缓存过期时间，如 "24h"，空或 "0" 表示永不过期
+	TTL                 string `yaml:"ttl"`                   // 缓存过期时间，如 "24h"，空或 "0" 表示永不过期
 	ShareAcrossServices bool   `yaml:"share_across_services"` // 不同服务共享缓存
+	CacheDetection      bool   `yaml:"cache_detection"`       // 单独缓存自动检测的源语言
+	DetectionTTL        string `yaml:"detection_ttl"`         // 检测结果过期时间，默认 720h
+	StatsInterval       string `yaml:"stats_interval"`        // 条目数、值大小与命中率指标的采样间隔，默认 1m
 
 	// 连接池配置
 	PoolSize     int `yaml:"pool_size"`     // 连接池大小，默认 10
@@ -67,6 +1271,202 @@ type CacheConfig struct {
 	WriteTimeout int `yaml:"write_timeout"` // 写入超时 (秒)，默认 3
 }
 
+// QuotaConfig 每日字符额度配置 (按客户端统计喵～)
+type QuotaConfig struct {
+	Enabled     bool  `yaml:"enabled"`      // 是否启用额度统计
+	DailyChars  int64 `yaml:"daily_chars"`  // 每个客户端每日字符额度
+	WarnPercent int   `yaml:"warn_percent"` // 剩余额度低于该百分比时返回 X-Quota-Warning，默认 10
+	Enforce     bool  `yaml:"enforce"`      // 超出额度后拒绝请求 (429 QUOTA_EXCEEDED)，否则只返回预警头
+}
+
+// UsageConfig 上游用量统计配置 (按提供商、模型与客户端累计字符数，用于核对上游账单)
+// 缓存使用 Redis 时计数写入 Redis 并在多实例间共享，否则保存在进程内
+type UsageConfig struct {
+	Enabled  bool         `yaml:"enabled"`  // 是否统计用量并开放 GET /admin/usage
+	Currency string       `yaml:"currency"` // 价格的货币单位，只用于展示，默认 USD
+	Pricing  []UsagePrice `yaml:"pricing"`  // 各提供商与模型的价格，用于估算费用
+
+	Budgets      []UsageBudget `yaml:"budgets"`       // 各提供商的费用预算 (需要为该提供商配置价格)
+	AlertWebhook string        `yaml:"alert_webhook"` // 预算告警的 Webhook 地址 (POST JSON)，为空时只记录日志
+}
+
+// GetCurrency 获取货币单位，参数: 无，返回: 货币代码 (默认 USD)
+func (c *UsageConfig) GetCurrency() string {
+	if currency := strings.TrimSpace(c.Currency); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	return "USD"
+}
+
+// UsagePrice 一个提供商 (或其某个模型) 的价格，按字符计费与按 token 计费可以同时设置
+type UsagePrice struct {
+	Provider               string  `yaml:"provider"`                  // 提供商名称 (service_type 或 providers 中的 name)
+	Model                  string  `yaml:"model"`                     // 模型名称，为空时适用于该提供商的全部模型
+	PerMillionChars        float64 `yaml:"per_million_chars"`         // 每百万原文字符的价格
+	PerMillionInputTokens  float64 `yaml:"per_million_input_tokens"`  // 每百万输入 token 的价格
+	PerMillionOutputTokens float64 `yaml:"per_million_output_tokens"` // 每百万输出 token 的价格
+	CharsPerToken          float64 `yaml:"chars_per_token"`           // 估算 token 数时每个 token 对应的字符数，默认 4
+}
+
+// 预算用尽后的处理方式
+const (
+	BudgetActionReject   = "reject"   // 拒绝请求 (429 QUOTA_EXCEEDED)
+	BudgetActionFallback = "fallback" // 改用 fallback 指定的提供商
+)
+
+// UsageBudget 一个提供商的费用预算，按 UTC 日与月计算
+type UsageBudget struct {
+	Provider     string  `yaml:"provider"`      // 提供商名称 (service_type 或 providers 中的 name)
+	Daily        float64 `yaml:"daily"`         // 每日预算，0 表示不限
+	Monthly      float64 `yaml:"monthly"`       // 每月预算，0 表示不限
+	Action       string  `yaml:"action"`        // 预算用尽后的处理: reject (默认) 或 fallback
+	Fallback     string  `yaml:"fallback"`      // action 为 fallback 时改用的提供商
+	AlertPercent int     `yaml:"alert_percent"` // 费用达到预算的该百分比时告警，默认 80
+}
+
+// GetAction 获取预算用尽后的处理方式，参数: 无，返回: reject 或 fallback (默认 reject)
+func (b *UsageBudget) GetAction() string {
+	if action := strings.ToLower(strings.TrimSpace(b.Action)); action != "" {
+		return action
+	}
+	return BudgetActionReject
+}
+
+// GetAlertPercent 获取告警阈值，参数: 无，返回: 百分比 (默认 80)
+func (b *UsageBudget) GetAlertPercent() int {
+	if b.AlertPercent > 0 {
+		return b.AlertPercent
+	}
+	return 80
+}
+
+// AdminConfig 管理接口配置
+// 配置 password_hash 或 totp_secret 后启用登录与会话；token 仅供自动化脚本使用，可留空
+type AdminConfig struct {
+	Token        string `yaml:"token"`         // 可选：静态访问令牌 (供脚本调用)
+	Username     string `yaml:"username"`      // 登录用户名，默认 admin
+	PasswordHash string `yaml:"password_hash"` // bcrypt 密码哈希
+	TOTPSecret   string `yaml:"totp_secret"`   // Base32 TOTP 密钥 (与验证器 App 共享)
+	SessionTTL   string `yaml:"session_ttl"`   // 会话有效期，默认 12h
+}
+
+// LoginEnabled 判断是否启用了登录认证，返回: 布尔
+func (a *AdminConfig) LoginEnabled() bool {
+	return strings.TrimSpace(a.PasswordHash) != "" || strings.TrimSpace(a.TOTPSecret) != ""
+}
+
+// Enabled 判断管理接口是否启用，返回: 布尔
+func (a *AdminConfig) Enabled() bool {
+	return a.LoginEnabled() || strings.TrimSpace(a.Token) != ""
+}
+
+// GetUsername 获取登录用户名，默认 admin
+func (a *AdminConfig) GetUsername() string {
+	if strings.TrimSpace(a.Username) == "" {
+		return "admin"
+	}
+	return a.Username
+}
+
+// GetSessionTTL 获取会话有效期，默认 12 小时
+func (a *AdminConfig) GetSessionTTL() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(a.SessionTTL))
+	if err != nil || d <= 0 {
+		return 12 * time.Hour
+	}
+	return d
+}
+
+// PreferencesConfig 按客户端学习常用语言方向的配置
+type PreferencesConfig struct {
+	Enabled    bool `yaml:"enabled"`     // 是否记录语言方向
+	AutoTarget bool `yaml:"auto_target"` // 请求未指定 tl 时使用该客户端最常用的目标语言
+	MaxClients int  `yaml:"max_clients"` // 最多跟踪的客户端数量，默认 10000
+
+	Prewarm PrewarmConfig `yaml:"prewarm"` // 按所有客户端最常用的语言方向预热路由与熔断状态
+}
+
+// PrewarmConfig 预热配置：定期把最常用的语言方向按路由规则发给对应提供商 (不读写缓存)
+// 首次出现的路由预热一次连接，熔断器进入半开时由预热请求代替用户请求探测恢复
+type PrewarmConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Interval string `yaml:"interval"` // 预热间隔，默认 1m
+	Pairs    int    `yaml:"pairs"`    // 预热最常用的多少个语言方向，默认 5
+	Text     string `yaml:"text"`     // 预热请求的文本，默认 Hello
+}
+
+// GetInterval 获取预热间隔，参数: 无，返回: 时长 (默认 1 分钟)
+func (c *PrewarmConfig) GetInterval() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Interval))
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// GetPairs 获取预热的语言方向数量，参数: 无，返回: 数量 (默认 5)
+func (c *PrewarmConfig) GetPairs() int {
+	if c.Pairs <= 0 {
+		return 5
+	}
+	return c.Pairs
+}
+
+// GetText 获取预热请求的文本，参数: 无，返回: 文本 (默认 Hello)
+func (c *PrewarmConfig) GetText() string {
+	if text := strings.TrimSpace(c.Text); text != "" {
+		return text
+	}
+	return "Hello"
+}
+
+// AuthConfig 下游客户端 API Key 认证配置
+// 启用后翻译接口必须携带已配置的密钥；未启用时允许匿名访问
+type AuthConfig struct {
+	Enabled bool              `yaml:"enabled"` // 是否要求客户端携带 API Key 或 JWT
+	Keys    []ClientKeyConfig `yaml:"keys"`    // 允许的密钥列表
+	JWT     JWTConfig         `yaml:"jwt"`     // 可选：接受 SSO 签发的 Bearer JWT
+}
+
+// JWTConfig Bearer JWT 认证配置，secret (HS256) 与 jwks_url (RS256) 至少配置一个
+type JWTConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Secret       string `yaml:"secret"`        // HS256 共享密钥
+	JWKSURL      string `yaml:"jwks_url"`      // RS256 公钥集合地址
+	JWKSRefresh  string `yaml:"jwks_refresh"`  // 公钥集合刷新间隔，默认 1h
+	Issuer       string `yaml:"issuer"`        // 可选：期望的 iss
+	Audience     string `yaml:"audience"`      // 可选：期望的 aud
+	SubjectClaim string `yaml:"subject_claim"` // 作为用户标识的声明，默认 sub
+	DailyChars   int64  `yaml:"daily_chars"`   // 可选：每个用户的每日字符额度，0 表示不限
+}
+
+// GetJWKSRefresh 获取公钥集合刷新间隔
+func (j *JWTConfig) GetJWKSRefresh() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(j.JWKSRefresh))
+	if err != nil || d <= 0 {
+		return time.Hour
+	}
+	return d
+}
+
+// ClientKeyConfig 单个客户端密钥及其限制
+type ClientKeyConfig struct {
+	Name       string   `yaml:"name"`        // 密钥名称，用于日志与额度统计
+	Key        string   `yaml:"key"`         // 密钥值
+	RPS        float64  `yaml:"rps"`         // 可选：该密钥每秒请求数，0 表示只受全局限流约束
+	Burst      int      `yaml:"burst"`       // 可选：突发容量，默认取 rps 向上取整
+	DailyChars int64    `yaml:"daily_chars"` // 可选：每日字符额度，超出后拒绝请求，0 表示不限
+	Endpoints  []string `yaml:"endpoints"`   // 可选：允许访问的路由，为空表示全部翻译接口
+}
+
+// GetWarnPercent 获取额度预警百分比
+func (c *QuotaConfig) GetWarnPercent() int {
+	if c.WarnPercent <= 0 || c.WarnPercent > 100 {
+		return 10
+	}
+	return c.WarnPercent
+}
+
 // GetTTL 获取 TTL 时间，返回 0 表示永不过期
 func (c *CacheConfig) GetTTL() time.Duration {
 	if c.TTL == "" || c.TTL == "0" {
@@ -79,6 +1479,24 @@ func (c *CacheConfig) GetTTL() time.Duration {
 	return d
 }
 
+// GetDetectionTTL 获取语言检测缓存过期时间，默认 30 天
+func (c *CacheConfig) GetDetectionTTL() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.DetectionTTL))
+	if err != nil || d <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return d
+}
+
+// GetStatsInterval 获取缓存指标的采样间隔，默认 1 分钟
+func (c *CacheConfig) GetStatsInterval() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.StatsInterval))
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
 // GetPoolSize 获取连接池大小
 func (c *CacheConfig) GetPoolSize() int {
 	if c.PoolSize <= 0 {
@@ -127,73 +1545,71 @@ func (c *ServerConfig) GetMiddlewareTimeout() int {
 	return c.MiddlewareTimeout
 }
 
-// GetShutdownTimeout 获取优雅停机超时时间，返回秒数
-func (c *ServerConfig) GetShutdownTimeout() int {
-	if c.ShutdownTimeout <= 0 {
-		return 15 // 默认 15 秒
-	}
-	return c.ShutdownTimeout
+// tlsVersions 支持的最低 TLS 版本
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
 }
 
-// Load 从配置文件与环境变量加载配置，参数: 无，返回: 配置指针与可能的错误
-func Load() (*Config, error) {
-	cfg := defaultConfig()
-
-	if err := loadFromFile(cfg); err != nil {
-		return nil, err
+// GetMinVersion 获取最低 TLS 版本，参数: 无，返回: crypto/tls 版本常量 (默认 TLS 1.2)
+func (c *TLSConfig) GetMinVersion() uint16 {
+	if v, ok := tlsVersions[strings.TrimSpace(c.MinVersion)]; ok {
+		return v
 	}
-
-	applyEnvOverrides(cfg)
-	return cfg, nil
+	return tls.VersionTLS12
 }
 
-// Validate 验证配置，参数: 接收者 Config，返回: 校验失败时的错误
-func (c *Config) Validate() error {
-	if c == nil {
-		return fmt.Errorf("配置不能为空")
+// GetCipherSuites 将密码套件名称解析为 ID，只接受 Go 认为安全的套件，参数: 无，返回: 套件 ID 列表与错误
+func (c *TLSConfig) GetCipherSuites() ([]uint16, error) {
+	if len(c.CipherSuites) == 0 {
+		return nil, nil
 	}
-
-	if err := validatePort(c.Port); err != nil {
-		return err
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
 	}
-
-	if err := validateTranslation(&c.Translation); err != nil {
-		return err
+	ids := make([]uint16, 0, len(c.CipherSuites))
+	for _, name := range c.CipherSuites {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("不支持的密码套件: %s", name)
+		}
+		ids = append(ids, id)
 	}
-
-	return nil
+	return ids, nil
 }
 
-// validateTranslation 校验翻译配置，参数: TranslationConfig 指针，返回: 验证失败的错误
-func validateTranslation(t *TranslationConfig) error {
-	if t == nil {
-		return fmt.Errorf("translation 配置不能为空")
-	}
+// GetTrustedProxies 解析可信代理网段，参数: 无，返回: 网段列表与错误
+func (c *ServerConfig) GetTrustedProxies() ([]netip.Prefix, error) {
+	return ParsePrefixes(c.TrustedProxies)
+}
 
-	if strings.TrimSpace(t.ServiceType) == "" {
-		return fmt.Errorf("translation.service_type 未设置")
+// GetMaxBodySize 获取请求体大小上限，参数: 无，返回: Echo BodyLimit 格式的大小 (默认 2M)
+func (c *ServerConfig) GetMaxBodySize() string {
+	if size := strings.TrimSpace(c.MaxBodySize); size != "" {
+		return size
 	}
+	return "2M"
+}
 
-	if strings.TrimSpace(t.APIKey) == "" {
-		return fmt.Errorf("translation.api_key 未设置")
+// GetShutdownTimeout 获取优雅停机超时时间，返回秒数
+func (c *ServerConfig) GetShutdownTimeout() int {
+	if c.ShutdownTimeout <= 0 {
+		return 15 // 默认 15 秒
 	}
-
-	return nil
+	return c.ShutdownTimeout
 }
 
-// validatePort 校验端口，参数: 端口字符串，返回: 无效端口的错误
-func validatePort(port string) error {
-	port = strings.TrimSpace(port)
-	if port == "" {
-		return fmt.Errorf("端口配置无效")
-	}
+// Load 从配置文件与环境变量加载配置，参数: 无，返回: 配置指针与可能的错误
+func Load() (*Config, error) {
+	cfg := defaultConfig()
 
-	value, err := strconv.Atoi(port)
-	if err != nil || value <= 0 || value > 65535 {
-		return fmt.Errorf("端口配置无效: %s", port)
+	if err := loadFromFile(cfg); err != nil {
+		return nil, err
 	}
 
-	return nil
+	applyEnvOverrides(cfg)
+	return cfg, nil
 }
 
 // defaultConfig 构建默认配置，参数: 无，返回: 默认配置指针
@@ -210,6 +1626,8 @@ func defaultConfig() *Config {
 			DB:                  0,
 			TTL:                 "", // 空表示永不过期
 			ShareAcrossServices: true,
+			CacheDetection:      false,
+			DetectionTTL:        "720h",
 			PoolSize:            10,
 			DialTimeout:         5,
 			ReadTimeout:         3,
@@ -218,22 +1636,49 @@ func defaultConfig() *Config {
 	}
 }
 
-// loadFromFile 从文件加载配置，参数: 目标配置指针，返回: 读取或解析时的错误
+// loadFromFile 从文件加载配置 (支持 include 叠加合并与 profile 覆盖)，参数: 目标配置指针，返回: 读取或解析时的错误
 func loadFromFile(cfg *Config) error {
 	path := strings.TrimSpace(os.Getenv("CONFIG_FILE"))
 	if path == "" {
 		path = defaultConfigPath
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
+	profile := strings.TrimSpace(os.Getenv("APP_ENV"))
+	if _, err := os.Stat(path); err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
+			if profile != "" {
+				return fmt.Errorf("未定义的配置 profile: %s (配置文件 %s 不存在)", profile, path)
+			}
 			return nil
 		}
 		return fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	doc, err := loadDocument(path, nil)
+	if err != nil {
+		return err
+	}
+
+	// 严格模式：拼写错误的字段 (如 requst_timeout) 直接报错而非静默忽略
+	if err := checkUnknownFields(doc); err != nil {
+		return err
+	}
+
+	cfg.Profile = profile
+	doc, err = applyProfile(doc, cfg.Profile)
+	if err != nil {
+		return err
+	}
+
+	// 合并后的文档重新编码，再解析到配置结构体中
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("编码合并后的配置失败: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
 		return fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
@@ -250,6 +1695,10 @@ func applyEnvOverrides(cfg *Config) {
 		cfg.Debug = parseBool(v)
 	}
 
+	if v := strings.TrimSpace(os.Getenv("LOG_FORMAT")); v != "" {
+		cfg.Logging.Format = v
+	}
+
 	if v := strings.TrimSpace(firstNonEmpty(
 		os.Getenv("TRANSLATION_SERVICE"),
 		os.Getenv("DEEPLX_SERVICE"),
@@ -278,6 +1727,43 @@ func applyEnvOverrides(cfg *Config) {
 		cfg.Translation.Model = v
 	}
 
+	if v := strings.TrimSpace(os.Getenv("ADMIN_TOKEN")); v != "" {
+		cfg.Admin.Token = v
+	}
+
+	if v := strings.TrimSpace(os.Getenv("ADMIN_PASSWORD_HASH")); v != "" {
+		cfg.Admin.PasswordHash = v
+	}
+
+	if v := strings.TrimSpace(os.Getenv("ADMIN_TOTP_SECRET")); v != "" {
+		cfg.Admin.TOTPSecret = v
+	}
+
+	// 以逗号分隔的客户端密钥，名称按顺序生成为 env-1、env-2 ...
+	if v := strings.TrimSpace(os.Getenv("AUTH_API_KEYS")); v != "" {
+		cfg.Auth.Enabled = true
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				cfg.Auth.Keys = append(cfg.Auth.Keys, ClientKeyConfig{
+					Name: fmt.Sprintf("env-%d", len(cfg.Auth.Keys)+1),
+					Key:  key,
+				})
+			}
+		}
+	}
+
+	if v := strings.TrimSpace(os.Getenv("AUTH_JWT_SECRET")); v != "" {
+		cfg.Auth.JWT.Secret = v
+	}
+
+	if v := strings.TrimSpace(os.Getenv("METRICS_TOKEN")); v != "" {
+		cfg.Metrics.Token = v
+	}
+
+	if v := os.Getenv("METRICS_PASSWORD"); v != "" {
+		cfg.Metrics.Password = v
+	}
+
 	// 缓存配置环境变量覆盖
 	if v := strings.TrimSpace(os.Getenv("CACHE_ENABLED")); v != "" {
 		cfg.Cache.Enabled = parseBool(v)
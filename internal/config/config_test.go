@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -37,27 +38,586 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "openai provider with prompt templates",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "openai",
+					APIKey:      "sk-test",
+					Model:       "gpt-4o-mini",
+					Prompt:      PromptConfig{System: "Translate into {{.TargetLang}}.", User: "{{.Text}}"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid prompt template",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "openai",
+					APIKey:      "sk-test",
+					Model:       "gpt-4o-mini",
+					Prompt:      PromptConfig{System: "Translate into {{.TargetLang"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "openai provider without model",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Providers:   []ProviderConfig{{Name: "gpt", ServiceType: "openai", APIKey: "sk-openai"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "keyless self-hosted relay",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					BaseURL:     "http://localhost:1188/translate",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "api key not matching key pattern",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "relay-token",
+					KeyPattern:  "^sk-",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "key pattern requires a key",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					BaseURL:     "http://localhost:1188/translate",
+					Providers:   []ProviderConfig{{Name: "relay", ServiceType: "deeplx", BaseURL: "http://relay", KeyPattern: "^sk-"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid port",
 			cfg: Config{
-				Port: "70000",
+				Port: "70000",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing service type",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					APIKey: "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max body size",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{MaxBodySize: "lots"},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid compression level",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{Compression: CompressionConfig{Enabled: true, Level: 11}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid provider concurrency limit",
+			cfg: Config{
+				Port: "8080",
+				Server: ServerConfig{Concurrency: ConcurrencyConfig{
+					Enabled:   true,
+					Providers: map[string]ConcurrencyLimit{"deeplx": {MaxInFlight: 0}},
+				}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider concurrency keys differing only by case",
+			cfg: Config{
+				Port: "8080",
+				Server: ServerConfig{Concurrency: ConcurrencyConfig{
+					Enabled:   true,
+					Providers: map[string]ConcurrencyLimit{"backup": {MaxInFlight: 1}, "Backup": {MaxInFlight: 4}},
+				}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid access control cidr",
+			cfg: Config{
+				Port: "8080",
+				Server: ServerConfig{AccessControl: AccessControlConfig{
+					Enabled: true,
+					Allow:   []string{"10.0.0.0/8", "192.168.1.300"},
+				}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid auto ban cooldown",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{AutoBan: AutoBanConfig{Enabled: true, Cooldown: "forever"}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "key rotation replaces api key",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					KeyRotation: KeyRotationConfig{
+						Keys:     []UpstreamKey{{Key: "sk-a", Weight: 3}, {Key: "sk-b"}},
+						Strategy: "weighted",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid key rotation strategy",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					KeyRotation: KeyRotationConfig{
+						Keys:     []UpstreamKey{{Key: "sk-a"}},
+						Strategy: "random",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid circuit breaker error rate",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType:    "deeplx",
+					APIKey:         "sk-test",
+					CircuitBreaker: CircuitBreakerConfig{Enabled: true, ErrorRate: 1.5},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid health check interval",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					HealthCheck: HealthCheckConfig{Enabled: true, Interval: "often"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "glossary redis without cache",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Glossary:    GlossaryConfig{Enabled: true, Redis: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid batching wait",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Batching:    BatchingConfig{Enabled: true, MaxWait: "soon"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid profanity mode",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Profanity:   ProfanityConfig{Enabled: true, Mode: "censor", Words: map[string][]string{"en": {"darn"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid normalize emoji mode",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Normalize:   NormalizeConfig{NFC: true, Emoji: "replace"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "routing rule with unknown provider",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Routing:     RoutingConfig{Enabled: true, Rules: []RoutingRule{{Targets: []string{"en"}, Provider: "gtx"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "canary to default provider",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Canary:      CanaryConfig{Enabled: true, Provider: "deeplx", Percent: 5},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shadow sample rate above one",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Providers:   []ProviderConfig{{Name: "backup", ServiceType: "deeplx", APIKey: "sk-backup"}},
+					Shadow:      ShadowConfig{Enabled: true, Provider: "backup", SampleRate: 1.5},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid endpoint url",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Endpoints:   EndpointsConfig{URLs: []string{"https://relay.example.com", "relay2.example.com"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid capabilities",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType:  "deeplx",
+					APIKey:       "sk-test",
+					Capabilities: CapabilitiesConfig{Languages: []string{"en", " "}, MaxTextLength: -1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative usage price",
+			cfg: Config{
+				Port:        "8080",
+				Translation: TranslationConfig{ServiceType: "deeplx", APIKey: "sk-test"},
+				Usage:       UsageConfig{Enabled: true, Pricing: []UsagePrice{{Provider: "deeplx", PerMillionChars: -20}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "usage budget fallback to itself",
+			cfg: Config{
+				Port:        "8080",
+				Translation: TranslationConfig{ServiceType: "deeplx", APIKey: "sk-test"},
+				Usage: UsageConfig{
+					Enabled: true,
+					Pricing: []UsagePrice{{Provider: "deeplx", PerMillionChars: 20}},
+					Budgets: []UsageBudget{{Provider: "deeplx", Daily: 5, Action: "fallback", Fallback: "deeplx"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid rewrite pattern",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Rewrite:     RewriteConfig{Enabled: true, Rules: []RewriteRule{{Pattern: `(?i)github(`, Replace: "GitHub"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "translation memory min score out of range",
+			cfg: Config{
+				Port: "8080",
 				Translation: TranslationConfig{
 					ServiceType: "deeplx",
 					APIKey:      "sk-test",
+					Memory:      TranslationMemoryConfig{Enabled: true, MinScore: 85},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "missing service type",
+			name: "provider override with unknown provider",
 			cfg: Config{
 				Port: "8080",
 				Translation: TranslationConfig{
-					APIKey: "sk-test",
+					ServiceType:      "deeplx",
+					APIKey:           "sk-test",
+					ProviderOverride: ProviderOverrideConfig{Enabled: true, Allow: []string{"deeplx", "openai"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "comparison with default provider",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Comparison:  ComparisonConfig{Enabled: true, Provider: "deeplx"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hedging with unknown provider",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Hedging:     HedgingConfig{Enabled: true, Provider: "missing"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "metrics username without password",
+			cfg: Config{
+				Port:    "8080",
+				Metrics: MetricsConfig{Username: "prometheus"},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "failover with unknown provider",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Providers:   []ProviderConfig{{Name: "backup", ServiceType: "deeplx", APIKey: "sk-backup"}},
+					Failover:    FailoverConfig{Enabled: true, Order: []string{"deeplx", "missing"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid trusted proxy",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{TrustedProxies: []string{"nginx"}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative access log retention",
+			cfg: Config{
+				Port:    "8080",
+				Logging: LoggingConfig{AccessLog: AccessLogConfig{Enabled: true, MaxAgeDays: -1}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log format",
+			cfg: Config{
+				Port:    "8080",
+				Logging: LoggingConfig{Format: "logfmt"},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid component log level",
+			cfg: Config{
+				Port:    "8080",
+				Logging: LoggingConfig{Levels: map[string]string{"cache": "verbose"}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative maintenance retry after",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{Maintenance: MaintenanceConfig{RetryAfter: -5}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tracing endpoint",
+			cfg: Config{
+				Port:    "8080",
+				Tracing: TracingConfig{Enabled: true, Endpoint: "otel-collector:4318"},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "adaptive timeout max below base",
+			cfg: Config{
+				Port: "8080",
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+					Providers: []ProviderConfig{
+						{Name: "backup", ServiceType: "deeplx", APIKey: "sk-backup", AdaptiveTimeout: AdaptiveTimeoutConfig{Enabled: true, Base: "5s", Max: "3s"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid ops addr",
+			cfg: Config{
+				Port:   "8080",
+				Server: ServerConfig{Ops: OpsConfig{Enabled: true, Addr: "9090"}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "prewarm without preferences",
+			cfg: Config{
+				Port:        "8080",
+				Preferences: PreferencesConfig{Prewarm: PrewarmConfig{Enabled: true}},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "totp without password hash",
+			cfg: Config{
+				Port:  "8080",
+				Admin: AdminConfig{TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
 				},
 			},
 			wantErr: true,
 		},
+		{
+			name: "totp with password hash",
+			cfg: Config{
+				Port: "8080",
+				Admin: AdminConfig{
+					PasswordHash: "$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BWX4Z7Ny6rjpUwaVbWMiYzgHp7Ae",
+					TOTPSecret:   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+				},
+				Translation: TranslationConfig{
+					ServiceType: "deeplx",
+					APIKey:      "sk-test",
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -108,6 +668,7 @@ func TestLoadEnvOverrides(t *testing.T) {
 	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
 	t.Setenv("PORT", "9100")
 	t.Setenv("DEBUG", "true")
+	t.Setenv("LOG_FORMAT", "json")
 	t.Setenv("TRANSLATION_SERVICE", "custom")
 	t.Setenv("TRANSLATION_API_KEY", "sk-env")
 	t.Setenv("TRANSLATION_BASE_URL", "https://env.example.com")
@@ -117,7 +678,7 @@ func TestLoadEnvOverrides(t *testing.T) {
 		t.Fatalf("Load() error = %v", err)
 	}
 
-	if cfg.Port != "9100" || !cfg.Debug {
+	if cfg.Port != "9100" || !cfg.Debug || cfg.Logging.Format != "json" {
 		t.Fatalf("环境变量未覆盖顶层字段: %#v", cfg)
 	}
 	if cfg.Translation.ServiceType != "custom" ||
@@ -126,3 +687,301 @@ func TestLoadEnvOverrides(t *testing.T) {
 		t.Fatalf("环境变量未覆盖 translation 字段: %#v", cfg.Translation)
 	}
 }
+
+// TestAttributionRender 测试署名文本渲染，参数: 测试实例，返回: 无
+func TestAttributionRender(t *testing.T) {
+	attr := AttributionConfig{}
+	if got := attr.Render("DeepLX"); got != "translated by DeepLX via translate-services" {
+		t.Fatalf("Render() 默认文本 = %q", got)
+	}
+	if got := attr.GetHeader(); got != "X-Translated-By" {
+		t.Fatalf("GetHeader() 默认值 = %q", got)
+	}
+
+	attr = AttributionConfig{Text: "Powered by {provider}", Header: "X-Attribution"}
+	if got := attr.Render("DeepLX"); got != "Powered by DeepLX" {
+		t.Fatalf("Render() 自定义文本 = %q", got)
+	}
+	if got := attr.GetHeader(); got != "X-Attribution" {
+		t.Fatalf("GetHeader() 自定义值 = %q", got)
+	}
+}
+
+// TestResolveModel 测试模型解析优先级，参数: 测试实例，返回: 无
+func TestResolveModel(t *testing.T) {
+	tc := TranslationConfig{
+		ServiceType: "deeplx",
+		Model:       "gpt-3.5-turbo",
+		Providers: []ProviderConfig{
+			{Name: "gemini", ServiceType: "deeplx", APIKey: "sk-x", Model: "gemini-1.5-flash-latest"},
+			{Name: "plain", ServiceType: "deeplx", APIKey: "sk-y"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		provider  string
+		requested string
+		want      string
+	}{
+		{name: "请求指定优先", provider: "gemini", requested: "gpt-4o", want: "gpt-4o"},
+		{name: "提供商默认模型", provider: "gemini", want: "gemini-1.5-flash-latest"},
+		{name: "名称大小写不敏感", provider: "Gemini", want: "gemini-1.5-flash-latest"},
+		{name: "回退全局模型", provider: "plain", want: "gpt-3.5-turbo"},
+		{name: "默认提供商", provider: "deeplx", want: "gpt-3.5-turbo"},
+		{name: "未知提供商", provider: "unknown", want: "gpt-3.5-turbo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tc.ResolveModel(tt.provider, tt.requested); got != tt.want {
+				t.Errorf("ResolveModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRoutingMatch 测试路由规则按顺序匹配语言对、长度与 dt，参数: 测试实例，返回: 无
+func TestRoutingMatch(t *testing.T) {
+	routing := RoutingConfig{
+		Enabled: true,
+		Rules: []RoutingRule{
+			{Name: "dictionary", DT: []string{"bd"}, Provider: "gtx"},
+			{Name: "short-zh-en", Sources: []string{"zh"}, Targets: []string{"en"}, MaxLength: 200, Provider: "gtx"},
+			{Sources: []string{"zh"}, MinLength: 201, Provider: "openai", Model: "gpt-4o-mini"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		target string
+		length int
+		dt     []string
+		want   int
+		ok     bool
+	}{
+		{name: "dt 命中", source: "fr", target: "de", length: 10, dt: []string{"t", "BD"}, want: 0, ok: true},
+		{name: "短文本", source: "zh-CN", target: "en-US", length: 200, dt: []string{"t"}, want: 1, ok: true},
+		{name: "长文档", source: "zh_TW", target: "en", length: 5000, want: 2, ok: true},
+		{name: "源语言未知", source: "", target: "en", length: 10},
+		{name: "长度不满足任一规则", source: "zh", target: "ja", length: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := routing.Match(tt.source, tt.target, tt.length, tt.dt)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("Match() = %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if got := routing.Rules[2].GetName(2); got != "rules[2]" {
+		t.Errorf("GetName() = %q", got)
+	}
+	routing.Enabled = false
+	if _, ok := routing.Match("zh", "en", 10, nil); ok {
+		t.Error("未启用时不应匹配")
+	}
+}
+
+// TestValidateProviders 测试额外提供商校验，参数: 测试实例，返回: 无
+func TestValidateProviders(t *testing.T) {
+	cfg := Config{
+		Port: "8080",
+		Translation: TranslationConfig{
+			ServiceType: "deeplx",
+			APIKey:      "sk-test",
+			Providers: []ProviderConfig{
+				{Name: "DeepLX", ServiceType: "deeplx", APIKey: "sk-dup"},
+			},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() 期望提供商名称与默认提供商重复时报错")
+	}
+
+	cfg.Translation.Providers[0].Name = "backup"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+// TestResolveLanguageAlias 测试语言代码别名解析，参数: 测试实例，返回: 无
+func TestResolveLanguageAlias(t *testing.T) {
+	tc := TranslationConfig{
+		LanguageAliases: map[string]string{
+			"cn":          "zh-CN",
+			"JP":          "ja",
+			"auto-detect": "auto",
+		},
+	}
+
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "cn", want: "zh-CN"},
+		{code: "CN", want: "zh-CN"},
+		{code: "jp", want: "ja"},
+		{code: "auto-detect", want: "auto"},
+		{code: "fr", want: "fr"},
+		{code: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := tc.ResolveLanguageAlias(tt.code); got != tt.want {
+			t.Errorf("ResolveLanguageAlias(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+// TestLanguagePolicy 测试语言限制判断，参数: 测试实例，返回: 无
+func TestLanguagePolicy(t *testing.T) {
+	policy := LanguagePolicyConfig{
+		AllowSources: []string{"en", "ja"},
+		AllowTargets: []string{"zh", "en"},
+		DenyTargets:  []string{"zh-TW"},
+	}
+
+	sources := map[string]bool{"en": true, "EN-us": true, "ja": true, "auto": true, "": true, "fr": false, "eng": false}
+	for code, want := range sources {
+		if got := policy.AllowsSource(code); got != want {
+			t.Errorf("AllowsSource(%q) = %v, want %v", code, got, want)
+		}
+	}
+	targets := map[string]bool{"zh-CN": true, "zh_cn": true, "zh": true, "zh-TW": false, "en": true, "de": false}
+	for code, want := range targets {
+		if got := policy.AllowsTarget(code); got != want {
+			t.Errorf("AllowsTarget(%q) = %v, want %v", code, got, want)
+		}
+	}
+
+	var open LanguagePolicyConfig
+	if !open.AllowsSource("fr") || !open.AllowsTarget("de") {
+		t.Error("empty policy should allow every language")
+	}
+}
+
+// TestValidateAuth 测试客户端认证配置校验，参数: 测试实例，返回: 无
+func TestValidateAuth(t *testing.T) {
+	base := Config{
+		Port:        "8080",
+		Translation: TranslationConfig{ServiceType: "deeplx", APIKey: "sk-test"},
+	}
+
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr bool
+	}{
+		{name: "未启用", auth: AuthConfig{}},
+		{name: "启用但无密钥", auth: AuthConfig{Enabled: true}, wantErr: true},
+		{name: "正常", auth: AuthConfig{Enabled: true, Keys: []ClientKeyConfig{{Name: "ide", Key: "k1"}}}},
+		{name: "名称重复", auth: AuthConfig{Enabled: true, Keys: []ClientKeyConfig{{Name: "a", Key: "k1"}, {Name: "a", Key: "k2"}}}, wantErr: true},
+		{name: "密钥重复", auth: AuthConfig{Enabled: true, Keys: []ClientKeyConfig{{Name: "a", Key: "k1"}, {Name: "b", Key: "k1"}}}, wantErr: true},
+		{name: "密钥为空", auth: AuthConfig{Keys: []ClientKeyConfig{{Name: "a"}}}, wantErr: true},
+		{name: "仅 JWT", auth: AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true, Secret: "s"}}},
+		{name: "JWT 缺少密钥来源", auth: AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true}}, wantErr: true},
+		{name: "JWKS 地址无效", auth: AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true, JWKSURL: "ftp://idp/keys"}}, wantErr: true},
+		{name: "JWT 未启用认证", auth: AuthConfig{JWT: JWTConfig{Enabled: true, Secret: "s"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			cfg.Auth = tt.auth
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateTLS 测试 TLS 配置校验，参数: 测试实例，返回: 无
+func TestValidateTLS(t *testing.T) {
+	base := Config{
+		Port:        "8080",
+		Translation: TranslationConfig{ServiceType: "deeplx", APIKey: "sk-test"},
+	}
+
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "未启用", tls: TLSConfig{MinVersion: "1.0"}},
+		{name: "正常", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "1.3"}},
+		{name: "缺少私钥", tls: TLSConfig{Enabled: true, CertFile: "cert.pem"}, wantErr: true},
+		{name: "版本过低", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "1.1"}, wantErr: true},
+		{name: "密码套件", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}},
+		{name: "不安全的密码套件", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, wantErr: true},
+		{name: "ACME", tls: TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true, Hosts: []string{"translate.example.com"}}}},
+		{name: "ACME 缺少域名", tls: TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true}}, wantErr: true},
+		{name: "ACME 与证书文件冲突", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", ACME: ACMEConfig{Enabled: true, Hosts: []string{"a.example.com"}}}, wantErr: true},
+		{name: "ACME 通配符域名", tls: TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true, Hosts: []string{"*.example.com"}}}, wantErr: true},
+		{name: "ACME 未启用 TLS", tls: TLSConfig{ACME: ACMEConfig{Enabled: true, Hosts: []string{"a.example.com"}}}, wantErr: true},
+		{name: "客户端证书", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", ClientAuth: "require", ClientCAFile: "ca.pem", ClientIdentity: true}},
+		{name: "客户端证书缺少 CA", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", ClientAuth: "optional"}, wantErr: true},
+		{name: "客户端证书模式无效", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", ClientAuth: "always", ClientCAFile: "ca.pem"}, wantErr: true},
+		{name: "证书标识未校验证书", tls: TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", ClientIdentity: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			cfg.Server.TLS = tt.tls
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestParsePrefixes 测试 CIDR 与单个 IP 的解析，参数: 测试实例，返回: 无
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes([]string{"10.1.2.3/8", " 192.168.1.10 ", "fd00::/8", "::1"})
+	if err != nil {
+		t.Fatalf("ParsePrefixes() error = %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.168.1.10/32", "fd00::/8", "::1/128"}
+	for i, prefix := range prefixes {
+		if prefix.String() != want[i] {
+			t.Errorf("prefixes[%d] = %s, want %s", i, prefix, want[i])
+		}
+	}
+
+	for _, entry := range []string{"", "10.0.0.0/33", "example.com"} {
+		if _, err := ParsePrefixes([]string{entry}); err == nil {
+			t.Errorf("ParsePrefixes(%q) 应返回错误", entry)
+		}
+	}
+}
+
+// TestCanaryIncludes 测试金丝雀分桶稳定且比例接近配置值，参数: 测试实例，返回: 无
+func TestCanaryIncludes(t *testing.T) {
+	canary := CanaryConfig{Enabled: true, Provider: "openai", Percent: 10}
+	included := 0
+	for i := 0; i < 10000; i++ {
+		client := fmt.Sprintf("client-%d", i)
+		in := canary.Includes(client)
+		if in != canary.Includes(client) {
+			t.Fatalf("Includes(%q) 结果不稳定", client)
+		}
+		if in {
+			included++
+		}
+	}
+	if included < 800 || included > 1200 {
+		t.Errorf("10%% 分流命中 %d / 10000", included)
+	}
+
+	canary.Percent = 100
+	if !canary.Includes("anyone") {
+		t.Error("100% 时应全部分流")
+	}
+	canary.Percent = 0
+	if canary.Includes("anyone") {
+		t.Error("0% 时不应分流")
+	}
+}
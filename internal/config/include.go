@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey 配置文件中声明引用其他文件的字段名
+const includeKey = "include"
+
+// loadDocument 递归加载配置文件及其 include 列表并合并，参数: 文件路径与当前引用链，返回: 合并后的文档与错误
+// 合并优先级: include 按声明顺序依次合并 (后者覆盖前者)，当前文件自身的字段最后覆盖所有 include
+func loadDocument(path string, chain []string) (map[string]any, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析配置路径失败: %w", err)
+	}
+
+	for _, visited := range chain {
+		if visited == absPath {
+			return nil, fmt.Errorf("检测到循环 include: %s", strings.Join(append(chain, absPath), " -> "))
+		}
+	}
+	chain = append(chain, absPath)
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", absPath, err)
+	}
+
+	includes, err := parseIncludes(doc[includeKey])
+	if err != nil {
+		return nil, fmt.Errorf("配置文件 %s: %w", absPath, err)
+	}
+	delete(doc, includeKey)
+
+	merged := map[string]any{}
+	baseDir := filepath.Dir(absPath)
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(baseDir, inc)
+		}
+		included, err := loadDocument(inc, chain)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeDocuments(merged, included)
+	}
+
+	return mergeDocuments(merged, doc), nil
+}
+
+// parseIncludes 解析 include 字段，参数: 原始字段值，返回: 路径列表与错误
+func parseIncludes(raw any) ([]string, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case []any:
+		paths := make([]string, 0, len(v))
+		for i, item := range v {
+			path, ok := item.(string)
+			if !ok || strings.TrimSpace(path) == "" {
+				return nil, fmt.Errorf("include[%d] 必须是非空字符串", i)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("include 必须是字符串或字符串列表")
+	}
+}
+
+// mergeDocuments 深度合并两个配置文档，参数: 基础文档与覆盖文档，返回: 合并结果
+// 映射递归合并，标量与列表整体替换
+func mergeDocuments(base, overlay map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range overlay {
+		baseMap, baseOK := result[k].(map[string]any)
+		overlayMap, overlayOK := v.(map[string]any)
+		if baseOK && overlayOK {
+			result[k] = mergeDocuments(baseMap, overlayMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile 测试辅助：写入文件，参数: 测试实例、路径、内容，返回: 无
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("写入文件 %s 失败: %v", path, err)
+	}
+}
+
+// TestLoadWithIncludes 测试 include 叠加合并的优先级，参数: 测试实例，返回: 无
+func TestLoadWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "base.yaml"), `
+port: "8000"
+translation:
+  service_type: "deeplx"
+  api_key: "sk-base"
+  base_url: "https://base.example.com"
+cache:
+  enabled: true
+  ttl: "24h"
+`)
+	writeFile(t, filepath.Join(dir, "secrets.yaml"), `
+translation:
+  api_key: "sk-secret"
+`)
+	writeFile(t, filepath.Join(dir, "prod.yaml"), `
+include:
+  - base.yaml
+  - secrets.yaml
+port: "9000"
+cache:
+  ttl: "1h"
+`)
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "prod.yaml"))
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Port != "9000" {
+		t.Errorf("Port = %q, 期望当前文件覆盖 include", cfg.Port)
+	}
+	if cfg.Translation.APIKey != "sk-secret" {
+		t.Errorf("APIKey = %q, 期望后声明的 include 覆盖先声明的", cfg.Translation.APIKey)
+	}
+	if cfg.Translation.BaseURL != "https://base.example.com" {
+		t.Errorf("BaseURL = %q, 期望保留基础配置", cfg.Translation.BaseURL)
+	}
+	if !cfg.Cache.Enabled || cfg.Cache.TTL != "1h" {
+		t.Errorf("Cache = %+v, 期望嵌套字段深度合并", cfg.Cache)
+	}
+}
+
+// TestLoadIncludeCycle 测试循环 include 检测，参数: 测试实例，返回: 无
+func TestLoadIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "include: b.yaml\nport: \"8001\"\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "include: [a.yaml]\nport: \"8002\"\n")
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "a.yaml"))
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "循环 include") {
+		t.Fatalf("Load() error = %v, 期望循环 include 错误", err)
+	}
+}
+
+// TestLoadIncludeMissing 测试引用不存在的文件，参数: 测试实例，返回: 无
+func TestLoadIncludeMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.yaml"), "include: [missing.yaml]\n")
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "main.yaml"))
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() 期望 include 文件缺失时返回错误")
+	}
+}
+
+// TestLoadWithProfile 测试 APP_ENV 选择 profile 覆盖，参数: 测试实例，返回: 无
+func TestLoadWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, `
+port: "8000"
+cache:
+  ttl: "24h"
+profiles:
+  dev:
+    debug: true
+    cache:
+      ttl: "10m"
+  prod:
+    port: "80"
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	t.Setenv("APP_ENV", "dev")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Debug || cfg.Cache.TTL != "10m" || cfg.Port != "8000" || cfg.Profile != "dev" {
+		t.Fatalf("dev profile 未正确叠加: debug=%v ttl=%q port=%q", cfg.Debug, cfg.Cache.TTL, cfg.Port)
+	}
+
+	t.Setenv("APP_ENV", "")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Debug || cfg.Cache.TTL != "24h" {
+		t.Fatalf("未选择 profile 时不应叠加: debug=%v ttl=%q", cfg.Debug, cfg.Cache.TTL)
+	}
+
+	t.Setenv("APP_ENV", "staging")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "staging") {
+		t.Fatalf("Load() error = %v, 期望未定义 profile 报错", err)
+	}
+}
+
+// TestLoadProfileWithoutProfiles 测试设置了 APP_ENV 但配置文件不存在或没有 profiles 段时同样报错，参数: 测试实例，返回: 无
+func TestLoadProfileWithoutProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("APP_ENV", "prod")
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "未定义的配置 profile: prod") {
+		t.Errorf("配置文件不存在: Load() error = %v, 期望 profile 报错", err)
+	}
+
+	for name, content := range map[string]string{
+		"没有 profiles 段": "port: \"8000\"\n",
+		"profiles 为空":   "port: \"8000\"\nprofiles:\n",
+	} {
+		path := filepath.Join(dir, "config.yaml")
+		writeFile(t, path, content)
+		t.Setenv("CONFIG_FILE", path)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "未定义的配置 profile: prod") {
+			t.Errorf("%s: Load() error = %v, 期望 profile 报错", name, err)
+		}
+	}
+
+	t.Setenv("APP_ENV", "")
+	if _, err := Load(); err != nil {
+		t.Errorf("未选择 profile: Load() error = %v", err)
+	}
+}
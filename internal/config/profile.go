@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// profilesKey 配置文件中声明环境 profile 的字段名
+const profilesKey = "profiles"
+
+// applyProfile 将选中的 profile 叠加到配置文档上，参数: 合并后的文档与 profile 名称，返回: 叠加后的文档与错误
+// profile 在 include 与主文件合并之后应用，优先级高于文件内容，低于环境变量；
+// 选择了 profile 但文件没有 profiles 段时与未定义的 profile 一样报错，避免 APP_ENV 被静默忽略
+func applyProfile(doc map[string]any, profile string) (map[string]any, error) {
+	raw, hasProfiles := doc[profilesKey]
+	delete(doc, profilesKey)
+
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return doc, nil
+	}
+	if !hasProfiles || raw == nil {
+		return nil, fmt.Errorf("未定义的配置 profile: %s (配置文件没有 profiles 段)", profile)
+	}
+
+	profiles, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profiles 必须是以名称为键的映射")
+	}
+
+	selected, ok := profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("未定义的配置 profile: %s (可选: %s)", profile, strings.Join(names, ", "))
+	}
+	if selected == nil {
+		return doc, nil
+	}
+
+	overlay, ok := selected.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profiles.%s 必须是映射", profile)
+	}
+
+	return mergeDocuments(doc, overlay), nil
+}
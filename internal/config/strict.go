@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// checkUnknownFields 检查配置文档中是否存在结构体未声明的字段，参数: 配置文档，返回: 汇总的 *ValidationError 或 nil
+// 未选中的 profile 同样会被检查，避免拼写错误潜伏到切换环境时才暴露
+func checkUnknownFields(doc map[string]any) error {
+	v := &validator{}
+	configType := reflect.TypeOf(Config{})
+
+	body := make(map[string]any, len(doc))
+	for key, value := range doc {
+		if key != profilesKey {
+			body[key] = value
+		}
+	}
+	walkUnknownFields(v, "", body, configType)
+
+	if profiles, ok := doc[profilesKey].(map[string]any); ok {
+		for name, overlay := range profiles {
+			if overlay == nil {
+				continue
+			}
+			walkUnknownFields(v, profilesKey+"."+name, overlay, configType)
+		}
+	}
+
+	sort.Slice(v.problems, func(i, j int) bool { return v.problems[i].Path < v.problems[j].Path })
+	return v.err()
+}
+
+// walkUnknownFields 递归比对文档节点与目标类型，参数: 收集器、当前路径、节点值、目标类型，返回: 无
+func walkUnknownFields(v *validator, path string, value any, typ reflect.Type) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		node, ok := value.(map[string]any)
+		if !ok {
+			return // 类型不匹配交由 YAML 解码报告
+		}
+		fields := yamlFields(typ)
+		for key, child := range node {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			fieldType, ok := fields[key]
+			if !ok {
+				if hint := closestField(key, fields); hint != "" {
+					v.add(childPath, "未知字段，是否想写 %q?", hint)
+				} else {
+					v.add(childPath, "未知字段")
+				}
+				continue
+			}
+			walkUnknownFields(v, childPath, child, fieldType)
+		}
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			walkUnknownFields(v, fmt.Sprintf("%s[%d]", path, i), item, typ.Elem())
+		}
+	case reflect.Map:
+		node, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, child := range node {
+			walkUnknownFields(v, path+"."+key, child, typ.Elem())
+		}
+	}
+}
+
+// yamlFields 获取结构体可被 YAML 设置的字段，参数: 结构体类型，返回: 字段名到类型的映射
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			for k, t := range yamlFields(field.Type) {
+				fields[k] = t
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// closestField 查找与未知字段最相近的合法字段名，参数: 未知字段与合法字段集合，返回: 建议字段 (无合适建议时为空)
+func closestField(key string, fields map[string]reflect.Type) string {
+	best, bestDist := "", 3 // 编辑距离超过 2 不给建议
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离，参数: 两个字符串，返回: Levenshtein 距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
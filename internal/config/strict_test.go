@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadRejectsUnknownFields 测试严格模式拒绝未知字段，参数: 测试实例，返回: 无
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, `
+port: "8080"
+server:
+  requst_timeout: 5
+translation:
+  service_type: "deeplx"
+  api_key: "sk-test"
+  providers:
+    - name: backup
+      service_type: deeplx
+      api_key: sk-backup
+      modle: gpt-4o
+profiles:
+  dev:
+    cache:
+      bogus_option: true
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	_, err := Load()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load() error = %v, 期望 *ValidationError", err)
+	}
+
+	want := map[string]string{
+		"server.requst_timeout":           `未知字段，是否想写 "request_timeout"?`,
+		"translation.providers[0].modle":  `未知字段，是否想写 "model"?`,
+		"profiles.dev.cache.bogus_option": "未知字段",
+	}
+	if len(verr.Problems) != len(want) {
+		t.Fatalf("Problems = %+v, 期望 %d 项", verr.Problems, len(want))
+	}
+	for _, p := range verr.Problems {
+		if msg, ok := want[p.Path]; !ok || msg != p.Message {
+			t.Errorf("问题 %s: %q 不符合预期 %q", p.Path, p.Message, msg)
+		}
+	}
+}
+
+// TestValidateReportsAllProblems 测试校验一次性报告全部问题，参数: 测试实例，返回: 无
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := Config{
+		Port: "0",
+		Cache: CacheConfig{
+			TTL: "forever",
+		},
+		Quota: QuotaConfig{Enabled: true, WarnPercent: 150},
+	}
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, 期望 *ValidationError", err)
+	}
+
+	paths := map[string]bool{}
+	for _, p := range verr.Problems {
+		paths[p.Path] = true
+	}
+	for _, path := range []string{
+		"port",
+		"translation.service_type",
+		"translation.api_key",
+		"cache.ttl",
+		"quota.daily_chars",
+		"quota.warn_percent",
+	} {
+		if !paths[path] {
+			t.Errorf("缺少对 %s 的问题报告, got %+v", path, verr.Problems)
+		}
+	}
+}
@@ -0,0 +1,846 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// FieldError 单个配置字段的校验问题
+type FieldError struct {
+	Path    string // 字段路径，如 translation.providers[0].api_key
+	Message string // 问题描述
+}
+
+// ValidationError 配置校验错误，汇总所有问题而非只报告第一个
+type ValidationError struct {
+	Problems []FieldError
+}
+
+// Error 实现 error 接口，参数: 无，返回: 多行错误描述
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败 (%d 项)", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s: %s", p.Path, p.Message)
+	}
+	return b.String()
+}
+
+// validator 校验问题收集器
+type validator struct {
+	problems []FieldError
+}
+
+// add 记录一个问题，参数: 字段路径与格式化消息，返回: 无
+func (v *validator) add(path, format string, args ...any) {
+	v.problems = append(v.problems, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// err 返回汇总错误，无问题时返回 nil
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// Validate 验证配置并报告所有问题，参数: 接收者 Config，返回: 校验失败时的 *ValidationError
+func (c *Config) Validate() error {
+	if c == nil {
+		return fmt.Errorf("配置不能为空")
+	}
+
+	v := &validator{}
+	validatePort(v, "port", c.Port)
+	validateServer(v, &c.Server)
+	validateTranslation(v, &c.Translation)
+	validateCache(v, &c.Cache)
+	if g := c.Translation.Glossary; g.Enabled && g.Redis && !c.Cache.Enabled {
+		v.add("translation.glossary.redis", "需要启用 cache")
+	}
+	validateQuota(v, &c.Quota)
+	validateUsage(v, &c.Usage, &c.Translation)
+
+	validateDuration(v, "admin.session_ttl", c.Admin.SessionTTL)
+	if hash := strings.TrimSpace(c.Admin.PasswordHash); hash != "" && !strings.HasPrefix(hash, "$2") {
+		v.add("admin.password_hash", "必须是 bcrypt 哈希 (以 $2a$/$2b$ 开头)")
+	}
+	if strings.TrimSpace(c.Admin.TOTPSecret) != "" && strings.TrimSpace(c.Admin.PasswordHash) == "" {
+		v.add("admin.totp_secret", "需要同时配置 password_hash，验证码不能单独作为登录凭据")
+	}
+
+	if c.Preferences.MaxClients < 0 {
+		v.add("preferences.max_clients", "不能为负数: %d", c.Preferences.MaxClients)
+	}
+	if pw := c.Preferences.Prewarm; pw.Enabled {
+		if !c.Preferences.Enabled {
+			v.add("preferences.prewarm", "需要启用 preferences")
+		}
+		validateDuration(v, "preferences.prewarm.interval", pw.Interval)
+		nonNegative(v, "preferences.prewarm.pairs", pw.Pairs)
+	}
+
+	validateAuth(v, &c.Auth)
+	validateTracing(v, &c.Tracing)
+	validateLogging(v, &c.Logging)
+	if (strings.TrimSpace(c.Metrics.Username) == "") != (c.Metrics.Password == "") {
+		v.add("metrics", "username 与 password 需要同时配置")
+	}
+
+	return v.err()
+}
+
+// validateTracing 校验分布式追踪配置，参数: 收集器与 TracingConfig 指针，返回: 无
+func validateTracing(v *validator, t *TracingConfig) {
+	if !t.Enabled {
+		return
+	}
+	if u := strings.TrimSpace(t.Endpoint); u != "" {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.add("tracing.endpoint", "必须是 http(s) 地址: %q", u)
+		}
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		v.add("tracing.sample_ratio", "必须在 0 到 1 之间: %v", t.SampleRatio)
+	}
+}
+
+// validateLogging 校验日志输出配置，参数: 收集器与 LoggingConfig 指针，返回: 无
+func validateLogging(v *validator, l *LoggingConfig) {
+	switch strings.ToLower(strings.TrimSpace(l.Format)) {
+	case "", "console", "json":
+	default:
+		v.add("logging.format", "仅支持 console 或 json: %q", l.Format)
+	}
+	for component, level := range l.Levels {
+		if !logComponents[component] {
+			v.add("logging.levels."+component, "未知的日志组件 (可选 access、cache、provider)")
+		}
+		if !logLevels[strings.ToLower(strings.TrimSpace(level))] {
+			v.add("logging.levels."+component, "无效的日志级别: %q", level)
+		}
+	}
+	nonNegative(v, "logging.sample_debug", l.SampleDebug)
+
+	if !l.AccessLog.Enabled {
+		return
+	}
+	nonNegative(v, "logging.access_log.max_size_mb", l.AccessLog.MaxSizeMB)
+	nonNegative(v, "logging.access_log.max_age_days", l.AccessLog.MaxAgeDays)
+	nonNegative(v, "logging.access_log.max_backups", l.AccessLog.MaxBackups)
+}
+
+// validateJWT 校验 JWT 认证配置，参数: 收集器与 AuthConfig 指针，返回: 无
+func validateJWT(v *validator, a *AuthConfig) {
+	j := &a.JWT
+	if !j.Enabled {
+		return
+	}
+	if !a.Enabled {
+		v.add("auth.jwt.enabled", "需要同时启用 auth.enabled")
+	}
+	if strings.TrimSpace(j.Secret) == "" && strings.TrimSpace(j.JWKSURL) == "" {
+		v.add("auth.jwt", "secret 与 jwks_url 至少配置一个")
+	}
+	if u := strings.TrimSpace(j.JWKSURL); u != "" {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.add("auth.jwt.jwks_url", "无效的 URL: %q", u)
+		}
+	}
+	validateDuration(v, "auth.jwt.jwks_refresh", j.JWKSRefresh)
+	if j.DailyChars < 0 {
+		v.add("auth.jwt.daily_chars", "不能为负数: %d", j.DailyChars)
+	}
+}
+
+// validateAuth 校验客户端认证配置，参数: 收集器与 AuthConfig 指针，返回: 无
+func validateAuth(v *validator, a *AuthConfig) {
+	if a.Enabled && len(a.Keys) == 0 && !a.JWT.Enabled {
+		v.add("auth.keys", "启用认证时至少需要配置一个密钥或启用 JWT")
+	}
+	validateJWT(v, a)
+
+	names := make(map[string]bool, len(a.Keys))
+	keys := make(map[string]bool, len(a.Keys))
+	for i, k := range a.Keys {
+		path := fmt.Sprintf("auth.keys[%d]", i)
+		name := strings.TrimSpace(k.Name)
+		switch {
+		case name == "":
+			v.add(path+".name", "不能为空")
+		case names[name]:
+			v.add(path+".name", "名称重复: %s", name)
+		}
+		names[name] = true
+
+		key := strings.TrimSpace(k.Key)
+		switch {
+		case key == "":
+			v.add(path+".key", "不能为空")
+		case keys[key]:
+			v.add(path+".key", "与其他密钥重复")
+		}
+		keys[key] = true
+
+		if k.RPS < 0 {
+			v.add(path+".rps", "不能为负数: %v", k.RPS)
+		}
+		nonNegative(v, path+".burst", k.Burst)
+		if k.DailyChars < 0 {
+			v.add(path+".daily_chars", "不能为负数: %d", k.DailyChars)
+		}
+		for j, endpoint := range k.Endpoints {
+			if !strings.HasPrefix(endpoint, "/") {
+				v.add(fmt.Sprintf("%s.endpoints[%d]", path, j), "必须以 / 开头: %q", endpoint)
+			}
+		}
+	}
+}
+
+// validateServer 校验服务器配置，参数: 收集器与 ServerConfig 指针，返回: 无
+func validateServer(v *validator, s *ServerConfig) {
+	nonNegative(v, "server.request_timeout", s.RequestTimeout)
+	nonNegative(v, "server.middleware_timeout", s.MiddlewareTimeout)
+	nonNegative(v, "server.shutdown_timeout", s.ShutdownTimeout)
+	nonNegative(v, "server.maintenance.retry_after", s.Maintenance.RetryAfter)
+	if size, err := bytes.Parse(s.GetMaxBodySize()); err != nil || size <= 0 {
+		v.add("server.max_body_size", "无效的大小: %q (示例: 2M、512K)", s.MaxBodySize)
+	}
+
+	if s.RateLimit.Enabled {
+		if s.RateLimit.RPS <= 0 {
+			v.add("server.rate_limit.rps", "启用限流时必须大于 0")
+		}
+		nonNegative(v, "server.rate_limit.burst", s.RateLimit.Burst)
+		for route, override := range s.RateLimit.Routes {
+			if !strings.HasPrefix(route, "/") {
+				v.add("server.rate_limit.routes."+route, "路由必须以 / 开头")
+			}
+			nonNegative(v, "server.rate_limit.routes."+route+".burst", override.Burst)
+		}
+	}
+
+	if _, err := s.GetTrustedProxies(); err != nil {
+		v.add("server.trusted_proxies", "%v", err)
+	}
+
+	if s.AccessControl.Enabled {
+		if _, err := s.AccessControl.GetAllow(); err != nil {
+			v.add("server.access_control.allow", "%v", err)
+		}
+		if _, err := s.AccessControl.GetDeny(); err != nil {
+			v.add("server.access_control.deny", "%v", err)
+		}
+	}
+
+	if s.AutoBan.Enabled {
+		nonNegative(v, "server.auto_ban.threshold", s.AutoBan.Threshold)
+		validateDuration(v, "server.auto_ban.window", s.AutoBan.Window)
+		validateDuration(v, "server.auto_ban.cooldown", s.AutoBan.Cooldown)
+	}
+
+	if s.TLS.Enabled {
+		validateCertificateSource(v, &s.TLS)
+		if version := strings.TrimSpace(s.TLS.MinVersion); version != "" {
+			if _, ok := tlsVersions[version]; !ok {
+				v.add("server.tls.min_version", "仅支持 1.2 或 1.3")
+			}
+		}
+		if _, err := s.TLS.GetCipherSuites(); err != nil {
+			v.add("server.tls.cipher_suites", "%v", err)
+		}
+	}
+	if s.TLS.ACME.Enabled && !s.TLS.Enabled {
+		v.add("server.tls.acme.enabled", "需要同时启用 server.tls")
+	}
+	validateClientCertificates(v, &s.TLS)
+
+	if s.Compression.Enabled {
+		nonNegative(v, "server.compression.min_length", s.Compression.MinLength)
+		if s.Compression.Level < 0 || s.Compression.Level > 9 {
+			v.add("server.compression.level", "必须在 0 到 9 之间: %d", s.Compression.Level)
+		}
+		for i, t := range s.Compression.ContentTypes {
+			if !strings.Contains(t, "/") {
+				v.add(fmt.Sprintf("server.compression.content_types[%d]", i), "无效的媒体类型: %q", t)
+			}
+		}
+	}
+
+	if s.Concurrency.Enabled {
+		nonNegative(v, "server.concurrency.max_in_flight", s.Concurrency.MaxInFlight)
+		nonNegative(v, "server.concurrency.max_queue", s.Concurrency.MaxQueue)
+		validateDuration(v, "server.concurrency.queue_timeout", s.Concurrency.QueueTimeout)
+		nonNegative(v, "server.concurrency.retry_after", s.Concurrency.RetryAfter)
+		names := make([]string, 0, len(s.Concurrency.Providers))
+		for name := range s.Concurrency.Providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		seen := make(map[string]string, len(names))
+		for _, name := range names {
+			limit := s.Concurrency.Providers[name]
+			if limit.MaxInFlight <= 0 {
+				v.add("server.concurrency.providers."+name+".max_in_flight", "必须大于 0")
+			}
+			nonNegative(v, "server.concurrency.providers."+name+".max_queue", limit.MaxQueue)
+			// 提供商名称不区分大小写，只差大小写的键会争用同一个隔离舱，生效的限制取决于遍历顺序
+			if other, ok := seen[strings.ToLower(name)]; ok {
+				v.add("server.concurrency.providers."+name, "与 %s 只有大小写不同，提供商名称不区分大小写", other)
+				continue
+			}
+			seen[strings.ToLower(name)] = name
+		}
+	}
+
+	if s.Ops.Enabled {
+		if _, _, err := net.SplitHostPort(s.Ops.GetAddr()); err != nil {
+			v.add("server.ops.addr", "必须是 host:port 格式: %v", err)
+		}
+	}
+}
+
+// validateCertificateSource 校验证书来源：证书文件与 ACME 二选一，参数: 收集器与 TLSConfig 指针，返回: 无
+func validateCertificateSource(v *validator, t *TLSConfig) {
+	if !t.ACME.Enabled {
+		if strings.TrimSpace(t.CertFile) == "" {
+			v.add("server.tls.cert_file", "启用 TLS 时必须设置")
+		}
+		if strings.TrimSpace(t.KeyFile) == "" {
+			v.add("server.tls.key_file", "启用 TLS 时必须设置")
+		}
+		return
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		v.add("server.tls.acme", "启用 ACME 时不能同时设置 cert_file/key_file")
+	}
+	if len(t.ACME.Hosts) == 0 {
+		v.add("server.tls.acme.hosts", "启用 ACME 时至少需要一个域名")
+	}
+	for i, host := range t.ACME.Hosts {
+		host = strings.TrimSpace(host)
+		if host == "" || strings.ContainsAny(host, ":/*") {
+			v.add(fmt.Sprintf("server.tls.acme.hosts[%d]", i), "无效的域名: %q", host)
+		}
+	}
+	if u := strings.TrimSpace(t.ACME.DirectoryURL); u != "" {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			v.add("server.tls.acme.directory_url", "必须是 https 地址")
+		}
+	}
+}
+
+// validateClientCertificates 校验客户端证书 (mTLS) 配置，参数: 收集器与 TLSConfig 指针，返回: 无
+func validateClientCertificates(v *validator, t *TLSConfig) {
+	mode := strings.ToLower(strings.TrimSpace(t.ClientAuth))
+	if _, ok := tlsClientAuthModes[mode]; !ok {
+		v.add("server.tls.client_auth", "仅支持 none、optional 或 require")
+		return
+	}
+
+	verifying := mode != "" && mode != "none"
+	if verifying && !t.Enabled {
+		v.add("server.tls.client_auth", "需要同时启用 server.tls")
+	}
+	if verifying && strings.TrimSpace(t.ClientCAFile) == "" {
+		v.add("server.tls.client_ca_file", "校验客户端证书时必须设置")
+	}
+	if t.ClientIdentity && !verifying {
+		v.add("server.tls.client_identity", "需要将 client_auth 设为 optional 或 require")
+	}
+}
+
+// validateTranslation 校验翻译配置，参数: 收集器与 TranslationConfig 指针，返回: 无
+func validateTranslation(v *validator, t *TranslationConfig) {
+	if strings.TrimSpace(t.ServiceType) == "" {
+		v.add("translation.service_type", "未设置")
+	}
+
+	defaultProvider := t.DefaultProvider()
+	validateProviderKeys(v, "translation", &defaultProvider)
+	validateKeyRotation(v, "translation.key_rotation", &t.KeyRotation)
+	validateEndpoints(v, "translation.endpoints", &t.Endpoints)
+	validateCapabilities(v, "translation.capabilities", &t.Capabilities)
+
+	nonNegative(v, "translation.timeout", t.Timeout)
+	validateAdaptiveTimeout(v, "translation.adaptive_timeout", &t.AdaptiveTimeout)
+	validatePrompt(v, "translation", &defaultProvider, t.Model)
+
+	for alias, target := range t.LanguageAliases {
+		if strings.TrimSpace(target) == "" {
+			v.add("translation.language_aliases."+alias, "目标语言代码不能为空")
+		}
+	}
+
+	policyLists := map[string][]string{
+		"allow_sources": t.LanguagePolicy.AllowSources,
+		"deny_sources":  t.LanguagePolicy.DenySources,
+		"allow_targets": t.LanguagePolicy.AllowTargets,
+		"deny_targets":  t.LanguagePolicy.DenyTargets,
+	}
+	for _, name := range []string{"allow_sources", "deny_sources", "allow_targets", "deny_targets"} {
+		for i, code := range policyLists[name] {
+			if strings.TrimSpace(code) == "" {
+				v.add(fmt.Sprintf("translation.language_policy.%s[%d]", name, i), "语言代码不能为空")
+			}
+		}
+	}
+
+	if t.Attribution.Enabled && !validHeaderName(t.Attribution.GetHeader()) {
+		v.add("translation.attribution.header", "不是合法的 HTTP 头名称: %q", t.Attribution.Header)
+	}
+
+	seen := map[string]bool{defaultProvider.GetName(): true}
+	for i, p := range t.Providers {
+		path := fmt.Sprintf("translation.providers[%d]", i)
+		if strings.TrimSpace(p.ServiceType) == "" {
+			v.add(path+".service_type", "未设置")
+		}
+		validateProviderKeys(v, path, &p)
+		validateKeyRotation(v, path+".key_rotation", &p.KeyRotation)
+		validateEndpoints(v, path+".endpoints", &p.Endpoints)
+		validateCapabilities(v, path+".capabilities", &p.Capabilities)
+		nonNegative(v, path+".timeout", p.Timeout)
+		validateAdaptiveTimeout(v, path+".adaptive_timeout", &p.AdaptiveTimeout)
+		validatePrompt(v, path, &p, t.Model)
+
+		name := p.GetName()
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			v.add(path+".name", "名称重复: %s", name)
+		}
+		seen[name] = true
+	}
+
+	if t.Failover.Enabled {
+		validateDuration(v, "translation.failover.attempt_timeout", t.Failover.AttemptTimeout)
+		for i, name := range t.Failover.Order {
+			if _, ok := t.FindProvider(name); !ok {
+				v.add(fmt.Sprintf("translation.failover.order[%d]", i), "未知的提供商: %s", name)
+			}
+		}
+		if len(t.FailoverProviders()) < 2 {
+			v.add("translation.failover", "至少需要两个提供商")
+		}
+	}
+
+	if t.Batching.Enabled {
+		nonNegative(v, "translation.batching.max_size", t.Batching.MaxSize)
+		validateDuration(v, "translation.batching.max_wait", t.Batching.MaxWait)
+	}
+
+	if t.Glossary.Enabled {
+		for i, set := range t.Glossary.Sets {
+			path := fmt.Sprintf("translation.glossary.sets[%d]", i)
+			if strings.TrimSpace(set.Target) == "" {
+				v.add(path+".target", "未设置")
+			}
+			for term := range set.Terms {
+				if strings.TrimSpace(term) == "" {
+					v.add(path+".terms", "术语不能为空")
+				}
+			}
+		}
+	}
+
+	switch t.Normalize.GetEmoji() {
+	case EmojiKeep, EmojiStrip:
+	default:
+		v.add("translation.normalize.emoji", "必须为 keep 或 strip: %q", t.Normalize.Emoji)
+	}
+
+	if t.Rewrite.Enabled {
+		if len(t.Rewrite.Rules) == 0 {
+			v.add("translation.rewrite.rules", "未配置规则")
+		}
+		for i, rule := range t.Rewrite.Rules {
+			path := fmt.Sprintf("translation.rewrite.rules[%d].pattern", i)
+			if rule.Pattern == "" {
+				v.add(path, "未设置")
+			} else if _, err := regexp.Compile(rule.Pattern); err != nil {
+				v.add(path, "正则无效: %v", err)
+			}
+		}
+	}
+
+	if t.Profanity.Enabled {
+		switch t.Profanity.GetMode() {
+		case ProfanityModeMask, ProfanityModeReject:
+		default:
+			v.add("translation.profanity.mode", "必须为 mask 或 reject: %q", t.Profanity.Mode)
+		}
+		if len(t.Profanity.Words) == 0 && strings.TrimSpace(t.Profanity.File) == "" {
+			v.add("translation.profanity", "未配置 words 或 file")
+		}
+	}
+
+	if t.Memory.Enabled && (t.Memory.MinScore < 0 || t.Memory.MinScore > 1) {
+		v.add("translation.memory.min_score", "必须在 0 到 1 之间: %v", t.Memory.MinScore)
+	}
+
+	if t.Hedging.Enabled {
+		validateDuration(v, "translation.hedging.delay", t.Hedging.Delay)
+		defaultProvider := t.DefaultProvider()
+		switch backup, ok := t.FindProvider(t.Hedging.Provider); {
+		case !ok:
+			v.add("translation.hedging.provider", "未知的提供商: %q", t.Hedging.Provider)
+		case backup.GetName() == defaultProvider.GetName():
+			v.add("translation.hedging.provider", "不能是默认提供商: %s", t.Hedging.Provider)
+		}
+		if t.Failover.Enabled {
+			v.add("translation.hedging", "不能与 failover 同时启用")
+		}
+	}
+
+	if t.Comparison.Enabled {
+		defaultProvider := t.DefaultProvider()
+		switch secondary, ok := t.FindProvider(t.Comparison.Provider); {
+		case !ok:
+			v.add("translation.comparison.provider", "未知的提供商: %q", t.Comparison.Provider)
+		case secondary.GetName() == defaultProvider.GetName():
+			v.add("translation.comparison.provider", "不能是默认提供商: %s", t.Comparison.Provider)
+		}
+	}
+
+	if t.Shadow.Enabled {
+		defaultProvider := t.DefaultProvider()
+		switch shadow, ok := t.FindProvider(t.Shadow.Provider); {
+		case !ok:
+			v.add("translation.shadow.provider", "未知的提供商: %q", t.Shadow.Provider)
+		case shadow.GetName() == defaultProvider.GetName():
+			v.add("translation.shadow.provider", "不能是默认提供商: %s", t.Shadow.Provider)
+		}
+		if t.Shadow.SampleRate < 0 || t.Shadow.SampleRate > 1 {
+			v.add("translation.shadow.sample_rate", "必须在 0 到 1 之间: %v", t.Shadow.SampleRate)
+		}
+		nonNegative(v, "translation.shadow.max_in_flight", t.Shadow.MaxInFlight)
+	}
+
+	if t.ProviderOverride.Enabled {
+		for i, name := range t.ProviderOverride.Allow {
+			if _, ok := t.FindProvider(name); !ok {
+				v.add(fmt.Sprintf("translation.provider_override.allow[%d]", i), "未知的提供商: %q", name)
+			}
+		}
+	}
+
+	if t.Routing.Enabled {
+		if len(t.Routing.Rules) == 0 {
+			v.add("translation.routing.rules", "未配置规则")
+		}
+		for i, rule := range t.Routing.Rules {
+			path := fmt.Sprintf("translation.routing.rules[%d]", i)
+			if _, ok := t.FindProvider(rule.Provider); !ok {
+				v.add(path+".provider", "未知的提供商: %q", rule.Provider)
+			}
+			nonNegative(v, path+".min_length", rule.MinLength)
+			nonNegative(v, path+".max_length", rule.MaxLength)
+			if rule.MaxLength > 0 && rule.MaxLength < rule.MinLength {
+				v.add(path+".max_length", "不能小于 min_length (%d)", rule.MinLength)
+			}
+		}
+	}
+
+	if t.Canary.Enabled {
+		p, ok := t.FindProvider(t.Canary.Provider)
+		defaultProvider := t.DefaultProvider()
+		switch {
+		case !ok:
+			v.add("translation.canary.provider", "未知的提供商: %q", t.Canary.Provider)
+		case p.GetName() == defaultProvider.GetName():
+			v.add("translation.canary.provider", "不能是默认提供商: %q", t.Canary.Provider)
+		}
+		if t.Canary.Percent < 0 || t.Canary.Percent > 100 {
+			v.add("translation.canary.percent", "必须在 0-100 之间: %v", t.Canary.Percent)
+		}
+	}
+
+	if cb := t.CircuitBreaker; cb.Enabled {
+		nonNegative(v, "translation.circuit_breaker.window", cb.Window)
+		nonNegative(v, "translation.circuit_breaker.min_calls", cb.MinCalls)
+		nonNegative(v, "translation.circuit_breaker.half_open_probes", cb.HalfOpenProbes)
+		if cb.ErrorRate < 0 || cb.ErrorRate > 1 {
+			v.add("translation.circuit_breaker.error_rate", "必须在 0 到 1 之间: %v", cb.ErrorRate)
+		}
+		if cb.SlowRate < 0 || cb.SlowRate > 1 {
+			v.add("translation.circuit_breaker.slow_rate", "必须在 0 到 1 之间: %v", cb.SlowRate)
+		}
+		validateDuration(v, "translation.circuit_breaker.slow_call", cb.SlowCall)
+		validateDuration(v, "translation.circuit_breaker.open_duration", cb.OpenDuration)
+	}
+
+	if hc := t.HealthCheck; hc.Enabled {
+		validateDuration(v, "translation.health_check.interval", hc.Interval)
+		validateDuration(v, "translation.health_check.timeout", hc.Timeout)
+		nonNegative(v, "translation.health_check.failure_threshold", hc.FailureThreshold)
+	}
+
+	if rb := t.RetryBudget; rb.Enabled {
+		if rb.Ratio < 0 || rb.Ratio > 1 {
+			v.add("translation.retry_budget.ratio", "必须在 0 到 1 之间: %v", rb.Ratio)
+		}
+		if rb.MinPerSecond < 0 {
+			v.add("translation.retry_budget.min_per_second", "不能为负数: %v", rb.MinPerSecond)
+		}
+		if rb.MaxTokens < 0 {
+			v.add("translation.retry_budget.max_tokens", "不能为负数: %v", rb.MaxTokens)
+		}
+	}
+}
+
+// validateProviderKeys 校验提供商的上游密钥：使用默认地址时必须配置密钥，配置了 key_pattern 时密钥须符合格式，参数: 收集器、提供商路径与 ProviderConfig 指针，返回: 无
+func validateProviderKeys(v *validator, path string, p *ProviderConfig) {
+	if p.KeyPattern == "" {
+		if !p.HasAPIKey() && !p.HasCustomEndpoint() {
+			v.add(path+".api_key", "未设置 (使用默认地址时必填，无需密钥的自建中继需配置 base_url)")
+		}
+		return
+	}
+	re, err := regexp.Compile(p.KeyPattern)
+	if err != nil {
+		v.add(path+".key_pattern", "无效的正则表达式: %v", err)
+		return
+	}
+	if !p.HasAPIKey() && !re.MatchString("") {
+		v.add(path+".api_key", "未设置 (key_pattern 要求配置密钥)")
+	}
+	if p.APIKey != "" && !re.MatchString(p.APIKey) {
+		v.add(path+".api_key", "不符合 key_pattern")
+	}
+	for i, key := range p.KeyRotation.Keys {
+		if key.Key != "" && !re.MatchString(key.Key) {
+			v.add(fmt.Sprintf("%s.key_rotation.keys[%d].key", path, i), "不符合 key_pattern")
+		}
+	}
+}
+
+// validateKeyRotation 校验上游多密钥轮换配置，参数: 收集器、字段路径与 KeyRotationConfig 指针，返回: 无
+func validateKeyRotation(v *validator, path string, k *KeyRotationConfig) {
+	switch k.Strategy {
+	case "", "round_robin", "weighted":
+	default:
+		v.add(path+".strategy", "仅支持 round_robin 或 weighted: %q", k.Strategy)
+	}
+	validateDuration(v, path+".quarantine", k.Quarantine)
+	for i, key := range k.Keys {
+		if strings.TrimSpace(key.Key) == "" {
+			v.add(fmt.Sprintf("%s.keys[%d].key", path, i), "未设置")
+		}
+		nonNegative(v, fmt.Sprintf("%s.keys[%d].weight", path, i), key.Weight)
+	}
+}
+
+// validateEndpoints 校验多中继地址配置，参数: 收集器、字段路径与 EndpointsConfig 指针，返回: 无
+func validateEndpoints(v *validator, path string, e *EndpointsConfig) {
+	for i, u := range e.URLs {
+		if parsed, err := url.Parse(strings.TrimSpace(u)); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.add(fmt.Sprintf("%s.urls[%d]", path, i), "必须是 http(s) 地址: %q", u)
+		}
+	}
+	validateDuration(v, path+".probe_interval", e.ProbeInterval)
+	validateDuration(v, path+".probe_timeout", e.ProbeTimeout)
+	validateDuration(v, path+".eviction", e.Eviction)
+	nonNegative(v, path+".max_failures", e.MaxFailures)
+}
+
+// validateCapabilities 校验提供商能力配置，参数: 收集器、字段路径与 CapabilitiesConfig 指针，返回: 无
+func validateCapabilities(v *validator, path string, c *CapabilitiesConfig) {
+	for i, code := range c.Languages {
+		if strings.TrimSpace(code) == "" {
+			v.add(fmt.Sprintf("%s.languages[%d]", path, i), "语言代码不能为空")
+		}
+	}
+	nonNegative(v, path+".max_text_length", c.MaxTextLength)
+}
+
+// validateAdaptiveTimeout 校验自适应超时配置，参数: 收集器、配置路径与配置，返回: 无
+func validateAdaptiveTimeout(v *validator, path string, a *AdaptiveTimeoutConfig) {
+	if !a.Enabled {
+		return
+	}
+	validateDuration(v, path+".base", a.Base)
+	validateDuration(v, path+".per_kb", a.PerKB)
+	validateDuration(v, path+".max", a.Max)
+	if a.GetMax() < a.GetBase() {
+		v.add(path+".max", "不能小于 base (%s)", a.GetBase())
+	}
+}
+
+// validatePrompt 校验提示词模板语法，openai 类型的提供商还须配置模型，参数: 收集器、提供商路径、ProviderConfig 指针与 translation.model，返回: 无
+func validatePrompt(v *validator, path string, p *ProviderConfig, defaultModel string) {
+	templates := []struct{ name, text string }{{"system", p.Prompt.System}, {"user", p.Prompt.User}}
+	for _, tmpl := range templates {
+		if _, err := template.New(tmpl.name).Parse(tmpl.text); err != nil {
+			v.add(path+".prompt."+tmpl.name, "无效的模板: %v", err)
+		}
+	}
+	if strings.EqualFold(strings.TrimSpace(p.ServiceType), "openai") && strings.TrimSpace(p.Model) == "" && strings.TrimSpace(defaultModel) == "" {
+		v.add(path+".model", "openai 类型的提供商必须设置 (或设置 translation.model)")
+	}
+}
+
+// validateCache 校验缓存配置，参数: 收集器与 CacheConfig 指针，返回: 无
+func validateCache(v *validator, c *CacheConfig) {
+	if c.Enabled && strings.TrimSpace(c.Addr) == "" {
+		v.add("cache.addr", "启用缓存时必须设置")
+	}
+	validateDuration(v, "cache.ttl", c.TTL)
+	validateDuration(v, "cache.detection_ttl", c.DetectionTTL)
+	nonNegative(v, "cache.db", c.DB)
+	nonNegative(v, "cache.pool_size", c.PoolSize)
+	nonNegative(v, "cache.dial_timeout", c.DialTimeout)
+	nonNegative(v, "cache.read_timeout", c.ReadTimeout)
+	nonNegative(v, "cache.write_timeout", c.WriteTimeout)
+}
+
+// validateQuota 校验额度配置，参数: 收集器与 QuotaConfig 指针，返回: 无
+func validateQuota(v *validator, q *QuotaConfig) {
+	if q.Enabled && q.DailyChars <= 0 {
+		v.add("quota.daily_chars", "启用额度时必须大于 0")
+	}
+	if q.WarnPercent < 0 || q.WarnPercent > 100 {
+		v.add("quota.warn_percent", "必须在 0-100 之间: %d", q.WarnPercent)
+	}
+}
+
+// validateUsage 校验用量统计的价格表，参数: 收集器、UsageConfig 指针与 TranslationConfig 指针 (用于检查提供商名称)，返回: 无
+func validateUsage(v *validator, u *UsageConfig, t *TranslationConfig) {
+	for i, price := range u.Pricing {
+		path := fmt.Sprintf("usage.pricing[%d]", i)
+		if _, ok := t.FindProvider(price.Provider); !ok {
+			v.add(path+".provider", "未知的提供商: %q", price.Provider)
+		}
+		fields := []struct {
+			name  string
+			value float64
+		}{
+			{"per_million_chars", price.PerMillionChars},
+			{"per_million_input_tokens", price.PerMillionInputTokens},
+			{"per_million_output_tokens", price.PerMillionOutputTokens},
+			{"chars_per_token", price.CharsPerToken},
+		}
+		for _, field := range fields {
+			if field.value < 0 {
+				v.add(path+"."+field.name, "不能为负数: %v", field.value)
+			}
+		}
+	}
+	validateBudgets(v, u, t)
+}
+
+// validateBudgets 校验费用预算与告警 Webhook，参数: 收集器、UsageConfig 指针与 TranslationConfig 指针，返回: 无
+func validateBudgets(v *validator, u *UsageConfig, t *TranslationConfig) {
+	if len(u.Budgets) > 0 && !u.Enabled {
+		v.add("usage.budgets", "需要开启 usage.enabled")
+	}
+	priced := make(map[string]bool, len(u.Pricing))
+	for _, price := range u.Pricing {
+		priced[strings.ToLower(strings.TrimSpace(price.Provider))] = true
+	}
+	seen := make(map[string]bool, len(u.Budgets))
+	for i, budget := range u.Budgets {
+		path := fmt.Sprintf("usage.budgets[%d]", i)
+		provider, ok := t.FindProvider(budget.Provider)
+		if !ok {
+			v.add(path+".provider", "未知的提供商: %q", budget.Provider)
+		} else {
+			if seen[provider.GetName()] {
+				v.add(path+".provider", "重复的提供商: %q", budget.Provider)
+			}
+			seen[provider.GetName()] = true
+			if !priced[strings.ToLower(strings.TrimSpace(budget.Provider))] {
+				v.add(path+".provider", "未在 usage.pricing 中配置价格，无法统计费用: %q", budget.Provider)
+			}
+		}
+		if budget.Daily < 0 {
+			v.add(path+".daily", "不能为负数: %v", budget.Daily)
+		}
+		if budget.Monthly < 0 {
+			v.add(path+".monthly", "不能为负数: %v", budget.Monthly)
+		}
+		if budget.Daily <= 0 && budget.Monthly <= 0 {
+			v.add(path, "daily 与 monthly 至少设置一个")
+		}
+		if budget.AlertPercent < 0 || budget.AlertPercent > 100 {
+			v.add(path+".alert_percent", "必须在 0-100 之间: %d", budget.AlertPercent)
+		}
+		switch budget.GetAction() {
+		case BudgetActionReject:
+		case BudgetActionFallback:
+			fallback, ok := t.FindProvider(budget.Fallback)
+			switch {
+			case strings.TrimSpace(budget.Fallback) == "":
+				v.add(path+".fallback", "action 为 fallback 时必须设置")
+			case !ok:
+				v.add(path+".fallback", "未知的提供商: %q", budget.Fallback)
+			case provider.GetName() == fallback.GetName():
+				v.add(path+".fallback", "不能与预算的提供商相同: %q", budget.Fallback)
+			}
+		default:
+			v.add(path+".action", "必须是 reject 或 fallback: %q", budget.Action)
+		}
+	}
+	if webhook := strings.TrimSpace(u.AlertWebhook); webhook != "" {
+		if parsed, err := url.Parse(webhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.add("usage.alert_webhook", "必须是 http(s) 地址: %q", webhook)
+		}
+	}
+}
+
+// validatePort 校验端口，参数: 收集器、字段路径、端口字符串，返回: 无
+func validatePort(v *validator, path, port string) {
+	port = strings.TrimSpace(port)
+	if port == "" {
+		v.add(path, "未设置")
+		return
+	}
+
+	value, err := strconv.Atoi(port)
+	if err != nil || value <= 0 || value > 65535 {
+		v.add(path, "端口无效: %s", port)
+	}
+}
+
+// validateDuration 校验可选的时长字符串，参数: 收集器、字段路径、时长字符串，返回: 无
+func validateDuration(v *validator, path, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.add(path, "无法解析的时长 %q (示例: 30s, 24h)", value)
+		return
+	}
+	if d < 0 {
+		v.add(path, "不能为负数: %s", value)
+	}
+}
+
+// nonNegative 校验整数字段非负，参数: 收集器、字段路径、数值，返回: 无
+func nonNegative(v *validator, path string, value int) {
+	if value < 0 {
+		v.add(path, "不能为负数: %d", value)
+	}
+}
+
+// validHeaderName 判断是否为合法的 HTTP 头名称，参数: 头名称，返回: 布尔
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	return http.CanonicalHeaderKey(name) != "" && !strings.ContainsAny(name, " \t\r\n:")
+}
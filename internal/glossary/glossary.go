@@ -0,0 +1,228 @@
+// Package glossary 术语表：按语言对维护 术语→译法 映射，翻译前把术语替换为占位符保护起来，
+// 翻译后还原为指定译法，使产品名与固定术语在不同提供商之间保持一致
+package glossary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnySource 适用于任意源语言 (包括自动检测) 的术语集
+const AnySource = "*"
+
+// Set 一个语言对的术语集
+type Set struct {
+	Source string            `yaml:"source"` // 源语言代码，* 表示任意源语言
+	Target string            `yaml:"target"` // 目标语言代码
+	Terms  map[string]string `yaml:"terms"`  // 术语 → 译法
+}
+
+// Store 术语来源
+type Store interface {
+	// Terms 返回语言对适用的术语，参数: 上下文、源语言与目标语言，返回: 术语 → 译法 与错误
+	Terms(ctx context.Context, source, target string) (map[string]string, error)
+}
+
+// pairKey 生成语言对键，参数: 源语言与目标语言，返回: 小写的 source:target
+func pairKey(source, target string) string {
+	return strings.ToLower(strings.TrimSpace(source)) + ":" + strings.ToLower(strings.TrimSpace(target))
+}
+
+// lookupSources 返回需要查询的源语言 (任意源语言在前，具体源语言在后以便覆盖)，参数: 请求的源语言，返回: 源语言列表
+func lookupSources(source string) []string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source == "" || source == "auto" || source == AnySource {
+		return []string{AnySource}
+	}
+	return []string{AnySource, source}
+}
+
+// StaticStore 基于配置或文件的静态术语表，构建后只读，并发安全
+type StaticStore struct {
+	pairs map[string]map[string]string
+}
+
+// NewStaticStore 创建静态术语表，同一语言对的多个术语集按顺序合并，参数: 术语集列表，返回: StaticStore 指针
+func NewStaticStore(sets []Set) *StaticStore {
+	s := &StaticStore{pairs: make(map[string]map[string]string)}
+	for _, set := range sets {
+		source := set.Source
+		if strings.TrimSpace(source) == "" {
+			source = AnySource
+		}
+		key := pairKey(source, set.Target)
+		terms, ok := s.pairs[key]
+		if !ok {
+			terms = make(map[string]string, len(set.Terms))
+			s.pairs[key] = terms
+		}
+		for term, translation := range set.Terms {
+			terms[term] = translation
+		}
+	}
+	return s
+}
+
+// Terms 返回语言对适用的术语，具体源语言的条目覆盖任意源语言的同名条目，参数: 上下文、源语言与目标语言，返回: 术语映射与错误
+func (s *StaticStore) Terms(_ context.Context, source, target string) (map[string]string, error) {
+	merged := make(map[string]string)
+	for _, src := range lookupSources(source) {
+		for term, translation := range s.pairs[pairKey(src, target)] {
+			merged[term] = translation
+		}
+	}
+	return merged, nil
+}
+
+// LoadFile 从 YAML 文件读取术语集列表，参数: 文件路径，返回: 术语集与错误
+func LoadFile(path string) ([]Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取术语表文件失败: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var sets []Set
+	if err := dec.Decode(&sets); err != nil {
+		return nil, fmt.Errorf("解析术语表文件 %s 失败: %w", path, err)
+	}
+	for i, set := range sets {
+		if strings.TrimSpace(set.Target) == "" {
+			return nil, fmt.Errorf("术语表文件 %s 第 %d 个术语集未设置 target", path, i+1)
+		}
+	}
+	return sets, nil
+}
+
+// Chain 依次合并多个术语来源，后面的来源覆盖前面的同名术语
+type Chain []Store
+
+// Terms 返回合并后的术语，参数: 上下文、源语言与目标语言，返回: 术语映射与第一个来源错误 (其余来源照常合并)
+func (c Chain) Terms(ctx context.Context, source, target string) (map[string]string, error) {
+	merged := make(map[string]string)
+	var firstErr error
+	for _, store := range c {
+		terms, err := store.Terms(ctx, source, target)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for term, translation := range terms {
+			merged[term] = translation
+		}
+	}
+	return merged, firstErr
+}
+
+// Match 文本中被保护的一个术语
+type Match struct {
+	Term        string // 原文中的术语
+	Translation string // 指定译法
+}
+
+// placeholderPattern 匹配占位符，容忍提供商在括号内插入空格
+var placeholderPattern = regexp.MustCompile(`\[\[\s*G\s*(\d+)\s*\]\]`)
+
+// placeholder 生成第 i 个术语的占位符，参数: 序号，返回: 占位符
+func placeholder(i int) string {
+	return "[[G" + strconv.Itoa(i) + "]]"
+}
+
+// compile 把术语编译为一个正则 (长术语优先，以字母数字开头或结尾的术语要求单词边界)，参数: 术语映射，返回: 正则 (无术语时为 nil)
+func compile(terms map[string]string) *regexp.Regexp {
+	keys := make([]string, 0, len(terms))
+	for term := range terms {
+		if strings.TrimSpace(term) != "" {
+			keys = append(keys, term)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	alternatives := make([]string, len(keys))
+	for i, term := range keys {
+		pattern := regexp.QuoteMeta(term)
+		if first, _ := utf8.DecodeRuneInString(term); isWordRune(first) {
+			pattern = `\b` + pattern
+		}
+		if last, _ := utf8.DecodeLastRuneInString(term); isWordRune(last) {
+			pattern += `\b`
+		}
+		alternatives[i] = pattern
+	}
+	return regexp.MustCompile(strings.Join(alternatives, "|"))
+}
+
+// isWordRune 判断字符是否属于 \b 意义上的单词字符，参数: 字符，返回: 布尔
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// Protect 把文本中的术语替换为占位符，参数: 文本与术语映射，返回: 替换后的文本与按占位符序号排列的术语
+func Protect(text string, terms map[string]string) (string, []Match) {
+	re := compile(terms)
+	if re == nil {
+		return text, nil
+	}
+	var matches []Match
+	protected := re.ReplaceAllStringFunc(text, func(term string) string {
+		matches = append(matches, Match{Term: term, Translation: terms[term]})
+		return placeholder(len(matches) - 1)
+	})
+	return protected, matches
+}
+
+// Restore 把译文中的占位符还原为指定译法，参数: 译文与 Protect 返回的术语，返回: 还原后的译文
+func Restore(text string, matches []Match) string {
+	return replacePlaceholders(text, matches, func(m Match) string { return m.Translation })
+}
+
+// RestoreOriginal 把原文中的占位符还原为原始术语，参数: 文本与 Protect 返回的术语，返回: 还原后的文本
+func RestoreOriginal(text string, matches []Match) string {
+	return replacePlaceholders(text, matches, func(m Match) string { return m.Term })
+}
+
+// replacePlaceholders 替换占位符，序号越界的占位符保持原样，参数: 文本、术语与取值函数，返回: 替换后的文本
+func replacePlaceholders(text string, matches []Match, value func(Match) string) string {
+	if len(matches) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(s string) string {
+		i, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(s)[1])
+		if err != nil || i >= len(matches) {
+			return s
+		}
+		return value(matches[i])
+	})
+}
+
+// Substitute 把译文中残留的原始术语 (提供商未翻译的部分) 替换为指定译法，参数: 译文与术语映射，返回: 替换后的译文
+// 应在 Restore 之前调用，避免已还原的译法被再次匹配
+func Substitute(text string, terms map[string]string) string {
+	re := compile(terms)
+	if re == nil {
+		return text
+	}
+	return re.ReplaceAllStringFunc(text, func(term string) string {
+		return terms[term]
+	})
+}
@@ -0,0 +1,80 @@
+package glossary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProtectRestore 测试术语保护、长术语优先、单词边界与占位符还原，参数: 测试实例，返回: 无
+func TestProtectRestore(t *testing.T) {
+	terms := map[string]string{
+		"Go":           "Go 语言",
+		"pull request": "合并请求",
+		"pull":         "拉取",
+	}
+
+	protected, matches := Protect("Open a pull request in Go, not Google.", terms)
+	if protected != "Open a [[G0]] in [[G1]], not Google." {
+		t.Fatalf("Protect() = %q", protected)
+	}
+	if len(matches) != 2 || matches[0].Term != "pull request" || matches[1].Translation != "Go 语言" {
+		t.Fatalf("matches = %+v", matches)
+	}
+
+	// 提供商可能在占位符内插入空格
+	if got := Restore("在 [[ G1 ]] 中打开一个[[G0]]，[[G9]]", matches); got != "在 Go 语言 中打开一个合并请求，[[G9]]" {
+		t.Errorf("Restore() = %q", got)
+	}
+	if got := RestoreOriginal(protected, matches); got != "Open a pull request in Go, not Google." {
+		t.Errorf("RestoreOriginal() = %q", got)
+	}
+	if got := Substitute("请 pull 代码", terms); got != "请 拉取 代码" {
+		t.Errorf("Substitute() = %q", got)
+	}
+}
+
+// TestStaticStore 测试语言对查找与任意源语言术语的合并覆盖，参数: 测试实例，返回: 无
+func TestStaticStore(t *testing.T) {
+	store := NewStaticStore([]Set{
+		{Source: "*", Target: "zh-CN", Terms: map[string]string{"Kubernetes": "Kubernetes", "cluster": "群集"}},
+		{Source: "en", Target: "zh-cn", Terms: map[string]string{"cluster": "集群"}},
+	})
+
+	terms, _ := store.Terms(context.Background(), "EN", "zh-CN")
+	if terms["cluster"] != "集群" || terms["Kubernetes"] != "Kubernetes" {
+		t.Errorf("en→zh-CN terms = %v", terms)
+	}
+	terms, _ = store.Terms(context.Background(), "auto", "zh-CN")
+	if terms["cluster"] != "群集" {
+		t.Errorf("auto→zh-CN terms = %v", terms)
+	}
+	if terms, _ = store.Terms(context.Background(), "en", "ja"); len(terms) != 0 {
+		t.Errorf("en→ja terms = %v, want empty", terms)
+	}
+}
+
+// TestLoadFile 测试从 YAML 文件读取术语集，参数: 测试实例，返回: 无
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "glossary.yaml")
+	content := "- source: en\n  target: zh-CN\n  terms:\n    Kubernetes: Kubernetes\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sets, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(sets) != 1 || sets[0].Terms["Kubernetes"] != "Kubernetes" {
+		t.Errorf("sets = %+v", sets)
+	}
+
+	if err := os.WriteFile(path, []byte("- source: en\n  terms: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("缺少 target 时应返回错误")
+	}
+}
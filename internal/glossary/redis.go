@@ -0,0 +1,46 @@
+package glossary
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 基于 Redis 哈希的术语表，每个语言对一个哈希 (字段为术语，值为译法)，可在运行中修改并在多实例间共享
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 术语表，参数: Redis 客户端与键前缀 (为空时使用 glossary:)，返回: RedisStore 指针
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "glossary:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Key 返回语言对对应的哈希键，参数: 源语言 (* 表示任意源语言) 与目标语言，返回: 键
+func (r *RedisStore) Key(source, target string) string {
+	return r.prefix + pairKey(source, target)
+}
+
+// Terms 返回语言对适用的术语，具体源语言的条目覆盖任意源语言的同名条目，参数: 上下文、源语言与目标语言，返回: 术语映射与错误
+func (r *RedisStore) Terms(ctx context.Context, source, target string) (map[string]string, error) {
+	sources := lookupSources(source)
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(sources))
+	for i, src := range sources {
+		cmds[i] = pipe.HGetAll(ctx, r.Key(src, target))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]string)
+	for _, cmd := range cmds {
+		for term, translation := range cmd.Val() {
+			merged[term] = translation
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,164 @@
+// Package langpref 按客户端学习常用的语言方向
+package langpref
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxClients 默认最多跟踪的客户端数量
+const defaultMaxClients = 10000
+
+// Pair 语言方向及其使用次数
+type Pair struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Count  int64  `json:"count"`
+}
+
+// clientStats 单个客户端的统计
+type clientStats struct {
+	pairs    map[[2]string]int64
+	lastSeen time.Time
+}
+
+// Tracker 语言偏好跟踪器，并发安全
+type Tracker struct {
+	mu         sync.Mutex
+	clients    map[string]*clientStats
+	maxClients int
+	now        func() time.Time
+}
+
+// NewTracker 创建偏好跟踪器，参数: 最大客户端数 (<=0 使用默认值)，返回: Tracker 指针
+func NewTracker(maxClients int) *Tracker {
+	if maxClients <= 0 {
+		maxClients = defaultMaxClients
+	}
+	return &Tracker{
+		clients:    make(map[string]*clientStats),
+		maxClients: maxClients,
+		now:        time.Now,
+	}
+}
+
+// Record 记录一次翻译的语言方向，参数: 客户端标识、源语言、目标语言，返回: 无
+func (t *Tracker) Record(client, source, target string) {
+	source = strings.TrimSpace(source)
+	target = strings.TrimSpace(target)
+	if client == "" || target == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.clients[client]
+	if !ok {
+		if len(t.clients) >= t.maxClients {
+			t.evictOldestLocked()
+		}
+		stats = &clientStats{pairs: make(map[[2]string]int64)}
+		t.clients[client] = stats
+	}
+	stats.pairs[[2]string{source, target}]++
+	stats.lastSeen = t.now()
+}
+
+// Top 返回客户端最常用的语言方向，参数: 客户端标识与数量上限，返回: 按次数降序的语言方向
+func (t *Tracker) Top(client string, n int) []Pair {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.clients[client]
+	if !ok {
+		return nil
+	}
+	return topPairs(stats, n)
+}
+
+// PreferredTarget 返回客户端最常用的目标语言，参数: 客户端标识，返回: 目标语言与是否存在
+func (t *Tracker) PreferredTarget(client string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.clients[client]
+	if !ok {
+		return "", false
+	}
+
+	targets := make(map[string]int64)
+	for pair, count := range stats.pairs {
+		targets[pair[1]] += count
+	}
+
+	var best string
+	var bestCount int64
+	for target, count := range targets {
+		if count > bestCount || (count == bestCount && target < best) {
+			best, bestCount = target, count
+		}
+	}
+	return best, best != ""
+}
+
+// Snapshot 返回所有客户端的常用语言方向，参数: 每个客户端的数量上限，返回: 客户端到语言方向的映射
+func (t *Tracker) Snapshot(n int) map[string][]Pair {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make(map[string][]Pair, len(t.clients))
+	for client, stats := range t.clients {
+		result[client] = topPairs(stats, n)
+	}
+	return result
+}
+
+// Popular 返回所有客户端合计最常用的语言方向，参数: 数量上限，返回: 按次数降序的语言方向
+func (t *Tracker) Popular(n int) []Pair {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total := &clientStats{pairs: make(map[[2]string]int64)}
+	for _, stats := range t.clients {
+		for pair, count := range stats.pairs {
+			total.pairs[pair] += count
+		}
+	}
+	return topPairs(total, n)
+}
+
+// evictOldestLocked 淘汰最久未活跃的客户端 (调用方需持有锁)，参数: 无，返回: 无
+func (t *Tracker) evictOldestLocked() {
+	var oldest string
+	var oldestAt time.Time
+	for client, stats := range t.clients {
+		if oldest == "" || stats.lastSeen.Before(oldestAt) {
+			oldest, oldestAt = client, stats.lastSeen
+		}
+	}
+	delete(t.clients, oldest)
+}
+
+// topPairs 对客户端统计排序并截取，参数: 客户端统计与数量上限，返回: 语言方向切片
+func topPairs(stats *clientStats, n int) []Pair {
+	pairs := make([]Pair, 0, len(stats.pairs))
+	for pair, count := range stats.pairs {
+		pairs = append(pairs, Pair{Source: pair[0], Target: pair[1], Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].Source != pairs[j].Source {
+			return pairs[i].Source < pairs[j].Source
+		}
+		return pairs[i].Target < pairs[j].Target
+	})
+	if n > 0 && len(pairs) > n {
+		pairs = pairs[:n]
+	}
+	return pairs
+}
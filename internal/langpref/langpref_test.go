@@ -0,0 +1,73 @@
+package langpref
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTrackerTop 测试常用语言方向排序，参数: 测试实例，返回: 无
+func TestTrackerTop(t *testing.T) {
+	tracker := NewTracker(0)
+	tracker.Record("key-a", "en", "zh-CN")
+	tracker.Record("key-a", "en", "zh-CN")
+	tracker.Record("key-a", "ja", "zh-CN")
+	tracker.Record("key-a", "en", "fr")
+	tracker.Record("key-b", "de", "en")
+
+	top := tracker.Top("key-a", 2)
+	if len(top) != 2 {
+		t.Fatalf("Top() 返回 %d 项，期望 2", len(top))
+	}
+	if top[0] != (Pair{Source: "en", Target: "zh-CN", Count: 2}) {
+		t.Errorf("Top()[0] = %+v", top[0])
+	}
+
+	target, ok := tracker.PreferredTarget("key-a")
+	if !ok || target != "zh-CN" {
+		t.Errorf("PreferredTarget() = %q, %v, 期望 zh-CN", target, ok)
+	}
+
+	if _, ok := tracker.PreferredTarget("unknown"); ok {
+		t.Error("未知客户端不应有偏好")
+	}
+}
+
+// TestTrackerPopular 测试合计所有客户端的常用语言方向，参数: 测试实例，返回: 无
+func TestTrackerPopular(t *testing.T) {
+	tracker := NewTracker(0)
+	tracker.Record("key-a", "en", "zh-CN")
+	tracker.Record("key-a", "de", "en")
+	tracker.Record("key-b", "de", "en")
+	tracker.Record("key-c", "de", "en")
+	tracker.Record("key-c", "en", "zh-CN")
+	tracker.Record("key-c", "ja", "fr")
+
+	popular := tracker.Popular(2)
+	want := []Pair{{Source: "de", Target: "en", Count: 3}, {Source: "en", Target: "zh-CN", Count: 2}}
+	if len(popular) != len(want) || popular[0] != want[0] || popular[1] != want[1] {
+		t.Errorf("Popular() = %+v, want %+v", popular, want)
+	}
+}
+
+// TestTrackerEviction 测试超出容量时淘汰最久未活跃客户端，参数: 测试实例，返回: 无
+func TestTrackerEviction(t *testing.T) {
+	tracker := NewTracker(2)
+	clock := time.Unix(0, 0)
+	tracker.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	tracker.Record("old", "en", "zh-CN")
+	tracker.Record("mid", "en", "zh-CN")
+	tracker.Record("old", "en", "ja") // old 重新活跃
+	tracker.Record("new", "en", "ko")
+
+	snapshot := tracker.Snapshot(0)
+	if _, ok := snapshot["mid"]; ok {
+		t.Error("期望淘汰最久未活跃的 mid")
+	}
+	if len(snapshot) != 2 {
+		t.Errorf("Snapshot() 客户端数 = %d, 期望 2", len(snapshot))
+	}
+}
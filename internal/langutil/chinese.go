@@ -0,0 +1,60 @@
+package langutil
+
+import "unicode"
+
+// simplifiedOnly 与 traditionalOnly 常用简繁字对照 (按位置一一对应)，只收录另一种写法中不使用的字形，用于区分简体与繁体中文
+const (
+	simplifiedOnly = "这个们来时说为国会学对过发还进经开问么动现两关无点与样长义电实东话业将车从没给机战应间当难头觉门见边运声报军际产区条员准" +
+		"变达处号听极结统识议种总验书买卖写读师认让语调谈谁请马鸟鱼龙风飞广乱亲传体价众优岁万专丰临丽举乐习乡亚亿仅儿党兰兴养内农" +
+		"决况净减凤刘则刚创删别剧办务劳势医华协单卫历压厅县参双吗启图团场块坚坏备复夺奋妈孙宁宝审宪寻导层属币带帮庆库废张弹归录彻" +
+		"径忆态怀恶恋惊惯户执扩扫扬护担拥择挂换据击损摄数断旧显晓术杀权构标桥档检楼欢毕气汇汉汤沟泪泽洁济浅测浓湾满灭灯灵炉烧热爱" +
+		"爷牵状独狮猎环画畅疗盖监盘矿码础确礼祸离积称稳穷窃笔简类粮紧红纪约级纯纸线练组细织终绍绝继续维综绿缘网罗罚职联肃胜脑脚脸" +
+		"节苏药获虽虑补装观规视览计讨训记讲许论设访证评诉词译试诗诚该详误课谢贝负财责贤败货质贩贫购费贵贷贸资赛赶趋跃转轮软轻载较" +
+		"辅辆辈迁远违连迟选递逻遗邮邻酱释针钟钢钱铁银销锁错锅镇闭闲闻阅队阳阴阵阶陆陈险随隐雾静韩页顶项顺须顾顿预领频题颜额饭饮馆" +
+		"鸡麦黄齐"
+	traditionalOnly = "這個們來時說為國會學對過發還進經開問麼動現兩關無點與樣長義電實東話業將車從沒給機戰應間當難頭覺門見邊運聲報軍際產區條員準" +
+		"變達處號聽極結統識議種總驗書買賣寫讀師認讓語調談誰請馬鳥魚龍風飛廣亂親傳體價眾優歲萬專豐臨麗舉樂習鄉亞億僅兒黨蘭興養內農" +
+		"決況淨減鳳劉則剛創刪別劇辦務勞勢醫華協單衛歷壓廳縣參雙嗎啟圖團場塊堅壞備復奪奮媽孫寧寶審憲尋導層屬幣帶幫慶庫廢張彈歸錄徹" +
+		"徑憶態懷惡戀驚慣戶執擴掃揚護擔擁擇掛換據擊損攝數斷舊顯曉術殺權構標橋檔檢樓歡畢氣匯漢湯溝淚澤潔濟淺測濃灣滿滅燈靈爐燒熱愛" +
+		"爺牽狀獨獅獵環畫暢療蓋監盤礦碼礎確禮禍離積稱穩窮竊筆簡類糧緊紅紀約級純紙線練組細織終紹絕繼續維綜綠緣網羅罰職聯肅勝腦腳臉" +
+		"節蘇藥獲雖慮補裝觀規視覽計討訓記講許論設訪證評訴詞譯試詩誠該詳誤課謝貝負財責賢敗貨質販貧購費貴貸貿資賽趕趨躍轉輪軟輕載較" +
+		"輔輛輩遷遠違連遲選遞邏遺郵鄰醬釋針鐘鋼錢鐵銀銷鎖錯鍋鎮閉閒聞閱隊陽陰陣階陸陳險隨隱霧靜韓頁頂項順須顧頓預領頻題顏額飯飲館" +
+		"雞麥黃齊"
+)
+
+// chineseVariants 简繁专用字到中文变体的索引
+var chineseVariants = func() map[rune]string {
+	variants := make(map[rune]string)
+	for _, r := range simplifiedOnly {
+		variants[r] = "zh-CN"
+	}
+	for _, r := range traditionalOnly {
+		variants[r] = "zh-TW"
+	}
+	return variants
+}()
+
+// ChineseVariant 根据简繁专用字判断中文文本是简体还是繁体，参数: 文本，返回: zh-CN、zh-TW 或空 (没有专用字或两者数量相同)
+// 只统计常用字，简繁写法相同的字 (如 中、人、大) 不参与判断
+func ChineseVariant(text string) string {
+	simplified, traditional := 0, 0
+	for _, r := range text {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+		switch chineseVariants[r] {
+		case "zh-CN":
+			simplified++
+		case "zh-TW":
+			traditional++
+		}
+	}
+	switch {
+	case simplified > traditional:
+		return "zh-CN"
+	case traditional > simplified:
+		return "zh-TW"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,31 @@
+package langutil
+
+import "testing"
+
+// TestChineseVariant 测试简繁中文判断，参数: 测试实例，返回: 无
+func TestChineseVariant(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"简体", "这是一个简单的测试", "zh-CN"},
+		{"繁体", "這是一個簡單的測試", "zh-TW"},
+		{"香港繁体", "我哋今日去銅鑼灣買嘢，好開心", "zh-TW"},
+		{"简繁相同", "中文", ""},
+		{"按多数判断", "这是一个繁體字", "zh-CN"},
+		{"夹杂英文", "Go 語言開發", "zh-TW"},
+		{"没有汉字", "hello", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChineseVariant(tt.text); got != tt.want {
+				t.Errorf("ChineseVariant(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if len([]rune(simplifiedOnly)) != len([]rune(traditionalOnly)) {
+		t.Errorf("简繁对照长度不一致: %d != %d", len([]rune(simplifiedOnly)), len([]rune(traditionalOnly)))
+	}
+}
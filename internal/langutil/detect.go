@@ -1,66 +1,262 @@
 package langutil
 
-import "strings"
+import (
+	"strings"
+	"unicode"
 
-// DetectLanguage 简单语言检测，参数: 文本与请求语言，返回: 推断语言代码
-func DetectLanguage(text, requested string) string {
-	if strings.TrimSpace(requested) != "" && !strings.EqualFold(requested, "auto") {
-		return NormalizeLanguageCode(requested)
+	"github.com/abadojack/whatlanggo"
+	"golang.org/x/text/language"
+)
+
+// minTrigramConfidence 采用 trigram 统计结果所需的最低置信度，更低时 (多为几个词的短文本) 退回按文字的默认语言
+const minTrigramConfidence = 0.1
+
+// minScriptShare 按专属文字直接确定语言所需的最低字母占比，更低时 (多种文字混排) 结果不可靠，置信度为该占比
+const minScriptShare = 0.5
+
+// maxCandidates Detect 保留的候选语言数
+const maxCandidates = 3
+
+// MaxDetectLanguages DetectLanguages 最多返回的候选语言数 (每个候选需要一次 trigram 统计)
+const MaxDetectLanguages = 10
+
+// Detection 本地语言检测结果
+type Detection struct {
+	Language   string      // 谷歌格式的语言代码
+	Confidence float64     // 置信度 (0-1)
+	Reliable   bool        // 结果是否可靠 (专属文字或 trigram 置信度足够高)
+	Candidates []Candidate // 候选语言，首个为 Language，其余按置信度降序 (无法判断文字时为空)
+}
+
+// Candidate 候选语言及其置信度
+type Candidate struct {
+	Language   string  // 谷歌格式的语言代码
+	Confidence float64 // 置信度 (0-1)，同一结果中各候选之和不超过 1
+}
+
+// Detect 检测文本语言，参数: 文本，返回: 检测结果
+// 先按全文字母占比判断主要文字：基本只用于一种语言的文字 (汉字、假名、谚文、泰文、希腊文等) 占比过半时直接确定，汉字再按简繁专用字区分 zh-CN 与 zh-TW；其他文字使用 trigram 统计模型 (whatlanggo)，
+// 置信度过低时退回该文字的默认语言 (拉丁文字为英语、西里尔文字为俄语、阿拉伯文字为阿拉伯语等)
+func Detect(text string) Detection {
+	return detect(text, maxCandidates)
+}
+
+// DetectLanguages 检测文本语言并返回前 n 个候选 (如 es 与 pt、da 与 no 难以区分时同时列出)，参数: 文本与候选数 (不大于 0 时为 3，最多 MaxDetectLanguages)，返回: 候选语言 (至少一个，首个与 Detect 结果相同)
+func DetectLanguages(text string, n int) []Candidate {
+	if n <= 0 {
+		n = maxCandidates
 	}
+	detection := detect(text, min(n, MaxDetectLanguages))
+	if len(detection.Candidates) == 0 {
+		return []Candidate{{Language: detection.Language, Confidence: detection.Confidence}}
+	}
+	return detection.Candidates
+}
 
-	// 简单的启发式检测
-	for _, r := range text {
-		if IsCJK(r) {
-			return "zh-CN"
+// detect 检测文本语言，参数: 文本与最多候选数，返回: 检测结果
+func detect(text string, n int) Detection {
+	if lang := DetectByScript(text); lang != "" {
+		return certain(lang)
+	}
+	script, share := dominantScript(text)
+	prior, known := scriptPriors[script]
+	if known && prior.exclusive {
+		lang := prior.language
+		if script == ScriptHan {
+			if variant := ChineseVariant(text); variant != "" {
+				lang = variant
+			}
 		}
-		if IsCyrillic(r) {
-			return "ru"
+		if share >= minScriptShare {
+			return certain(lang)
 		}
-		if IsJapanese(r) {
-			return "ja"
+		// 多种文字混排且没有过半的文字时，只以占比作为置信度
+		return Detection{Language: lang, Confidence: share, Candidates: []Candidate{{Language: lang, Confidence: share}}}
+	}
+	info := whatlanggo.Detect(text)
+	if info.Script == nil {
+		return Detection{Language: "en"}
+	}
+
+	candidates := rankCandidates(text, info, n)
+	if len(candidates) == 0 || info.Confidence < minTrigramConfidence {
+		fallback := "en"
+		if known {
+			fallback = prior.language
 		}
-		if IsKorean(r) {
-			return "ko"
+		candidates = promote(candidates, fallback, n)
+	}
+	return Detection{
+		Language:   candidates[0].Language,
+		Confidence: candidates[0].Confidence,
+		Reliable:   info.IsReliable(),
+		Candidates: candidates,
+	}
+}
+
+// certain 构造按文字确定的检测结果，参数: 语言代码，返回: 置信度为 1 的检测结果
+func certain(lang string) Detection {
+	return Detection{Language: lang, Confidence: 1, Reliable: true, Candidates: []Candidate{{Language: lang, Confidence: 1}}}
+}
+
+// rankCandidates 依次排除已选语言重新检测得到候选排名，参数: 文本、首次检测结果与最多候选数，返回: 候选语言
+// whatlanggo 的置信度描述第一名与第二名的差距 (0 为并列，1 为远超)，据此把剩余概率按 (1+置信度)/2 分给当前第一名，
+// 再把剩余部分留给排除它之后的检测结果，因此各候选置信度之和不超过 1，且第一名恰好领先时约为 0.5
+func rankCandidates(text string, info whatlanggo.Info, n int) []Candidate {
+	var candidates []Candidate
+	blacklist := make(map[whatlanggo.Lang]bool)
+	remaining := 1.0
+	for len(candidates) < n && info.Lang >= 0 && remaining > 0.005 {
+		share := remaining * (1 + info.Confidence) / 2
+		remaining -= share
+		candidates = appendCandidate(candidates, languageCode(info.Lang), share)
+		blacklist[info.Lang] = true
+		info = whatlanggo.DetectWithOptions(text, whatlanggo.Options{Blacklist: blacklist})
+	}
+	return candidates
+}
+
+// languageCode 把 whatlanggo 语言转换为谷歌格式代码，参数: 语言，返回: 语言代码 (没有 ISO 639-1 代码时使用 ISO 639-3，个体语言由 NormalizeLanguageCode 换成宏语言)
+func languageCode(lang whatlanggo.Lang) string {
+	code := lang.Iso6391()
+	if code == "" {
+		code = lang.Iso6393()
+	}
+	return NormalizeLanguageCode(code)
+}
+
+// appendCandidate 追加候选语言，规范化后代码相同的候选合并置信度，参数: 候选列表、语言代码与置信度，返回: 新列表
+func appendCandidate(candidates []Candidate, lang string, confidence float64) []Candidate {
+	for i := range candidates {
+		if candidates[i].Language == lang {
+			candidates[i].Confidence += confidence
+			return candidates
 		}
 	}
+	return append(candidates, Candidate{Language: lang, Confidence: confidence})
+}
 
-	return "en"
+// promote 把默认语言移到候选首位 (不在候选中时以 0 置信度加入，并去掉末位以保持候选数)，参数: 候选列表、语言代码与最多候选数，返回: 新列表
+func promote(candidates []Candidate, lang string, n int) []Candidate {
+	promoted := []Candidate{{Language: lang}}
+	for _, c := range candidates {
+		if c.Language == lang {
+			promoted[0].Confidence = c.Confidence
+			continue
+		}
+		promoted = append(promoted, c)
+	}
+	if len(promoted) > n {
+		promoted = promoted[:n]
+	}
+	return promoted
 }
 
-// NormalizeLanguageCode 规范化语言代码，参数: 原始代码字符串，返回: 标准化语言代码
+// Ranked 返回检测结果的候选语言与置信度，首个为 Language，用于填充 ld_result，参数: 无，返回: 语言代码切片与置信度切片
+func (d Detection) Ranked() ([]string, []float64) {
+	if len(d.Candidates) == 0 {
+		return []string{d.Language}, []float64{d.Confidence}
+	}
+	languages := make([]string, len(d.Candidates))
+	confidences := make([]float64, len(d.Candidates))
+	for i, c := range d.Candidates {
+		languages[i], confidences[i] = c.Language, c.Confidence
+	}
+	return languages, confidences
+}
+
+// DetectSource 确定源语言，请求指定了源语言时直接采用 (置信度 1)，否则检测文本，参数: 文本与请求语言，返回: 检测结果
+func DetectSource(text, requested string) Detection {
+	if strings.TrimSpace(requested) != "" && !strings.EqualFold(requested, "auto") {
+		return certain(NormalizeLanguageCode(requested))
+	}
+	return Detect(text)
+}
+
+// DetectLanguage 检测语言，请求指定了源语言时直接使用，参数: 文本与请求语言，返回: 推断语言代码
+func DetectLanguage(text, requested string) string {
+	return DetectSource(text, requested).Language
+}
+
+// googleLanguageCodes 谷歌翻译沿用旧代码的语言 (BCP-47 规范代码 → 谷歌代码)
+var googleLanguageCodes = map[string]string{
+	"fil": "tl", // 菲律宾语
+	"he":  "iw", // 希伯来语
+	"jv":  "jw", // 爪哇语
+	"nb":  "no", // 书面挪威语
+}
+
+// googleRegionalVariants 谷歌格式中保留地区的语言变体，其余地区变体归并到主语言 (如 en-US、pt-BR)
+var googleRegionalVariants = map[string]bool{
+	"en-GB": true,
+	"pt-PT": true,
+	"fr-CA": true, // 加拿大法语
+	"fa-AF": true, // 达利语
+}
+
+// canonical 语言代码规范化方式：在默认规则 (废弃与旧代码) 之外把个体语言换成宏语言 (如 cmn → zh、pes → fa、arb → ar)
+var canonical = language.Default | language.Macro
+
+// NormalizeLanguageCode 按 BCP-47 解析语言代码并转换为谷歌格式，参数: 原始代码字符串 (不区分大小写，- 与 _ 均可)，返回: 标准化语言代码
+// 中文按文字归并为 zh-CN (简体) 或 zh-TW (繁体)，如 zh-Hans-SG → zh-CN、zh-HK → zh-TW；其他语言只在文字不是该语言默认文字时保留文字 (如 sr-Latn)，
+// 地区只对 googleRegionalVariants 中的变体保留；废弃代码与个体语言先按 CLDR 规范化 (iw → he、in → id、cmn → zh)，再换成谷歌沿用的代码；
+// 因此任意大小写与分隔符的 ISO 639-1/639-2/639-3 代码都得到同一结果，无法解析时原样转为小写
 func NormalizeLanguageCode(code string) string {
-	code = strings.ToLower(code)
+	tag, err := canonical.Parse(strings.TrimSpace(code))
+	if err != nil || tag == language.Und {
+		return strings.ToLower(code)
+	}
 
-	// 语言代码转换为谷歌格式
-	switch code {
-	case "zh", "zh-hans":
+	base, _ := tag.Base()
+	script, _ := tag.Script()
+	if base.String() == "zh" {
+		if script.String() == "Hant" {
+			return "zh-TW"
+		}
 		return "zh-CN"
-	case "zh-hant":
-		return "zh-TW"
-	case "en", "en-us":
-		return "en"
-	case "en-gb":
-		return "en-GB"
-	case "ja":
+	}
+
+	result := base.String()
+	if google, ok := googleLanguageCodes[result]; ok {
+		result = google
+	}
+	if defaultScript, _ := language.Make(base.String()).Script(); script != defaultScript {
+		result += "-" + script.String()
+	}
+	if region, confidence := tag.Region(); confidence == language.Exact {
+		if variant := result + "-" + region.String(); googleRegionalVariants[variant] {
+			return variant
+		}
+	}
+	return result
+}
+
+// DetectByScript 只根据专属文字判断语言 (含假名且只有假名与汉字为日语，只有谚文与汉字为韩语，只有泰文为泰语)，参数: 文本，返回: 语言代码 (无法确定时为空)
+// 与 DetectLanguage 不同，拉丁、西里尔、阿拉伯、希伯来、天城文与纯汉字文本不猜测语言 (同一文字用于多种语言)，结果可用于跳过翻译等需要确定性的场景
+func DetectByScript(text string) string {
+	kana, hangul, thai := false, false, false
+	for _, r := range text {
+		switch {
+		case IsJapanese(r):
+			kana = true
+		case IsKorean(r):
+			hangul = true
+		case IsThai(r):
+			thai = true
+		case IsCJK(r), !unicode.IsLetter(r):
+		default:
+			return ""
+		}
+	}
+	switch {
+	case kana && !hangul && !thai:
 		return "ja"
-	case "ko":
+	case hangul && !kana && !thai:
 		return "ko"
-	case "fr":
-		return "fr"
-	case "de":
-		return "de"
-	case "es":
-		return "es"
-	case "ru":
-		return "ru"
-	case "pt", "pt-br":
-		return "pt"
-	case "it":
-		return "it"
-	case "ar":
-		return "ar"
+	case thai && !kana && !hangul:
+		return "th"
 	default:
-		return code
+		return ""
 	}
 }
 
@@ -76,6 +272,32 @@ func IsCyrillic(r rune) bool {
 	return r >= 0x0400 && r <= 0x04FF
 }
 
+// IsThai 判断字符是否为泰文，参数: rune，返回: 布尔
+func IsThai(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
+
+// IsArabic 判断字符是否为阿拉伯字母 (含补充、扩展与表现形式区)，参数: rune，返回: 布尔
+func IsArabic(r rune) bool {
+	return (r >= 0x0600 && r <= 0x06FF) ||
+		(r >= 0x0750 && r <= 0x077F) || // 补充
+		(r >= 0x08A0 && r <= 0x08FF) || // 扩展-A
+		(r >= 0xFB50 && r <= 0xFDFF) || // 表现形式-A
+		(r >= 0xFE70 && r <= 0xFEFF) // 表现形式-B
+}
+
+// IsHebrew 判断字符是否为希伯来字母 (含表现形式区)，参数: rune，返回: 布尔
+func IsHebrew(r rune) bool {
+	return (r >= 0x0590 && r <= 0x05FF) ||
+		(r >= 0xFB1D && r <= 0xFB4F)
+}
+
+// IsDevanagari 判断字符是否为天城文 (含扩展区)，参数: rune，返回: 布尔
+func IsDevanagari(r rune) bool {
+	return (r >= 0x0900 && r <= 0x097F) ||
+		(r >= 0xA8E0 && r <= 0xA8FF)
+}
+
 // IsJapanese 判断字符是否为日语假名，参数: rune，返回: 布尔
 func IsJapanese(r rune) bool {
 	return (r >= 0x3040 && r <= 0x309F) || // 平假名
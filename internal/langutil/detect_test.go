@@ -1,6 +1,11 @@
 package langutil
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
 
 // TestDetectLanguage 测试语言检测，参数: 测试实例，返回: 无
 func TestDetectLanguage(t *testing.T) {
@@ -46,6 +51,42 @@ func TestDetectLanguage(t *testing.T) {
 			requested: "auto",
 			want:      "en",
 		},
+		{
+			name:      "法语文本",
+			text:      "Bonjour tout le monde, comment allez-vous aujourd'hui ?",
+			requested: "auto",
+			want:      "fr",
+		},
+		{
+			name:      "波兰语文本",
+			text:      "Dzień dobry, jak się masz?",
+			requested: "auto",
+			want:      "pl",
+		},
+		{
+			name:      "越南语文本",
+			text:      "Xin chào, bạn khỏe không?",
+			requested: "auto",
+			want:      "vi",
+		},
+		{
+			name:      "泰语文本",
+			text:      "สวัสดีครับ",
+			requested: "auto",
+			want:      "th",
+		},
+		{
+			name:      "汉字与假名混排",
+			text:      "日本語です",
+			requested: "auto",
+			want:      "ja",
+		},
+		{
+			name:      "置信度过低的拉丁文本",
+			text:      "Hello World",
+			requested: "auto",
+			want:      "en",
+		},
 		{
 			name:      "指定语言",
 			text:      "任意文本",
@@ -70,6 +111,132 @@ func TestDetectLanguage(t *testing.T) {
 	}
 }
 
+// TestDetect 测试检测结果的置信度与可靠性，参数: 测试实例，返回: 无
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantLanguage string
+		wantReliable bool
+	}{
+		{"CJK 快速判断", "你好世界", "zh-CN", true},
+		{"繁体中文", "這個問題很難", "zh-TW", true},
+		{"西里尔文字夹杂英文", "Привет, как дела? (hello)", "ru", false},
+		{"英文夹杂个别汉字", "I really love this 猫 picture from my friend", "en", false},
+		{"多种文字混排没有过半", "你好世界朋友 hello мир", "zh-CN", false},
+		{"trigram 可靠结果", "Bonjour tout le monde, comment allez-vous aujourd'hui ?", "fr", true},
+		{"短文本不可靠", "Hello", "en", false},
+		{"没有文字", "12345", "en", false},
+		{"专属文字直接确定", "Γειά", "el", true},
+		{"whatlanggo 不支持的文字", "Բարեւ", "hy", true},
+		{"短文本退回文字默认语言", "نعم", "ar", false},
+		{"个体语言换成宏语言代码", "سلام، حال شما چطور است؟ امروز هوا خیلی خوب است", "fa", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.text)
+			if got.Language != tt.wantLanguage || got.Reliable != tt.wantReliable {
+				t.Errorf("Detect() = %+v, want language %s reliable %v", got, tt.wantLanguage, tt.wantReliable)
+			}
+			if got.Reliable && got.Confidence < minTrigramConfidence {
+				t.Errorf("可靠结果的置信度过低: %+v", got)
+			}
+		})
+	}
+}
+
+// TestDetectCandidates 测试候选语言排名与置信度，参数: 测试实例，返回: 无
+func TestDetectCandidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantFirst string
+		wantMin   int // 最少候选数
+	}{
+		{"专属文字只有一个候选", "こんにちは", "ja", 1},
+		{"相近语言给出多个候选", "Hallo wereld, hoe gaat het met je vandaag?", "nl", 2},
+		{"短文本默认语言排在首位", "Ich bin", "en", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.text)
+			if len(got.Candidates) < tt.wantMin || len(got.Candidates) > maxCandidates {
+				t.Fatalf("Candidates = %+v, want %d..%d", got.Candidates, tt.wantMin, maxCandidates)
+			}
+			if got.Candidates[0].Language != tt.wantFirst || got.Candidates[0].Confidence != got.Confidence {
+				t.Errorf("首个候选 = %+v, want %s with confidence %v", got.Candidates[0], tt.wantFirst, got.Confidence)
+			}
+			total := 0.0
+			for _, c := range got.Candidates {
+				total += c.Confidence
+			}
+			if total > 1+1e-9 {
+				t.Errorf("候选置信度之和 = %v, want <= 1", total)
+			}
+		})
+	}
+
+	languages, confidences := Detect("12345").Ranked()
+	if len(languages) != 1 || languages[0] != "en" || confidences[0] != 0 {
+		t.Errorf("Ranked() = %v, %v, want [en] [0]", languages, confidences)
+	}
+}
+
+// TestDetectLanguages 测试返回前 n 个候选语言，参数: 测试实例，返回: 无
+func TestDetectLanguages(t *testing.T) {
+	text := "Hej med dig, hvordan har du det i dag?"
+	tests := []struct {
+		name string
+		n    int
+		want int // 最多候选数 (剩余置信度过低时提前结束)
+	}{
+		{"默认候选数", 0, maxCandidates},
+		{"只要一个", 1, 1},
+		{"更多候选", 5, 5},
+		{"超过上限", 100, MaxDetectLanguages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectLanguages(text, tt.n)
+			if len(got) == 0 || len(got) > tt.want {
+				t.Fatalf("DetectLanguages(n=%d) 返回 %d 个候选, want 1..%d: %+v", tt.n, len(got), tt.want, got)
+			}
+			if got[0].Language != Detect(text).Language {
+				t.Errorf("首个候选 = %s, want %s", got[0].Language, Detect(text).Language)
+			}
+		})
+	}
+
+	if got := DetectLanguages(text, 2); len(got) != 2 || got[0].Language != "da" || got[1].Language != "no" {
+		t.Errorf("DetectLanguages() = %+v, want da 与 no", got)
+	}
+	if got := DetectLanguages("12345", 3); len(got) != 1 || got[0].Language != "en" {
+		t.Errorf("没有文字时 DetectLanguages() = %+v, want [en]", got)
+	}
+}
+
+// TestNormalizeLanguageCodeConsistent 测试全部两字母代码的规范化结果一致：不区分大小写与分隔符，且再次规范化不变，参数: 测试实例，返回: 无
+func TestNormalizeLanguageCodeConsistent(t *testing.T) {
+	for a := 'a'; a <= 'z'; a++ {
+		for b := 'a'; b <= 'z'; b++ {
+			code := string([]rune{a, b})
+			got := NormalizeLanguageCode(code)
+			if upper := NormalizeLanguageCode(strings.ToUpper(code)); upper != got {
+				t.Errorf("NormalizeLanguageCode(%q) = %q, want %q", strings.ToUpper(code), upper, got)
+			}
+			if again := NormalizeLanguageCode(got); again != got {
+				t.Errorf("NormalizeLanguageCode(%q) = %q, not idempotent (%q)", code, got, again)
+			}
+			if _, err := language.ParseBase(code); err != nil {
+				continue // 不是 ISO 639 代码，原样返回
+			}
+			if regional := NormalizeLanguageCode(code + "_IE"); regional != got {
+				t.Errorf("NormalizeLanguageCode(%q) = %q, want %q", code+"_IE", regional, got)
+			}
+		}
+	}
+}
+
 // TestNormalizeLanguageCode 测试语言代码规范化，参数: 测试实例，返回: 无
 func TestNormalizeLanguageCode(t *testing.T) {
 	tests := []struct {
@@ -93,6 +260,40 @@ func TestNormalizeLanguageCode(t *testing.T) {
 		{"葡萄牙语巴西", "pt-br", "pt"},
 		{"意大利语", "IT", "it"},
 		{"阿拉伯语", "AR", "ar"},
+		{"新加坡简体中文", "zh-Hans-SG", "zh-CN"},
+		{"香港中文", "zh_HK", "zh-TW"},
+		{"下划线分隔", "ZH_cn", "zh-CN"},
+		{"塞尔维亚语拉丁字母", "sr-latn", "sr-Latn"},
+		{"塞尔维亚语默认文字", "sr-Cyrl-RS", "sr"},
+		{"欧洲葡萄牙语", "pt-PT", "pt-PT"},
+		{"菲律宾语", "fil", "tl"},
+		{"书面挪威语", "nb", "no"},
+		{"挪威语", "no", "no"},
+		{"新挪威语", "nn", "nn"},
+		{"希伯来语旧代码", "iw", "iw"},
+		{"印尼语旧代码", "in", "id"},
+		{"拉美西班牙语", "es-419", "es"},
+		{"扩展子标签", "en-US-u-ca-gregory", "en"},
+		{"曼尼普尔语", "mni-Mtei", "mni-Mtei"},
+		{"荷兰语大写", "NL", "nl"},
+		{"波兰语地区", "pl_PL", "pl"},
+		{"土耳其语", "tr-TR", "tr"},
+		{"越南语", "VI", "vi"},
+		{"泰语", "th-TH", "th"},
+		{"乌克兰语", "UK", "uk"},
+		{"捷克语", "cs-CZ", "cs"},
+		{"瑞典语", "SV", "sv"},
+		{"芬兰语", "fi_FI", "fi"},
+		{"希伯来语", "he-IL", "iw"},
+		{"书面挪威语地区", "NB-no", "no"},
+		{"塞尔维亚-克罗地亚语", "sh", "sr-Latn"},
+		{"ISO 639-2 代码", "fra", "fr"},
+		{"ISO 639-2/B 代码", "ger", "de"},
+		{"个体语言换成宏语言", "cmn", "zh-CN"},
+		{"伊朗波斯语", "pes", "fa"},
+		{"加拿大法语", "fr_ca", "fr-CA"},
+		{"达利语", "fa-AF", "fa-AF"},
+		{"自动检测", "auto", "auto"},
 		{"未知语言", "unknown", "unknown"},
 	}
 
@@ -201,3 +402,55 @@ func TestIsKorean(t *testing.T) {
 		})
 	}
 }
+
+// TestDetectByScript 测试只按专属文字判断语言，参数: 测试实例，返回: 无
+func TestDetectByScript(t *testing.T) {
+	tests := map[string]string{
+		"日本語のテキスト。":    "ja",
+		"안녕하세요, 世界!":   "ko",
+		"你好世界":         "",
+		"Hello":        "",
+		"こんにちは hello":  "",
+		"안녕 こんにちは":     "",
+		"123 !":        "",
+		"สวัสดีครับ":   "th",
+		"สวัสดี こんにちは": "",
+		"مرحبا":        "",
+		"नमस्ते":       "",
+	}
+	for text, want := range tests {
+		if got := DetectByScript(text); got != want {
+			t.Errorf("DetectByScript(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// TestScriptRunes 测试泰文、阿拉伯、希伯来与天城文字符范围，参数: 测试实例，返回: 无
+func TestScriptRunes(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     rune
+		match func(rune) bool
+		want  bool
+	}{
+		{"泰文 ก", 'ก', IsThai, true},
+		{"泰文元音符号", 'ั', IsThai, true},
+		{"拉丁不是泰文", 'A', IsThai, false},
+		{"阿拉伯 ع", 'ع', IsArabic, true},
+		{"波斯语 گ", 'گ', IsArabic, true},
+		{"阿拉伯表现形式 ﻻ", 'ﻻ', IsArabic, true},
+		{"希伯来不是阿拉伯", 'ש', IsArabic, false},
+		{"希伯来 ש", 'ש', IsHebrew, true},
+		{"希伯来表现形式", '\uFB2A', IsHebrew, true},
+		{"阿拉伯不是希伯来", 'ع', IsHebrew, false},
+		{"天城文 न", 'न', IsDevanagari, true},
+		{"孟加拉不是天城文", 'ন', IsDevanagari, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match(tt.r); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.r, got, tt.want)
+			}
+		})
+	}
+}
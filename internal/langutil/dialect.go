@@ -0,0 +1,87 @@
+package langutil
+
+import "strings"
+
+// Dialect 提供商的语言代码方言，在内部代码 (谷歌格式，见 NormalizeLanguageCode) 与提供商代码之间双向转换
+// 适配器只使用内部代码，发送请求前用 Source/Target 转换，读取上游响应时用 Internal 转回
+type Dialect struct {
+	upper         bool              // 未列出的代码是否转为大写
+	primarySource bool              // 源语言是否只用主语言 (如 DeepL 的源语言不区分 EN-GB 与 EN-US)
+	codes         map[string]string // 内部代码 → 提供商代码 (源语言与目标语言通用)
+	targets       map[string]string // 内部代码 → 只用于目标语言的提供商代码
+	internal      map[string]string // 小写的提供商代码 → 内部代码
+}
+
+// newDialect 创建方言，参数: 是否大写、源语言是否只用主语言、通用映射与目标语言映射，返回: Dialect 指针
+func newDialect(upper, primarySource bool, codes, targets map[string]string) *Dialect {
+	d := &Dialect{upper: upper, primarySource: primarySource, codes: codes, targets: targets, internal: make(map[string]string)}
+	for _, m := range []map[string]string{codes, targets} {
+		for internal, provider := range m {
+			d.internal[strings.ToLower(provider)] = internal
+		}
+	}
+	return d
+}
+
+var (
+	// DeepL DeepL 与 DeepLX 的语言代码：大写，希伯来语为 HE、挪威语为 NB，繁体中文与地区变体只用于目标语言
+	DeepL = newDialect(true, true,
+		map[string]string{"zh-CN": "ZH", "iw": "HE", "no": "NB"},
+		map[string]string{"zh-TW": "ZH-HANT", "en-GB": "EN-GB", "pt-PT": "PT-PT"},
+	)
+	// Google 谷歌翻译的语言代码，与内部代码相同
+	Google = newDialect(false, false, nil, nil)
+	// Baidu 百度翻译的语言代码：多数语言使用自有缩写 (如 jp、kor、fra、cht)
+	Baidu = newDialect(false, false,
+		map[string]string{
+			"zh-CN": "zh", "zh-TW": "cht", "lzh": "wyw", "ja": "jp", "ko": "kor", "fr": "fra", "es": "spa",
+			"ar": "ara", "bg": "bul", "et": "est", "da": "dan", "fi": "fin", "ro": "rom", "sl": "slo",
+			"sv": "swe", "vi": "vie",
+		},
+		nil,
+	)
+)
+
+// Source 把源语言转换为提供商代码，参数: 语言代码 (任意写法)，返回: 提供商代码 (空或 auto 时为空，表示由上游检测)
+func (d *Dialect) Source(code string) string {
+	if strings.TrimSpace(code) == "" || strings.EqualFold(strings.TrimSpace(code), "auto") {
+		return ""
+	}
+	internal := NormalizeLanguageCode(code)
+	if d.primarySource {
+		primary, _, _ := strings.Cut(internal, "-")
+		internal = NormalizeLanguageCode(primary)
+	}
+	return d.provider(internal, nil)
+}
+
+// Target 把目标语言转换为提供商代码，参数: 语言代码 (任意写法)，返回: 提供商代码
+func (d *Dialect) Target(code string) string {
+	return d.provider(NormalizeLanguageCode(code), d.targets)
+}
+
+// Internal 把提供商返回的语言代码转换为内部代码，参数: 提供商代码，返回: 内部代码 (空输入返回空)
+func (d *Dialect) Internal(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	if internal, ok := d.internal[strings.ToLower(code)]; ok {
+		return internal
+	}
+	return NormalizeLanguageCode(code)
+}
+
+// provider 按映射把内部代码转换为提供商代码，参数: 内部代码与额外映射，返回: 提供商代码
+func (d *Dialect) provider(internal string, extra map[string]string) string {
+	if code, ok := extra[internal]; ok {
+		return code
+	}
+	if code, ok := d.codes[internal]; ok {
+		return code
+	}
+	if d.upper {
+		return strings.ToUpper(internal)
+	}
+	return internal
+}
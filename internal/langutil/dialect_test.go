@@ -0,0 +1,51 @@
+package langutil
+
+import "testing"
+
+// TestDialect 测试提供商语言代码的双向转换，参数: 测试实例，返回: 无
+func TestDialect(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      *Dialect
+		code         string
+		wantSource   string
+		wantTarget   string
+		wantInternal string // 提供商目标语言代码转回的内部代码
+	}{
+		{"DeepL 简体中文", DeepL, "zh-CN", "ZH", "ZH", "zh-CN"},
+		{"DeepL 繁体中文", DeepL, "zh_TW", "ZH", "ZH-HANT", "zh-TW"},
+		{"DeepL 希伯来语", DeepL, "iw", "HE", "HE", "iw"},
+		{"DeepL 挪威语", DeepL, "no", "NB", "NB", "no"},
+		{"DeepL 英式英语", DeepL, "en-GB", "EN", "EN-GB", "en-GB"},
+		{"DeepL 美式英语", DeepL, "en-US", "EN", "EN", "en"},
+		{"DeepL 其他语言", DeepL, "de", "DE", "DE", "de"},
+		{"谷歌与内部代码相同", Google, "zh-TW", "zh-TW", "zh-TW", "zh-TW"},
+		{"谷歌希伯来语", Google, "he", "iw", "iw", "iw"},
+		{"百度日语", Baidu, "ja", "jp", "jp", "ja"},
+		{"百度韩语", Baidu, "KO", "kor", "kor", "ko"},
+		{"百度繁体中文", Baidu, "zh-Hant", "cht", "cht", "zh-TW"},
+		{"百度斯洛文尼亚语", Baidu, "sl", "slo", "slo", "sl"},
+		{"百度英语", Baidu, "en", "en", "en", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Source(tt.code); got != tt.wantSource {
+				t.Errorf("Source(%q) = %q, want %q", tt.code, got, tt.wantSource)
+			}
+			target := tt.dialect.Target(tt.code)
+			if target != tt.wantTarget {
+				t.Errorf("Target(%q) = %q, want %q", tt.code, target, tt.wantTarget)
+			}
+			if got := tt.dialect.Internal(target); got != tt.wantInternal {
+				t.Errorf("Internal(%q) = %q, want %q", target, got, tt.wantInternal)
+			}
+		})
+	}
+
+	if got := DeepL.Source("auto"); got != "" {
+		t.Errorf("Source(auto) = %q, want empty", got)
+	}
+	if got := Baidu.Internal(""); got != "" {
+		t.Errorf("Internal(\"\") = %q, want empty", got)
+	}
+}
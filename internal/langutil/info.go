@@ -0,0 +1,114 @@
+package langutil
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// 文字书写方向
+const (
+	DirectionLTR = "ltr" // 从左到右
+	DirectionRTL = "rtl" // 从右到左
+)
+
+// rtlScripts 从右到左书写的文字 (ISO 15924 代码)
+var rtlScripts = map[string]bool{
+	"Arab": true, // 阿拉伯语、波斯语、乌尔都语等
+	"Hebr": true, // 希伯来语、意第绪语
+	"Thaa": true, // 迪维希语
+	"Syrc": true, // 叙利亚语
+	"Nkoo": true, // 西非书面语
+	"Adlm": true, // 富拉语
+	"Rohg": true, // 罗兴亚语
+}
+
+// LanguageInfo 语言元数据
+type LanguageInfo struct {
+	Code       string // 谷歌格式的语言代码
+	Name       string // 英文名称
+	NativeName string // 本地名称 (CLDR 没有时与英文名称相同)
+	Script     string // 文字 (ISO 15924 代码，如 Latn、Arab、Hans)
+	Direction  string // 书写方向 (DirectionLTR 或 DirectionRTL)
+}
+
+// Info 返回语言元数据，参数: 语言代码 (先经 NormalizeLanguageCode 规范化)，返回: 元数据 (无法识别的代码名称为代码本身、方向为从左到右)
+func Info(code string) LanguageInfo {
+	normalized := NormalizeLanguageCode(code)
+	info := LanguageInfo{Code: normalized, Name: normalized, NativeName: normalized, Direction: DirectionLTR}
+	tag, err := canonical.Parse(normalized)
+	if err != nil || tag == language.Und {
+		return info
+	}
+
+	if script, _ := tag.Script(); script.String() != "Zzzz" {
+		info.Script = script.String()
+	}
+	if rtlScripts[info.Script] {
+		info.Direction = DirectionRTL
+	}
+	info.Name = DisplayName(normalized, "en")
+	info.NativeName = DisplayName(normalized, normalized)
+	return info
+}
+
+// DisplayName 返回语言在指定语言中的名称 (CLDR 数据，如 de 在 en、de、zh-CN 中为 German、Deutsch、德语)，参数: 语言代码与显示语言 (均先规范化，显示语言为空时为英语)，返回: 名称
+// 显示语言不受支持或 CLDR 没有该名称时依次退回英文名称与规范化后的代码
+func DisplayName(code, inLanguage string) string {
+	normalized := NormalizeLanguageCode(code)
+	tag, ok := displayTag(normalized)
+	if !ok {
+		return normalized
+	}
+	in := language.English
+	if strings.TrimSpace(inLanguage) != "" {
+		if t, ok := displayTag(NormalizeLanguageCode(inLanguage)); ok {
+			in = t
+		}
+	}
+	if name := localizedName(tag, in); name != "" {
+		return name
+	}
+	if name := localizedName(tag, language.English); name != "" {
+		return name
+	}
+	return normalized
+}
+
+// displayTag 把谷歌格式代码转换为用于查找名称的标签 (zh-CN → zh-Hans、zh-TW → zh-Hant)，参数: 规范化后的代码，返回: 标签与是否为已知语言
+func displayTag(normalized string) (language.Tag, bool) {
+	switch normalized {
+	case "zh-CN":
+		return language.SimplifiedChinese, true
+	case "zh-TW":
+		return language.TraditionalChinese, true
+	}
+	tag, err := canonical.Parse(normalized)
+	if err != nil || tag == language.Und {
+		return language.Und, false
+	}
+	base, _ := tag.Base()
+	if display.English.Languages().Name(base) == "" {
+		return language.Und, false
+	}
+	return tag, true
+}
+
+// localizedName 返回标签在显示语言中的名称，保留了非默认文字的代码 (如 sr-Latn) 显示为“语言 (文字)”，参数: 标签与显示语言，返回: 名称 (不受支持时为空)
+func localizedName(tag, in language.Tag) string {
+	namer := display.Tags(in)
+	if namer == nil {
+		return ""
+	}
+	base, _ := tag.Base()
+	if script, confidence := tag.Script(); confidence == language.Exact && base.String() != "zh" {
+		languages, scripts := display.Languages(in), display.Scripts(in)
+		if languages != nil && scripts != nil {
+			if name, scriptName := languages.Name(base), scripts.Name(script); name != "" && scriptName != "" {
+				return name + " (" + scriptName + ")"
+			}
+		}
+	}
+	return namer.Name(tag)
+}
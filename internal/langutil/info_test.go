@@ -0,0 +1,55 @@
+package langutil
+
+import "testing"
+
+// TestInfo 测试语言元数据，参数: 测试实例，返回: 无
+func TestInfo(t *testing.T) {
+	tests := []struct {
+		code string
+		want LanguageInfo
+	}{
+		{"ar", LanguageInfo{Code: "ar", Name: "Arabic", NativeName: "العربية", Script: "Arab", Direction: DirectionRTL}},
+		{"he", LanguageInfo{Code: "iw", Name: "Hebrew", NativeName: "עברית", Script: "Hebr", Direction: DirectionRTL}},
+		{"FA", LanguageInfo{Code: "fa", Name: "Persian", NativeName: "فارسی", Script: "Arab", Direction: DirectionRTL}},
+		{"ur", LanguageInfo{Code: "ur", Name: "Urdu", NativeName: "اردو", Script: "Arab", Direction: DirectionRTL}},
+		{"en-US", LanguageInfo{Code: "en", Name: "English", NativeName: "English", Script: "Latn", Direction: DirectionLTR}},
+		{"zh_hant", LanguageInfo{Code: "zh-TW", Name: "Traditional Chinese", NativeName: "繁體中文", Script: "Hant", Direction: DirectionLTR}},
+		{"ja", LanguageInfo{Code: "ja", Name: "Japanese", NativeName: "日本語", Script: "Jpan", Direction: DirectionLTR}},
+		{"unknown", LanguageInfo{Code: "unknown", Name: "unknown", NativeName: "unknown", Direction: DirectionLTR}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := Info(tt.code); got != tt.want {
+				t.Errorf("Info(%q) = %+v, want %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDisplayName 测试本地化语言名称，参数: 测试实例，返回: 无
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		code       string
+		inLanguage string
+		want       string
+	}{
+		{"de", "en", "German"},
+		{"de", "de", "Deutsch"},
+		{"de", "zh-CN", "德语"},
+		{"DE", "", "German"},
+		{"zh-CN", "en", "Simplified Chinese"},
+		{"zh_hant", "zh-TW", "繁體中文"},
+		{"zh-TW", "zh-CN", "繁体中文"},
+		{"ja", "fr", "japonais"},
+		{"sr-Latn", "en", "Serbian (Latin)"},
+		{"de", "xx", "German"},
+		{"xx", "en", "xx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code+"/"+tt.inLanguage, func(t *testing.T) {
+			if got := DisplayName(tt.code, tt.inLanguage); got != tt.want {
+				t.Errorf("DisplayName(%q, %q) = %q, want %q", tt.code, tt.inLanguage, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package langutil
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions 输入规范化选项
+type NormalizeOptions struct {
+	NFC                bool // Unicode NFC 规范化 (组合字符与预组合字符统一)
+	StripZeroWidth     bool // 去掉零宽空格、零宽不换行空格 (BOM) 与词连接符
+	StripEmoji         bool // 去掉 emoji 及其变体选择符、肤色修饰符与连接符
+	CollapseWhitespace bool // 连续的行内空白折叠为一个空格，去掉行尾空白 (不合并换行)
+}
+
+// Enabled 判断是否开启了任一规范化，参数: 无，返回: 布尔
+func (o NormalizeOptions) Enabled() bool {
+	return o.NFC || o.StripZeroWidth || o.StripEmoji || o.CollapseWhitespace
+}
+
+// NormalizeInput 按选项规范化待翻译文本，使几乎相同的输入得到相同的缓存键，参数: 文本与选项，返回: 规范化后的文本
+// 依次执行 NFC、去零宽字符、去 emoji 与空白折叠；零宽连接符与零宽非连接符影响波斯语、印地语等文字的字形，只在去 emoji 时随 emoji 一起去掉
+func NormalizeInput(text string, opts NormalizeOptions) string {
+	if opts.NFC {
+		text = norm.NFC.String(text)
+	}
+	if opts.StripZeroWidth || opts.StripEmoji {
+		text = strings.Map(func(r rune) rune {
+			if opts.StripZeroWidth && isZeroWidth(r) {
+				return -1
+			}
+			if opts.StripEmoji && isEmoji(r) {
+				return -1
+			}
+			return r
+		}, text)
+	}
+	if opts.CollapseWhitespace {
+		text = collapseWhitespace(text)
+	}
+	return text
+}
+
+// isZeroWidth 判断是否为可安全去掉的零宽字符，参数: 字符，返回: 布尔
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200B', '\u2060', '\uFEFF': // 零宽空格、词连接符、零宽不换行空格 (BOM)
+		return true
+	}
+	return false
+}
+
+// isEmoji 判断是否为 emoji 或 emoji 序列的组成部分 (变体选择符、零宽连接符、肤色修饰符、区域指示符与标签字符)，参数: 字符，返回: 布尔
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // 表情、符号与象形文字、交通地图、补充符号、区域指示符等
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号与装饰符号
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // 旗帜标签字符
+		return true
+	case r == '\uFE0F' || r == '\u200D' || r == '\u20E3': // 变体选择符、零宽连接符、键帽组合符
+		return true
+	}
+	return false
+}
+
+// collapseWhitespace 把每行内连续的空白折叠为一个空格并去掉行尾空白，换行原样保留，参数: 文本，返回: 处理后的文本
+func collapseWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		cr := strings.HasSuffix(line, "\r")
+		fields := strings.Fields(line)
+		// 行首缩进原样保留，避免改变代码块、列表等的结构
+		indent := ""
+		if len(fields) > 0 && strings.TrimLeft(line, " \t　") != line {
+			indent = line[:len(line)-len(strings.TrimLeft(line, " \t　"))]
+		}
+		lines[i] = indent + strings.Join(fields, " ")
+		if cr {
+			lines[i] += "\r"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,29 @@
+package langutil
+
+import "testing"
+
+// TestNormalizeInput 测试 NFC、零宽字符、emoji 与空白折叠，参数: 测试实例，返回: 无
+func TestNormalizeInput(t *testing.T) {
+	all := NormalizeOptions{NFC: true, StripZeroWidth: true, StripEmoji: true, CollapseWhitespace: true}
+	tests := []struct {
+		name string
+		text string
+		opts NormalizeOptions
+		want string
+	}{
+		{name: "NFC 组合字符", text: "Cafe\u0301", opts: NormalizeOptions{NFC: true}, want: "Caf\u00e9"},
+		{name: "零宽字符", text: "\uFEFFhel\u200Blo", opts: NormalizeOptions{StripZeroWidth: true}, want: "hello"},
+		{name: "保留零宽非连接符", text: "\u0645\u06CC\u200C\u062E\u0648\u0627\u0647\u0645", opts: NormalizeOptions{StripZeroWidth: true}, want: "\u0645\u06CC\u200C\u062E\u0648\u0627\u0647\u0645"},
+		{name: "emoji 序列", text: "Nice \U0001F44D\U0001F3FD job \U0001F468\u200D\U0001F469\u200D\U0001F467 \u2600\uFE0F", opts: NormalizeOptions{StripEmoji: true}, want: "Nice  job  "},
+		{name: "空白折叠保留换行与缩进", text: "a  b\t c  \r\n    d   e\n", opts: NormalizeOptions{CollapseWhitespace: true}, want: "a b c\r\n    d e\n"},
+		{name: "全部选项", text: "Hello \U0001F44B  wor\u200Bld ", opts: all, want: "Hello world"},
+		{name: "未开启", text: "a  b 👋", want: "a  b 👋"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeInput(tt.text, tt.opts); got != tt.want {
+				t.Errorf("NormalizeInput(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
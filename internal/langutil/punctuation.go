@@ -0,0 +1,126 @@
+package langutil
+
+import (
+	"strings"
+)
+
+// fullWidthPunctuation 半角标点到全角标点的映射 (括号单独处理)
+var fullWidthPunctuation = map[rune]rune{
+	',': '，',
+	'.': '。',
+	'!': '！',
+	'?': '？',
+	':': '：',
+	';': '；',
+}
+
+// IsChineseTarget 判断目标语言是否为中文 (zh、zh-CN、zh-TW 等)，参数: 语言代码，返回: 布尔
+func IsChineseTarget(code string) bool {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-")), "-")
+	return primary == "zh"
+}
+
+// NormalizeChinesePunctuation 把紧跟汉字的半角标点转换为全角标点并去掉标点两侧多余的空格，可选在汉字与拉丁字母、数字之间插入空格，参数: 文本与是否调整中英文间距，返回: 规范化后的文本
+// 只转换前面是汉字 (或全角标点) 且后面不紧跟字母、数字的标点，不会改动 3.14、example.com、http:// 与纯英文句子；
+// 括号在紧邻汉字时成对转换，连续三个句点转换为省略号
+func NormalizeChinesePunctuation(text string, spacing bool) string {
+	runes := []rune(text)
+	out := make([]rune, 0, len(runes))
+	var parens []bool // 尚未闭合的左括号是否已转换
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := runeAt(runes, i+1)
+		converted := rune(0)
+		switch {
+		case r == '(':
+			convert := isChinese(lastVisible(out)) || isChinese(next)
+			parens = append(parens, convert)
+			if convert {
+				converted = '（'
+			}
+		case r == ')':
+			convert := isChinese(lastVisible(out))
+			if n := len(parens); n > 0 {
+				convert = parens[n-1]
+				parens = parens[:n-1]
+			}
+			if convert {
+				converted = '）'
+			}
+		case r == '.' && next == '.' && runeAt(runes, i+2) == '.' && isChinese(lastVisible(out)):
+			out = append(trimSpaces(out), '…', '…')
+			i += 2
+			i = skipSpaces(runes, i)
+			continue
+		default:
+			if full, ok := fullWidthPunctuation[r]; ok && isChinese(lastVisible(out)) && !isAlnum(next) {
+				converted = full
+			}
+		}
+
+		if converted != 0 {
+			out = append(trimSpaces(out), converted)
+			if converted != '（' {
+				i = skipSpaces(runes, i)
+			}
+			continue
+		}
+		if spacing && len(out) > 0 {
+			last := out[len(out)-1]
+			if (IsCJK(last) && isAlnum(r)) || (isAlnum(last) && IsCJK(r)) {
+				out = append(out, ' ')
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// isChinese 判断字符是否为汉字或全角标点 (其后的半角标点应转换为全角)，参数: 字符，返回: 布尔
+func isChinese(r rune) bool {
+	return IsCJK(r) || strings.ContainsRune("，。！？：；（）…“”‘’「」『』《》【】、", r)
+}
+
+// isAlnum 判断字符是否为 ASCII 字母或数字，参数: 字符，返回: 布尔
+func isAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// isHorizontalSpace 判断字符是否为行内空白 (不含换行)，参数: 字符，返回: 布尔
+func isHorizontalSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '　'
+}
+
+// runeAt 返回指定位置的字符，越界时返回 0，参数: 字符切片与位置，返回: 字符
+func runeAt(runes []rune, i int) rune {
+	if i < 0 || i >= len(runes) {
+		return 0
+	}
+	return runes[i]
+}
+
+// lastVisible 返回输出中最后一个非行内空白字符，参数: 字符切片，返回: 字符 (没有时为 0)
+func lastVisible(out []rune) rune {
+	for i := len(out) - 1; i >= 0; i-- {
+		if !isHorizontalSpace(out[i]) {
+			return out[i]
+		}
+	}
+	return 0
+}
+
+// trimSpaces 去掉输出末尾的行内空白，参数: 字符切片，返回: 新切片
+func trimSpaces(out []rune) []rune {
+	for len(out) > 0 && isHorizontalSpace(out[len(out)-1]) {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// skipSpaces 跳过位置 i 之后连续的行内空白，参数: 字符切片与当前位置，返回: 最后一个空白的位置
+func skipSpaces(runes []rune, i int) int {
+	for i+1 < len(runes) && isHorizontalSpace(runes[i+1]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,42 @@
+package langutil
+
+import "testing"
+
+// TestNormalizeChinesePunctuation 测试半角标点转换与中英文间距，参数: 测试实例，返回: 无
+func TestNormalizeChinesePunctuation(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		spacing bool
+		want    string
+	}{
+		{name: "句末与句中标点", text: "你好, 世界! 今天天气怎么样?", want: "你好，世界！今天天气怎么样？"},
+		{name: "标点前的空格", text: "注意 : 请保存 ; 然后退出.", want: "注意：请保存；然后退出。"},
+		{name: "连续标点", text: "真的?!", want: "真的？！"},
+		{name: "省略号", text: "等一下... 好的", want: "等一下……好的"},
+		{name: "括号成对转换", text: "文件 (可选) 与设置", want: "文件（可选）与设置"},
+		{name: "英文括号保持不变", text: "打开 README (see docs) 文件", want: "打开 README (see docs) 文件"},
+		{name: "数字与网址不变", text: "版本 3.14 见example.com或 http://a.b", want: "版本 3.14 见example.com或 http://a.b"},
+		{name: "纯英文不变", text: "Hello, world. Done!", want: "Hello, world. Done!"},
+		{name: "保留换行", text: "第一行,\n第二行.", want: "第一行，\n第二行。"},
+		{name: "中英文间距", text: "使用Go语言编写,共3个模块", spacing: true, want: "使用 Go 语言编写，共 3 个模块"},
+		{name: "已有空格不重复", text: "使用 Go 语言", spacing: true, want: "使用 Go 语言"},
+		{name: "未开启间距", text: "使用Go语言", want: "使用Go语言"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeChinesePunctuation(tt.text, tt.spacing); got != tt.want {
+				t.Errorf("NormalizeChinesePunctuation(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsChineseTarget 测试中文目标语言判断，参数: 测试实例，返回: 无
+func TestIsChineseTarget(t *testing.T) {
+	for code, want := range map[string]bool{"zh": true, "zh-CN": true, "ZH_tw": true, "ja": false, "": false} {
+		if got := IsChineseTarget(code); got != want {
+			t.Errorf("IsChineseTarget(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package langutil
+
+import "unicode"
+
+// Script 文字系统名称
+type Script string
+
+const (
+	ScriptLatin      Script = "Latin"      // 拉丁字母
+	ScriptCyrillic   Script = "Cyrillic"   // 西里尔字母
+	ScriptHan        Script = "Han"        // 汉字
+	ScriptKana       Script = "Kana"       // 日文假名 (含假名时汉字也计入)
+	ScriptHangul     Script = "Hangul"     // 谚文
+	ScriptArabic     Script = "Arabic"     // 阿拉伯字母
+	ScriptHebrew     Script = "Hebrew"     // 希伯来字母
+	ScriptThai       Script = "Thai"       // 泰文
+	ScriptDevanagari Script = "Devanagari" // 天城文
+	ScriptGreek      Script = "Greek"      // 希腊字母
+	ScriptArmenian   Script = "Armenian"   // 亚美尼亚字母
+	ScriptGeorgian   Script = "Georgian"   // 格鲁吉亚字母
+	ScriptBengali    Script = "Bengali"    // 孟加拉文
+)
+
+// scriptMatchers 判断字符属于哪种文字，按顺序匹配
+var scriptMatchers = []struct {
+	script Script
+	match  func(rune) bool
+}{
+	{ScriptLatin, inTable(unicode.Latin)},
+	{ScriptCyrillic, IsCyrillic},
+	{ScriptHan, inTable(unicode.Han)},
+	{ScriptKana, IsJapanese},
+	{ScriptHangul, inTable(unicode.Hangul)},
+	{ScriptArabic, IsArabic},
+	{ScriptHebrew, IsHebrew},
+	{ScriptThai, IsThai},
+	{ScriptDevanagari, IsDevanagari},
+	{ScriptGreek, inTable(unicode.Greek)},
+	{ScriptArmenian, inTable(unicode.Armenian)},
+	{ScriptGeorgian, inTable(unicode.Georgian)},
+	{ScriptBengali, inTable(unicode.Bengali)},
+}
+
+// inTable 返回判断字符是否在 Unicode 范围表中的函数，参数: 范围表，返回: 判断函数
+func inTable(table *unicode.RangeTable) func(rune) bool {
+	return func(r rune) bool {
+		return unicode.Is(table, r)
+	}
+}
+
+// scriptPrior 文字系统对应的默认语言，exclusive 表示该文字基本只用于这一种语言
+type scriptPrior struct {
+	language  string
+	exclusive bool
+}
+
+// scriptPriors 各文字系统的默认语言 (trigram 置信度过低时采用)
+var scriptPriors = map[Script]scriptPrior{
+	ScriptLatin:      {"en", false},
+	ScriptCyrillic:   {"ru", false},
+	ScriptHan:        {"zh-CN", true},
+	ScriptKana:       {"ja", true},
+	ScriptHangul:     {"ko", true},
+	ScriptArabic:     {"ar", false}, // 另有波斯语、乌尔都语
+	ScriptHebrew:     {"iw", false}, // 另有意第绪语
+	ScriptThai:       {"th", true},
+	ScriptDevanagari: {"hi", false}, // 另有马拉地语、尼泊尔语
+	ScriptGreek:      {"el", true},
+	ScriptArmenian:   {"hy", true},
+	ScriptGeorgian:   {"ka", true},
+	ScriptBengali:    {"bn", true},
+}
+
+// ScriptProportions 按全文统计各文字系统的字母占比 (含假名时汉字计入假名)，参数: 文本，返回: 文字系统到占全部字母比例的映射 (没有字母时为空，未识别文字的字母只计入分母)
+func ScriptProportions(text string) map[Script]float64 {
+	counts := make(map[Script]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptMatchers {
+			if s.match(r) {
+				counts[s.script]++
+				break
+			}
+		}
+	}
+	if counts[ScriptKana] > 0 {
+		counts[ScriptKana] += counts[ScriptHan]
+		delete(counts, ScriptHan)
+	}
+
+	proportions := make(map[Script]float64, len(counts))
+	for script, count := range counts {
+		proportions[script] = float64(count) / float64(letters)
+	}
+	return proportions
+}
+
+// DetectScript 检测文本的主要文字系统，参数: 文本，返回: 全文字母占比最高的文字 (没有可识别的字母时为空)
+// 按全文占比而不是首个字符判断，"Привет (hello)" 为西里尔文字，夹杂个别汉字的英文句子仍为拉丁文字
+func DetectScript(text string) Script {
+	script, _ := dominantScript(text)
+	return script
+}
+
+// dominantScript 返回占比最高的文字及其占比 (并列时取 scriptMatchers 中靠前的文字)，参数: 文本，返回: 文字系统与占比
+func dominantScript(text string) (Script, float64) {
+	proportions := ScriptProportions(text)
+	var best Script
+	for _, s := range scriptMatchers {
+		if proportions[s.script] > proportions[best] {
+			best = s.script
+		}
+	}
+	return best, proportions[best]
+}
@@ -0,0 +1,49 @@
+package langutil
+
+import "testing"
+
+// TestDetectScript 测试文字系统检测，参数: 测试实例，返回: 无
+func TestDetectScript(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Script
+	}{
+		{"拉丁", "Hello world", ScriptLatin},
+		{"西里尔", "Привет мир", ScriptCyrillic},
+		{"汉字", "你好世界", ScriptHan},
+		{"汉字与假名", "日本語を話します", ScriptKana},
+		{"谚文", "안녕하세요", ScriptHangul},
+		{"阿拉伯", "مرحبا بالعالم", ScriptArabic},
+		{"希伯来", "שלום עולם", ScriptHebrew},
+		{"泰文", "สวัสดีชาวโลก", ScriptThai},
+		{"天城文", "नमस्ते दुनिया", ScriptDevanagari},
+		{"希腊", "Γειά σου κόσμε", ScriptGreek},
+		{"亚美尼亚", "Բարեւ աշխարհ", ScriptArmenian},
+		{"格鲁吉亚", "გამარჯობა მსოფლიო", ScriptGeorgian},
+		{"孟加拉", "ওহে বিশ্ব", ScriptBengali},
+		{"按字母数量取主要文字", "Привет, world and everyone", ScriptLatin},
+		{"没有字母", "12345 !?", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectScript(tt.text); got != tt.want {
+				t.Errorf("DetectScript(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScriptProportions 测试按全文统计文字占比，参数: 测试实例，返回: 无
+func TestScriptProportions(t *testing.T) {
+	got := ScriptProportions("Привет (hi)")
+	if got[ScriptCyrillic] != 0.75 || got[ScriptLatin] != 0.25 {
+		t.Errorf("ScriptProportions() = %v, want Cyrillic 0.75 Latin 0.25", got)
+	}
+	if got := ScriptProportions("12345 !?"); len(got) != 0 {
+		t.Errorf("没有字母时 ScriptProportions() = %v, want empty", got)
+	}
+	if got := ScriptProportions("日本語です"); got[ScriptKana] != 1 || got[ScriptHan] != 0 {
+		t.Errorf("ScriptProportions() = %v, want 汉字计入假名", got)
+	}
+}
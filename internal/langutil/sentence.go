@@ -0,0 +1,63 @@
+package langutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitSentences 按句子边界拆分文本，拼接结果与原文完全一致 (句末空白归入前一句)，参数: 文本，返回: 句子列表
+// 全角句末标点 (。！？…) 直接断句，半角句末标点 (.!?) 后需跟空白才断句 (避免拆开 3.14、example.com)，换行也视为句子边界
+func SplitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		end := i + size
+		boundary := false
+		switch {
+		case r == '\n':
+			boundary = true
+		case isFullWidthTerminator(r):
+			end = skipClosers(text, end)
+			boundary = true
+		case r == '.' || r == '!' || r == '?':
+			end = skipClosers(text, end)
+			next, _ := utf8.DecodeRuneInString(text[end:])
+			boundary = end == len(text) || unicode.IsSpace(next)
+		}
+		if !boundary {
+			i = end
+			continue
+		}
+		// 句末空白 (包括连续换行) 归入当前句
+		end += len(text[end:]) - len(strings.TrimLeftFunc(text[end:], unicode.IsSpace))
+		sentences = append(sentences, text[start:end])
+		start, i = end, end
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// isFullWidthTerminator 判断是否为全角句末标点，参数: 字符，返回: 布尔
+func isFullWidthTerminator(r rune) bool {
+	switch r {
+	case '。', '！', '？', '…', '｡':
+		return true
+	}
+	return false
+}
+
+// skipClosers 跳过句末标点后连续的句末标点与右引号、右括号，参数: 文本与起始位置，返回: 新位置
+func skipClosers(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !strings.ContainsRune(`.!?。！？…"')]}”’」』）】`, r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,58 @@
+package langutil
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSplitSentences 测试句子拆分，参数: 测试实例，返回: 无
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "英文句子",
+			text: "Hello world. How are you? Fine!",
+			want: []string{"Hello world. ", "How are you? ", "Fine!"},
+		},
+		{
+			name: "小数与域名不断句",
+			text: "Pi is 3.14 on example.com. Done",
+			want: []string{"Pi is 3.14 on example.com. ", "Done"},
+		},
+		{
+			name: "中文句子",
+			text: "你好。今天天气很好！真的吗？",
+			want: []string{"你好。", "今天天气很好！", "真的吗？"},
+		},
+		{
+			name: "引号与省略号",
+			text: `He said "Stop." Then left... 好吧……走了`,
+			want: []string{`He said "Stop." `, "Then left... ", "好吧……", "走了"},
+		},
+		{
+			name: "换行",
+			text: "line one\n\nline two",
+			want: []string{"line one\n\n", "line two"},
+		},
+		{
+			name: "空文本",
+			text: "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitSentences(tt.text)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("SplitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if strings.Join(got, "") != tt.text {
+				t.Errorf("拼接结果与原文不一致: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// AccessLogOptions 定义访问日志文件配置，参数: 无，返回: 无
+type AccessLogOptions struct {
+	File       string // 日志文件路径，目录不存在时自动创建
+	MaxSizeMB  int    // 单个文件大小上限 (MB)，超过后轮转
+	MaxAgeDays int    // 轮转文件保留天数，0 表示不按天数清理
+	MaxBackups int    // 最多保留的轮转文件数，0 表示不按数量清理
+	Compress   bool   // 是否 gzip 压缩轮转后的文件
+	Service    string
+}
+
+// NewAccessLog 创建写入轮转文件的访问日志器，每行一条 JSON，参数: AccessLogOptions 配置，返回: 日志器与用于关闭文件的 io.Closer
+// 访问日志不受应用日志级别约束，所有请求都会写入
+func NewAccessLog(opts AccessLogOptions) (*zerolog.Logger, io.Closer) {
+	file := &lumberjack.Logger{
+		Filename:   opts.File,
+		MaxSize:    opts.MaxSizeMB,
+		MaxAge:     opts.MaxAgeDays,
+		MaxBackups: opts.MaxBackups,
+		Compress:   opts.Compress,
+		LocalTime:  true,
+	}
+
+	contextBuilder := zerolog.New(file).With().Timestamp()
+	if opts.Service != "" {
+		contextBuilder = contextBuilder.Str("service", opts.Service)
+	}
+	logger := contextBuilder.Logger().Level(zerolog.TraceLevel)
+	return &logger, file
+}
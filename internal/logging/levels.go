@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// 可单独设置级别的日志组件
+const (
+	ComponentAccess   = "access"   // HTTP 访问日志
+	ComponentCache    = "cache"    // 译文与检测缓存
+	ComponentProvider = "provider" // 翻译请求与上游提供商调用
+)
+
+// Components 全部可配置的日志组件
+var Components = []string{ComponentAccess, ComponentCache, ComponentProvider}
+
+// Levels 按组件的日志级别与 debug 采样率，运行时可调整，并发安全
+type Levels struct {
+	levels map[string]*atomic.Int32 // 组件集合固定，构建后只读
+	sample atomic.Uint32            // 每 N 条 debug 事件保留 1 条，0 或 1 表示不采样
+	seq    atomic.Uint64
+}
+
+// NewLevels 创建组件日志级别，参数: 未单独设置的组件使用的默认级别，返回: Levels 指针
+func NewLevels(def zerolog.Level) *Levels {
+	l := &Levels{levels: make(map[string]*atomic.Int32, len(Components))}
+	for _, component := range Components {
+		level := new(atomic.Int32)
+		level.Store(int32(def))
+		l.levels[component] = level
+	}
+	return l
+}
+
+// Set 设置组件级别，参数: 组件名称与级别名称 (trace/debug/info/warn/error/disabled)，返回: 组件或级别无效时的错误
+func (l *Levels) Set(component, level string) error {
+	target, ok := l.levels[component]
+	if !ok {
+		return fmt.Errorf("未知的日志组件: %s", component)
+	}
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	target.Store(int32(parsed))
+	return nil
+}
+
+// Level 返回组件当前级别，参数: 组件名称，返回: 级别 (未知组件为 info)
+func (l *Levels) Level(component string) zerolog.Level {
+	if level, ok := l.levels[component]; ok {
+		return zerolog.Level(level.Load())
+	}
+	return zerolog.InfoLevel
+}
+
+// SetSampling 设置 debug 事件采样率，参数: 每 N 条保留 1 条 (0 或 1 表示全部保留)，返回: 无
+func (l *Levels) SetSampling(n uint32) {
+	l.sample.Store(n)
+}
+
+// Sampling 返回 debug 事件采样率，参数: 无，返回: N
+func (l *Levels) Sampling() uint32 {
+	return l.sample.Load()
+}
+
+// Snapshot 返回各组件当前级别，参数: 无，返回: 组件到级别名称的映射
+func (l *Levels) Snapshot() map[string]string {
+	snapshot := make(map[string]string, len(l.levels))
+	for component, level := range l.levels {
+		snapshot[component] = zerolog.Level(level.Load()).String()
+	}
+	return snapshot
+}
+
+// Logger 派生组件日志器，级别与采样在写入时按当前设置判断，参数: 基础日志器与组件名称，返回: 日志器
+// 组件级别可以低于基础日志器的级别 (如全局 info、cache=debug)
+func (l *Levels) Logger(base *zerolog.Logger, component string) *zerolog.Logger {
+	logger := base.With().Str("component", component).Logger().
+		Level(zerolog.TraceLevel).
+		Hook(componentHook{levels: l, component: component})
+	return &logger
+}
+
+// keep 判断本条 debug 事件是否被采样保留，参数: 无，返回: 布尔
+func (l *Levels) keep() bool {
+	n := uint64(l.sample.Load())
+	if n <= 1 {
+		return true
+	}
+	return l.seq.Add(1)%n == 1
+}
+
+// componentHook 按组件级别与采样率丢弃事件的钩子
+type componentHook struct {
+	levels    *Levels
+	component string
+}
+
+// Run 实现 zerolog.Hook 接口，参数: 事件、级别与消息，返回: 无
+func (h componentHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < h.levels.Level(h.component) {
+		e.Discard()
+		return
+	}
+	if level <= zerolog.DebugLevel && !h.levels.keep() {
+		e.Discard()
+	}
+}
+
+// ParseLevel 解析级别名称，参数: 级别名称，返回: 级别与错误
+func ParseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "trace":
+		return zerolog.TraceLevel, nil
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "info":
+		return zerolog.InfoLevel, nil
+	case "warn":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	case "disabled", "off":
+		return zerolog.Disabled, nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("无效的日志级别: %q (可选 trace、debug、info、warn、error、disabled)", level)
+}
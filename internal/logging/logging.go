@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -9,29 +10,41 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// 日志输出格式
+const (
+	FormatConsole = "console" // 便于阅读的彩色控制台格式 (默认)
+	FormatJSON    = "json"    // 每行一条 JSON，便于 Loki/ELK 等采集解析
+)
+
 // Options 定义日志器配置，参数: 无，返回: 无
 type Options struct {
 	Debug   bool
 	Service string
-	Writer  io.Writer
+	Format  string    // console 或 json，默认 console
+	Output  string    // stdout、stderr 或文件路径 (追加写入)，默认 stdout；Writer 非空时忽略
+	Writer  io.Writer // 直接指定输出 (主要用于测试)
 }
 
 // New 创建带有统一字段的结构化日志器，参数: Options 配置，返回: 初始化好的 zerolog.Logger 指针
+// 输出文件无法打开时退回 stderr 并记录错误，不阻止服务启动
 func New(opts Options) *zerolog.Logger {
 	writer := opts.Writer
+	var openErr error
 	if writer == nil {
-		writer = os.Stdout
+		writer, openErr = openOutput(opts.Output)
 	}
 
-	consoleWriter := zerolog.ConsoleWriter{
-		Out:        writer,
-		TimeFormat: time.RFC3339,
-		FormatLevel: func(i interface{}) string {
-			if level, ok := i.(string); ok {
-				return strings.ToUpper(level)
-			}
-			return "INFO"
-		},
+	if strings.ToLower(strings.TrimSpace(opts.Format)) != FormatJSON {
+		writer = zerolog.ConsoleWriter{
+			Out:        writer,
+			TimeFormat: time.RFC3339,
+			FormatLevel: func(i interface{}) string {
+				if level, ok := i.(string); ok {
+					return strings.ToUpper(level)
+				}
+				return "INFO"
+			},
+		}
 	}
 
 	level := zerolog.InfoLevel
@@ -39,12 +52,30 @@ func New(opts Options) *zerolog.Logger {
 		level = zerolog.DebugLevel
 	}
 
-	contextBuilder := zerolog.New(consoleWriter).With().Timestamp()
+	contextBuilder := zerolog.New(writer).With().Timestamp()
 	if opts.Service != "" {
 		contextBuilder = contextBuilder.Str("service", opts.Service)
 	}
 	// 注意：移除了 started_at 字段，服务启动时间应在启动时单独记录一次
 
 	logger := contextBuilder.Logger().Level(level)
+	if openErr != nil {
+		logger.Error().Err(openErr).Str("output", opts.Output).Msg("无法打开日志输出，改为输出到 stderr")
+	}
 	return &logger
 }
+
+// openOutput 解析日志输出目的地，参数: stdout、stderr 或文件路径，返回: 输出与打开失败的错误 (失败时输出为 stderr)
+func openOutput(output string) (io.Writer, error) {
+	switch strings.TrimSpace(output) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return os.Stderr, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,416 @@
+// Package metrics 汇总服务自定义的 Prometheus 指标
+// 指标在包初始化时注册到默认注册表，由 /metrics 统一暴露
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace 自定义指标的命名空间
+const namespace = "translate"
+
+// 限流相关指标
+var (
+	// RateLimitDecisions 限流判定次数，按路由与结果 (allowed/limited) 区分
+	RateLimitDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rate_limit",
+		Name:      "decisions_total",
+		Help:      "Rate limiter decisions by route and result.",
+	}, []string{"route", "result"})
+
+	// RateLimitTrackedClients 限流器当前跟踪的客户端数量
+	RateLimitTrackedClients = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "rate_limit",
+		Name:      "tracked_clients",
+		Help:      "Number of client buckets currently tracked by the rate limiter.",
+	}, []string{"route"})
+)
+
+// 访问控制相关指标
+var (
+	// AccessDenied 访问控制拒绝次数，按原因 (deny/not_allowed/invalid_ip) 区分
+	AccessDenied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "access_control",
+		Name:      "denied_total",
+		Help:      "Requests rejected by the IP access control list, by reason.",
+	}, []string{"reason"})
+
+	// AutoBanEvents 自动封禁事件次数，按事件 (banned 新增封禁/rejected 拒绝被封禁客户端的请求) 区分
+	AutoBanEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auto_ban",
+		Name:      "events_total",
+		Help:      "Automatic temporary ban events by type (banned/rejected).",
+	}, []string{"event"})
+)
+
+// 提供商故障转移相关指标
+var (
+	// ProviderAttempts 故障转移链中各提供商的调用次数，按提供商与结果 (success/error) 区分
+	ProviderAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "failover",
+		Name:      "attempts_total",
+		Help:      "Provider attempts in the failover chain by provider and result.",
+	}, []string{"provider", "result"})
+
+	// ProviderFailovers 请求转到下一个提供商的次数，按出错的提供商区分
+	ProviderFailovers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "failover",
+		Name:      "switches_total",
+		Help:      "Requests moved on to the next provider after a failure, by failed provider.",
+	}, []string{"provider"})
+)
+
+// 规则路由相关指标
+var (
+	// RoutingMatches 匹配路由规则的请求数，按规则名称与选中的提供商区分
+	RoutingMatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "routing",
+		Name:      "matches_total",
+		Help:      "Requests routed by a routing rule, by rule and provider.",
+	}, []string{"rule", "provider"})
+
+	// CanaryRequests 参与金丝雀分流的请求数，按分组 (canary/control) 与提供商区分
+	CanaryRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "routing",
+		Name:      "canary_requests_total",
+		Help:      "Requests eligible for canary splitting, by group (canary/control) and provider.",
+	}, []string{"group", "provider"})
+)
+
+// 批量合并相关指标
+var (
+	// BatchSize 合并后每次上游调用包含的文本段数
+	BatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "batch",
+		Name:      "size",
+		Help:      "Number of text segments combined into one upstream call.",
+		Buckets:   []float64{1, 2, 4, 8, 16, 32, 64},
+	})
+)
+
+// 术语表相关指标
+var (
+	// GlossaryTerms 翻译前被术语表保护的术语数量
+	GlossaryTerms = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "glossary",
+		Name:      "terms_applied_total",
+		Help:      "Glossary terms protected before translation and restored afterwards.",
+	})
+)
+
+// 脏词过滤相关指标
+var (
+	// ProfanityFiltered 被脏词过滤的译文数，按处理方式 (mask/reject) 区分
+	ProfanityFiltered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "profanity",
+		Name:      "filtered_total",
+		Help:      "Translations that matched the profanity word lists, by mode.",
+	}, []string{"mode"})
+)
+
+// 译文后处理规则相关指标
+var (
+	// RewriteApplied 后处理规则改写过的译文数
+	RewriteApplied = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rewrite",
+		Name:      "applied_total",
+		Help:      "Translations changed by post-processing rewrite rules.",
+	})
+)
+
+// 翻译记忆相关指标
+var (
+	// MemoryLookups 翻译记忆查找次数，按结果 (exact/fuzzy/miss) 区分
+	MemoryLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "memory",
+		Name:      "lookups_total",
+		Help:      "Translation memory lookups by result (exact/fuzzy/miss).",
+	}, []string{"result"})
+)
+
+// 同语言短路相关指标
+var (
+	// SameLanguageSkipped 源语言与目标语言相同而直接返回原文的请求数，按源语言来源 (request/detection_cache/script) 区分
+	SameLanguageSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "same_language",
+		Name:      "skipped_total",
+		Help:      "Requests answered with the original text because source and target languages match, by how the source was known.",
+	}, []string{"source"})
+)
+
+// 对冲请求相关指标
+var (
+	// HedgeRequests 向备用提供商发起的对冲请求数，按原因 (delay/error) 区分
+	HedgeRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hedge",
+		Name:      "requests_total",
+		Help:      "Hedged requests sent to the backup provider, by reason (delay/error).",
+	}, []string{"reason"})
+
+	// HedgeWins 发起对冲后率先成功的提供商
+	HedgeWins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hedge",
+		Name:      "wins_total",
+		Help:      "Hedged requests won by each provider.",
+	}, []string{"provider"})
+)
+
+// 对比模式与影子流量相关指标
+var (
+	// ComparisonSimilarity 对比模式下主提供商与对比提供商译文的相似度
+	ComparisonSimilarity = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "comparison",
+		Name:      "similarity",
+		Help:      "Similarity between the primary and the comparison provider translations.",
+		Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1},
+	}, []string{"provider"})
+
+	// ShadowRequests 影子流量的镜像请求数，按影子提供商与结果 (success/error/dropped) 区分
+	ShadowRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "shadow",
+		Name:      "requests_total",
+		Help:      "Requests mirrored to the shadow provider, by provider and result.",
+	}, []string{"provider", "result"})
+
+	// ShadowSimilarity 影子提供商与主提供商译文的相似度
+	ShadowSimilarity = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "shadow",
+		Name:      "similarity",
+		Help:      "Similarity between the primary and the shadow provider translations.",
+		Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1},
+	}, []string{"provider"})
+)
+
+// 上游用量相关指标
+var (
+	// UsageCharacters 发往上游的字符数，按提供商与模型区分 (不含客户端维度，按客户端的明细见 /admin/usage)
+	UsageCharacters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "usage",
+		Name:      "characters_total",
+		Help:      "Characters sent to upstream providers by provider and model.",
+	}, []string{"provider", "model"})
+
+	// UsageRequests 发往上游的翻译请求数，按提供商与模型区分
+	UsageRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "usage",
+		Name:      "requests_total",
+		Help:      "Translation requests sent to upstream providers by provider and model.",
+	}, []string{"provider", "model"})
+
+	// UsageCost 按价格表估算的上游费用，按提供商与模型区分 (货币单位见 usage.currency)
+	UsageCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "usage",
+		Name:      "cost_total",
+		Help:      "Estimated upstream cost from the configured pricing, by provider and model.",
+	}, []string{"provider", "model"})
+
+	// UsageBudgetExceeded 因预算用尽被拒绝或改用备用提供商的调用数，按提供商与处理方式区分
+	UsageBudgetExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "usage",
+		Name:      "budget_exceeded_total",
+		Help:      "Upstream calls rejected or redirected because the provider budget is exhausted, by provider and action.",
+	}, []string{"provider", "action"})
+
+	// UsageBudgetAlerts 预算告警次数，按提供商、周期与级别 (alert/exceeded) 区分
+	UsageBudgetAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "usage",
+		Name:      "budget_alerts_total",
+		Help:      "Budget alerts raised, by provider, period and level.",
+	}, []string{"provider", "period", "level"})
+)
+
+// 提供商熔断与重试预算相关指标
+var (
+	// CircuitBreakerState 熔断器当前状态 (0=closed, 1=open, 2=half_open)
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "state",
+		Help:      "Current circuit breaker state per provider (0=closed, 1=open, 2=half_open).",
+	}, []string{"provider"})
+
+	// CircuitBreakerTransitions 熔断器状态切换次数，按目标状态区分
+	CircuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "transitions_total",
+		Help:      "Circuit breaker state transitions per provider, by new state.",
+	}, []string{"provider", "state"})
+
+	// CircuitBreakerRejections 熔断器拒绝的调用数
+	CircuitBreakerRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "rejections_total",
+		Help:      "Upstream calls rejected because the provider's circuit breaker is open.",
+	}, []string{"provider"})
+
+	// RetryBudgetExhausted 因重试预算耗尽而放弃的重试次数
+	RetryBudgetExhausted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "retry_budget",
+		Name:      "exhausted_total",
+		Help:      "Upstream retries skipped because the shared retry budget was exhausted.",
+	})
+)
+
+// 提供商后台健康检查相关指标
+var (
+	// ProviderHealthy 提供商最近的健康检查结果 (1=健康，0=不健康)
+	ProviderHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "healthy",
+		Help:      "Whether the provider passed its recent background health checks (1) or not (0).",
+	}, []string{"provider"})
+
+	// ProviderHealthchecks 提供商健康检查次数，按结果区分
+	ProviderHealthchecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "healthchecks_total",
+		Help:      "Background provider health checks, by result (success, failure).",
+	}, []string{"provider", "result"})
+)
+
+// 多中继地址相关指标
+var (
+	// ProviderEndpointUp 提供商中继地址是否可用 (1=可用，0=已剔除)
+	ProviderEndpointUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "provider_endpoint",
+		Name:      "up",
+		Help:      "Whether a provider relay endpoint is currently in rotation (1) or evicted (0).",
+	}, []string{"provider", "endpoint"})
+
+	// ProviderEndpointEvictions 中继地址被剔除的次数
+	ProviderEndpointEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider_endpoint",
+		Name:      "evictions_total",
+		Help:      "Provider relay endpoints evicted after consecutive failures.",
+	}, []string{"provider", "endpoint"})
+)
+
+// 上游并发隔离相关指标
+var (
+	// BulkheadInFlight 隔离舱当前进行中的上游调用数
+	BulkheadInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "bulkhead",
+		Name:      "in_flight",
+		Help:      "Upstream calls currently holding a bulkhead slot.",
+	}, []string{"bulkhead"})
+
+	// BulkheadQueued 隔离舱当前排队等待的调用数
+	BulkheadQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "bulkhead",
+		Name:      "queued",
+		Help:      "Upstream calls currently waiting for a bulkhead slot.",
+	}, []string{"bulkhead"})
+
+	// BulkheadRejections 隔离舱拒绝次数，按原因 (queue_full/queue_timeout/canceled) 区分
+	BulkheadRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bulkhead",
+		Name:      "rejections_total",
+		Help:      "Upstream calls rejected by a bulkhead, by reason.",
+	}, []string{"bulkhead", "reason"})
+)
+
+// 上游调用相关指标 (由提供商适配层在每次上游调用后记录，缓存命中与被熔断、隔离舱拒绝的请求不计入)
+var (
+	// UpstreamRequests 上游翻译调用次数，按提供商、模型与结果 (success/error) 区分
+	UpstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "requests_total",
+		Help:      "Upstream translation calls by provider, model and result (success/error).",
+	}, []string{"provider", "model", "result"})
+
+	// UpstreamErrors 上游翻译失败次数，按提供商、模型与错误类别 (timeout/unauthorized/rate_limited 等) 区分
+	UpstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "errors_total",
+		Help:      "Failed upstream translation calls by provider, model and error class.",
+	}, []string{"provider", "model", "class"})
+
+	// UpstreamDuration 上游翻译调用耗时 (含重试)，按提供商与模型区分
+	UpstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "duration_seconds",
+		Help:      "Upstream translation call latency including retries, by provider and model.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"provider", "model"})
+
+	// UpstreamCharacters 上游翻译的字符数，按提供商、模型与方向 (source 原文/translated 译文) 区分
+	UpstreamCharacters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "characters_total",
+		Help:      "Characters sent to (source) and returned by (translated) upstream providers, by provider and model.",
+	}, []string{"provider", "model", "direction"})
+)
+
+// 译文缓存相关指标
+var (
+	// CacheLookups 译文缓存读取次数，按结果 (hit/miss) 区分
+	CacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Translation cache lookups by result (hit/miss).",
+	}, []string{"result"})
+
+	// CacheHitRatio 最近一个采样周期内的译文缓存命中率 (周期内没有读取时保持上次的值)
+	CacheHitRatio = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "hit_ratio",
+		Help:      "Translation cache hit ratio over the last sampling interval.",
+	})
+
+	// CacheEntries 缓存条目数 (Redis 按抽样估算)
+	CacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "entries",
+		Help:      "Number of cache entries (sampled for Redis).",
+	})
+
+	// CacheValueBytes 缓存值的总字节数 (Redis 按抽样估算)
+	CacheValueBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "value_bytes",
+		Help:      "Total size of cached values in bytes (sampled for Redis).",
+	})
+)
@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/requestid"
+)
+
+// ErrShuttingDown 停机开始后提交的管道请求返回的错误
+var ErrShuttingDown = errors.New("服务正在停机，不再接受新的翻译请求")
+
+// Background 管道的后台任务组，持有所有脱离请求生命周期的任务，停机时统一排空
+// 同时记录进行中的管道请求，停机时先等待请求结束，使其提交的后台任务不被丢弃
+type Background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *zerolog.Logger
+
+	runs     sync.WaitGroup
+	inFlight atomic.Int64
+	pending  atomic.Int64 // 进行中的后台任务数
+
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool // 停机已开始，不再登记新的管道请求 (与 runs.Add 同在锁内，避免与 Wait 竞争)
+	closed   bool // 不再接收新的后台任务
+}
+
+// NewBackground 创建后台任务组，参数: 日志器 (可为空)，返回: Background 指针
+func NewBackground(logger *zerolog.Logger) *Background {
+	if logger == nil {
+		nop := zerolog.Nop()
+		logger = &nop
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Background{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// Go 提交后台任务，停机开始后提交的任务会被丢弃，参数: 任务名称与任务，返回: 无
+func (b *Background) Go(name string, fn func(ctx context.Context) error) {
+	b.goWithID(name, "", fn)
+}
+
+// goWithID 提交关联请求 ID 的后台任务，任务上下文与失败日志都带上该 ID，参数: 任务名称、请求 ID 与任务，返回: 无
+func (b *Background) goWithID(name, id string, fn func(ctx context.Context) error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		b.logger.Debug().Str("task", name).Str("request_id", id).Msg("后台任务组已关闭，丢弃任务")
+		return
+	}
+	b.wg.Add(1)
+	b.pending.Add(1)
+	b.mu.Unlock()
+
+	go func() {
+		defer b.wg.Done()
+		defer b.pending.Add(-1)
+		if err := fn(requestid.NewContext(b.ctx, id)); err != nil {
+			b.logger.Warn().Err(err).Str("task", name).Str("request_id", id).Msg("后台任务失败")
+		}
+	}()
+}
+
+// track 登记一次进行中的管道请求，停机开始后拒绝登记，参数: 无，返回: 请求结束时调用的函数或 ErrShuttingDown
+func (b *Background) track() (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.draining {
+		return nil, ErrShuttingDown
+	}
+	b.runs.Add(1)
+	b.inFlight.Add(1)
+	return func() {
+		b.inFlight.Add(-1)
+		b.runs.Done()
+	}, nil
+}
+
+// InFlight 返回进行中的管道请求数，参数: 无，返回: 数量
+func (b *Background) InFlight() int64 {
+	return b.inFlight.Load()
+}
+
+// Pending 返回进行中的后台任务数，参数: 无，返回: 数量
+func (b *Background) Pending() int64 {
+	return b.pending.Load()
+}
+
+// Shutdown 拒绝新的管道请求并等待进行中的请求结束，再停止接收新任务并等待已有任务结束，参数: 控制等待时长的上下文，返回: 超时时的错误
+// 等待超时后取消剩余任务的上下文
+func (b *Background) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.draining = true
+	b.mu.Unlock()
+
+	if n := b.InFlight(); n > 0 {
+		b.logger.Info().Int64("in_flight", n).Msg("等待进行中的翻译请求结束")
+	}
+	err := wait(ctx, &b.runs)
+
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	if err == nil {
+		err = wait(ctx, &b.wg)
+	}
+	b.cancel()
+	return err
+}
+
+// wait 等待 WaitGroup 归零，参数: 控制等待时长的上下文与 WaitGroup，返回: 超时时的错误
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// batchKey 可以合并为一次上游调用的请求特征
+type batchKey struct {
+	source    string
+	target    string
+	model     string
+	dt        string
+	formality string
+}
+
+// batchItem 等待批量结果的单个请求
+type batchItem struct {
+	text string
+	done chan batchResult
+}
+
+// batchResult 单个请求的批量结果
+type batchResult struct {
+	resp *translation.Response
+	err  error
+}
+
+// pendingBatch 正在收集的一批请求
+type pendingBatch struct {
+	ctx      context.Context // 首个请求的上下文 (去除取消信号，保留追踪等信息)
+	deadline time.Time       // 首个请求的截止时间 (为零表示不限制)
+	items    []*batchItem
+	timer    *time.Timer
+	flushed  bool
+}
+
+// batcher 把短时间内到达的同类请求合并为一次批量调用，并发安全
+type batcher struct {
+	service deeplx.TranslationService
+	maxSize int
+	maxWait time.Duration
+
+	mu      sync.Mutex
+	pending map[batchKey]*pendingBatch
+}
+
+// BatchHandler 批量合并处理器：maxWait 内到达的源语言、目标语言、模型、数据类型与语气相同的请求合并为一次上游调用，参数: 翻译服务、每批最多段数与最长等待时间，返回: 处理器
+// 服务不支持原生批量 (Capabilities().SupportsBatch 为 false) 时等同于 ServiceHandler；凑满 maxSize 时立即发出
+func BatchHandler(service deeplx.TranslationService, maxSize int, maxWait time.Duration) Handler {
+	if !service.Capabilities().SupportsBatch || maxSize < 2 {
+		return ServiceHandler(service)
+	}
+	b := &batcher{
+		service: service,
+		maxSize: maxSize,
+		maxWait: maxWait,
+		pending: make(map[batchKey]*pendingBatch),
+	}
+	return b.handle
+}
+
+// handle 把请求加入当前批次并等待结果，参数: 上下文与请求，返回: 翻译响应与错误
+// 请求自身被取消时立即返回，批次中的其他请求不受影响
+func (b *batcher) handle(ctx context.Context, req *Request) (*translation.Response, error) {
+	if err := checkCapabilities(b.service, req); err != nil {
+		return nil, err
+	}
+	key := batchKey{source: req.Source, target: req.Target, model: req.Model, dt: strings.Join(req.DT, ","), formality: req.Formality}
+	item := &batchItem{text: req.Text, done: make(chan batchResult, 1)}
+
+	b.mu.Lock()
+	batch, ok := b.pending[key]
+	if !ok {
+		// 批次由多个请求共享，首个请求被取消时其余请求仍需要结果，因此只沿用其截止时间
+		batch = &pendingBatch{ctx: context.WithoutCancel(ctx)}
+		batch.deadline, _ = ctx.Deadline()
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.maxWait, func() { b.flush(key, batch) })
+	}
+	batch.items = append(batch.items, item)
+	full := len(batch.items) >= b.maxSize
+	if full {
+		// 后续请求进入新的批次
+		delete(b.pending, key)
+	}
+	b.mu.Unlock()
+
+	if full {
+		batch.timer.Stop()
+		go b.flush(key, batch)
+	}
+
+	select {
+	case result := <-item.done:
+		return result.resp, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush 发出一批请求并分发结果，参数: 批次键与批次，返回: 无
+func (b *batcher) flush(key batchKey, batch *pendingBatch) {
+	b.mu.Lock()
+	if batch.flushed {
+		b.mu.Unlock()
+		return
+	}
+	batch.flushed = true
+	if b.pending[key] == batch {
+		delete(b.pending, key)
+	}
+	b.mu.Unlock()
+
+	ctx, cancel := batch.ctx, context.CancelFunc(func() {})
+	if !batch.deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(batch.ctx, batch.deadline)
+	}
+	defer cancel()
+	ctx = deeplx.WithFormality(ctx, key.formality)
+
+	texts := make([]string, len(batch.items))
+	for i, item := range batch.items {
+		texts[i] = item.text
+	}
+	metrics.BatchSize.Observe(float64(len(texts)))
+
+	dt := []string(nil)
+	if key.dt != "" {
+		dt = strings.Split(key.dt, ",")
+	}
+	start := time.Now()
+	responses, err := b.service.TranslateBatch(ctx, texts, key.source, key.target, dt, key.model)
+	if err == nil && len(responses) != len(texts) {
+		err = errors.New("批量翻译返回的结果数与请求数不一致")
+	}
+	latency := time.Since(start)
+	for i, item := range batch.items {
+		if err != nil {
+			item.done <- batchResult{err: err}
+			continue
+		}
+		if resp := responses[i]; resp != nil {
+			resp.Model = key.model
+			resp.UpstreamLatency = latency
+		}
+		item.done <- batchResult{resp: responses[i]}
+	}
+}
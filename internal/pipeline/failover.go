@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// Provider 故障转移链中的一个提供商
+type Provider struct {
+	Name    string                    // 提供商名称，记录在响应的 Provider 字段
+	Service deeplx.TranslationService // 提供商服务，失败时需返回错误 (而非原文) 才能触发转移
+	Model   string                    // 提供商的默认模型，请求的 DefaultModel 为 true 时代替 Request.Model (可为空)
+}
+
+// request 返回发往该提供商的请求，请求使用默认模型时换成该提供商的默认模型，参数: 请求，返回: 请求 (无需替换时原样返回)
+func (p Provider) request(req *Request) *Request {
+	if !req.DefaultModel || req.Model == p.Model {
+		return req
+	}
+	resolved := *req
+	resolved.Model = p.Model
+	return &resolved
+}
+
+// FailoverHandler 按顺序尝试各提供商，前一个出错或超时时转到下一个，参数: 提供商列表 (按优先级) 与单次尝试超时 (<=0 只受请求超时约束)，返回: 处理器
+// 成功的响应在 Provider 字段记录实际提供译文的提供商；请求上下文结束 (客户端取消或管道整体超时) 后不再尝试
+// IsAvailable 为 false 的提供商 (如后台健康检查判定不健康) 排到最后，只在其余提供商都失败时尝试；
+// 请求使用默认模型时各提供商使用各自的默认模型
+func FailoverHandler(providers []Provider, attemptTimeout time.Duration) Handler {
+	handlers := make([]Handler, len(providers))
+	for i, p := range providers {
+		handlers[i] = ServiceHandler(p.Service)
+	}
+
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		var errs []error
+		order := availableFirst(providers)
+		for n, i := range order {
+			p := providers[i]
+			resp, err := attempt(ctx, handlers[i], p.request(req), attemptTimeout)
+			if err == nil {
+				metrics.ProviderAttempts.WithLabelValues(p.Name, "success").Inc()
+				if resp != nil {
+					resp.Provider = p.Name
+				}
+				return resp, nil
+			}
+
+			metrics.ProviderAttempts.WithLabelValues(p.Name, "error").Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			if ctx.Err() != nil {
+				break
+			}
+			if n < len(order)-1 {
+				metrics.ProviderFailovers.WithLabelValues(p.Name).Inc()
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// availableFirst 返回尝试顺序：可用的提供商在前，不可用的在后，各自保持优先级顺序，参数: 提供商列表，返回: 下标列表
+func availableFirst(providers []Provider) []int {
+	order := make([]int, 0, len(providers))
+	var unavailable []int
+	for i, p := range providers {
+		if p.Service.IsAvailable() {
+			order = append(order, i)
+		} else {
+			unavailable = append(unavailable, i)
+		}
+	}
+	return append(order, unavailable...)
+}
+
+// attempt 以单次尝试超时调用提供商，参数: 请求上下文、处理器、请求与超时，返回: 翻译响应与错误
+func attempt(ctx context.Context, handler Handler, req *Request, timeout time.Duration) (*translation.Response, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// hedgeResult 对冲请求中单个提供商的调用结果
+type hedgeResult struct {
+	provider string
+	resp     *translation.Response
+	err      error
+}
+
+// HedgeHandler 对冲请求：主提供商在 delay 内未返回时同时请求备用提供商，采用先成功的结果并取消另一个，参数: 主提供商、备用提供商与对冲延迟，返回: 处理器
+// 主提供商在延迟前出错时立即改用备用提供商；两者都失败时返回各自的错误；请求上下文结束后不再发起对冲；
+// 请求使用默认模型时各提供商使用各自的默认模型
+func HedgeHandler(primary, backup Provider, delay time.Duration) Handler {
+	primaryHandler := ServiceHandler(primary.Service)
+	backupHandler := ServiceHandler(backup.Service)
+
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		// 返回时取消仍在进行的调用
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make(chan hedgeResult, 2)
+		launch := func(p Provider, handler Handler) {
+			go func() {
+				resp, err := handler(ctx, p.request(req))
+				results <- hedgeResult{provider: p.Name, resp: resp, err: err}
+			}()
+		}
+
+		launch(primary, primaryHandler)
+		pending := 1
+		hedged := false
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		fire := timer.C
+
+		hedge := func(reason string) {
+			hedged = true
+			fire = nil
+			pending++
+			metrics.HedgeRequests.WithLabelValues(reason).Inc()
+			launch(backup, backupHandler)
+		}
+
+		var errs []error
+		for {
+			select {
+			case <-fire:
+				hedge("delay")
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					if hedged {
+						metrics.HedgeWins.WithLabelValues(r.provider).Inc()
+					}
+					if r.resp != nil {
+						r.resp.Provider = r.provider
+					}
+					return r.resp, nil
+				}
+				errs = append(errs, fmt.Errorf("%s: %w", r.provider, r.err))
+				if !hedged && ctx.Err() == nil {
+					hedge("error")
+				}
+				if pending == 0 {
+					return nil, errors.Join(errs...)
+				}
+			}
+		}
+	}
+}
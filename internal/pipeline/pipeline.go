@@ -0,0 +1,274 @@
+// Package pipeline 将翻译请求组织为显式的阶段链 (handler → 各阶段 → 提供商)
+// 每次请求在独立的 errgroup 中运行，阶段拥有各自的子上下文，取消沿链路向下传播
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/XgzK/translate-services/internal/requestid"
+	"github.com/XgzK/translate-services/internal/tracing"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// Request 流经管道的翻译请求
+type Request struct {
+	Text   string   // 待翻译文本
+	Source string   // 源语言代码 (空或 auto 表示自动检测)
+	Target string   // 目标语言代码
+	DT     []string // 请求的数据类型
+	Model  string   // 模型名称 (可选)
+
+	// DefaultModel 表示 Model 取自提供商的默认配置 (请求、路由与分流都未指定模型)，
+	// 故障转移与对冲的其他提供商此时改用各自的默认模型 (见 Provider.Model)
+	DefaultModel bool
+
+	Formality string // 语气 (可选，deeplx.NormalizeFormality 规范化后的取值)
+	Client    string // 客户端标识 (用于用量统计，不参与翻译)
+	Provider  string // 请求指定的提供商 (可选，为空时使用默认路由)
+	Cache     string // 客户端缓存指令 (CacheDefault/CacheNoCache/CacheNoStore)
+}
+
+// 请求的译文缓存指令
+const (
+	CacheDefault = ""         // 正常读写缓存
+	CacheNoCache = "no-cache" // 不读缓存，结果仍写入 (强制刷新)
+	CacheNoStore = "no-store" // 既不读也不写缓存
+)
+
+// Handler 处理请求并返回译文，阶段通过调用 next 把请求交给下游
+type Handler func(ctx context.Context, req *Request) (*translation.Response, error)
+
+// Stage 管道中的一个可插拔阶段 (缓存、DLP、译后编辑、影子流量等)
+type Stage interface {
+	// Name 返回阶段名称，用于单阶段超时配置与日志
+	Name() string
+
+	// Process 处理请求，参数: 阶段上下文、请求、下游处理器，返回: 翻译响应与错误
+	// 阶段可以短路 (不调用 next)、改写请求或响应，也可以调用 Go / Detach 派生并发任务
+	Process(ctx context.Context, req *Request, next Handler) (*translation.Response, error)
+}
+
+// ServiceHandler 将翻译服务适配为管道末端处理器，调用前按服务能力校验请求，并在响应中记录模型与上游耗时，参数: 翻译服务，返回: 处理器
+func ServiceHandler(service deeplx.TranslationService) Handler {
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		if err := checkCapabilities(service, req); err != nil {
+			return nil, err
+		}
+		ctx = deeplx.WithFormality(ctx, req.Formality)
+		start := time.Now()
+		var resp *translation.Response
+		var err error
+		if req.Model != "" {
+			resp, err = service.TranslateWithModel(ctx, req.Text, req.Source, req.Target, req.DT, req.Model)
+		} else {
+			resp, err = service.Translate(ctx, req.Text, req.Source, req.Target, req.DT)
+		}
+		if resp != nil && !resp.Cached {
+			resp.Model = req.Model
+			resp.UpstreamLatency = time.Since(start)
+		}
+		return resp, err
+	}
+}
+
+// checkCapabilities 按服务能力校验请求，超出能力的请求不发往上游，参数: 翻译服务与请求，返回: 错误 (可用 errors.Is 判断 deeplx.ErrUnsupportedLanguage 等类别)
+func checkCapabilities(service deeplx.TranslationService, req *Request) error {
+	if err := service.Capabilities().Check(req.Text, req.Source, req.Target, req.Model); err != nil {
+		return fmt.Errorf("%s: %w", service.GetName(), err)
+	}
+	return nil
+}
+
+// Pipeline 由若干阶段与末端处理器组成的请求管道，构建后并发安全
+type Pipeline struct {
+	stages        []Stage
+	terminal      Handler
+	timeout       time.Duration
+	timeoutFunc   func(req *Request) time.Duration // 按请求计算的超时，返回值 >0 时取代 timeout
+	stageTimeouts map[string]time.Duration
+	background    *Background
+	handler       Handler // 预先组装好的处理链
+}
+
+// Option 管道可选配置函数类型
+type Option func(*Pipeline)
+
+// WithStages 追加阶段，按传入顺序由外向内执行，参数: 阶段列表，返回: 配置函数
+func WithStages(stages ...Stage) Option {
+	return func(p *Pipeline) {
+		p.stages = append(p.stages, stages...)
+	}
+}
+
+// WithTimeout 设置整条管道的超时，参数: 超时时间 (<=0 不限制)，返回: 配置函数
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Pipeline) {
+		p.timeout = timeout
+	}
+}
+
+// WithTimeoutFunc 按请求计算整条管道的超时 (如按文本长度)，返回值 <=0 时使用 WithTimeout 的固定超时，参数: 计算函数，返回: 配置函数
+func WithTimeoutFunc(fn func(req *Request) time.Duration) Option {
+	return func(p *Pipeline) {
+		p.timeoutFunc = fn
+	}
+}
+
+// WithStageTimeout 为指定阶段单独设置超时，参数: 阶段名称与超时时间，返回: 配置函数
+func WithStageTimeout(name string, timeout time.Duration) Option {
+	return func(p *Pipeline) {
+		p.stageTimeouts[name] = timeout
+	}
+}
+
+// WithBackground 设置后台任务组，阶段通过 Detach 提交的任务在其中运行，参数: 后台任务组，返回: 配置函数
+func WithBackground(background *Background) Option {
+	return func(p *Pipeline) {
+		p.background = background
+	}
+}
+
+// New 创建管道，参数: 末端处理器与可选配置，返回: Pipeline 指针
+func New(terminal Handler, opts ...Option) *Pipeline {
+	p := &Pipeline{
+		terminal:      terminal,
+		stageTimeouts: make(map[string]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	handler := traced("provider", p.terminal)
+	for i := len(p.stages) - 1; i >= 0; i-- {
+		handler = p.wrap(p.stages[i], handler)
+	}
+	p.handler = handler
+	return p
+}
+
+// Stages 返回阶段名称列表，参数: 无，返回: 按执行顺序排列的名称
+func (p *Pipeline) Stages() []string {
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.Name())
+	}
+	return names
+}
+
+// Run 执行一次请求，参数: 请求上下文与请求，返回: 翻译响应与错误
+// 阶段通过 Go 派生的并发任务全部结束后才返回，任一任务失败都会取消其余任务
+func (p *Pipeline) Run(ctx context.Context, req *Request) (resp *translation.Response, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "pipeline.run", trace.WithAttributes(
+		attribute.String("translate.source", req.Source),
+		attribute.String("translate.target", req.Target),
+		attribute.Int("translate.chars", utf8.RuneCountInString(req.Text)),
+		attribute.String("translate.model", req.Model),
+	))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	timeout := p.timeout
+	if p.timeoutFunc != nil {
+		if d := p.timeoutFunc(req); d > 0 {
+			timeout = d
+		}
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if p.background != nil {
+		done, err := p.background.track()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		ctx = context.WithValue(ctx, backgroundKey{}, p.background)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	gctx = context.WithValue(gctx, groupKey{}, g)
+
+	g.Go(func() error {
+		var err error
+		resp, err = p.handler(gctx, req)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// wrap 用阶段包装下游处理器，为阶段派生独立的子上下文，参数: 阶段与下游处理器，返回: 包装后的处理器
+func (p *Pipeline) wrap(stage Stage, next Handler) Handler {
+	timeout := p.stageTimeouts[stage.Name()]
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		} else {
+			ctx, cancel = context.WithCancel(ctx)
+		}
+		defer cancel()
+
+		ctx, span := tracing.Tracer().Start(ctx, "pipeline.stage "+stage.Name())
+		defer span.End()
+		resp, err := stage.Process(ctx, req, next)
+		tracing.RecordError(span, err)
+		return resp, err
+	}
+}
+
+// traced 为处理器包装追踪 span，参数: span 名称后缀与处理器，返回: 包装后的处理器
+func traced(name string, next Handler) Handler {
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		ctx, span := tracing.Tracer().Start(ctx, "pipeline."+name)
+		defer span.End()
+		resp, err := next(ctx, req)
+		tracing.RecordError(span, err)
+		return resp, err
+	}
+}
+
+// groupKey 请求级 errgroup 的上下文键
+type groupKey struct{}
+
+// backgroundKey 后台任务组的上下文键
+type backgroundKey struct{}
+
+// Go 在当前请求的 errgroup 中派生并发任务，Run 会等待其结束，参数: 阶段上下文与任务，返回: 无
+// 不在管道内调用时 (如直接使用缓存装饰器) 同步执行任务
+func Go(ctx context.Context, fn func() error) {
+	if g, ok := ctx.Value(groupKey{}).(*errgroup.Group); ok {
+		g.Go(fn)
+		return
+	}
+	_ = fn()
+}
+
+// Detach 提交不阻塞响应的后台任务 (如缓存回写)，其生命周期归后台任务组管理，参数: 上下文、任务名称与任务，返回: 无
+// 任务收到的上下文不随请求取消，只在后台任务组被强制关闭时取消
+// 未配置后台任务组时同步执行，保证任务不会脱离任何生命周期管理
+func Detach(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	if background, ok := ctx.Value(backgroundKey{}).(*Background); ok {
+		background.goWithID(name, requestid.FromContext(ctx), fn)
+		return
+	}
+	_ = fn(context.WithoutCancel(ctx))
+}
@@ -0,0 +1,744 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/XgzK/translate-services/internal/requestid"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// funcStage 以函数实现的测试阶段
+type funcStage struct {
+	name string
+	fn   func(ctx context.Context, req *Request, next Handler) (*translation.Response, error)
+}
+
+func (f funcStage) Name() string { return f.name }
+
+func (f funcStage) Process(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+	return f.fn(ctx, req, next)
+}
+
+// echoTerminal 返回原文大写的末端处理器
+func echoTerminal(ctx context.Context, req *Request) (*translation.Response, error) {
+	return &translation.Response{
+		Src:       req.Source,
+		Sentences: []translation.Sentence{{Orig: req.Text, Trans: strings.ToUpper(req.Text)}},
+	}, nil
+}
+
+// TestPipelineStageOrder 测试阶段按声明顺序由外向内执行，参数: 测试实例，返回: 无
+func TestPipelineStageOrder(t *testing.T) {
+	var order []string
+	record := func(name string) Stage {
+		return funcStage{name: name, fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+			order = append(order, name)
+			return next(ctx, req)
+		}}
+	}
+
+	p := New(echoTerminal, WithStages(record("a"), record("b"), record("c")))
+	resp, err := p.Run(context.Background(), &Request{Text: "hi"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.Sentences[0].Trans != "HI" {
+		t.Fatalf("Run() trans = %q", resp.Sentences[0].Trans)
+	}
+	if got := strings.Join(order, ","); got != "a,b,c" {
+		t.Fatalf("阶段执行顺序 = %s", got)
+	}
+	if got := strings.Join(p.Stages(), ","); got != "a,b,c" {
+		t.Fatalf("Stages() = %s", got)
+	}
+}
+
+// TestPipelineShortCircuit 测试阶段可以不调用下游直接返回，参数: 测试实例，返回: 无
+func TestPipelineShortCircuit(t *testing.T) {
+	hit := funcStage{name: "cache", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		return &translation.Response{Src: "cached"}, nil
+	}}
+	terminal := func(ctx context.Context, req *Request) (*translation.Response, error) {
+		t.Fatal("短路后不应调用末端处理器")
+		return nil, nil
+	}
+
+	resp, err := New(terminal, WithStages(hit)).Run(context.Background(), &Request{Text: "hi"})
+	if err != nil || resp.Src != "cached" {
+		t.Fatalf("Run() = %v, %v", resp, err)
+	}
+}
+
+// TestPipelineStageTimeout 测试单阶段超时只作用于该阶段及其下游，参数: 测试实例，返回: 无
+func TestPipelineStageTimeout(t *testing.T) {
+	slow := func(ctx context.Context, req *Request) (*translation.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	pass := funcStage{name: "slow_stage", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		return next(ctx, req)
+	}}
+
+	p := New(slow, WithStages(pass), WithStageTimeout("slow_stage", 20*time.Millisecond))
+	start := time.Now()
+	_, err := p.Run(context.Background(), &Request{Text: "hi"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("阶段超时未生效，耗时 %v", elapsed)
+	}
+}
+
+// TestPipelineTimeoutFunc 测试按请求计算的超时取代固定超时，返回 0 时仍使用固定超时，参数: 测试实例，返回: 无
+func TestPipelineTimeoutFunc(t *testing.T) {
+	remaining := func(ctx context.Context, req *Request) (*translation.Response, error) {
+		deadline, _ := ctx.Deadline()
+		return &translation.Response{Src: time.Until(deadline).Round(time.Second).String()}, nil
+	}
+	p := New(remaining, WithTimeout(time.Minute), WithTimeoutFunc(func(req *Request) time.Duration {
+		return time.Duration(len(req.Text)) * time.Second
+	}))
+
+	for text, want := range map[string]string{"abc": "3s", "": "1m0s"} {
+		resp, err := p.Run(context.Background(), &Request{Text: text})
+		if err != nil || resp.Src != want {
+			t.Errorf("Run(%q) 超时 = %v, %v, want %s", text, resp, err, want)
+		}
+	}
+}
+
+// TestPipelineGoCancelsSiblings 测试请求级并发任务失败时取消其余任务且 Run 等待全部结束，参数: 测试实例，返回: 无
+func TestPipelineGoCancelsSiblings(t *testing.T) {
+	var finished atomic.Bool
+	boom := errors.New("boom")
+	fanOut := funcStage{name: "fan_out", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		Go(ctx, func() error {
+			<-ctx.Done()
+			finished.Store(true)
+			return nil
+		})
+		Go(ctx, func() error { return boom })
+		return next(ctx, req)
+	}}
+
+	_, err := New(echoTerminal, WithStages(fanOut)).Run(context.Background(), &Request{Text: "hi"})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run() error = %v, want boom", err)
+	}
+	if !finished.Load() {
+		t.Fatal("Run() 返回前应等待所有并发任务结束")
+	}
+}
+
+// TestDetachDrainsOnShutdown 测试后台任务脱离请求取消并在停机时被排空，参数: 测试实例，返回: 无
+func TestDetachDrainsOnShutdown(t *testing.T) {
+	background := NewBackground(nil)
+	release := make(chan struct{})
+	var wrote atomic.Bool
+
+	writeBehind := funcStage{name: "write_behind", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		resp, err := next(ctx, req)
+		Detach(ctx, "write", func(ctx context.Context) error {
+			<-release
+			if ctx.Err() == nil {
+				wrote.Store(true)
+			}
+			return nil
+		})
+		return resp, err
+	}}
+
+	p := New(echoTerminal, WithStages(writeBehind), WithBackground(background))
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := p.Run(ctx, &Request{Text: "hi"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	cancel() // 请求结束不应影响后台任务
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- background.Shutdown(context.Background()) }()
+	close(release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !wrote.Load() {
+		t.Fatal("后台任务未在停机前完成")
+	}
+
+	background.Go("late", func(ctx context.Context) error {
+		t.Error("停机后提交的任务不应执行")
+		return nil
+	})
+}
+
+// TestBackgroundShutdownTimeout 测试排空超时后取消剩余任务，参数: 测试实例，返回: 无
+func TestBackgroundShutdownTimeout(t *testing.T) {
+	background := NewBackground(nil)
+	cancelled := make(chan struct{})
+	background.Go("stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := background.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want DeadlineExceeded", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("排空超时后剩余任务应被取消")
+	}
+}
+
+// TestPipelineTracing 测试每个阶段与提供商调用都产生挂在 pipeline.run 之下的 span，参数: 测试实例，返回: 无
+func TestPipelineTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	pass := funcStage{name: "cache", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		return next(ctx, req)
+	}}
+	failing := New(func(ctx context.Context, req *Request) (*translation.Response, error) {
+		return nil, errors.New("upstream down")
+	}, WithStages(pass))
+	if _, err := failing.Run(context.Background(), &Request{Text: "hi"}); err == nil {
+		t.Fatal("期望返回提供商错误")
+	}
+
+	spans := recorder.Ended()
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+	run, stage, upstream := byName["pipeline.run"], byName["pipeline.stage cache"], byName["pipeline.provider"]
+	if run == nil || stage == nil || upstream == nil {
+		t.Fatalf("缺少 span，实际: %d 个", len(spans))
+	}
+	if stage.Parent().SpanID() != run.SpanContext().SpanID() || upstream.Parent().SpanID() != stage.SpanContext().SpanID() {
+		t.Error("span 父子关系应为 run → stage → provider")
+	}
+	if upstream.Status().Code != codes.Error || run.Status().Code != codes.Error {
+		t.Error("提供商错误应标记在 span 状态上")
+	}
+}
+
+// TestShutdownWaitsInFlightRuns 测试停机等待进行中的请求，且其提交的后台任务不被丢弃，参数: 测试实例，返回: 无
+func TestShutdownWaitsInFlightRuns(t *testing.T) {
+	background := NewBackground(nil)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wrote atomic.Bool
+
+	slow := funcStage{name: "slow", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		close(started)
+		<-release
+		resp, err := next(ctx, req)
+		Detach(ctx, "write", func(ctx context.Context) error {
+			wrote.Store(true)
+			return nil
+		})
+		return resp, err
+	}}
+
+	p := New(echoTerminal, WithStages(slow), WithBackground(background))
+	runErr := make(chan error, 1)
+	go func() {
+		_, err := p.Run(context.Background(), &Request{Text: "hi"})
+		runErr <- err
+	}()
+	<-started
+	if n := background.InFlight(); n != 1 {
+		t.Fatalf("InFlight() = %d, want 1", n)
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- background.Shutdown(context.Background()) }()
+	select {
+	case <-shutdownErr:
+		t.Fatal("Shutdown() 应等待进行中的请求")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !wrote.Load() {
+		t.Fatal("进行中请求提交的后台任务应在停机前完成")
+	}
+}
+
+// TestRunRejectedAfterShutdown 测试停机开始后新的请求返回 ErrShuttingDown，且不阻塞停机，参数: 测试实例，返回: 无
+func TestRunRejectedAfterShutdown(t *testing.T) {
+	background := NewBackground(nil)
+	p := New(echoTerminal, WithBackground(background))
+	if err := background.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := p.Run(context.Background(), &Request{Text: "hi"}); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("Run() error = %v, want ErrShuttingDown", err)
+	}
+	if n := background.InFlight(); n != 0 {
+		t.Errorf("InFlight() = %d, want 0", n)
+	}
+}
+
+// TestRunConcurrentWithShutdown 测试请求与停机并发时不会误用 WaitGroup，参数: 测试实例，返回: 无
+func TestRunConcurrentWithShutdown(t *testing.T) {
+	background := NewBackground(nil)
+	p := New(echoTerminal, WithBackground(background))
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Run(context.Background(), &Request{Text: "hi"}); err != nil && !errors.Is(err, ErrShuttingDown) {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	if err := background.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	wg.Wait()
+}
+
+// TestDetachKeepsRequestID 测试后台任务沿用请求 ID，参数: 测试实例，返回: 无
+func TestDetachKeepsRequestID(t *testing.T) {
+	background := NewBackground(nil)
+	got := make(chan string, 1)
+
+	writeBehind := funcStage{name: "write_behind", fn: func(ctx context.Context, req *Request, next Handler) (*translation.Response, error) {
+		Detach(ctx, "write", func(ctx context.Context) error {
+			got <- requestid.FromContext(ctx)
+			return nil
+		})
+		return next(ctx, req)
+	}}
+
+	p := New(echoTerminal, WithStages(writeBehind), WithBackground(background))
+	if _, err := p.Run(requestid.NewContext(context.Background(), "req-42"), &Request{Text: "hi"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := background.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if id := <-got; id != "req-42" {
+		t.Errorf("request id = %q, want req-42", id)
+	}
+}
+
+// fakeService 以函数实现的测试翻译服务
+type fakeService struct {
+	name string
+	fn   func(ctx context.Context, q string) (*translation.Response, error)
+	caps *deeplx.Capabilities // 为 nil 时不限制
+
+	unavailable bool // IsAvailable 返回 false (模拟健康检查判定不健康)
+}
+
+func (f fakeService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	return f.fn(ctx, q)
+}
+
+func (f fakeService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	return f.fn(ctx, q)
+}
+
+func (f fakeService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	return deeplx.TranslateSequential(ctx, f, texts, sl, tl, dt, model)
+}
+
+func (f fakeService) DetectLanguage(ctx context.Context, text string) (*deeplx.Detection, error) {
+	return nil, deeplx.ErrDetectionNotSupported
+}
+
+func (f fakeService) Healthcheck(ctx context.Context) error { return nil }
+
+func (f fakeService) GetName() string   { return f.name }
+func (f fakeService) IsAvailable() bool { return !f.unavailable }
+func (f fakeService) Capabilities() deeplx.Capabilities {
+	if f.caps != nil {
+		return *f.caps
+	}
+	return deeplx.Capabilities{SupportsModel: true, SupportsBatch: true}
+}
+
+// TestFailoverHandler 测试主提供商出错或超时后转到下一个，并记录实际提供译文的提供商，参数: 测试实例，返回: 无
+func TestFailoverHandler(t *testing.T) {
+	failing := fakeService{name: "primary", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return nil, errors.New("upstream down")
+	}}
+	slow := fakeService{name: "slow", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	backup := fakeService{name: "backup", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: "ok"}}}, nil
+	}}
+
+	handler := FailoverHandler([]Provider{
+		{Name: "primary", Service: failing},
+		{Name: "slow", Service: slow},
+		{Name: "backup", Service: backup},
+	}, 20*time.Millisecond)
+	resp, err := New(handler).Run(context.Background(), &Request{Text: "hi"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.Provider != "backup" {
+		t.Errorf("Provider = %q, want backup", resp.Provider)
+	}
+
+	_, err = New(FailoverHandler([]Provider{{Name: "primary", Service: failing}, {Name: "slow", Service: slow}}, 20*time.Millisecond)).
+		Run(context.Background(), &Request{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "primary") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("全部失败时应返回各提供商的错误，got %v", err)
+	}
+}
+
+// TestFailoverPrefersAvailable 测试不可用的提供商排到最后，其余提供商都失败时仍会尝试，参数: 测试实例，返回: 无
+func TestFailoverPrefersAvailable(t *testing.T) {
+	var calls []string
+	service := func(name string, unavailable bool, err error) fakeService {
+		return fakeService{name: name, unavailable: unavailable, fn: func(ctx context.Context, q string) (*translation.Response, error) {
+			calls = append(calls, name)
+			if err != nil {
+				return nil, err
+			}
+			return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: "ok"}}}, nil
+		}}
+	}
+
+	resp, err := New(FailoverHandler([]Provider{
+		{Name: "primary", Service: service("primary", true, nil)},
+		{Name: "backup", Service: service("backup", false, nil)},
+	}, 0)).Run(context.Background(), &Request{Text: "hi"})
+	if err != nil || resp.Provider != "backup" || !slices.Equal(calls, []string{"backup"}) {
+		t.Fatalf("应跳过不可用的主提供商, resp = %+v, err = %v, calls = %v", resp, err, calls)
+	}
+
+	calls = nil
+	resp, err = New(FailoverHandler([]Provider{
+		{Name: "primary", Service: service("primary", true, nil)},
+		{Name: "backup", Service: service("backup", false, errors.New("upstream down"))},
+	}, 0)).Run(context.Background(), &Request{Text: "hi"})
+	if err != nil || resp.Provider != "primary" || !slices.Equal(calls, []string{"backup", "primary"}) {
+		t.Errorf("其余提供商失败时应尝试不可用的提供商, resp = %+v, err = %v, calls = %v", resp, err, calls)
+	}
+}
+
+// TestRouteHandler 测试按请求指定的提供商路由，参数: 测试实例，返回: 无
+func TestRouteHandler(t *testing.T) {
+	other := fakeService{name: "other", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: "from other"}}}, nil
+	}}
+	handler := RouteHandler(echoTerminal, []Provider{{Name: "other", Service: other}})
+
+	resp, err := handler(context.Background(), &Request{Text: "hi", Provider: "other"})
+	if err != nil || resp.Sentences[0].Trans != "from other" || resp.Provider != "other" {
+		t.Errorf("指定提供商 = %+v, %v", resp, err)
+	}
+	for _, provider := range []string{"", "unknown"} {
+		resp, err = handler(context.Background(), &Request{Text: "hi", Provider: provider})
+		if err != nil || resp.Sentences[0].Trans != "HI" {
+			t.Errorf("provider %q 应使用默认处理器，got %+v, %v", provider, resp, err)
+		}
+	}
+}
+
+// TestFailoverStopsOnRequestDeadline 测试请求整体超时后不再尝试后续提供商，参数: 测试实例，返回: 无
+func TestFailoverStopsOnRequestDeadline(t *testing.T) {
+	var backupCalled atomic.Bool
+	slow := fakeService{name: "slow", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	backup := fakeService{name: "backup", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		backupCalled.Store(true)
+		return &translation.Response{}, nil
+	}}
+
+	p := New(FailoverHandler([]Provider{{Name: "slow", Service: slow}, {Name: "backup", Service: backup}}, 0),
+		WithTimeout(20*time.Millisecond))
+	if _, err := p.Run(context.Background(), &Request{Text: "hi"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want DeadlineExceeded", err)
+	}
+	if backupCalled.Load() {
+		t.Error("请求超时后不应继续尝试下一个提供商")
+	}
+}
+
+// TestHedgeHandler 测试主提供商过慢时对冲到备用提供商，并取消落后的调用，参数: 测试实例，返回: 无
+func TestHedgeHandler(t *testing.T) {
+	canceled := make(chan struct{})
+	slow := fakeService{name: "slow", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}}
+	backup := fakeService{name: "backup", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: "ok"}}}, nil
+	}}
+
+	resp, err := New(HedgeHandler(Provider{Name: "slow", Service: slow}, Provider{Name: "backup", Service: backup}, 10*time.Millisecond)).
+		Run(context.Background(), &Request{Text: "hi"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.Provider != "backup" {
+		t.Errorf("Provider = %q, want backup", resp.Provider)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("对冲成功后应取消主提供商的调用")
+	}
+}
+
+// TestHedgeHandlerPrimaryFirst 测试主提供商及时返回时不发起对冲，出错时立即改用备用提供商，参数: 测试实例，返回: 无
+func TestHedgeHandlerPrimaryFirst(t *testing.T) {
+	var backupCalls atomic.Int32
+	fast := fakeService{name: "fast", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{}, nil
+	}}
+	failing := fakeService{name: "failing", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return nil, errors.New("upstream down")
+	}}
+	backup := fakeService{name: "backup", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		backupCalls.Add(1)
+		return nil, errors.New("backup down")
+	}}
+
+	resp, err := New(HedgeHandler(Provider{Name: "fast", Service: fast}, Provider{Name: "backup", Service: backup}, time.Second)).
+		Run(context.Background(), &Request{Text: "hi"})
+	if err != nil || resp.Provider != "fast" {
+		t.Fatalf("Run() = %+v, %v, want provider fast", resp, err)
+	}
+	if backupCalls.Load() != 0 {
+		t.Error("主提供商在延迟内返回时不应请求备用提供商")
+	}
+
+	_, err = New(HedgeHandler(Provider{Name: "failing", Service: failing}, Provider{Name: "backup", Service: backup}, time.Second)).
+		Run(context.Background(), &Request{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "failing") || !strings.Contains(err.Error(), "backup") {
+		t.Errorf("两者都失败时应返回各自的错误，got %v", err)
+	}
+	if backupCalls.Load() != 1 {
+		t.Errorf("backup calls = %d, want 1", backupCalls.Load())
+	}
+}
+
+// TestProviderDefaultModel 测试请求使用默认模型时故障转移与对冲的备用提供商改用各自的默认模型，请求指定的模型原样传递，参数: 测试实例，返回: 无
+func TestProviderDefaultModel(t *testing.T) {
+	failing := fakeService{name: "primary", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return nil, errors.New("upstream down")
+	}}
+	backup := fakeService{name: "backup", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: "ok"}}}, nil
+	}}
+	primary := Provider{Name: "primary", Service: failing, Model: "primary-model"}
+	handlers := map[string]Handler{
+		"failover":    FailoverHandler([]Provider{primary, {Name: "backup", Service: backup, Model: "backup-model"}}, 0),
+		"hedging":     HedgeHandler(primary, Provider{Name: "backup", Service: backup, Model: "backup-model"}, time.Second),
+		"备用提供商没有默认模型": FailoverHandler([]Provider{primary, {Name: "backup", Service: backup}}, 0),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			want := "backup-model"
+			if name == "备用提供商没有默认模型" {
+				want = ""
+			}
+			req := &Request{Text: "hi", Model: "primary-model", DefaultModel: true}
+			resp, err := New(handler).Run(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if resp.Model != want {
+				t.Errorf("默认模型: Model = %q, want %q", resp.Model, want)
+			}
+			if req.Model != "primary-model" {
+				t.Errorf("不应修改原请求, Model = %q", req.Model)
+			}
+
+			resp, err = New(handler).Run(context.Background(), &Request{Text: "hi", Model: "custom"})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if resp.Model != "custom" {
+				t.Errorf("请求指定的模型: Model = %q, want custom", resp.Model)
+			}
+		})
+	}
+}
+
+// fakeBatchService 记录批量调用的测试翻译服务
+type fakeBatchService struct {
+	fakeService
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (f *fakeBatchService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, texts)
+	f.mu.Unlock()
+	responses := make([]*translation.Response, len(texts))
+	for i, text := range texts {
+		responses[i] = &translation.Response{Sentences: []translation.Sentence{{Orig: text, Trans: tl + ":" + text}}}
+	}
+	return responses, nil
+}
+
+// TestBatchHandler 测试同类并发请求合并为一次批量调用，不同目标语言分开，参数: 测试实例，返回: 无
+func TestBatchHandler(t *testing.T) {
+	service := &fakeBatchService{}
+	p := New(BatchHandler(service, 3, 50*time.Millisecond))
+
+	requests := []*Request{
+		{Text: "a", Target: "zh"},
+		{Text: "b", Target: "zh"},
+		{Text: "c", Target: "zh"},
+		{Text: "d", Target: "ja"},
+	}
+	results := make([]string, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := p.Run(context.Background(), req)
+			if err != nil {
+				t.Errorf("Run(%q) error = %v", req.Text, err)
+				return
+			}
+			results[i] = resp.Sentences[0].Trans
+		}()
+	}
+	wg.Wait()
+
+	if strings.Join(results, ",") != "zh:a,zh:b,zh:c,ja:d" {
+		t.Errorf("results = %v", results)
+	}
+	if len(service.batches) != 2 {
+		t.Fatalf("batches = %v, want 2 upstream calls", service.batches)
+	}
+	for _, batch := range service.batches {
+		if len(batch) != 3 && !(len(batch) == 1 && batch[0] == "d") {
+			t.Errorf("unexpected batch %v", batch)
+		}
+	}
+}
+
+// TestBatchHandlerWithoutNativeBatch 测试服务未声明原生批量能力时不合并请求，参数: 测试实例，返回: 无
+func TestBatchHandlerWithoutNativeBatch(t *testing.T) {
+	service := &fakeBatchService{fakeService: fakeService{
+		fn: func(ctx context.Context, q string) (*translation.Response, error) {
+			return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: q}}}, nil
+		},
+		caps: &deeplx.Capabilities{},
+	}}
+	p := New(BatchHandler(service, 3, 50*time.Millisecond))
+	if _, err := p.Run(context.Background(), &Request{Text: "a", Target: "zh"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(service.batches) != 0 {
+		t.Errorf("batches = %v, want none", service.batches)
+	}
+}
+
+// TestServiceHandlerFormality 测试语气参数经上下文传给翻译服务，参数: 测试实例，返回: 无
+func TestServiceHandlerFormality(t *testing.T) {
+	service := fakeService{name: "deeplx", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Sentences: []translation.Sentence{{Trans: deeplx.FormalityFromContext(ctx)}}}, nil
+	}}
+	p := New(ServiceHandler(service))
+
+	resp, err := p.Run(context.Background(), &Request{Text: "hi", Target: "de", Formality: deeplx.FormalityMore})
+	if err != nil || resp.Sentences[0].Trans != deeplx.FormalityMore {
+		t.Fatalf("Run() = %+v, %v", resp, err)
+	}
+	resp, _ = p.Run(context.Background(), &Request{Text: "hi", Target: "de"})
+	if resp.Sentences[0].Trans != "" {
+		t.Errorf("formality = %q, want empty", resp.Sentences[0].Trans)
+	}
+}
+
+// TestServiceHandlerCapabilities 测试超出服务能力的请求不调用上游，参数: 测试实例，返回: 无
+func TestServiceHandlerCapabilities(t *testing.T) {
+	calls := 0
+	service := fakeService{
+		name: "deeplx",
+		fn: func(ctx context.Context, q string) (*translation.Response, error) {
+			calls++
+			return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: q}}}, nil
+		},
+		caps: &deeplx.Capabilities{Languages: []string{"en", "zh"}, MaxTextLength: 5},
+	}
+	p := New(ServiceHandler(service))
+
+	tests := []struct {
+		name string
+		req  *Request
+		want error
+	}{
+		{"不支持的目标语言", &Request{Text: "hi", Source: "en", Target: "xx"}, deeplx.ErrUnsupportedLanguage},
+		{"不支持的源语言", &Request{Text: "hi", Source: "xx", Target: "zh"}, deeplx.ErrUnsupportedLanguage},
+		{"文本过长", &Request{Text: "hello world", Target: "zh-CN"}, deeplx.ErrTextTooLong},
+		{"不支持模型", &Request{Text: "hi", Target: "zh", Model: "gpt-4o"}, deeplx.ErrModelNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.Run(context.Background(), tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if calls != 0 {
+		t.Fatalf("calls = %d, want 0", calls)
+	}
+
+	if _, err := p.Run(context.Background(), &Request{Text: "hi", Source: "auto", Target: "zh-CN"}); err != nil || calls != 1 {
+		t.Fatalf("Run() error = %v, calls = %d", err, calls)
+	}
+}
+
+// TestServiceHandlerMetadata 测试响应记录发往上游的模型与上游耗时，参数: 测试实例，返回: 无
+func TestServiceHandlerMetadata(t *testing.T) {
+	service := fakeService{name: "deeplx", fn: func(ctx context.Context, q string) (*translation.Response, error) {
+		time.Sleep(5 * time.Millisecond)
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: q}}}, nil
+	}}
+	resp, err := New(ServiceHandler(service)).Run(context.Background(), &Request{Text: "hi", Target: "zh", Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if resp.Model != "gpt-4o" || resp.UpstreamLatency < 5*time.Millisecond || resp.Cached {
+		t.Fatalf("metadata = %q, %v, %v", resp.Model, resp.UpstreamLatency, resp.Cached)
+	}
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// RouteHandler 按请求指定的提供商路由，参数: 默认处理器 (请求未指定提供商时使用) 与可指定的提供商列表，返回: 处理器
+// 指定的提供商直接调用，不经过默认处理器的故障转移、对冲与批量合并；成功的响应在 Provider 字段记录该提供商
+func RouteHandler(fallback Handler, providers []Provider) Handler {
+	handlers := make(map[string]Handler, len(providers))
+	for _, p := range providers {
+		handlers[p.Name] = ServiceHandler(p.Service)
+	}
+
+	return func(ctx context.Context, req *Request) (*translation.Response, error) {
+		handler, ok := handlers[req.Provider]
+		if req.Provider == "" || !ok {
+			return fallback(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		if err == nil && resp != nil {
+			resp.Provider = req.Provider
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,144 @@
+// Package profanity 译文脏词过滤：按目标语言维护词表，命中时把词语替换为星号或拒绝整个译文
+package profanity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnyLanguage 适用于所有目标语言的词表
+const AnyLanguage = "*"
+
+// ErrRejected 拒绝模式下译文包含脏词
+var ErrRejected = errors.New("译文包含被过滤的词语")
+
+// Filter 按语言编译好的词表，构建后只读，并发安全
+type Filter struct {
+	patterns map[string]*regexp.Regexp
+}
+
+// New 编译词表，参数: 语言 → 词语列表 (语言不区分大小写，* 表示所有语言)，返回: Filter 指针
+func New(lists map[string][]string) *Filter {
+	f := &Filter{patterns: make(map[string]*regexp.Regexp, len(lists))}
+	merged := make(map[string][]string, len(lists))
+	for lang, words := range lists {
+		key := primaryLanguage(lang)
+		merged[key] = append(merged[key], words...)
+	}
+	for lang, words := range merged {
+		if re := compile(words); re != nil {
+			f.patterns[lang] = re
+		}
+	}
+	return f
+}
+
+// LoadFile 从 YAML 文件读取词表 (语言 → 词语列表)，参数: 文件路径，返回: 词表与错误
+func LoadFile(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取词表文件失败: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var lists map[string][]string
+	if err := dec.Decode(&lists); err != nil {
+		return nil, fmt.Errorf("解析词表文件 %s 失败: %w", path, err)
+	}
+	return lists, nil
+}
+
+// primaryLanguage 取语言代码的主语言部分 (zh-CN → zh)，参数: 语言代码，返回: 小写主语言
+func primaryLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// compile 把词语编译为一个不区分大小写的正则 (长词优先，以字母数字开头或结尾的词要求单词边界)，参数: 词语列表，返回: 正则 (无词语时为 nil)
+func compile(words []string) *regexp.Regexp {
+	seen := make(map[string]bool, len(words))
+	keys := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" || seen[strings.ToLower(word)] {
+			continue
+		}
+		seen[strings.ToLower(word)] = true
+		keys = append(keys, word)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	alternatives := make([]string, len(keys))
+	for i, word := range keys {
+		pattern := regexp.QuoteMeta(word)
+		if first, _ := utf8.DecodeRuneInString(word); isWordRune(first) {
+			pattern = `\b` + pattern
+		}
+		if last, _ := utf8.DecodeLastRuneInString(word); isWordRune(last) {
+			pattern += `\b`
+		}
+		alternatives[i] = pattern
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+}
+
+// isWordRune 判断字符是否属于 \b 意义上的单词字符，参数: 字符，返回: 布尔
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// lookup 返回目标语言适用的正则 (通用词表在前)，参数: 目标语言，返回: 正则列表
+func (f *Filter) lookup(lang string) []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	if re, ok := f.patterns[AnyLanguage]; ok {
+		patterns = append(patterns, re)
+	}
+	if key := primaryLanguage(lang); key != AnyLanguage {
+		if re, ok := f.patterns[key]; ok {
+			patterns = append(patterns, re)
+		}
+	}
+	return patterns
+}
+
+// Contains 判断文本是否包含目标语言词表中的词语，参数: 文本与目标语言，返回: 布尔
+func (f *Filter) Contains(text, lang string) bool {
+	for _, re := range f.lookup(lang) {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask 把命中的词语逐字替换为 *，参数: 文本与目标语言，返回: 替换后的文本与命中次数
+func (f *Filter) Mask(text, lang string) (string, int) {
+	hits := 0
+	for _, re := range f.lookup(lang) {
+		text = re.ReplaceAllStringFunc(text, func(word string) string {
+			hits++
+			return strings.Repeat("*", utf8.RuneCountInString(word))
+		})
+	}
+	return text, hits
+}
@@ -0,0 +1,63 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMask 测试按目标语言掩码、大小写不敏感、单词边界与通用词表，参数: 测试实例，返回: 无
+func TestMask(t *testing.T) {
+	f := New(map[string][]string{
+		"en":    {"darn", "heck"},
+		"zh-CN": {"笨蛋"},
+		"*":     {"xxx"},
+	})
+
+	got, hits := f.Mask("Darn it, what the HECK. Darnell xxx", "en-US")
+	if got != "**** it, what the ****. Darnell ***" || hits != 3 {
+		t.Errorf("Mask(en) = %q, %d", got, hits)
+	}
+	got, hits = f.Mask("你这个笨蛋", "zh-TW")
+	if got != "你这个**" || hits != 1 {
+		t.Errorf("Mask(zh) = %q, %d", got, hits)
+	}
+	// 其他语言的词表不生效
+	if got, hits = f.Mask("darn", "fr"); got != "darn" || hits != 0 {
+		t.Errorf("Mask(fr) = %q, %d", got, hits)
+	}
+}
+
+// TestContains 测试命中判断，参数: 测试实例，返回: 无
+func TestContains(t *testing.T) {
+	f := New(map[string][]string{"en": {"heck", " "}})
+	if !f.Contains("oh heck", "en") {
+		t.Error("Contains() = false, want true")
+	}
+	if f.Contains("checking", "en") || f.Contains("oh heck", "de") {
+		t.Error("Contains() = true, want false")
+	}
+	if New(nil).Contains("anything", "en") {
+		t.Error("empty filter should not match")
+	}
+}
+
+// TestLoadFile 测试从 YAML 文件读取词表，参数: 测试实例，返回: 无
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "words.yaml")
+	if err := os.WriteFile(path, []byte("en:\n  - darn\nzh:\n  - 笨蛋\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lists, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(lists["en"]) != 1 || lists["zh"][0] != "笨蛋" {
+		t.Errorf("LoadFile() = %v", lists)
+	}
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("LoadFile(missing) error = nil")
+	}
+}
@@ -0,0 +1,213 @@
+// Package quota 提供按客户端统计的每日字符额度
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store 额度计数存储接口，支持内存、Redis 等实现
+type Store interface {
+	// Add 为指定键累加字符数，返回累加后的总量
+	// ttl 为计数的保留时间，超过后计数自动失效
+	Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+
+	// Get 获取指定键当前的累计字符数，不存在时返回 0
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// counterTTL 计数的保留时间，覆盖按 UTC 日期分桶的当天与跨时区的前后一天
+const counterTTL = 48 * time.Hour
+
+// Usage 某客户端当日的额度使用情况
+type Usage struct {
+	Used  int64 // 已使用字符数
+	Limit int64 // 每日额度，0 表示不限
+}
+
+// Exceeded 判断追加指定字符数后是否超出额度，参数: 待追加字符数，返回: 布尔
+func (u Usage) Exceeded(chars int64) bool {
+	return u.Limit > 0 && u.Used+chars > u.Limit
+}
+
+// Remaining 返回剩余字符数，参数: 无，返回: 剩余额度 (不限额时为 -1)
+func (u Usage) Remaining() int64 {
+	if u.Limit <= 0 {
+		return -1
+	}
+	if u.Used >= u.Limit {
+		return 0
+	}
+	return u.Limit - u.Used
+}
+
+// NearLimit 判断剩余额度是否低于阈值，参数: 百分比阈值，返回: 布尔
+func (u Usage) NearLimit(percent int) bool {
+	if u.Limit <= 0 || percent <= 0 {
+		return false
+	}
+	return u.Remaining()*100 <= u.Limit*int64(percent)
+}
+
+// Tracker 每日字符额度跟踪器
+type Tracker struct {
+	store  Store
+	limit  int64
+	prefix string
+	now    func() time.Time
+}
+
+// Option 跟踪器可选配置函数类型
+type Option func(*Tracker)
+
+// WithPrefix 设置计数键前缀，多个跟踪器共用同一存储时用于区分，参数: 前缀，返回: 配置函数
+func WithPrefix(prefix string) Option {
+	return func(t *Tracker) {
+		t.prefix = prefix
+	}
+}
+
+// NewTracker 创建额度跟踪器，参数: 存储实现、每日额度与可选配置，返回: Tracker 指针
+func NewTracker(store Store, dailyLimit int64, opts ...Option) *Tracker {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	t := &Tracker{
+		store:  store,
+		limit:  dailyLimit,
+		prefix: "quota",
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Consume 记录客户端使用的字符数，参数: 上下文、客户端标识、字符数，返回: 记录后的使用情况与错误
+func (t *Tracker) Consume(ctx context.Context, client string, chars int64) (Usage, error) {
+	return t.ConsumeLimit(ctx, client, chars, t.limit)
+}
+
+// ConsumeLimit 按指定额度记录字符数 (不同客户端额度不同时使用)，参数: 上下文、客户端标识、字符数、每日额度，返回: 使用情况与错误
+func (t *Tracker) ConsumeLimit(ctx context.Context, client string, chars, limit int64) (Usage, error) {
+	reservation, err := t.Reserve(ctx, client, chars, limit)
+	if err != nil {
+		return Usage{Limit: limit}, err
+	}
+	return reservation.Usage, nil
+}
+
+// Reservation 预留的字符额度，请求失败或只用掉一部分时退还，退还计入预留时所在日期的计数
+type Reservation struct {
+	Usage Usage // 预留后的使用情况，Usage.Exceeded(0) 为真表示预留越过了额度
+
+	store Store
+	key   string
+}
+
+// Reserve 先累加再判断的字符额度预留：累加由存储原子完成，并发请求不会在检查与记录之间越过额度，参数: 上下文、客户端标识、字符数、每日额度，返回: 预留与错误
+// 是否超额由调用方根据 Usage 判断，超额拒绝时需调用 Release 退还
+func (t *Tracker) Reserve(ctx context.Context, client string, chars, limit int64) (*Reservation, error) {
+	key := t.key(client)
+	used, err := t.store.Add(ctx, key, chars, counterTTL)
+	if err != nil {
+		return nil, fmt.Errorf("记录额度失败: %w", err)
+	}
+	return &Reservation{Usage: Usage{Used: used, Limit: limit}, store: t.store, key: key}, nil
+}
+
+// Release 退还预留中未使用的字符数，参数: 上下文与字符数，返回: 错误
+func (r *Reservation) Release(ctx context.Context, chars int64) error {
+	if chars <= 0 {
+		return nil
+	}
+	if _, err := r.store.Add(ctx, r.key, -chars, counterTTL); err != nil {
+		return fmt.Errorf("退还额度失败: %w", err)
+	}
+	return nil
+}
+
+// Peek 查询客户端当日使用情况，参数: 上下文与客户端标识，返回: 使用情况与错误
+func (t *Tracker) Peek(ctx context.Context, client string) (Usage, error) {
+	return t.PeekLimit(ctx, client, t.limit)
+}
+
+// PeekLimit 按指定额度查询客户端当日使用情况，参数: 上下文、客户端标识、每日额度，返回: 使用情况与错误
+func (t *Tracker) PeekLimit(ctx context.Context, client string, limit int64) (Usage, error) {
+	used, err := t.store.Get(ctx, t.key(client))
+	if err != nil {
+		return Usage{Limit: limit}, fmt.Errorf("查询额度失败: %w", err)
+	}
+	return Usage{Used: used, Limit: limit}, nil
+}
+
+// ResetIn 返回距当日额度重置 (UTC 零点) 的时长，参数: 无，返回: 时长
+func (t *Tracker) ResetIn() time.Duration {
+	now := t.now().UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(now)
+}
+
+// key 生成按天分桶的计数键，参数: 客户端标识，返回: 计数键
+func (t *Tracker) key(client string) string {
+	return fmt.Sprintf("%s:%s:%s", t.prefix, t.now().UTC().Format("2006-01-02"), client)
+}
+
+// MemoryStore 进程内额度计数存储
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建内存计数存储，参数: 无，返回: MemoryStore 指针
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Add 累加计数，参数: 上下文、键、增量、保留时间，返回: 累加后的总量与错误
+func (m *MemoryStore) Add(_ context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.pruneLocked(now)
+
+	entry := m.entries[key]
+	entry.value += n
+	entry.expiresAt = now.Add(ttl)
+	m.entries[key] = entry
+	return entry.value, nil
+}
+
+// Get 获取计数，参数: 上下文与键，返回: 当前总量与错误
+func (m *MemoryStore) Get(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok || m.now().After(entry.expiresAt) {
+		return 0, nil
+	}
+	return entry.value, nil
+}
+
+// pruneLocked 清理过期计数 (调用方需持有锁)，参数: 当前时间，返回: 无
+func (m *MemoryStore) pruneLocked(now time.Time) {
+	for k, e := range m.entries {
+		if now.After(e.expiresAt) {
+			delete(m.entries, k)
+		}
+	}
+}
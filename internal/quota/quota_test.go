@@ -0,0 +1,143 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestUsageNearLimit 测试剩余额度阈值判断，参数: 测试实例，返回: 无
+func TestUsageNearLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		usage   Usage
+		percent int
+		want    bool
+	}{
+		{name: "远未到达", usage: Usage{Used: 100, Limit: 1000}, percent: 10, want: false},
+		{name: "恰好到阈值", usage: Usage{Used: 900, Limit: 1000}, percent: 10, want: true},
+		{name: "已超出", usage: Usage{Used: 1200, Limit: 1000}, percent: 10, want: true},
+		{name: "不限额", usage: Usage{Used: 1200}, percent: 10, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.NearLimit(tt.percent); got != tt.want {
+				t.Errorf("NearLimit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTrackerConsume 测试额度累计与按天分桶，参数: 测试实例，返回: 无
+func TestTrackerConsume(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 1000)
+	day := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return day }
+
+	ctx := context.Background()
+	if _, err := tracker.Consume(ctx, "1.2.3.4", 400); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	usage, err := tracker.Consume(ctx, "1.2.3.4", 300)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if usage.Used != 700 || usage.Remaining() != 300 {
+		t.Fatalf("usage = %+v, 期望已用 700 剩余 300", usage)
+	}
+
+	other, _ := tracker.Peek(ctx, "5.6.7.8")
+	if other.Used != 0 {
+		t.Fatalf("不同客户端计数应隔离, got %+v", other)
+	}
+
+	tracker.now = func() time.Time { return day.Add(24 * time.Hour) }
+	next, _ := tracker.Peek(ctx, "1.2.3.4")
+	if next.Used != 0 {
+		t.Fatalf("次日计数应重置, got %+v", next)
+	}
+}
+
+// TestTrackerPrefixAndLimit 测试共享存储时的键前缀隔离与按调用指定额度，参数: 测试实例，返回: 无
+func TestTrackerPrefixAndLimit(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ipTracker := NewTracker(store, 1000)
+	keyTracker := NewTracker(store, 0, WithPrefix("keyquota"))
+
+	if _, err := ipTracker.Consume(ctx, "ide", 500); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	usage, err := keyTracker.ConsumeLimit(ctx, "ide", 40, 50)
+	if err != nil {
+		t.Fatalf("ConsumeLimit() error = %v", err)
+	}
+	if usage.Used != 40 || usage.Limit != 50 {
+		t.Fatalf("不同前缀的计数应隔离, got %+v", usage)
+	}
+	if !usage.Exceeded(11) || usage.Exceeded(10) {
+		t.Errorf("Exceeded() 边界判断错误: %+v", usage)
+	}
+	if (Usage{Used: 100}).Exceeded(1) {
+		t.Error("不限额时不应超额")
+	}
+}
+
+// TestTrackerReserve 测试并发预留不会越过额度，超额的预留退还后计数回到额度以内，参数: 测试实例，返回: 无
+func TestTrackerReserve(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 1000)
+	ctx := context.Background()
+
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int64
+	)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := tracker.Reserve(ctx, "ide", 100, 1000)
+			if err != nil {
+				t.Errorf("Reserve() error = %v", err)
+				return
+			}
+			if reservation.Usage.Exceeded(0) {
+				if err := reservation.Release(ctx, 100); err != nil {
+					t.Errorf("Release() error = %v", err)
+				}
+				return
+			}
+			accepted.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	usage, _ := tracker.Peek(ctx, "ide")
+	if accepted.Load() != 10 || usage.Used != 1000 {
+		t.Errorf("accepted = %d, used = %d, want 10 与 1000", accepted.Load(), usage.Used)
+	}
+
+	// 只用掉一部分时退还剩余部分
+	reservation, err := tracker.Reserve(ctx, "partial", 300, 1000)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := reservation.Release(ctx, 120); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if usage, _ := tracker.Peek(ctx, "partial"); usage.Used != 180 {
+		t.Errorf("used = %d, want 180", usage.Used)
+	}
+}
+
+// TestTrackerResetIn 测试额度重置时间按 UTC 零点计算，参数: 测试实例，返回: 无
+func TestTrackerResetIn(t *testing.T) {
+	tracker := NewTracker(NewMemoryStore(), 1000)
+	tracker.now = func() time.Time { return time.Date(2025, 1, 1, 20, 30, 0, 0, time.FixedZone("CST", 8*3600)) }
+	if got, want := tracker.ResetIn(), 11*time.Hour+30*time.Minute; got != want {
+		t.Errorf("ResetIn() = %v, want %v", got, want)
+	}
+}
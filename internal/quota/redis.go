@@ -0,0 +1,39 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 基于 Redis 的额度计数存储，计数在多实例间共享且重启后保留
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建 Redis 计数存储，参数: Redis 客户端，返回: RedisStore 指针
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Add 累加计数并刷新过期时间，参数: 上下文、键、增量、保留时间，返回: 累加后的总量与错误
+func (r *RedisStore) Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, n)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Get 获取计数，参数: 上下文与键，返回: 当前总量与错误
+func (r *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	n, err := r.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
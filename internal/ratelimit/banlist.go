@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Ban 一条封禁记录
+type Ban struct {
+	Key   string    `json:"key"`
+	Until time.Time `json:"until"`
+}
+
+// BanList 按键 (通常是客户端 IP) 统计违规次数，窗口内达到阈值后在冷却期内封禁，并发安全
+type BanList struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*banEntry
+	calls   int
+}
+
+type banEntry struct {
+	strikes     int
+	windowStart time.Time
+	bannedUntil time.Time
+}
+
+// NewBanList 创建封禁列表，参数: 触发封禁的违规次数、统计窗口与封禁时长，返回: BanList 指针
+func NewBanList(threshold int, window, cooldown time.Duration) *BanList {
+	return &BanList{
+		threshold: max(threshold, 1),
+		window:    window,
+		cooldown:  cooldown,
+		now:       time.Now,
+		entries:   make(map[string]*banEntry),
+	}
+}
+
+// Banned 判断键是否处于封禁期，参数: 键，返回: 是否封禁与剩余时间
+func (b *BanList) Banned(key string) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[key]
+	if !ok {
+		return false, 0
+	}
+	remaining := entry.bannedUntil.Sub(b.now())
+	if remaining <= 0 {
+		return false, 0
+	}
+	return true, remaining
+}
+
+// Strike 记录一次违规，参数: 键，返回: 本次是否触发封禁
+// 封禁期内的违规不再计数，窗口过期后重新计数
+func (b *BanList) Strike(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.calls++
+	if b.calls%cleanupEveryNCalls == 0 {
+		b.cleanupLocked(now)
+	}
+
+	entry, ok := b.entries[key]
+	if !ok {
+		entry = &banEntry{}
+		b.entries[key] = entry
+	}
+	if now.Before(entry.bannedUntil) {
+		return false
+	}
+	if entry.strikes == 0 || now.Sub(entry.windowStart) > b.window {
+		entry.strikes = 0
+		entry.windowStart = now
+	}
+	entry.strikes++
+	if entry.strikes < b.threshold {
+		return false
+	}
+	entry.strikes = 0
+	entry.bannedUntil = now.Add(b.cooldown)
+	return true
+}
+
+// List 返回当前生效的封禁，参数: 无，返回: 按解封时间排序的封禁列表
+func (b *BanList) List() []Ban {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	bans := make([]Ban, 0)
+	for key, entry := range b.entries {
+		if now.Before(entry.bannedUntil) {
+			bans = append(bans, Ban{Key: key, Until: entry.bannedUntil})
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	return bans
+}
+
+// Unban 解除键的封禁并清空其违规计数，参数: 键，返回: 解除前是否处于封禁期
+func (b *BanList) Unban(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[key]
+	if !ok {
+		return false
+	}
+	delete(b.entries, key)
+	return b.now().Before(entry.bannedUntil)
+}
+
+// Clear 解除全部封禁并清空违规计数，参数: 无，返回: 解除的封禁数量
+func (b *BanList) Clear() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	cleared := 0
+	for _, entry := range b.entries {
+		if now.Before(entry.bannedUntil) {
+			cleared++
+		}
+	}
+	b.entries = make(map[string]*banEntry)
+	return cleared
+}
+
+// cleanupLocked 回收封禁已过期且计数窗口已结束的记录 (调用方需持有锁)，参数: 当前时间，返回: 无
+func (b *BanList) cleanupLocked(now time.Time) {
+	for key, entry := range b.entries {
+		if !now.Before(entry.bannedUntil) && now.Sub(entry.windowStart) > b.window {
+			delete(b.entries, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package ratelimit 提供按客户端划分的令牌桶限流
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// 空闲桶清理参数
+const (
+	defaultIdleTTL     = 10 * time.Minute // 超过该时间未访问的客户端桶会被回收
+	cleanupEveryNCalls = 1024             // 每隔多少次调用顺带清理一次
+)
+
+// Limiter 按键 (通常是客户端 IP) 维护独立令牌桶的限流器，并发安全
+type Limiter struct {
+	limit   rate.Limit
+	burst   int
+	idleTTL time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// New 创建限流器，参数: 每秒请求数与突发容量，返回: Limiter 指针
+func New(rps float64, burst int) *Limiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rps)))
+	}
+	return &Limiter{
+		limit:   rate.Limit(rps),
+		burst:   burst,
+		idleTTL: defaultIdleTTL,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow 判断请求是否放行，参数: 限流键，返回: 是否放行与建议的重试等待时间
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	now := l.now()
+	l.calls++
+	if l.calls%cleanupEveryNCalls == 0 {
+		l.cleanupLocked(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+	// 不排队等待，归还令牌并告知客户端何时重试
+	reservation.CancelAt(now)
+	return false, delay
+}
+
+// Size 返回当前跟踪的键数量，参数: 无，返回: 数量
+func (l *Limiter) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// cleanupLocked 回收空闲桶 (调用方需持有锁)，参数: 当前时间，返回: 无
+func (l *Limiter) cleanupLocked(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLimiterAllow 测试突发容量与令牌补充，参数: 测试实例，返回: 无
+func TestLimiterAllow(t *testing.T) {
+	l := New(1, 2)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("1.2.3.4"); !ok {
+			t.Fatalf("第 %d 次请求应在突发容量内放行", i+1)
+		}
+	}
+
+	ok, retry := l.Allow("1.2.3.4")
+	if ok {
+		t.Fatal("超出突发容量应被限流")
+	}
+	if retry <= 0 || retry > time.Second {
+		t.Errorf("retry = %v, 期望 (0, 1s]", retry)
+	}
+
+	if ok, _ := l.Allow("5.6.7.8"); !ok {
+		t.Error("不同客户端应使用独立令牌桶")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("1.2.3.4"); !ok {
+		t.Error("等待补充后应放行")
+	}
+}
+
+// TestLimiterCleanup 测试空闲桶回收，参数: 测试实例，返回: 无
+func TestLimiterCleanup(t *testing.T) {
+	l := New(10, 10)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Allow("idle")
+	now = now.Add(time.Hour)
+	for i := 0; i < cleanupEveryNCalls; i++ {
+		l.Allow("active")
+	}
+
+	if l.Size() != 1 {
+		t.Errorf("Size() = %d, 期望空闲客户端被回收", l.Size())
+	}
+}
+
+// TestBanListStrike 测试窗口内违规达到阈值后封禁、冷却期后解封，参数: 测试实例，返回: 无
+func TestBanListStrike(t *testing.T) {
+	b := NewBanList(3, time.Minute, 10*time.Minute)
+	now := time.Unix(1000, 0)
+	b.now = func() time.Time { return now }
+
+	b.Strike("1.2.3.4")
+	b.Strike("1.2.3.4")
+	now = now.Add(2 * time.Minute)
+	if b.Strike("1.2.3.4") {
+		t.Fatal("窗口过期后应重新计数")
+	}
+	b.Strike("1.2.3.4")
+	if !b.Strike("1.2.3.4") {
+		t.Fatal("窗口内达到阈值应触发封禁")
+	}
+	if banned, remaining := b.Banned("1.2.3.4"); !banned || remaining != 10*time.Minute {
+		t.Fatalf("Banned() = %v, %v", banned, remaining)
+	}
+	if banned, _ := b.Banned("5.6.7.8"); banned {
+		t.Error("其他客户端不应被封禁")
+	}
+	if bans := b.List(); len(bans) != 1 || bans[0].Key != "1.2.3.4" {
+		t.Errorf("List() = %+v", bans)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if banned, _ := b.Banned("1.2.3.4"); banned {
+		t.Error("冷却期后应解除封禁")
+	}
+}
+
+// TestBanListUnban 测试手动解除封禁，参数: 测试实例，返回: 无
+func TestBanListUnban(t *testing.T) {
+	b := NewBanList(1, time.Minute, time.Hour)
+	b.Strike("a")
+	b.Strike("b")
+
+	if !b.Unban("a") {
+		t.Error("Unban(a) 应返回 true")
+	}
+	if banned, _ := b.Banned("a"); banned {
+		t.Error("Unban 后不应封禁")
+	}
+	if n := b.Clear(); n != 1 {
+		t.Errorf("Clear() = %d, want 1", n)
+	}
+	if len(b.List()) != 0 {
+		t.Error("Clear 后封禁列表应为空")
+	}
+}
@@ -0,0 +1,24 @@
+// Package requestid 在请求上下文中传递请求 ID，供上游调用与日志关联同一次请求
+package requestid
+
+import "context"
+
+// Header 传递请求 ID 的 HTTP 头
+const Header = "X-Request-ID"
+
+// contextKey 请求 ID 的上下文键
+type contextKey struct{}
+
+// NewContext 返回携带请求 ID 的上下文，参数: 上下文与请求 ID，返回: 新上下文
+func NewContext(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 读取上下文中的请求 ID，参数: 上下文，返回: 请求 ID (不存在时为空)
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
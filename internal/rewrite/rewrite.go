@@ -0,0 +1,94 @@
+// Package rewrite 译文后处理规则：按语言对配置有序的正则查找/替换规则，
+// 修正提供商反复出现的小问题 (品牌大小写、多余的引号等) 而无需改代码
+package rewrite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnyLanguage 适用于任意语言的规则
+const AnyLanguage = "*"
+
+// Rule 一条查找/替换规则
+type Rule struct {
+	Source  string // 源语言代码，* 或留空表示任意源语言
+	Target  string // 目标语言代码，* 或留空表示任意目标语言
+	Pattern string // 正则表达式 (Go RE2 语法)
+	Replace string // 替换文本，可用 $1、${name} 引用分组
+}
+
+// compiledRule 编译好的规则
+type compiledRule struct {
+	source  string
+	target  string
+	pattern *regexp.Regexp
+	replace string
+}
+
+// Rewriter 编译好的规则列表，构建后只读，并发安全
+type Rewriter struct {
+	rules []compiledRule
+}
+
+// New 按顺序编译规则，参数: 规则列表，返回: Rewriter 指针与错误 (正则无效时)
+func New(rules []Rule) (*Rewriter, error) {
+	r := &Rewriter{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %d 的正则无效: %w", i, err)
+		}
+		r.rules = append(r.rules, compiledRule{
+			source:  normalizeLang(rule.Source),
+			target:  normalizeLang(rule.Target),
+			pattern: re,
+			replace: rule.Replace,
+		})
+	}
+	return r, nil
+}
+
+// Len 返回规则数量，参数: 无，返回: 数量
+func (r *Rewriter) Len() int {
+	return len(r.rules)
+}
+
+// Apply 依次应用语言对适用的规则，前一条规则的结果作为后一条的输入，参数: 源语言 (空或 auto 时只应用任意源语言的规则)、目标语言与译文，返回: 改写后的译文与生效的规则数
+func (r *Rewriter) Apply(source, target, text string) (string, int) {
+	source, target = normalizeLang(source), normalizeLang(target)
+	if source == "auto" {
+		source = AnyLanguage
+	}
+	applied := 0
+	for _, rule := range r.rules {
+		if !langMatches(rule.source, source) || !langMatches(rule.target, target) {
+			continue
+		}
+		rewritten := rule.pattern.ReplaceAllString(text, rule.replace)
+		if rewritten != text {
+			text = rewritten
+			applied++
+		}
+	}
+	return text, applied
+}
+
+// normalizeLang 规范化语言代码用于比较，留空视为任意语言，参数: 语言代码，返回: 小写代码
+func normalizeLang(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if code == "" {
+		return AnyLanguage
+	}
+	return code
+}
+
+// langMatches 判断规则语言是否适用于请求语言，规则为任意语言、与请求相同或只写主语言 (zh 适用于 zh-CN) 时适用，参数: 规则语言与请求语言，返回: 是否适用
+func langMatches(rule, requested string) bool {
+	if rule == AnyLanguage || rule == requested {
+		return true
+	}
+	primary, _, _ := strings.Cut(requested, "-")
+	return rule == primary
+}
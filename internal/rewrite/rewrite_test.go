@@ -0,0 +1,43 @@
+package rewrite
+
+import "testing"
+
+// TestApply 测试规则顺序、语言匹配与分组引用，参数: 测试实例，返回: 无
+func TestApply(t *testing.T) {
+	r, err := New([]Rule{
+		{Pattern: `(?i)\bgithub\b`, Replace: "GitHub"},
+		{Target: "zh", Pattern: `“([^”]*)”`, Replace: "$1"},
+		{Source: "en", Target: "de", Pattern: `\bDatei\b`, Replace: "Dokument"},
+		{Target: "zh-CN", Pattern: `GitHub 仓库`, Replace: "GitHub 存储库"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		target  string
+		text    string
+		want    string
+		applied int
+	}{
+		{name: "任意语言", source: "en", target: "fr", text: "dépôt github", want: "dépôt GitHub", applied: 1},
+		{name: "主语言匹配与顺序", source: "auto", target: "zh-CN", text: "打开“github 仓库”", want: "打开GitHub 存储库", applied: 3},
+		{name: "其他中文变体", source: "en", target: "zh-TW", text: "“github 仓库”", want: "GitHub 仓库", applied: 2},
+		{name: "源语言限定", source: "en-US", target: "de", text: "Datei öffnen", want: "Dokument öffnen", applied: 1},
+		{name: "自动检测不匹配限定源语言的规则", source: "auto", target: "de", text: "Datei öffnen", want: "Datei öffnen", applied: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, applied := r.Apply(tt.source, tt.target, tt.text)
+			if got != tt.want || applied != tt.applied {
+				t.Errorf("Apply() = %q, %d, want %q, %d", got, applied, tt.want, tt.applied)
+			}
+		})
+	}
+
+	if _, err := New([]Rule{{Pattern: `(`}}); err == nil {
+		t.Error("New(无效正则) error = nil")
+	}
+}
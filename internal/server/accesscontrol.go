@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/metrics"
+)
+
+// accessControl 按客户端 IP 的允许/拒绝网段
+type accessControl struct {
+	allow []netip.Prefix // 为空表示不限制来源
+	deny  []netip.Prefix
+}
+
+// newAccessControl 根据配置构建访问控制，参数: 无（使用接收者），返回: 访问控制 (未启用时为 nil) 与错误
+func (s *Server) newAccessControl() (*accessControl, error) {
+	cfg := s.config.Server.AccessControl
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	allow, err := cfg.GetAllow()
+	if err != nil {
+		return nil, err
+	}
+	deny, err := cfg.GetDeny()
+	if err != nil {
+		return nil, err
+	}
+	return &accessControl{allow: allow, deny: deny}, nil
+}
+
+// check 判断客户端 IP 是否允许访问，参数: 客户端 IP 字符串，返回: 是否允许与拒绝原因 (deny/not_allowed/invalid_ip)
+func (a *accessControl) check(ip string) (bool, string) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, "invalid_ip"
+	}
+	addr = addr.Unmap()
+
+	if containsAddr(a.deny, addr) {
+		return false, "deny"
+	}
+	if len(a.allow) > 0 && !containsAddr(a.allow, addr) {
+		return false, "not_allowed"
+	}
+	return true, ""
+}
+
+// accessControlMiddleware 拒绝不在允许网段或命中拒绝网段的请求，参数: 下一个处理器，返回: 包装后的处理器
+func (s *Server) accessControlMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	acl := s.accessControl
+	return func(c echo.Context) error {
+		ip := c.RealIP()
+		if ok, reason := acl.check(ip); !ok {
+			metrics.AccessDenied.WithLabelValues(reason).Inc()
+			return c.JSON(http.StatusForbidden, NewAPIError(ErrCodeForbidden, "access denied for client address"))
+		}
+		return next(c)
+	}
+}
+
+// containsAddr 判断地址是否落在任一网段内，参数: 网段列表与地址，返回: 布尔
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
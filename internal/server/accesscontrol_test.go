@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestAccessControlCheck 测试拒绝网段优先于允许网段、IPv4 映射地址按 IPv4 匹配，参数: 测试实例，返回: 无
+func TestAccessControlCheck(t *testing.T) {
+	s := &Server{config: &config.Config{Server: config.ServerConfig{AccessControl: config.AccessControlConfig{
+		Enabled: true,
+		Allow:   []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:    []string{"10.0.0.66"},
+	}}}}
+	acl, err := s.newAccessControl()
+	if err != nil {
+		t.Fatalf("newAccessControl() error = %v", err)
+	}
+
+	tests := []struct {
+		ip         string
+		wantOK     bool
+		wantReason string
+	}{
+		{ip: "10.1.2.3", wantOK: true},
+		{ip: "::ffff:10.1.2.3", wantOK: true},
+		{ip: "2001:db8::1", wantOK: true},
+		{ip: "10.0.0.66", wantReason: "deny"},
+		{ip: "::ffff:10.0.0.66", wantReason: "deny"},
+		{ip: "192.0.2.1", wantReason: "not_allowed"},
+		{ip: "not-an-ip", wantReason: "invalid_ip"},
+		{ip: "", wantReason: "invalid_ip"},
+	}
+	for _, tt := range tests {
+		ok, reason := acl.check(tt.ip)
+		if ok != tt.wantOK || reason != tt.wantReason {
+			t.Errorf("check(%q) = %v, %q, want %v, %q", tt.ip, ok, reason, tt.wantOK, tt.wantReason)
+		}
+	}
+
+	// 只配置拒绝网段时其余来源都允许
+	s.config.Server.AccessControl.Allow = nil
+	if acl, err = s.newAccessControl(); err != nil {
+		t.Fatalf("newAccessControl() error = %v", err)
+	}
+	if ok, _ := acl.check("192.0.2.1"); !ok {
+		t.Error("未配置允许网段时应允许其余来源")
+	}
+	if ok, _ := acl.check("10.0.0.66"); ok {
+		t.Error("命中拒绝网段时应拒绝")
+	}
+
+	// 无效网段在启动时报错
+	s.config.Server.AccessControl.Deny = []string{"10.0.0.0/33"}
+	if _, err := s.newAccessControl(); err == nil {
+		t.Error("无效网段应返回错误")
+	}
+}
+
+// TestAccessControlMiddleware 测试被拒绝的来源在路由之前返回 403，客户端地址取自可信代理链路，参数: 测试实例，返回: 无
+func TestAccessControlMiddleware(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{
+		TrustedProxies: []string{"10.0.0.0/8"},
+		AccessControl:  config.AccessControlConfig{Enabled: true, Deny: []string{"198.51.100.0/24"}},
+	}}
+	svc := &stubService{}
+	s := newTestServer(t, cfg, svc)
+
+	tests := []struct {
+		name       string
+		remote     string
+		xff        string
+		wantStatus int
+	}{
+		{name: "允许的来源", remote: "192.0.2.1:5000", wantStatus: http.StatusOK},
+		{name: "拒绝的来源", remote: "198.51.100.7:5000", wantStatus: http.StatusForbidden},
+		{name: "经可信代理的拒绝来源", remote: "10.0.0.2:5000", xff: "198.51.100.7", wantStatus: http.StatusForbidden},
+		{name: "不可信对端伪造转发头", remote: "198.51.100.7:5000", xff: "192.0.2.1", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"Hello","tl":"de"}`)
+			req.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			rec := serve(s, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+			var apiErr APIError
+			if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil || apiErr.Code != ErrCodeForbidden {
+				t.Errorf("body = %s", rec.Body.String())
+			}
+		})
+	}
+	if got := svc.callCount(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1 (被拒绝的请求不调用上游)", got)
+	}
+}
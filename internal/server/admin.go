@@ -0,0 +1,256 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/XgzK/translate-services/internal/auth"
+)
+
+// ErrCodeUnauthorized 未授权访问错误代码
+const ErrCodeUnauthorized = "UNAUTHORIZED"
+
+// ErrCodeTooManyAttempts 登录尝试过多错误代码
+const ErrCodeTooManyAttempts = "TOO_MANY_ATTEMPTS"
+
+// adminSessionCookie 管理会话 Cookie 名称
+const adminSessionCookie = "admin_session"
+
+// 登录失败限制参数：单个 IP 失败 5 次锁定该 IP；所有 IP 合计失败 20 次后每次登录先等待 adminLoginSlowdown
+// (减缓分散 IP 的暴力破解，但不拒绝登录，避免攻击者借此把管理员挡在门外)
+const (
+	adminMaxLoginFailures       = 5
+	adminMaxGlobalLoginFailures = 20
+	adminLoginCooldown          = 5 * time.Minute
+	adminLoginSlowdown          = time.Second
+)
+
+// adminGlobalSlowdownKey 全局登录减速使用的来源标识
+const adminGlobalSlowdownKey = "*"
+
+// adminAuthenticator 管理后台认证状态
+type adminAuthenticator struct {
+	sessions *auth.SessionStore
+	totp     *auth.TOTPVerifier
+	lockout  *auth.Lockout // 按 IP 锁定
+	global   *auth.Lockout // 不分来源的失败计数，"锁定"期间对所有登录减速
+	slowdown time.Duration // 全局减速期间每次登录的等待时间
+}
+
+// adminLoginRequest 登录请求体
+type adminLoginRequest struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+	Code     string `json:"code" form:"code"` // TOTP 验证码
+}
+
+// newAdminAuthenticator 根据配置创建认证状态，参数: 无（使用接收者），返回: 认证状态或错误
+func (s *Server) newAdminAuthenticator() (*adminAuthenticator, error) {
+	a := &adminAuthenticator{
+		sessions: auth.NewSessionStore(s.config.Admin.GetSessionTTL()),
+		lockout:  auth.NewLockout(adminMaxLoginFailures, adminLoginCooldown),
+		global:   auth.NewLockout(adminMaxGlobalLoginFailures, adminLoginCooldown),
+		slowdown: adminLoginSlowdown,
+	}
+	if secret := strings.TrimSpace(s.config.Admin.TOTPSecret); secret != "" {
+		verifier, err := auth.NewTOTPVerifier(secret)
+		if err != nil {
+			return nil, err
+		}
+		a.totp = verifier
+	}
+	return a, nil
+}
+
+// registerAdminRoutes 注册管理接口，参数: 注册路由的 Echo 实例，返回: 无
+func (s *Server) registerAdminRoutes(e *echo.Echo) {
+	if !s.config.Admin.Enabled() {
+		return
+	}
+
+	if s.config.Admin.LoginEnabled() {
+		e.POST("/admin/login", s.adminLoginHandler)
+	}
+
+	admin := e.Group("/admin", s.adminAuth)
+	admin.POST("/logout", s.adminLogoutHandler)
+	admin.GET("/session", s.adminSessionHandler)
+	admin.GET("/stats/languages", s.languageStatsHandler)
+	admin.GET("/usage", s.usageHandler)
+	admin.GET("/logging", s.logLevelsHandler)
+	admin.PUT("/logging", s.updateLogLevelsHandler)
+	admin.GET("/maintenance", s.maintenanceHandler)
+	admin.PUT("/maintenance", s.updateMaintenanceHandler)
+	s.registerBanRoutes(admin)
+	s.registerKeyRoutes(admin)
+	s.registerMemoryRoutes(admin)
+}
+
+// adminAuth 管理接口认证中间件 (会话 Cookie 或静态令牌)，参数: 下一个处理器，返回: 包装后的处理器
+func (s *Server) adminAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := s.adminSession(c); ok {
+			return next(c)
+		}
+		if s.validAdminToken(c) {
+			return next(c)
+		}
+		return c.JSON(http.StatusUnauthorized, NewAPIError(ErrCodeUnauthorized, "admin login required"))
+	}
+}
+
+// adminSession 从请求中读取有效会话，参数: Echo 上下文，返回: 会话与是否有效
+func (s *Server) adminSession(c echo.Context) (auth.Session, bool) {
+	if s.adminAuthn == nil {
+		return auth.Session{}, false
+	}
+	cookie, err := c.Cookie(adminSessionCookie)
+	if err != nil || cookie.Value == "" {
+		return auth.Session{}, false
+	}
+	return s.adminAuthn.sessions.Get(cookie.Value)
+}
+
+// validAdminToken 校验静态令牌，参数: Echo 上下文，返回: 是否有效
+func (s *Server) validAdminToken(c echo.Context) bool {
+	expected := strings.TrimSpace(s.config.Admin.Token)
+	if expected == "" {
+		return false
+	}
+
+	token := c.Request().Header.Get("X-Admin-Token")
+	if header := c.Request().Header.Get(echo.HeaderAuthorization); token == "" && strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// adminLoginHandler 处理管理员登录，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) adminLoginHandler(c echo.Context) error {
+	source := c.RealIP()
+	if locked, remaining := s.adminAuthn.lockout.Locked(source); locked {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(remaining.Seconds())+1))
+		return c.JSON(http.StatusTooManyRequests, NewAPIError(ErrCodeTooManyAttempts, "too many failed login attempts"))
+	}
+	if slowed, _ := s.adminAuthn.global.Locked(adminGlobalSlowdownKey); slowed {
+		timer := time.NewTimer(s.adminAuthn.slowdown)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		}
+	}
+
+	var req adminLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid login payload", err.Error())
+	}
+
+	if !s.checkAdminCredentials(req) {
+		s.adminAuthn.lockout.Fail(source)
+		if s.adminAuthn.global.Fail(adminGlobalSlowdownKey) {
+			s.logger.Error().Dur("cooldown", adminLoginCooldown).Dur("delay", s.adminAuthn.slowdown).Msg("管理员登录失败次数过多，所有来源的登录将被减速")
+		}
+		s.logger.Warn().Str("ip", source).Str("username", req.Username).Msg("管理员登录失败")
+		return c.JSON(http.StatusUnauthorized, NewAPIError(ErrCodeUnauthorized, "invalid credentials"))
+	}
+	s.adminAuthn.lockout.Reset(source)
+
+	session, err := s.adminAuthn.sessions.Create(req.Username)
+	if err != nil {
+		return InternalError(c, "failed to create session")
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    session.Token,
+		Path:     "/admin",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   c.IsTLS(),
+		SameSite: http.SameSiteStrictMode,
+	})
+	s.logger.Info().Str("ip", source).Str("username", req.Username).Msg("管理员登录成功")
+
+	return c.JSON(http.StatusOK, session)
+}
+
+// checkAdminCredentials 校验用户名、密码与 TOTP，参数: 登录请求，返回: 是否通过
+// 已配置的因子全部需要通过：password_hash 校验密码，totp_secret 校验验证码 (配置校验保证 TOTP 不会单独使用)
+func (s *Server) checkAdminCredentials(req adminLoginRequest) bool {
+	cfg := &s.config.Admin
+	if subtle.ConstantTimeCompare([]byte(req.Username), []byte(cfg.GetUsername())) != 1 {
+		return false
+	}
+	if hash := strings.TrimSpace(cfg.PasswordHash); hash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)) != nil {
+			return false
+		}
+	}
+	if s.adminAuthn.totp != nil && !s.adminAuthn.totp.Verify(req.Code) {
+		return false
+	}
+	return true
+}
+
+// adminLogoutHandler 注销当前会话，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) adminLogoutHandler(c echo.Context) error {
+	if cookie, err := c.Cookie(adminSessionCookie); err == nil && s.adminAuthn != nil {
+		s.adminAuthn.sessions.Revoke(cookie.Value)
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    "",
+		Path:     "/admin",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.IsTLS(),
+		SameSite: http.SameSiteStrictMode,
+	})
+	return c.NoContent(http.StatusNoContent)
+}
+
+// adminSessionHandler 返回当前会话信息，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) adminSessionHandler(c echo.Context) error {
+	if session, ok := s.adminSession(c); ok {
+		return c.JSON(http.StatusOK, session)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subject": "token",
+	})
+}
+
+// languageStatsHandler 返回各客户端常用的语言方向，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) languageStatsHandler(c echo.Context) error {
+	if s.preferences == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"enabled": false,
+			"clients": map[string]interface{}{},
+		})
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		limit = 5
+	}
+
+	if client := c.QueryParam("client"); client != "" {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"enabled": true,
+			"client":  client,
+			"pairs":   s.preferences.Top(client, limit),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled": true,
+		"clients": s.preferences.Snapshot(limit),
+	})
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// newLoginServer 构建启用密码登录的服务器，参数: 测试实例，返回: 服务器
+func newLoginServer(t *testing.T) *Server {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	return newTestServer(t, &config.Config{Admin: config.AdminConfig{PasswordHash: string(hash)}}, &stubService{})
+}
+
+// login 以指定客户端地址登录，参数: 服务器、客户端 IP 与密码，返回: 状态码
+func login(s *Server, ip, password string) int {
+	req := jsonRequest(http.MethodPost, "/admin/login", `{"username":"admin","password":"`+password+`"}`)
+	req.RemoteAddr = ip + ":5000"
+	return serve(s, req).Code
+}
+
+// TestAdminLoginLockout 测试单个 IP 失败过多时锁定该 IP，其他 IP 不受影响，参数: 测试实例，返回: 无
+func TestAdminLoginLockout(t *testing.T) {
+	s := newLoginServer(t)
+	for range adminMaxLoginFailures {
+		if code := login(s, "192.0.2.1", "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401", code)
+		}
+	}
+	if code := login(s, "192.0.2.1", "correct"); code != http.StatusTooManyRequests {
+		t.Errorf("锁定后 status = %d, want 429", code)
+	}
+	if code := login(s, "192.0.2.2", "correct"); code != http.StatusOK {
+		t.Errorf("其他 IP status = %d, want 200", code)
+	}
+}
+
+// TestAdminLoginGlobalSlowdown 测试分散在多个 IP 的失败合计达到上限后，所有来源的登录被减速但不被拒绝，参数: 测试实例，返回: 无
+func TestAdminLoginGlobalSlowdown(t *testing.T) {
+	s := newLoginServer(t)
+	s.adminAuthn.slowdown = 50 * time.Millisecond
+	for i := range adminMaxGlobalLoginFailures {
+		if code := login(s, fmt.Sprintf("198.51.100.%d", i+1), "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("第 %d 次 status = %d, want 401", i+1, code)
+		}
+	}
+	start := time.Now()
+	if code := login(s, "203.0.113.1", "correct"); code != http.StatusOK {
+		t.Errorf("全局减速期间 status = %d, want 200", code)
+	}
+	if elapsed := time.Since(start); elapsed < s.adminAuthn.slowdown {
+		t.Errorf("全局减速期间登录耗时 %v, want >= %v", elapsed, s.adminAuthn.slowdown)
+	}
+}
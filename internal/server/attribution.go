@@ -0,0 +1,70 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// attributionText 生成当前署名文本，参数: 实际提供译文的提供商 (为空时使用主提供商)，返回: 署名文本，未启用时为空
+func (s *Server) attributionText(provider string) string {
+	attr := &s.config.Translation.Attribution
+	if !attr.Enabled {
+		return ""
+	}
+	if provider == "" {
+		provider = s.providerName
+	}
+	return attr.Render(provider)
+}
+
+// applyAttribution 为单句翻译响应注入署名，参数: Echo 上下文与翻译响应，返回: 无
+func (s *Server) applyAttribution(c echo.Context, resp *translation.Response) {
+	var provider string
+	if resp != nil {
+		provider = resp.Provider
+	}
+	text := s.attributionText(provider)
+	if text == "" {
+		return
+	}
+
+	c.Response().Header().Set(s.config.Translation.Attribution.GetHeader(), text)
+	if s.config.Translation.Attribution.Field && resp != nil {
+		resp.Attribution = text
+	}
+}
+
+// documentAttribution 为文档翻译设置署名响应头，参数: Echo 上下文与提供商 (为空时使用主提供商)，返回: 追加到 HTML 末尾的署名注释，未启用 HTML 署名时为空
+func (s *Server) documentAttribution(c echo.Context, provider string) string {
+	text := s.attributionText(provider)
+	if text == "" {
+		return ""
+	}
+
+	c.Response().Header().Set(s.config.Translation.Attribution.GetHeader(), text)
+	if !s.config.Translation.Attribution.HTML {
+		return ""
+	}
+	// HTML 注释中不允许出现 "--"，避免破坏注释结构
+	return "<!-- " + strings.ReplaceAll(text, "--", "- -") + " -->"
+}
+
+// applyDocumentAttribution 为文档翻译响应注入署名，参数: Echo 上下文、提供商 (为空时使用主提供商) 与文档响应，返回: 无
+// 署名注释只追加到最后一个片段，即文档末尾
+func (s *Server) applyDocumentAttribution(c echo.Context, provider string, doc [][][]string) {
+	comment := s.documentAttribution(c, provider)
+	if comment == "" {
+		return
+	}
+	for i := len(doc) - 1; i >= 0; i-- {
+		for j := len(doc[i]) - 1; j >= 0; j-- {
+			if len(doc[i][j]) > 0 {
+				doc[i][j][0] += comment
+				return
+			}
+		}
+	}
+}
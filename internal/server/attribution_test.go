@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestApplyDocumentAttribution 测试多片段文档只在最后一个片段末尾追加署名注释，参数: 测试实例，返回: 无
+func TestApplyDocumentAttribution(t *testing.T) {
+	cfg := &config.Config{Translation: config.TranslationConfig{Attribution: config.AttributionConfig{
+		Enabled: true,
+		Text:    "translated by {provider} -- test",
+		HTML:    true,
+	}}}
+	s := newTestServer(t, cfg, &stubService{})
+
+	doc := [][][]string{
+		{{"<p>Hallo</p>", "en"}, {"<p>Welt</p>", "en"}},
+		{{"<p>Ende</p>", "en"}, {}},
+	}
+	rec := httptest.NewRecorder()
+	s.applyDocumentAttribution(s.echo.NewContext(httptest.NewRequest(http.MethodPost, "/translate_a/t", nil), rec), "", doc)
+
+	want := [][][]string{
+		{{"<p>Hallo</p>", "en"}, {"<p>Welt</p>", "en"}},
+		{{"<p>Ende</p><!-- translated by stub - - test -->", "en"}, {}},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("doc = %q, want %q", doc, want)
+	}
+	if got := rec.Header().Get("X-Translated-By"); got != "translated by stub -- test" {
+		t.Errorf("X-Translated-By = %q", got)
+	}
+}
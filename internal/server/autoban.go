@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/ratelimit"
+)
+
+// newBanList 根据配置构建自动封禁列表，参数: 无（使用接收者），返回: 封禁列表 (未启用时为 nil)
+func (s *Server) newBanList() *ratelimit.BanList {
+	cfg := s.config.Server.AutoBan
+	if !cfg.Enabled {
+		return nil
+	}
+	return ratelimit.NewBanList(cfg.GetThreshold(), cfg.GetWindow(), cfg.GetCooldown())
+}
+
+// autoBanMiddleware 拒绝被封禁的客户端，并把其他请求的 4xx 响应 (429 与上游错误除外) 计为违规，参数: 下一个处理器，返回: 包装后的处理器
+// 运维端点与管理接口不参与封禁 (管理登录有独立的失败锁定)，避免管理员被一并拦截后无法解封
+func (s *Server) autoBanMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	bans := s.banList
+	return func(c echo.Context) error {
+		if route := c.Path(); rateLimitExemptPaths[route] || strings.HasPrefix(route, "/admin/") {
+			return next(c)
+		}
+
+		ip := c.RealIP()
+		if banned, remaining := bans.Banned(ip); banned {
+			metrics.AutoBanEvents.WithLabelValues("rejected").Inc()
+			seconds := max(int(math.Ceil(remaining.Seconds())), 1)
+			c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+			return c.JSON(http.StatusForbidden, NewAPIError(ErrCodeForbidden, "client temporarily banned").WithDetails(map[string]interface{}{
+				"retry_after": seconds,
+			}))
+		}
+
+		err := next(c)
+		if isStrike(c, err) && bans.Strike(ip) {
+			metrics.AutoBanEvents.WithLabelValues("banned").Inc()
+			s.logger.Warn().Str("ip", ip).Dur("cooldown", s.config.Server.AutoBan.GetCooldown()).Msg("客户端 4xx 响应过多，已临时封禁")
+		}
+		return err
+	}
+}
+
+// responseStatus 获取请求最终的响应状态码，参数: Echo 上下文与处理器返回的错误，返回: 状态码
+// 处理器返回错误时响应尚未写出，状态码以错误为准
+func responseStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// isStrike 判断响应是否计为违规，参数: Echo 上下文与处理器返回的错误，返回: 布尔
+// 限流 (429) 已单独处理，不重复计数；上游错误映射出的 4xx (上游不支持的语言、超过上游长度上限等) 不是客户端的违规
+func isStrike(c echo.Context, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Upstream {
+		return false
+	}
+	if upstream, _ := c.Get(upstreamErrorContextKey).(bool); upstream {
+		return false
+	}
+	status := responseStatus(c, err)
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError && status != http.StatusTooManyRequests
+}
+
+// registerBanRoutes 注册封禁列表管理接口，参数: 管理路由组，返回: 无
+func (s *Server) registerBanRoutes(admin *echo.Group) {
+	if s.banList == nil {
+		return
+	}
+	admin.GET("/bans", s.listBansHandler)
+	admin.DELETE("/bans", s.clearBansHandler)
+	admin.DELETE("/bans/:ip", s.unbanHandler)
+}
+
+// listBansHandler 列出当前生效的封禁，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) listBansHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{"bans": s.banList.List()})
+}
+
+// clearBansHandler 解除全部封禁，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) clearBansHandler(c echo.Context) error {
+	cleared := s.banList.Clear()
+	s.logger.Info().Int("cleared", cleared).Str("admin_ip", c.RealIP()).Msg("已解除全部自动封禁")
+	return c.JSON(http.StatusOK, map[string]interface{}{"cleared": cleared})
+}
+
+// unbanHandler 解除单个 IP 的封禁，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) unbanHandler(c echo.Context) error {
+	ip := c.Param("ip")
+	if !s.banList.Unban(ip) {
+		return c.JSON(http.StatusNotFound, NewAPIError(ErrCodeInvalidRequest, "ban not found"))
+	}
+	s.logger.Info().Str("ip", ip).Str("admin_ip", c.RealIP()).Msg("已解除自动封禁")
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// newAutoBanServer 构建启用自动封禁 (3 次违规封禁) 与管理令牌的服务器，并注册按路径参数返回状态码的测试路由，参数: 测试实例与封禁时长，返回: 服务器
+func newAutoBanServer(t *testing.T, cooldown string) *Server {
+	t.Helper()
+	cfg := &config.Config{
+		Server: config.ServerConfig{AutoBan: config.AutoBanConfig{Enabled: true, Threshold: 3, Window: "1m", Cooldown: cooldown}},
+		Admin:  config.AdminConfig{Token: "admin-token"},
+	}
+	s := newTestServer(t, cfg, &stubService{})
+	s.echo.GET("/test/status/:code", func(c echo.Context) error {
+		code, _ := strconv.Atoi(c.Param("code"))
+		return c.NoContent(code)
+	})
+	s.echo.GET("/test/error/:code", func(c echo.Context) error {
+		code, _ := strconv.Atoi(c.Param("code"))
+		return echo.NewHTTPError(code)
+	})
+	s.echo.GET("/test/forbidden", func(c echo.Context) error {
+		return NewAPIError(ErrCodeForbidden, "forbidden")
+	})
+	s.echo.GET("/test/upstream/unsupported", func(c echo.Context) error {
+		return upstreamErrorResponse(c, deeplx.ErrUnsupportedLanguage)
+	})
+	s.echo.GET("/test/upstream/too-long", func(c echo.Context) error {
+		status, apiErr := upstreamError(deeplx.ErrTextTooLong)
+		return apiErr.WithStatus(status)
+	})
+	return s
+}
+
+// requestFrom 以指定客户端地址发送 GET 请求，参数: 服务器、客户端 IP、路径与请求头，返回: 响应记录
+func requestFrom(s *Server, ip, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":5000"
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return serve(s, req)
+}
+
+// TestAutoBanStrikes 测试哪些响应计为违规：401、403、404 (含未知路由与返回错误的处理器) 计入，429、5xx 与上游错误映射出的 4xx 不计入，参数: 测试实例，返回: 无
+func TestAutoBanStrikes(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantBanned bool
+	}{
+		{name: "401", path: "/test/status/401", wantBanned: true},
+		{name: "未知路由 404", path: "/no-such-route", wantBanned: true},
+		{name: "HTTPError 404", path: "/test/error/404", wantBanned: true},
+		{name: "APIError 403", path: "/test/forbidden", wantBanned: true},
+		{name: "400", path: "/test/status/400", wantBanned: true},
+		{name: "429 不计入", path: "/test/status/429"},
+		{name: "500 不计入", path: "/test/error/500"},
+		{name: "200 不计入", path: "/test/status/200"},
+		{name: "上游 400 不计入", path: "/test/upstream/unsupported"},
+		{name: "上游 413 不计入", path: "/test/upstream/too-long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAutoBanServer(t, "10m")
+			s.echo.Logger.SetOutput(io.Discard)
+			for range 3 {
+				requestFrom(s, "192.0.2.10", tt.path, nil)
+			}
+			rec := requestFrom(s, "192.0.2.10", "/test/status/200", nil)
+			if banned := rec.Code == http.StatusForbidden; banned != tt.wantBanned {
+				t.Fatalf("3 次 %s 后 status = %d, want banned = %v", tt.path, rec.Code, tt.wantBanned)
+			}
+			if !tt.wantBanned {
+				return
+			}
+			if retryAfter, _ := strconv.Atoi(rec.Header().Get("Retry-After")); retryAfter <= 0 || retryAfter > 600 {
+				t.Errorf("Retry-After = %q", rec.Header().Get("Retry-After"))
+			}
+			// 封禁只针对该 IP
+			if rec := requestFrom(s, "192.0.2.11", "/test/status/200", nil); rec.Code != http.StatusOK {
+				t.Errorf("其他 IP status = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
+
+// TestAutoBanAdminExempt 测试管理接口不参与封禁：其 401 不计为违规，被封禁的 IP 仍可访问管理接口，参数: 测试实例，返回: 无
+func TestAutoBanAdminExempt(t *testing.T) {
+	s := newAutoBanServer(t, "10m")
+	for range 5 {
+		if rec := requestFrom(s, "192.0.2.20", "/admin/usage", nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("无令牌访问管理接口 status = %d, want 401", rec.Code)
+		}
+	}
+	if rec := requestFrom(s, "192.0.2.20", "/test/status/200", nil); rec.Code != http.StatusOK {
+		t.Errorf("管理接口的 401 不应计为违规, status = %d", rec.Code)
+	}
+
+	for range 3 {
+		requestFrom(s, "192.0.2.21", "/test/status/404", nil)
+	}
+	if rec := requestFrom(s, "192.0.2.21", "/test/status/200", nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403 (已封禁)", rec.Code)
+	}
+	rec := requestFrom(s, "192.0.2.21", "/admin/bans", map[string]string{echo.HeaderAuthorization: "Bearer admin-token"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("被封禁的 IP 访问管理接口 status = %d, want 200", rec.Code)
+	}
+	if rec := requestFrom(s, "192.0.2.21", "/healthz", nil); rec.Code != http.StatusOK {
+		t.Errorf("被封禁的 IP 访问 /healthz status = %d, want 200", rec.Code)
+	}
+}
+
+// TestAutoBanCooldown 测试封禁到期后自动解除，管理接口可提前解封，参数: 测试实例，返回: 无
+func TestAutoBanCooldown(t *testing.T) {
+	s := newAutoBanServer(t, "100ms")
+	for range 3 {
+		requestFrom(s, "192.0.2.30", "/test/status/401", nil)
+	}
+	if rec := requestFrom(s, "192.0.2.30", "/test/status/200", nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+	// 封禁期间的请求不延长封禁
+	time.Sleep(150 * time.Millisecond)
+	if rec := requestFrom(s, "192.0.2.30", "/test/status/200", nil); rec.Code != http.StatusOK {
+		t.Errorf("封禁到期后 status = %d, want 200", rec.Code)
+	}
+
+	s = newAutoBanServer(t, "10m")
+	for range 3 {
+		requestFrom(s, "192.0.2.31", "/test/status/401", nil)
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/admin/bans/192.0.2.31", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer admin-token")
+	if rec := serve(s, req); rec.Code != http.StatusNoContent {
+		t.Fatalf("解封 status = %d, want 204", rec.Code)
+	}
+	if rec := requestFrom(s, "192.0.2.31", "/test/status/200", nil); rec.Code != http.StatusOK {
+		t.Errorf("解封后 status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/bytes"
+)
+
+// bodyLimit 构建请求体大小限制中间件，超限时返回统一的 APIError，参数: 无（使用接收者），返回: 中间件
+// Content-Length 超限时直接拒绝；未声明长度的请求体在处理器读取时超限，同样在这里转换为 413
+// 使用 http.MaxBytesReader 而非 Echo 的 BodyLimit：后者会把越界的那次读取数据一并交给解码器，分块传输的 JSON 可能被完整解析
+func (s *Server) bodyLimit() echo.MiddlewareFunc {
+	maxSize := s.config.Server.GetMaxBodySize()
+	limit, err := bytes.Parse(maxSize)
+	if err != nil || limit <= 0 {
+		// 配置已在 Validate 中校验，这里兜底使用默认值
+		maxSize, limit = "2M", 2*bytes.MiB
+	}
+
+	tooLarge := func(c echo.Context) error {
+		return c.JSON(http.StatusRequestEntityTooLarge, NewAPIError(ErrCodePayloadTooLarge, "request body too large").WithDetails(map[string]interface{}{
+			"max_body_size": maxSize,
+		}))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return tooLarge(c)
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+
+			err := next(c)
+			if isBodyTooLarge(err) && !c.Response().Committed {
+				return tooLarge(c)
+			}
+			return err
+		}
+	}
+}
+
+// isBodyTooLarge 判断错误是否由请求体超限引起 (包括绑定时包装后的错误)，参数: 错误，返回: 布尔
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
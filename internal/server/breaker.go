@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// breakerService 经熔断器调用提供商的翻译服务，断开期间直接返回 *breaker.OpenError
+type breakerService struct {
+	deeplx.TranslationService
+	breaker *breaker.Breaker
+}
+
+// withCircuitBreaker 按配置为提供商包装熔断器并登记到上游限制，参数: 熔断配置、上游限制、提供商名称与服务，返回: 包装后的服务 (未启用时原样返回)
+// 只在 New 中构建提供商时调用，无需加锁
+func withCircuitBreaker(cfg config.CircuitBreakerConfig, limits *upstreamLimits, name string, service deeplx.TranslationService) deeplx.TranslationService {
+	if !cfg.Enabled {
+		return service
+	}
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(breaker.StateClosed))
+	b := breaker.New(name, breaker.Settings{
+		Window:         cfg.Window,
+		MinCalls:       cfg.MinCalls,
+		ErrorRate:      cfg.ErrorRate,
+		SlowCall:       cfg.GetSlowCall(),
+		SlowRate:       cfg.SlowRate,
+		OpenDuration:   cfg.GetOpenDuration(),
+		HalfOpenProbes: cfg.HalfOpenProbes,
+	}, recordBreakerTransition)
+	limits.breakers = append(limits.breakers, b)
+	return &breakerService{TranslationService: service, breaker: b}
+}
+
+// TranslateBatch 经熔断器执行批量翻译，一次批量调用计为一次调用，参数: 上下文、文本列表、源语言、目标语言、数据类型、模型名称，返回: 翻译响应列表与错误
+func (b *breakerService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	done, err := b.breaker.Allow()
+	if err != nil {
+		metrics.CircuitBreakerRejections.WithLabelValues(b.breaker.Name()).Inc()
+		return nil, err
+	}
+	responses, err := b.TranslationService.TranslateBatch(ctx, texts, sl, tl, dt, model)
+	done(err)
+	return responses, err
+}
+
+// recordBreakerTransition 记录熔断器状态变化指标，参数: 提供商名称、原状态与新状态，返回: 无
+func recordBreakerTransition(name string, _, to breaker.State) {
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(to))
+	metrics.CircuitBreakerTransitions.WithLabelValues(name, to.String()).Inc()
+}
+
+// Translate 经熔断器执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型，返回: 翻译响应与错误
+func (b *breakerService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	return b.call(func() (*translation.Response, error) {
+		return b.TranslationService.Translate(ctx, q, sl, tl, dt)
+	})
+}
+
+// TranslateWithModel 经熔断器使用指定模型执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型、模型名称，返回: 翻译响应与错误
+func (b *breakerService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	return b.call(func() (*translation.Response, error) {
+		return b.TranslationService.TranslateWithModel(ctx, q, sl, tl, dt, model)
+	})
+}
+
+// call 申请熔断器放行后执行调用并记录结果，参数: 实际调用，返回: 翻译响应与错误
+func (b *breakerService) call(fn func() (*translation.Response, error)) (*translation.Response, error) {
+	done, err := b.breaker.Allow()
+	if err != nil {
+		metrics.CircuitBreakerRejections.WithLabelValues(b.breaker.Name()).Inc()
+		return nil, err
+	}
+	resp, err := fn()
+	done(err)
+	return resp, err
+}
+
+// circuitOpenResponse 返回熔断拒绝的 503 响应，参数: Echo 上下文与熔断错误，返回: 处理结果的错误
+func circuitOpenResponse(c echo.Context, open *breaker.OpenError) error {
+	seconds := max(int(math.Ceil(open.RetryAfter.Seconds())), 1)
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return c.JSON(http.StatusServiceUnavailable, NewAPIError(ErrCodeServiceUnavailable, "translation provider circuit open").WithDetails(map[string]interface{}{
+		"provider":    open.Name,
+		"retry_after": seconds,
+	}))
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+	"github.com/XgzK/translate-services/internal/usage"
+)
+
+// budgetWebhookTimeout 发送预算告警 Webhook 的超时
+const budgetWebhookTimeout = 10 * time.Second
+
+// newBudgetTracker 根据用量配置创建费用预算跟踪器，参数: 用量配置、用量账本、后台任务组与日志记录器，返回: 跟踪器 (未配置预算时为 nil)
+func newBudgetTracker(cfg config.UsageConfig, ledger *usage.Ledger, background *pipeline.Background, logger *zerolog.Logger) *usage.BudgetTracker {
+	if len(cfg.Budgets) == 0 {
+		return nil
+	}
+	budgets := make([]usage.Budget, 0, len(cfg.Budgets))
+	for _, b := range cfg.Budgets {
+		budgets = append(budgets, usage.Budget{
+			Provider:     b.Provider,
+			Daily:        b.Daily,
+			Monthly:      b.Monthly,
+			AlertPercent: float64(b.GetAlertPercent()),
+		})
+	}
+	return usage.NewBudgetTracker(ledger, budgets, budgetAlerter(cfg, background, logger))
+}
+
+// budgetAlerter 生成预算告警回调：记录日志与指标，配置了 Webhook 时在后台 POST 告警，参数: 用量配置、后台任务组与日志记录器，返回: 回调
+func budgetAlerter(cfg config.UsageConfig, background *pipeline.Background, logger *zerolog.Logger) func(usage.Alert) {
+	client := &http.Client{Timeout: budgetWebhookTimeout}
+	currency := cfg.GetCurrency()
+	return func(alert usage.Alert) {
+		level, msg := "alert", "提供商费用达到预算告警阈值"
+		if alert.Exceeded {
+			level, msg = "exceeded", "提供商费用预算已用尽"
+		}
+		metrics.UsageBudgetAlerts.WithLabelValues(alert.Provider, alert.Period, level).Inc()
+		logger.Warn().
+			Str("provider", alert.Provider).
+			Str("period", alert.Period).
+			Str("date", alert.Date).
+			Float64("budget", alert.Budget).
+			Float64("spent", alert.Spent).
+			Str("currency", currency).
+			Msg(msg)
+
+		if cfg.AlertWebhook == "" {
+			return
+		}
+		background.Go("budget_alert", func(ctx context.Context) error {
+			return postBudgetAlert(ctx, client, cfg.AlertWebhook, currency, alert)
+		})
+	}
+}
+
+// postBudgetAlert 向 Webhook 发送预算告警，参数: 上下文、HTTP 客户端、Webhook 地址、货币单位与告警，返回: 错误
+func postBudgetAlert(ctx context.Context, client *http.Client, webhook, currency string, alert usage.Alert) error {
+	body, err := json.Marshal(struct {
+		Event string `json:"event"`
+		usage.Alert
+		Currency string `json:"currency"`
+	}{Event: "budget_alert", Alert: alert, Currency: currency})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送预算告警失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("预算告警 Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// budgetService 调用提供商前检查费用预算的翻译服务，预算用尽时直接返回 *usage.ExceededError (故障转移时转到下一个提供商)
+type budgetService struct {
+	deeplx.TranslationService
+	budgets *usage.BudgetTracker
+	name    string
+}
+
+// withBudget 为配置了预算的提供商包装预算检查，参数: 上游限制、提供商名称与服务，返回: 包装后的服务 (未配置该提供商的预算时原样返回)
+// 应包装在隔离舱之外，预算用尽的调用不占用并发槽位
+func withBudget(limits *upstreamLimits, name string, service deeplx.TranslationService) deeplx.TranslationService {
+	if limits.budgets == nil || !limits.budgets.Has(name) {
+		return service
+	}
+	return &budgetService{TranslationService: service, budgets: limits.budgets, name: name}
+}
+
+// check 检查提供商的预算并记录拒绝指标，参数: 上下文，返回: 预算用尽时的错误
+func (b *budgetService) check(ctx context.Context) error {
+	if err := b.budgets.Check(ctx, b.name); err != nil {
+		metrics.UsageBudgetExceeded.WithLabelValues(b.name, config.BudgetActionReject).Inc()
+		return err
+	}
+	return nil
+}
+
+// Translate 检查预算后执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型，返回: 翻译响应与错误
+func (b *budgetService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	if err := b.check(ctx); err != nil {
+		return nil, err
+	}
+	return b.TranslationService.Translate(ctx, q, sl, tl, dt)
+}
+
+// TranslateWithModel 检查预算后使用指定模型执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型、模型名称，返回: 翻译响应与错误
+func (b *budgetService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	if err := b.check(ctx); err != nil {
+		return nil, err
+	}
+	return b.TranslationService.TranslateWithModel(ctx, q, sl, tl, dt, model)
+}
+
+// TranslateBatch 检查预算后执行批量翻译，参数: 上下文、文本列表、源语言、目标语言、数据类型、模型名称，返回: 翻译响应列表与错误
+func (b *budgetService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	if err := b.check(ctx); err != nil {
+		return nil, err
+	}
+	return b.TranslationService.TranslateBatch(ctx, texts, sl, tl, dt, model)
+}
+
+// budgetFallbacks 返回 action 为 fallback 的预算：提供商名称 → 备用提供商名称，参数: 配置，返回: 映射
+func budgetFallbacks(cfg *config.Config) map[string]string {
+	fallbacks := make(map[string]string)
+	for _, b := range cfg.Usage.Budgets {
+		if b.GetAction() != config.BudgetActionFallback {
+			continue
+		}
+		provider, _ := cfg.Translation.FindProvider(b.Provider)
+		fallback, _ := cfg.Translation.FindProvider(b.Fallback)
+		fallbacks[provider.GetName()] = fallback.GetName()
+	}
+	return fallbacks
+}
+
+// budgetFallbackProviders 返回预算用尽后改用的提供商，需要加入按 Request.Provider 路由的提供商，参数: 配置，返回: 提供商配置列表
+func budgetFallbackProviders(cfg *config.Config) []config.ProviderConfig {
+	var providers []config.ProviderConfig
+	for _, b := range cfg.Usage.Budgets {
+		if b.GetAction() != config.BudgetActionFallback {
+			continue
+		}
+		if p, ok := cfg.Translation.FindProvider(b.Fallback); ok {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// withBudgetFallback 请求的提供商 (未指定时为默认提供商) 预算用尽且配置了备用提供商时，改由备用提供商翻译，参数: 配置、上游限制与路由后的末端处理器，返回: 处理器
+// 改用备用提供商时丢弃请求的模型，改用备用提供商的默认模型
+func withBudgetFallback(cfg *config.Config, limits *upstreamLimits, terminal pipeline.Handler) pipeline.Handler {
+	fallbacks := budgetFallbacks(cfg)
+	if limits.budgets == nil || len(fallbacks) == 0 {
+		return terminal
+	}
+	defaultProvider := cfg.Translation.DefaultProvider()
+	defaultName := defaultProvider.GetName()
+
+	return func(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+		provider := req.Provider
+		if provider == "" {
+			provider = defaultName
+		}
+		fallback, ok := fallbacks[provider]
+		if !ok {
+			return terminal(ctx, req)
+		}
+		var exceeded *usage.ExceededError
+		if err := limits.budgets.Check(ctx, provider); !errors.As(err, &exceeded) {
+			return terminal(ctx, req)
+		}
+
+		metrics.UsageBudgetExceeded.WithLabelValues(provider, config.BudgetActionFallback).Inc()
+		redirected := *req
+		redirected.Provider = fallback
+		redirected.Model = cfg.Translation.ResolveModel(fallback, "")
+		redirected.DefaultModel = true
+		if fallback == defaultName {
+			redirected.Provider = ""
+		}
+		return terminal(ctx, &redirected)
+	}
+}
+
+// budgetExceededResponse 返回提供商预算用尽的 429 响应，参数: Echo 上下文与预算错误，返回: 处理结果的错误
+func budgetExceededResponse(c echo.Context, exceeded *usage.ExceededError) error {
+	return c.JSON(http.StatusTooManyRequests, budgetExceededError(exceeded))
+}
+
+// budgetExceededError 把预算错误转换为 API 错误，参数: 预算错误，返回: API 错误
+func budgetExceededError(exceeded *usage.ExceededError) *APIError {
+	return NewAPIError(ErrCodeQuotaExceeded, "provider budget exceeded").WithDetails(map[string]interface{}{
+		"provider": exceeded.Provider,
+		"period":   exceeded.Period,
+		"budget":   exceeded.Budget,
+		"spent":    exceeded.Spent,
+	})
+}
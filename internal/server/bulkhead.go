@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/bulkhead"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// overloadError 隔离舱拒绝调用时返回的错误
+type overloadError struct {
+	bulkhead string
+	err      error
+}
+
+// Error 实现 error 接口，参数: 无，返回: 错误字符串
+func (e *overloadError) Error() string {
+	return fmt.Sprintf("上游并发隔离 %s 拒绝请求: %v", e.bulkhead, e.err)
+}
+
+// Unwrap 返回底层错误，参数: 无，返回: 错误
+func (e *overloadError) Unwrap() error {
+	return e.err
+}
+
+// bulkheadStage 在调用提供商前获取全局隔离舱槽位的管道阶段
+// 位于缓存阶段之后，缓存命中不占用上游并发；按提供商的限制由 withBulkhead 包装在各提供商服务上
+type bulkheadStage struct {
+	global *bulkhead.Bulkhead
+}
+
+// newBulkheadStage 根据配置构建并发隔离阶段，参数: 并发配置，返回: 阶段 (未启用时为 nil)
+func newBulkheadStage(cfg config.ConcurrencyConfig) *bulkheadStage {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &bulkheadStage{global: bulkhead.New("global", cfg.GetMaxInFlight(), cfg.MaxQueue, cfg.GetQueueTimeout())}
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (b *bulkheadStage) Name() string {
+	return "bulkhead"
+}
+
+// Process 获取全局隔离舱槽位后调用下游，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (b *bulkheadStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	release, err := acquireBulkhead(ctx, b.global)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return next(ctx, req)
+}
+
+// acquireBulkhead 获取隔离舱槽位并记录排队、占用与拒绝指标，参数: 上下文与隔离舱，返回: 释放函数与错误 (*overloadError)
+func acquireBulkhead(ctx context.Context, bh *bulkhead.Bulkhead) (func(), error) {
+	metrics.BulkheadQueued.WithLabelValues(bh.Name()).Inc()
+	release, err := bh.Acquire(ctx)
+	metrics.BulkheadQueued.WithLabelValues(bh.Name()).Dec()
+	if err != nil {
+		metrics.BulkheadRejections.WithLabelValues(bh.Name(), rejectionReason(err)).Inc()
+		return nil, &overloadError{bulkhead: bh.Name(), err: err}
+	}
+
+	metrics.BulkheadInFlight.WithLabelValues(bh.Name()).Inc()
+	return func() {
+		release()
+		metrics.BulkheadInFlight.WithLabelValues(bh.Name()).Dec()
+	}, nil
+}
+
+// bulkheadService 经提供商隔离舱调用上游的翻译服务，并发已满时排队，队列已满或排队超时返回 *overloadError
+type bulkheadService struct {
+	deeplx.TranslationService
+	bulkhead *bulkhead.Bulkhead
+}
+
+// withBulkhead 为提供商包装隔离舱，所有对该提供商的上游调用都经过它，参数: 上游限制、提供商名称与服务，返回: 包装后的服务 (未配置该提供商的限制时原样返回)
+// 应包装在熔断器之外，排队被拒绝不计入熔断失败率
+func withBulkhead(limits *upstreamLimits, name string, service deeplx.TranslationService) deeplx.TranslationService {
+	bh := limits.bulkhead(name)
+	if bh == nil {
+		return service
+	}
+	return &bulkheadService{TranslationService: service, bulkhead: bh}
+}
+
+// Translate 获取槽位后执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型，返回: 翻译响应与错误
+func (b *bulkheadService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	release, err := acquireBulkhead(ctx, b.bulkhead)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.TranslationService.Translate(ctx, q, sl, tl, dt)
+}
+
+// TranslateWithModel 获取槽位后使用指定模型执行翻译，参数: 上下文、文本、源语言、目标语言、数据类型、模型名称，返回: 翻译响应与错误
+func (b *bulkheadService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	release, err := acquireBulkhead(ctx, b.bulkhead)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.TranslationService.TranslateWithModel(ctx, q, sl, tl, dt, model)
+}
+
+// TranslateBatch 获取槽位后执行批量翻译，一次批量调用占用一个槽位，参数: 上下文、文本列表、源语言、目标语言、数据类型、模型名称，返回: 翻译响应列表与错误
+func (b *bulkheadService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	release, err := acquireBulkhead(ctx, b.bulkhead)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return b.TranslationService.TranslateBatch(ctx, texts, sl, tl, dt, model)
+}
+
+// overloadedResponse 返回上游队列饱和的 429 响应，客户端应按 Retry-After 退避后重试，参数: Echo 上下文与隔离舱错误，返回: 处理结果的错误
+func (s *Server) overloadedResponse(c echo.Context, overloaded *overloadError) error {
+	retryAfter := s.config.Server.Concurrency.GetRetryAfter()
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return c.JSON(http.StatusTooManyRequests, NewAPIError(ErrCodeRateLimited, "translation service overloaded").WithDetails(map[string]interface{}{
+		"bulkhead":    overloaded.bulkhead,
+		"reason":      rejectionReason(overloaded.err),
+		"retry_after": retryAfter,
+	}))
+}
+
+// rejectionReason 将拒绝原因转换为指标标签，参数: 错误，返回: 标签值
+func rejectionReason(err error) string {
+	switch {
+	case errors.Is(err, bulkhead.ErrQueueFull):
+		return "queue_full"
+	case errors.Is(err, bulkhead.ErrQueueTimeout):
+		return "queue_timeout"
+	default:
+		return "canceled"
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// slotUpstream OpenAI 兼容的测试上游，记录调用次数与最大并发数，release 关闭前每个调用都会阻塞
+type slotUpstream struct {
+	*httptest.Server
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	release  chan struct{}
+}
+
+// newSlotUpstream 创建测试上游，参数: 测试实例与每次调用的耗时，返回: 测试上游
+func newSlotUpstream(t *testing.T, delay time.Duration) *slotUpstream {
+	t.Helper()
+	u := &slotUpstream{release: make(chan struct{})}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.calls.Add(1)
+		n := u.inFlight.Add(1)
+		defer u.inFlight.Add(-1)
+		for {
+			peak := u.peak.Load()
+			if n <= peak || u.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		select {
+		case <-u.release:
+		case <-r.Context().Done():
+			return
+		}
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": "backup"}}},
+		})
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+// backupConfig 使用测试上游作为 backup 提供商的配置，backup 的并发限制为 1 (键名大小写与提供商名称不同)，参数: 测试上游，返回: 配置
+func backupConfig(upstream *slotUpstream, limit config.ConcurrencyLimit) *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			RequestTimeout: 10,
+			Concurrency: config.ConcurrencyConfig{
+				Enabled:    true,
+				RetryAfter: 7,
+				Providers:  map[string]config.ConcurrencyLimit{"Backup": limit},
+			},
+		},
+		Translation: config.TranslationConfig{
+			Providers:        []config.ProviderConfig{{Name: "backup", ServiceType: "openai", APIKey: "sk-backup", BaseURL: upstream.URL, Model: "m"}},
+			ProviderOverride: config.ProviderOverrideConfig{Enabled: true},
+			Comparison:       config.ComparisonConfig{Enabled: true, Provider: "backup"},
+		},
+	}
+}
+
+// TestProviderBulkheadShared 测试故障转移、对冲、指定提供商与对比模式调用同一提供商时共用一个并发上限，参数: 测试实例，返回: 无
+func TestProviderBulkheadShared(t *testing.T) {
+	for _, mode := range []string{"failover", "hedging"} {
+		t.Run(mode, func(t *testing.T) {
+			upstream := newSlotUpstream(t, 5*time.Millisecond)
+			close(upstream.release)
+			cfg := backupConfig(upstream, config.ConcurrencyLimit{MaxInFlight: 1, MaxQueue: 100})
+			primary := &stubService{translate: func(ctx context.Context, q string) (*translation.Response, error) {
+				switch {
+				case strings.HasPrefix(q, "fail"):
+					return nil, deeplx.ErrTranslationFailed
+				case strings.HasPrefix(q, "slow"):
+					select {
+					case <-time.After(time.Second):
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					}
+				}
+				return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: "T:" + q}}}, nil
+			}}
+			// 故障转移把失败的请求交给 backup，对冲在主提供商较慢时调用 backup
+			bodies := []string{}
+			if mode == "failover" {
+				cfg.Translation.Failover = config.FailoverConfig{Enabled: true}
+				for i := range 4 {
+					bodies = append(bodies, fmt.Sprintf(`{"q":"fail-%d","tl":"de"}`, i))
+				}
+			} else {
+				cfg.Translation.Hedging = config.HedgingConfig{Enabled: true, Provider: "backup", Delay: "5ms"}
+				for i := range 4 {
+					bodies = append(bodies, fmt.Sprintf(`{"q":"slow-%d","tl":"de"}`, i))
+				}
+			}
+			for i := range 4 {
+				bodies = append(bodies,
+					fmt.Sprintf(`{"q":"override-%d","tl":"de","provider":"backup"}`, i),
+					fmt.Sprintf(`{"q":"compare-%d","tl":"de","compare":true}`, i),
+				)
+			}
+			s := newTestServer(t, cfg, primary)
+
+			var wg sync.WaitGroup
+			codes := make([]int, len(bodies))
+			for i, body := range bodies {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					codes[i] = serve(s, jsonRequest(http.MethodPost, "/translate_a/single", body)).Code
+				}()
+			}
+			wg.Wait()
+
+			for i, code := range codes {
+				if code != http.StatusOK {
+					t.Errorf("%s: status = %d, want 200", bodies[i], code)
+				}
+			}
+			if got := upstream.calls.Load(); got != int32(len(bodies)) {
+				t.Errorf("backup 调用 %d 次, want %d", got, len(bodies))
+			}
+			if got := upstream.peak.Load(); got != 1 {
+				t.Errorf("backup 最大并发 = %d, want 1 (各用途共用一个隔离舱)", got)
+			}
+			if got := len(s.upstream.bulkheads); got != 1 {
+				t.Errorf("创建了 %d 个提供商隔离舱, want 1", got)
+			}
+		})
+	}
+}
+
+// batchStub 记录批量调用次数的测试服务
+type batchStub struct {
+	*stubService
+	batches atomic.Int32
+}
+
+func (b *batchStub) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	b.batches.Add(1)
+	return b.stubService.TranslateBatch(ctx, texts, sl, tl, dt, model)
+}
+
+// TestProviderBulkheadBatch 测试合并后的一次批量调用只占用一个槽位，参数: 测试实例，返回: 无
+func TestProviderBulkheadBatch(t *testing.T) {
+	svc := &batchStub{stubService: &stubService{}}
+	cfg := &config.Config{
+		Server: config.ServerConfig{Concurrency: config.ConcurrencyConfig{
+			Enabled:   true,
+			Providers: map[string]config.ConcurrencyLimit{"stub": {MaxInFlight: 1}},
+		}},
+		Translation: config.TranslationConfig{Batching: config.BatchingConfig{Enabled: true, MaxWait: "50ms"}},
+	}
+	s := newTestServer(t, cfg, svc)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve(s, jsonRequest(http.MethodPost, "/translate_a/single", fmt.Sprintf(`{"q":"text-%d","tl":"de"}`, i))).Code
+		}()
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("请求 %d status = %d, want 200 (不排队时每段各占一个槽位会被拒绝)", i, code)
+		}
+	}
+	if got := svc.batches.Load(); got != 1 {
+		t.Errorf("批量调用 %d 次, want 1", got)
+	}
+}
+
+// TestOverloadedResponse 测试提供商隔离舱饱和时返回 429、配置的 Retry-After 与拒绝原因，参数: 测试实例，返回: 无
+func TestOverloadedResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      config.ConcurrencyLimit
+		timeout    string
+		wantReason string
+	}{
+		{name: "队列已满", limit: config.ConcurrencyLimit{MaxInFlight: 1}, wantReason: "queue_full"},
+		{name: "排队超时", limit: config.ConcurrencyLimit{MaxInFlight: 1, MaxQueue: 1}, timeout: "20ms", wantReason: "queue_timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := newSlotUpstream(t, 0)
+			cfg := backupConfig(upstream, tt.limit)
+			cfg.Server.Concurrency.QueueTimeout = tt.timeout
+			s := newTestServer(t, cfg, &stubService{})
+
+			// 第一个请求占住 backup 唯一的槽位
+			held := make(chan int)
+			go func() {
+				held <- serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"first","tl":"de","provider":"backup"}`)).Code
+			}()
+			for upstream.inFlight.Load() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"second","tl":"de","provider":"backup"}`))
+			close(upstream.release)
+			if code := <-held; code != http.StatusOK {
+				t.Errorf("占用槽位的请求 status = %d, want 200", code)
+			}
+
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want 429", rec.Code)
+			}
+			if got := rec.Header().Get("Retry-After"); got != "7" {
+				t.Errorf("Retry-After = %q, want 7", got)
+			}
+			var body APIError
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("响应不是 JSON: %s", rec.Body.String())
+			}
+			details, _ := body.Details.(map[string]interface{})
+			if body.Code != ErrCodeRateLimited || details["reason"] != tt.wantReason || details["bulkhead"] != "provider:backup" || details["retry_after"] != float64(7) {
+				t.Errorf("body = %s", rec.Body.String())
+			}
+		})
+	}
+}
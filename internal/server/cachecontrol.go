@@ -0,0 +1,33 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+)
+
+// requestCacheDirective 从请求头 Cache-Control / Pragma 与 nocache 参数解析译文缓存指令，参数: Echo 上下文与 nocache 参数，返回: 缓存指令
+// no-store 优先于 no-cache；nocache=1 等同于 Cache-Control: no-cache
+func requestCacheDirective(c echo.Context, noCache bool) string {
+	header := c.Request().Header
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case pipeline.CacheNoStore:
+				return pipeline.CacheNoStore
+			case pipeline.CacheNoCache:
+				noCache = true
+			}
+		}
+	}
+	if strings.EqualFold(strings.TrimSpace(header.Get("Pragma")), pipeline.CacheNoCache) {
+		noCache = true
+	}
+	if noCache {
+		return pipeline.CacheNoCache
+	}
+	return pipeline.CacheDefault
+}
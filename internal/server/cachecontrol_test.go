@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// TestRequestCacheDirective 测试从 Cache-Control、Pragma 与 nocache 参数解析缓存指令，参数: 测试实例，返回: 无
+func TestRequestCacheDirective(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		noCache bool
+		want    string
+	}{
+		{name: "无指令", want: pipeline.CacheDefault},
+		{name: "no-cache", headers: map[string][]string{"Cache-Control": {"no-cache"}}, want: pipeline.CacheNoCache},
+		{name: "no-store", headers: map[string][]string{"Cache-Control": {"No-Store"}}, want: pipeline.CacheNoStore},
+		{name: "no-store 优先", headers: map[string][]string{"Cache-Control": {"no-cache", "max-age=0, no-store"}}, want: pipeline.CacheNoStore},
+		{name: "no-cache 带字段名", headers: map[string][]string{"Cache-Control": {`max-age=0, no-cache="Set-Cookie"`}}, want: pipeline.CacheNoCache},
+		{name: "Pragma", headers: map[string][]string{"Pragma": {"no-cache"}}, want: pipeline.CacheNoCache},
+		{name: "nocache 参数", noCache: true, want: pipeline.CacheNoCache},
+		{name: "nocache 参数与 no-store", headers: map[string][]string{"Cache-Control": {"no-store"}}, noCache: true, want: pipeline.CacheNoStore},
+		{name: "其他指令", headers: map[string][]string{"Cache-Control": {"max-age=60"}}, want: pipeline.CacheDefault},
+	}
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/translate_a/single", nil)
+			for name, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+			if got := requestCacheDirective(e.NewContext(req, httptest.NewRecorder()), tt.noCache); got != tt.want {
+				t.Errorf("requestCacheDirective() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCacheDirectives 测试 no-cache 跳过读取但仍写回、no-store 既不读也不写，nocache=1 等同于 no-cache，参数: 测试实例，返回: 无
+func TestCacheDirectives(t *testing.T) {
+	var version atomic.Int32
+	svc := &stubService{translate: func(ctx context.Context, q string) (*translation.Response, error) {
+		return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: fmt.Sprintf("v%d:%s", version.Load(), q)}}}, nil
+	}}
+	s, mc := newCachedTestServer(t, nil, svc)
+
+	// translate 发送请求并返回译文版本，每次调用前递增上游译文的版本，参数: 请求，返回: 响应中的译文版本
+	translate := func(req *http.Request) string {
+		t.Helper()
+		version.Add(1)
+		rec := serve(s, req)
+		waitBackground(t, s)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		for v := range version.Load() + 1 {
+			if strings.Contains(rec.Body.String(), fmt.Sprintf(`"v%d:Hello"`, v)) {
+				return fmt.Sprintf("v%d", v)
+			}
+		}
+		t.Fatalf("body = %s", rec.Body.String())
+		return ""
+	}
+	post := func(body string, headers ...string) *http.Request {
+		req := jsonRequest(http.MethodPost, "/translate_a/single", body)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		return req
+	}
+	plain := `{"q":"Hello","sl":"en","tl":"de"}`
+
+	steps := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{name: "首次请求写入缓存", req: post(plain), want: "v1"},
+		{name: "命中缓存", req: post(plain), want: "v1"},
+		{name: "no-cache 跳过读取", req: post(plain, "Cache-Control", "no-cache"), want: "v3"},
+		{name: "no-cache 的结果已写回", req: post(plain), want: "v3"},
+		{name: "no-store 跳过读取", req: post(plain, "Cache-Control", "no-store"), want: "v5"},
+		{name: "no-store 的结果未写回", req: post(plain), want: "v3"},
+		{name: "查询参数 nocache=1", req: jsonRequest(http.MethodPost, "/translate_a/single?nocache=1", plain), want: "v7"},
+		{name: "nocache=1 的结果已写回", req: post(plain), want: "v7"},
+		{name: "请求体 nocache", req: post(`{"q":"Hello","sl":"en","tl":"de","nocache":true}`), want: "v9"},
+		{name: "Pragma: no-cache", req: post(plain, "Pragma", "no-cache"), want: "v10"},
+		{name: "最后写回的结果", req: post(plain), want: "v10"},
+	}
+	for _, step := range steps {
+		if got := translate(step.req); got != step.want {
+			t.Fatalf("%s: 译文版本 = %s, want %s", step.name, got, step.want)
+		}
+	}
+	if got := svc.callCount(); got != 6 {
+		t.Errorf("upstream calls = %d, want 6", got)
+	}
+	if got := mc.len(); got != 1 {
+		t.Errorf("缓存条目 = %d, want 1", got)
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/metrics"
+)
+
+// cacheStatsTimeout 单次统计缓存条目的超时
+const cacheStatsTimeout = 10 * time.Second
+
+// cacheStats 统计译文缓存命中率并定期采样缓存条目数与值大小，结果写入 translate_cache_* 指标
+type cacheStats struct {
+	reporter cache.StatsReporter // 缓存后端不支持统计时为 nil，只计算命中率
+	interval time.Duration
+	logger   *zerolog.Logger
+
+	hits   atomic.Int64 // 上次采样以来的命中次数
+	misses atomic.Int64 // 上次采样以来的未命中次数
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newCacheStats 创建缓存统计，参数: 缓存实例、采样间隔与日志器，返回: 缓存统计
+func newCacheStats(instance cache.Cache, interval time.Duration, logger *zerolog.Logger) *cacheStats {
+	reporter, _ := instance.(cache.StatsReporter)
+	return &cacheStats{reporter: reporter, interval: interval, logger: logger}
+}
+
+// observe 记录一次缓存读取，作为 cache.WithLookupObserver 的回调，参数: 是否命中，返回: 无
+func (c *cacheStats) observe(hit bool) {
+	if hit {
+		c.hits.Add(1)
+		metrics.CacheLookups.WithLabelValues("hit").Inc()
+		return
+	}
+	c.misses.Add(1)
+	metrics.CacheLookups.WithLabelValues("miss").Inc()
+}
+
+// Start 在后台启动定期采样 (启动时立即采样一次)，参数: 无，返回: 无
+func (c *cacheStats) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			c.sample(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止采样并等待进行中的采样结束，参数: 无，返回: 无
+func (c *cacheStats) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+}
+
+// sample 更新命中率与缓存条目指标，命中率按上次采样以来的读取计算 (期间没有读取时保持不变)，参数: 上下文，返回: 无
+func (c *cacheStats) sample(ctx context.Context) {
+	hits, misses := c.hits.Swap(0), c.misses.Swap(0)
+	if total := hits + misses; total > 0 {
+		metrics.CacheHitRatio.Set(float64(hits) / float64(total))
+	}
+	if c.reporter == nil {
+		return
+	}
+
+	statsCtx, cancel := context.WithTimeout(ctx, cacheStatsTimeout)
+	stats, err := c.reporter.Stats(statsCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warn().Err(err).Msg("统计缓存条目失败")
+		}
+		return
+	}
+	metrics.CacheEntries.Set(float64(stats.Entries))
+	metrics.CacheValueBytes.Set(float64(stats.ValueBytes))
+}
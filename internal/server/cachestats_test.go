@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/metrics"
+)
+
+// statsCache 可统计条目的测试缓存，err 不为空时统计失败
+type statsCache struct {
+	*memCache
+	stats cache.Stats
+	err   error
+}
+
+func (s *statsCache) Stats(ctx context.Context) (cache.Stats, error) {
+	return s.stats, s.err
+}
+
+// TestCacheStatsSample 测试命中率按每个采样周期重新计算 (周期内没有读取时保持不变)，条目指标取自缓存后端，参数: 测试实例，返回: 无
+func TestCacheStatsSample(t *testing.T) {
+	logger := zerolog.Nop()
+	backend := &statsCache{memCache: newMemCache(), stats: cache.Stats{Entries: 42, ValueBytes: 4200}}
+	stats := newCacheStats(backend, 0, &logger)
+	ctx := context.Background()
+
+	for _, hit := range []bool{true, true, true, false} {
+		stats.observe(hit)
+	}
+	stats.sample(ctx)
+	if got := testutil.ToFloat64(metrics.CacheHitRatio); got != 0.75 {
+		t.Errorf("hit ratio = %v, want 0.75", got)
+	}
+	if got := testutil.ToFloat64(metrics.CacheEntries); got != 42 {
+		t.Errorf("entries = %v, want 42", got)
+	}
+	if got := testutil.ToFloat64(metrics.CacheValueBytes); got != 4200 {
+		t.Errorf("value bytes = %v, want 4200", got)
+	}
+
+	// 周期内没有读取，命中率保持上一周期的值；统计失败时条目指标保持不变
+	backend.err = errors.New("scan failed")
+	stats.sample(ctx)
+	if got := testutil.ToFloat64(metrics.CacheHitRatio); got != 0.75 {
+		t.Errorf("hit ratio = %v, want 0.75 (保持不变)", got)
+	}
+	if got := testutil.ToFloat64(metrics.CacheEntries); got != 42 {
+		t.Errorf("entries = %v, want 42 (保持不变)", got)
+	}
+
+	// 新周期只统计本周期的读取
+	stats.observe(false)
+	stats.sample(ctx)
+	if got := testutil.ToFloat64(metrics.CacheHitRatio); got != 0 {
+		t.Errorf("hit ratio = %v, want 0", got)
+	}
+}
+
+// TestCacheStatsWithoutReporter 测试不支持统计的缓存后端只更新命中率，参数: 测试实例，返回: 无
+func TestCacheStatsWithoutReporter(t *testing.T) {
+	logger := zerolog.Nop()
+	stats := newCacheStats(newMemCache(), 0, &logger)
+	if stats.reporter != nil {
+		t.Fatal("memCache 不应被识别为 StatsReporter")
+	}
+	stats.observe(true)
+	stats.sample(context.Background())
+	if got := testutil.ToFloat64(metrics.CacheHitRatio); got != 1 {
+		t.Errorf("hit ratio = %v, want 1", got)
+	}
+}
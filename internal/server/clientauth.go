@@ -0,0 +1,254 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/auth"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/quota"
+	"github.com/XgzK/translate-services/internal/ratelimit"
+)
+
+// HeaderAPIKey 客户端密钥请求头
+const HeaderAPIKey = "X-API-Key"
+
+// headerGoogAPIKey 谷歌客户端库使用的密钥请求头
+const headerGoogAPIKey = "X-Goog-Api-Key"
+
+// clientKeyContextKey 认证通过后保存密钥的上下文键
+const clientKeyContextKey = "client_key"
+
+// 客户端密钥相关错误代码
+const (
+	ErrCodeForbidden     = "FORBIDDEN"
+	ErrCodeQuotaExceeded = "QUOTA_EXCEEDED"
+)
+
+// clientKeys 客户端认证状态：密钥、可选的 JWT 校验器，以及按客户端的限流器与额度计数
+type clientKeys struct {
+	store         *auth.KeyStore
+	jwt           *auth.JWTVerifier // 未启用 JWT 时为 nil
+	jwtDailyChars int64             // JWT 用户的每日字符额度
+	usage         *quota.Tracker    // 按客户端标识统计的每日字符数
+
+	mu       sync.Mutex
+	limiters map[string]*ratelimit.Limiter // 按密钥名称懒加载
+}
+
+// newClientKeys 根据配置构建客户端认证状态，参数: 无（使用接收者），返回: 认证状态 (未启用认证时为 nil) 与错误
+func (s *Server) newClientKeys() (*clientKeys, error) {
+	if !s.config.Auth.Enabled {
+		return nil, nil
+	}
+
+	keys := make([]auth.APIKey, 0, len(s.config.Auth.Keys))
+	for _, k := range s.config.Auth.Keys {
+		keys = append(keys, auth.APIKey{
+			Name:       strings.TrimSpace(k.Name),
+			Key:        strings.TrimSpace(k.Key),
+			RPS:        k.RPS,
+			Burst:      k.Burst,
+			DailyChars: k.DailyChars,
+			Endpoints:  k.Endpoints,
+			Source:     auth.KeySourceConfig,
+			CreatedAt:  s.startedAt,
+		})
+	}
+
+	k := &clientKeys{
+		store:    auth.NewKeyStore(keys),
+		usage:    quota.NewTracker(s.newQuotaStore(), 0, quota.WithPrefix("keyquota")),
+		limiters: make(map[string]*ratelimit.Limiter),
+	}
+
+	if jwtCfg := s.config.Auth.JWT; jwtCfg.Enabled {
+		verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+			Secret:       jwtCfg.Secret,
+			JWKSURL:      jwtCfg.JWKSURL,
+			Issuer:       jwtCfg.Issuer,
+			Audience:     jwtCfg.Audience,
+			SubjectClaim: jwtCfg.SubjectClaim,
+			JWKSRefresh:  jwtCfg.GetJWKSRefresh(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		k.jwt = verifier
+		k.jwtDailyChars = jwtCfg.DailyChars
+	}
+	return k, nil
+}
+
+// authenticate 校验请求凭据，JWT 优先于 API Key，参数: Echo 上下文，返回: 客户端与错误 (凭据无效时返回 401 错误)
+func (k *clientKeys) authenticate(c echo.Context) (auth.APIKey, *APIError) {
+	credential := requestAPIKey(c)
+	if k.jwt != nil && auth.LooksLikeJWT(credential) {
+		subject, err := k.jwt.Verify(c.Request().Context(), credential)
+		if err != nil {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="translate-services", error="invalid_token"`)
+			return auth.APIKey{}, NewAPIError(ErrCodeUnauthorized, "invalid bearer token").WithDetails(err.Error())
+		}
+		return auth.APIKey{Name: subject, DailyChars: k.jwtDailyChars, Source: auth.KeySourceJWT}, nil
+	}
+
+	if key, ok := k.store.Lookup(credential); ok {
+		return key, nil
+	}
+	return auth.APIKey{}, NewAPIError(ErrCodeUnauthorized, "valid API key required")
+}
+
+// limiter 返回密钥的限流器，参数: 密钥，返回: 限流器 (未配置 rps 时为 nil)
+func (k *clientKeys) limiter(key auth.APIKey) *ratelimit.Limiter {
+	if key.RPS <= 0 {
+		return nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l, ok := k.limiters[key.Name]
+	if !ok {
+		l = ratelimit.New(key.RPS, key.Burst)
+		k.limiters[key.Name] = l
+	}
+	return l
+}
+
+// revoke 吊销密钥并清理其限流器，参数: 密钥名称，返回: 是否存在
+func (k *clientKeys) revoke(name string) bool {
+	if !k.store.Revoke(name) {
+		return false
+	}
+	k.mu.Lock()
+	delete(k.limiters, name)
+	k.mu.Unlock()
+	return true
+}
+
+// clientAuth 翻译接口的认证中间件 (客户端证书、API Key 或 JWT)，参数: 下一个处理器，返回: 包装后的处理器
+// 已校验的客户端证书直接作为客户端标识；否则依次校验凭据、允许的路由与按密钥限流；未启用认证时直接放行
+func (s *Server) clientAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if key, ok := s.certificateClient(c); ok {
+			c.Set(clientKeyContextKey, key)
+			return next(c)
+		}
+		if s.clientKeys == nil {
+			return next(c)
+		}
+
+		key, apiErr := s.clientKeys.authenticate(c)
+		if apiErr != nil {
+			if c.Response().Header().Get(echo.HeaderWWWAuthenticate) == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="translate-services"`)
+			}
+			return c.JSON(http.StatusUnauthorized, apiErr)
+		}
+
+		if !key.AllowsEndpoint(c.Path()) {
+			return c.JSON(http.StatusForbidden, NewAPIError(ErrCodeForbidden, "API key is not allowed to access this endpoint"))
+		}
+
+		if limiter := s.clientKeys.limiter(key); limiter != nil {
+			route := "key:" + key.Name
+			allowed, retryAfter := limiter.Allow(key.Name)
+			if !allowed {
+				metrics.RateLimitDecisions.WithLabelValues(route, "limited").Inc()
+				seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+				return c.JSON(http.StatusTooManyRequests, NewAPIError(ErrCodeRateLimited, "rate limit exceeded for API key").WithDetails(map[string]interface{}{
+					"retry_after": seconds,
+				}))
+			}
+			metrics.RateLimitDecisions.WithLabelValues(route, "allowed").Inc()
+		}
+
+		c.Set(clientKeyContextKey, key)
+		return next(c)
+	}
+}
+
+// reserveKeyQuota 预留密钥当日字符额度，超额时退还并返回错误，参数: Echo 上下文与字符数，返回: 预留 (未认证或计数存储不可用时为 nil) 与超额时的错误
+func (s *Server) reserveKeyQuota(c echo.Context, chars int64) (*quota.Reservation, *APIError) {
+	// 仅通过客户端证书识别时未启用认证，不单独统计用量
+	key, ok := clientKey(c)
+	if !ok || s.clientKeys == nil || chars <= 0 {
+		return nil, nil
+	}
+
+	reservation, err := s.clientKeys.usage.Reserve(c.Request().Context(), key.Identity(), chars, key.DailyChars)
+	if err != nil {
+		// 计数存储不可用时放行，避免额度系统故障导致整体不可用
+		s.logger.Warn().Err(err).Str("key", key.Name).Msg("记录密钥用量失败")
+		return nil, nil
+	}
+	if usage := reservation.Usage; usage.Exceeded(0) {
+		if err := reservation.Release(c.Request().Context(), chars); err != nil {
+			s.logger.Warn().Err(err).Str("key", key.Name).Msg("退还密钥用量失败")
+		}
+		return nil, NewAPIError(ErrCodeQuotaExceeded, "daily character quota exceeded").WithDetails(map[string]interface{}{
+			"used":  usage.Used - chars,
+			"limit": usage.Limit,
+		})
+	}
+	return reservation, nil
+}
+
+// requestAPIKey 从请求头、Bearer 令牌或 key 查询参数中提取密钥，参数: Echo 上下文，返回: 密钥 (未提供时为空)
+func requestAPIKey(c echo.Context) string {
+	header := c.Request().Header
+	for _, name := range []string{HeaderAPIKey, headerGoogAPIKey} {
+		if v := strings.TrimSpace(header.Get(name)); v != "" {
+			return v
+		}
+	}
+	if authz := header.Get(echo.HeaderAuthorization); strings.HasPrefix(authz, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authz, "Bearer "))
+	}
+	// 谷歌兼容客户端通过 ?key= 传递密钥
+	return strings.TrimSpace(c.QueryParam("key"))
+}
+
+// redactedKeyValue 日志中代替 key 查询参数值的占位符
+const redactedKeyValue = "REDACTED"
+
+// redactedURI 返回隐去 key 查询参数值的请求 URI，用于日志，避免客户端密钥以明文写入访问日志，参数: 请求 URI，返回: 脱敏后的 URI (其余参数保持原顺序与编码)
+func redactedURI(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok || query == "" {
+		return uri
+	}
+	params := strings.Split(query, "&")
+	redacted := false
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && unescaped == "key" {
+			params[i] = name + "=" + redactedKeyValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return uri
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+// clientKey 返回已认证请求的密钥，参数: Echo 上下文，返回: 密钥与是否已认证
+func clientKey(c echo.Context) (auth.APIKey, bool) {
+	key, ok := c.Get(clientKeyContextKey).(auth.APIKey)
+	return key, ok
+}
+
+// authenticatedIdentity 返回已认证请求的客户端标识，参数: Echo 上下文，返回: 标识 (未认证时为空)
+func authenticatedIdentity(c echo.Context) string {
+	if key, ok := clientKey(c); ok {
+		return key.Identity()
+	}
+	return ""
+}
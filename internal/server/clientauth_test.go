@@ -0,0 +1,58 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestRedactedURI 测试日志中的 URI 隐去 key 查询参数的值，其余参数保持原样，参数: 测试实例，返回: 无
+func TestRedactedURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/translate_a/single", "/translate_a/single"},
+		{"/translate_a/single?", "/translate_a/single?"},
+		{"/translate_a/single?client=gtx&key=secret&tl=de", "/translate_a/single?client=gtx&key=REDACTED&tl=de"},
+		{"/translate_a/single?key=a&key=b", "/translate_a/single?key=REDACTED&key=REDACTED"},
+		{"/translate_a/single?%6Bey=secret", "/translate_a/single?%6Bey=REDACTED"},
+		{"/translate_a/single?key", "/translate_a/single?key=REDACTED"},
+		{"/translate_a/single?monkey=1&keys=2", "/translate_a/single?monkey=1&keys=2"},
+	}
+	for _, tt := range tests {
+		if got := redactedURI(tt.uri); got != tt.want {
+			t.Errorf("redactedURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+// TestAccessLogRedactsKey 测试访问日志不记录 ?key= 传递的客户端密钥，参数: 测试实例，返回: 无
+func TestAccessLogRedactsKey(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		Enabled: true,
+		Keys:    []config.ClientKeyConfig{{Name: "ide", Key: "key-ide"}},
+	}}
+	s := newTestServer(t, cfg, &stubService{})
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Level(zerolog.DebugLevel)
+	s.accessLog = &logger
+
+	for _, key := range []string{"key-ide", "key-wrong"} {
+		rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single?key="+key, `{"q":"Hello","tl":"de"}`))
+		if rec.Code == http.StatusInternalServerError {
+			t.Fatalf("status = %d", rec.Code)
+		}
+		if strings.Contains(buf.String(), key) {
+			t.Errorf("访问日志包含密钥 %s: %s", key, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "key=REDACTED") {
+		t.Errorf("访问日志 = %s, want 脱敏后的 URI", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// newComparisonProvider 根据配置创建对比提供商，参数: 配置与上游限制，返回: 提供商 (未启用时为 nil) 与错误
+func newComparisonProvider(cfg *config.Config, limits *upstreamLimits) (*pipeline.Provider, error) {
+	if !cfg.Translation.Comparison.Enabled {
+		return nil, nil
+	}
+	p, _ := cfg.Translation.FindProvider(cfg.Translation.Comparison.Provider)
+	service, err := newProviderService(cfg, limits, p)
+	if err != nil {
+		return nil, fmt.Errorf("创建对比提供商 %s 失败: %w", p.GetName(), err)
+	}
+	return &pipeline.Provider{Name: p.GetName(), Service: service}, nil
+}
+
+// translateCompared 同时经管道翻译并直接请求对比提供商 (不经缓存)，参数: 上下文与请求，返回: 附带对比结果的响应与错误
+// 对比提供商使用自己的默认模型；它失败时只在 comparison.error 中说明，不影响主译文
+func (s *Server) translateCompared(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+	type result struct {
+		resp *translation.Response
+		err  error
+	}
+	secondary := make(chan result, 1)
+	go func() {
+		compareCtx, cancel := context.WithTimeout(ctx, time.Duration(s.config.Server.GetRequestTimeout())*time.Second)
+		defer cancel()
+		compareReq := *req
+		compareReq.Model = s.config.Translation.ResolveModel(s.comparison.Name, "")
+		resp, err := pipeline.ServiceHandler(s.comparison.Service)(compareCtx, &compareReq)
+		if err == nil && resp != nil && s.usage != nil {
+			s.usage.record(ctx, s.comparison.Name, &compareReq, resp)
+		}
+		secondary <- result{resp: resp, err: err}
+	}()
+
+	resp, err := s.pipeline.Run(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	other := <-secondary
+	return withComparison(resp, req.Text, s.comparison.Name, other.resp, other.err), nil
+}
+
+// withComparison 在响应副本上附加对比结果 (下游响应可能被缓存共享，不能原地修改)，参数: 主响应、原文、对比提供商名称、对比响应与错误，返回: 新响应
+func withComparison(resp *translation.Response, text, provider string, other *translation.Response, err error) *translation.Response {
+	out := *resp
+	comparison := &translation.Comparison{Provider: provider}
+	out.Comparison = comparison
+	if err == nil && other == nil {
+		err = errors.New("对比提供商返回为空")
+	}
+	if err != nil {
+		comparison.Error = err.Error()
+		return &out
+	}
+
+	primaryTrans, otherTrans := joinTrans(resp), joinTrans(other)
+	comparison.Similarity = translation.Similarity(primaryTrans, otherTrans)
+	metrics.ComparisonSimilarity.WithLabelValues(provider).Observe(comparison.Similarity)
+
+	out.AlternativeTranslations = append(append([]translation.AlternativeTranslation(nil), resp.AlternativeTranslations...), translation.AlternativeTranslation{
+		SrcPhrase:     text,
+		RawSrcSegment: text,
+		Alternative: []translation.Alternative{{
+			WordPostproc:      otherTrans,
+			Score:             comparison.Similarity,
+			HasPrecedingSpace: strings.HasPrefix(otherTrans, " "),
+		}},
+	})
+	return &out
+}
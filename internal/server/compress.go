@@ -0,0 +1,258 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+)
+
+// 支持的内容编码
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// compressor 响应压缩中间件的共享状态 (编码器池与白名单)，在 New 中构建一次
+type compressor struct {
+	minLength    int
+	brotli       bool
+	contentTypes []string
+	gzipPool     sync.Pool
+	brotliPool   sync.Pool
+}
+
+// newCompressor 根据配置创建压缩器，参数: 无（使用接收者），返回: 压缩器 (未启用时为 nil)
+func (s *Server) newCompressor() *compressor {
+	cfg := s.config.Server.Compression
+	if !cfg.Enabled {
+		return nil
+	}
+
+	level := cfg.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return &compressor{
+		minLength:    cfg.GetMinLength(),
+		brotli:       cfg.Brotli,
+		contentTypes: cfg.GetContentTypes(),
+		gzipPool: sync.Pool{New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			return w
+		}},
+		brotliPool: sync.Pool{New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+		}},
+	}
+}
+
+// middleware 压缩中间件，参数: 下一个处理器，返回: 包装后的处理器
+// 响应先缓冲到 min_length，再根据状态码与 Content-Type 决定是否压缩
+func (cp *compressor) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		res := c.Response()
+		res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+		encoding := cp.negotiate(req.Header.Get(echo.HeaderAcceptEncoding))
+		if encoding == "" || req.Method == http.MethodHead {
+			return next(c)
+		}
+
+		cw := &compressWriter{ResponseWriter: res.Writer, compressor: cp, encoding: encoding}
+		res.Writer = cw
+		defer func() {
+			cw.close()
+			res.Writer = cw.ResponseWriter
+		}()
+		return next(c)
+	}
+}
+
+// negotiate 按 Accept-Encoding 选择编码，参数: 请求头取值，返回: 编码 (不压缩时为空)
+func (cp *compressor) negotiate(acceptEncoding string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v <= 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	switch {
+	case cp.brotli && accepted[encodingBrotli]:
+		return encodingBrotli
+	case accepted[encodingGzip]:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+// compressible 判断响应是否应压缩，参数: 响应头与状态码，返回: 布尔
+func (cp *compressor) compressible(header http.Header, status int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if header.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get(echo.HeaderContentType))
+	if err != nil {
+		return false
+	}
+	for _, allowed := range cp.contentTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 缓冲响应直到可以决定是否压缩的 ResponseWriter
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	encoding   string
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser // 决定压缩后非空
+}
+
+// WriteHeader 记录状态码，延迟到决定是否压缩时再写出，参数: 状态码，返回: 无
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write 写入响应体，参数: 数据，返回: 写入字节数与错误
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		w.buf.Write(b)
+		if w.buf.Len() < w.compressor.minLength {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide 写出响应头与已缓冲的数据，参数: 是否达到压缩阈值，返回: 写出错误
+func (w *compressWriter) decide(largeEnough bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	header := w.ResponseWriter.Header()
+	if largeEnough && w.compressor.compressible(header, w.status) {
+		header.Set(echo.HeaderContentEncoding, w.encoding)
+		header.Del(echo.HeaderContentLength)
+		w.encoder = w.compressor.acquire(w.encoding, w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// Flush 刷新缓冲与编码器，参数: 无，返回: 无
+// 处理器主动刷新说明是流式响应 (如 NDJSON)，总长度未知，不论已缓冲多少都按达到阈值决定是否压缩
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 透传连接劫持 (WebSocket 等)，参数: 无，返回: 连接、读写缓冲与错误
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap 返回底层 ResponseWriter，供 http.ResponseController 使用，参数: 无，返回: ResponseWriter
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close 处理器返回后写出剩余数据并归还编码器，参数: 无，返回: 无
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			// 处理器未写出任何内容
+			return
+		}
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.compressor.release(w.encoding, w.encoder)
+		w.encoder = nil
+	}
+}
+
+// acquire 从池中取出编码器并指向目标，参数: 编码与目标写入器，返回: 编码器
+func (cp *compressor) acquire(encoding string, dst io.Writer) io.WriteCloser {
+	if encoding == encodingBrotli {
+		bw := cp.brotliPool.Get().(*brotli.Writer)
+		bw.Reset(dst)
+		return bw
+	}
+	gw := cp.gzipPool.Get().(*gzip.Writer)
+	gw.Reset(dst)
+	return gw
+}
+
+// release 归还编码器，参数: 编码与编码器，返回: 无
+func (cp *compressor) release(encoding string, encoder io.WriteCloser) {
+	if encoding == encodingBrotli {
+		cp.brotliPool.Put(encoder)
+		return
+	}
+	cp.gzipPool.Put(encoder)
+}
+
+// 确保 compressWriter 满足常用的可选接口
+var (
+	_ http.Flusher  = (*compressWriter)(nil)
+	_ http.Hijacker = (*compressWriter)(nil)
+)
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// newCompressionEcho 构建只挂载压缩中间件的 Echo 实例，参数: 测试实例与压缩配置，返回: Echo 实例与压缩器
+func newCompressionEcho(t *testing.T, cfg config.CompressionConfig) (*echo.Echo, *compressor) {
+	t.Helper()
+	cfg.Enabled = true
+	s := &Server{config: &config.Config{Server: config.ServerConfig{Compression: cfg}}}
+	cp := s.newCompressor()
+	e := echo.New()
+	e.Use(cp.middleware)
+	large := strings.Repeat(`{"trans":"hello world"}`, 100)
+	e.Match([]string{http.MethodGet, http.MethodHead}, "/json", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(large))
+	})
+	e.GET("/small", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(`{"ok":true}`))
+	})
+	e.GET("/png", func(c echo.Context) error { return c.Blob(http.StatusOK, "image/png", []byte(large)) })
+	e.GET("/encoded", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentEncoding, "identity-custom")
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(large))
+	})
+	e.GET("/no-content", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	e.GET("/not-modified", func(c echo.Context) error { return c.NoContent(http.StatusNotModified) })
+	return e, cp
+}
+
+// get 发送带 Accept-Encoding 的请求，参数: Echo 实例、方法、路径与 Accept-Encoding，返回: 响应记录
+func get(e *echo.Echo, method, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode 按 Content-Encoding 解压响应体，参数: 测试实例与响应记录，返回: 解压后的内容
+func decode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch rec.Header().Get(echo.HeaderContentEncoding) {
+	case encodingGzip:
+		gr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		r = gr
+	case encodingBrotli:
+		r = brotli.NewReader(rec.Body)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("读取响应失败: %v", err)
+	}
+	return string(body)
+}
+
+// TestCompressor 测试压缩阈值、媒体类型白名单、编码协商与不应压缩的状态码，参数: 测试实例，返回: 无
+func TestCompressor(t *testing.T) {
+	e, _ := newCompressionEcho(t, config.CompressionConfig{Brotli: true})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+		wantStatus     int
+	}{
+		{name: "超过阈值压缩", path: "/json", acceptEncoding: "gzip", wantEncoding: encodingGzip},
+		{name: "优先 brotli", path: "/json", acceptEncoding: "gzip, br", wantEncoding: encodingBrotli},
+		{name: "br q=0 时退回 gzip", path: "/json", acceptEncoding: "br;q=0, gzip", wantEncoding: encodingGzip},
+		{name: "gzip q=0 不压缩", path: "/json", acceptEncoding: "gzip;q=0"},
+		{name: "不接受压缩", path: "/json"},
+		{name: "低于阈值不压缩", path: "/small", acceptEncoding: "gzip"},
+		{name: "媒体类型不在白名单", path: "/png", acceptEncoding: "gzip"},
+		{name: "已有 Content-Encoding", path: "/encoded", acceptEncoding: "gzip", wantEncoding: "identity-custom"},
+		{name: "HEAD 不压缩", method: http.MethodHead, path: "/json", acceptEncoding: "gzip"},
+		{name: "204", path: "/no-content", acceptEncoding: "gzip", wantStatus: http.StatusNoContent},
+		{name: "304", path: "/not-modified", acceptEncoding: "gzip", wantStatus: http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := get(e, method, tt.path, tt.acceptEncoding)
+			wantStatus := tt.wantStatus
+			if wantStatus == 0 {
+				wantStatus = http.StatusOK
+			}
+			if rec.Code != wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, wantStatus)
+			}
+			if got := rec.Header().Get(echo.HeaderContentEncoding); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get(echo.HeaderVary); got != echo.HeaderAcceptEncoding {
+				t.Errorf("Vary = %q", got)
+			}
+			if wantStatus != http.StatusOK {
+				if rec.Body.Len() != 0 {
+					t.Errorf("body = %q, want 空", rec.Body.String())
+				}
+				return
+			}
+			// HEAD 的响应体由 net/http 丢弃，ResponseRecorder 仍会记录，只检查未压缩
+			if method == http.MethodHead || tt.wantEncoding == "identity-custom" {
+				return
+			}
+			if body := decode(t, rec); !strings.HasPrefix(body, "{") {
+				t.Errorf("body = %.40q", body)
+			}
+		})
+	}
+}
+
+// TestCompressorFlush 测试流式响应每次 Flush 都把已写出的内容 (含压缩编码器中的数据) 推给客户端，参数: 测试实例，返回: 无
+func TestCompressorFlush(t *testing.T) {
+	for _, contentTypes := range [][]string{nil, {mimeNDJSON}} {
+		e, _ := newCompressionEcho(t, config.CompressionConfig{ContentTypes: contentTypes, MinLength: 16})
+		var afterFlush []byte
+		rec := httptest.NewRecorder()
+		e.GET("/stream", func(c echo.Context) error {
+			res := c.Response()
+			res.Header().Set(echo.HeaderContentType, mimeNDJSON)
+			res.WriteHeader(http.StatusOK)
+			res.Write([]byte(`{"index":0}` + "\n"))
+			res.Flush()
+			afterFlush = append([]byte(nil), rec.Body.Bytes()...)
+			res.Write([]byte(`{"done":true}` + "\n"))
+			return nil
+		})
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		e.ServeHTTP(rec, req)
+
+		wantEncoding := ""
+		if contentTypes != nil {
+			wantEncoding = encodingGzip
+		}
+		if got := rec.Header().Get(echo.HeaderContentEncoding); got != wantEncoding {
+			t.Errorf("content types %v: Content-Encoding = %q, want %q", contentTypes, got, wantEncoding)
+		}
+		if !rec.Flushed {
+			t.Errorf("content types %v: 未刷新到客户端", contentTypes)
+		}
+
+		// Flush 时第一行 (低于阈值) 已经完整可读
+		var first io.Reader = bytes.NewReader(afterFlush)
+		if wantEncoding == encodingGzip {
+			gr, err := gzip.NewReader(first)
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error = %v", err)
+			}
+			first = gr
+		}
+		if line, _ := bufio.NewReader(first).ReadString('\n'); line != `{"index":0}`+"\n" {
+			t.Errorf("content types %v: Flush 后可读内容 = %q", contentTypes, line)
+		}
+		if got := decode(t, rec); got != `{"index":0}`+"\n"+`{"done":true}`+"\n" {
+			t.Errorf("content types %v: body = %q", contentTypes, got)
+		}
+	}
+}
+
+// TestCompressorPool 测试编码器从池中复用，复用后的编码器输出不受上一次响应影响，参数: 测试实例，返回: 无
+func TestCompressorPool(t *testing.T) {
+	e, cp := newCompressionEcho(t, config.CompressionConfig{})
+	newGzip := cp.gzipPool.New
+	var created atomic.Int32
+	cp.gzipPool.New = func() interface{} {
+		created.Add(1)
+		return newGzip()
+	}
+
+	want := strings.Repeat(`{"trans":"hello world"}`, 100)
+	const requests = 20
+	for range requests {
+		rec := get(e, http.MethodGet, "/json", "gzip")
+		if got := decode(t, rec); got != want {
+			t.Fatalf("body = %.40q", got)
+		}
+	}
+	// sync.Pool 可能在 GC 或竞态检测时丢弃对象，只要求大部分请求复用
+	if got := created.Load(); got == 0 || got >= requests/2 {
+		t.Errorf("创建了 %d 个编码器，want 复用", got)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// detectStage 源语言为自动检测时先调用提供商的语言检测并把结果作为源语言的管道阶段
+// 只在提供商声明 SupportsDetection 时启用；检测失败时原样交给下游，由上游自行检测
+type detectStage struct {
+	detector deeplx.TranslationService // 检测服务 (启用检测缓存时为带缓存的包装)
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (s *detectStage) Name() string {
+	return "detect"
+}
+
+// Process 源语言为空或 auto 时检测源语言并改写请求副本，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (s *detectStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	if req.Source != "" && !strings.EqualFold(req.Source, "auto") {
+		return next(ctx, req)
+	}
+	detection, err := s.detector.DetectLanguage(ctx, req.Text)
+	if err != nil || detection == nil || strings.TrimSpace(detection.Language) == "" {
+		return next(ctx, req)
+	}
+	detected := *req
+	detected.Source = detection.Language
+	return next(ctx, &detected)
+}
+
+// detectRequest 语言检测请求参数
+type detectRequest struct {
+	Q string `json:"q" form:"q"`
+	N int    `json:"n" form:"n"` // 候选语言数，0 时为默认值 3
+}
+
+// detectResponse 语言检测响应
+type detectResponse struct {
+	Language   string            `json:"language"`
+	Confidence float64           `json:"confidence"`
+	Method     string            `json:"method"`     // provider 或 heuristic
+	Candidates []detectCandidate `json:"candidates"` // 候选语言，首个与 language 相同
+}
+
+// detectCandidate 候选语言
+type detectCandidate struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+// detectHandler 处理语言检测请求，优先使用提供商的检测接口，不支持或失败时退回本地检测，参数: Echo 上下文，返回: 错误
+func (s *Server) detectHandler(c echo.Context) error {
+	var payload detectRequest
+	if strings.Contains(strings.ToLower(c.Request().Header.Get("Content-Type")), "application/json") {
+		if err := c.Bind(&payload); err != nil {
+			if isBodyTooLarge(err) {
+				return err
+			}
+			return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid request payload", err.Error())
+		}
+	} else {
+		if _, err := c.FormParams(); isBodyTooLarge(err) {
+			return err
+		}
+		payload.Q = c.FormValue("q")
+		if raw := c.FormValue("n"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid parameter: n", err.Error())
+			}
+			payload.N = n
+		}
+	}
+	if strings.TrimSpace(payload.Q) == "" {
+		return BadRequest(c, ErrCodeMissingParameter, "missing required parameter: q")
+	}
+	if payload.N < 0 {
+		return BadRequest(c, ErrCodeInvalidRequest, "invalid parameter: n must not be negative")
+	}
+
+	detection, err := deeplx.DetectWithFallback(c.Request().Context(), s.detector, payload.Q)
+	if err != nil {
+		s.requestLog(c).Warn().Err(err).Str("provider", s.detector.GetName()).Msg("提供商语言检测失败，使用本地检测")
+	}
+	method := "provider"
+	candidates := []detectCandidate{{Language: detection.Language, Confidence: detection.Confidence}}
+	if detection.Heuristic {
+		method = "heuristic"
+		// 本地检测可给出多个候选 (如 es 与 pt)，提供商检测只有一个结果
+		local := langutil.DetectLanguages(payload.Q, payload.N)
+		candidates = make([]detectCandidate, len(local))
+		for i, candidate := range local {
+			candidates[i] = detectCandidate{Language: candidate.Language, Confidence: candidate.Confidence}
+		}
+	}
+	return c.JSON(http.StatusOK, detectResponse{
+		Language:   detection.Language,
+		Confidence: detection.Confidence,
+		Method:     method,
+		Candidates: candidates,
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/pipeline"
+)
+
+// dryRunResponse 试运行响应结构，描述请求将如何被处理
+type dryRunResponse struct {
+	DryRun     bool            `json:"dry_run"`
+	Provider   string          `json:"provider"`
+	Model      string          `json:"model,omitempty"`
+	Formality  string          `json:"formality,omitempty"`
+	SourceLang string          `json:"source_lang"`
+	TargetLang string          `json:"target_lang"`
+	DT         []string        `json:"dt"`
+	Characters int             `json:"characters"` // 预计计费字符数
+	Cache      dryRunCacheInfo `json:"cache"`
+
+	EstimatedCost *float64 `json:"estimated_cost,omitempty"` // 按提供商与模型价格估算的费用 (未配置价格时省略)，译文长度按原文估计
+	Currency      string   `json:"currency,omitempty"`       // 费用的货币单位
+}
+
+// dryRunCacheInfo 试运行中的缓存信息
+type dryRunCacheInfo struct {
+	Enabled bool   `json:"enabled"`
+	Key     string `json:"key,omitempty"`
+}
+
+// handleDryRun 返回试运行结果，不调用上游也不写缓存，参数: Echo 上下文、处理器名称 (用于日志) 与已解析的管道请求，返回: 处理结果的错误
+func (s *Server) handleDryRun(c echo.Context, handler string, req *pipeline.Request) error {
+	q, sl, tl, model, formality := req.Text, req.Source, req.Target, req.Model, req.Formality
+	provider := req.Provider
+	if provider == "" {
+		provider = s.providerName
+	}
+	resp := dryRunResponse{
+		DryRun:     true,
+		Provider:   provider,
+		Model:      model,
+		Formality:  formality,
+		SourceLang: sl,
+		TargetLang: tl,
+		DT:         req.DT,
+		Characters: utf8.RuneCountInString(q),
+	}
+
+	// 与用量统计相同，未指定模型时按 default 查找价格
+	priceModel := model
+	if priceModel == "" {
+		priceModel = "default"
+	}
+	chars := int64(resp.Characters)
+	if cost, ok := s.pricing.Cost(provider, priceModel, chars, chars); ok {
+		resp.EstimatedCost = &cost
+		resp.Currency = s.config.Usage.GetCurrency()
+	}
+
+	if s.cache != nil {
+		resp.Cache.Enabled = true
+		resp.Cache.Key = cache.NewKeyGenerator(s.config.Cache.ShareAcrossServices).
+			Generate(provider, q, sl, tl, cache.ModelVariant(model, formality))
+	}
+
+	s.logger.Debug().
+		Str("handler", handler).
+		Str("ip", c.RealIP()).
+		Str("provider", resp.Provider).
+		Str("cache_key", resp.Cache.Key).
+		Msg("试运行请求")
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// isTruthy 判断请求参数是否表示开启，参数: 参数值，返回: 布尔
+func isTruthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "t", "yes", "y", "on":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestDryRunEstimatedCost 测试试运行按提供商与模型的价格估算费用，未配置价格时省略，参数: 测试实例，返回: 无
+func TestDryRunEstimatedCost(t *testing.T) {
+	cfg := &config.Config{Usage: config.UsageConfig{
+		Currency: "EUR",
+		Pricing: []config.UsagePrice{
+			{Provider: "stub", PerMillionChars: 20},
+			{Provider: "stub", Model: "large", PerMillionInputTokens: 4, PerMillionOutputTokens: 8, CharsPerToken: 2},
+		},
+	}}
+	s := newTestServer(t, cfg, &stubService{})
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCost *float64
+	}{
+		{name: "提供商价格", body: `{"q":"Hello","tl":"de","dry_run":true}`, wantCost: ptr(5 * 20 / 1e6)},
+		{name: "模型价格", body: `{"q":"Hello","tl":"de","model":"large","dry_run":true}`, wantCost: ptr(2.5*4/1e6 + 2.5*8/1e6)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", tt.body))
+			var resp dryRunResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !resp.DryRun {
+				t.Fatalf("body = %s, err = %v", rec.Body.String(), err)
+			}
+			if resp.EstimatedCost == nil || math.Abs(*resp.EstimatedCost-*tt.wantCost) > 1e-12 || resp.Currency != "EUR" {
+				t.Errorf("estimated_cost = %v, currency = %q, want %v EUR", resp.EstimatedCost, resp.Currency, *tt.wantCost)
+			}
+		})
+	}
+
+	// 未配置价格的提供商不返回费用
+	s = newTestServer(t, nil, &stubService{})
+	rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"Hello","tl":"de","dry_run":true}`))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body = %s", rec.Body.String())
+	}
+	if _, ok := body["estimated_cost"]; ok {
+		t.Errorf("未配置价格时不应返回 estimated_cost: %s", rec.Body.String())
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// elementHandler 返回元素脚本，参数: Echo 上下文，返回: 处理结果的错误
+// 脚本只在 TKK 周期 (每小时) 切换时变化，附带 ETag / Last-Modified / Cache-Control 并支持条件请求返回 304
+func (s *Server) elementHandler(c echo.Context) error {
+	now := time.Now()
+	js, modified := translation.ElementScriptAt(now)
+	etag := fmt.Sprintf(`"tkk-%d"`, modified.Unix()/3600)
+	maxAge := int(modified.Add(translation.ElementScriptTTL).Sub(now).Seconds())
+
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Last-Modified", modified.Format(http.TimeFormat))
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(max(maxAge, 0)))
+
+	if notModified(c.Request(), etag, modified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, "text/javascript; charset=utf-8", []byte(js))
+}
+
+// notModified 判断条件请求是否命中，参数: 请求、当前 ETag 与最后修改时间，返回: 是否可返回 304
+// 按 RFC 9110 优先使用 If-None-Match，只有其缺失时才比较 If-Modified-Since
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
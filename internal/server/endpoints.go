@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// endpointOptions 将多中继地址配置转换为适配层的健康检查参数，地址被剔除或恢复时更新指标，参数: 提供商名称与中继地址配置，返回: 健康检查参数
+func endpointOptions(name string, cfg config.EndpointsConfig) deeplx.EndpointOptions {
+	return deeplx.EndpointOptions{
+		ProbeInterval: cfg.GetProbeInterval(),
+		ProbeTimeout:  cfg.GetProbeTimeout(),
+		MaxFailures:   cfg.GetMaxFailures(),
+		Eviction:      cfg.GetEviction(),
+		OnStateChange: func(url string, up bool) {
+			if up {
+				metrics.ProviderEndpointUp.WithLabelValues(name, url).Set(1)
+				return
+			}
+			metrics.ProviderEndpointUp.WithLabelValues(name, url).Set(0)
+			metrics.ProviderEndpointEvictions.WithLabelValues(name, url).Inc()
+		},
+	}
+}
@@ -1,9 +1,17 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
 )
 
 // APIError 统一的 API 错误响应结构 (规范化错误处理喵～)
@@ -11,6 +19,12 @@ type APIError struct {
 	Code    string `json:"code"`              // 错误代码
 	Message string `json:"message"`           // 错误消息
 	Details any    `json:"details,omitempty"` // 详细信息（可选）
+
+	// Status 作为 error 返回给框架时使用的状态码，为 0 时按错误代码推断 (见 codeStatuses)
+	Status int `json:"-"`
+
+	// Upstream 错误由上游失败映射而来 (见 upstreamError)，即使是 4xx 也不是客户端的违规
+	Upstream bool `json:"-"`
 }
 
 // 预定义的错误代码常量
@@ -18,11 +32,58 @@ const (
 	ErrCodeInvalidRequest     = "INVALID_REQUEST"
 	ErrCodeMissingParameter   = "MISSING_PARAMETER"
 	ErrCodeUnsupportedFormat  = "UNSUPPORTED_FORMAT"
+	ErrCodeUnsupportedLang    = "UNSUPPORTED_LANGUAGE"
 	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
 	ErrCodeInternalError      = "INTERNAL_ERROR"
 	ErrCodeTranslationFailed  = "TRANSLATION_FAILED"
+	ErrCodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	ErrCodeNotFound           = "NOT_FOUND"
+	ErrCodeMethodNotAllowed   = "METHOD_NOT_ALLOWED"
+	ErrCodeUpstreamTimeout    = "UPSTREAM_TIMEOUT"
+	ErrCodeUpstreamAuth       = "UPSTREAM_AUTH"
+	ErrCodeUpstreamRateLimit  = "UPSTREAM_RATE_LIMITED"
+	ErrCodeUpstreamQuota      = "UPSTREAM_QUOTA_EXCEEDED"
+	ErrCodeUpstreamEmpty      = "UPSTREAM_EMPTY_RESULT"
 )
 
+// statusErrorCodes 框架错误的状态码与错误代码对应关系，未列出的 4xx 归为 INVALID_REQUEST，5xx 归为 INTERNAL_ERROR
+var statusErrorCodes = map[int]string{
+	http.StatusUnauthorized:          ErrCodeUnauthorized,
+	http.StatusForbidden:             ErrCodeForbidden,
+	http.StatusNotFound:              ErrCodeNotFound,
+	http.StatusMethodNotAllowed:      ErrCodeMethodNotAllowed,
+	http.StatusRequestEntityTooLarge: ErrCodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  ErrCodeUnsupportedFormat,
+	http.StatusTooManyRequests:       ErrCodeRateLimited,
+	http.StatusServiceUnavailable:    ErrCodeServiceUnavailable,
+}
+
+// codeStatuses 错误代码对应的默认状态码，APIError 作为 error 返回且未设置 Status 时使用，未列出的代码视为 500
+var codeStatuses = map[string]int{
+	ErrCodeInvalidRequest:     http.StatusBadRequest,
+	ErrCodeMissingParameter:   http.StatusBadRequest,
+	ErrCodeUnsupportedFormat:  http.StatusBadRequest,
+	ErrCodeUnsupportedLang:    http.StatusBadRequest,
+	ErrCodeUnauthorized:       http.StatusUnauthorized,
+	ErrCodeForbidden:          http.StatusForbidden,
+	ErrCodeNotFound:           http.StatusNotFound,
+	ErrCodeMethodNotAllowed:   http.StatusMethodNotAllowed,
+	ErrCodePayloadTooLarge:    http.StatusRequestEntityTooLarge,
+	ErrCodeContentRejected:    http.StatusUnprocessableEntity,
+	ErrCodeRateLimited:        http.StatusTooManyRequests,
+	ErrCodeTooManyAttempts:    http.StatusTooManyRequests,
+	ErrCodeQuotaExceeded:      http.StatusTooManyRequests,
+	ErrCodeUpstreamRateLimit:  http.StatusTooManyRequests,
+	ErrCodeInternalError:      http.StatusInternalServerError,
+	ErrCodeTranslationFailed:  http.StatusBadGateway,
+	ErrCodeUpstreamAuth:       http.StatusBadGateway,
+	ErrCodeUpstreamEmpty:      http.StatusBadGateway,
+	ErrCodeServiceUnavailable: http.StatusServiceUnavailable,
+	ErrCodeMaintenance:        http.StatusServiceUnavailable,
+	ErrCodeUpstreamQuota:      http.StatusServiceUnavailable,
+	ErrCodeUpstreamTimeout:    http.StatusGatewayTimeout,
+}
+
 // NewAPIError 创建 API 错误，参数: 错误代码与消息，返回: APIError 指针
 func NewAPIError(code, message string) *APIError {
 	return &APIError{
@@ -37,6 +98,23 @@ func (e *APIError) WithDetails(details any) *APIError {
 	return e
 }
 
+// WithStatus 设置作为 error 返回给框架时使用的状态码，参数: 状态码，返回: APIError 指针
+func (e *APIError) WithStatus(status int) *APIError {
+	e.Status = status
+	return e
+}
+
+// StatusCode 返回作为 error 返回给框架时使用的状态码，未设置时按错误代码推断，参数: 无，返回: 状态码
+func (e *APIError) StatusCode() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	if status, ok := codeStatuses[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
 // Error 实现 error 接口，参数: 无，返回: 错误字符串
 func (e *APIError) Error() string {
 	return e.Message
@@ -64,7 +142,122 @@ func BadGatewayWithDetails(c echo.Context, code, message string, details any) er
 	return c.JSON(http.StatusBadGateway, NewAPIError(code, message).WithDetails(details))
 }
 
+// upstreamError 按错误类别把上游翻译失败转换为状态码与 API 错误，参数: 错误，返回: 状态码与 API 错误
+// 不支持的语言或模型返回 400，文本超过提供商上限返回 413，上游限流返回 429，上游额度用尽返回 503，超时返回 504，上游拒绝密钥、空译文与其余错误返回 502
+// 返回的 API 错误带 Upstream 标记，自动封禁不把其中的 4xx 计为客户端违规
+func upstreamError(err error) (int, *APIError) {
+	status, apiErr := mapUpstreamError(err)
+	apiErr.Upstream = true
+	return status, apiErr
+}
+
+// mapUpstreamError 按错误类别选择状态码与 API 错误 (见 upstreamError)，参数: 错误，返回: 状态码与 API 错误
+func mapUpstreamError(err error) (int, *APIError) {
+	switch {
+	case errors.Is(err, deeplx.ErrUnsupportedLanguage):
+		return http.StatusBadRequest, NewAPIError(ErrCodeUnsupportedLang, "language not supported by translation provider").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrTextTooLong):
+		return http.StatusRequestEntityTooLarge, NewAPIError(ErrCodePayloadTooLarge, "text exceeds translation provider limit").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrModelNotSupported):
+		return http.StatusBadRequest, NewAPIError(ErrCodeInvalidRequest, "model not supported by translation provider").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrRateLimited):
+		details := map[string]interface{}{"error": err.Error()}
+		if seconds := retryAfterSeconds(err); seconds > 0 {
+			details["retry_after"] = seconds
+		}
+		return http.StatusTooManyRequests, NewAPIError(ErrCodeUpstreamRateLimit, "translation provider rate limited").WithDetails(details)
+	case errors.Is(err, deeplx.ErrQuotaExceeded):
+		return http.StatusServiceUnavailable, NewAPIError(ErrCodeUpstreamQuota, "translation provider quota exceeded").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrTimeout):
+		return http.StatusGatewayTimeout, NewAPIError(ErrCodeUpstreamTimeout, "translation provider timed out").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrEmptyResult):
+		return http.StatusBadGateway, NewAPIError(ErrCodeUpstreamEmpty, "translation provider returned an empty result").WithDetails(err.Error())
+	case errors.Is(err, deeplx.ErrUnauthorized):
+		return http.StatusBadGateway, NewAPIError(ErrCodeUpstreamAuth, "translation provider rejected credentials").WithDetails(err.Error())
+	case errors.Is(err, pipeline.ErrShuttingDown):
+		return http.StatusServiceUnavailable, NewAPIError(ErrCodeServiceUnavailable, "service is shutting down")
+	default:
+		return http.StatusBadGateway, NewAPIError(ErrCodeTranslationFailed, "translation service unavailable").WithDetails(err.Error())
+	}
+}
+
+// upstreamErrorContextKey 标记响应为上游错误的上下文键，供自动封禁识别已直接写出的上游错误
+const upstreamErrorContextKey = "upstream_error"
+
+// upstreamErrorResponse 返回上游翻译失败的响应，上游限流时透传其 Retry-After，参数: Echo 上下文与错误，返回: 处理结果的错误
+func upstreamErrorResponse(c echo.Context, err error) error {
+	status, apiErr := upstreamError(err)
+	c.Set(upstreamErrorContextKey, true)
+	if seconds := retryAfterSeconds(err); status == http.StatusTooManyRequests && seconds > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	return c.JSON(status, apiErr)
+}
+
+// retryAfterSeconds 返回上游要求的等待秒数 (向上取整)，参数: 错误，返回: 秒数 (上游未给出时为 0)
+func retryAfterSeconds(err error) int {
+	return int(math.Ceil(deeplx.RetryAfter(err).Seconds()))
+}
+
 // InternalError 返回 500 错误响应，参数: Echo 上下文、消息，返回: error
 func InternalError(c echo.Context, message string) error {
 	return c.JSON(http.StatusInternalServerError, NewAPIError(ErrCodeInternalError, message))
 }
+
+// httpErrorHandler 将处理器与中间件返回的错误 (路由未命中、方法不允许、请求体超限、panic 等) 统一转换为 APIError 响应
+// 参数: 错误与 Echo 上下文，返回: 无
+func (s *Server) httpErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, apiErr := s.toAPIError(err)
+	if status >= http.StatusInternalServerError {
+		s.logger.Error().Err(err).Str("method", c.Request().Method).Str("uri", redactedURI(c.Request().RequestURI)).Msg("请求处理失败")
+	}
+
+	var writeErr error
+	if c.Request().Method == http.MethodHead {
+		writeErr = c.NoContent(status)
+	} else {
+		writeErr = c.JSON(status, apiErr)
+	}
+	if writeErr != nil {
+		s.logger.Warn().Err(writeErr).Msg("写入错误响应失败")
+	}
+}
+
+// toAPIError 将错误映射为状态码与 APIError，参数: 错误，返回: 状态码与 APIError 指针
+// APIError 使用其状态码，其他非框架错误一律视为 500，且不向客户端暴露内部错误信息
+func (s *Server) toAPIError(err error) (int, *APIError) {
+	if isBodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge, NewAPIError(ErrCodePayloadTooLarge, "request body too large").WithDetails(map[string]interface{}{
+			"max_body_size": s.config.Server.GetMaxBodySize(),
+		})
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode(), apiErr
+	}
+
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return http.StatusInternalServerError, NewAPIError(ErrCodeInternalError, "internal server error")
+	}
+
+	code, ok := statusErrorCodes[httpErr.Code]
+	switch {
+	case ok:
+	case httpErr.Code >= http.StatusInternalServerError:
+		code = ErrCodeInternalError
+	default:
+		code = ErrCodeInvalidRequest
+	}
+
+	message := fmt.Sprint(httpErr.Message)
+	if message == http.StatusText(httpErr.Code) {
+		message = strings.ToLower(message)
+	}
+	return httpErr.Code, NewAPIError(code, message)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestHTTPErrorHandler 测试框架错误、panic 与处理器返回的 APIError 统一转换为带正确状态码的 JSON 错误，参数: 测试实例，返回: 无
+func TestHTTPErrorHandler(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{MaxBodySize: "1K"}}
+	s := newTestServer(t, cfg, &stubService{})
+	s.echo.Logger.SetOutput(io.Discard) // Recover 中间件把 panic 堆栈写入框架日志
+	s.echo.GET("/test/panic", func(c echo.Context) error { panic("boom") })
+	s.echo.GET("/test/forbidden", func(c echo.Context) error {
+		return NewAPIError(ErrCodeForbidden, "forbidden")
+	})
+	s.echo.GET("/test/teapot", func(c echo.Context) error {
+		return NewAPIError(ErrCodeInvalidRequest, "teapot").WithStatus(http.StatusTeapot)
+	})
+	s.echo.GET("/test/unknown-code", func(c echo.Context) error {
+		return NewAPIError("SOMETHING_ELSE", "unknown")
+	})
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string // 为空时期望响应体为空
+	}{
+		{name: "未知路由", req: httptest.NewRequest(http.MethodGet, "/nope", nil), wantStatus: http.StatusNotFound, wantCode: ErrCodeNotFound},
+		{name: "方法不允许", req: httptest.NewRequest(http.MethodGet, "/translate_a/single", nil), wantStatus: http.StatusMethodNotAllowed, wantCode: ErrCodeMethodNotAllowed},
+		{
+			name:       "请求体超限",
+			req:        jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"`+strings.Repeat("a", 2048)+`","tl":"de"}`),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   ErrCodePayloadTooLarge,
+		},
+		{name: "panic", req: httptest.NewRequest(http.MethodGet, "/test/panic", nil), wantStatus: http.StatusInternalServerError, wantCode: ErrCodeInternalError},
+		{name: "HEAD 未知路由", req: httptest.NewRequest(http.MethodHead, "/nope", nil), wantStatus: http.StatusNotFound},
+		{name: "APIError 按代码推断状态码", req: httptest.NewRequest(http.MethodGet, "/test/forbidden", nil), wantStatus: http.StatusForbidden, wantCode: ErrCodeForbidden},
+		{name: "APIError 指定状态码", req: httptest.NewRequest(http.MethodGet, "/test/teapot", nil), wantStatus: http.StatusTeapot, wantCode: ErrCodeInvalidRequest},
+		{name: "APIError 未知代码", req: httptest.NewRequest(http.MethodGet, "/test/unknown-code", nil), wantStatus: http.StatusInternalServerError, wantCode: "SOMETHING_ELSE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(s, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode == "" {
+				if rec.Body.Len() != 0 {
+					t.Errorf("body = %q, want 空", rec.Body.String())
+				}
+				return
+			}
+			var apiErr APIError
+			if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil || apiErr.Code != tt.wantCode {
+				t.Errorf("body = %s, want code %s", rec.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
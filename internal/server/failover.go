@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// providerHeader 记录实际提供译文的提供商的响应头
+const providerHeader = "X-Translation-Provider"
+
+// newTerminalHandler 构建管道末端处理器，启用故障转移时依次尝试各提供商，启用对冲时并发请求备用提供商，仅启用批量合并时合并同类请求，参数: 配置、上游限制与主提供商服务 (已包装熔断器与隔离舱)，返回: 处理器或错误
+func newTerminalHandler(cfg *config.Config, limits *upstreamLimits, primary deeplx.TranslationService) (pipeline.Handler, error) {
+	switch {
+	case cfg.Translation.Failover.Enabled:
+		defaultProvider := cfg.Translation.DefaultProvider()
+		defaultName := defaultProvider.GetName()
+		var providers []pipeline.Provider
+		for _, p := range cfg.Translation.FailoverProviders() {
+			if p.GetName() == defaultName {
+				providers = append(providers, pipeline.Provider{Name: primary.GetName(), Service: primary, Model: cfg.Translation.ResolveModel(defaultName, "")})
+				continue
+			}
+			service, err := newProviderService(cfg, limits, p)
+			if err != nil {
+				return nil, fmt.Errorf("创建故障转移提供商 %s 失败: %w", p.GetName(), err)
+			}
+			providers = append(providers, pipeline.Provider{Name: p.GetName(), Service: service, Model: cfg.Translation.ResolveModel(p.GetName(), "")})
+		}
+		return pipeline.FailoverHandler(providers, cfg.Translation.Failover.GetAttemptTimeout()), nil
+
+	case cfg.Translation.Hedging.Enabled:
+		p, _ := cfg.Translation.FindProvider(cfg.Translation.Hedging.Provider)
+		backup, err := newProviderService(cfg, limits, p)
+		if err != nil {
+			return nil, fmt.Errorf("创建对冲提供商 %s 失败: %w", p.GetName(), err)
+		}
+		defaultProvider := cfg.Translation.DefaultProvider()
+		return pipeline.HedgeHandler(
+			pipeline.Provider{Name: primary.GetName(), Service: primary, Model: cfg.Translation.ResolveModel(defaultProvider.GetName(), "")},
+			pipeline.Provider{Name: p.GetName(), Service: backup, Model: cfg.Translation.ResolveModel(p.GetName(), "")},
+			cfg.Translation.Hedging.GetDelay(),
+		), nil
+
+	case cfg.Translation.Batching.Enabled:
+		return pipeline.BatchHandler(primary, cfg.Translation.Batching.GetMaxSize(), cfg.Translation.Batching.GetMaxWait()), nil
+
+	default:
+		return pipeline.ServiceHandler(primary), nil
+	}
+}
+
+// withProviderRouting 启用按请求指定提供商、规则路由、金丝雀分流或预算备用提供商时，在末端处理器外加一层按 Request.Provider 路由，参数: 配置、上游限制与默认末端处理器，返回: 处理器或错误
+func withProviderRouting(cfg *config.Config, limits *upstreamLimits, terminal pipeline.Handler) (pipeline.Handler, error) {
+	defaultProvider := cfg.Translation.DefaultProvider()
+	seen := map[string]bool{defaultProvider.GetName(): true}
+	var providers []pipeline.Provider
+	routable := append(cfg.Translation.OverrideProviders(), cfg.Translation.RoutingProviders()...)
+	routable = append(routable, cfg.Translation.CanaryProviders()...)
+	for _, p := range append(routable, budgetFallbackProviders(cfg)...) {
+		if seen[p.GetName()] {
+			continue
+		}
+		seen[p.GetName()] = true
+		service, err := newProviderService(cfg, limits, p)
+		if err != nil {
+			return nil, fmt.Errorf("创建可路由的提供商 %s 失败: %w", p.GetName(), err)
+		}
+		providers = append(providers, pipeline.Provider{Name: p.GetName(), Service: service})
+	}
+	if len(providers) == 0 {
+		return terminal, nil
+	}
+	return pipeline.RouteHandler(terminal, providers), nil
+}
+
+// newProviderService 创建故障转移或对冲使用的额外提供商 (失败时返回错误而非原文)，参数: 配置、上游限制与提供商配置，返回: 翻译服务或错误
+func newProviderService(cfg *config.Config, limits *upstreamLimits, p config.ProviderConfig) (deeplx.TranslationService, error) {
+	service, err := deeplx.NewFactory().CreateService(deeplx.ServiceType(strings.ToLower(p.ServiceType)), &deeplx.TranslationServiceConfig{
+		APIKey:        p.APIKey,
+		BaseURL:       p.BaseURL,
+		Timeout:       p.Timeout,
+		Name:          p.GetName(),
+		FailOnError:   true,
+		APIKeys:       upstreamKeys(p.KeyRotation),
+		KeyStrategy:   deeplx.KeyStrategy(p.KeyRotation.Strategy),
+		KeyQuarantine: p.KeyRotation.GetQuarantine(),
+		KeyPattern:    p.KeyPattern,
+		Languages:     p.Capabilities.Languages,
+		MaxTextLength: p.Capabilities.MaxTextLength,
+
+		BaseURLs:        p.Endpoints.URLs,
+		EndpointOptions: endpointOptions(p.GetName(), p.Endpoints),
+		AdaptiveTimeout: adaptiveTimeout(p.AdaptiveTimeout),
+		RetryBudget:     limits.retryBudget,
+		OnUpstreamCall:  limits.observeUpstreamCall(p.GetName()),
+		Prompt:          deeplx.PromptTemplate{System: p.Prompt.System, User: p.Prompt.User},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withBudget(limits, p.GetName(), withBulkhead(limits, p.GetName(), withHealthcheck(limits, p.GetName(), withCircuitBreaker(cfg.Translation.CircuitBreaker, limits, p.GetName(), service)))), nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/glossary"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// glossaryStage 翻译前用占位符保护术语、翻译后还原为指定译法的管道阶段
+// 位于缓存阶段之外，缓存中保存的是带占位符的译文，术语表修改后无需清理缓存即可生效
+type glossaryStage struct {
+	store  glossary.Store
+	logger *zerolog.Logger
+}
+
+// newGlossaryStage 根据配置构建术语表阶段，参数: 术语表配置、缓存实例 (可为 nil) 与日志记录器，返回: 阶段 (未启用时为 nil) 与错误
+func newGlossaryStage(cfg config.GlossaryConfig, cacheInstance cache.Cache, logger *zerolog.Logger) (*glossaryStage, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	sets := make([]glossary.Set, 0, len(cfg.Sets))
+	for _, set := range cfg.Sets {
+		sets = append(sets, glossary.Set{Source: set.Source, Target: set.Target, Terms: set.Terms})
+	}
+	if cfg.File != "" {
+		fileSets, err := glossary.LoadFile(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, fileSets...)
+	}
+
+	stores := glossary.Chain{glossary.NewStaticStore(sets)}
+	if cfg.Redis {
+		if redisCache, ok := cacheInstance.(*cache.RedisCache); ok {
+			stores = append(stores, glossary.NewRedisStore(redisCache.Client(), cfg.RedisPrefix))
+		} else {
+			logger.Warn().Msg("Redis 不可用，术语表仅使用配置与文件中的术语")
+		}
+	}
+	logger.Info().Int("sets", len(sets)).Bool("redis", len(stores) > 1).Msg("术语表初始化完成")
+	return &glossaryStage{store: stores, logger: logger}, nil
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (g *glossaryStage) Name() string {
+	return "glossary"
+}
+
+// Process 保护术语后调用下游，再把占位符还原为指定译法，参数: 上下文、请求与下游处理器，返回: 译文与错误
+// 读取术语失败时记录警告并继续翻译 (部分来源失败时仍使用其余来源的术语)
+func (g *glossaryStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	terms, err := g.store.Terms(ctx, req.Source, req.Target)
+	if err != nil {
+		g.logger.Warn().Err(err).Str("source", req.Source).Str("target", req.Target).Msg("读取术语表失败")
+	}
+	if len(terms) == 0 {
+		return next(ctx, req)
+	}
+
+	text, matches := glossary.Protect(req.Text, terms)
+	if len(matches) == 0 {
+		return next(ctx, req)
+	}
+	protected := *req
+	protected.Text = text
+	resp, err := next(ctx, &protected)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	metrics.GlossaryTerms.Add(float64(len(matches)))
+	return applyGlossary(resp, matches, terms), nil
+}
+
+// applyGlossary 在响应副本上还原术语 (下游响应可能被缓存共享，不能原地修改)，参数: 响应、保护的术语与术语映射，返回: 新响应
+func applyGlossary(resp *translation.Response, matches []glossary.Match, terms map[string]string) *translation.Response {
+	out := *resp
+	out.Sentences = make([]translation.Sentence, len(resp.Sentences))
+	for i, sentence := range resp.Sentences {
+		sentence.Orig = glossary.RestoreOriginal(sentence.Orig, matches)
+		sentence.Trans = glossary.Restore(glossary.Substitute(sentence.Trans, terms), matches)
+		out.Sentences[i] = sentence
+	}
+	return &out
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// healthMonitor 后台定期对各提供商执行 Healthcheck 的监控器，结果用于 /readyz、熔断与故障转移
+// 同一提供商被多处使用时共用一个健康状态，在 New 中注册完全部提供商后由 Start 启动
+type healthMonitor struct {
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	logger    *zerolog.Logger
+
+	providers []*providerHealth
+	byName    map[string]*providerHealth // 按小写名称索引
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// providerHealth 一个提供商的健康状态，并发安全
+type providerHealth struct {
+	name     string
+	service  deeplx.TranslationService
+	breakers []*breaker.Breaker // 不健康时立即断开的熔断器 (未启用熔断时为空)
+
+	mu        sync.Mutex
+	healthy   bool
+	failures  int // 连续失败次数
+	lastError string
+	lastCheck time.Time
+}
+
+// providerHealthView 提供商健康状态的只读视图，用于 /readyz 输出
+type providerHealthView struct {
+	Healthy   bool       `json:"healthy"`
+	Failures  int        `json:"consecutive_failures"`
+	LastError string     `json:"last_error,omitempty"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+}
+
+// newHealthMonitor 根据配置创建健康监控器，参数: 健康检查配置与日志器，返回: 监控器 (未启用时为 nil)
+func newHealthMonitor(cfg config.HealthCheckConfig, logger *zerolog.Logger) *healthMonitor {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &healthMonitor{
+		interval:  cfg.GetInterval(),
+		timeout:   cfg.GetTimeout(),
+		threshold: cfg.GetFailureThreshold(),
+		logger:    logger,
+		byName:    make(map[string]*providerHealth),
+	}
+}
+
+// healthService 按后台健康检查结果报告可用性的提供商服务，不健康时 IsAvailable 返回 false (故障转移时排到最后)
+type healthService struct {
+	deeplx.TranslationService
+	health *providerHealth
+}
+
+// withHealthcheck 把提供商注册到健康监控，参数: 上游限制、提供商名称与服务 (应已包装熔断器)，返回: 包装后的服务 (未启用健康检查时原样返回)
+// 只在 New 中构建提供商时调用，无需加锁
+func withHealthcheck(limits *upstreamLimits, name string, service deeplx.TranslationService) deeplx.TranslationService {
+	m := limits.health
+	if m == nil {
+		return service
+	}
+	key := strings.ToLower(name)
+	health, ok := m.byName[key]
+	if !ok {
+		health = &providerHealth{name: name, service: service, healthy: true}
+		m.byName[key] = health
+		m.providers = append(m.providers, health)
+		metrics.ProviderHealthy.WithLabelValues(name).Set(1)
+	}
+	if b, ok := service.(*breakerService); ok {
+		health.breakers = append(health.breakers, b.breaker)
+	}
+	return &healthService{TranslationService: service, health: health}
+}
+
+// IsAvailable 配置完整且最近的健康检查未判定为不健康时可用，参数: 无，返回: 布尔
+func (h *healthService) IsAvailable() bool {
+	return h.health.isHealthy() && h.TranslationService.IsAvailable()
+}
+
+// isHealthy 返回当前是否健康，参数: 无，返回: 布尔
+func (p *providerHealth) isHealthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.healthy
+}
+
+// view 返回健康状态视图，参数: 无，返回: 视图
+func (p *providerHealth) view() providerHealthView {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	view := providerHealthView{Healthy: p.healthy, Failures: p.failures, LastError: p.lastError}
+	if !p.lastCheck.IsZero() {
+		lastCheck := p.lastCheck
+		view.LastCheck = &lastCheck
+	}
+	return view
+}
+
+// Start 在后台启动定期探测 (启动时立即探测一次)，参数: 无，返回: 无
+func (m *healthMonitor) Start() {
+	if len(m.providers) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.checkAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台探测并等待进行中的探测结束，参数: 无，返回: 无
+func (m *healthMonitor) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// checkAll 并发探测全部提供商，参数: 上下文，返回: 无
+func (m *healthMonitor) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range m.providers {
+		wg.Add(1)
+		go func(p *providerHealth) {
+			defer wg.Done()
+			m.check(ctx, p)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// check 探测一个提供商并更新状态：连续失败达到阈值时标记为不健康并断开熔断器，一次成功即恢复，参数: 上下文与提供商，返回: 无
+func (m *healthMonitor) check(ctx context.Context, p *providerHealth) {
+	checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	err := p.service.Healthcheck(checkCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastCheck = time.Now()
+	if err == nil {
+		metrics.ProviderHealthchecks.WithLabelValues(p.name, "success").Inc()
+		if !p.healthy {
+			m.logger.Info().Str("provider", p.name).Msg("提供商健康检查恢复")
+		}
+		p.healthy, p.failures, p.lastError = true, 0, ""
+		metrics.ProviderHealthy.WithLabelValues(p.name).Set(1)
+		return
+	}
+
+	metrics.ProviderHealthchecks.WithLabelValues(p.name, "failure").Inc()
+	p.failures++
+	p.lastError = err.Error()
+	if p.failures < m.threshold {
+		return
+	}
+	if p.healthy {
+		m.logger.Warn().Err(err).Str("provider", p.name).Int("failures", p.failures).Msg("提供商健康检查连续失败，标记为不健康")
+	}
+	p.healthy = false
+	metrics.ProviderHealthy.WithLabelValues(p.name).Set(0)
+	for _, b := range p.breakers {
+		b.Trip()
+	}
+}
+
+// readyHandler 就绪检查：默认提供商健康 (启用故障转移时任一故障转移提供商健康) 时返回 200，否则返回 503，参数: Echo 上下文，返回: 处理结果的错误
+// 未启用健康检查时按提供商配置是否完整判断
+func (s *Server) readyHandler(c echo.Context) error {
+	ready := s.translationService.IsAvailable()
+	providers := map[string]providerHealthView{}
+	if s.health != nil {
+		candidates := []string{s.translationService.GetName()}
+		if s.config.Translation.Failover.Enabled {
+			candidates = candidates[:0]
+			for _, p := range s.config.Translation.FailoverProviders() {
+				candidates = append(candidates, p.GetName())
+			}
+		}
+		ready = false
+		for _, name := range candidates {
+			if health, ok := s.health.byName[strings.ToLower(name)]; ok && health.isHealthy() {
+				ready = true
+			}
+		}
+		for _, p := range s.health.providers {
+			providers[p.name] = p.view()
+		}
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	return c.JSON(code, map[string]interface{}{
+		"status":    status,
+		"providers": providers,
+	})
+}
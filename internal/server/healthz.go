@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// Version 构建版本，发布时通过 -ldflags "-X github.com/XgzK/translate-services/internal/server.Version=v1.2.3" 注入
+// 未注入时取模块版本 (本地构建为 (devel))
+var Version = ""
+
+// cachePingTimeout /healthz?verbose=1 检查缓存连通性的超时
+const cachePingTimeout = 2 * time.Second
+
+// upstreamCalls 各提供商最近的上游调用结果，并发安全，名称不区分大小写
+type upstreamCalls struct {
+	mu     sync.Mutex
+	byName map[string]*upstreamCallState
+}
+
+// upstreamCallState 一个提供商最近的上游调用结果
+type upstreamCallState struct {
+	name           string
+	lastSuccess    time.Time
+	lastError      time.Time
+	lastErrorClass string
+}
+
+// newUpstreamCalls 创建上游调用记录，参数: 无，返回: 记录指针
+func newUpstreamCalls() *upstreamCalls {
+	return &upstreamCalls{byName: make(map[string]*upstreamCallState)}
+}
+
+// record 记录一次上游调用结果，参数: 提供商名称与错误 (成功时为 nil)，返回: 无
+func (u *upstreamCalls) record(name string, err error) {
+	now := time.Now()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	state := u.stateLocked(name)
+	if err != nil {
+		state.lastError, state.lastErrorClass = now, deeplx.ErrorClass(err)
+		return
+	}
+	state.lastSuccess = now
+}
+
+// stateLocked 返回提供商的调用记录，首次出现时创建，调用方需持有锁，参数: 提供商名称，返回: 调用记录
+func (u *upstreamCalls) stateLocked(name string) *upstreamCallState {
+	key := strings.ToLower(name)
+	state, ok := u.byName[key]
+	if !ok {
+		state = &upstreamCallState{name: name}
+		u.byName[key] = state
+	}
+	return state
+}
+
+// upstreamProviderView 提供商上游调用与熔断状态的只读视图，用于 /healthz?verbose=1 输出
+type upstreamProviderView struct {
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	LastError      *time.Time `json:"last_error,omitempty"`
+	LastErrorClass string     `json:"last_error_class,omitempty"`
+	CircuitBreaker string     `json:"circuit_breaker,omitempty"` // closed/open/half_open，未启用熔断时省略
+}
+
+// cacheHealthView 缓存连通性的只读视图
+type cacheHealthView struct {
+	Enabled   bool    `json:"enabled"`
+	Reachable bool    `json:"reachable"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// buildView 构建信息
+type buildView struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"` // VCS 提交 (构建时带 -buildvcs 才有)
+	Modified  bool   `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+	GoVersion string `json:"go_version"`
+}
+
+// providerViews 汇总各提供商最近的上游调用结果与熔断器状态，参数: 无，返回: 按提供商名称索引的视图
+// 同一提供商被多处使用而有多个熔断器时取最严重的状态 (open > half_open > closed)
+func (u *upstreamLimits) providerViews() map[string]upstreamProviderView {
+	u.calls.mu.Lock()
+	defer u.calls.mu.Unlock()
+	states := make(map[string]breaker.State)
+	for _, b := range u.breakers {
+		u.calls.stateLocked(b.Name())
+		key := strings.ToLower(b.Name())
+		if current, ok := states[key]; !ok || breakerSeverity(b.State()) > breakerSeverity(current) {
+			states[key] = b.State()
+		}
+	}
+
+	views := make(map[string]upstreamProviderView, len(u.calls.byName))
+	for key, state := range u.calls.byName {
+		view := upstreamProviderView{LastErrorClass: state.lastErrorClass}
+		if !state.lastSuccess.IsZero() {
+			lastSuccess := state.lastSuccess
+			view.LastSuccess = &lastSuccess
+		}
+		if !state.lastError.IsZero() {
+			lastError := state.lastError
+			view.LastError = &lastError
+		}
+		if s, ok := states[key]; ok {
+			view.CircuitBreaker = s.String()
+		}
+		views[state.name] = view
+	}
+	return views
+}
+
+// breakerSeverity 熔断器状态的严重程度，参数: 状态，返回: 越大越严重
+func breakerSeverity(state breaker.State) int {
+	switch state {
+	case breaker.StateOpen:
+		return 2
+	case breaker.StateHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// verboseHealthAllowed 判断是否允许输出详细健康状态 (含缓存错误与构建提交，每次请求都会 PING 缓存)，参数: Echo 上下文，返回: 是否允许
+// 启用独立运维端口时 /healthz 只注册在该端口上；否则需要 /metrics 凭据或管理令牌
+func (s *Server) verboseHealthAllowed(c echo.Context) bool {
+	if s.ops != nil {
+		return true
+	}
+	return s.validMetricsCredentials(c) || s.validAdminToken(c)
+}
+
+// cacheHealth 检查缓存是否可达，参数: 上下文，返回: 缓存视图
+func (s *Server) cacheHealth(ctx context.Context) cacheHealthView {
+	if s.cache == nil {
+		return cacheHealthView{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, cachePingTimeout)
+	defer cancel()
+	start := time.Now()
+	view := cacheHealthView{Enabled: true}
+	if err := s.cache.Ping(ctx); err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	view.Reachable = true
+	view.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	return view
+}
+
+// buildInfo 返回构建信息，参数: 无，返回: 构建视图
+func buildInfo() buildView {
+	view := buildView{Version: Version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		if view.Version == "" {
+			view.Version = "unknown"
+		}
+		return view
+	}
+	view.GoVersion = info.GoVersion
+	if view.Version == "" {
+		view.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			view.Revision = setting.Value
+		case "vcs.modified":
+			view.Modified = setting.Value == "true"
+		}
+	}
+	return view
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// TestHealthzVerboseAuth 测试翻译端口上的详细健康状态需要 /metrics 凭据或管理令牌，独立运维端口上直接开放，参数: 测试实例，返回: 无
+func TestHealthzVerboseAuth(t *testing.T) {
+	cfg := &config.Config{
+		Metrics: config.MetricsConfig{Token: "metrics-token"},
+		Admin:   config.AdminConfig{Token: "admin-token"},
+	}
+	s := newTestServer(t, cfg, &stubService{})
+
+	tests := []struct {
+		name       string
+		target     string
+		headers    map[string]string
+		wantStatus int
+		wantBuild  bool
+	}{
+		{name: "普通探活", target: "/healthz", wantStatus: http.StatusOK},
+		{name: "无凭据", target: "/healthz?verbose=1", wantStatus: http.StatusUnauthorized},
+		{name: "错误的令牌", target: "/healthz?verbose=1", headers: map[string]string{"Authorization": "Bearer wrong"}, wantStatus: http.StatusUnauthorized},
+		{name: "metrics 令牌", target: "/healthz?verbose=1", headers: map[string]string{"Authorization": "Bearer metrics-token"}, wantStatus: http.StatusOK, wantBuild: true},
+		{name: "管理令牌", target: "/healthz?verbose=1", headers: map[string]string{"X-Admin-Token": "admin-token"}, wantStatus: http.StatusOK, wantBuild: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := serve(s, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("响应不是 JSON: %s", rec.Body.String())
+			}
+			if _, ok := body["build"]; ok != tt.wantBuild {
+				t.Errorf("body = %s, want build = %v", rec.Body.String(), tt.wantBuild)
+			}
+		})
+	}
+
+	t.Run("未配置凭据", func(t *testing.T) {
+		s := newTestServer(t, nil, &stubService{})
+		if rec := serve(s, httptest.NewRequest(http.MethodGet, "/healthz?verbose=1", nil)); rec.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", rec.Code)
+		}
+	})
+
+	t.Run("独立运维端口", func(t *testing.T) {
+		s := newTestServer(t, &config.Config{Server: config.ServerConfig{Ops: config.OpsConfig{Enabled: true}}}, &stubService{})
+		rec := httptest.NewRecorder()
+		s.ops.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz?verbose=1", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200, body = %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+// trippedBreaker 创建已断开的熔断器，参数: 名称与断开时长 (极短时立即进入半开)，返回: 熔断器
+func trippedBreaker(name string, open time.Duration) *breaker.Breaker {
+	b := breaker.New(name, breaker.Settings{OpenDuration: open}, nil)
+	b.Trip()
+	return b
+}
+
+// TestProviderViews 测试提供商视图按名称合并 (不区分大小写，保留首次出现的名称)，多个熔断器取最严重的状态，参数: 测试实例，返回: 无
+func TestProviderViews(t *testing.T) {
+	u := &upstreamLimits{
+		calls: newUpstreamCalls(),
+		breakers: []*breaker.Breaker{
+			breaker.New("DeepLX", breaker.Settings{}, nil),
+			trippedBreaker("deeplx", time.Hour),
+			trippedBreaker("Backup", time.Nanosecond),
+			breaker.New("backup", breaker.Settings{}, nil),
+			breaker.New("idle", breaker.Settings{}, nil),
+		},
+	}
+	u.calls.record("DeepLX", nil)
+	u.calls.record("DEEPLX", deeplx.ErrTimeout)
+	u.calls.record("memory", nil)
+	time.Sleep(time.Millisecond) // 等待 Backup 的断开时长结束
+
+	views := u.providerViews()
+	if len(views) != 4 {
+		t.Fatalf("views = %+v, want 4 个提供商", views)
+	}
+
+	deepLX, ok := views["DeepLX"]
+	if !ok {
+		t.Fatalf("views = %+v, want 键 DeepLX", views)
+	}
+	if deepLX.CircuitBreaker != "open" || deepLX.LastSuccess == nil || deepLX.LastError == nil || deepLX.LastErrorClass != deeplx.ErrorClassTimeout {
+		t.Errorf("DeepLX = %+v, want open 且同时有成功与超时记录", deepLX)
+	}
+	if backup := views["Backup"]; backup.CircuitBreaker != "half_open" || backup.LastSuccess != nil || backup.LastError != nil {
+		t.Errorf("Backup = %+v, want half_open 且没有调用记录", backup)
+	}
+	if idle := views["idle"]; idle.CircuitBreaker != "closed" {
+		t.Errorf("idle = %+v, want closed", idle)
+	}
+	if memory := views["memory"]; memory.CircuitBreaker != "" || memory.LastSuccess == nil {
+		t.Errorf("memory = %+v, want 没有熔断器状态", memory)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// 单文本接口支持的 format 取值
+const (
+	formatText     = "text"
+	formatHTML     = "html"
+	formatMarkdown = "markdown"
+)
+
+// translateHTML 翻译 HTML 片段：只翻译文本节点，标签、属性与实体原样保留，参数: 上下文与请求 (Text 为 HTML)，返回: 翻译响应与错误
+// 每个不同的文本节点各经管道翻译一次 (享有缓存、术语表等阶段)，任一节点失败则整体失败
+func (s *Server) translateHTML(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+	fragment := translation.ParseHTML(req.Text)
+	return s.translateDocument(ctx, req, fragment.Texts(), fragment.Render)
+}
+
+// translateMarkdown 翻译 Markdown 文档：逐行翻译正文，代码块与行内代码不发送给提供商，参数: 上下文与请求 (Text 为 Markdown)，返回: 翻译响应与错误
+func (s *Server) translateMarkdown(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+	doc := translation.ParseMarkdown(req.Text)
+	return s.translateDocument(ctx, req, doc.Texts(), doc.Render)
+}
+
+// translateDocument 翻译从结构化文档中拆出的文本段并重新拼装，整份文档作为一个句子返回，参数: 上下文、请求、文本段与拼装函数，返回: 翻译响应与错误
+func (s *Server) translateDocument(ctx context.Context, req *pipeline.Request, texts []string, render func([]string) string) (*translation.Response, error) {
+	responses, err := s.translateSegments(ctx, req, texts)
+	if err != nil {
+		return nil, err
+	}
+	translated := append([]string(nil), texts...) // 上游返回空响应的段保留原文
+	for i, resp := range responses {
+		if resp != nil {
+			translated[i] = joinTrans(resp)
+		}
+	}
+
+	return &translation.Response{
+		Src:               firstDetected(responses, req.Source),
+		Sentences:         []translation.Sentence{{Orig: req.Text, Trans: render(translated)}},
+		ProfanityFiltered: anyFiltered(responses),
+		Provider:          lastProvider(responses),
+		Model:             lastModel(responses),
+		Cached:            allCached(responses),
+		UpstreamLatency:   maxLatency(responses),
+	}, nil
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/auth"
+)
+
+// keyView 管理接口返回的密钥信息 (不含完整密钥)
+type keyView struct {
+	Name       string    `json:"name"`
+	KeyHint    string    `json:"key_hint"`
+	RPS        float64   `json:"rps,omitempty"`
+	Burst      int       `json:"burst,omitempty"`
+	DailyChars int64     `json:"daily_chars,omitempty"`
+	Endpoints  []string  `json:"endpoints,omitempty"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+	UsedToday  int64     `json:"used_today"`
+}
+
+// createKeyRequest 创建密钥请求体
+type createKeyRequest struct {
+	Name       string   `json:"name"`
+	Key        string   `json:"key"` // 可选，留空自动生成
+	RPS        float64  `json:"rps"`
+	Burst      int      `json:"burst"`
+	DailyChars int64    `json:"daily_chars"`
+	Endpoints  []string `json:"endpoints"`
+}
+
+// registerKeyRoutes 注册密钥管理接口，参数: 管理路由组，返回: 无
+func (s *Server) registerKeyRoutes(admin *echo.Group) {
+	if s.clientKeys == nil {
+		return
+	}
+	admin.GET("/keys", s.listKeysHandler)
+	admin.POST("/keys", s.createKeyHandler)
+	admin.DELETE("/keys/:name", s.revokeKeyHandler)
+}
+
+// listKeysHandler 列出全部客户端密钥及当日用量，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) listKeysHandler(c echo.Context) error {
+	keys := s.clientKeys.store.List()
+	views := make([]keyView, 0, len(keys))
+	for _, key := range keys {
+		view := newKeyView(key)
+		usage, err := s.clientKeys.usage.PeekLimit(c.Request().Context(), key.Identity(), key.DailyChars)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("key", key.Name).Msg("查询密钥用量失败")
+		}
+		view.UsedToday = usage.Used
+		views = append(views, view)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"keys": views})
+}
+
+// createKeyHandler 运行时创建客户端密钥，完整密钥只在响应中返回一次，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) createKeyHandler(c echo.Context) error {
+	var req createKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid key payload", err.Error())
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return BadRequest(c, ErrCodeMissingParameter, "missing required parameter: name")
+	}
+	if req.RPS < 0 || req.Burst < 0 || req.DailyChars < 0 {
+		return BadRequest(c, ErrCodeInvalidRequest, "rps, burst and daily_chars must not be negative")
+	}
+	for _, endpoint := range req.Endpoints {
+		if !strings.HasPrefix(endpoint, "/") {
+			return BadRequestWithDetails(c, ErrCodeInvalidRequest, "endpoints must start with /", endpoint)
+		}
+	}
+
+	key, err := s.clientKeys.store.Add(auth.APIKey{
+		Name:       req.Name,
+		Key:        strings.TrimSpace(req.Key),
+		RPS:        req.RPS,
+		Burst:      req.Burst,
+		DailyChars: req.DailyChars,
+		Endpoints:  req.Endpoints,
+		Source:     auth.KeySourceRuntime,
+	})
+	if errors.Is(err, auth.ErrDuplicateKey) {
+		return c.JSON(http.StatusConflict, NewAPIError(ErrCodeInvalidRequest, "key name or value already exists"))
+	}
+	if err != nil {
+		return InternalError(c, "failed to create key")
+	}
+
+	s.logger.Info().Str("key", key.Name).Str("ip", c.RealIP()).Msg("已创建客户端密钥")
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"key":  key.Key,
+		"info": newKeyView(key),
+	})
+}
+
+// revokeKeyHandler 吊销客户端密钥，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) revokeKeyHandler(c echo.Context) error {
+	name := c.Param("name")
+	if !s.clientKeys.revoke(name) {
+		return c.JSON(http.StatusNotFound, NewAPIError(ErrCodeInvalidRequest, "key not found"))
+	}
+	s.logger.Info().Str("key", name).Str("ip", c.RealIP()).Msg("已吊销客户端密钥")
+	return c.NoContent(http.StatusNoContent)
+}
+
+// newKeyView 构建密钥展示结构，参数: 密钥，返回: 展示结构
+func newKeyView(key auth.APIKey) keyView {
+	return keyView{
+		Name:       key.Name,
+		KeyHint:    key.Hint(),
+		RPS:        key.RPS,
+		Burst:      key.Burst,
+		DailyChars: key.DailyChars,
+		Endpoints:  key.Endpoints,
+		Source:     key.Source,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// languageResponse 单个语言的元数据，前端可用 direction 设置 dir 属性
+type languageResponse struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	NativeName  string `json:"native_name"`
+	DisplayName string `json:"display_name,omitempty"` // 查询参数 hl 指定的语言中的名称 (未传 hl 时省略)
+	Script      string `json:"script,omitempty"`
+	Direction   string `json:"direction"` // ltr 或 rtl
+}
+
+// languagesResponse 支持的语言列表响应
+type languagesResponse struct {
+	Languages []languageResponse `json:"languages"`
+	Any       bool               `json:"any"` // 默认提供商不限制语言时为 true (此时 languages 为空)
+}
+
+// languagesHandler 返回默认提供商支持的语言及其名称、文字与书写方向 (传 hl 时另附该语言下的名称)，参数: Echo 上下文，返回: 错误
+func (s *Server) languagesHandler(c echo.Context) error {
+	caps := s.translationService.Capabilities()
+	if len(caps.Languages) == 0 || slices.Contains(caps.Languages, deeplx.AnyLanguage) {
+		return c.JSON(http.StatusOK, languagesResponse{Languages: []languageResponse{}, Any: true})
+	}
+
+	codes := make([]string, 0, len(caps.Languages)+1)
+	for _, lang := range caps.Languages {
+		code := langutil.NormalizeLanguageCode(lang)
+		if langutil.IsChineseTarget(code) {
+			// 能力按主语言声明，中文的简繁两种变体都可用
+			codes = append(codes, "zh-CN", "zh-TW")
+			continue
+		}
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	codes = slices.Compact(codes)
+
+	hl := strings.TrimSpace(c.QueryParam("hl"))
+	languages := make([]languageResponse, 0, len(codes))
+	for _, code := range codes {
+		info := langutil.Info(code)
+		languages = append(languages, languageResponse{
+			Code:       info.Code,
+			Name:       info.Name,
+			NativeName: info.NativeName,
+			Script:     info.Script,
+			Direction:  info.Direction,
+		})
+		if hl != "" {
+			languages[len(languages)-1].DisplayName = langutil.DisplayName(code, hl)
+		}
+	}
+	return c.JSON(http.StatusOK, languagesResponse{Languages: languages})
+}
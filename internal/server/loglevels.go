@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/logging"
+)
+
+// logLevelsRequest 调整日志级别的请求体，未提供的字段保持不变
+type logLevelsRequest struct {
+	Levels      map[string]string `json:"levels"`
+	SampleDebug *uint32           `json:"sample_debug"`
+}
+
+// newLogLevels 根据配置构建组件日志级别，参数: 日志配置与基础日志器，返回: 组件级别与错误
+// 未单独设置的组件沿用基础日志器的级别；访问日志写入独立文件时默认记录全部请求
+func newLogLevels(cfg config.LoggingConfig, logger *zerolog.Logger) (*logging.Levels, error) {
+	levels := logging.NewLevels(logger.GetLevel())
+	if cfg.AccessLog.Enabled {
+		_ = levels.Set(logging.ComponentAccess, "trace")
+	}
+	for component, level := range cfg.Levels {
+		if err := levels.Set(component, level); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SampleDebug > 0 {
+		levels.SetSampling(uint32(cfg.SampleDebug))
+	}
+	return levels, nil
+}
+
+// logLevelsHandler 返回各组件当前日志级别与 debug 采样率，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) logLevelsHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"levels":       s.logLevels.Snapshot(),
+		"sample_debug": s.logLevels.Sampling(),
+	})
+}
+
+// updateLogLevelsHandler 运行时调整组件日志级别与 debug 采样率，参数: Echo 上下文，返回: 处理结果的错误
+// 先校验全部条目再应用，任一条目无效时不做任何修改；调整只在当前进程内生效，重启后恢复配置值
+func (s *Server) updateLogLevelsHandler(c echo.Context) error {
+	var req logLevelsRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid log level payload", err.Error())
+	}
+
+	for component, level := range req.Levels {
+		if !isLogComponent(component) {
+			return BadRequestWithDetails(c, ErrCodeInvalidRequest, "unknown log component", map[string]interface{}{
+				"component": component,
+				"supported": logging.Components,
+			})
+		}
+		if _, err := logging.ParseLevel(level); err != nil {
+			return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid log level", err.Error())
+		}
+	}
+	for component, level := range req.Levels {
+		_ = s.logLevels.Set(component, level)
+	}
+	if req.SampleDebug != nil {
+		s.logLevels.SetSampling(*req.SampleDebug)
+	}
+
+	s.logger.Info().
+		Interface("levels", s.logLevels.Snapshot()).
+		Uint32("sample_debug", s.logLevels.Sampling()).
+		Str("ip", c.RealIP()).
+		Msg("已调整日志级别")
+	return s.logLevelsHandler(c)
+}
+
+// isLogComponent 判断是否为可配置的日志组件，参数: 组件名称，返回: 布尔
+func isLogComponent(component string) bool {
+	for _, known := range logging.Components {
+		if known == component {
+			return true
+		}
+	}
+	return false
+}
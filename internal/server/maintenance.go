@@ -0,0 +1,124 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrCodeMaintenance 维护模式错误代码
+const ErrCodeMaintenance = "MAINTENANCE"
+
+// defaultMaintenanceMessage 未指定说明时返回给客户端的提示
+const defaultMaintenanceMessage = "service is under maintenance"
+
+// maintenanceState 维护模式开关，开启期间翻译接口返回 503，并发安全
+type maintenanceState struct {
+	mu         sync.RWMutex
+	enabled    bool
+	message    string
+	retryAfter int // 建议客户端重试间隔 (秒)
+	since      time.Time
+}
+
+// maintenanceView 维护状态的展示结构
+type maintenanceView struct {
+	Enabled    bool       `json:"enabled"`
+	Message    string     `json:"message,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+}
+
+// maintenanceRequest 切换维护模式的请求体
+type maintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after"` // 秒，<=0 时使用配置值
+}
+
+// newMaintenanceState 根据配置创建维护状态，参数: 无（使用接收者），返回: 维护状态
+func (s *Server) newMaintenanceState() *maintenanceState {
+	cfg := s.config.Server.Maintenance
+	m := &maintenanceState{}
+	if cfg.Enabled {
+		m.set(true, cfg.Message, cfg.GetRetryAfter())
+	}
+	return m
+}
+
+// set 切换维护模式，参数: 是否开启、说明与重试间隔 (秒)，返回: 无
+func (m *maintenanceState) set(enabled bool, message string, retryAfter int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled && !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled = enabled
+	m.message = message
+	m.retryAfter = retryAfter
+	if !enabled {
+		m.message, m.retryAfter, m.since = "", 0, time.Time{}
+	}
+}
+
+// view 返回当前维护状态，参数: 无，返回: 展示结构
+func (m *maintenanceState) view() maintenanceView {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v := maintenanceView{Enabled: m.enabled, Message: m.message, RetryAfter: m.retryAfter}
+	if m.enabled {
+		since := m.since
+		v.Since = &since
+	}
+	return v
+}
+
+// maintenanceGuard 维护期间拒绝翻译请求的路由中间件，参数: 下一个处理器，返回: 包装后的处理器
+func (s *Server) maintenanceGuard(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		state := s.maintenance.view()
+		if !state.Enabled {
+			return next(c)
+		}
+
+		message := state.Message
+		if message == "" {
+			message = defaultMaintenanceMessage
+		}
+		c.Response().Header().Set("Retry-After", strconv.Itoa(state.RetryAfter))
+		return c.JSON(http.StatusServiceUnavailable, NewAPIError(ErrCodeMaintenance, message).WithDetails(map[string]interface{}{
+			"retry_after": state.RetryAfter,
+		}))
+	}
+}
+
+// maintenanceHandler 返回维护状态，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) maintenanceHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.maintenance.view())
+}
+
+// updateMaintenanceHandler 开启或关闭维护模式，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) updateMaintenanceHandler(c echo.Context) error {
+	var req maintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid maintenance payload", err.Error())
+	}
+
+	retryAfter := req.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = s.config.Server.Maintenance.GetRetryAfter()
+	}
+	s.maintenance.set(req.Enabled, req.Message, retryAfter)
+
+	s.logger.Warn().
+		Bool("enabled", req.Enabled).
+		Str("message", req.Message).
+		Str("ip", c.RealIP()).
+		Msg("维护模式已切换")
+	return c.JSON(http.StatusOK, s.maintenance.view())
+}
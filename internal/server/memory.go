@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/tm"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// memoryStage 翻译前查找翻译记忆的管道阶段，命中时直接返回记忆中的译文，不调用下游
+// 位于脏词过滤之内、术语表之外：记忆中的译文已经人工确认，不再做术语替换
+type memoryStage struct {
+	memory   *tm.Memory
+	minScore float64
+}
+
+// newMemoryStage 根据配置构建翻译记忆阶段，参数: 翻译记忆配置与日志记录器，返回: 阶段 (未启用时为 nil) 与错误
+func newMemoryStage(cfg config.TranslationMemoryConfig, logger *zerolog.Logger) (*memoryStage, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	memory := tm.New()
+	if cfg.File != "" {
+		f, err := os.Open(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("读取翻译记忆文件失败: %w", err)
+		}
+		defer f.Close()
+		entries, err := tm.ReadTMX(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.File, err)
+		}
+		for _, entry := range entries {
+			memory.Add(entry)
+		}
+	}
+	logger.Info().Int("entries", memory.Len()).Float64("min_score", cfg.GetMinScore()).Msg("翻译记忆初始化完成")
+	return &memoryStage{memory: memory, minScore: cfg.GetMinScore()}, nil
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (m *memoryStage) Name() string {
+	return "memory"
+}
+
+// Process 命中翻译记忆时返回记忆中的译文，否则交给下游，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (m *memoryStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	match, ok := m.memory.Lookup(req.Source, req.Target, req.Text, m.minScore)
+	if !ok {
+		metrics.MemoryLookups.WithLabelValues("miss").Inc()
+		return next(ctx, req)
+	}
+	if match.Exact {
+		metrics.MemoryLookups.WithLabelValues("exact").Inc()
+	} else {
+		metrics.MemoryLookups.WithLabelValues("fuzzy").Inc()
+	}
+
+	src := req.Source
+	if src == "" || strings.EqualFold(src, "auto") {
+		src = langutil.NormalizeLanguageCode(match.Source)
+	}
+	return &translation.Response{
+		Src:       src,
+		Sentences: translation.AlignSentences(req.Text, match.TargetText),
+		Memory:    &translation.MemoryMatch{Score: match.Score, Exact: match.Exact},
+		Provider:  "memory",
+	}, nil
+}
+
+// registerMemoryRoutes 注册翻译记忆管理接口，参数: 管理接口路由组，返回: 无
+func (s *Server) registerMemoryRoutes(admin *echo.Group) {
+	if s.memory == nil {
+		return
+	}
+	admin.GET("/memory", s.memoryExportHandler)
+	admin.POST("/memory", s.memoryImportHandler)
+	admin.POST("/memory/entries", s.addMemoryEntryHandler)
+	admin.DELETE("/memory/entries", s.deleteMemoryEntryHandler)
+}
+
+// memoryExportHandler 以 TMX 导出全部翻译记忆，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) memoryExportHandler(c echo.Context) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/x-tmx+xml; charset=UTF-8")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="translation-memory.tmx"`)
+	res.WriteHeader(http.StatusOK)
+	return tm.WriteTMX(res, s.memory.memory.Entries())
+}
+
+// memoryImportHandler 导入请求体中的 TMX 文件，原文相同的条目被覆盖，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) memoryImportHandler(c echo.Context) error {
+	entries, err := tm.ReadTMX(c.Request().Body)
+	if err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid TMX document", err.Error())
+	}
+	added := 0
+	for _, entry := range entries {
+		if s.memory.memory.Add(entry) {
+			added++
+		}
+	}
+	s.logger.Info().Int("imported", len(entries)).Int("added", added).Str("ip", c.RealIP()).Msg("已导入翻译记忆")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"imported": len(entries),
+		"added":    added,
+		"entries":  s.memory.memory.Len(),
+	})
+}
+
+// addMemoryEntryHandler 添加一条确认过的句段对，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) addMemoryEntryHandler(c echo.Context) error {
+	var entry tm.Entry
+	if err := c.Bind(&entry); err != nil {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid memory entry", err.Error())
+	}
+	if strings.TrimSpace(entry.Source) == "" || strings.TrimSpace(entry.Target) == "" ||
+		strings.TrimSpace(entry.SourceText) == "" || strings.TrimSpace(entry.TargetText) == "" {
+		return BadRequest(c, ErrCodeMissingParameter, "source, target, source_text and target_text are required")
+	}
+	status := http.StatusOK
+	if s.memory.memory.Add(entry) {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, entry)
+}
+
+// deleteMemoryEntryHandler 删除一条翻译记忆，查询参数 source、target 与 text 指定条目，参数: Echo 上下文，返回: 处理结果的错误
+func (s *Server) deleteMemoryEntryHandler(c echo.Context) error {
+	if !s.memory.memory.Delete(c.QueryParam("source"), c.QueryParam("target"), c.QueryParam("text")) {
+		return c.JSON(http.StatusNotFound, NewAPIError(ErrCodeNotFound, "memory entry not found"))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
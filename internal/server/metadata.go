@@ -0,0 +1,54 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// 开启 server.metadata_headers 后在翻译响应中设置的元数据头
+const (
+	metadataProviderHeader = "X-Translate-Provider"
+	metadataModelHeader    = "X-Translate-Model"
+	metadataCacheHeader    = "X-Cache"            // HIT 或 MISS，仅在启用缓存时设置
+	metadataLatencyHeader  = "X-Upstream-Latency" // 上游调用耗时 (毫秒)，未调用上游时不设置
+)
+
+// setMetadataHeaders 设置译文来源的元数据头，参数: Echo 上下文、请求与翻译响应，返回: 无
+// 提供商头总是设置，响应未记录提供商时取请求指定的提供商，再退回默认提供商 (配置未命名时取服务名称)；
+// 模型为空 (提供商默认模型或未经上游) 时不设置。providerHeader 不受此开关影响，仍只在响应记录了提供商时设置
+func (s *Server) setMetadataHeaders(c echo.Context, req *pipeline.Request, resp *translation.Response) {
+	if !s.config.Server.MetadataHeaders {
+		return
+	}
+	header := c.Response().Header()
+
+	provider := resp.Provider
+	if provider == "" {
+		provider = req.Provider
+	}
+	if provider == "" {
+		defaultProvider := s.config.Translation.DefaultProvider()
+		provider = defaultProvider.GetName()
+	}
+	if provider == "" {
+		provider = s.providerName
+	}
+	header.Set(metadataProviderHeader, provider)
+	if resp.Model != "" {
+		header.Set(metadataModelHeader, resp.Model)
+	}
+	if s.config.Cache.Enabled {
+		cache := "MISS"
+		if resp.Cached {
+			cache = "HIT"
+		}
+		header.Set(metadataCacheHeader, cache)
+	}
+	if resp.UpstreamLatency > 0 {
+		header.Set(metadataLatencyHeader, strconv.FormatInt(resp.UpstreamLatency.Milliseconds(), 10))
+	}
+}
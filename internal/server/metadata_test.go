@@ -0,0 +1,35 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestMetadataHeaders 测试开启 metadata_headers 后总是设置 X-Translate-Provider，未开启时不设置元数据头，参数: 测试实例，返回: 无
+func TestMetadataHeaders(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		cfg := &config.Config{Server: config.ServerConfig{MetadataHeaders: enabled}}
+		s := newTestServer(t, cfg, &stubService{})
+		rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"Hello","tl":"de","model":"m1"}`))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("metadata_headers=%v: status = %d", enabled, rec.Code)
+		}
+		wantProvider, wantModel := "", ""
+		if enabled {
+			wantProvider = "stub" // 空配置的默认提供商没有名称，取服务名称
+			wantModel = "m1"
+		}
+		if got := rec.Header().Get("X-Translate-Provider"); got != wantProvider {
+			t.Errorf("metadata_headers=%v: X-Translate-Provider = %q, want %q", enabled, got, wantProvider)
+		}
+		if got := rec.Header().Get("X-Translate-Model"); got != wantModel {
+			t.Errorf("metadata_headers=%v: X-Translate-Model = %q, want %q", enabled, got, wantModel)
+		}
+		// 响应未记录提供商时不设置 X-Translation-Provider
+		if got := rec.Header().Get(providerHeader); got != "" {
+			t.Errorf("metadata_headers=%v: %s = %q, want 空", enabled, providerHeader, got)
+		}
+	}
+}
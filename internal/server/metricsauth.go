@@ -0,0 +1,46 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// metricsAuth /metrics 认证中间件，接受 Basic Auth 或 Bearer 令牌，未配置凭据时直接放行，参数: 下一个处理器，返回: 包装后的处理器
+func (s *Server) metricsAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	cfg := s.config.Metrics
+	if !cfg.AuthEnabled() {
+		return next
+	}
+
+	return func(c echo.Context) error {
+		if s.validMetricsCredentials(c) {
+			return next(c)
+		}
+		if cfg.BasicAuthEnabled() {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="metrics"`)
+		}
+		return c.JSON(http.StatusUnauthorized, NewAPIError(ErrCodeUnauthorized, "metrics credentials required"))
+	}
+}
+
+// validMetricsCredentials 校验请求是否带有 /metrics 的 Basic Auth 或 Bearer 令牌，参数: Echo 上下文，返回: 是否有效 (未配置凭据时为 false)
+func (s *Server) validMetricsCredentials(c echo.Context) bool {
+	cfg := &s.config.Metrics
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if token := strings.TrimSpace(cfg.Token); token != "" && strings.HasPrefix(header, "Bearer ") && secureEqual(strings.TrimPrefix(header, "Bearer "), token) {
+		return true
+	}
+	if cfg.BasicAuthEnabled() {
+		username, password, ok := c.Request().BasicAuth()
+		return ok && secureEqual(username, strings.TrimSpace(cfg.Username)) && secureEqual(password, cfg.Password)
+	}
+	return false
+}
+
+// secureEqual 以常量时间比较两个字符串，参数: 实际值与期望值，返回: 是否相等
+func secureEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
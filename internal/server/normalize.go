@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// normalizeStage 翻译前规范化原文的管道阶段，位于最外层，翻译记忆、术语表与缓存看到的都是规范化后的原文
+type normalizeStage struct {
+	opts langutil.NormalizeOptions
+}
+
+// newNormalizeStage 根据配置构建输入规范化阶段，参数: 输入规范化配置，返回: 阶段 (未开启任何一项时为 nil)
+func newNormalizeStage(cfg config.NormalizeConfig) *normalizeStage {
+	opts := langutil.NormalizeOptions{
+		NFC:                cfg.NFC,
+		StripZeroWidth:     cfg.StripZeroWidth,
+		StripEmoji:         cfg.GetEmoji() == config.EmojiStrip,
+		CollapseWhitespace: cfg.CollapseWhitespace,
+	}
+	if !opts.Enabled() {
+		return nil
+	}
+	return &normalizeStage{opts: opts}
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (n *normalizeStage) Name() string {
+	return "normalize"
+}
+
+// Process 规范化原文后交给下游，原文不变时直接透传请求，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (n *normalizeStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	text := langutil.NormalizeInput(req.Text, n.opts)
+	if text == req.Text {
+		return next(ctx, req)
+	}
+	normalized := *req
+	normalized.Text = text
+	return next(ctx, &normalized)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// newOpsEcho 创建运维端点使用的独立 Echo 实例，参数: 无（使用接收者），返回: Echo 实例 (未启用时为 nil)
+func (s *Server) newOpsEcho() *echo.Echo {
+	if !s.config.Server.Ops.Enabled {
+		return nil
+	}
+
+	ops := echo.New()
+	ops.HideBanner = true
+	ops.HidePort = true
+	ops.IPExtractor = s.echo.IPExtractor
+	ops.HTTPErrorHandler = s.httpErrorHandler
+	ops.Use(middleware.Recover())
+	ops.Use(requestIDMiddleware())
+	ops.Use(s.requestLogger())
+	return ops
+}
+
+// opsRouter 返回注册运维端点的 Echo 实例，参数: 无（使用接收者），返回: 独立监听或翻译端口的实例
+func (s *Server) opsRouter() *echo.Echo {
+	if s.ops != nil {
+		return s.ops
+	}
+	return s.echo
+}
+
+// startOps 启动运维端点监听，端口绑定同步完成以便尽早暴露配置错误，参数: 无（使用接收者），返回: 绑定失败的错误
+func (s *Server) startOps() error {
+	addr := s.config.Server.Ops.GetAddr()
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("运维端点监听 %s 失败: %w", addr, err)
+	}
+	s.ops.Listener = listener
+	s.logger.Info().Str("address", addr).Msg("运维端点已启动")
+
+	go func() {
+		if err := s.ops.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error().Err(err).Msg("运维端点运行失败")
+		}
+	}()
+	return nil
+}
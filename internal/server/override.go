@@ -0,0 +1,34 @@
+package server
+
+import (
+	"strings"
+)
+
+// resolveProviderOverride 校验请求指定的提供商，参数: 请求中的 provider 参数，返回: 提供商名称 (未指定或为默认提供商时为空) 与错误
+func (s *Server) resolveProviderOverride(requested string) (string, *APIError) {
+	name := strings.ToLower(strings.TrimSpace(requested))
+	if name == "" {
+		return "", nil
+	}
+	if !s.config.Translation.ProviderOverride.Enabled {
+		return "", NewAPIError(ErrCodeInvalidRequest, "provider override is not enabled")
+	}
+
+	defaultProvider := s.config.Translation.DefaultProvider()
+	allowed := s.config.Translation.OverrideProviders()
+	names := make([]string, 0, len(allowed))
+	for _, p := range allowed {
+		if p.GetName() == name {
+			// 默认提供商走默认路由，缓存键与未指定时一致
+			if name == defaultProvider.GetName() {
+				return "", nil
+			}
+			return name, nil
+		}
+		names = append(names, p.GetName())
+	}
+	return "", NewAPIError(ErrCodeInvalidRequest, "provider not allowed").WithDetails(map[string]interface{}{
+		"provider": requested,
+		"allowed":  names,
+	})
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/pipeline"
+)
+
+// prewarmClient 预热请求使用的客户端标识 (计入用量统计)
+const prewarmClient = "prewarm"
+
+// recordPreference 记录客户端本次使用的语言方向，参数: Echo 上下文、源语言、目标语言，返回: 无
+func (s *Server) recordPreference(c echo.Context, source, target string) {
+	if s.preferences == nil {
+		return
+	}
+	s.preferences.Record(clientIdentity(c), source, target)
+}
+
+// preferredTarget 获取客户端最常用的目标语言，参数: Echo 上下文，返回: 目标语言 (无偏好或未开启时为空)
+func (s *Server) preferredTarget(c echo.Context) string {
+	if s.preferences == nil || !s.config.Preferences.AutoTarget {
+		return ""
+	}
+	target, _ := s.preferences.PreferredTarget(clientIdentity(c))
+	return target
+}
+
+// prewarmRoute 一个已预热的路由：提供商与语言方向
+type prewarmRoute struct {
+	provider string
+	source   string
+	target   string
+}
+
+// prewarmer 按所有客户端最常用的语言方向定期预热路由与熔断状态，在 Start 中启动
+// 每个语言方向按路由规则选出提供商：首次出现的路由发送一次预热请求建立连接；
+// 提供商的熔断器进入半开时再次发送，由预热请求代替用户请求完成探测
+type prewarmer struct {
+	server   *Server
+	interval time.Duration
+	pairs    int
+	text     string
+	logger   *zerolog.Logger
+
+	mu     sync.Mutex
+	warmed map[prewarmRoute]bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newPrewarmer 根据配置创建预热器，参数: 预热配置，返回: 预热器 (未启用或未记录偏好时为 nil)
+func (s *Server) newPrewarmer(cfg config.PrewarmConfig) *prewarmer {
+	if !cfg.Enabled || s.preferences == nil {
+		return nil
+	}
+	return &prewarmer{
+		server:   s,
+		interval: cfg.GetInterval(),
+		pairs:    cfg.GetPairs(),
+		text:     cfg.GetText(),
+		logger:   s.providerLog,
+		warmed:   make(map[prewarmRoute]bool),
+	}
+}
+
+// Start 在后台定期预热，参数: 无，返回: 无
+func (p *prewarmer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.warmAll(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止预热并等待进行中的预热结束，参数: 无，返回: 无
+func (p *prewarmer) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// warmAll 预热最常用的语言方向中需要预热的路由，参数: 上下文，返回: 发送的预热请求数
+func (p *prewarmer) warmAll(ctx context.Context) int {
+	s := p.server
+	sent := 0
+	for _, pair := range s.preferences.Popular(p.pairs) {
+		if ctx.Err() != nil {
+			break
+		}
+		provider, model, _ := s.matchRoute(p.text, pair.Source, pair.Target, nil)
+		route := prewarmRoute{provider: s.routedProviderName(provider), source: pair.Source, target: pair.Target}
+		if !p.needsWarm(route) {
+			continue
+		}
+		defaultModel := model == ""
+		if provider != "" {
+			model = s.config.Translation.ResolveModel(provider, model)
+		} else {
+			model = s.config.Translation.ResolveModel(s.config.Translation.ServiceType, model)
+		}
+		sent++
+		_, err := s.pipeline.Run(ctx, &pipeline.Request{
+			Text:     p.text,
+			Source:   pair.Source,
+			Target:   pair.Target,
+			DT:       []string{"t"},
+			Model:    model,
+			Client:   prewarmClient,
+			Provider: provider,
+			Cache:    pipeline.CacheNoStore,
+
+			DefaultModel: defaultModel,
+		})
+		if err != nil {
+			p.logger.Debug().Err(err).Str("provider", route.provider).Str("sl", pair.Source).Str("tl", pair.Target).Msg("预热请求失败")
+			continue
+		}
+		p.mu.Lock()
+		p.warmed[route] = true
+		p.mu.Unlock()
+		p.logger.Debug().Str("provider", route.provider).Str("sl", pair.Source).Str("tl", pair.Target).Msg("已预热路由")
+	}
+	return sent
+}
+
+// needsWarm 判断路由是否需要预热：尚未预热过，或提供商的熔断器处于半开，参数: 路由，返回: 布尔
+// 熔断器仍在断开冷却期时不发送，避免预热请求被直接拒绝
+func (p *prewarmer) needsWarm(route prewarmRoute) bool {
+	state := breaker.StateClosed
+	if b := p.server.providerBreaker(route.provider); b != nil {
+		state = b.State()
+	}
+	switch state {
+	case breaker.StateHalfOpen:
+		return true
+	case breaker.StateOpen:
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.warmed[route]
+}
+
+// providerBreaker 查找提供商的熔断器，名称不区分大小写，默认提供商按底层服务名称查找，参数: 提供商名称，返回: 熔断器 (未启用熔断时为 nil)
+func (s *Server) providerBreaker(name string) *breaker.Breaker {
+	defaultProvider := s.config.Translation.DefaultProvider()
+	if strings.EqualFold(name, defaultProvider.GetName()) {
+		name = s.providerName
+	}
+	for _, b := range s.upstream.breakers {
+		if strings.EqualFold(b.Name(), name) {
+			return b
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestPrewarm 测试预热只对尚未预热的路由与半开的熔断器发送请求，成功的预热请求使熔断器恢复闭合，参数: 测试实例，返回: 无
+func TestPrewarm(t *testing.T) {
+	svc := &stubService{}
+	cfg := &config.Config{
+		Preferences: config.PreferencesConfig{Enabled: true, Prewarm: config.PrewarmConfig{Enabled: true, Pairs: 1, Text: "warm"}},
+		Translation: config.TranslationConfig{CircuitBreaker: config.CircuitBreakerConfig{Enabled: true, OpenDuration: "20ms"}},
+	}
+	s := newTestServer(t, cfg, svc)
+	s.preferences.Record("client-a", "en", "de")
+	s.preferences.Record("client-b", "en", "de")
+	s.preferences.Record("client-b", "ja", "fr") // 不在最常用的 1 个语言方向内
+
+	ctx := context.Background()
+	if sent := s.prewarm.warmAll(ctx); sent != 1 {
+		t.Fatalf("首次预热发送 %d 个请求, want 1", sent)
+	}
+	if got := strings.Join(svc.calls, ","); got != "warm" {
+		t.Errorf("upstream calls = %s", got)
+	}
+	if sent := s.prewarm.warmAll(ctx); sent != 0 {
+		t.Errorf("已预热的路由再次发送 %d 个请求", sent)
+	}
+
+	b := s.providerBreaker("")
+	if b == nil {
+		t.Fatal("未找到默认提供商的熔断器")
+	}
+	b.Trip()
+	if sent := s.prewarm.warmAll(ctx); sent != 0 {
+		t.Errorf("熔断冷却期内发送 %d 个请求, want 0", sent)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if state := b.State(); state != breaker.StateHalfOpen {
+		t.Fatalf("state = %v, want half-open", state)
+	}
+	if sent := s.prewarm.warmAll(ctx); sent != 1 {
+		t.Errorf("半开时发送 %d 个请求, want 1", sent)
+	}
+	if state := b.State(); state != breaker.StateClosed {
+		t.Errorf("预热成功后 state = %v, want closed", state)
+	}
+}
+
+// TestPrewarmDisabled 测试未启用语言偏好或预热时不创建预热器，参数: 测试实例，返回: 无
+func TestPrewarmDisabled(t *testing.T) {
+	cfg := &config.Config{Preferences: config.PreferencesConfig{Enabled: true}}
+	if s := newTestServer(t, cfg, &stubService{}); s.prewarm != nil {
+		t.Error("未启用预热时不应创建预热器")
+	}
+}
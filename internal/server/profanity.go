@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/profanity"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// ErrCodeContentRejected 译文被脏词过滤拒绝时的错误代码
+const ErrCodeContentRejected = "CONTENT_REJECTED"
+
+// profanityFilteredHeader 译文经过脏词过滤时设置的响应头
+const profanityFilteredHeader = "X-Profanity-Filtered"
+
+// profanityStage 按目标语言词表过滤译文的管道阶段，位于最外层，过滤的是术语还原后的最终译文
+type profanityStage struct {
+	filter *profanity.Filter
+	mode   string
+}
+
+// newProfanityStage 根据配置构建脏词过滤阶段，参数: 过滤配置与日志记录器，返回: 阶段 (未启用时为 nil) 与错误
+func newProfanityStage(cfg config.ProfanityConfig, logger *zerolog.Logger) (*profanityStage, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	lists := make(map[string][]string, len(cfg.Words))
+	for lang, words := range cfg.Words {
+		lists[lang] = append(lists[lang], words...)
+	}
+	if cfg.File != "" {
+		fileLists, err := profanity.LoadFile(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		for lang, words := range fileLists {
+			lists[lang] = append(lists[lang], words...)
+		}
+	}
+	logger.Info().Int("languages", len(lists)).Str("mode", cfg.GetMode()).Msg("脏词过滤初始化完成")
+	return &profanityStage{filter: profanity.New(lists), mode: cfg.GetMode()}, nil
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (p *profanityStage) Name() string {
+	return "profanity"
+}
+
+// Process 调用下游后过滤译文，拒绝模式下命中即返回 profanity.ErrRejected，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (p *profanityStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if p.mode == config.ProfanityModeReject {
+		for _, sentence := range resp.Sentences {
+			if p.filter.Contains(sentence.Trans, req.Target) {
+				metrics.ProfanityFiltered.WithLabelValues(p.mode).Inc()
+				return nil, fmt.Errorf("目标语言 %s: %w", req.Target, profanity.ErrRejected)
+			}
+		}
+		return resp, nil
+	}
+
+	// 下游响应可能被缓存共享，在副本上掩码
+	var out *translation.Response
+	for i, sentence := range resp.Sentences {
+		masked, hits := p.filter.Mask(sentence.Trans, req.Target)
+		if hits == 0 {
+			continue
+		}
+		if out == nil {
+			copied := *resp
+			copied.Sentences = append([]translation.Sentence(nil), resp.Sentences...)
+			copied.ProfanityFiltered = true
+			out = &copied
+		}
+		out.Sentences[i].Trans = masked
+	}
+	if out == nil {
+		return resp, nil
+	}
+	metrics.ProfanityFiltered.WithLabelValues(p.mode).Inc()
+	return out, nil
+}
+
+// contentRejectedResponse 返回译文被拒绝的 422 响应，参数: Echo 上下文，返回: 写入响应的错误
+func contentRejectedResponse(c echo.Context) error {
+	return c.JSON(http.StatusUnprocessableEntity, NewAPIError(ErrCodeContentRejected, "translation rejected by profanity filter"))
+}
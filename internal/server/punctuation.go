@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// punctuationStage 目标语言为中文时把译文中的半角标点转换为全角的管道阶段
+// 位于同语言短路与后处理规则之内、术语表之外：规范化的是术语还原后的译文，原样返回的原文与翻译记忆中人工确认的译文不做改动
+type punctuationStage struct {
+	spacing bool
+}
+
+// newPunctuationStage 根据配置构建标点规范化阶段，参数: 标点规范化配置，返回: 阶段 (未启用时为 nil)
+func newPunctuationStage(cfg config.PunctuationConfig) *punctuationStage {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &punctuationStage{spacing: cfg.CJKSpacing}
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (p *punctuationStage) Name() string {
+	return "punctuation"
+}
+
+// Process 调用下游后规范化中文译文的标点，其他目标语言直接透传，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (p *punctuationStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil || !langutil.IsChineseTarget(req.Target) {
+		return resp, err
+	}
+
+	// 下游响应可能被缓存共享，在副本上修改
+	var out *translation.Response
+	for i, sentence := range resp.Sentences {
+		normalized := langutil.NormalizeChinesePunctuation(sentence.Trans, p.spacing)
+		if normalized == sentence.Trans {
+			continue
+		}
+		if out == nil {
+			copied := *resp
+			copied.Sentences = append([]translation.Sentence(nil), resp.Sentences...)
+			out = &copied
+		}
+		out.Sentences[i].Trans = normalized
+	}
+	if out == nil {
+		return resp, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/quota"
+)
+
+// HeaderQuotaWarning 额度即将用尽时返回的响应头
+const HeaderQuotaWarning = "X-Quota-Warning"
+
+// clientIdentity 获取用于额度与偏好统计的客户端标识，参数: Echo 上下文，返回: 客户端标识
+// 已认证的请求按密钥名称或 JWT 用户统计，否则按 IP 统计
+func clientIdentity(c echo.Context) string {
+	if identity := authenticatedIdentity(c); identity != "" {
+		return identity
+	}
+	return c.RealIP()
+}
+
+// newQuotaStore 创建额度计数存储，参数: 无（使用接收者），返回: 存储实现
+// 有 Redis 时计数持久化并在多实例间共享，否则退回进程内计数
+func (s *Server) newQuotaStore() quota.Store {
+	if redisCache, ok := s.cache.(*cache.RedisCache); ok {
+		return quota.NewRedisStore(redisCache.Client())
+	}
+	return quota.NewMemoryStore()
+}
+
+// quotaReservation 请求预留的字符额度 (客户端额度与密钥额度，未启用的为 nil)
+type quotaReservation struct {
+	client *quota.Reservation
+	key    *quota.Reservation
+}
+
+// reserveQuota 在调用上游之前预留本次请求的字符数，超额时退还并返回错误，参数: Echo 上下文与字符数，返回: 预留与超额时的错误 (未超额为 nil)
+// 预留先累加再判断，并发请求不会越过额度；接近额度的预警头在写出响应之前设置
+func (s *Server) reserveQuota(c echo.Context, chars int64) (*quotaReservation, *APIError) {
+	reservation := &quotaReservation{}
+	var apiErr *APIError
+	reservation.client, apiErr = s.reserveClientQuota(c, chars)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	reservation.key, apiErr = s.reserveKeyQuota(c, chars)
+	if apiErr != nil {
+		s.releaseQuota(c, reservation, chars)
+		return nil, apiErr
+	}
+	return reservation, nil
+}
+
+// releaseQuota 退还预留中未使用的字符数 (翻译失败或流式请求中止)，参数: Echo 上下文、预留与字符数，返回: 无
+func (s *Server) releaseQuota(c echo.Context, reservation *quotaReservation, chars int64) {
+	if reservation == nil || chars <= 0 {
+		return
+	}
+	// 请求可能已被取消，退还不受请求上下文约束
+	ctx := context.WithoutCancel(c.Request().Context())
+	for _, r := range []*quota.Reservation{reservation.client, reservation.key} {
+		if r == nil {
+			continue
+		}
+		if err := r.Release(ctx, chars); err != nil {
+			s.logger.Warn().Err(err).Str("client", clientIdentity(c)).Msg("退还字符额度失败")
+		}
+	}
+}
+
+// reserveClientQuota 预留客户端当日字符额度并在接近额度时添加预警头，参数: Echo 上下文与字符数，返回: 预留 (未启用或计数存储不可用时为 nil) 与超额时的错误
+// 只有启用强制额度时才拒绝，超额时设置 Retry-After 为距额度重置 (UTC 零点) 的秒数
+func (s *Server) reserveClientQuota(c echo.Context, chars int64) (*quota.Reservation, *APIError) {
+	if s.quota == nil || chars <= 0 {
+		return nil, nil
+	}
+
+	client := clientIdentity(c)
+	reservation, err := s.quota.Reserve(c.Request().Context(), client, chars, s.config.Quota.DailyChars)
+	if err != nil {
+		// 计数存储不可用时放行，避免额度系统故障导致整体不可用
+		s.logger.Warn().Err(err).Str("client", client).Msg("记录字符额度失败")
+		return nil, nil
+	}
+
+	usage := reservation.Usage
+	if s.config.Quota.Enforce && usage.Exceeded(0) {
+		if err := reservation.Release(c.Request().Context(), chars); err != nil {
+			s.logger.Warn().Err(err).Str("client", client).Msg("退还字符额度失败")
+		}
+		resetIn := int(math.Ceil(s.quota.ResetIn().Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(resetIn))
+		return nil, NewAPIError(ErrCodeQuotaExceeded, "daily character quota exceeded").WithDetails(map[string]interface{}{
+			"used":        usage.Used - chars,
+			"limit":       usage.Limit,
+			"retry_after": resetIn,
+		})
+	}
+
+	if usage.NearLimit(s.config.Quota.GetWarnPercent()) {
+		c.Response().Header().Set(HeaderQuotaWarning, fmt.Sprintf("remaining=%d; limit=%d", usage.Remaining(), usage.Limit))
+	}
+	return reservation, nil
+}
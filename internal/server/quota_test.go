@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// TestQuotaConcurrentRequests 测试并发请求不会越过强制额度，参数: 测试实例，返回: 无
+func TestQuotaConcurrentRequests(t *testing.T) {
+	svc := &stubService{translate: func(ctx context.Context, q string) (*translation.Response, error) {
+		time.Sleep(10 * time.Millisecond) // 让请求在上游调用期间重叠
+		return &translation.Response{Sentences: []translation.Sentence{{Orig: q, Trans: q}}}, nil
+	}}
+	cfg := &config.Config{Quota: config.QuotaConfig{Enabled: true, DailyChars: 50, Enforce: true}}
+	s := newTestServer(t, cfg, svc)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		statuses = map[int]int{}
+	)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 不同原文，避免请求合并
+			rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"abcdefghi`+string(rune('A'+i))+`","tl":"de"}`))
+			mu.Lock()
+			statuses[rec.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if statuses[http.StatusOK] != 5 || statuses[http.StatusTooManyRequests] != 15 {
+		t.Errorf("statuses = %v, want 5 个 200 与 15 个 429", statuses)
+	}
+	if got := quotaUsed(t, s); got != 50 {
+		t.Errorf("quota used = %d, want 50", got)
+	}
+}
+
+// TestQuotaRefundOnFailure 测试上游失败时退还预留的额度，参数: 测试实例，返回: 无
+func TestQuotaRefundOnFailure(t *testing.T) {
+	svc := &stubService{translate: func(ctx context.Context, q string) (*translation.Response, error) {
+		return nil, deeplx.ErrTranslationFailed
+	}}
+	s := newTestServer(t, quotaConfig(), svc)
+
+	rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"Hello","tl":"de"}`))
+	if rec.Code == http.StatusOK {
+		t.Fatalf("status = %d, want 错误", rec.Code)
+	}
+	if got := quotaUsed(t, s); got != 0 {
+		t.Errorf("quota used = %d, want 0", got)
+	}
+}
+
+// TestQuotaWarningOnStream 测试流式响应在写出响应头之前设置额度预警头，超额时返回 429 而不是流，参数: 测试实例，返回: 无
+func TestQuotaWarningOnStream(t *testing.T) {
+	cfg := &config.Config{Quota: config.QuotaConfig{Enabled: true, DailyChars: 10, Enforce: true, WarnPercent: 50}}
+	s := newTestServer(t, cfg, &stubService{})
+
+	rec := serve(s, jsonRequest(http.MethodPost, "/translate_a/single?stream=1", `{"q":["abc","def"],"tl":"de"}`))
+	if got := rec.Header().Get(HeaderQuotaWarning); got != "remaining=4; limit=10" {
+		t.Errorf("%s = %q", HeaderQuotaWarning, got)
+	}
+
+	rec = serve(s, jsonRequest(http.MethodPost, "/translate_a/single?stream=1", `{"q":["abc","def"],"tl":"de"}`))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, Retry-After = %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if got := quotaUsed(t, s); got != 6 {
+		t.Errorf("quota used = %d, want 6 (被拒绝的请求不计入)", got)
+	}
+}
+
+// TestKeyQuota 测试密钥额度同样先预留再调用上游，超额时不调用上游，参数: 测试实例，返回: 无
+func TestKeyQuota(t *testing.T) {
+	svc := &stubService{}
+	cfg := &config.Config{Auth: config.AuthConfig{
+		Enabled: true,
+		Keys:    []config.ClientKeyConfig{{Name: "ide", Key: "key-ide", DailyChars: 8}},
+	}}
+	s := newTestServer(t, cfg, svc)
+
+	send := func(q string) int {
+		req := jsonRequest(http.MethodPost, "/translate_a/single", `{"q":"`+q+`","tl":"de"}`)
+		req.Header.Set(HeaderAPIKey, "key-ide")
+		return serve(s, req).Code
+	}
+	if code := send("Hello"); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if code := send("World"); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", code)
+	}
+	if code := send("Hi"); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 (被拒绝的请求已退还)", code)
+	}
+	if got := strings.Join(svc.calls, ","); got != "Hello,Hi" {
+		t.Errorf("upstream calls = %s", got)
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newIPExtractor 构建客户端 IP 提取器，参数: 无（使用接收者），返回: 提取器与错误
+// 未配置可信代理时直接使用连接对端地址，忽略客户端可伪造的转发头；
+// 配置后只在 X-Forwarded-For 链路上越过可信代理，取第一个不可信的地址
+func (s *Server) newIPExtractor() (echo.IPExtractor, error) {
+	prefixes, err := s.config.Server.GetTrustedProxies()
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// 关闭 Echo 默认信任的回环、链路本地与私有网段，只信任显式配置的代理
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, prefix := range prefixes {
+		_, ipNet, err := net.ParseCIDR(prefix.String())
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
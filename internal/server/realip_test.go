@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// TestIPExtractor 测试客户端 IP 只在可信代理链路上采信 X-Forwarded-For，参数: 测试实例，返回: 无
+func TestIPExtractor(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     string
+		want    string
+	}{
+		{name: "未配置可信代理时忽略伪造的转发头", remote: "203.0.113.9:5000", xff: "1.2.3.4", want: "203.0.113.9"},
+		{name: "未配置可信代理时私有网段对端同样忽略转发头", remote: "10.0.0.2:5000", xff: "1.2.3.4", want: "10.0.0.2"},
+		{name: "对端不可信", trusted: []string{"10.0.0.0/8"}, remote: "203.0.113.9:5000", xff: "1.2.3.4", want: "203.0.113.9"},
+		{name: "回环地址默认不可信", trusted: []string{"10.0.0.0/8"}, remote: "127.0.0.1:5000", xff: "1.2.3.4", want: "127.0.0.1"},
+		{name: "单跳可信代理", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:5000", xff: "198.51.100.7", want: "198.51.100.7"},
+		{name: "多跳可信代理链", trusted: []string{"10.0.0.0/8", "192.168.1.1"}, remote: "10.0.0.2:5000", xff: "198.51.100.7, 192.168.1.1, 10.0.0.5", want: "198.51.100.7"},
+		{name: "中间有不可信跳时取该跳", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:5000", xff: "1.1.1.1, 203.0.113.50, 10.0.0.5", want: "203.0.113.50"},
+		{name: "可信代理未附加转发头", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:5000", want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{config: &config.Config{Server: config.ServerConfig{TrustedProxies: tt.trusted}}}
+			extract, err := s.newIPExtractor()
+			if err != nil {
+				t.Fatalf("newIPExtractor() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := extract(req); got != tt.want {
+				t.Errorf("IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
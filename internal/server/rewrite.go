@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/rewrite"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// rewriteStage 按配置的正则规则改写译文的管道阶段
+// 位于标点规范化之外，规则看到的是最终译文；与标点规范化一样不改动同语言短路与翻译记忆的结果
+type rewriteStage struct {
+	rewriter *rewrite.Rewriter
+}
+
+// newRewriteStage 根据配置构建后处理规则阶段，参数: 后处理规则配置与日志记录器，返回: 阶段 (未启用时为 nil) 与错误
+func newRewriteStage(cfg config.RewriteConfig, logger *zerolog.Logger) (*rewriteStage, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	rules := make([]rewrite.Rule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rules = append(rules, rewrite.Rule{Source: rule.Source, Target: rule.Target, Pattern: rule.Pattern, Replace: rule.Replace})
+	}
+	rewriter, err := rewrite.New(rules)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info().Int("rules", rewriter.Len()).Msg("译文后处理规则初始化完成")
+	return &rewriteStage{rewriter: rewriter}, nil
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (r *rewriteStage) Name() string {
+	return "rewrite"
+}
+
+// Process 调用下游后按语言对应用规则，自动检测时按检测到的源语言匹配，参数: 上下文、请求与下游处理器，返回: 译文与错误
+func (r *rewriteStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	source := req.Source
+	if (source == "" || strings.EqualFold(source, "auto")) && resp.Src != "" {
+		source = resp.Src
+	}
+
+	// 下游响应可能被缓存共享，在副本上修改
+	var out *translation.Response
+	for i, sentence := range resp.Sentences {
+		rewritten, applied := r.rewriter.Apply(source, req.Target, sentence.Trans)
+		if applied == 0 {
+			continue
+		}
+		if out == nil {
+			copied := *resp
+			copied.Sentences = append([]translation.Sentence(nil), resp.Sentences...)
+			out = &copied
+		}
+		out.Sentences[i].Trans = rewritten
+	}
+	if out == nil {
+		return resp, nil
+	}
+	metrics.RewriteApplied.Inc()
+	return out, nil
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+)
+
+// translateParams 翻译端点共用的请求参数，语言别名已解析
+type translateParams struct {
+	q        string
+	sl       string
+	tl       string
+	dt       []string // 为空时只返回翻译文本
+	model    string   // 请求指定的模型 (可为空)
+	provider string   // 请求指定的提供商 (可为空)
+	noCache  bool     // 请求参数 nocache
+}
+
+// resolveTranslateRequest 选择提供商与模型并检查语言限制，构建管道请求，单句与文档端点 (含流式) 共用，参数: Echo 上下文与请求参数，返回: 管道请求或 400 错误
+// 请求未指定提供商时按路由规则选择，都不匹配时按金丝雀分流；规则或分流指定的模型优先于提供商默认模型
+func (s *Server) resolveTranslateRequest(c echo.Context, p translateParams) (*pipeline.Request, *APIError) {
+	provider, apiErr := s.resolveProviderOverride(p.provider)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	log := s.requestLog(c)
+	model := p.model
+	if strings.TrimSpace(p.provider) == "" {
+		routedProvider, routedModel, rule := s.routeRequest(p.q, p.sl, p.tl, p.dt)
+		if rule != "" {
+			provider = routedProvider
+			if strings.TrimSpace(model) == "" {
+				model = routedModel
+			}
+			log.Debug().Str("rule", rule).Str("provider", provider).Str("model", model).Msg("请求匹配路由规则")
+		} else if canaryProvider, canaryModel, ok := s.canaryRoute(clientIdentity(c)); ok {
+			provider = canaryProvider
+			if strings.TrimSpace(model) == "" {
+				model = canaryModel
+			}
+			log.Debug().Str("provider", provider).Str("model", model).Msg("请求分入金丝雀提供商")
+		}
+	}
+
+	// 如果请求中没有指定模型，依次使用提供商默认模型与全局默认模型
+	defaultModel := strings.TrimSpace(model) == ""
+	if provider != "" {
+		model = s.config.Translation.ResolveModel(provider, model)
+	} else {
+		model = s.config.Translation.ResolveModel(s.config.Translation.ServiceType, model)
+	}
+
+	// 语言限制在缓存与上游调用之前检查
+	policy := &s.config.Translation.LanguagePolicy
+	if !policy.AllowsSource(p.sl) {
+		return nil, NewAPIError(ErrCodeUnsupportedLang, "source language not allowed").WithDetails(map[string]interface{}{
+			"sl": p.sl,
+		})
+	}
+	if !policy.AllowsTarget(p.tl) {
+		return nil, NewAPIError(ErrCodeUnsupportedLang, "target language not allowed").WithDetails(map[string]interface{}{
+			"tl": p.tl,
+		})
+	}
+
+	dt := p.dt
+	if len(dt) == 0 {
+		// 默认只返回翻译文本
+		dt = []string{"t"}
+	}
+	return &pipeline.Request{
+		Text:   p.q,
+		Source: p.sl,
+		Target: p.tl,
+		DT:     dt,
+		Model:  model,
+
+		DefaultModel: defaultModel,
+		Client:       clientIdentity(c),
+		Provider:     provider,
+		Cache:        requestCacheDirective(c, p.noCache),
+	}, nil
+}
+
+// routeRequest 按路由规则为未指定提供商的请求选择提供商与模型，参数: 文本、源语言、目标语言与 dt，返回: 提供商名称 (默认提供商为空)、规则指定的模型与规则名称 (未匹配时均为空)
+func (s *Server) routeRequest(q, sl, tl string, dt []string) (provider, model, rule string) {
+	provider, model, rule = s.matchRoute(q, sl, tl, dt)
+	if rule != "" {
+		metrics.RoutingMatches.WithLabelValues(rule, s.routedProviderName(provider)).Inc()
+	}
+	return provider, model, rule
+}
+
+// matchRoute 按路由规则选择提供商与模型，不记录指标 (预热也使用)，参数: 文本、源语言、目标语言与 dt，返回: 同 routeRequest
+func (s *Server) matchRoute(q, sl, tl string, dt []string) (provider, model, rule string) {
+	routing := &s.config.Translation.Routing
+	i, ok := routing.Match(routingSource(q, sl), tl, utf8.RuneCountInString(q), dt)
+	if !ok {
+		return "", "", ""
+	}
+	matched := routing.Rules[i]
+	p, _ := s.config.Translation.FindProvider(matched.Provider)
+	rule = matched.GetName(i)
+
+	provider = p.GetName()
+	defaultProvider := s.config.Translation.DefaultProvider()
+	if provider == defaultProvider.GetName() {
+		// 默认提供商走默认路由 (故障转移、对冲与批量合并照常生效)，缓存键与未匹配时一致
+		provider = ""
+	}
+	return provider, strings.TrimSpace(matched.Model), rule
+}
+
+// routedProviderName 返回路由结果对应的提供商名称，参数: 路由选出的提供商 (默认提供商为空)，返回: 提供商名称
+func (s *Server) routedProviderName(provider string) string {
+	if provider == "" {
+		defaultProvider := s.config.Translation.DefaultProvider()
+		return defaultProvider.GetName()
+	}
+	return provider
+}
+
+// canaryRoute 按客户端分桶决定请求是否分给金丝雀提供商，参数: 客户端身份，返回: 提供商名称、模型与是否分流
+// 未启用时不记录指标；启用时分流与未分流的请求分别计入 canary 与 control 组
+func (s *Server) canaryRoute(client string) (provider, model string, ok bool) {
+	canary := &s.config.Translation.Canary
+	if !canary.Enabled {
+		return "", "", false
+	}
+	if !canary.Includes(client) {
+		defaultProvider := s.config.Translation.DefaultProvider()
+		metrics.CanaryRequests.WithLabelValues("control", defaultProvider.GetName()).Inc()
+		return "", "", false
+	}
+	p, _ := s.config.Translation.FindProvider(canary.Provider)
+	metrics.CanaryRequests.WithLabelValues("canary", p.GetName()).Inc()
+	return p.GetName(), strings.TrimSpace(canary.Model), true
+}
+
+// routingSource 确定路由使用的源语言，参数: 文本与请求的源语言，返回: 源语言代码 (无法确定时为空)
+// 自动检测时只采用可靠的本地检测结果 (CJK 文字或 trigram 置信度足够高)，短文本等无法可靠判断的不匹配限定源语言的规则
+func routingSource(q, sl string) string {
+	if trimmed := strings.TrimSpace(sl); trimmed != "" && !strings.EqualFold(trimmed, "auto") {
+		return trimmed
+	}
+	if detected := langutil.Detect(q); detected.Reliable {
+		return detected.Language
+	}
+	return ""
+}
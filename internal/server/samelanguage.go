@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// sameLanguageStage 源语言与目标语言相同时直接返回原文的管道阶段
+// 位于译文缓存之外，短路的请求既不调用上游也不写缓存
+type sameLanguageStage struct {
+	detected func(ctx context.Context, text string) string // 查询检测缓存 (未启用检测缓存时为 nil)
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (s *sameLanguageStage) Name() string {
+	return "same_language"
+}
+
+// Process 能确定源语言且与目标语言相同时返回原文，否则交给下游，参数: 上下文、请求与下游处理器，返回: 译文与错误
+// 自动检测时依次参考检测缓存 (之前上游的检测结果)、专属文字 (假名、谚文、泰文) 与简繁专用字，不使用会误判的首字符启发式
+func (s *sameLanguageStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	source, from := req.Source, "request"
+	if source == "" || strings.EqualFold(source, "auto") {
+		source, from = "", ""
+		if s.detected != nil {
+			source, from = s.detected(ctx, req.Text), "detection_cache"
+		}
+		if source == "" {
+			source, from = langutil.DetectByScript(req.Text), "script"
+		}
+		if source == "" && langutil.DetectScript(req.Text) == langutil.ScriptHan {
+			// 汉字文本按简繁专用字区分，繁体译为简体 (或反之) 的请求照常交给上游
+			source = langutil.ChineseVariant(req.Text)
+		}
+	}
+	if source == "" || langutil.NormalizeLanguageCode(source) != langutil.NormalizeLanguageCode(req.Target) {
+		return next(ctx, req)
+	}
+
+	metrics.SameLanguageSkipped.WithLabelValues(from).Inc()
+	return &translation.Response{
+		Src:       langutil.NormalizeLanguageCode(source),
+		Sentences: translation.AlignSentences(req.Text, req.Text),
+		Provider:  "same_language",
+	}, nil
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// segmentConcurrency 多段文本 (HTML 文本节点或多个 q) 同时翻译的段数
+const segmentConcurrency = 4
+
+// segmentLimit 返回多段文本的并发数，启用批量合并时放宽到每批段数以便同批发出，参数: 无，返回: 并发数
+func (s *Server) segmentLimit() int {
+	if batching := s.config.Translation.Batching; batching.Enabled {
+		return max(segmentConcurrency, batching.GetMaxSize())
+	}
+	return segmentConcurrency
+}
+
+// translateSegments 逐段经管道翻译 (享有缓存、术语表与批量合并等阶段)，相同文本只翻译一次，参数: 上下文、请求模板与文本列表，返回: 与文本一一对应的响应 (可能为 nil) 与错误
+// 任一段失败则整体失败
+func (s *Server) translateSegments(ctx context.Context, req *pipeline.Request, texts []string) ([]*translation.Response, error) {
+	positions := make(map[string][]int, len(texts))
+	var unique []string
+	for i, text := range texts {
+		if _, ok := positions[text]; !ok {
+			unique = append(unique, text)
+		}
+		positions[text] = append(positions[text], i)
+	}
+
+	// 每个不同文本只由一个 goroutine 写入其对应位置，无需加锁
+	responses := make([]*translation.Response, len(texts))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.segmentLimit())
+	for _, text := range unique {
+		g.Go(func() error {
+			segmentReq := *req
+			segmentReq.Text = text
+			resp, err := s.pipeline.Run(gctx, &segmentReq)
+			if err != nil {
+				return err
+			}
+			for _, i := range positions[text] {
+				responses[i] = resp
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// joinTrans 拼接响应中各句译文，参数: 响应，返回: 译文
+func joinTrans(resp *translation.Response) string {
+	var trans strings.Builder
+	for _, sentence := range resp.Sentences {
+		trans.WriteString(sentence.Trans)
+	}
+	return trans.String()
+}
+
+// firstDetected 返回第一个有检测结果的源语言，参数: 响应列表与默认值，返回: 语言代码
+func firstDetected(responses []*translation.Response, fallback string) string {
+	for _, resp := range responses {
+		if resp != nil && resp.Src != "" {
+			return resp.Src
+		}
+	}
+	return fallback
+}
+
+// lastProvider 返回最后一个非空的提供商名称，参数: 响应列表，返回: 提供商名称
+func lastProvider(responses []*translation.Response) string {
+	provider := ""
+	for _, resp := range responses {
+		if resp != nil && resp.Provider != "" {
+			provider = resp.Provider
+		}
+	}
+	return provider
+}
+
+// lastModel 返回最后一个记录了模型的响应的模型，参数: 响应列表，返回: 模型名称 (均未记录时为空)
+func lastModel(responses []*translation.Response) string {
+	model := ""
+	for _, resp := range responses {
+		if resp != nil && resp.Model != "" {
+			model = resp.Model
+		}
+	}
+	return model
+}
+
+// allCached 判断是否全部响应都来自缓存，参数: 响应列表，返回: 布尔
+func allCached(responses []*translation.Response) bool {
+	cached := false
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if !resp.Cached {
+			return false
+		}
+		cached = true
+	}
+	return cached
+}
+
+// maxLatency 返回各段上游耗时的最大值 (各段并发翻译)，参数: 响应列表，返回: 耗时
+func maxLatency(responses []*translation.Response) time.Duration {
+	var latency time.Duration
+	for _, resp := range responses {
+		if resp != nil {
+			latency = max(latency, resp.UpstreamLatency)
+		}
+	}
+	return latency
+}
+
+// anyFiltered 判断是否有响应经过脏词过滤，参数: 响应列表，返回: 布尔
+func anyFiltered(responses []*translation.Response) bool {
+	for _, resp := range responses {
+		if resp != nil && resp.ProfanityFiltered {
+			return true
+		}
+	}
+	return false
+}
+
+// translateMulti 翻译多个 q 段，每段对应响应中的一个句子，参数: 上下文、请求模板与文本段，返回: 合并后的响应与错误
+func (s *Server) translateMulti(ctx context.Context, req *pipeline.Request, segments []string) (*translation.Response, error) {
+	responses, err := s.translateSegments(ctx, req, segments)
+	if err != nil {
+		return nil, err
+	}
+	sentences := make([]translation.Sentence, len(segments))
+	for i, segment := range segments {
+		// 上游返回空响应的段保留原文
+		sentences[i] = translation.Sentence{Orig: segment, Trans: segment}
+		if responses[i] != nil {
+			sentences[i].Trans = joinTrans(responses[i])
+		}
+	}
+	return &translation.Response{
+		Src:               firstDetected(responses, req.Source),
+		Sentences:         sentences,
+		ProfanityFiltered: anyFiltered(responses),
+		Provider:          lastProvider(responses),
+		Model:             lastModel(responses),
+		Cached:            allCached(responses),
+		UpstreamLatency:   maxLatency(responses),
+	}, nil
+}
+
+// translateLines 逐行翻译多行纯文本，换行、空行与缩进原样保留，每个非空行对应响应中的一个句子，参数: 上下文与请求，返回: 翻译响应与错误
+func (s *Server) translateLines(ctx context.Context, req *pipeline.Request) (*translation.Response, error) {
+	lines := translation.ParseLines(req.Text)
+	texts := lines.Texts()
+	responses, err := s.translateSegments(ctx, req, texts)
+	if err != nil {
+		return nil, err
+	}
+	translated := append([]string(nil), texts...) // 上游返回空响应的行保留原文
+	for i, resp := range responses {
+		if resp != nil {
+			translated[i] = joinTrans(resp)
+		}
+	}
+	return &translation.Response{
+		Src:               firstDetected(responses, req.Source),
+		Sentences:         lines.Sentences(translated),
+		ProfanityFiltered: anyFiltered(responses),
+		Provider:          lastProvider(responses),
+		Model:             lastModel(responses),
+		Cached:            allCached(responses),
+		UpstreamLatency:   maxLatency(responses),
+	}, nil
+}
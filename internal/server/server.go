@@ -1,36 +1,80 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/labstack/echo-contrib/echoprometheus"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/XgzK/translate-services/internal/breaker"
 	"github.com/XgzK/translate-services/internal/cache"
 	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/langpref"
+	"github.com/XgzK/translate-services/internal/logging"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/profanity"
+	"github.com/XgzK/translate-services/internal/quota"
+	"github.com/XgzK/translate-services/internal/ratelimit"
+	"github.com/XgzK/translate-services/internal/requestid"
 	"github.com/XgzK/translate-services/internal/translation"
 	"github.com/XgzK/translate-services/internal/translator/deeplx"
+	"github.com/XgzK/translate-services/internal/usage"
 )
 
 // Server 服务器结构 (封装翻译服务喵～)
 type Server struct {
 	echo               *echo.Echo
-	translationService deeplx.TranslationService
+	ops                *echo.Echo                // 运维端点独立监听 (未启用时为 nil，端点注册在 echo 上)
+	translationService deeplx.TranslationService // 底层翻译提供商
+	detector           deeplx.TranslationService // 语言检测服务 (启用检测缓存时带缓存)
+	pipeline           *pipeline.Pipeline        // 翻译请求管道 (缓存等阶段 → 提供商)
+	background         *pipeline.Background      // 管道后台任务 (缓存回写等)，停机时排空
 	config             *config.Config
 	logger             *zerolog.Logger
+	accessLog          *zerolog.Logger // 访问日志 (未单独写文件时与 logger 相同)
+	accessLogFile      io.Closer       // 访问日志文件 (未启用时为 nil)
+	providerLog        *zerolog.Logger // 翻译请求与提供商调用日志
+	logLevels          *logging.Levels // 按组件的日志级别，可通过管理接口调整
 	startedAt          time.Time
-	cache              cache.Cache // 可选的缓存实例
+	cache              cache.Cache     // 可选的缓存实例
+	cacheStats         *cacheStats     // 缓存命中率与条目统计 (未启用缓存时为 nil)
+	upstream           *upstreamLimits // 上游限制、熔断器与最近调用结果，用于 /healthz?verbose=1
+	providerName       string          // 底层翻译提供商名称 (不含缓存包装前缀)
+	quota              *quota.Tracker
+	usage              *usageStage    // 上游用量统计 (未启用时为 nil)
+	pricing            *usage.Pricing // 各提供商与模型的价格，试运行据此估算费用 (未配置价格时为空表)
+	shadow             *shadowStage   // 影子流量 (未启用时为 nil)，停机时关闭对比数据集文件
+	memory             *memoryStage   // 翻译记忆 (未启用时为 nil)
+	preferences        *langpref.Tracker
+	adminAuthn         *adminAuthenticator // 管理后台登录与会话 (未启用登录时为 nil)
+	accessControl      *accessControl      // 按 IP 访问控制 (未启用时为 nil)
+	banList            *ratelimit.BanList  // 按 IP 自动临时封禁 (未启用时为 nil)
+	rateLimiters       *rateLimiters       // 按 IP 限流器 (未启用限流时为 nil)
+	clientKeys         *clientKeys         // 客户端密钥 (未启用认证时为 nil)
+	compressor         *compressor         // 响应压缩 (未启用时为 nil)
+	maintenance        *maintenanceState   // 维护模式开关
+	comparison         *pipeline.Provider  // 对比提供商 (未启用对比模式时为 nil)
+	health             *healthMonitor      // 提供商后台健康检查 (未启用时为 nil)
+	prewarm            *prewarmer          // 按常用语言方向预热路由与熔断状态 (未启用时为 nil)
 }
 
 type Dependencies struct {
 	TranslationService deeplx.TranslationService
+	Cache              cache.Cache // 可选：启用缓存时代替按配置连接的 Redis (测试使用)
 }
 
 type translateRequest struct {
@@ -39,6 +83,57 @@ type translateRequest struct {
 	TL    string   `json:"tl"`
 	DT    []string `json:"dt"`
 	Model string   `json:"model,omitempty"` // 可选：指定翻译模型
+
+	Format string `json:"format,omitempty"` // 可选：text (默认)、html 或 markdown，html 只翻译文本节点，markdown 不翻译代码
+
+	Formality string `json:"formality,omitempty"` // 可选：语气 (more/less/prefer_more/prefer_less/formal/informal)
+	Tone      string `json:"tone,omitempty"`      // 可选：formality 的别名
+
+	Compare bool `json:"compare,omitempty"` // 可选：对比模式，同时返回对比提供商的译文与相似度
+
+	Provider string `json:"provider,omitempty"` // 可选：指定提供商 (需启用 translation.provider_override)
+
+	DryRun bool `json:"dry_run,omitempty"` // 可选：试运行，只返回路由与缓存信息
+
+	NoCache bool `json:"nocache,omitempty"` // 可选：不读缓存强制重新翻译，结果仍写入缓存 (同 Cache-Control: no-cache)
+
+	PreserveLines bool `json:"preserve_lines,omitempty"` // 可选：逐行翻译，保留换行、空行与缩进
+
+	Segments []string `json:"-"` // 多段文本 (JSON 中 q 为数组或表单中 q 重复出现)，每段对应响应中的一个句子
+}
+
+// UnmarshalJSON 解析 JSON 请求体，q 可以是字符串或字符串数组，参数: JSON 数据，返回: 错误
+func (r *translateRequest) UnmarshalJSON(data []byte) error {
+	type plain translateRequest
+	aux := struct {
+		*plain
+		Q json.RawMessage `json:"q"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Q) == 0 || bytes.Equal(aux.Q, []byte("null")) {
+		return nil
+	}
+	if aux.Q[0] != '[' {
+		return json.Unmarshal(aux.Q, &r.Q)
+	}
+	var segments []string
+	if err := json.Unmarshal(aux.Q, &segments); err != nil {
+		return err
+	}
+	r.setSegments(segments)
+	return nil
+}
+
+// setSegments 设置多段文本，只有一段时等同于单个 q，参数: 文本段，返回: 无
+func (r *translateRequest) setSegments(segments []string) {
+	if len(segments) == 1 {
+		r.Q = segments[0]
+		return
+	}
+	r.Segments = segments
+	r.Q = strings.Join(segments, "\n")
 }
 
 // New 构建服务器，参数: 配置、日志器、依赖注入，返回: 初始化好的 Server 或错误
@@ -52,7 +147,15 @@ func New(cfg *config.Config, logger *zerolog.Logger, deps *Dependencies) (*Serve
 		logger = &nop
 	}
 
-	service, err := selectTranslationService(cfg, deps)
+	logLevels, err := newLogLevels(cfg.Logging, logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化日志级别失败: %w", err)
+	}
+	cacheLog := logLevels.Logger(logger, logging.ComponentCache)
+
+	limits := newUpstreamLimits(cfg)
+	limits.health = newHealthMonitor(cfg.Translation.HealthCheck, logLevels.Logger(logger, logging.ComponentProvider))
+	service, err := selectTranslationService(cfg, deps, limits)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +166,16 @@ func New(cfg *config.Config, logger *zerolog.Logger, deps *Dependencies) (*Serve
 		logger.Info().Str("provider", service.GetName()).Msg("翻译服务初始化完成")
 	}
 
+	providerName := service.GetName()
+	service = withCircuitBreaker(cfg.Translation.CircuitBreaker, limits, providerName, service)
+	service = withHealthcheck(limits, providerName, service)
+	service = withBulkhead(limits, providerName, service)
+
 	// 初始化缓存（如果启用）
 	var cacheInstance cache.Cache
-	if cfg.Cache.Enabled {
+	if cfg.Cache.Enabled && deps != nil && deps.Cache != nil {
+		cacheInstance = deps.Cache
+	} else if cfg.Cache.Enabled {
 		redisCache, err := cache.NewRedisCache(cache.RedisConfig{
 			Addr:         cfg.Cache.Addr,
 			Password:     cfg.Cache.Password,
@@ -85,27 +195,192 @@ func New(cfg *config.Config, logger *zerolog.Logger, deps *Dependencies) (*Serve
 				Dur("ttl", cfg.Cache.GetTTL()).
 				Bool("share_across_services", cfg.Cache.ShareAcrossServices).
 				Msg("Redis 缓存初始化完成")
+		}
+	}
 
-			// 包装翻译服务，添加缓存功能 (修复: 传入 logger 保持日志一致性喵～)
-			service = cache.NewCachedTranslationService(service, cacheInstance, cache.CachedServiceConfig{
-				TTL:                 cfg.Cache.GetTTL(),
-				Enabled:             true,
-				ShareAcrossServices: cfg.Cache.ShareAcrossServices,
-			}, cache.WithLogger(logger))
-			logger.Info().Str("provider", service.GetName()).Msg("翻译服务已启用缓存")
+	// 组装请求管道：输入规范化 → 脏词过滤 → 翻译记忆 → 同语言短路 → 后处理规则 → 标点规范化 → 术语表 → 译文缓存 → 检测缓存 → 提供商语言检测 → 并发隔离 → 影子流量 → 用量统计 → 提供商
+	background := pipeline.NewBackground(logger)
+	var stages []pipeline.Stage
+	var detection *cache.DetectionCachingService
+	if cacheInstance != nil && cfg.Cache.CacheDetection {
+		detection = cache.NewDetectionCachingService(service, cacheInstance, cfg.Cache.GetDetectionTTL(), cacheLog)
+	}
+	if normalize := newNormalizeStage(cfg.Translation.Normalize); normalize != nil {
+		stages = append(stages, normalize)
+	}
+	profanityStage, err := newProfanityStage(cfg.Translation.Profanity, logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化脏词过滤失败: %w", err)
+	}
+	if profanityStage != nil {
+		stages = append(stages, profanityStage)
+	}
+	memoryStage, err := newMemoryStage(cfg.Translation.Memory, logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化翻译记忆失败: %w", err)
+	}
+	if memoryStage != nil {
+		stages = append(stages, memoryStage)
+	}
+	if cfg.Translation.SkipSameLanguage {
+		sameLanguage := &sameLanguageStage{}
+		if detection != nil {
+			sameLanguage.detected = detection.Detected
+		}
+		stages = append(stages, sameLanguage)
+	}
+	rewriteStage, err := newRewriteStage(cfg.Translation.Rewrite, logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化译文后处理规则失败: %w", err)
+	}
+	if rewriteStage != nil {
+		stages = append(stages, rewriteStage)
+	}
+	if punctuation := newPunctuationStage(cfg.Translation.Punctuation); punctuation != nil {
+		stages = append(stages, punctuation)
+	}
+	glossaryStage, err := newGlossaryStage(cfg.Translation.Glossary, cacheInstance, logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化术语表失败: %w", err)
+	}
+	if glossaryStage != nil {
+		stages = append(stages, glossaryStage)
+	}
+	var cacheStatistics *cacheStats
+	if cacheInstance != nil {
+		cacheStatistics = newCacheStats(cacheInstance, cfg.Cache.GetStatsInterval(), cacheLog)
+		stages = append(stages, cache.NewCachedTranslationService(service, cacheInstance, cache.CachedServiceConfig{
+			TTL:                 cfg.Cache.GetTTL(),
+			Enabled:             true,
+			ShareAcrossServices: cfg.Cache.ShareAcrossServices,
+		}, cache.WithLogger(cacheLog), cache.WithLookupObserver(cacheStatistics.observe)))
+
+		// 单独缓存检测语言，位于译文缓存之下，译文缓存未命中或被跳过时仍可复用
+		if detection != nil {
+			stages = append(stages, detection)
 		}
 	}
+	// 语言检测接口既供 /detect 使用，也在源语言为 auto 时由检测阶段先确定源语言
+	var detector deeplx.TranslationService = service
+	if detection != nil {
+		detector = detection
+	}
+	if service.Capabilities().SupportsDetection {
+		stages = append(stages, &detectStage{detector: detector})
+	}
+	// 并发隔离放在缓存之后，缓存命中不占用上游并发槽位
+	if global := newBulkheadStage(cfg.Server.Concurrency); global != nil {
+		stages = append(stages, global)
+	}
+	var usageStage *usageStage
+	if cfg.Usage.Enabled {
+		usageStage = newUsageStage(cfg.Usage, cacheInstance, providerName, logger)
+		limits.budgets = newBudgetTracker(cfg.Usage, usageStage.ledger, background, logger)
+		usageStage.budgets = limits.budgets
+	}
+	shadow, err := newShadowStage(cfg, limits, providerName, usageStage, logLevels.Logger(logger, logging.ComponentProvider))
+	if err != nil {
+		return nil, err
+	}
+	if shadow != nil {
+		stages = append(stages, shadow)
+	}
+	if usageStage != nil {
+		stages = append(stages, usageStage)
+	}
+	terminal, err := newTerminalHandler(cfg, limits, withBudget(limits, providerName, service))
+	if err != nil {
+		return nil, err
+	}
+	if terminal, err = withProviderRouting(cfg, limits, terminal); err != nil {
+		return nil, err
+	}
+	terminal = withBudgetFallback(cfg, limits, terminal)
+	comparison, err := newComparisonProvider(cfg, limits)
+	if err != nil {
+		return nil, err
+	}
+	translatePipeline := pipeline.New(terminal,
+		pipeline.WithStages(stages...),
+		pipeline.WithTimeout(time.Duration(cfg.Server.GetRequestTimeout())*time.Second),
+		pipeline.WithTimeoutFunc(requestTimeoutFunc(cfg)),
+		pipeline.WithBackground(background),
+	)
+	logger.Info().Strs("stages", translatePipeline.Stages()).Msg("翻译管道初始化完成")
 
 	e := echo.New()
 
 	s := &Server{
 		echo:               e,
 		translationService: service,
+		detector:           detector,
+		pipeline:           translatePipeline,
+		background:         background,
 		config:             cfg,
 		logger:             logger,
+		accessLog:          logger,
+		providerLog:        logLevels.Logger(logger, logging.ComponentProvider),
+		logLevels:          logLevels,
 		startedAt:          time.Now(),
 		cache:              cacheInstance,
+		cacheStats:         cacheStatistics,
+		providerName:       providerName,
+		health:             limits.health,
+		upstream:           limits,
+		comparison:         comparison,
+		usage:              usageStage,
+		pricing:            newPricing(cfg.Usage),
+		shadow:             shadow,
+		memory:             memoryStage,
+	}
+
+	if cfg.Quota.Enabled && cfg.Quota.DailyChars > 0 {
+		s.quota = quota.NewTracker(s.newQuotaStore(), cfg.Quota.DailyChars)
+	}
+	if cfg.Preferences.Enabled {
+		s.preferences = langpref.NewTracker(cfg.Preferences.MaxClients)
+	}
+	s.prewarm = s.newPrewarmer(cfg.Preferences.Prewarm)
+	if cfg.Admin.LoginEnabled() {
+		authn, err := s.newAdminAuthenticator()
+		if err != nil {
+			return nil, fmt.Errorf("初始化管理员认证失败: %w", err)
+		}
+		s.adminAuthn = authn
+	}
+	if accessCfg := cfg.Logging.AccessLog; accessCfg.Enabled {
+		s.accessLog, s.accessLogFile = logging.NewAccessLog(logging.AccessLogOptions{
+			File:       accessCfg.GetFile(),
+			MaxSizeMB:  accessCfg.GetMaxSizeMB(),
+			MaxAgeDays: accessCfg.MaxAgeDays,
+			MaxBackups: accessCfg.MaxBackups,
+			Compress:   accessCfg.Compress,
+			Service:    "deeplx-server",
+		})
+		logger.Info().Str("file", accessCfg.GetFile()).Msg("访问日志写入独立文件")
+	}
+	s.accessLog = logLevels.Logger(s.accessLog, logging.ComponentAccess)
+	ipExtractor, err := s.newIPExtractor()
+	if err != nil {
+		return nil, fmt.Errorf("初始化可信代理失败: %w", err)
+	}
+	e.IPExtractor = ipExtractor
+	e.HTTPErrorHandler = s.httpErrorHandler
+	accessControl, err := s.newAccessControl()
+	if err != nil {
+		return nil, fmt.Errorf("初始化访问控制失败: %w", err)
+	}
+	s.accessControl = accessControl
+	s.banList = s.newBanList()
+	s.rateLimiters = s.newRateLimiters()
+	clientKeys, err := s.newClientKeys()
+	if err != nil {
+		return nil, fmt.Errorf("初始化客户端认证失败: %w", err)
 	}
+	s.clientKeys = clientKeys
+	s.ops = s.newOpsEcho()
+	s.compressor = s.newCompressor()
+	s.maintenance = s.newMaintenanceState()
 
 	s.configureMiddleware()
 	s.registerRoutes()
@@ -113,8 +388,8 @@ func New(cfg *config.Config, logger *zerolog.Logger, deps *Dependencies) (*Serve
 	return s, nil
 }
 
-// selectTranslationService 选择翻译服务，参数: 配置和测试依赖，返回: 翻译服务实例或错误
-func selectTranslationService(cfg *config.Config, deps *Dependencies) (deeplx.TranslationService, error) {
+// selectTranslationService 选择翻译服务，参数: 配置、测试依赖与上游限制，返回: 翻译服务实例或错误
+func selectTranslationService(cfg *config.Config, deps *Dependencies, limits *upstreamLimits) (deeplx.TranslationService, error) {
 	if deps != nil && deps.TranslationService != nil {
 		return deps.TranslationService, nil
 	}
@@ -124,11 +399,27 @@ func selectTranslationService(cfg *config.Config, deps *Dependencies) (deeplx.Tr
 	if strings.TrimSpace(serviceType) == "" {
 		serviceType = string(deeplx.ServiceTypeDeepLX)
 	}
+	defaultProvider := cfg.Translation.DefaultProvider()
 	service, err := factory.CreateService(
 		deeplx.ServiceType(strings.ToLower(serviceType)),
 		&deeplx.TranslationServiceConfig{
-			APIKey:  cfg.Translation.APIKey,
-			BaseURL: cfg.Translation.BaseURL,
+			APIKey:        cfg.Translation.APIKey,
+			BaseURL:       cfg.Translation.BaseURL,
+			APIKeys:       upstreamKeys(cfg.Translation.KeyRotation),
+			KeyStrategy:   deeplx.KeyStrategy(cfg.Translation.KeyRotation.Strategy),
+			KeyQuarantine: cfg.Translation.KeyRotation.GetQuarantine(),
+			KeyPattern:    cfg.Translation.KeyPattern,
+			Languages:     cfg.Translation.Capabilities.Languages,
+			MaxTextLength: cfg.Translation.Capabilities.MaxTextLength,
+
+			BaseURLs:        cfg.Translation.Endpoints.URLs,
+			EndpointOptions: endpointOptions(defaultProvider.GetName(), cfg.Translation.Endpoints),
+			AdaptiveTimeout: adaptiveTimeout(cfg.Translation.AdaptiveTimeout),
+			RetryBudget:     limits.retryBudget,
+			OnUpstreamCall:  limits.observeUpstreamCall(defaultProvider.GetName()),
+			Prompt:          deeplx.PromptTemplate{System: defaultProvider.Prompt.System, User: defaultProvider.Prompt.User},
+			// 故障转移、对冲与熔断需要感知失败，否则失败时返回原文
+			FailOnError: cfg.Translation.Failover.Enabled || cfg.Translation.Hedging.Enabled || cfg.Translation.CircuitBreaker.Enabled,
 		},
 	)
 	if err != nil {
@@ -137,13 +428,90 @@ func selectTranslationService(cfg *config.Config, deps *Dependencies) (deeplx.Tr
 	return service, nil
 }
 
+// upstreamKeys 将多密钥轮换配置转换为适配层的密钥列表，参数: 轮换配置，返回: 带权重的密钥列表
+func upstreamKeys(k config.KeyRotationConfig) []deeplx.WeightedKey {
+	keys := make([]deeplx.WeightedKey, 0, len(k.Keys))
+	for _, key := range k.Keys {
+		keys = append(keys, deeplx.WeightedKey{Key: key.Key, Weight: key.Weight})
+	}
+	return keys
+}
+
 // Start 启动服务器，参数: 监听地址字符串，返回: 启动失败的错误
+// 启用 server.tls 时在该地址上提供 HTTPS；启用 server.ops 时同时启动运维端点监听
 func (s *Server) Start(addr string) error {
-	return s.echo.Start(addr)
+	if s.health != nil {
+		s.health.Start()
+	}
+	if s.cacheStats != nil {
+		s.cacheStats.Start()
+	}
+	if s.prewarm != nil {
+		s.prewarm.Start()
+	}
+	if s.ops != nil {
+		if err := s.startOps(); err != nil {
+			return err
+		}
+	}
+
+	tlsCfg := s.config.Server.TLS
+	if !tlsCfg.Enabled {
+		return s.echo.Start(addr)
+	}
+
+	tlsConfig, err := newTLSConfig(tlsCfg)
+	if err != nil {
+		return err
+	}
+	if tlsCfg.ACME.Enabled {
+		s.logger.Info().Strs("hosts", tlsCfg.ACME.Hosts).Str("cache_dir", tlsCfg.ACME.GetCacheDir()).Msg("已启用 ACME 自动证书")
+	}
+	server := s.echo.TLSServer
+	server.Addr = addr
+	server.TLSConfig = tlsConfig
+	return s.echo.StartServer(server)
 }
 
 // Shutdown 优雅关闭服务器，参数: 上下文，用于超时控制，返回: 关闭时的错误
+// 依次停止接收请求、等待进行中的翻译 (含已超时响应但仍在调用上游的请求)、排空管道后台任务 (缓存回写等)、关闭运维端点与缓存连接
+// 缓存连接最后关闭，确保排空期间的缓存回写仍可写入
 func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.echo.Shutdown(ctx)
+	if s.prewarm != nil {
+		s.prewarm.Stop()
+	}
+
+	if bgErr := s.background.Shutdown(ctx); bgErr != nil {
+		s.logger.Warn().Err(bgErr).Int64("in_flight", s.background.InFlight()).Msg("等待翻译请求与后台任务结束超时，剩余任务已取消")
+	}
+
+	if s.health != nil {
+		s.health.Stop()
+	}
+	if s.cacheStats != nil {
+		s.cacheStats.Stop()
+	}
+
+	// 运维端点最后关闭，排空期间仍可观察指标
+	if s.ops != nil {
+		if opsErr := s.ops.Shutdown(ctx); opsErr != nil {
+			s.logger.Warn().Err(opsErr).Msg("关闭运维端点失败")
+		}
+	}
+
+	if s.shadow != nil {
+		if err := s.shadow.Close(); err != nil {
+			s.logger.Warn().Err(err).Msg("关闭影子流量数据集文件失败")
+		}
+	}
+
+	if s.accessLogFile != nil {
+		if err := s.accessLogFile.Close(); err != nil {
+			s.logger.Warn().Err(err).Msg("关闭访问日志文件失败")
+		}
+	}
+
 	// 关闭缓存连接
 	if s.cache != nil {
 		if err := s.cache.Close(); err != nil {
@@ -152,13 +520,17 @@ func (s *Server) Shutdown(ctx context.Context) error {
 			s.logger.Info().Msg("缓存连接已关闭")
 		}
 	}
-	return s.echo.Shutdown(ctx)
+	return err
 }
 
 // translateHandler 处理翻译请求，参数: Echo 上下文，返回: 处理结果的错误
 func (s *Server) translateHandler(c echo.Context) error {
 	clientIP := c.RealIP()
+	log := s.requestLog(c)
 	payload, err := s.decodeTranslateRequest(c)
+	if isBodyTooLarge(err) {
+		return err
+	}
 	if err != nil {
 		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "invalid request payload", err.Error())
 	}
@@ -171,71 +543,175 @@ func (s *Server) translateHandler(c echo.Context) error {
 
 	sl := payload.SL
 	tl := payload.TL
-	dt := payload.DT
-	model := payload.Model
-
-	// 如果请求中没有指定模型，使用配置文件中的默认模型
-	if model == "" && s.config.Translation.Model != "" {
-		model = s.config.Translation.Model
+	if strings.TrimSpace(tl) == "" {
+		tl = s.preferredTarget(c)
 	}
-
 	if strings.TrimSpace(tl) == "" {
 		return BadRequest(c, ErrCodeMissingParameter, "missing required parameter: tl")
 	}
 
-	if len(dt) == 0 {
-		// 默认只返回翻译文本
-		dt = []string{"t"}
+	req, apiErr := s.resolveTranslateRequest(c, translateParams{
+		q:        q,
+		sl:       sl,
+		tl:       tl,
+		dt:       payload.DT,
+		model:    payload.Model,
+		provider: payload.Provider,
+		noCache:  payload.NoCache,
+	})
+	if apiErr != nil {
+		return c.JSON(http.StatusBadRequest, apiErr)
+	}
+
+	format := strings.ToLower(strings.TrimSpace(payload.Format))
+	if format != "" && format != formatText && format != formatHTML && format != formatMarkdown {
+		return BadRequestWithDetails(c, ErrCodeUnsupportedFormat, "unsupported format", map[string]interface{}{
+			"format":    payload.Format,
+			"supported": []string{formatText, formatHTML, formatMarkdown},
+		})
+	}
+	structured := format == formatHTML || format == formatMarkdown
+	formality, ok := deeplx.NormalizeFormality(payload.Formality)
+	if !ok {
+		return BadRequestWithDetails(c, ErrCodeInvalidRequest, "unsupported formality", map[string]interface{}{
+			"formality": payload.Formality,
+			"supported": []string{deeplx.FormalityDefault, deeplx.FormalityMore, deeplx.FormalityLess, deeplx.FormalityPreferMore, deeplx.FormalityPreferLess, "formal", "informal"},
+		})
+	}
+	if structured && len(payload.Segments) > 0 {
+		return BadRequest(c, ErrCodeInvalidRequest, "format="+format+" does not support multiple q segments")
+	}
+	stream := wantsStream(c)
+	if payload.PreserveLines && (structured || len(payload.Segments) > 0 || payload.Compare || stream) {
+		return BadRequest(c, ErrCodeInvalidRequest, "preserve_lines only supports a single plain-text q")
+	}
+	if stream && (structured || payload.Compare) {
+		return BadRequest(c, ErrCodeInvalidRequest, "stream mode only supports plain-text q segments")
+	}
+	if payload.Compare {
+		if s.comparison == nil {
+			return BadRequest(c, ErrCodeInvalidRequest, "comparison mode is not enabled")
+		}
+		if structured || len(payload.Segments) > 0 {
+			return BadRequest(c, ErrCodeInvalidRequest, "comparison mode only supports a single plain-text q")
+		}
 	}
 
 	// 调试日志：记录请求参数
-	logEvent := s.logger.Debug().
+	logEvent := log.Debug().
 		Str("handler", "translate_single").
 		Str("ip", clientIP).
 		Str("sl", sl).
 		Str("tl", tl).
-		Int("dt_count", len(dt))
+		Int("dt_count", len(req.DT))
 
-	if model != "" {
-		logEvent.Str("model", model)
+	if req.Model != "" {
+		logEvent.Str("model", req.Model)
 	}
 	logEvent.Msg("收到翻译请求")
 
-	// 调用真实的翻译服务 (浮浮酱的核心改进喵～)，为外部调用增加超时
-	requestTimeout := time.Duration(s.config.Server.GetRequestTimeout()) * time.Second
-	ctx, cancel := context.WithTimeout(c.Request().Context(), requestTimeout)
-	defer cancel()
+	// 经由请求管道调用真实的翻译服务 (浮浮酱的核心改进喵～)，超时与取消由管道统一控制
+	req.Formality = formality
+	if payload.DryRun {
+		return s.handleDryRun(c, "translate_single", req)
+	}
+	if stream {
+		segments := payload.Segments
+		if len(segments) == 0 {
+			segments = []string{q} // 单个 q 视为只有一段
+		}
+		return s.streamTranslate(c, req, "translate_stream", segments, nil)
+	}
 
+	// 额度在调用上游之前预留，翻译失败时退还
+	chars := int64(utf8.RuneCountInString(q))
+	reservation, apiErr := s.reserveQuota(c, chars)
+	if apiErr != nil {
+		return c.JSON(http.StatusTooManyRequests, apiErr)
+	}
 	var resp *translation.Response
-
-	// 根据是否指定模型选择不同的翻译方法
-	if model != "" {
-		resp, err = s.translationService.TranslateWithModel(ctx, q, sl, tl, dt, model)
-	} else {
-		resp, err = s.translationService.Translate(ctx, q, sl, tl, dt)
+	switch {
+	case format == formatHTML:
+		resp, err = s.translateHTML(c.Request().Context(), req)
+	case format == formatMarkdown:
+		resp, err = s.translateMarkdown(c.Request().Context(), req)
+	case len(payload.Segments) > 0:
+		resp, err = s.translateMulti(c.Request().Context(), req, payload.Segments)
+	case payload.Compare:
+		resp, err = s.translateCompared(c.Request().Context(), req)
+	case payload.PreserveLines && strings.Contains(q, "\n"):
+		resp, err = s.translateLines(c.Request().Context(), req)
+	default:
+		resp, err = s.pipeline.Run(c.Request().Context(), req)
+	}
+	if err != nil || resp == nil {
+		s.releaseQuota(c, reservation, chars)
+	}
+	var overloaded *overloadError
+	if errors.As(err, &overloaded) {
+		log.Warn().
+			Err(err).
+			Str("handler", "translate_single").
+			Str("ip", clientIP).
+			Msg("上游并发已满，拒绝请求")
+		return s.overloadedResponse(c, overloaded)
+	}
+	var open *breaker.OpenError
+	if errors.As(err, &open) {
+		log.Warn().
+			Err(err).
+			Str("handler", "translate_single").
+			Str("ip", clientIP).
+			Msg("提供商熔断中，拒绝请求")
+		return circuitOpenResponse(c, open)
+	}
+	var exceeded *usage.ExceededError
+	if errors.As(err, &exceeded) {
+		log.Warn().
+			Err(err).
+			Str("handler", "translate_single").
+			Str("ip", clientIP).
+			Msg("提供商费用预算已用尽，拒绝请求")
+		return budgetExceededResponse(c, exceeded)
+	}
+	if errors.Is(err, profanity.ErrRejected) {
+		log.Info().
+			Str("handler", "translate_single").
+			Str("ip", clientIP).
+			Str("tl", tl).
+			Msg("译文命中脏词过滤，拒绝返回")
+		return contentRejectedResponse(c)
 	}
-
 	if err != nil {
-		s.logger.Warn().
+		log.Warn().
 			Err(err).
 			Str("handler", "translate_single").
 			Str("ip", clientIP).
 			Msg("翻译失败，返回上游错误")
-		return BadGatewayWithDetails(c, ErrCodeTranslationFailed, "translation service unavailable", err.Error())
+		return upstreamErrorResponse(c, err)
 	}
 
 	if resp == nil {
-		s.logger.Error().
+		log.Error().
 			Str("handler", "translate_single").
 			Str("ip", clientIP).
 			Msg("翻译返回为空")
 		return BadGatewayWithDetails(c, ErrCodeServiceUnavailable, "translation service unavailable", "empty response from translation provider")
 	}
 
+	if resp.Provider != "" {
+		c.Response().Header().Set(providerHeader, resp.Provider)
+	}
+	if resp.ProfanityFiltered {
+		c.Response().Header().Set(profanityFilteredHeader, "true")
+	}
+	s.setMetadataHeaders(c, req, resp)
+
 	// 请求成功日志（保持在 Info，默认可见）
 	if len(resp.Sentences) > 0 {
-		s.logger.Info().
+		log.Info().
 			Str("handler", "translate_single").
+			Str("provider", resp.Provider).
 			Str("ip", clientIP).
 			Str("requested_sl", sl).
 			Str("requested_tl", tl).
@@ -245,6 +721,8 @@ func (s *Server) translateHandler(c echo.Context) error {
 			Msg("翻译成功")
 	}
 
+	s.recordPreference(c, resp.Src, tl)
+	s.applyAttribution(c, resp)
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -260,6 +738,9 @@ func (s *Server) translateDocumentHandler(c echo.Context) error {
 	}
 
 	// 检查必需的表单参数
+	if _, err := c.FormParams(); isBodyTooLarge(err) {
+		return err
+	}
 	q := c.FormValue("q")
 	if strings.TrimSpace(q) == "" {
 		missing = append(missing, "q")
@@ -281,22 +762,58 @@ func (s *Server) translateDocumentHandler(c echo.Context) error {
 		})
 	}
 
-	resp := translation.BuildDocumentResponse(q, c.QueryParam("sl"))
-	return c.JSON(http.StatusOK, resp)
-}
+	// 流式与非流式共用提供商选择 (指定提供商、路由规则、金丝雀分流)、语言限制与试运行，只有响应的写法不同
+	req, apiErr := s.resolveTranslateRequest(c, translateParams{
+		q:        q,
+		sl:       s.config.Translation.ResolveLanguageAlias(c.QueryParam("sl")),
+		tl:       s.config.Translation.ResolveLanguageAlias(c.QueryParam("tl")),
+		provider: c.QueryParam("provider"),
+		noCache:  isTruthy(c.QueryParam("nocache")),
+	})
+	if apiErr != nil {
+		return c.JSON(http.StatusBadRequest, apiErr)
+	}
+	if isTruthy(c.QueryParam("dry_run")) || isTruthy(c.FormValue("dry_run")) {
+		return s.handleDryRun(c, "translate_document", req)
+	}
+
+	if wantsStream(c) {
+		return s.streamDocument(c, req)
+	}
 
-// elementHandler 返回元素脚本，参数: Echo 上下文，返回: 处理结果的错误
-func (s *Server) elementHandler(c echo.Context) error {
-	js := translation.ElementScript()
-	return c.Blob(http.StatusOK, "text/javascript; charset=utf-8", []byte(js))
+	resp := translation.BuildDocumentResponse(q, req.Source)
+	s.applyDocumentAttribution(c, req.Provider, resp)
+	return c.JSON(http.StatusOK, resp)
 }
 
 // healthHandler 健康检查，参数: Echo 上下文，返回: 处理结果的错误
+// 维护期间仍返回 200 (进程健康，避免被编排系统重启)，通过 status 与 maintenance 字段反映状态
+// 查询参数 verbose=1 时另附构建版本、缓存连通性、各提供商最近的上游调用时间与熔断器状态 (依赖异常不影响状态码)，
+// 详细模式只在独立运维端口上开放，翻译端口上需要 /metrics 凭据或管理令牌
 func (s *Server) healthHandler(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"status": "ok",
-		"uptime": time.Since(s.startedAt).Seconds(),
-	})
+	status := "ok"
+	maintenance := s.maintenance.view()
+	if maintenance.Enabled {
+		status = "maintenance"
+	}
+	body := map[string]interface{}{
+		"status":      status,
+		"uptime":      time.Since(s.startedAt).Seconds(),
+		"maintenance": maintenance,
+	}
+	if verbose, _ := strconv.ParseBool(c.QueryParam("verbose")); verbose {
+		if !s.verboseHealthAllowed(c) {
+			return c.JSON(http.StatusUnauthorized, NewAPIError(ErrCodeUnauthorized, "verbose health requires metrics or admin credentials"))
+		}
+		body["build"] = buildInfo()
+		body["cache"] = s.cacheHealth(c.Request().Context())
+		providers := map[string]upstreamProviderView{}
+		if s.upstream != nil {
+			providers = s.upstream.providerViews()
+		}
+		body["providers"] = providers
+	}
+	return c.JSON(http.StatusOK, body)
 }
 
 // configureMiddleware 配置中间件，参数: 无（使用接收者），返回: 无
@@ -304,13 +821,58 @@ func (s *Server) configureMiddleware() {
 	s.echo.HideBanner = true
 	s.echo.HidePort = true
 	s.echo.Use(middleware.Recover())
-	s.echo.Use(middleware.RequestID())
-	s.echo.Use(middleware.BodyLimit("2M"))
+	s.echo.Use(requestIDMiddleware())
+	s.echo.Use(s.tracingMiddleware)
+	if s.compressor != nil {
+		s.echo.Use(s.compressor.middleware)
+	}
+	s.echo.Use(s.bodyLimit())
 	s.echo.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		// 超时中间件缓冲整个响应，流式请求跳过，每段仍受管道超时约束
+		Skipper: streamSkipper,
 		Timeout: time.Duration(s.config.Server.GetMiddlewareTimeout()) * time.Second,
 	}))
 
-	s.echo.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+	s.echo.Use(s.requestLogger())
+
+	s.echo.Use(httpMetricsMiddleware())
+	// 访问控制先于限流，被拒绝的来源不占用限流桶
+	if s.accessControl != nil {
+		s.echo.Use(s.accessControlMiddleware)
+	}
+	// 自动封禁位于限流之前，被封禁的来源不占用限流桶
+	if s.banList != nil {
+		s.echo.Use(s.autoBanMiddleware)
+	}
+	if s.rateLimiters != nil {
+		s.echo.Use(s.rateLimitMiddleware)
+	}
+}
+
+// httpMetricsMiddleware HTTP 请求指标中间件，指标注册到默认注册表且只能注册一次，多次构建服务器 (如测试中) 时共用，参数: 无，返回: 中间件
+var httpMetricsMiddleware = sync.OnceValue(func() echo.MiddlewareFunc {
+	return echoprometheus.NewMiddleware("deeplx")
+})
+
+// requestIDMiddleware 生成或沿用 X-Request-ID，并写入请求上下文供上游调用与日志使用，参数: 无，返回: 中间件
+func requestIDMiddleware() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(requestid.NewContext(req.Context(), id)))
+		},
+	})
+}
+
+// requestLog 返回附带请求 ID 的日志器，参数: Echo 上下文，返回: 日志器
+func (s *Server) requestLog(c echo.Context) *zerolog.Logger {
+	logger := s.providerLog.With().Str("request_id", requestid.FromContext(c.Request().Context())).Logger()
+	return &logger
+}
+
+// requestLogger 构建访问日志中间件，参数: 无（使用接收者），返回: 中间件
+func (s *Server) requestLogger() echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogStatus:  true,
 		LogURI:     true,
 		LogMethod:  true,
@@ -320,35 +882,47 @@ func (s *Server) configureMiddleware() {
 			var event *zerolog.Event
 			switch {
 			case v.Error != nil:
-				event = s.logger.Error().Err(v.Error)
+				event = s.accessLog.Error().Err(v.Error)
 			case v.Status >= http.StatusInternalServerError:
-				event = s.logger.Error()
+				event = s.accessLog.Error()
 			case v.Status >= http.StatusBadRequest:
-				event = s.logger.Warn()
+				event = s.accessLog.Warn()
 			default:
-				event = s.logger.Debug()
+				event = s.accessLog.Debug()
 			}
 			event = event.
 				Str("method", v.Method).
-				Str("uri", v.URI).
+				Str("uri", redactedURI(v.URI)).
 				Str("ip", c.RealIP()).
+				Str("request_id", requestid.FromContext(c.Request().Context())).
 				Int("status", v.Status).
 				Dur("latency", v.Latency)
+			if identity := authenticatedIdentity(c); identity != "" {
+				event = event.Str("client", identity)
+			}
+			if sc := trace.SpanContextFromContext(c.Request().Context()); sc.IsValid() {
+				event = event.Str("trace_id", sc.TraceID().String())
+			}
 			event.Msg("http_request")
 			return nil
 		},
-	}))
-
-	s.echo.Use(echoprometheus.NewMiddleware("deeplx"))
+	})
 }
 
 // registerRoutes 注册路由，参数: 无（使用接收者），返回: 无
+// 启用 server.ops 时运维端点注册到独立监听上
 func (s *Server) registerRoutes() {
 	s.echo.GET("/translate_a/element.js", s.elementHandler)
-	s.echo.POST("/translate_a/single", s.translateHandler)
-	s.echo.POST("/translate_a/t", s.translateDocumentHandler)
-	s.echo.GET("/healthz", s.healthHandler)
-	s.echo.GET("/metrics", echoprometheus.NewHandler())
+	s.echo.POST("/translate_a/single", s.translateHandler, s.maintenanceGuard, s.clientAuth)
+	s.echo.POST("/translate_a/t", s.translateDocumentHandler, s.maintenanceGuard, s.clientAuth)
+	s.echo.POST("/detect", s.detectHandler, s.maintenanceGuard, s.clientAuth)
+	s.echo.GET("/languages", s.languagesHandler)
+
+	ops := s.opsRouter()
+	ops.GET("/healthz", s.healthHandler)
+	ops.GET("/readyz", s.readyHandler)
+	ops.GET("/metrics", echoprometheus.NewHandler(), s.metricsAuth)
+	s.registerAdminRoutes(ops)
 }
 
 // decodeTranslateRequest 解析翻译请求参数，参数: Echo 上下文，返回: 翻译请求结构与错误
@@ -361,9 +935,24 @@ func (s *Server) decodeTranslateRequest(c echo.Context) (translateRequest, error
 			return payload, err
 		}
 	} else {
+		// 其他表单解析错误沿用原行为 (视为缺少参数)，只单独上报请求体超限
+		if _, err := c.FormParams(); isBodyTooLarge(err) {
+			return payload, err
+		}
 		payload.Q = c.FormValue("q")
+		if formValues, err := c.FormParams(); err == nil && len(formValues["q"]) > 1 {
+			payload.setSegments(formValues["q"])
+		}
 		payload.SL = c.FormValue("sl")
 		payload.TL = c.FormValue("tl")
+		payload.Format = c.FormValue("format")
+		payload.Formality = c.FormValue("formality")
+		payload.Tone = c.FormValue("tone")
+		payload.DryRun = isTruthy(c.FormValue("dry_run"))
+		payload.Compare = isTruthy(c.FormValue("compare"))
+		payload.Provider = c.FormValue("provider")
+		payload.NoCache = isTruthy(c.FormValue("nocache"))
+		payload.PreserveLines = isTruthy(c.FormValue("preserve_lines"))
 
 		if formValues, err := c.FormParams(); err == nil && len(formValues["dt"]) > 0 {
 			payload.DT = append(payload.DT, formValues["dt"]...)
@@ -376,11 +965,42 @@ func (s *Server) decodeTranslateRequest(c echo.Context) (translateRequest, error
 	if payload.TL == "" {
 		payload.TL = c.QueryParam("tl")
 	}
+	if payload.Format == "" {
+		payload.Format = c.QueryParam("format")
+	}
+	if payload.Formality == "" {
+		payload.Formality = c.QueryParam("formality")
+	}
+	if payload.Tone == "" {
+		payload.Tone = c.QueryParam("tone")
+	}
+	if payload.Formality == "" {
+		payload.Formality = payload.Tone
+	}
 	if len(payload.DT) == 0 {
 		if queryValues := c.QueryParams()["dt"]; len(queryValues) > 0 {
 			payload.DT = append(payload.DT, queryValues...)
 		}
 	}
+	if !payload.DryRun {
+		payload.DryRun = isTruthy(c.QueryParam("dry_run"))
+	}
+	if !payload.Compare {
+		payload.Compare = isTruthy(c.QueryParam("compare"))
+	}
+	if payload.Provider == "" {
+		payload.Provider = c.QueryParam("provider")
+	}
+	if !payload.NoCache {
+		payload.NoCache = isTruthy(c.QueryParam("nocache"))
+	}
+	if !payload.PreserveLines {
+		payload.PreserveLines = isTruthy(c.QueryParam("preserve_lines"))
+	}
+
+	// 兼容旧客户端的非标准语言代码
+	payload.SL = s.config.Translation.ResolveLanguageAlias(payload.SL)
+	payload.TL = s.config.Translation.ResolveLanguageAlias(payload.TL)
 
 	return payload, nil
 }
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// stubService 测试用翻译服务，默认返回 "T:" 加原文，translate 不为空时改用该函数
+type stubService struct {
+	name      string
+	translate func(ctx context.Context, q string) (*translation.Response, error)
+
+	mu    sync.Mutex
+	calls []string // 收到的原文，按调用顺序
+}
+
+func (s *stubService) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, q)
+	s.mu.Unlock()
+	if s.translate != nil {
+		return s.translate(ctx, q)
+	}
+	return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: "T:" + q}}}, nil
+}
+
+func (s *stubService) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	return s.Translate(ctx, q, sl, tl, dt)
+}
+
+func (s *stubService) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	return deeplx.TranslateSequential(ctx, s, texts, sl, tl, dt, model)
+}
+
+func (s *stubService) DetectLanguage(ctx context.Context, text string) (*deeplx.Detection, error) {
+	return nil, deeplx.ErrDetectionNotSupported
+}
+
+func (s *stubService) Healthcheck(ctx context.Context) error { return nil }
+
+func (s *stubService) GetName() string {
+	if s.name == "" {
+		return "stub"
+	}
+	return s.name
+}
+
+func (s *stubService) IsAvailable() bool { return true }
+
+func (s *stubService) Capabilities() deeplx.Capabilities { return deeplx.DefaultCapabilities() }
+
+// callCount 返回收到的调用次数，参数: 无，返回: 次数
+func (s *stubService) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls)
+}
+
+// memCache 测试用内存缓存，不处理过期
+type memCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+var _ cache.Cache = (*memCache)(nil)
+
+func newMemCache() *memCache {
+	return &memCache{data: make(map[string][]byte)}
+}
+
+func (m *memCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memCache) Ping(ctx context.Context) error { return nil }
+
+func (m *memCache) Close() error { return nil }
+
+// len 返回条目数，参数: 无，返回: 条目数
+func (m *memCache) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data)
+}
+
+// newCachedTestServer 使用测试翻译服务与内存缓存构建启用缓存的服务器，参数: 测试实例、配置 (nil 使用空配置) 与翻译服务，返回: 服务器与缓存
+func newCachedTestServer(t *testing.T, cfg *config.Config, svc deeplx.TranslationService) (*Server, *memCache) {
+	t.Helper()
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	cfg.Cache.Enabled = true
+	mc := newMemCache()
+	s, err := New(cfg, nil, &Dependencies{TranslationService: svc, Cache: mc})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s, mc
+}
+
+// waitBackground 等待管道后台任务 (缓存回写等) 结束，参数: 测试实例与服务器，返回: 无
+func waitBackground(t *testing.T, s *Server) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.background.InFlight() > 0 || s.background.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("等待后台任务超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newTestServer 使用测试翻译服务构建服务器，参数: 测试实例、配置 (nil 使用空配置) 与翻译服务，返回: 服务器
+func newTestServer(t *testing.T, cfg *config.Config, svc deeplx.TranslationService) *Server {
+	t.Helper()
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	s, err := New(cfg, nil, &Dependencies{TranslationService: svc})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+// serve 向服务器发送请求，参数: 服务器与请求，返回: 响应记录
+func serve(s *Server, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+// jsonRequest 构造 JSON 请求，参数: 方法、路径与请求体，返回: 请求
+func jsonRequest(method, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
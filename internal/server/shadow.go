@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/requestid"
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// shadowStage 把成功的上游请求按比例异步镜像到影子提供商的管道阶段，位于缓存与并发隔离之后，只镜像真正发往上游的请求
+// 影子请求在后台任务中执行，失败、超时或被丢弃都不影响返回给客户端的译文
+type shadowStage struct {
+	provider     pipeline.Provider
+	model        string
+	sampleRate   float64
+	timeout      time.Duration
+	providerName string        // 主提供商名称，响应未记录提供商时使用
+	slots        chan struct{} // 影子请求并发槽位
+	usage        *usageStage   // 用量统计 (未启用时为 nil)，影子请求同样计入
+	logger       *zerolog.Logger
+
+	mu      sync.Mutex
+	dataset *os.File // 对比数据集文件 (未配置时为 nil)
+}
+
+// shadowRecord 对比数据集中的一条记录
+type shadowRecord struct {
+	Time            time.Time `json:"time"`
+	RequestID       string    `json:"request_id,omitempty"`
+	Source          string    `json:"source"`
+	Target          string    `json:"target"`
+	DetectedSource  string    `json:"detected_source,omitempty"`
+	Text            string    `json:"text"`
+	PrimaryProvider string    `json:"primary_provider"`
+	PrimaryTrans    string    `json:"primary_trans"`
+	ShadowProvider  string    `json:"shadow_provider"`
+	ShadowModel     string    `json:"shadow_model,omitempty"`
+	ShadowTrans     string    `json:"shadow_trans,omitempty"`
+	ShadowError     string    `json:"shadow_error,omitempty"`
+	ShadowLatencyMS int64     `json:"shadow_latency_ms"`
+	Similarity      float64   `json:"similarity"`
+}
+
+// newShadowStage 根据配置构建影子流量阶段，参数: 配置、上游限制、主提供商名称、用量统计阶段 (可为 nil) 与日志记录器，返回: 阶段 (未启用时为 nil) 与错误
+func newShadowStage(cfg *config.Config, limits *upstreamLimits, providerName string, usage *usageStage, logger *zerolog.Logger) (*shadowStage, error) {
+	shadow := cfg.Translation.Shadow
+	if !shadow.Enabled {
+		return nil, nil
+	}
+	p, _ := cfg.Translation.FindProvider(shadow.Provider)
+	service, err := newProviderService(cfg, limits, p)
+	if err != nil {
+		return nil, fmt.Errorf("创建影子提供商 %s 失败: %w", p.GetName(), err)
+	}
+	stage := &shadowStage{
+		provider:     pipeline.Provider{Name: p.GetName(), Service: service},
+		model:        cfg.Translation.ResolveModel(p.GetName(), shadow.Model),
+		sampleRate:   shadow.GetSampleRate(),
+		timeout:      time.Duration(cfg.Server.GetRequestTimeout()) * time.Second,
+		providerName: providerName,
+		slots:        make(chan struct{}, shadow.GetMaxInFlight()),
+		usage:        usage,
+		logger:       logger,
+	}
+	if shadow.Output != "" {
+		file, err := os.OpenFile(shadow.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("打开影子流量数据集文件失败: %w", err)
+		}
+		stage.dataset = file
+	}
+	return stage, nil
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (s *shadowStage) Name() string {
+	return "shadow"
+}
+
+// Process 调用下游成功后按比例提交影子请求，参数: 上下文、请求与下游处理器，返回: 译文与错误 (只来自下游)
+// 请求本身就发往影子提供商时不镜像；并发槽位已满时丢弃本次镜像
+func (s *shadowStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil || req.Provider == s.provider.Name || rand.Float64() >= s.sampleRate {
+		return resp, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		metrics.ShadowRequests.WithLabelValues(s.provider.Name, "dropped").Inc()
+		return resp, nil
+	}
+	record := shadowRecord{
+		RequestID:       requestid.FromContext(ctx),
+		Source:          req.Source,
+		Target:          req.Target,
+		DetectedSource:  resp.Src,
+		Text:            req.Text,
+		PrimaryProvider: resp.Provider,
+		PrimaryTrans:    joinTrans(resp),
+		ShadowProvider:  s.provider.Name,
+		ShadowModel:     s.model,
+	}
+	if record.PrimaryProvider == "" {
+		record.PrimaryProvider = s.providerName
+	}
+	shadowReq := *req
+	shadowReq.Provider = ""
+	shadowReq.Model = s.model
+	pipeline.Detach(ctx, "shadow", func(ctx context.Context) error {
+		defer func() { <-s.slots }()
+		s.mirror(ctx, &shadowReq, record)
+		return nil
+	})
+	return resp, nil
+}
+
+// mirror 调用影子提供商并记录对比结果，参数: 后台任务上下文、影子请求与已填好主译文的记录，返回: 无
+func (s *shadowStage) mirror(ctx context.Context, req *pipeline.Request, record shadowRecord) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := pipeline.ServiceHandler(s.provider.Service)(ctx, req)
+	record.Time = start.UTC()
+	record.ShadowLatencyMS = time.Since(start).Milliseconds()
+	if err == nil && resp == nil {
+		err = fmt.Errorf("影子提供商返回为空")
+	}
+	if err != nil {
+		metrics.ShadowRequests.WithLabelValues(s.provider.Name, "error").Inc()
+		record.ShadowError = err.Error()
+	} else {
+		metrics.ShadowRequests.WithLabelValues(s.provider.Name, "success").Inc()
+		record.ShadowTrans = joinTrans(resp)
+		record.Similarity = translation.Similarity(record.PrimaryTrans, record.ShadowTrans)
+		metrics.ShadowSimilarity.WithLabelValues(s.provider.Name).Observe(record.Similarity)
+		if s.usage != nil {
+			s.usage.record(ctx, s.provider.Name, req, resp)
+		}
+	}
+
+	s.logger.Info().
+		Str("request_id", record.RequestID).
+		Str("primary_provider", record.PrimaryProvider).
+		Str("shadow_provider", record.ShadowProvider).
+		Str("primary_trans", record.PrimaryTrans).
+		Str("shadow_trans", record.ShadowTrans).
+		Str("shadow_error", record.ShadowError).
+		Int64("shadow_latency_ms", record.ShadowLatencyMS).
+		Float64("similarity", record.Similarity).
+		Msg("影子流量对比")
+	s.write(record)
+}
+
+// write 把记录追加到对比数据集文件，参数: 记录，返回: 无
+func (s *shadowStage) write(record shadowRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dataset == nil {
+		return
+	}
+	if _, err := s.dataset.Write(append(line, '\n')); err != nil {
+		s.logger.Warn().Err(err).Msg("写入影子流量数据集失败")
+	}
+}
+
+// Close 关闭对比数据集文件，应在后台任务排空后调用，参数: 无，返回: 错误
+func (s *shadowStage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dataset == nil {
+		return nil
+	}
+	err := s.dataset.Close()
+	s.dataset = nil
+	return err
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/profanity"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/usage"
+)
+
+// mimeNDJSON 流式响应的内容类型，每行一个 JSON 对象
+const mimeNDJSON = "application/x-ndjson"
+
+// streamSegment 流式响应中一段完成的译文，按完成顺序而非输入顺序输出
+type streamSegment struct {
+	Index    int    `json:"index"`              // 该段在请求 q 中的位置 (从 0 开始)
+	Orig     string `json:"orig"`               // 原文
+	Trans    string `json:"trans"`              // 译文
+	Src      string `json:"src,omitempty"`      // 检测到的源语言
+	Provider string `json:"provider,omitempty"` // 实际提供译文的提供商
+}
+
+// streamSummary 流式响应的最后一行，done 为 false 时 error 说明中止原因
+type streamSummary struct {
+	Done              bool      `json:"done"`
+	Segments          int       `json:"segments"`                     // 已输出的段数
+	Src               string    `json:"src,omitempty"`                // 第一个检测到的源语言
+	ProfanityFiltered bool      `json:"profanity_filtered,omitempty"` // 是否有段经过脏词过滤
+	Document          string    `json:"document,omitempty"`           // 文档翻译全部成功时拼装好的译文
+	Error             *APIError `json:"error,omitempty"`
+
+	characters int64 // 已输出段的原文字符数，用于计入额度
+}
+
+// wantsStream 判断请求是否要求流式响应 (查询参数 stream=1 或 Accept: application/x-ndjson)，参数: Echo 上下文，返回: 布尔
+// 只看查询参数与请求头，以便超时中间件在读取请求体之前判断
+func wantsStream(c echo.Context) bool {
+	if stream, err := strconv.ParseBool(c.QueryParam("stream")); err == nil {
+		return stream
+	}
+	for _, accept := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == mimeNDJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// streamError 把翻译错误转换为流式响应中的错误对象 (状态码已经写出，无法再按错误类型返回)，参数: 错误，返回: API 错误
+func streamError(err error) *APIError {
+	var overloaded *overloadError
+	var open *breaker.OpenError
+	var exceeded *usage.ExceededError
+	switch {
+	case errors.As(err, &overloaded):
+		return NewAPIError(ErrCodeRateLimited, "translation service overloaded").WithDetails(map[string]interface{}{
+			"bulkhead": overloaded.bulkhead,
+			"reason":   rejectionReason(overloaded.err),
+		})
+	case errors.As(err, &open):
+		return NewAPIError(ErrCodeServiceUnavailable, "translation provider circuit open").WithDetails(map[string]interface{}{
+			"provider": open.Name,
+		})
+	case errors.As(err, &exceeded):
+		return budgetExceededError(exceeded)
+	case errors.Is(err, profanity.ErrRejected):
+		return NewAPIError(ErrCodeContentRejected, "translation rejected by profanity filter")
+	default:
+		_, apiErr := upstreamError(err)
+		return apiErr
+	}
+}
+
+// streamSegments 逐段翻译并以 NDJSON 流式返回，每段完成即写出并刷新，参数: Echo 上下文、请求模板、文本段与拼装函数 (可为 nil)，返回: 流式摘要与错误 (翻译失败或写出失败)
+// 相同文本只翻译一次；任一段失败时取消其余段，并以 done=false 的摘要行结束
+// render 为 nil 时不在内存中汇总整份结果，否则保留各段译文，全部成功后拼装为摘要的 document
+func (s *Server) streamSegments(c echo.Context, req *pipeline.Request, segments []string, render func([]string) string) (streamSummary, error) {
+	positions := make(map[string][]int, len(segments))
+	var unique []string
+	for i, text := range segments {
+		if _, ok := positions[text]; !ok {
+			unique = append(unique, text)
+		}
+		positions[text] = append(positions[text], i)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeNDJSON+"; charset=UTF-8")
+	res.Header().Set("X-Accel-Buffering", "no") // 关闭 nginx 等反向代理的响应缓冲
+	res.WriteHeader(http.StatusOK)
+
+	var (
+		mu       sync.Mutex
+		summary  streamSummary
+		writeErr error
+	)
+	var translated []string
+	if render != nil {
+		translated = append([]string(nil), segments...) // 上游返回空响应的段保留原文
+	}
+	enc := json.NewEncoder(res)
+	enc.SetEscapeHTML(false)
+
+	g, gctx := errgroup.WithContext(c.Request().Context())
+	g.SetLimit(s.segmentLimit())
+	for _, text := range unique {
+		g.Go(func() error {
+			segmentReq := *req
+			segmentReq.Text = text
+			resp, err := s.pipeline.Run(gctx, &segmentReq)
+			if err != nil {
+				return err
+			}
+
+			// 上游返回空响应的段保留原文
+			segment := streamSegment{Orig: text, Trans: text}
+			if resp != nil {
+				segment.Trans, segment.Src, segment.Provider = joinTrans(resp), resp.Src, resp.Provider
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if summary.Src == "" {
+				summary.Src = segment.Src
+			}
+			if resp != nil && resp.ProfanityFiltered {
+				summary.ProfanityFiltered = true
+			}
+			for _, i := range positions[text] {
+				segment.Index = i
+				if err := enc.Encode(segment); err != nil {
+					// 客户端已断开，取消其余段
+					writeErr = err
+					return err
+				}
+				summary.Segments++
+				summary.characters += int64(utf8.RuneCountInString(text))
+				if translated != nil {
+					translated[i] = segment.Trans
+				}
+			}
+			res.Flush()
+			return nil
+		})
+	}
+	err := g.Wait()
+	if writeErr != nil {
+		return summary, writeErr
+	}
+
+	if summary.Src == "" {
+		summary.Src = req.Source
+	}
+	if err != nil {
+		summary.Error = streamError(err)
+	} else {
+		summary.Done = true
+		if render != nil {
+			summary.Document = render(translated)
+		}
+	}
+	if encErr := enc.Encode(summary); encErr != nil {
+		return summary, encErr
+	}
+	res.Flush()
+	return summary, err
+}
+
+// streamTranslate 以流式响应处理翻译请求，参数: Echo 上下文、请求模板、处理器名称 (用于日志)、文本段与拼装函数 (可为 nil)，返回: 处理结果的错误
+// 写出响应头之前按全部段预留额度 (超额时返回 429)；之后翻译失败只记录日志并体现在摘要行中，未输出的段退还额度，全部成功后才记录语言偏好
+func (s *Server) streamTranslate(c echo.Context, req *pipeline.Request, handler string, segments []string, render func([]string) string) error {
+	log := s.requestLog(c)
+	var chars int64
+	for _, segment := range segments {
+		chars += int64(utf8.RuneCountInString(segment))
+	}
+	reservation, apiErr := s.reserveQuota(c, chars)
+	if apiErr != nil {
+		return c.JSON(http.StatusTooManyRequests, apiErr)
+	}
+
+	summary, err := s.streamSegments(c, req, segments, render)
+	s.releaseQuota(c, reservation, chars-summary.characters)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("handler", handler).
+			Str("ip", c.RealIP()).
+			Int("segments", len(segments)).
+			Int("completed", summary.Segments).
+			Msg("流式翻译中止")
+		return nil
+	}
+
+	log.Info().
+		Str("handler", handler).
+		Str("ip", c.RealIP()).
+		Str("requested_sl", req.Source).
+		Str("requested_tl", req.Target).
+		Str("detected_src", summary.Src).
+		Int("segments", summary.Segments).
+		Msg("流式翻译完成")
+
+	s.recordPreference(c, summary.Src, req.Target)
+	return nil
+}
+
+// streamSkipper 判断超时中间件是否跳过请求：只有翻译端点的流式请求不经过超时中间件，参数: Echo 上下文，返回: 布尔
+func streamSkipper(c echo.Context) bool {
+	switch c.Path() {
+	case "/translate_a/single", "/translate_a/t":
+		return wantsStream(c)
+	}
+	return false
+}
+
+// streamDocument 以流式响应翻译 HTML 文档：每个文本节点完成即写出一行，全部成功后摘要行附带拼装好的译文 HTML，参数: Echo 上下文与已解析的管道请求 (Text 为原文 HTML)，返回: 处理结果的错误
+// 与 /translate_a/single 的 HTML 模式相同，标签与不翻译元素内的文本原样保留，index 为文本节点的顺序；开启 HTML 署名时注释追加在拼装后的译文末尾
+func (s *Server) streamDocument(c echo.Context, req *pipeline.Request) error {
+	fragment := translation.ParseHTML(req.Text)
+	comment := s.documentAttribution(c, req.Provider)
+	render := func(texts []string) string {
+		return fragment.Render(texts) + comment
+	}
+	return s.streamTranslate(c, req, "translate_document_stream", fragment.Texts(), render)
+}
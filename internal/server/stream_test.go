@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// streamWriter 记录流式响应的写出，第一次写出时关闭 wrote，写出 failAfter 次后返回错误 (模拟客户端断开，0 表示不失败)
+type streamWriter struct {
+	*httptest.ResponseRecorder
+	failAfter int
+
+	mu     sync.Mutex
+	writes int
+	once   sync.Once
+	wrote  chan struct{}
+}
+
+func newStreamWriter(failAfter int) *streamWriter {
+	return &streamWriter{ResponseRecorder: httptest.NewRecorder(), failAfter: failAfter, wrote: make(chan struct{})}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failAfter > 0 && w.writes >= w.failAfter {
+		return 0, errors.New("client disconnected")
+	}
+	w.writes++
+	n, err := w.ResponseRecorder.Write(p)
+	w.once.Do(func() { close(w.wrote) })
+	return n, err
+}
+
+// waitFirstLine 在第一行写出后才返回译文的翻译函数，用于控制段的完成顺序，参数: 写出记录与该段的结果，返回: 翻译函数
+func waitFirstLine(w *streamWriter, resp func(q string) (*translation.Response, error)) func(ctx context.Context, q string) (*translation.Response, error) {
+	return func(ctx context.Context, q string) (*translation.Response, error) {
+		select {
+		case <-w.wrote:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return resp(q)
+	}
+}
+
+// readStream 按行解析 NDJSON 响应，最后一行为摘要，参数: 测试实例与响应体，返回: 各段与摘要
+func readStream(t *testing.T, body string) ([]streamSegment, streamSummary) {
+	t.Helper()
+	if !strings.HasSuffix(body, "\n") {
+		t.Fatalf("响应没有以换行结束: %q", body)
+	}
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	segments := make([]streamSegment, len(lines)-1)
+	for i, line := range lines[:len(lines)-1] {
+		if err := json.Unmarshal([]byte(line), &segments[i]); err != nil {
+			t.Fatalf("第 %d 行不是 JSON: %q", i, line)
+		}
+	}
+	var summary streamSummary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("摘要行不是 JSON: %q", lines[len(lines)-1])
+	}
+	return segments, summary
+}
+
+// quotaUsed 返回测试客户端当日已用字符数，参数: 测试实例与服务器，返回: 字符数
+func quotaUsed(t *testing.T, s *Server) int64 {
+	t.Helper()
+	usage, err := s.quota.Peek(context.Background(), "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Peek() error = %v", err)
+	}
+	return usage.Used
+}
+
+// quotaConfig 启用额度统计的配置，参数: 无，返回: 配置
+func quotaConfig() *config.Config {
+	return &config.Config{Quota: config.QuotaConfig{Enabled: true, DailyChars: 1000}}
+}
+
+// TestStreamTranslate 测试流式响应按完成顺序逐行输出、重复段共用译文并以摘要行结束，参数: 测试实例，返回: 无
+func TestStreamTranslate(t *testing.T) {
+	w := newStreamWriter(0)
+	svc := &stubService{}
+	svc.translate = func(ctx context.Context, q string) (*translation.Response, error) {
+		if q == "first" {
+			// 第一段等第二段写出后才完成
+			return waitFirstLine(w, func(q string) (*translation.Response, error) {
+				return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: "T:" + q}}}, nil
+			})(ctx, q)
+		}
+		return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: "T:" + q}}}, nil
+	}
+	s := newTestServer(t, quotaConfig(), svc)
+
+	s.echo.ServeHTTP(w, jsonRequest(http.MethodPost, "/translate_a/single?stream=1", `{"q":["first","second","first"],"tl":"de"}`))
+	if got := w.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, mimeNDJSON) {
+		t.Errorf("Content-Type = %q", got)
+	}
+	segments, summary := readStream(t, w.Body.String())
+	if len(segments) != 3 {
+		t.Fatalf("segments = %+v, want 3", segments)
+	}
+	if segments[0].Index != 1 || segments[0].Trans != "T:second" {
+		t.Errorf("第一行 = %+v, want index 1 (先完成的段先输出)", segments[0])
+	}
+	indexes := map[int]string{}
+	for _, segment := range segments {
+		indexes[segment.Index] = segment.Trans
+	}
+	if indexes[0] != "T:first" || indexes[2] != "T:first" || indexes[1] != "T:second" {
+		t.Errorf("index -> trans = %v", indexes)
+	}
+	if !summary.Done || summary.Segments != 3 || summary.Src != "en" || summary.Error != nil {
+		t.Errorf("summary = %+v", summary)
+	}
+	if got := svc.callCount(); got != 2 {
+		t.Errorf("upstream calls = %d, want 2 (相同文本只翻译一次)", got)
+	}
+	if got := quotaUsed(t, s); got != int64(len("first")*2+len("second")) {
+		t.Errorf("quota used = %d", got)
+	}
+}
+
+// TestStreamTranslateError 测试某段失败时以 done=false 的摘要行结束，已输出的段仍计入额度，参数: 测试实例，返回: 无
+func TestStreamTranslateError(t *testing.T) {
+	w := newStreamWriter(0)
+	svc := &stubService{}
+	svc.translate = func(ctx context.Context, q string) (*translation.Response, error) {
+		if q == "bad" {
+			return waitFirstLine(w, func(string) (*translation.Response, error) {
+				return nil, deeplx.ErrTranslationFailed
+			})(ctx, q)
+		}
+		return &translation.Response{Src: "en", Sentences: []translation.Sentence{{Orig: q, Trans: "T:" + q}}}, nil
+	}
+	s := newTestServer(t, quotaConfig(), svc)
+
+	s.echo.ServeHTTP(w, jsonRequest(http.MethodPost, "/translate_a/single?stream=1", `{"q":["good","bad"],"tl":"de"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (错误体现在摘要行)", w.Code)
+	}
+	segments, summary := readStream(t, w.Body.String())
+	if len(segments) != 1 || segments[0].Index != 0 {
+		t.Fatalf("segments = %+v", segments)
+	}
+	if summary.Done || summary.Segments != 1 || summary.Error == nil || summary.Error.Code == "" {
+		t.Errorf("summary = %+v", summary)
+	}
+	if got := quotaUsed(t, s); got != int64(len("good")) {
+		t.Errorf("quota used = %d, want %d", got, len("good"))
+	}
+}
+
+// TestStreamTranslateDisconnect 测试客户端断开后不再写出摘要行，已写出的段计入额度，参数: 测试实例，返回: 无
+func TestStreamTranslateDisconnect(t *testing.T) {
+	s := newTestServer(t, quotaConfig(), &stubService{})
+	w := newStreamWriter(1)
+	req := jsonRequest(http.MethodPost, "/translate_a/single", `{"q":["aa","bb","cc"],"tl":"de"}`)
+	req.Header.Set(echo.HeaderAccept, mimeNDJSON)
+
+	s.echo.ServeHTTP(w, req)
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) != 1 || strings.Contains(lines[0], `"done"`) {
+		t.Errorf("body = %q, want 只有一段", w.Body.String())
+	}
+	if got := quotaUsed(t, s); got != 2 {
+		t.Errorf("quota used = %d, want 2", got)
+	}
+}
+
+// TestStreamDocument 测试文档端点流式逐个输出文本节点，摘要行附带拼装好的译文 HTML，参数: 测试实例，返回: 无
+func TestStreamDocument(t *testing.T) {
+	s := newTestServer(t, quotaConfig(), &stubService{})
+	form := url.Values{"q": {"<p>Hello</p><pre>code</pre><p>World</p>"}}
+	req := httptest.NewRequest(http.MethodPost, "/translate_a/t?client=te&sl=en&tl=de&format=html&tk=1&stream=1", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	rec := serve(s, req)
+	segments, summary := readStream(t, rec.Body.String())
+	if len(segments) != 2 {
+		t.Fatalf("segments = %+v, want 2 (pre 元素不翻译)", segments)
+	}
+	if want := "<p>T:Hello</p><pre>code</pre><p>T:World</p>"; !summary.Done || summary.Document != want {
+		t.Errorf("summary = %+v, want document %q", summary, want)
+	}
+	if got := quotaUsed(t, s); got != int64(len("Hello")+len("World")) {
+		t.Errorf("quota used = %d", got)
+	}
+}
+
+// TestDocumentRequestResolution 测试文档端点的流式与非流式模式共用路由规则、语言限制、试运行与署名，参数: 测试实例，返回: 无
+func TestDocumentRequestResolution(t *testing.T) {
+	cfg := &config.Config{Translation: config.TranslationConfig{
+		Routing: config.RoutingConfig{Enabled: true, Rules: []config.RoutingRule{
+			{Name: "german", Targets: []string{"de"}, Model: "routed"},
+		}},
+		LanguagePolicy: config.LanguagePolicyConfig{DenyTargets: []string{"fr"}},
+		Attribution:    config.AttributionConfig{Enabled: true, HTML: true},
+	}}
+	s := newTestServer(t, cfg, &stubService{})
+	document := func(query string) *http.Request {
+		form := url.Values{"q": {"<p>Hello</p>"}}
+		req := httptest.NewRequest(http.MethodPost, "/translate_a/t?client=te&sl=en&format=html&tk=1&"+query, strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		return req
+	}
+
+	for _, mode := range []string{"", "&stream=1"} {
+		rec := serve(s, document("tl=de&dry_run=1"+mode))
+		var resp dryRunResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !resp.DryRun || resp.Model != "routed" {
+			t.Errorf("mode=%q: 试运行 body = %s, want 路由规则指定的模型", mode, rec.Body.String())
+		}
+		if rec := serve(s, document("tl=fr"+mode)); rec.Code != http.StatusBadRequest {
+			t.Errorf("mode=%q: 禁止的目标语言 status = %d, want 400", mode, rec.Code)
+		}
+	}
+
+	rec := serve(s, document("tl=de&stream=1"))
+	_, summary := readStream(t, rec.Body.String())
+	if want := "<p>T:Hello</p><!-- translated by stub via translate-services -->"; summary.Document != want {
+		t.Errorf("document = %q, want %q", summary.Document, want)
+	}
+	if got := rec.Header().Get("X-Translated-By"); got != "translated by stub via translate-services" {
+		t.Errorf("X-Translated-By = %q", got)
+	}
+}
+
+// TestStreamSkipper 测试只有翻译端点的流式请求跳过超时中间件，参数: 测试实例，返回: 无
+func TestStreamSkipper(t *testing.T) {
+	e := echo.New()
+	tests := []struct {
+		path   string
+		target string
+		want   bool
+	}{
+		{"/translate_a/single", "/translate_a/single?stream=1", true},
+		{"/translate_a/t", "/translate_a/t?stream=true", true},
+		{"/translate_a/single", "/translate_a/single", false},
+		{"/detect", "/detect?stream=1", false},
+		{"/admin/usage", "/admin/usage?stream=1", false},
+	}
+	for _, tt := range tests {
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, tt.target, nil), httptest.NewRecorder())
+		c.SetPath(tt.path)
+		if got := streamSkipper(c); got != tt.want {
+			t.Errorf("streamSkipper(%s) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+)
+
+// adaptiveTimeout 将自适应超时配置转换为适配层参数，参数: 自适应超时配置，返回: 适配层参数 (未启用时为 nil)
+func adaptiveTimeout(cfg config.AdaptiveTimeoutConfig) *deeplx.AdaptiveTimeout {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &deeplx.AdaptiveTimeout{Base: cfg.GetBase(), PerKB: cfg.GetPerKB(), Max: cfg.GetMax()}
+}
+
+// requestTimeoutFunc 构建按请求计算管道超时的函数，路由到的提供商 (指定的或默认的) 启用自适应超时时按文本长度计算，参数: 配置，返回: 计算函数 (没有提供商启用时为 nil)
+// 故障转移与对冲的后续尝试共享该预算，与固定的 server.request_timeout 一致
+func requestTimeoutFunc(cfg *config.Config) func(req *pipeline.Request) time.Duration {
+	defaultProvider := cfg.Translation.DefaultProvider()
+	timeouts := make(map[string]*deeplx.AdaptiveTimeout)
+	for _, p := range cfg.Translation.ProviderConfigs() {
+		if adaptive := adaptiveTimeout(p.AdaptiveTimeout); adaptive != nil {
+			timeouts[p.GetName()] = adaptive
+		}
+	}
+	if len(timeouts) == 0 {
+		return nil
+	}
+
+	return func(req *pipeline.Request) time.Duration {
+		name := req.Provider
+		if name == "" {
+			name = defaultProvider.GetName()
+		}
+		if adaptive, ok := timeouts[name]; ok {
+			return adaptive.For(len(req.Text))
+		}
+		return 0
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/XgzK/translate-services/internal/auth"
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// newTLSConfig 根据配置构建 HTTPS 监听使用的 TLS 参数，参数: TLS 配置，返回: TLS 配置与错误
+// 证书来自 cert_file/key_file，或在启用 ACME 时按需自动申请与续期
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	suites, err := cfg.GetCipherSuites()
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.ACME.Enabled {
+		tlsConfig = newACMEManager(cfg.ACME).TLSConfig()
+	} else {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载 TLS 证书失败: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{"h2", "http/1.1"},
+		}
+	}
+
+	tlsConfig.MinVersion = cfg.GetMinVersion()
+	tlsConfig.CipherSuites = suites
+
+	if tlsConfig.ClientAuth = cfg.GetClientAuth(); tlsConfig.ClientAuth != tls.NoClientCert {
+		pool, err := loadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool 读取 PEM 格式的 CA 证书，参数: 文件路径，返回: 证书池与错误
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取客户端 CA 证书失败: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("客户端 CA 文件中没有有效证书: %s", path)
+	}
+	return pool, nil
+}
+
+// certificateClient 从已校验的客户端证书中取得客户端，参数: Echo 上下文，返回: 客户端与是否存在
+// 仅在启用 client_identity 且证书已通过 CA 校验时返回，CN 为空的证书不作为标识
+func (s *Server) certificateClient(c echo.Context) (auth.APIKey, bool) {
+	if !s.config.Server.TLS.ClientIdentity {
+		return auth.APIKey{}, false
+	}
+	state := c.Request().TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return auth.APIKey{}, false
+	}
+	cn := strings.TrimSpace(state.VerifiedChains[0][0].Subject.CommonName)
+	if cn == "" {
+		return auth.APIKey{}, false
+	}
+	return auth.APIKey{Name: cn, Source: auth.KeySourceCert}, true
+}
+
+// newACMEManager 创建自动证书管理器，只为白名单内的域名申请证书，参数: ACME 配置，返回: 证书管理器
+func newACMEManager(cfg config.ACMEConfig) *autocert.Manager {
+	hosts := make([]string, 0, len(cfg.Hosts))
+	for _, host := range cfg.Hosts {
+		hosts = append(hosts, strings.TrimSpace(host))
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cfg.GetCacheDir()),
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return manager
+}
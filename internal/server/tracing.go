@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/XgzK/translate-services/internal/tracing"
+)
+
+// tracingMiddleware 为请求创建服务端 span，并延续请求头中的 traceparent，参数: 下一个处理器，返回: 包装后的处理器
+// 运维端点 (/healthz、/metrics) 不产生 span
+func (s *Server) tracingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		route := c.Path()
+		if rateLimitExemptPaths[route] {
+			return next(c)
+		}
+
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracing.Tracer().Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("client.address", c.RealIP()),
+			),
+		)
+		defer span.End()
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+
+		status := c.Response().Status
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Code
+		}
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", status),
+			attribute.String("request.id", c.Response().Header().Get(echo.HeaderXRequestID)),
+		)
+		if err != nil {
+			tracing.RecordError(span, err)
+		} else if status >= http.StatusInternalServerError {
+			tracing.RecordError(span, errors.New(http.StatusText(status)))
+		}
+		return err
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/XgzK/translate-services/internal/breaker"
+	"github.com/XgzK/translate-services/internal/bulkhead"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/translator/deeplx"
+	"github.com/XgzK/translate-services/internal/usage"
+)
+
+// upstreamLimits 各提供商共享的上游限制：按提供商名称的隔离舱、全局重试预算与费用预算，在 New 中构建一次
+// 同一提供商被默认路由、故障转移、对冲、指定提供商与对比模式多处使用时共用一个并发上限
+type upstreamLimits struct {
+	concurrency config.ConcurrencyConfig
+	bulkheads   map[string]*bulkhead.Bulkhead
+	retryBudget *deeplx.RetryBudget  // 未启用时为 nil
+	budgets     *usage.BudgetTracker // 费用预算，未配置时为 nil；在 New 中创建用量统计阶段后、构建提供商路由前设置
+	health      *healthMonitor       // 后台健康检查，未启用时为 nil
+	calls       *upstreamCalls       // 各提供商最近的上游调用结果，用于 /healthz?verbose=1
+	breakers    []*breaker.Breaker   // 已创建的熔断器 (未启用熔断时为空)，用于 /healthz?verbose=1
+}
+
+// newUpstreamLimits 根据配置创建上游限制，参数: 配置，返回: 上游限制指针
+func newUpstreamLimits(cfg *config.Config) *upstreamLimits {
+	limits := &upstreamLimits{
+		concurrency: cfg.Server.Concurrency,
+		bulkheads:   make(map[string]*bulkhead.Bulkhead),
+		calls:       newUpstreamCalls(),
+	}
+	if rb := cfg.Translation.RetryBudget; rb.Enabled {
+		limits.retryBudget = deeplx.NewRetryBudget(rb.GetRatio(), rb.GetMinPerSecond(), rb.GetMaxTokens(), metrics.RetryBudgetExhausted.Inc)
+	}
+	return limits
+}
+
+// bulkhead 返回提供商的隔离舱，首次使用时创建，名称不区分大小写，参数: 提供商名称，返回: 隔离舱 (未启用或未配置该提供商时为 nil)
+// 只在 New 中构建提供商时调用，无需加锁
+func (u *upstreamLimits) bulkhead(name string) *bulkhead.Bulkhead {
+	if !u.concurrency.Enabled {
+		return nil
+	}
+	name = strings.ToLower(name)
+	if bh, ok := u.bulkheads[name]; ok {
+		return bh
+	}
+	for configured, limit := range u.concurrency.Providers {
+		if strings.ToLower(configured) == name {
+			bh := bulkhead.New("provider:"+name, limit.MaxInFlight, limit.MaxQueue, u.concurrency.GetQueueTimeout())
+			u.bulkheads[name] = bh
+			return bh
+		}
+	}
+	return nil
+}
+
+// observeUpstreamCall 返回记录提供商上游调用指标与最近调用结果的回调，参数: 提供商名称，返回: 回调
+func (u *upstreamLimits) observeUpstreamCall(provider string) func(call deeplx.UpstreamCall) {
+	return func(call deeplx.UpstreamCall) {
+		u.calls.record(provider, call.Err)
+		model := call.Model
+		if model == "" {
+			model = "default"
+		}
+		metrics.UpstreamDuration.WithLabelValues(provider, model).Observe(call.Duration.Seconds())
+		metrics.UpstreamCharacters.WithLabelValues(provider, model, "source").Add(float64(call.Characters))
+		if call.Err != nil {
+			metrics.UpstreamRequests.WithLabelValues(provider, model, "error").Inc()
+			metrics.UpstreamErrors.WithLabelValues(provider, model, deeplx.ErrorClass(call.Err)).Inc()
+			return
+		}
+		metrics.UpstreamRequests.WithLabelValues(provider, model, "success").Inc()
+		metrics.UpstreamCharacters.WithLabelValues(provider, model, "translated").Add(float64(call.TranslatedCharacters))
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/XgzK/translate-services/internal/cache"
+	"github.com/XgzK/translate-services/internal/config"
+	"github.com/XgzK/translate-services/internal/metrics"
+	"github.com/XgzK/translate-services/internal/pipeline"
+	"github.com/XgzK/translate-services/internal/translation"
+	"github.com/XgzK/translate-services/internal/usage"
+)
+
+// usageStage 统计发往上游的字符数与估算费用的管道阶段，位于最内层，缓存命中与被并发隔离拒绝的请求不计入
+type usageStage struct {
+	ledger       *usage.Ledger
+	pricing      *usage.Pricing
+	currency     string
+	providerName string
+	budgets      *usage.BudgetTracker // 费用预算 (未配置时为 nil)，记录费用时同步累加
+	logger       *zerolog.Logger
+}
+
+// newUsageStage 创建用量统计阶段，参数: 用量配置、缓存实例 (Redis 缓存时计数写入 Redis)、主提供商名称与日志记录器，返回: 阶段
+func newUsageStage(cfg config.UsageConfig, cacheInstance cache.Cache, providerName string, logger *zerolog.Logger) *usageStage {
+	var store usage.Store = usage.NewMemoryStore()
+	if redisCache, ok := cacheInstance.(*cache.RedisCache); ok {
+		store = usage.NewRedisStore(redisCache.Client())
+	}
+	return &usageStage{
+		ledger:       usage.NewLedger(store),
+		pricing:      newPricing(cfg),
+		currency:     cfg.GetCurrency(),
+		providerName: providerName,
+		logger:       logger,
+	}
+}
+
+// newPricing 根据配置构建价格表，参数: 用量配置，返回: 价格表 (未配置价格时为空表)
+func newPricing(cfg config.UsageConfig) *usage.Pricing {
+	prices := make([]usage.Price, 0, len(cfg.Pricing))
+	for _, p := range cfg.Pricing {
+		prices = append(prices, usage.Price{
+			Provider:               p.Provider,
+			Model:                  p.Model,
+			PerMillionChars:        p.PerMillionChars,
+			PerMillionInputTokens:  p.PerMillionInputTokens,
+			PerMillionOutputTokens: p.PerMillionOutputTokens,
+			CharsPerToken:          p.CharsPerToken,
+		})
+	}
+	return usage.NewPricing(prices)
+}
+
+// Name 返回阶段名称，参数: 无，返回: 名称
+func (u *usageStage) Name() string {
+	return "usage"
+}
+
+// Process 调用下游成功后记录用量，参数: 上下文、请求与下游处理器，返回: 译文与错误
+// 提供商取实际返回译文的提供商 (故障转移、对冲时可能不是主提供商)
+func (u *usageStage) Process(ctx context.Context, req *pipeline.Request, next pipeline.Handler) (*translation.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	u.record(ctx, resp.Provider, req, resp)
+	return resp, nil
+}
+
+// record 记录一次成功的上游调用，参数: 上下文、提供商名称 (为空时取主提供商)、请求与响应 (译文长度用于估算输出 token)，返回: 无
+func (u *usageStage) record(ctx context.Context, provider string, req *pipeline.Request, resp *translation.Response) {
+	key := usage.Key{Provider: provider, Model: req.Model, Client: req.Client}
+	if key.Provider == "" {
+		key.Provider = u.providerName
+	}
+	if key.Model == "" {
+		key.Model = "default"
+	}
+	chars := int64(utf8.RuneCountInString(req.Text))
+	cost, priced := u.pricing.Cost(key.Provider, key.Model, chars, int64(utf8.RuneCountInString(joinTrans(resp))))
+	metrics.UsageCharacters.WithLabelValues(key.Provider, key.Model).Add(float64(chars))
+	metrics.UsageRequests.WithLabelValues(key.Provider, key.Model).Inc()
+	if priced {
+		metrics.UsageCost.WithLabelValues(key.Provider, key.Model).Add(cost)
+		if u.budgets != nil {
+			u.budgets.Add(key.Provider, cost)
+		}
+	}
+	pipeline.Detach(ctx, "usage", func(ctx context.Context) error {
+		if err := u.ledger.RecordCost(ctx, key, chars, cost); err != nil {
+			u.logger.Warn().Err(err).Str("provider", key.Provider).Msg("记录上游用量失败")
+			return err
+		}
+		return nil
+	})
+}
+
+// usageHandler 查询上游用量，参数: Echo 上下文，返回: 写入响应的错误
+// 查询参数 period 为 day (默认) 或 month，date 为 2006-01-02 或 2006-01 (默认当前周期，UTC)
+func (s *Server) usageHandler(c echo.Context) error {
+	if s.usage == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{"enabled": false})
+	}
+
+	period := c.QueryParam("period")
+	if period == "" {
+		period = usage.PeriodDay
+	}
+	report, err := s.usage.ledger.Report(c.Request().Context(), period, c.QueryParam("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewAPIError(ErrCodeInvalidRequest, err.Error()))
+	}
+	return c.JSON(http.StatusOK, struct {
+		*usage.Report
+		Currency string `json:"currency"`
+	}{Report: report, Currency: s.usage.currency})
+}
@@ -0,0 +1,161 @@
+// Package tm 翻译记忆：保存人工确认过的 原文→译文 句段对，翻译前先查找完全匹配或相似度达到阈值的模糊匹配，
+// 命中时直接使用记忆中的译文，并支持与 CAT 工具交换 TMX 文件
+package tm
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/XgzK/translate-services/internal/translation"
+)
+
+// Entry 一条翻译记忆
+type Entry struct {
+	Source     string `json:"source"`      // 源语言代码
+	Target     string `json:"target"`      // 目标语言代码
+	SourceText string `json:"source_text"` // 原文
+	TargetText string `json:"target_text"` // 确认的译文
+}
+
+// Match 一次查找的结果
+type Match struct {
+	Entry
+	Score float64 `json:"score"` // 相似度，完全匹配为 1
+	Exact bool    `json:"exact"` // 是否完全匹配 (模糊匹配在只有大小写差异时相似度也可能为 1)
+}
+
+// Memory 进程内翻译记忆，并发安全
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]map[string]Entry // 规范化的 source:target → 规范化的原文 → 条目
+}
+
+// New 创建空的翻译记忆，参数: 无，返回: Memory 指针
+func New() *Memory {
+	return &Memory{entries: make(map[string]map[string]Entry)}
+}
+
+// normalizeLang 规范化语言代码用于比较，参数: 语言代码，返回: 小写代码
+func normalizeLang(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+}
+
+// normalizeText 规范化原文用于完全匹配 (首尾空白去除、连续空白折叠，大小写敏感)，参数: 文本，返回: 规范化文本
+func normalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")
+}
+
+// langMatches 判断条目语言是否适用于请求语言，相同或条目只写主语言 (如 en 适用于 en-US) 时适用，参数: 条目语言与请求语言，返回: 是否适用
+func langMatches(entry, requested string) bool {
+	if entry == requested {
+		return true
+	}
+	primary, _, _ := strings.Cut(requested, "-")
+	return entry == primary
+}
+
+// Add 添加或覆盖一条记忆，语言对与规范化原文相同的条目被替换，参数: 条目，返回: 是否为新条目
+func (m *Memory) Add(entry Entry) bool {
+	entry.Source, entry.Target = strings.TrimSpace(entry.Source), strings.TrimSpace(entry.Target)
+	pair := normalizeLang(entry.Source) + ":" + normalizeLang(entry.Target)
+	key := normalizeText(entry.SourceText)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	segments, ok := m.entries[pair]
+	if !ok {
+		segments = make(map[string]Entry)
+		m.entries[pair] = segments
+	}
+	_, exists := segments[key]
+	segments[key] = entry
+	return !exists
+}
+
+// Delete 删除一条记忆，参数: 源语言、目标语言与原文，返回: 是否存在
+func (m *Memory) Delete(source, target, text string) bool {
+	pair := normalizeLang(source) + ":" + normalizeLang(target)
+	key := normalizeText(text)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[pair][key]; !ok {
+		return false
+	}
+	delete(m.entries[pair], key)
+	return true
+}
+
+// Len 返回条目数量，参数: 无，返回: 数量
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := 0
+	for _, segments := range m.entries {
+		n += len(segments)
+	}
+	return n
+}
+
+// Entries 返回全部条目 (用于导出)，参数: 无，返回: 条目列表
+func (m *Memory) Entries() []Entry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var entries []Entry
+	for _, segments := range m.entries {
+		for _, entry := range segments {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Lookup 查找原文的译文，优先完全匹配，其次取相似度不低于 minScore 的最佳模糊匹配，参数: 源语言 (空或 auto 表示任意)、目标语言、原文与模糊匹配阈值 (>=1 时只做完全匹配)，返回: 匹配结果与是否命中
+func (m *Memory) Lookup(source, target, text string, minScore float64) (Match, bool) {
+	source, target = normalizeLang(source), normalizeLang(target)
+	anySource := source == "" || source == "auto"
+	key := normalizeText(text)
+	if key == "" {
+		return Match{}, false
+	}
+	length := len([]rune(key))
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var best Match
+	found := false
+	for pair, segments := range m.entries {
+		entrySource, entryTarget, _ := strings.Cut(pair, ":")
+		if !langMatches(entryTarget, target) || (!anySource && !langMatches(entrySource, source)) {
+			continue
+		}
+		if entry, ok := segments[key]; ok {
+			return Match{Entry: entry, Score: 1, Exact: true}, true
+		}
+		if minScore >= 1 {
+			continue
+		}
+		for candidate, entry := range segments {
+			// 长度差决定了相似度上限，先按长度过滤避免逐条计算编辑距离
+			other := len([]rune(candidate))
+			if 1-float64(abs(other-length))/float64(max(other, length)) < minScore {
+				continue
+			}
+			score := translation.Similarity(key, candidate)
+			if score >= minScore && score > best.Score {
+				best = Match{Entry: entry, Score: score}
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// abs 返回整数绝对值，参数: 整数，返回: 绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package tm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestLookup 测试完全匹配、模糊匹配阈值与语言匹配，参数: 测试实例，返回: 无
+func TestLookup(t *testing.T) {
+	m := New()
+	m.Add(Entry{Source: "en", Target: "zh-CN", SourceText: "Save  changes", TargetText: "保存更改"})
+	m.Add(Entry{Source: "en", Target: "zh-CN", SourceText: "Discard changes", TargetText: "放弃更改"})
+	if m.Add(Entry{Source: "EN", Target: "zh-cn", SourceText: "Save changes", TargetText: "保存修改"}) {
+		t.Error("Add() 规范化后相同的原文应覆盖而不是新增")
+	}
+
+	match, ok := m.Lookup("auto", "zh-CN", " Save changes ", 1)
+	if !ok || !match.Exact || match.TargetText != "保存修改" {
+		t.Errorf("Lookup(exact) = %+v, %v", match, ok)
+	}
+	if _, ok := m.Lookup("en", "zh-CN", "Save change", 1); ok {
+		t.Error("只做完全匹配时不应返回模糊匹配")
+	}
+	match, ok = m.Lookup("en-US", "zh-CN", "Save change", 0.9)
+	if !ok || match.Exact || match.TargetText != "保存修改" || match.Score < 0.9 {
+		t.Errorf("Lookup(fuzzy) = %+v, %v", match, ok)
+	}
+	if _, ok := m.Lookup("en", "zh-CN", "Close window", 0.9); ok {
+		t.Error("相似度低于阈值时不应命中")
+	}
+	if _, ok := m.Lookup("de", "zh-CN", "Save changes", 1); ok {
+		t.Error("源语言不同时不应命中")
+	}
+	if _, ok := m.Lookup("en", "zh-TW", "Save changes", 1); ok {
+		t.Error("目标语言不同时不应命中")
+	}
+
+	if !m.Delete("en", "zh-CN", "Save changes") || m.Len() != 1 {
+		t.Errorf("Delete() 后 Len() = %d", m.Len())
+	}
+}
+
+// TestTMXRoundTrip 测试 TMX 读取多语言翻译单元并可写回，参数: 测试实例，返回: 无
+func TestTMXRoundTrip(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="cat" creationtoolversion="1" segtype="sentence" o-tmf="x" adminlang="en" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="de-DE"><seg>Datei öffnen</seg></tuv>
+      <tuv xml:lang="en-US"><seg>Open <ph x="1">&lt;b&gt;</ph>file</seg></tuv>
+      <tuv xml:lang="fr-FR"><seg>Ouvrir le fichier</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="en-US"><seg></seg></tuv>
+      <tuv xml:lang="de-DE"><seg>leer</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+	entries, err := ReadTMX(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTMX() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Source != "en-US" || entries[0].Target != "de-DE" || entries[0].SourceText != "Open file" || entries[1].TargetText != "Ouvrir le fichier" {
+		t.Fatalf("ReadTMX() = %+v", entries)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTMX(&buf, entries); err != nil {
+		t.Fatalf("WriteTMX() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `xml:lang="de-DE"`) {
+		t.Errorf("WriteTMX() = %s", buf.String())
+	}
+	again, err := ReadTMX(&buf)
+	if err != nil || len(again) != 2 || again[0] != entries[0] {
+		t.Errorf("round trip = %+v, %v", again, err)
+	}
+
+	if _, err := ReadTMX(strings.NewReader("<xliff/>")); err == nil {
+		t.Error("ReadTMX(非 TMX) error = nil")
+	}
+}
@@ -0,0 +1,146 @@
+package tm
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// tmxDocument TMX 1.4 文档
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+// tmxHeader TMX 文件头，srclang 为 *all* 时每个翻译单元的第一个变体视为原文
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+// tmxUnit 翻译单元，包含同一句段在多种语言下的变体
+type tmxUnit struct {
+	SrcLang  string       `xml:"srclang,attr,omitempty"`
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+// tmxVariant 翻译单元中某种语言的变体
+type tmxVariant struct {
+	Lang       string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	LegacyLang string `xml:"lang,attr,omitempty"` // TMX 1.1 使用 lang 属性
+	Seg        tmxSeg `xml:"seg"`
+}
+
+// tmxSeg 句段内容，只读取文本，bpt/ept/ph 等内联标记被忽略
+type tmxSeg struct {
+	Text string `xml:",chardata"`
+}
+
+// lang 返回变体的语言代码，参数: 无，返回: 语言代码
+func (v tmxVariant) lang() string {
+	if v.Lang != "" {
+		return v.Lang
+	}
+	return v.LegacyLang
+}
+
+// ReadTMX 读取 TMX 文件，每个翻译单元按原文与其余各语言变体拆成多条记忆，参数: TMX 内容，返回: 条目列表与错误
+func ReadTMX(r io.Reader) ([]Entry, error) {
+	var doc tmxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析 TMX 失败: %w", err)
+	}
+	if doc.XMLName.Local != "tmx" {
+		return nil, errors.New("不是 TMX 文档")
+	}
+
+	var entries []Entry
+	for i, unit := range doc.Units {
+		srcLang := unit.SrcLang
+		if srcLang == "" {
+			srcLang = doc.Header.SrcLang
+		}
+		source := -1
+		for j, variant := range unit.Variants {
+			if variant.lang() == "" {
+				return nil, fmt.Errorf("第 %d 个翻译单元的变体缺少 xml:lang", i+1)
+			}
+			if source < 0 && (srcLang == "" || srcLang == "*all*" || strings.EqualFold(variant.lang(), srcLang)) {
+				source = j
+			}
+		}
+		if source < 0 || strings.TrimSpace(unit.Variants[source].Seg.Text) == "" {
+			continue
+		}
+		for j, variant := range unit.Variants {
+			if j == source || strings.TrimSpace(variant.Seg.Text) == "" {
+				continue
+			}
+			entries = append(entries, Entry{
+				Source:     unit.Variants[source].lang(),
+				Target:     variant.lang(),
+				SourceText: unit.Variants[source].Seg.Text,
+				TargetText: variant.Seg.Text,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// WriteTMX 把条目写为 TMX 1.4 文件，每个条目一个翻译单元，按语言对与原文排序以便比较差异，参数: 输出与条目列表，返回: 错误
+func WriteTMX(w io.Writer, entries []Entry) error {
+	sorted := append([]Entry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.SourceText < b.SourceText
+	})
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "translate-services",
+			CreationToolVersion: "1",
+			SegType:             "sentence",
+			OTMF:                "translate-services",
+			AdminLang:           "en",
+			SrcLang:             "*all*",
+			DataType:            "plaintext",
+		},
+		Units: make([]tmxUnit, 0, len(sorted)),
+	}
+	for _, entry := range sorted {
+		doc.Units = append(doc.Units, tmxUnit{
+			SrcLang: entry.Source,
+			Variants: []tmxVariant{
+				{Lang: entry.Source, Seg: tmxSeg{Text: entry.SourceText}},
+				{Lang: entry.Target, Seg: tmxSeg{Text: entry.TargetText}},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("生成 TMX 失败: %w", err)
+	}
+	return enc.Close()
+}
@@ -0,0 +1,73 @@
+// Package tracing 初始化 OpenTelemetry 追踪 (OTLP/HTTP 导出、W3C traceparent 传播)
+// 未启用时全局 TracerProvider 保持为 noop，各处埋点几乎没有开销
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/XgzK/translate-services/internal/config"
+)
+
+// instrumentationName 本服务埋点使用的 Tracer 名称
+const instrumentationName = "github.com/XgzK/translate-services"
+
+// Tracer 返回本服务使用的 Tracer，参数: 无，返回: Tracer (未启用追踪时为 noop)
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup 按配置初始化全局 TracerProvider 与传播器，参数: 上下文与追踪配置，返回: 停机时刷新并关闭导出器的函数与错误
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	// 始终解析上游 traceparent，即使本服务不导出也能把链路透传给提供商
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("创建 OTLP 导出器失败: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.GetServiceName()),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("构建追踪资源失败: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.GetSampleRatio()))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// RecordError 将错误记录到 span 并标记失败状态，参数: span 与错误，返回: 无
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
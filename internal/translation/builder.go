@@ -7,15 +7,19 @@ import (
 	"github.com/XgzK/translate-services/internal/langutil"
 )
 
+// NewLanguageDetectionResult 根据检测结果构造语言检测结果 (候选按排名列出)，参数: 检测结果，返回: LanguageDetectionResult 指针
+func NewLanguageDetectionResult(detection langutil.Detection) *LanguageDetectionResult {
+	languages, confidences := detection.Ranked()
+	return &LanguageDetectionResult{Srclangs: languages, SrclangsConfidences: confidences}
+}
+
 // BuildResponse 构造响应，参数: 文本q、源语言sl、目标语言tl、数据段dt，返回: 模拟的翻译响应
 func BuildResponse(q, sl, tl string, dt []string) Response {
-	detected := langutil.DetectLanguage(q, sl)
+	detection := langutil.DetectSource(q, sl)
+	detected := detection.Language
 	resp := Response{
-		Src: detected,
-		LDResult: &LanguageDetectionResult{
-			Srclangs:            []string{detected},
-			SrclangsConfidences: []float64{0.99},
-		},
+		Src:      detected,
+		LDResult: NewLanguageDetectionResult(detection),
 	}
 
 	if langutil.Includes(dt, "t") {
@@ -151,7 +151,25 @@ func TestBuildResponse_LDResult(t *testing.T) {
 	if len(resp.LDResult.SrclangsConfidences) != 1 {
 		t.Errorf("SrclangsConfidences length = %d, want 1", len(resp.LDResult.SrclangsConfidences))
 	}
-	if resp.LDResult.SrclangsConfidences[0] != 0.99 {
-		t.Errorf("Confidence = %v, want 0.99", resp.LDResult.SrclangsConfidences[0])
+	if resp.LDResult.SrclangsConfidences[0] != 1 {
+		t.Errorf("Confidence = %v, want 1", resp.LDResult.SrclangsConfidences[0])
+	}
+}
+
+// TestBuildResponse_LDResultCandidates 测试自动检测时按排名列出候选语言，参数: 测试实例，返回: 无
+func TestBuildResponse_LDResultCandidates(t *testing.T) {
+	resp := BuildResponse("Hallo wereld, hoe gaat het met je vandaag?", "auto", "zh", []string{"t"})
+
+	ld := resp.LDResult
+	if len(ld.Srclangs) < 2 || len(ld.Srclangs) != len(ld.SrclangsConfidences) {
+		t.Fatalf("LDResult = %+v, want several ranked candidates", ld)
+	}
+	if ld.Srclangs[0] != resp.Src {
+		t.Errorf("Srclangs[0] = %s, want %s", ld.Srclangs[0], resp.Src)
+	}
+	for i := 1; i < len(ld.SrclangsConfidences); i++ {
+		if ld.SrclangsConfidences[i] > ld.SrclangsConfidences[i-1] {
+			t.Errorf("置信度未降序: %v", ld.SrclangsConfidences)
+		}
 	}
 }
@@ -5,8 +5,17 @@ import (
 	"time"
 )
 
+// ElementScriptTTL element.js 的有效期，TKK 按小时变化
+const ElementScriptTTL = time.Hour
+
 // ElementScript 模拟 element.js，参数: 无，返回: 含 TKK 的脚本字符串
 func ElementScript() string {
-	now := time.Now().Unix() / 3600
-	return fmt.Sprintf("var tkk='%d.544157181';", now)
+	script, _ := ElementScriptAt(time.Now())
+	return script
+}
+
+// ElementScriptAt 生成指定时刻的 element.js，参数: 时刻，返回: 脚本字符串与当前 TKK 周期的起始时间 (即脚本的最后修改时间)
+func ElementScriptAt(now time.Time) (string, time.Time) {
+	hour := now.Unix() / 3600
+	return fmt.Sprintf("var tkk='%d.544157181';", hour), time.Unix(hour*3600, 0).UTC()
 }
@@ -83,3 +83,18 @@ func BenchmarkElementScript(b *testing.B) {
 		ElementScript()
 	}
 }
+
+// TestElementScriptAt 测试同一小时内脚本与修改时间不变，参数: 测试实例，返回: 无
+func TestElementScriptAt(t *testing.T) {
+	start := time.Unix(3600*480000, 0)
+	script, modified := ElementScriptAt(start.Add(59 * time.Minute))
+	if script != "var tkk='480000.544157181';" {
+		t.Errorf("script = %s", script)
+	}
+	if !modified.Equal(start) {
+		t.Errorf("modified = %v, want %v", modified, start)
+	}
+	if next, _ := ElementScriptAt(start.Add(ElementScriptTTL)); next == script {
+		t.Error("进入下一小时后 TKK 应变化")
+	}
+}
@@ -0,0 +1,163 @@
+package translation
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// skipElements 内容不参与翻译的元素
+var skipElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"code":     true,
+	"pre":      true,
+	"noscript": true,
+	"template": true,
+}
+
+// voidElements 没有结束标签的元素
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlPart HTML 片段中的一段：原样保留的标记，或需要翻译的文本节点
+type htmlPart struct {
+	raw      string // 原样输出的内容 (标签、注释、不翻译的文本)
+	text     int    // 文本节点序号，-1 表示原样输出
+	leading  string // 文本节点前导空白
+	trailing string // 文本节点尾随空白
+}
+
+// HTMLFragment 拆分后的 HTML 片段，只有文本节点参与翻译，标签、属性与注释原样保留
+type HTMLFragment struct {
+	parts []htmlPart
+	texts []string
+}
+
+// ParseHTML 拆分 HTML 片段，参数: HTML 文本，返回: HTMLFragment 指针
+// script/style/code/pre 等元素以及 translate="no" 或 class 含 notranslate 的元素内的文本不翻译
+func ParseHTML(fragment string) *HTMLFragment {
+	f := &HTMLFragment{}
+	type open struct {
+		name string
+		skip bool
+	}
+	var stack []open
+	skipping := func() bool {
+		for _, o := range stack {
+			if o.skip {
+				return true
+			}
+		}
+		return false
+	}
+
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				// 无法继续解析的剩余内容原样保留
+				f.parts = append(f.parts, htmlPart{raw: string(z.Raw()), text: -1})
+			}
+			return f
+		}
+		raw := string(z.Raw())
+
+		switch tt {
+		case html.StartTagToken:
+			token := z.Token()
+			if !voidElements[token.Data] {
+				stack = append(stack, open{name: token.Data, skip: skipElements[token.Data] || noTranslate(token)})
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == string(name) {
+					stack = stack[:i]
+					break
+				}
+			}
+		case html.TextToken:
+			if !skipping() {
+				if part, ok := f.textPart(html.UnescapeString(raw)); ok {
+					f.parts = append(f.parts, part)
+					continue
+				}
+			}
+		}
+		f.parts = append(f.parts, htmlPart{raw: raw, text: -1})
+	}
+}
+
+// textPart 把文本节点拆为前后空白与待翻译文本，参数: 反转义后的文本，返回: 片段与是否需要翻译
+func (f *HTMLFragment) textPart(text string) (htmlPart, bool) {
+	core := strings.TrimSpace(text)
+	if !strings.ContainsFunc(core, unicode.IsLetter) {
+		return htmlPart{}, false
+	}
+	start := strings.Index(text, core)
+	part := htmlPart{
+		text:     len(f.texts),
+		leading:  text[:start],
+		trailing: text[start+len(core):],
+	}
+	f.texts = append(f.texts, core)
+	return part, true
+}
+
+// noTranslate 判断元素是否标记为不翻译，参数: 开始标签，返回: 布尔
+func noTranslate(token html.Token) bool {
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "translate":
+			if strings.EqualFold(strings.TrimSpace(attr.Val), "no") {
+				return true
+			}
+		case "class":
+			for _, class := range strings.Fields(attr.Val) {
+				if class == "notranslate" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Texts 返回需要翻译的文本 (已反转义、去除首尾空白)，参数: 无，返回: 文本列表
+func (f *HTMLFragment) Texts() []string {
+	return f.texts
+}
+
+// Render 用译文替换文本节点并重新拼装 HTML，参数: 与 Texts 一一对应的译文 (缺少的条目保留原文)，返回: HTML 文本
+func (f *HTMLFragment) Render(translations []string) string {
+	var buf bytes.Buffer
+	for _, part := range f.parts {
+		if part.text < 0 {
+			buf.WriteString(part.raw)
+			continue
+		}
+		text := f.texts[part.text]
+		if part.text < len(translations) {
+			text = translations[part.text]
+		}
+		buf.WriteString(escapeText(part.leading))
+		buf.WriteString(escapeText(text))
+		buf.WriteString(escapeText(part.trailing))
+	}
+	return buf.String()
+}
+
+// textEscaper 文本节点只需转义 &、<、>，不换行空格写回为 &nbsp;，引号保持原样
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+
+// escapeText 转义文本节点，参数: 文本，返回: 转义后的文本
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
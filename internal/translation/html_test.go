@@ -0,0 +1,36 @@
+package translation
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseHTML 测试只提取文本节点，并跳过脚本、代码与 notranslate 元素，参数: 测试实例，返回: 无
+func TestParseHTML(t *testing.T) {
+	fragment := ParseHTML(`<p class="intro" title="Hello">Hello <b>world</b>!</p>` +
+		`<script>var s = "skip";</script><code>fmt.Println()</code>` +
+		`<span translate="no">Brand</span><div class="x notranslate">Keep <i>me</i></div>` +
+		`<br>Tom &amp; Jerry&nbsp;<!-- comment --> 42`)
+
+	want := []string{"Hello", "world", "Tom & Jerry"}
+	got := fragment.Texts()
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Texts() = %q, want %q", got, want)
+	}
+}
+
+// TestHTMLFragmentRender 测试译文写回时保留标签、属性、空白与实体，参数: 测试实例，返回: 无
+func TestHTMLFragmentRender(t *testing.T) {
+	input := `<p class="intro" title="Hello"> Hello <b>world</b>!</p><br/>Tom &amp; Jerry's&nbsp;<span translate="no">Brand</span>`
+	fragment := ParseHTML(input)
+
+	got := fragment.Render([]string{"你好", "世界", "汤姆 & 杰瑞的"})
+	want := `<p class="intro" title="Hello"> 你好 <b>世界</b>!</p><br/>汤姆 &amp; 杰瑞的&nbsp;<span translate="no">Brand</span>`
+	if got != want {
+		t.Errorf("Render() = %q\nwant %q", got, want)
+	}
+
+	if got := fragment.Render(nil); got != input {
+		t.Errorf("Render(nil) = %q, want original %q", got, input)
+	}
+}
@@ -0,0 +1,91 @@
+package translation
+
+import "strings"
+
+// textLine 纯文本中的一行
+type textLine struct {
+	text     int    // 需要翻译的行序号，-1 表示空行
+	leading  string // 行首空白 (缩进)
+	trailing string // 行尾空白
+	newline  string // 行尾换行符 ("\n"、"\r\n"，最后一行可能为空)
+}
+
+// TextLines 按换行拆分的纯文本，每个非空行单独翻译，换行、空行与行首尾空白原样保留
+// 用于聊天记录、列表等依赖换行排版的文本，避免提供商合并或丢失换行
+type TextLines struct {
+	lines []textLine
+	texts []string
+}
+
+// ParseLines 按换行拆分文本，参数: 文本，返回: TextLines 指针
+func ParseLines(text string) *TextLines {
+	l := &TextLines{}
+	for text != "" {
+		line, newline := text, ""
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line, newline, text = text[:i], "\n", text[i+1:]
+			if strings.HasSuffix(line, "\r") {
+				line, newline = line[:len(line)-1], "\r\n"
+			}
+		} else {
+			text = ""
+		}
+
+		core := strings.TrimSpace(line)
+		if core == "" {
+			l.lines = append(l.lines, textLine{text: -1, leading: line, newline: newline})
+			continue
+		}
+		start := strings.Index(line, core)
+		l.lines = append(l.lines, textLine{
+			text:     len(l.texts),
+			leading:  line[:start],
+			trailing: line[start+len(core):],
+			newline:  newline,
+		})
+		l.texts = append(l.texts, core)
+	}
+	return l
+}
+
+// Texts 返回需要翻译的行 (已去除首尾空白，不含空行)，参数: 无，返回: 文本列表
+func (l *TextLines) Texts() []string {
+	return l.texts
+}
+
+// Sentences 用译文替换各行并拆成句子，每个非空行一个句子，空行与换行归入前一句 (开头的空行归入第一句)，参数: 与 Texts 一一对应的译文 (缺少的条目保留原文)，返回: 句子列表
+// 拼接全部 trans 即得到保留原排版的译文
+func (l *TextLines) Sentences(translations []string) []Sentence {
+	var sentences []Sentence
+	var pendingOrig, pendingTrans strings.Builder
+	for _, line := range l.lines {
+		if line.text < 0 {
+			blank := line.leading + line.newline
+			if len(sentences) == 0 {
+				pendingOrig.WriteString(blank)
+				pendingTrans.WriteString(blank)
+				continue
+			}
+			last := &sentences[len(sentences)-1]
+			last.Orig += blank
+			last.Trans += blank
+			continue
+		}
+
+		text := l.texts[line.text]
+		trans := text
+		if line.text < len(translations) {
+			trans = translations[line.text]
+		}
+		orig := line.leading + text + line.trailing + line.newline
+		trans = line.leading + trans + line.trailing + line.newline
+		if len(sentences) == 0 {
+			orig, trans = pendingOrig.String()+orig, pendingTrans.String()+trans
+		}
+		sentences = append(sentences, Sentence{Orig: orig, Trans: trans})
+	}
+	if len(sentences) == 0 && pendingOrig.Len() > 0 {
+		sentences = append(sentences, Sentence{Orig: pendingOrig.String(), Trans: pendingTrans.String()})
+	}
+	return sentences
+}
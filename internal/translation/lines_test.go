@@ -0,0 +1,37 @@
+package translation
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTextLines 测试按行拆分时保留换行、空行与缩进，拼接译文后排版不变，参数: 测试实例，返回: 无
+func TestTextLines(t *testing.T) {
+	input := "\n[10:01] alice: Hello\r\n\n  - first item  \n[10:02] bob: Hello\n"
+	lines := ParseLines(input)
+
+	want := []string{"[10:01] alice: Hello", "- first item", "[10:02] bob: Hello"}
+	if got := lines.Texts(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Texts() = %q, want %q", got, want)
+	}
+
+	sentences := lines.Sentences([]string{"[10:01] alice: 你好", "- 第一项"})
+	if len(sentences) != 3 {
+		t.Fatalf("Sentences() 句数 = %d, want 3", len(sentences))
+	}
+	var orig, trans strings.Builder
+	for _, s := range sentences {
+		orig.WriteString(s.Orig)
+		trans.WriteString(s.Trans)
+	}
+	if orig.String() != input {
+		t.Errorf("拼接 orig = %q, want %q", orig.String(), input)
+	}
+	if wantTrans := "\n[10:01] alice: 你好\r\n\n  - 第一项  \n[10:02] bob: Hello\n"; trans.String() != wantTrans {
+		t.Errorf("拼接 trans = %q, want %q", trans.String(), wantTrans)
+	}
+
+	if got := ParseLines("\n \n").Sentences(nil); len(got) != 1 || got[0].Trans != "\n \n" {
+		t.Errorf("只有空行时 Sentences() = %+v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package translation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// markdownPart Markdown 文档中的一行：原样保留的行 (代码块、空行等)，或带块标记前缀的待翻译文本
+type markdownPart struct {
+	raw      string   // 原样输出的内容 (text 为 -1 时为整行，否则为块标记前缀)
+	text     int      // 待翻译文本序号，-1 表示原样输出
+	codes    []string // 被占位符替换的行内代码，按占位符序号排列
+	trailing string   // 行尾空白
+	newline  string   // 行尾换行符 ("\n"、"\r\n"，最后一行可能为空)
+}
+
+// MarkdownDocument 拆分后的 Markdown 文档，逐行翻译正文，代码不发送给提供商
+// 围栏代码块、缩进代码块与 <pre>/<script>/<style> HTML 块整块原样保留；行内代码 (`code`、<code>…</code>) 替换为占位符，翻译后还原
+type MarkdownDocument struct {
+	parts []markdownPart
+	texts []string
+}
+
+// 行级结构的正则
+var (
+	// markdownFencePattern 围栏代码块的起止行 (最多 3 个空格缩进)
+	markdownFencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// markdownHTMLBlockPattern 内容不翻译的 HTML 块的起始行
+	markdownHTMLBlockPattern = regexp.MustCompile(`(?i)^ {0,3}<(pre|script|style)(?:[\s>]|$)`)
+	// markdownPrefixPattern 行首的引用、标题与列表标记
+	markdownPrefixPattern = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*[ \t]*(?:#{1,6}[ \t]+|[-*+][ \t]+(?:\[[ xX]\][ \t]+)?|\d{1,9}[.)][ \t]+)?`)
+	// markdownListPattern 列表项
+	markdownListPattern = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]`)
+	// inlineHTMLCodePattern 行内的 <code>、<kbd>、<script>、<style> 元素
+	inlineHTMLCodePattern = regexp.MustCompile(`(?is)<(code|kbd|script|style)\b[^>]*>.*?</(?:code|kbd|script|style)\s*>`)
+	// codePlaceholderPattern 匹配行内代码占位符，容忍提供商在括号内插入空格
+	codePlaceholderPattern = regexp.MustCompile(`\[\[\s*C\s*(\d+)\s*\]\]`)
+)
+
+// ParseMarkdown 拆分 Markdown 文档，参数: Markdown 文本，返回: MarkdownDocument 指针
+func ParseMarkdown(text string) *MarkdownDocument {
+	d := &MarkdownDocument{}
+	var (
+		fence     string // 当前围栏代码块的围栏字符串，空表示不在围栏内
+		htmlEnd   string // 当前 HTML 块的结束标签，空表示不在 HTML 块内
+		indented  bool   // 是否在缩进代码块内
+		prevBlank = true // 上一行是否为空行 (文档开头视为空行)
+		inList    bool   // 上一个段落是否属于列表 (列表项下的缩进行是续行而不是代码块)
+	)
+	for text != "" {
+		line, newline := text, ""
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line, newline, text = text[:i], "\n", text[i+1:]
+			if strings.HasSuffix(line, "\r") {
+				line, newline = line[:len(line)-1], "\r\n"
+			}
+		} else {
+			text = ""
+		}
+		raw := markdownPart{raw: line, text: -1, newline: newline}
+		blank := strings.TrimSpace(line) == ""
+		if indented && !blank && !isIndentedCode(line) {
+			indented = false
+		}
+
+		switch {
+		case fence != "":
+			if m := markdownFencePattern.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) &&
+				strings.TrimSpace(line[len(m[0]):]) == "" {
+				fence = ""
+			}
+		case htmlEnd != "":
+			if strings.Contains(strings.ToLower(line), htmlEnd) {
+				htmlEnd = ""
+			}
+		case indented:
+		case markdownFencePattern.MatchString(line):
+			fence = markdownFencePattern.FindStringSubmatch(line)[1]
+		case markdownHTMLBlockPattern.MatchString(line):
+			end := "</" + strings.ToLower(markdownHTMLBlockPattern.FindStringSubmatch(line)[1]) + ">"
+			if !strings.Contains(strings.ToLower(line), end) {
+				htmlEnd = end
+			}
+		case blank:
+		case prevBlank && !inList && isIndentedCode(line):
+			indented = true
+		default:
+			if markdownListPattern.MatchString(line) {
+				inList = true
+			} else if !isIndentedCode(line) && !strings.HasPrefix(line, " ") {
+				inList = false
+			}
+			if part, ok := d.textPart(line, newline); ok {
+				raw = part
+			}
+		}
+		prevBlank = blank
+		d.parts = append(d.parts, raw)
+	}
+	return d
+}
+
+// isIndentedCode 判断行是否以 4 个空格或制表符缩进，参数: 行，返回: 布尔
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// textPart 拆出行首块标记与行尾空白，并把行内代码替换为占位符，参数: 行与换行符，返回: 片段与是否需要翻译
+func (d *MarkdownDocument) textPart(line, newline string) (markdownPart, bool) {
+	prefix := markdownPrefixPattern.FindString(line)
+	content := strings.TrimRightFunc(line[len(prefix):], unicode.IsSpace)
+	protected, codes := protectInlineCode(content)
+	if !strings.ContainsFunc(codePlaceholderPattern.ReplaceAllString(protected, ""), unicode.IsLetter) {
+		return markdownPart{}, false
+	}
+	part := markdownPart{
+		raw:      prefix,
+		text:     len(d.texts),
+		codes:    codes,
+		trailing: line[len(prefix)+len(content):],
+		newline:  newline,
+	}
+	d.texts = append(d.texts, protected)
+	return part, true
+}
+
+// protectInlineCode 把行内代码替换为占位符，同一行的占位符从 0 开始编号，相同的行得到相同的待翻译文本，参数: 行内容，返回: 替换后的内容与按序号排列的代码
+func protectInlineCode(content string) (string, []string) {
+	var codes []string
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		if content[i] != '`' {
+			b.WriteByte(content[i])
+			i++
+			continue
+		}
+		// 代码段以 n 个反引号开始，到下一个恰好 n 个反引号结束；没有结束时按普通字符处理
+		n := 0
+		for i+n < len(content) && content[i+n] == '`' {
+			n++
+		}
+		end := closingBackticks(content, i+n, n)
+		if end < 0 {
+			b.WriteString(content[i : i+n])
+			i += n
+			continue
+		}
+		b.WriteString(codePlaceholder(len(codes)))
+		codes = append(codes, content[i:end+n])
+		i = end + n
+	}
+
+	protected := inlineHTMLCodePattern.ReplaceAllStringFunc(b.String(), func(code string) string {
+		codes = append(codes, code)
+		return codePlaceholder(len(codes) - 1)
+	})
+	return protected, codes
+}
+
+// closingBackticks 查找恰好 n 个反引号组成的结束标记，参数: 内容、起始位置与反引号数，返回: 结束标记位置 (没有时为 -1)
+func closingBackticks(content string, from, n int) int {
+	for i := from; i < len(content); {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		run := 0
+		for i+run < len(content) && content[i+run] == '`' {
+			run++
+		}
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// codePlaceholder 生成第 i 段行内代码的占位符，参数: 序号，返回: 占位符
+func codePlaceholder(i int) string {
+	return "[[C" + strconv.Itoa(i) + "]]"
+}
+
+// restoreInlineCode 把译文中的占位符还原为行内代码，提供商丢失的代码追加在行尾，参数: 译文与代码，返回: 还原后的译文
+func restoreInlineCode(text string, codes []string) string {
+	if len(codes) == 0 {
+		return text
+	}
+	used := make([]bool, len(codes))
+	text = codePlaceholderPattern.ReplaceAllStringFunc(text, func(s string) string {
+		i, err := strconv.Atoi(codePlaceholderPattern.FindStringSubmatch(s)[1])
+		if err != nil || i >= len(codes) {
+			return s
+		}
+		used[i] = true
+		return codes[i]
+	})
+	for i, code := range codes {
+		if !used[i] {
+			text += " " + code
+		}
+	}
+	return text
+}
+
+// Texts 返回需要翻译的正文 (每行一段，行内代码已替换为占位符)，参数: 无，返回: 文本列表
+func (d *MarkdownDocument) Texts() []string {
+	return d.texts
+}
+
+// Render 用译文替换正文并还原代码，重新拼装 Markdown，参数: 与 Texts 一一对应的译文 (缺少的条目保留原文)，返回: Markdown 文本
+func (d *MarkdownDocument) Render(translations []string) string {
+	var b strings.Builder
+	for _, part := range d.parts {
+		b.WriteString(part.raw)
+		if part.text >= 0 {
+			text := d.texts[part.text]
+			if part.text < len(translations) {
+				text = translations[part.text]
+			}
+			b.WriteString(restoreInlineCode(text, part.codes))
+			b.WriteString(part.trailing)
+		}
+		b.WriteString(part.newline)
+	}
+	return b.String()
+}
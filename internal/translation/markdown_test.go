@@ -0,0 +1,70 @@
+package translation
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseMarkdown 测试代码块整块保留、行内代码替换为占位符与块标记不参与翻译，参数: 测试实例，返回: 无
+func TestParseMarkdown(t *testing.T) {
+	input := "# Install guide\n" +
+		"\n" +
+		"Run `go build ./...` and then <code>make test</code>.\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"do not translate\")\n" +
+		"```\n" +
+		"\n" +
+		"    indented code\n" +
+		"\n" +
+		"- First item\n" +
+		"\n" +
+		"    continued item text\n" +
+		"> Quoted ``a ` b`` text\r\n" +
+		"<pre>\n" +
+		"raw block\n" +
+		"</pre>\n" +
+		"---\n" +
+		"1. Last step"
+
+	doc := ParseMarkdown(input)
+	want := []string{
+		"Install guide",
+		"Run [[C0]] and then [[C1]].",
+		"First item",
+		"continued item text",
+		"Quoted [[C0]] text",
+		"Last step",
+	}
+	if got := doc.Texts(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Texts() = %q\nwant %q", got, want)
+	}
+
+	if got := doc.Render(nil); got != input {
+		t.Errorf("Render(nil) = %q, want original", got)
+	}
+
+	got := doc.Render([]string{"安装指南", "先运行 [[ C0 ]]，再运行 [[C1]]。", "第一项", "续行", "引用文本", "最后一步"})
+	wantRender := "# 安装指南\n" +
+		"\n" +
+		"先运行 `go build ./...`，再运行 <code>make test</code>。\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"do not translate\")\n" +
+		"```\n" +
+		"\n" +
+		"    indented code\n" +
+		"\n" +
+		"- 第一项\n" +
+		"\n" +
+		"    续行\n" +
+		"> 引用文本 ``a ` b``\r\n" +
+		"<pre>\n" +
+		"raw block\n" +
+		"</pre>\n" +
+		"---\n" +
+		"1. 最后一步"
+	if got != wantRender {
+		t.Errorf("Render() = %q\nwant %q", got, wantRender)
+	}
+}
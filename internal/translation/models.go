@@ -1,5 +1,7 @@
 package translation
 
+import "time"
+
 // Response 表示翻译响应，参数: 无，返回: 无
 type Response struct {
 	Src                     string                   `json:"src"`
@@ -9,6 +11,32 @@ type Response struct {
 	LDResult                *LanguageDetectionResult `json:"ld_result,omitempty"`
 	AlternativeTranslations []AlternativeTranslation `json:"alternative_translations,omitempty"`
 	Examples                *Examples                `json:"examples,omitempty"`
+	Attribution             string                   `json:"attribution,omitempty"`
+	ProfanityFiltered       bool                     `json:"profanity_filtered,omitempty"` // 译文中有词语被脏词过滤掩码
+	Comparison              *Comparison              `json:"comparison,omitempty"`         // 对比模式下对比提供商的结果
+	Memory                  *MemoryMatch             `json:"translation_memory,omitempty"` // 译文来自翻译记忆时的匹配信息
+
+	// Provider 实际提供译文的提供商 (故障转移时记录，不序列化)
+	Provider string `json:"-"`
+	// Model 发往上游的模型，为空表示提供商默认模型 (不序列化)
+	Model string `json:"-"`
+	// Cached 译文是否来自缓存 (不序列化)
+	Cached bool `json:"-"`
+	// UpstreamLatency 上游调用耗时，未调用上游时为 0 (不序列化)
+	UpstreamLatency time.Duration `json:"-"`
+}
+
+// Comparison 对比模式结果，对比提供商的译文同时写入 AlternativeTranslations，参数: 无，返回: 无
+type Comparison struct {
+	Provider   string  `json:"provider"`
+	Similarity float64 `json:"similarity"`      // 两份译文的相似度 [0, 1]，对比提供商失败时为 0
+	Error      string  `json:"error,omitempty"` // 对比提供商的错误
+}
+
+// MemoryMatch 翻译记忆命中信息，参数: 无，返回: 无
+type MemoryMatch struct {
+	Score float64 `json:"score"` // 原文与记忆原文的相似度，完全匹配为 1
+	Exact bool    `json:"exact"` // 是否完全匹配
 }
 
 // Sentence 表示单句翻译结果，参数: 无，返回: 无
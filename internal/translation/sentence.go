@@ -0,0 +1,18 @@
+package translation
+
+import "github.com/XgzK/translate-services/internal/langutil"
+
+// AlignSentences 把原文与译文按句子边界拆分并一一配对，参数: 原文与译文，返回: 句子列表
+// 两边句数不一致时无法可靠对齐，退回为整段一个句子
+func AlignSentences(orig, trans string) []Sentence {
+	origParts := langutil.SplitSentences(orig)
+	transParts := langutil.SplitSentences(trans)
+	if len(origParts) < 2 || len(origParts) != len(transParts) {
+		return []Sentence{{Orig: orig, Trans: trans}}
+	}
+	sentences := make([]Sentence, len(origParts))
+	for i := range origParts {
+		sentences[i] = Sentence{Orig: origParts[i], Trans: transParts[i]}
+	}
+	return sentences
+}
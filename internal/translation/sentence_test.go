@@ -0,0 +1,17 @@
+package translation
+
+import "testing"
+
+// TestAlignSentences 测试原文与译文按句配对，参数: 测试实例，返回: 无
+func TestAlignSentences(t *testing.T) {
+	got := AlignSentences("Hello. How are you?", "你好。你好吗？")
+	if len(got) != 2 || got[0].Orig != "Hello. " || got[0].Trans != "你好。" || got[1].Orig != "How are you?" || got[1].Trans != "你好吗？" {
+		t.Errorf("AlignSentences() = %+v", got)
+	}
+
+	// 句数不一致时整段返回
+	got = AlignSentences("Hello. How are you?", "你好，你好吗？")
+	if len(got) != 1 || got[0].Orig != "Hello. How are you?" || got[0].Trans != "你好，你好吗？" {
+		t.Errorf("AlignSentences() = %+v", got)
+	}
+}
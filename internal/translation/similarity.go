@@ -0,0 +1,42 @@
+package translation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity 计算两段译文的相似度 (按字符的归一化编辑距离，忽略大小写与空白差异)，参数: 两段文本，返回: [0, 1] 之间的相似度，1 表示相同
+func Similarity(a, b string) float64 {
+	ra, rb := similarityRunes(a), similarityRunes(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// similarityRunes 规范化文本用于比较 (小写、连续空白折叠为一个空格)，参数: 文本，返回: 字符序列
+func similarityRunes(text string) []rune {
+	return []rune(strings.ToLower(strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")))
+}
+
+// levenshtein 计算编辑距离，参数: 两个字符序列，返回: 距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
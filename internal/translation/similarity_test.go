@@ -0,0 +1,26 @@
+package translation
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSimilarity 测试译文相似度，参数: 测试实例，返回: 无
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "你好，世界", b: "你好，世界", want: 1},
+		{a: "Hello  World", b: "hello world", want: 1},
+		{a: "", b: "", want: 1},
+		{a: "abc", b: "", want: 0},
+		{a: "kitten", b: "sitting", want: 1 - 3.0/7},
+		{a: "你好世界", b: "您好世界", want: 0.75},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package deeplx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+)
+
+// 请求超出提供商能力时的错误类别，在调用上游前返回，调用方用 errors.Is 判断
+var (
+	// ErrTextTooLong 文本超过提供商单次请求的长度上限
+	ErrTextTooLong = errors.New("文本超过提供商的长度上限")
+	// ErrModelNotSupported 提供商不支持指定模型
+	ErrModelNotSupported = errors.New("提供商不支持指定模型")
+)
+
+// AnyLanguage 写在 Capabilities.Languages 中表示不限制语言
+const AnyLanguage = "*"
+
+// deeplLanguages DeepL 支持的语言 (主语言代码)，DeepLX 默认按此校验
+var deeplLanguages = []string{
+	"AR", "BG", "CS", "DA", "DE", "EL", "EN", "ES", "ET", "FI", "FR", "HE", "HU", "ID", "IT", "JA", "KO",
+	"LT", "LV", "NB", "NL", "PL", "PT", "RO", "RU", "SK", "SL", "SV", "TH", "TR", "UK", "VI", "ZH",
+}
+
+// Capabilities 提供商能力，服务端在调用上游前按此校验请求，参数: 无，返回: 无
+type Capabilities struct {
+	Languages     []string // 支持的源语言与目标语言 (主语言代码，不区分大小写)，为空或含 AnyLanguage 时不限制
+	MaxTextLength int      // 单次请求文本的最大字符数，0 表示不限制
+	SupportsModel bool     // 是否支持按请求指定模型
+	SupportsHTML  bool     // 是否能直接翻译 HTML (否则由服务端拆出文本节点逐段翻译)
+	SupportsBatch bool     // 是否支持一次调用翻译多段文本
+
+	SupportsDetection bool // 是否有独立的语言检测接口 (见 TranslationService.DetectLanguage)
+}
+
+// DefaultCapabilities 返回 DeepLX 提供商的默认能力，参数: 无，返回: 能力
+func DefaultCapabilities() Capabilities {
+	return Capabilities{
+		Languages:     slices.Clone(deeplLanguages),
+		SupportsModel: true,
+		SupportsBatch: true,
+	}
+}
+
+// SupportsLanguage 判断是否支持某个语言，两侧都先规范化为内部代码再比较主语言 (HE 与 iw、NB 与 no 视为相同)，参数: 语言代码 (如 zh-CN、EN-US)，返回: 布尔
+func (c Capabilities) SupportsLanguage(code string) bool {
+	if len(c.Languages) == 0 {
+		return true
+	}
+	primary := primaryLanguage(langutil.NormalizeLanguageCode(code))
+	return slices.ContainsFunc(c.Languages, func(lang string) bool {
+		return lang == AnyLanguage || primaryLanguage(langutil.NormalizeLanguageCode(lang)) == primary
+	})
+}
+
+// Check 校验请求是否在能力范围内，参数: 文本、源语言 (空或 auto 不校验)、目标语言、模型名称，返回: 超出能力时按类别包装的错误
+func (c Capabilities) Check(text, sl, tl, model string) error {
+	if sl != "" && !strings.EqualFold(sl, "auto") && !c.SupportsLanguage(sl) {
+		return fmt.Errorf("%w: 源语言 %s", ErrUnsupportedLanguage, sl)
+	}
+	if !c.SupportsLanguage(tl) {
+		return fmt.Errorf("%w: 目标语言 %s", ErrUnsupportedLanguage, tl)
+	}
+	if c.MaxTextLength > 0 {
+		if length := utf8.RuneCountInString(text); length > c.MaxTextLength {
+			return fmt.Errorf("%w: %d 个字符，上限 %d", ErrTextTooLong, length, c.MaxTextLength)
+		}
+	}
+	if model != "" && !c.SupportsModel {
+		return fmt.Errorf("%w: %s", ErrModelNotSupported, model)
+	}
+	return nil
+}
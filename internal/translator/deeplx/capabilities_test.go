@@ -0,0 +1,65 @@
+package deeplx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCapabilitiesCheck 测试按能力校验语言、文本长度与模型，参数: 测试实例，返回: 无
+func TestCapabilitiesCheck(t *testing.T) {
+	deeplx := DefaultCapabilities()
+	limited := Capabilities{Languages: []string{"en", "ZH"}, MaxTextLength: 4}
+	unlimited := Capabilities{Languages: []string{AnyLanguage}}
+
+	tests := []struct {
+		name  string
+		caps  Capabilities
+		text  string
+		sl    string
+		tl    string
+		model string
+		want  error
+	}{
+		{"DeepL 语言", deeplx, "hi", "en", "zh-CN", "", nil},
+		{"地区代码", deeplx, "hi", "EN_US", "PT-BR", "", nil},
+		{"自动检测", deeplx, "hi", "auto", "ja", "", nil},
+		{"谷歌旧代码", deeplx, "hi", "iw", "no", "", nil},
+		{"不支持的目标语言", deeplx, "hi", "", "xh", "", ErrUnsupportedLanguage},
+		{"不支持的源语言", limited, "hi", "ja", "zh", "", ErrUnsupportedLanguage},
+		{"不限制语言", unlimited, "hi", "xh", "zu", "", nil},
+		{"按字符计算长度", limited, "你好世界", "", "en", "", nil},
+		{"文本过长", limited, strings.Repeat("a", 5), "", "en", "", ErrTextTooLong},
+		{"不支持模型", limited, "hi", "", "en", "gpt-4o", ErrModelNotSupported},
+		{"支持模型", deeplx, "hi", "", "en", "gpt-4o", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.caps.Check(tt.text, tt.sl, tt.tl, tt.model)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestCapabilitiesFromConfig 测试配置覆盖默认能力，参数: 测试实例，返回: 无
+func TestCapabilitiesFromConfig(t *testing.T) {
+	adapter, err := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{
+		APIKey:        testAPIKey,
+		Languages:     []string{"en", "zh"},
+		MaxTextLength: 100,
+	})
+	if err != nil {
+		t.Fatalf("NewGoogleTranslatorWithConfig() error = %v", err)
+	}
+	caps := adapter.Capabilities()
+	if caps.SupportsLanguage("ja") || !caps.SupportsLanguage("zh-TW") || caps.MaxTextLength != 100 || !caps.SupportsBatch {
+		t.Fatalf("Capabilities() = %+v", caps)
+	}
+
+	adapter, _ = NewGoogleTranslatorWithConfig(&TranslationServiceConfig{APIKey: testAPIKey})
+	if caps := adapter.Capabilities(); !caps.SupportsLanguage("ja") || caps.SupportsLanguage("xh") || caps.MaxTextLength != 0 {
+		t.Fatalf("Capabilities() = %+v", caps)
+	}
+}
@@ -0,0 +1,37 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+)
+
+// ErrDetectionNotSupported 提供商没有独立的语言检测接口，调用方应退回 langutil 的本地检测
+var ErrDetectionNotSupported = errors.New("提供商不支持语言检测")
+
+// Detection 语言检测结果，参数: 无，返回: 无
+type Detection struct {
+	Language   string  // 语言代码 (谷歌格式，如 zh-CN)
+	Confidence float64 // 置信度 (0-1)，0 表示未知
+	Heuristic  bool    // 是否来自 langutil 的本地检测
+}
+
+// DetectWithFallback 优先使用提供商的语言检测，不支持或失败时退回本地检测 (langutil.Detect)，参数: 上下文、翻译服务与文本，返回: 检测结果与提供商的错误 (不支持检测时为 nil)
+// 返回的检测结果总是可用；错误只用于记录提供商检测失败的原因
+func DetectWithFallback(ctx context.Context, service TranslationService, text string) (*Detection, error) {
+	detection, err := service.DetectLanguage(ctx, text)
+	if err == nil && detection != nil && strings.TrimSpace(detection.Language) != "" {
+		return detection, nil
+	}
+	if errors.Is(err, ErrDetectionNotSupported) {
+		err = nil
+	}
+	local := langutil.Detect(text)
+	return &Detection{
+		Language:   local.Language,
+		Confidence: local.Confidence,
+		Heuristic:  true,
+	}, err
+}
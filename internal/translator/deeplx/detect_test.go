@@ -0,0 +1,50 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// detectingService 只实现语言检测的测试服务
+type detectingService struct {
+	TranslationService
+	detection *Detection
+	err       error
+}
+
+func (d detectingService) DetectLanguage(ctx context.Context, text string) (*Detection, error) {
+	return d.detection, d.err
+}
+
+// TestDetectWithFallback 测试提供商检测优先、不支持或失败时退回本地检测，参数: 测试实例，返回: 无
+func TestDetectWithFallback(t *testing.T) {
+	adapter, err := NewGoogleTranslator("test-key")
+	if err != nil {
+		t.Fatalf("NewGoogleTranslator() error = %v", err)
+	}
+	upstreamErr := errors.New("upstream down")
+	tests := []struct {
+		name          string
+		service       TranslationService
+		wantLanguage  string
+		wantHeuristic bool
+		wantErr       error
+	}{
+		{"提供商检测", detectingService{detection: &Detection{Language: "fr", Confidence: 0.98}}, "fr", false, nil},
+		{"不支持检测", adapter, "ja", true, nil},
+		{"检测失败", detectingService{err: upstreamErr}, "ja", true, upstreamErr},
+		{"空结果", detectingService{detection: &Detection{}}, "ja", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectWithFallback(context.Background(), tt.service, "こんにちは")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DetectWithFallback() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Language != tt.wantLanguage || got.Heuristic != tt.wantHeuristic {
+				t.Fatalf("DetectWithFallback() = %+v, want language %s heuristic %v", got, tt.wantLanguage, tt.wantHeuristic)
+			}
+		})
+	}
+}
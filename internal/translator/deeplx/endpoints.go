@@ -0,0 +1,229 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 多中继地址的默认参数
+const (
+	defaultProbeInterval       = 30 * time.Second
+	defaultProbeTimeout        = 5 * time.Second
+	defaultEndpointMaxFailures = 3
+	defaultEndpointEviction    = time.Minute
+)
+
+// latencyWeight 延迟指数移动平均中新样本的权重
+const latencyWeight = 0.3
+
+// EndpointOptions 多中继地址的健康检查参数，零值使用默认值
+type EndpointOptions struct {
+	ProbeInterval time.Duration // 两次探测之间的最短间隔，默认 30 秒
+	ProbeTimeout  time.Duration // 单次探测超时，默认 5 秒
+	MaxFailures   int           // 连续失败多少次后剔除，默认 3
+	Eviction      time.Duration // 剔除时长，默认 1 分钟；到期前探测成功即恢复
+
+	// OnStateChange 地址被剔除或恢复时回调 (可选)，参数: 地址与是否可用
+	OnStateChange func(url string, up bool)
+}
+
+// EndpointPool 同一提供商的多个中继地址，每次选择未被剔除且延迟最低的地址，并发安全
+// 延迟来自真实请求与探测的指数移动平均；探测按需触发 (距上次探测超过间隔时在后台进行)，没有请求时不探测
+type EndpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	opts      EndpointOptions
+	client    *http.Client
+	probing   bool
+	lastProbe time.Time
+	now       func() time.Time
+	probe     func(ctx context.Context, url string) error // 探测一个地址，测试时可替换
+}
+
+type endpoint struct {
+	url          string
+	latency      time.Duration // 延迟的指数移动平均，0 表示尚未测得
+	failures     int           // 连续失败次数
+	evictedUntil time.Time
+}
+
+// NewEndpointPool 创建中继地址池，参数: 地址列表 (已去掉末尾斜杠) 与健康检查参数，返回: EndpointPool 指针
+func NewEndpointPool(urls []string, opts EndpointOptions) *EndpointPool {
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = defaultProbeInterval
+	}
+	if opts.ProbeTimeout <= 0 {
+		opts.ProbeTimeout = defaultProbeTimeout
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = defaultEndpointMaxFailures
+	}
+	if opts.Eviction <= 0 {
+		opts.Eviction = defaultEndpointEviction
+	}
+	pool := &EndpointPool{opts: opts, client: defaultHTTPClient(opts.ProbeTimeout), now: time.Now}
+	pool.probe = pool.httpProbe
+	for _, url := range urls {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url})
+	}
+	return pool
+}
+
+// Len 返回地址数量，参数: 无，返回: 数量
+func (p *EndpointPool) Len() int {
+	return len(p.endpoints)
+}
+
+// Next 选择本次请求使用的地址，参数: 无，返回: 地址
+// 优先选择未被剔除、连续失败次数最少且延迟最低的地址 (尚未测得延迟的地址优先，以便尽快测得)；全部被剔除时选择最早到期的地址，不因中继全部故障直接拒绝
+func (p *EndpointPool) Next() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.maybeProbe(now)
+	var best, earliest *endpoint
+	for _, e := range p.endpoints {
+		if earliest == nil || e.evictedUntil.Before(earliest.evictedUntil) {
+			earliest = e
+		}
+		if now.Before(e.evictedUntil) {
+			continue
+		}
+		if best == nil || e.failures < best.failures || (e.failures == best.failures && e.latency < best.latency) {
+			best = e
+		}
+	}
+	if best == nil {
+		best = earliest
+	}
+	return best.url
+}
+
+// Success 记录地址的一次成功调用，参数: 地址与耗时，返回: 无
+func (p *EndpointPool) Success(url string, latency time.Duration) {
+	p.mu.Lock()
+	e := p.find(url)
+	if e == nil {
+		p.mu.Unlock()
+		return
+	}
+	recovered := p.now().Before(e.evictedUntil)
+	e.failures = 0
+	e.evictedUntil = time.Time{}
+	latency = max(latency, time.Microsecond) // 0 表示尚未测得
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+	}
+	p.mu.Unlock()
+
+	if recovered && p.opts.OnStateChange != nil {
+		p.opts.OnStateChange(url, true)
+	}
+}
+
+// Failure 记录地址的一次失败 (连接失败、超时或 5xx)，连续失败达到上限时剔除，已剔除的地址再次失败时延长剔除，参数: 地址，返回: 无
+func (p *EndpointPool) Failure(url string) {
+	p.mu.Lock()
+	e := p.find(url)
+	if e == nil {
+		p.mu.Unlock()
+		return
+	}
+	now := p.now()
+	evicted := false
+	switch e.failures++; {
+	case now.Before(e.evictedUntil):
+		// 剔除期间探测仍失败，延长剔除
+		e.evictedUntil = now.Add(p.opts.Eviction)
+		e.failures = 0
+	case e.failures >= p.opts.MaxFailures:
+		evicted = true
+		e.evictedUntil = now.Add(p.opts.Eviction)
+		e.failures = 0
+	}
+	p.mu.Unlock()
+
+	if evicted && p.opts.OnStateChange != nil {
+		p.opts.OnStateChange(url, false)
+	}
+}
+
+// Available 返回当前未被剔除的地址数量，参数: 无，返回: 数量
+func (p *EndpointPool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	n := 0
+	for _, e := range p.endpoints {
+		if !now.Before(e.evictedUntil) {
+			n++
+		}
+	}
+	return n
+}
+
+// find 按地址查找，调用方需持有锁，参数: 地址，返回: 地址状态 (未找到时为 nil)
+func (p *EndpointPool) find(url string) *endpoint {
+	for _, e := range p.endpoints {
+		if e.url == url {
+			return e
+		}
+	}
+	return nil
+}
+
+// maybeProbe 距上次探测超过间隔且没有进行中的探测时，在后台探测全部地址，调用方需持有锁，参数: 当前时间，返回: 无
+func (p *EndpointPool) maybeProbe(now time.Time) {
+	if p.probing || now.Sub(p.lastProbe) < p.opts.ProbeInterval {
+		return
+	}
+	p.probing = true
+	p.lastProbe = now
+	go p.probeAll()
+}
+
+// probeAll 并发探测全部地址并记录结果，参数: 无，返回: 无
+func (p *EndpointPool) probeAll() {
+	defer func() {
+		p.mu.Lock()
+		p.probing = false
+		p.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), p.opts.ProbeTimeout)
+			defer cancel()
+			start := time.Now()
+			if err := p.probe(ctx, url); err != nil {
+				p.Failure(url)
+				return
+			}
+			p.Success(url, time.Since(start))
+		}(e.url)
+	}
+	wg.Wait()
+}
+
+// httpProbe 以 GET 请求探测地址，参数: 上下文与地址，返回: 错误
+func (p *EndpointPool) httpProbe(ctx context.Context, url string) error {
+	return probeURL(ctx, p.client, url)
+}
+
+// isEndpointFailure 判断错误是否应计为中继地址的故障 (连接失败、超时或 5xx，不含密钥被拒绝等 4xx)，参数: 错误，返回: 布尔
+func isEndpointFailure(err error) bool {
+	var te *TransportError
+	if !errors.As(err, &te) {
+		return false
+	}
+	return (te.StatusCode == 0 && te.Err != nil) || te.StatusCode >= http.StatusInternalServerError
+}
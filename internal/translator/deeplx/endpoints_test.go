@@ -0,0 +1,112 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestEndpointPoolSelection 测试按失败次数与延迟选择地址、连续失败剔除、全部剔除时仍返回地址与成功后恢复，参数: 测试实例，返回: 无
+func TestEndpointPoolSelection(t *testing.T) {
+	var changes []string
+	pool := NewEndpointPool([]string{"http://a", "http://b"}, EndpointOptions{
+		ProbeInterval: time.Hour,
+		MaxFailures:   2,
+		Eviction:      time.Minute,
+		OnStateChange: func(url string, up bool) {
+			if up {
+				changes = append(changes, "up:"+url)
+			} else {
+				changes = append(changes, "down:"+url)
+			}
+		},
+	})
+	now := time.Unix(1000, 0)
+	pool.now = func() time.Time { return now }
+	pool.lastProbe = now // 不触发后台探测
+
+	pool.Success("http://a", 300*time.Millisecond)
+	pool.Success("http://b", 100*time.Millisecond)
+	if got := pool.Next(); got != "http://b" {
+		t.Fatalf("Next() = %s, want 延迟更低的 http://b", got)
+	}
+
+	pool.Failure("http://b")
+	if got := pool.Next(); got != "http://a" || pool.Available() != 2 {
+		t.Errorf("一次失败应只降低优先级, Next() = %s, Available() = %d", got, pool.Available())
+	}
+	pool.Failure("http://b")
+	if got := pool.Next(); got != "http://a" || pool.Available() != 1 {
+		t.Errorf("连续失败后 Next() = %s, Available() = %d", got, pool.Available())
+	}
+
+	now = now.Add(time.Second)
+	pool.Failure("http://a")
+	pool.Failure("http://a")
+	if got := pool.Next(); got != "http://b" {
+		t.Errorf("全部剔除时应选最早到期的地址, Next() = %s", got)
+	}
+
+	pool.Success("http://a", 200*time.Millisecond)
+	if got := pool.Next(); got != "http://a" {
+		t.Errorf("成功后应恢复, Next() = %s", got)
+	}
+	want := []string{"down:http://b", "down:http://a", "up:http://a"}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] || changes[2] != want[2] {
+		t.Errorf("状态变化 = %v, want %v", changes, want)
+	}
+}
+
+// TestEndpointPoolProbe 测试探测失败剔除地址且探测成功后恢复，参数: 测试实例，返回: 无
+func TestEndpointPoolProbe(t *testing.T) {
+	pool := NewEndpointPool([]string{"http://a", "http://b"}, EndpointOptions{MaxFailures: 1})
+	var down atomic.Bool
+	down.Store(true)
+	pool.probe = func(_ context.Context, url string) error {
+		if url == "http://a" && down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	pool.probeAll()
+	if got := pool.Next(); got != "http://b" || pool.Available() != 1 {
+		t.Fatalf("探测失败后 Next() = %s, Available() = %d", got, pool.Available())
+	}
+	down.Store(false)
+	pool.probeAll()
+	if pool.Available() != 2 {
+		t.Errorf("探测成功后 Available() = %d, want 2", pool.Available())
+	}
+}
+
+// TestTranslatorEndpointFailover 测试中继地址连接失败时换用其他地址重试，参数: 测试实例，返回: 无
+func TestTranslatorEndpointFailover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":200,"data":"你好"}`))
+	}))
+	defer server.Close()
+	dead := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	translator, err := NewTranslatorWithConfig(&TranslationServiceConfig{
+		APIKey:          "sk-test",
+		BaseURL:         deadURL,
+		BaseURLs:        []string{server.URL},
+		EndpointOptions: EndpointOptions{ProbeInterval: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("NewTranslatorWithConfig() error = %v", err)
+	}
+	translator.endpoints.lastProbe = time.Now()
+
+	result, err := translator.Translate(context.Background(), Request{Text: "hello", Target: "zh"})
+	if err != nil || result.TranslatedText != "你好" {
+		t.Fatalf("Translate() = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,93 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 翻译失败的错误类别，DeepLXTranslator 返回的错误按类别包装，调用方用 errors.Is 判断
+var (
+	// ErrTimeout 上游请求超时 (单次请求超时或网络超时)
+	ErrTimeout = errors.New("上游请求超时")
+	// ErrUnauthorized 上游拒绝了 API 密钥 (401/403)
+	ErrUnauthorized = errors.New("上游拒绝了 API 密钥")
+	// ErrRateLimited 上游限流 (429)，等待时间见 RetryAfter
+	ErrRateLimited = errors.New("上游请求过于频繁")
+	// ErrQuotaExceeded 上游额度已用尽 (456)
+	ErrQuotaExceeded = errors.New("上游额度已用尽")
+	// ErrUnsupportedLanguage 上游不支持请求的语言 (400/422 且错误信息提及语言)
+	ErrUnsupportedLanguage = errors.New("上游不支持该语言")
+	// ErrEmptyResult 上游返回成功但译文为空或与原文相同，重试一次后仍如此时返回
+	ErrEmptyResult = errors.New("上游返回了空译文")
+)
+
+// classifyError 按类别包装传输层错误，保留原始错误链，参数: 错误，返回: 包装后的错误 (无法归类时原样返回)
+func classifyError(err error) error {
+	var te *TransportError
+	if errors.As(err, &te) {
+		switch te.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%w: %w", ErrRateLimited, err)
+		case statusQuotaExceeded:
+			return fmt.Errorf("%w: %w", ErrQuotaExceeded, err)
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			if strings.Contains(strings.ToLower(te.Message), "lang") {
+				return fmt.Errorf("%w: %w", ErrUnsupportedLanguage, err)
+			}
+		}
+	}
+	if isTimeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// 错误类别名称，用于指标标签
+const (
+	ErrorClassTimeout             = "timeout"
+	ErrorClassUnauthorized        = "unauthorized"
+	ErrorClassRateLimited         = "rate_limited"
+	ErrorClassQuotaExceeded       = "quota_exceeded"
+	ErrorClassUnsupportedLanguage = "unsupported_language"
+	ErrorClassEmptyResult         = "empty_result"
+	ErrorClassCanceled            = "canceled"
+	ErrorClassOther               = "other"
+)
+
+// ErrorClass 返回错误的类别名称，参数: 错误 (应已经 classifyError 归类)，返回: 类别名称 (err 为 nil 时为空)
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrTimeout):
+		return ErrorClassTimeout
+	case errors.Is(err, ErrUnauthorized):
+		return ErrorClassUnauthorized
+	case errors.Is(err, ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, ErrQuotaExceeded):
+		return ErrorClassQuotaExceeded
+	case errors.Is(err, ErrUnsupportedLanguage):
+		return ErrorClassUnsupportedLanguage
+	case errors.Is(err, ErrEmptyResult):
+		return ErrorClassEmptyResult
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	}
+	return ErrorClassOther
+}
+
+// RetryAfter 返回上游在错误响应中要求的等待时间，参数: 错误，返回: 等待时间 (上游未给出时为 0)
+func RetryAfter(err error) time.Duration {
+	var te *TransportError
+	if errors.As(err, &te) {
+		return te.RetryAfter
+	}
+	return 0
+}
@@ -2,21 +2,69 @@ package deeplx
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // ServiceType 翻译服务类型 (枚举模式喵～)
 type ServiceType string
 
 const (
-	ServiceTypeDeepLX ServiceType = "deeplx"  // DeepLX 服务
-	ServiceTypeBaidu  ServiceType = "baidu"   // 百度翻译（预留）
-	ServiceTypeYoudao ServiceType = "youdao"  // 有道翻译（预留）
-	ServiceTypeGoogle ServiceType = "google"  // 谷歌翻译（预留）
-	ServiceTypeCustom ServiceType = "custom"  // 自定义服务（预留）
+	ServiceTypeDeepLX ServiceType = "deeplx" // DeepLX 服务
+	ServiceTypeOpenAI ServiceType = "openai" // OpenAI 兼容的大模型对话接口 (OpenAI、Ollama、Gemini 等)
+	ServiceTypeBaidu  ServiceType = "baidu"  // 百度翻译（预留）
+	ServiceTypeYoudao ServiceType = "youdao" // 有道翻译（预留）
+	ServiceTypeGoogle ServiceType = "google" // 谷歌翻译（预留）
+	ServiceTypeCustom ServiceType = "custom" // 自定义服务（预留）
 )
 
-// TranslationServiceFactory 翻译服务工厂 (工厂模式：统一创建接口喵～)
+// Constructor 提供商构造函数，参数: 翻译服务配置 (不为 nil)，返回: 翻译服务实例或错误
+type Constructor func(config *TranslationServiceConfig) (TranslationService, error)
+
+// registry 已注册的提供商构造函数 (注册表模式：新增提供商无需修改工厂喵～)，按小写服务类型索引
+var registry = struct {
+	sync.RWMutex
+	constructors map[ServiceType]Constructor
+}{constructors: make(map[ServiceType]Constructor)}
+
+func init() {
+	Register(ServiceTypeDeepLX, createDeepLXService)
+	Register(ServiceTypeOpenAI, createOpenAIService)
+}
+
+// Register 注册提供商构造函数，通常在提供商所在包的 init 中调用 (外部模块同样适用)，参数: 服务类型 (不区分大小写) 与构造函数，返回: 无
+// 服务类型为空、构造函数为 nil 或重复注册时 panic，与 database/sql.Register 一致
+func Register(serviceType ServiceType, constructor Constructor) {
+	name := normalizeServiceType(serviceType)
+	if name == "" {
+		panic("deeplx: Register 的服务类型为空")
+	}
+	if constructor == nil {
+		panic(fmt.Sprintf("deeplx: Register 的构造函数为 nil: %s", name))
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, dup := registry.constructors[name]; dup {
+		panic(fmt.Sprintf("deeplx: 重复注册服务类型: %s", name))
+	}
+	registry.constructors[name] = constructor
+}
+
+// normalizeServiceType 规范化服务类型 (去空白、转小写)，参数: 服务类型，返回: 规范化后的服务类型
+func normalizeServiceType(serviceType ServiceType) ServiceType {
+	return ServiceType(strings.ToLower(strings.TrimSpace(string(serviceType))))
+}
+
+// lookupConstructor 查找已注册的构造函数，参数: 服务类型，返回: 构造函数与是否存在
+func lookupConstructor(serviceType ServiceType) (Constructor, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	constructor, ok := registry.constructors[normalizeServiceType(serviceType)]
+	return constructor, ok
+}
+
+// TranslationServiceFactory 翻译服务工厂 (工厂模式：统一创建接口喵～)，按注册表创建提供商
 type TranslationServiceFactory struct {
 	// 可以添加默认配置、缓存等
 }
@@ -35,39 +83,15 @@ func (f *TranslationServiceFactory) CreateService(
 		return nil, fmt.Errorf("配置不能为空")
 	}
 
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("API 密钥不能为空")
-	}
-
-	switch strings.ToLower(string(serviceType)) {
-	case string(ServiceTypeDeepLX):
-		return f.createDeepLXService(config)
-
-	case string(ServiceTypeBaidu):
-		// 预留：将来实现百度翻译
-		return nil, fmt.Errorf("百度翻译服务尚未实现，敬请期待喵～")
-
-	case string(ServiceTypeYoudao):
-		// 预留：将来实现有道翻译
-		return nil, fmt.Errorf("有道翻译服务尚未实现，敬请期待喵～")
-
-	case string(ServiceTypeGoogle):
-		// 预留：将来实现真实的谷歌翻译
-		return nil, fmt.Errorf("谷歌翻译服务尚未实现，敬请期待喵～")
-
-	case string(ServiceTypeCustom):
-		// 预留：自定义服务
-		return nil, fmt.Errorf("自定义翻译服务需要额外配置，敬请期待喵～")
-
-	default:
-		return nil, fmt.Errorf("不支持的服务类型: %s", serviceType)
+	constructor, ok := lookupConstructor(serviceType)
+	if !ok {
+		return nil, fmt.Errorf("不支持的服务类型: %s (已注册: %s)", serviceType, joinServiceTypes(f.GetSupportedServices()))
 	}
+	return constructor(config)
 }
 
 // createDeepLXService 创建 DeepLX 服务，参数: 配置，返回: DeepLX 翻译服务或错误
-func (f *TranslationServiceFactory) createDeepLXService(
-	config *TranslationServiceConfig,
-) (TranslationService, error) {
+func createDeepLXService(config *TranslationServiceConfig) (TranslationService, error) {
 	// 使用完整配置创建服务（包含 Timeout、BaseURL 等）
 	service, err := NewGoogleTranslatorWithConfig(config)
 	if err != nil {
@@ -87,15 +111,31 @@ func (f *TranslationServiceFactory) CreateServiceSimple(
 	})
 }
 
-// GetSupportedServices 获取支持的服务类型，参数: 无，返回: 服务类型切片
+// GetSupportedServices 获取已注册的服务类型，参数: 无，返回: 按名称排序的服务类型切片
 func (f *TranslationServiceFactory) GetSupportedServices() []ServiceType {
-	return []ServiceType{
-		ServiceTypeDeepLX,
-		// 以下服务预留，将来可以添加
-		// ServiceTypeBaidu,
-		// ServiceTypeYoudao,
-		// ServiceTypeGoogle,
+	registry.RLock()
+	defer registry.RUnlock()
+	services := make([]ServiceType, 0, len(registry.constructors))
+	for serviceType := range registry.constructors {
+		services = append(services, serviceType)
 	}
+	slices.Sort(services)
+	return services
+}
+
+// IsSupported 判断服务类型是否已注册，参数: 服务类型 (不区分大小写)，返回: 布尔
+func (f *TranslationServiceFactory) IsSupported(serviceType ServiceType) bool {
+	_, ok := lookupConstructor(serviceType)
+	return ok
+}
+
+// joinServiceTypes 拼接服务类型用于错误信息，参数: 服务类型切片，返回: 逗号分隔的字符串
+func joinServiceTypes(services []ServiceType) string {
+	names := make([]string, len(services))
+	for i, s := range services {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
 }
 
 // GetServiceInfo 获取服务描述，参数: 服务类型，返回: 描述字符串
@@ -108,9 +148,13 @@ func (f *TranslationServiceFactory) GetServiceInfo(serviceType ServiceType) stri
 		ServiceTypeCustom: "自定义服务 - 支持自定义翻译接口（即将支持）",
 	}
 
-	if desc, ok := info[serviceType]; ok {
+	name := normalizeServiceType(serviceType)
+	if desc, ok := info[name]; ok {
 		return desc
 	}
+	if f.IsSupported(name) {
+		return fmt.Sprintf("%s - 通过 Register 注册的翻译服务", name)
+	}
 
 	return "未知服务类型"
 }
@@ -2,6 +2,7 @@ package deeplx
 
 import (
 	"context"
+	"slices"
 	"testing"
 )
 
@@ -40,10 +41,19 @@ func TestCreateService(t *testing.T) {
 			wantErr:     true,
 		},
 		{
-			name:        "空 API 密钥",
+			name:        "无需密钥的中继",
 			serviceType: ServiceTypeDeepLX,
 			config: &TranslationServiceConfig{
-				APIKey: "",
+				BaseURL: "http://localhost:1188/translate",
+			},
+			wantErr: false,
+		},
+		{
+			name:        "API 密钥不符合格式",
+			serviceType: ServiceTypeDeepLX,
+			config: &TranslationServiceConfig{
+				APIKey:     "invalid-key",
+				KeyPattern: "^sk-",
 			},
 			wantErr: true,
 		},
@@ -164,6 +174,48 @@ func TestGetSupportedServices(t *testing.T) {
 	}
 }
 
+// TestRegister 测试注册新的提供商后可由工厂创建并出现在支持列表中，参数: 测试实例，返回: 无
+func TestRegister(t *testing.T) {
+	factory := NewFactory()
+	const custom ServiceType = "test-registry"
+	if factory.IsSupported(custom) {
+		t.Fatal("注册前不应支持该服务类型")
+	}
+
+	var got *TranslationServiceConfig
+	Register("Test-Registry", func(config *TranslationServiceConfig) (TranslationService, error) {
+		got = config
+		return NewGoogleTranslatorWithConfig(config)
+	})
+	defer func() {
+		registry.Lock()
+		delete(registry.constructors, custom)
+		registry.Unlock()
+	}()
+
+	config := &TranslationServiceConfig{Name: "custom"}
+	service, err := factory.CreateService("TEST-REGISTRY", config)
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if got != config || service.GetName() != "custom" {
+		t.Errorf("构造函数应收到原配置, got %p, name %q", got, service.GetName())
+	}
+	if !slices.Contains(factory.GetSupportedServices(), custom) {
+		t.Errorf("GetSupportedServices() = %v, 应包含 %s", factory.GetSupportedServices(), custom)
+	}
+	if info := factory.GetServiceInfo(custom); info == "未知服务类型" {
+		t.Errorf("GetServiceInfo() = %q", info)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	Register(custom, createDeepLXService)
+}
+
 // TestGetServiceInfo 测试获取服务信息
 // TestGetServiceInfo 测试服务信息查询，参数: 测试实例，返回: 无
 func TestGetServiceInfo(t *testing.T) {
@@ -0,0 +1,63 @@
+package deeplx
+
+import (
+	"context"
+	"strings"
+)
+
+// 支持的语气取值 (与 DeepL formality 参数一致)
+const (
+	FormalityDefault    = "default"
+	FormalityMore       = "more"        // 更正式 (目标语言不支持时上游报错)
+	FormalityLess       = "less"        // 更随意 (目标语言不支持时上游报错)
+	FormalityPreferMore = "prefer_more" // 尽量正式，目标语言不支持时忽略
+	FormalityPreferLess = "prefer_less" // 尽量随意，目标语言不支持时忽略
+)
+
+// formalityAliases 常用别名到标准取值的映射
+var formalityAliases = map[string]string{
+	FormalityDefault:    FormalityDefault,
+	FormalityMore:       FormalityMore,
+	FormalityLess:       FormalityLess,
+	FormalityPreferMore: FormalityPreferMore,
+	FormalityPreferLess: FormalityPreferLess,
+	"formal":            FormalityPreferMore,
+	"informal":          FormalityPreferLess,
+}
+
+// NormalizeFormality 规范化语气参数，参数: 客户端传入的取值 (不区分大小写，formal/informal 为 prefer_more/prefer_less 的别名)，返回: 标准取值与是否合法
+// 空值与 default 都返回空字符串，表示不向上游传递
+func NormalizeFormality(formality string) (string, bool) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(formality)), "-", "_")
+	if normalized == "" {
+		return "", true
+	}
+	value, ok := formalityAliases[normalized]
+	if !ok {
+		return "", false
+	}
+	if value == FormalityDefault {
+		return "", true
+	}
+	return value, true
+}
+
+// formalityKey 上下文中保存语气参数的键
+type formalityKey struct{}
+
+// WithFormality 在上下文中携带语气参数，由提供商在构造上游请求时读取，参数: 上下文与标准取值，返回: 新上下文 (取值为空时原样返回)
+func WithFormality(ctx context.Context, formality string) context.Context {
+	if formality == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, formalityKey{}, formality)
+}
+
+// FormalityFromContext 读取上下文中的语气参数，参数: 上下文，返回: 标准取值 (未设置时为空)
+func FormalityFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	formality, _ := ctx.Value(formalityKey{}).(string)
+	return formality
+}
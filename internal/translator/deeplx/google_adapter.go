@@ -2,7 +2,12 @@ package deeplx
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/XgzK/translate-services/internal/langutil"
 	"github.com/XgzK/translate-services/internal/translation"
@@ -11,10 +16,16 @@ import (
 // GoogleTranslator 谷歌翻译接口适配器 (适配器模式，让 DeepLX 兼容谷歌格式喵～)
 // 实现 TranslationService 接口
 type GoogleTranslator struct {
-	translator *DeepLXTranslator
-	name       string
+	translator  *DeepLXTranslator
+	name        string
+	failOnError bool // 上游失败时返回 ErrTranslationFailed 而非原文
+	caps        Capabilities
+	onCall      func(call UpstreamCall) // 上游调用结束后的回调 (可为 nil)
 }
 
+// ErrTranslationFailed 上游翻译失败 (仅在配置 FailOnError 时返回)，同时包装 DeepLXTranslator 的原始错误，可继续用 errors.Is 判断 ErrTimeout 等类别
+var ErrTranslationFailed = errors.New("上游翻译失败")
+
 // NewGoogleTranslator 创建谷歌翻译适配器，参数: API 密钥，返回: 适配器指针或错误
 func NewGoogleTranslator(apiKey string) (*GoogleTranslator, error) {
 	translator, err := NewTranslator(apiKey)
@@ -25,6 +36,7 @@ func NewGoogleTranslator(apiKey string) (*GoogleTranslator, error) {
 	return &GoogleTranslator{
 		translator: translator,
 		name:       "DeepLX",
+		caps:       DefaultCapabilities(),
 	}, nil
 }
 
@@ -35,9 +47,21 @@ func NewGoogleTranslatorWithConfig(config *TranslationServiceConfig) (*GoogleTra
 		return nil, err
 	}
 
+	name := strings.TrimSpace(config.Name)
+	if name == "" {
+		name = "DeepLX"
+	}
+	caps := DefaultCapabilities()
+	if len(config.Languages) > 0 {
+		caps.Languages = slices.Clone(config.Languages)
+	}
+	caps.MaxTextLength = config.MaxTextLength
 	return &GoogleTranslator{
-		translator: translator,
-		name:       "DeepLX",
+		translator:  translator,
+		name:        name,
+		failOnError: config.FailOnError,
+		caps:        caps,
+		onCall:      config.OnUpstreamCall,
 	}, nil
 }
 
@@ -51,42 +75,128 @@ func NewGoogleTranslatorWithClient(apiKey string, client interface{}) (*GoogleTr
 
 	return &GoogleTranslator{
 		translator: translator,
+		caps:       DefaultCapabilities(),
 	}, nil
 }
 
-// translateFunc 翻译函数类型定义，用于抽象不同翻译方法
-type translateFunc func(ctx context.Context, text, targetLang string, sourceLang ...string) *TranslationResult
-
-// doTranslate 执行翻译的公共逻辑 (DRY 原则：抽取重复代码喵～)
-// 参数: 上下文、文本、源语言、目标语言、数据类型、翻译函数，返回: 翻译响应或错误
-func (g *GoogleTranslator) doTranslate(ctx context.Context, q, sl, tl string, dt []string, fn translateFunc) (*translation.Response, error) {
-	var result *TranslationResult
-	if sl != "" && !strings.EqualFold(sl, "auto") {
-		result = fn(ctx, q, tl, sl)
-	} else {
-		result = fn(ctx, q, tl)
+// request 构建翻译器请求，语气取自上下文 (由服务端的请求解析阶段写入)，参数: 上下文、文本、源语言、目标语言、模型名称，返回: 翻译请求
+func request(ctx context.Context, text, sl, tl, model string) Request {
+	return Request{
+		Text:    text,
+		Source:  sl,
+		Target:  tl,
+		Model:   model,
+		Options: Options{Formality: FormalityFromContext(ctx)},
 	}
+}
 
-	if !result.Success {
-		// 即使失败也返回一个基本的响应结构，避免调用方报错
+// doTranslate 执行翻译的公共逻辑 (DRY 原则：抽取重复代码喵～)
+// 参数: 上下文、文本、源语言、目标语言、数据类型、模型名称 (可为空)，返回: 翻译响应或错误
+func (g *GoogleTranslator) doTranslate(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
+	result, err := g.call(ctx, request(ctx, q, sl, tl, model))
+	if err != nil {
+		if g.failOnError || errors.Is(err, ErrEmptyResult) {
+			return nil, fmt.Errorf("%w: %w", ErrTranslationFailed, err)
+		}
+		// 即使失败也返回一个基本的响应结构，避免调用方报错 (上游持续返回空译文时除外，原文不能冒充译文)
 		return g.buildErrorResponse(q, sl, tl), nil
 	}
 
 	return g.convertToGoogleFormat(q, result, dt), nil
 }
 
+// call 调用上游翻译并回调调用结果 (失败且未配置 FailOnError 时同样回调，返回原文之前的错误也能被统计)，参数: 上下文与翻译请求，返回: 翻译结果与错误
+func (g *GoogleTranslator) call(ctx context.Context, req Request) (*TranslationResult, error) {
+	if g.onCall == nil {
+		return g.translator.Translate(ctx, req)
+	}
+	start := time.Now()
+	result, err := g.translator.Translate(ctx, req)
+	call := UpstreamCall{
+		Model:      req.Model,
+		Characters: utf8.RuneCountInString(req.Text),
+		Duration:   time.Since(start),
+		Err:        err,
+	}
+	if result != nil {
+		call.TranslatedCharacters = utf8.RuneCountInString(result.TranslatedText)
+	}
+	g.onCall(call)
+	return result, err
+}
+
 // Translate 执行翻译并返回谷歌格式，参数: 上下文、文本、源语言、目标语言、数据类型，返回: 翻译响应或错误
 func (g *GoogleTranslator) Translate(ctx context.Context, q, sl, tl string, dt []string) (*translation.Response, error) {
-	return g.doTranslate(ctx, q, sl, tl, dt, g.translator.TranslateWithContext)
+	return g.doTranslate(ctx, q, sl, tl, dt, "")
 }
 
 // TranslateWithModel 使用指定模型执行翻译并返回谷歌格式，参数: 上下文、文本、源语言、目标语言、数据类型、模型名称，返回: 翻译响应或错误
 func (g *GoogleTranslator) TranslateWithModel(ctx context.Context, q, sl, tl string, dt []string, model string) (*translation.Response, error) {
-	// 使用闭包捕获 model 参数，适配统一的 translateFunc 签名
-	fn := func(ctx context.Context, text, targetLang string, sourceLang ...string) *TranslationResult {
-		return g.translator.TranslateWithModelContext(ctx, text, targetLang, model, sourceLang...)
+	return g.doTranslate(ctx, q, sl, tl, dt, model)
+}
+
+// batchSeparator 批量翻译时拼接各段文本的分隔符
+// DeepLX 接口只接受单个 text，多段文本按行拼接为一次请求，译文再按行拆回
+const batchSeparator = "\n"
+
+// TranslateBatch 在一次上游调用中翻译多段文本，参数: 上下文、文本列表、源语言、目标语言、数据类型、模型名称 (可为空)，返回: 与文本一一对应的翻译响应或错误
+// 文本自身含换行、或译文行数与原文段数对不上时退回逐段翻译，保证结果不会错位
+func (g *GoogleTranslator) TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	if len(texts) < 2 || slices.ContainsFunc(texts, func(text string) bool { return strings.Contains(text, batchSeparator) }) {
+		return TranslateSequential(ctx, g, texts, sl, tl, dt, model)
 	}
-	return g.doTranslate(ctx, q, sl, tl, dt, fn)
+
+	result, err := g.call(ctx, request(ctx, strings.Join(texts, batchSeparator), sl, tl, model))
+	if err != nil {
+		if g.failOnError || errors.Is(err, ErrEmptyResult) {
+			return nil, fmt.Errorf("%w: %w", ErrTranslationFailed, err)
+		}
+		responses := make([]*translation.Response, len(texts))
+		for i, text := range texts {
+			responses[i] = g.buildErrorResponse(text, sl, tl)
+		}
+		return responses, nil
+	}
+
+	parts := strings.Split(strings.TrimRight(result.TranslatedText, batchSeparator), batchSeparator)
+	if len(parts) != len(texts) {
+		return TranslateSequential(ctx, g, texts, sl, tl, dt, model)
+	}
+	alternatives := splitAlternatives(result.RawResponse, len(texts))
+	responses := make([]*translation.Response, len(texts))
+	for i, text := range texts {
+		segment := *result
+		segment.TranslatedText = parts[i]
+		segment.RawResponse = nil
+		if alternatives != nil {
+			raw := *result.RawResponse
+			raw.Alternatives = alternatives[i]
+			segment.RawResponse = &raw
+		}
+		responses[i] = g.convertToGoogleFormat(text, &segment, dt)
+	}
+	return responses, nil
+}
+
+// splitAlternatives 把合并请求的备选译文按行拆回各段，参数: 上游响应与段数，返回: 每段的备选译文 (行数对不上的备选整体丢弃，无可用备选时为 nil)
+func splitAlternatives(raw *TranslationResponse, count int) [][]string {
+	if raw == nil || len(raw.Alternatives) == 0 {
+		return nil
+	}
+	var split [][]string
+	for _, alternative := range raw.Alternatives {
+		lines := strings.Split(strings.TrimRight(alternative, batchSeparator), batchSeparator)
+		if len(lines) != count {
+			continue
+		}
+		if split == nil {
+			split = make([][]string, count)
+		}
+		for i, line := range lines {
+			split[i] = append(split[i], line)
+		}
+	}
+	return split
 }
 
 // convertToGoogleFormat 将结果转换为谷歌格式，参数: 原文本、翻译结果、数据类型，返回: 翻译响应
@@ -95,30 +205,19 @@ func (g *GoogleTranslator) convertToGoogleFormat(
 	result *TranslationResult,
 	dt []string,
 ) *translation.Response {
-	// 规范化检测到的源语言
-	detectedLang := langutil.NormalizeLanguageCode(result.SourceLang)
-
-	// 如果源语言为空，使用语言检测作为后备 (健壮性处理喵～)
-	if detectedLang == "" {
-		detectedLang = langutil.DetectLanguage(originalText, "")
-	}
-
+	detection := detectSource(originalText, result)
 	resp := &translation.Response{
-		Src: detectedLang,
-		LDResult: &translation.LanguageDetectionResult{
-			Srclangs:            []string{detectedLang},
-			SrclangsConfidences: []float64{0.99},
-		},
+		Src:      detection.Language,
+		LDResult: translation.NewLanguageDetectionResult(detection),
 	}
 
 	// 根据请求的数据类型填充响应 (接口隔离原则：按需提供喵)
 	if langutil.Includes(dt, "t") {
-		// 基本翻译
-		resp.Sentences = append(resp.Sentences, translation.Sentence{
-			Orig:    originalText,
-			Trans:   result.TranslatedText,
-			Backend: 1,
-		})
+		// 基本翻译：与谷歌一致，每个源句一个 Sentence
+		for _, sentence := range translation.AlignSentences(originalText, result.TranslatedText) {
+			sentence.Backend = 1
+			resp.Sentences = append(resp.Sentences, sentence)
+		}
 	}
 
 	if langutil.Includes(dt, "rm") {
@@ -145,6 +244,17 @@ func (g *GoogleTranslator) convertToGoogleFormat(
 		}
 	}
 
+	if langutil.Includes(dt, "at") || langutil.Includes(dt, "bd") {
+		// 备选翻译：使用上游返回的 alternatives
+		if alternatives := upstreamAlternatives(result); len(alternatives) > 0 {
+			resp.AlternativeTranslations = []translation.AlternativeTranslation{{
+				SrcPhrase:     originalText,
+				RawSrcSegment: originalText,
+				Alternative:   alternatives,
+			}}
+		}
+	}
+
 	if langutil.Includes(dt, "qca") {
 		// 拼写检查（DeepLX 不提供，返回原文）
 		resp.Spell = &translation.SpellCheck{
@@ -162,25 +272,72 @@ func (g *GoogleTranslator) convertToGoogleFormat(
 	return resp
 }
 
-// buildErrorResponse 构建错误响应，参数: 文本、源语言、目标语言，返回: 基本翻译响应
-func (g *GoogleTranslator) buildErrorResponse(q, sl, tl string) *translation.Response {
-	detectedLang := sl
-	if detectedLang == "" || strings.EqualFold(detectedLang, "auto") {
-		detectedLang = langutil.DetectLanguage(q, sl)
+// upstreamAlternatives 提取上游返回的备选译文 (去除空值、重复以及与主译文相同的条目)，参数: 翻译结果，返回: 备选翻译列表
+func upstreamAlternatives(result *TranslationResult) []translation.Alternative {
+	if result.RawResponse == nil {
+		return nil
+	}
+	seen := map[string]bool{result.TranslatedText: true}
+	var alternatives []translation.Alternative
+	for _, text := range result.RawResponse.Alternatives {
+		if strings.TrimSpace(text) == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		alternatives = append(alternatives, translation.Alternative{WordPostproc: text})
 	}
+	return alternatives
+}
 
+// detectSource 确定源语言及候选语言，优先使用上游报告的检测结果，上游未报告语言时才退回本地检测，参数: 原文与翻译结果，返回: 检测结果 (首个候选为源语言)
+// 上游报告中文时按简繁专用字确定变体；上游只报告语言时与本地检测各占一半权重：源语言置信度为 (1+本地置信度)/2，其余本地候选的置信度减半
+func detectSource(originalText string, result *TranslationResult) langutil.Detection {
+	detectedLang := langutil.NormalizeLanguageCode(result.SourceLang)
+	if detectedLang == "" {
+		return langutil.Detect(originalText)
+	}
+	if langutil.IsChineseTarget(detectedLang) {
+		// DeepL 等上游只报告 ZH，按简繁专用字区分变体
+		if variant := langutil.ChineseVariant(originalText); variant != "" {
+			detectedLang = variant
+		}
+	}
+	if result.SourceScore > 0 && result.SourceScore <= 1 {
+		return langutil.Detection{
+			Language:   detectedLang,
+			Confidence: result.SourceScore,
+			Candidates: []langutil.Candidate{{Language: detectedLang, Confidence: result.SourceScore}},
+		}
+	}
+
+	reported := langutil.Candidate{Language: detectedLang, Confidence: 0.5}
+	var others []langutil.Candidate
+	for _, c := range langutil.Detect(originalText).Candidates {
+		if c.Language == detectedLang {
+			reported.Confidence += c.Confidence / 2
+			continue
+		}
+		others = append(others, langutil.Candidate{Language: c.Language, Confidence: c.Confidence / 2})
+	}
+	return langutil.Detection{
+		Language:   detectedLang,
+		Confidence: reported.Confidence,
+		Candidates: append([]langutil.Candidate{reported}, others...),
+	}
+}
+
+// buildErrorResponse 构建错误响应，参数: 文本、源语言、目标语言，返回: 基本翻译响应
+func (g *GoogleTranslator) buildErrorResponse(q, sl, tl string) *translation.Response {
+	detection := langutil.DetectSource(q, sl)
 	return &translation.Response{
-		Src: detectedLang,
+		Src: detection.Language,
 		Sentences: []translation.Sentence{
 			{
 				Orig:  q,
 				Trans: q, // 翻译失败时返回原文
 			},
 		},
-		LDResult: &translation.LanguageDetectionResult{
-			Srclangs:            []string{detectedLang},
-			SrclangsConfidences: []float64{0.5},
-		},
+		LDResult: translation.NewLanguageDetectionResult(detection),
 	}
 }
 
@@ -193,8 +350,26 @@ func (g *GoogleTranslator) GetName() string {
 
 // IsAvailable 检查服务是否可用，参数: 无，返回: 布尔
 func (g *GoogleTranslator) IsAvailable() bool {
-	// 检查翻译器是否已初始化
-	return g.translator != nil && g.translator.apiKey != ""
+	// 检查翻译器是否已初始化 (无需密钥的中继不配置密钥)
+	return g.translator != nil
+}
+
+// Healthcheck 探测 DeepLX 上游，参数: 上下文，返回: 不健康时包装 ErrUnhealthy 的错误
+func (g *GoogleTranslator) Healthcheck(ctx context.Context) error {
+	if !g.IsAvailable() {
+		return fmt.Errorf("%w: 翻译器未初始化", ErrUnhealthy)
+	}
+	return g.translator.Healthcheck(ctx)
+}
+
+// Capabilities 返回提供商能力，参数: 无，返回: 能力
+func (g *GoogleTranslator) Capabilities() Capabilities {
+	return g.caps
+}
+
+// DetectLanguage DeepLX 没有独立的语言检测接口，检测结果只随译文返回，参数: 上下文与文本，返回: ErrDetectionNotSupported
+func (g *GoogleTranslator) DetectLanguage(ctx context.Context, text string) (*Detection, error) {
+	return nil, ErrDetectionNotSupported
 }
 
 // SetName 设置服务名称，参数: 名称字符串，返回: 无
@@ -2,8 +2,10 @@ package deeplx
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/XgzK/translate-services/internal/langutil"
@@ -22,14 +24,14 @@ func TestNewGoogleTranslator(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "空 API 密钥",
+			name:    "无需密钥的中继",
 			apiKey:  "",
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name:    "无效前缀的 API 密钥",
-			apiKey:  "invalid-key",
-			wantErr: true,
+			name:    "其他格式的 API 密钥",
+			apiKey:  "relay-token",
+			wantErr: false,
 		},
 	}
 
@@ -301,7 +303,6 @@ func TestConvertToGoogleFormat(t *testing.T) {
 	adapter, _ := NewGoogleTranslator(testAPIKey)
 
 	result := &TranslationResult{
-		Success:        true,
 		TranslatedText: "你好，世界！",
 		SourceLang:     "EN",
 		TargetLang:     "ZH",
@@ -343,6 +344,64 @@ func TestConvertToGoogleFormat(t *testing.T) {
 	}
 }
 
+// TestConvertAlternatives 测试上游备选译文写入 alternative_translations，参数: 测试实例，返回: 无
+func TestConvertAlternatives(t *testing.T) {
+	adapter, _ := NewGoogleTranslator(testAPIKey)
+	result := &TranslationResult{
+		TranslatedText: "你好",
+		SourceLang:     "EN",
+		RawResponse:    &TranslationResponse{Alternatives: []string{"您好", "你好", "", "您好", "嗨"}},
+	}
+
+	resp := adapter.convertToGoogleFormat("Hello", result, []string{"t", "at"})
+	if len(resp.AlternativeTranslations) != 1 {
+		t.Fatalf("alternative_translations = %+v", resp.AlternativeTranslations)
+	}
+	alt := resp.AlternativeTranslations[0]
+	if alt.SrcPhrase != "Hello" || len(alt.Alternative) != 2 || alt.Alternative[0].WordPostproc != "您好" || alt.Alternative[1].WordPostproc != "嗨" {
+		t.Errorf("alternative = %+v", alt)
+	}
+
+	if resp := adapter.convertToGoogleFormat("Hello", result, []string{"t"}); resp.AlternativeTranslations != nil {
+		t.Errorf("未请求 at/bd 时不应返回备选译文: %+v", resp.AlternativeTranslations)
+	}
+
+	split := splitAlternatives(&TranslationResponse{Alternatives: []string{"a1\nb1", "only-one", "a2\nb2\n"}}, 2)
+	if len(split) != 2 || strings.Join(split[0], ",") != "a1,a2" || strings.Join(split[1], ",") != "b1,b2" {
+		t.Errorf("splitAlternatives() = %v", split)
+	}
+}
+
+// TestDetectSource 测试优先使用上游报告的源语言与置信度，参数: 测试实例，返回: 无
+func TestDetectSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *TranslationResult
+		wantLang  string
+		wantScore float64
+	}{
+		{"上游报告语言与置信度", &TranslationResult{SourceLang: "DE", SourceScore: 0.87}, "de", 0.87},
+		{"上游只报告语言且与本地检测一致", &TranslationResult{SourceLang: "ZH"}, "zh-CN", 1},
+		{"上游只报告语言但本地检测不同", &TranslationResult{SourceLang: "DE"}, "de", 0.5},
+		{"置信度越界", &TranslationResult{SourceLang: "DE", SourceScore: 87}, "de", 0.5},
+		{"上游未报告", &TranslationResult{}, "zh-CN", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectSource("你好", tt.result)
+			if got.Language != tt.wantLang || got.Confidence != tt.wantScore {
+				t.Errorf("detectSource() = %q, %v, want %q, %v", got.Language, got.Confidence, tt.wantLang, tt.wantScore)
+			}
+			if len(got.Candidates) == 0 || got.Candidates[0].Language != got.Language {
+				t.Errorf("首个候选应为源语言: %+v", got.Candidates)
+			}
+		})
+	}
+	if got := detectSource("這個問題很難", &TranslationResult{SourceLang: "ZH"}); got.Language != "zh-TW" {
+		t.Errorf("上游报告 ZH 的繁体文本 = %q, want zh-TW", got.Language)
+	}
+}
+
 // TestBuildErrorResponse 测试错误响应构建，参数: 测试实例，返回: 无
 func TestBuildErrorResponse(t *testing.T) {
 	adapter, _ := NewGoogleTranslator(testAPIKey)
@@ -404,3 +463,95 @@ func BenchmarkGoogleTranslate(b *testing.B) {
 		adapter.Translate(context.Background(), "Benchmark test", "EN", "ZH", []string{"t"})
 	}
 }
+
+// TestGoogleTranslatorFailOnError 测试配置 FailOnError 后上游失败返回错误而非原文，参数: 测试实例，返回: 无
+func TestGoogleTranslatorFailOnError(t *testing.T) {
+	adapter, err := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{
+		APIKey:      testAPIKey,
+		Name:        "backup",
+		FailOnError: true,
+		Transport:   &fakeTransport{errs: []error{&TransportError{Message: "bad request"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewGoogleTranslatorWithConfig() error = %v", err)
+	}
+	if adapter.GetName() != "backup" {
+		t.Errorf("GetName() = %q, want backup", adapter.GetName())
+	}
+
+	resp, err := adapter.Translate(context.Background(), "Hello", "en", "zh", nil)
+	if !errors.Is(err, ErrTranslationFailed) || resp != nil {
+		t.Fatalf("Translate() = %v, %v, want ErrTranslationFailed", resp, err)
+	}
+}
+
+// TestGoogleTranslatorUpstreamCall 测试每次上游调用都回调模型、字符数与错误 (未配置 FailOnError 返回原文时也回调)，参数: 测试实例，返回: 无
+func TestGoogleTranslatorUpstreamCall(t *testing.T) {
+	var calls []UpstreamCall
+	adapter, err := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{
+		APIKey:         testAPIKey,
+		Transport:      &fakeTransport{errs: []error{&TransportError{Message: "HTTP 401", StatusCode: http.StatusUnauthorized}}},
+		OnUpstreamCall: func(call UpstreamCall) { calls = append(calls, call) },
+	})
+	if err != nil {
+		t.Fatalf("NewGoogleTranslatorWithConfig() error = %v", err)
+	}
+
+	if _, err := adapter.Translate(context.Background(), "你好", "zh", "en", nil); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if _, err := adapter.TranslateWithModel(context.Background(), "Hello", "en", "zh", nil, "gpt-4o"); err != nil {
+		t.Fatalf("TranslateWithModel() error = %v", err)
+	}
+	if _, err := adapter.TranslateBatch(context.Background(), []string{"a", "b"}, "en", "zh", nil, ""); err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("calls = %d, want 3", len(calls))
+	}
+	if ErrorClass(calls[0].Err) != ErrorClassUnauthorized || calls[0].Characters != 2 || calls[0].TranslatedCharacters != 0 {
+		t.Errorf("calls[0] = %+v, want unauthorized with 2 characters", calls[0])
+	}
+	if calls[1].Err != nil || calls[1].Model != "gpt-4o" || calls[1].Characters != 5 || calls[1].TranslatedCharacters != len([]rune("译文:Hello")) {
+		t.Errorf("calls[1] = %+v", calls[1])
+	}
+	if calls[2].Err != nil || calls[2].Characters != len("a\nb") {
+		t.Errorf("calls[2] = %+v, want one batched call", calls[2])
+	}
+}
+
+// TestGoogleTranslatorTranslateBatch 测试多段文本合并为一次上游调用，含换行的文本退回逐段翻译，参数: 测试实例，返回: 无
+func TestGoogleTranslatorTranslateBatch(t *testing.T) {
+	transport := &fakeTransport{}
+	adapter, err := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{APIKey: testAPIKey, Transport: transport})
+	if err != nil {
+		t.Fatalf("NewGoogleTranslatorWithConfig() error = %v", err)
+	}
+
+	responses, err := adapter.TranslateBatch(context.Background(), []string{"one", "two", "three"}, "en", "zh", []string{"t"}, "")
+	if err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+	if transport.calls != 1 {
+		t.Errorf("upstream calls = %d, want 1", transport.calls)
+	}
+	want := []string{"译文:one", "two", "three"}
+	for i, resp := range responses {
+		if resp.Sentences[0].Orig != []string{"one", "two", "three"}[i] || resp.Sentences[0].Trans != want[i] {
+			t.Errorf("responses[%d] = %+v, want trans %q", i, resp.Sentences[0], want[i])
+		}
+	}
+
+	transport.calls = 0
+	responses, err = adapter.TranslateBatch(context.Background(), []string{"line\nbreak", "two"}, "en", "zh", []string{"t"}, "gpt-4o")
+	if err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+	if transport.calls != 2 || transport.model != "gpt-4o" {
+		t.Errorf("含换行时应逐段调用，calls = %d, model = %q", transport.calls, transport.model)
+	}
+	if len(responses) != 2 || responses[1].Sentences[0].Trans != "译文:two" {
+		t.Errorf("responses = %+v", responses)
+	}
+}
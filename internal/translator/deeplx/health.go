@@ -0,0 +1,72 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnhealthy 提供商健康检查失败，包装具体原因
+var ErrUnhealthy = errors.New("提供商健康检查失败")
+
+// HealthChecker 可选接口，自定义传输实现后由 DeepLXTranslator.Healthcheck 调用
+type HealthChecker interface {
+	// Healthcheck 执行一次廉价的上游探测，参数: 上下文，返回: 不健康时的错误
+	Healthcheck(ctx context.Context) error
+}
+
+// errProbeStatus 探测收到 5xx 响应
+var errProbeStatus = errors.New("探测返回 5xx")
+
+// probeURL 以 GET 请求探测地址 (不带密钥，不消耗额度)，收到任何非 5xx 响应即视为可用，参数: 上下文、HTTP 客户端与地址，返回: 错误
+func probeURL(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errProbeStatus
+	}
+	return nil
+}
+
+// Healthcheck 执行一次不消耗额度的上游探测，参数: 上下文，返回: 不健康时包装 ErrUnhealthy 的错误
+// 全部密钥被隔离或全部中继地址被剔除时直接视为不健康；否则探测当前选中的地址 (结果计入地址池)，自定义传输实现 HealthChecker 时改为调用它
+func (t *DeepLXTranslator) Healthcheck(ctx context.Context) error {
+	if t.transport != nil {
+		checker, ok := t.transport.(HealthChecker)
+		if !ok {
+			return nil
+		}
+		if err := checker.Healthcheck(ctx); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnhealthy, err)
+		}
+		return nil
+	}
+	if t.keys != nil && t.keys.Available() == 0 {
+		return fmt.Errorf("%w: 全部密钥已被隔离", ErrUnhealthy)
+	}
+	if t.endpoints == nil {
+		if err := probeURL(ctx, t.httpClient, t.baseURL); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnhealthy, err)
+		}
+		return nil
+	}
+	if t.endpoints.Available() == 0 {
+		return fmt.Errorf("%w: 全部中继地址已被剔除", ErrUnhealthy)
+	}
+	endpoint := t.endpoints.Next()
+	if err := t.endpoints.probe(ctx, endpoint); err != nil {
+		if ctx.Err() == nil {
+			t.endpoints.Failure(endpoint)
+		}
+		return fmt.Errorf("%w: %s: %w", ErrUnhealthy, endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package deeplx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHealthcheck 测试按上游探测结果、地址池与自定义传输判断健康，参数: 测试实例，返回: 无
+func TestHealthcheck(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	newTranslator := func(urls ...string) *DeepLXTranslator {
+		translator, err := NewTranslatorWithConfig(&TranslationServiceConfig{BaseURLs: urls, EndpointOptions: EndpointOptions{MaxFailures: 1}})
+		if err != nil {
+			t.Fatalf("NewTranslatorWithConfig() error = %v", err)
+		}
+		return translator
+	}
+
+	if err := newTranslator(up.URL).Healthcheck(context.Background()); err != nil {
+		t.Errorf("非 5xx 响应应视为健康: %v", err)
+	}
+	if err := newTranslator(down.URL).Healthcheck(context.Background()); !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("5xx 响应应返回 ErrUnhealthy, got %v", err)
+	}
+
+	pool := newTranslator(down.URL, down.URL+"/v2")
+	for range 2 {
+		if err := pool.Healthcheck(context.Background()); !errors.Is(err, ErrUnhealthy) {
+			t.Fatalf("探测失败应返回 ErrUnhealthy, got %v", err)
+		}
+	}
+	if pool.endpoints.Available() != 0 {
+		t.Fatalf("探测失败应计入地址池, Available() = %d", pool.endpoints.Available())
+	}
+
+	custom, err := NewTranslatorWithTransport("", &fakeTransport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := custom.Healthcheck(context.Background()); err != nil {
+		t.Errorf("未实现 HealthChecker 的自定义传输应视为健康: %v", err)
+	}
+}
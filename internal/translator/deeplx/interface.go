@@ -2,6 +2,7 @@ package deeplx
 
 import (
 	"context"
+	"time"
 
 	"github.com/XgzK/translate-services/internal/translation"
 )
@@ -29,11 +30,89 @@ type TranslationService interface {
 
 	// IsAvailable 检查服务是否可用，参数: 无，返回: 布尔值
 	IsAvailable() bool
+
+	// Healthcheck 执行一次廉价的上游探测 (不消耗翻译额度)，由后台监控定期调用，参数: 上下文，返回: 不健康时的错误
+	// IsAvailable 只检查配置是否完整，Healthcheck 才反映上游当前是否可用
+	Healthcheck(ctx context.Context) error
+
+	// TranslateBatch 翻译多段文本，参数: 上下文、文本列表、源语言、目标语言、数据类型、模型名称 (为空使用默认模型)，返回: 与 texts 一一对应的翻译响应与错误
+	// 支持原生批量的提供商在一次上游调用中完成 (Capabilities().SupportsBatch 为 true)，其余提供商可用 TranslateSequential 逐段调用
+	TranslateBatch(ctx context.Context, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error)
+
+	// DetectLanguage 使用提供商自己的接口检测文本语言，参数: 上下文与文本，返回: 检测结果与错误
+	// 没有独立检测接口的提供商返回 ErrDetectionNotSupported (Capabilities().SupportsDetection 为 false)，调用方可用 DetectWithFallback 退回本地检测
+	DetectLanguage(ctx context.Context, text string) (*Detection, error)
+
+	// Capabilities 返回提供商能力 (支持的语言、文本长度上限等)，调用上游前按此校验请求，参数: 无，返回: 能力
+	Capabilities() Capabilities
+}
+
+// TranslateSequential 逐段调用 TranslateWithModel (model 为空时调用 Translate) 的默认批量实现，供不支持原生批量的提供商使用
+// 参数: 上下文、翻译服务、文本列表、源语言、目标语言、数据类型、模型名称，返回: 与 texts 一一对应的翻译响应与错误 (任一段失败即返回)
+func TranslateSequential(ctx context.Context, service TranslationService, texts []string, sl, tl string, dt []string, model string) ([]*translation.Response, error) {
+	responses := make([]*translation.Response, len(texts))
+	for i, text := range texts {
+		var err error
+		if model != "" {
+			responses[i], err = service.TranslateWithModel(ctx, text, sl, tl, dt, model)
+		} else {
+			responses[i], err = service.Translate(ctx, text, sl, tl, dt)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
 }
 
 // TranslationServiceConfig 翻译服务配置 (统一的配置接口喵)
 type TranslationServiceConfig struct {
-	APIKey  string // API 密钥
+	APIKey  string // API 密钥（无需密钥的自建中继可为空）
 	BaseURL string // 基础 URL（可选）
 	Timeout int    // 超时时间（秒）
+	Name    string // 提供商名称（可选），默认 DeepLX
+
+	// AdaptiveTimeout 按文本长度计算单次请求超时（可选），设置后取代 Timeout
+	AdaptiveTimeout *AdaptiveTimeout
+
+	// RetryBudget 跨请求共享的重试预算（可选），预算耗尽时可重试的失败也不再重试
+	RetryBudget *RetryBudget
+
+	// APIKeys 额外的 API 密钥（可选），与 APIKey 一起按 KeyStrategy 轮换，被拒绝的密钥隔离 KeyQuarantine
+	APIKeys       []WeightedKey
+	KeyStrategy   KeyStrategy
+	KeyQuarantine time.Duration
+
+	// KeyPattern API 密钥格式（可选，正则表达式），设置后不符合的密钥在创建时报错
+	KeyPattern string
+
+	// BaseURLs 额外的中继地址（可选），与 BaseURL 一起按健康状况与延迟选择，连续失败的地址被暂时剔除
+	BaseURLs        []string
+	EndpointOptions EndpointOptions
+
+	// Languages 支持的语言（可选，主语言代码），为空时使用 DeepL 的语言列表，含 "*" 时不限制
+	// MaxTextLength 单次请求文本的最大字符数（可选），0 表示不限制
+	Languages     []string
+	MaxTextLength int
+
+	// FailOnError 上游失败时返回错误而非原文（可选），供故障转移切换到下一个提供商
+	FailOnError bool
+
+	// Transport 自定义底层传输（可选），为空时使用 HTTP
+	Transport Transport
+
+	// Prompt 提示词模板（可选，仅 openai 类型的大模型提供商使用），为空的字段使用默认模板
+	Prompt PromptTemplate
+
+	// OnUpstreamCall 每次上游翻译调用 (含重试) 结束后回调（可选），用于按提供商与模型记录指标
+	OnUpstreamCall func(call UpstreamCall)
+}
+
+// UpstreamCall 一次上游翻译调用的结果，批量合并的请求计为一次调用
+type UpstreamCall struct {
+	Model                string        // 模型名称，为空表示上游默认模型
+	Characters           int           // 发往上游的字符数
+	TranslatedCharacters int           // 译文字符数 (失败时为 0)
+	Duration             time.Duration // 耗时 (含重试与退避)
+	Err                  error         // 失败时的错误，可用 ErrorClass 取得类别
 }
@@ -0,0 +1,126 @@
+package deeplx
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeyStrategy 多密钥的选择策略
+type KeyStrategy string
+
+const (
+	KeyStrategyRoundRobin KeyStrategy = "round_robin" // 依次轮换，忽略权重
+	KeyStrategyWeighted   KeyStrategy = "weighted"    // 按权重平滑轮换
+)
+
+// defaultKeyQuarantine 密钥被拒绝后的默认隔离时长
+const defaultKeyQuarantine = 5 * time.Minute
+
+// WeightedKey 带权重的 API 密钥
+type WeightedKey struct {
+	Key    string
+	Weight int // 加权策略下的权重，<=0 视为 1
+}
+
+// ErrNoAvailableKey 密钥池中全部密钥都处于隔离期
+var ErrNoAvailableKey = errors.New("全部 API 密钥均已被隔离")
+
+// KeyPool 同一提供商的多个 API 密钥，按策略轮换选择，返回鉴权/额度错误的密钥被暂时隔离，并发安全
+type KeyPool struct {
+	mu         sync.Mutex
+	keys       []*poolKey
+	quarantine time.Duration
+	now        func() time.Time
+}
+
+type poolKey struct {
+	key              string
+	weight           int
+	current          int // 平滑加权轮询的当前权重
+	quarantinedUntil time.Time
+}
+
+// NewKeyPool 创建密钥池，参数: 密钥列表、选择策略与隔离时长 (<=0 使用默认 5 分钟)，返回: KeyPool 指针
+func NewKeyPool(keys []WeightedKey, strategy KeyStrategy, quarantine time.Duration) *KeyPool {
+	if quarantine <= 0 {
+		quarantine = defaultKeyQuarantine
+	}
+	pool := &KeyPool{quarantine: quarantine, now: time.Now}
+	for _, k := range keys {
+		weight := k.Weight
+		if weight <= 0 || strategy != KeyStrategyWeighted {
+			weight = 1
+		}
+		pool.keys = append(pool.keys, &poolKey{key: k.Key, weight: weight})
+	}
+	return pool
+}
+
+// Next 选择下一个可用密钥 (平滑加权轮询，权重相同时即依次轮换)，参数: 无，返回: 密钥或 ErrNoAvailableKey
+func (p *KeyPool) Next() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var best *poolKey
+	total := 0
+	for _, k := range p.keys {
+		if now.Before(k.quarantinedUntil) {
+			continue
+		}
+		k.current += k.weight
+		total += k.weight
+		if best == nil || k.current > best.current {
+			best = k
+		}
+	}
+	if best == nil {
+		return "", ErrNoAvailableKey
+	}
+	best.current -= total
+	return best.key, nil
+}
+
+// Quarantine 隔离密钥，隔离期内 Next 不再选择它，参数: 密钥，返回: 无
+func (p *KeyPool) Quarantine(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.key == key {
+			k.quarantinedUntil = p.now().Add(p.quarantine)
+			k.current = 0
+		}
+	}
+}
+
+// Available 返回当前未被隔离的密钥数量，参数: 无，返回: 数量
+func (p *KeyPool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	n := 0
+	for _, k := range p.keys {
+		if !now.Before(k.quarantinedUntil) {
+			n++
+		}
+	}
+	return n
+}
+
+// isKeyRejected 判断错误是否表示密钥被上游拒绝 (鉴权失败或额度耗尽)，参数: 错误，返回: 布尔
+func isKeyRejected(err error) bool {
+	var te *TransportError
+	if !errors.As(err, &te) {
+		return false
+	}
+	switch te.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, statusQuotaExceeded:
+		return true
+	}
+	return false
+}
+
+// statusQuotaExceeded DeepL 兼容接口表示额度耗尽的状态码
+const statusQuotaExceeded = 456
@@ -0,0 +1,100 @@
+package deeplx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestKeyPoolWeighted 测试加权轮换按权重分配且交错选择，参数: 测试实例，返回: 无
+func TestKeyPoolWeighted(t *testing.T) {
+	pool := NewKeyPool([]WeightedKey{{Key: "sk-a", Weight: 3}, {Key: "sk-b", Weight: 1}}, KeyStrategyWeighted, 0)
+
+	var picks []string
+	for i := 0; i < 8; i++ {
+		key, err := pool.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		picks = append(picks, strings.TrimPrefix(key, "sk-"))
+	}
+	if got := strings.Join(picks, ""); got != "aabaaaba" {
+		t.Errorf("加权轮换顺序 = %s, want aabaaaba", got)
+	}
+
+	rr := NewKeyPool([]WeightedKey{{Key: "sk-a", Weight: 3}, {Key: "sk-b", Weight: 1}}, KeyStrategyRoundRobin, 0)
+	first, _ := rr.Next()
+	second, _ := rr.Next()
+	if first == second {
+		t.Error("轮询策略应忽略权重依次轮换")
+	}
+}
+
+// TestKeyPoolQuarantine 测试被隔离的密钥在隔离期内不再被选择，参数: 测试实例，返回: 无
+func TestKeyPoolQuarantine(t *testing.T) {
+	pool := NewKeyPool([]WeightedKey{{Key: "sk-a"}, {Key: "sk-b"}}, KeyStrategyRoundRobin, time.Minute)
+	now := time.Unix(1000, 0)
+	pool.now = func() time.Time { return now }
+
+	pool.Quarantine("sk-a")
+	for i := 0; i < 3; i++ {
+		if key, _ := pool.Next(); key != "sk-b" {
+			t.Fatalf("Next() = %s, 隔离期内不应选择 sk-a", key)
+		}
+	}
+
+	pool.Quarantine("sk-b")
+	if _, err := pool.Next(); err != ErrNoAvailableKey {
+		t.Fatalf("Next() error = %v, want ErrNoAvailableKey", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if pool.Available() != 2 {
+		t.Errorf("Available() = %d, 隔离期后应恢复", pool.Available())
+	}
+}
+
+// TestTranslateRotatesRejectedKey 测试密钥被上游拒绝时隔离并换用下一个密钥，参数: 测试实例，返回: 无
+func TestTranslateRotatesRejectedKey(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/sk-revoked") {
+			http.Error(w, "invalid key", http.StatusUnauthorized)
+			return
+		}
+		mockServerHandler(w, r)
+	}))
+	defer server.Close()
+
+	translator, err := NewTranslatorWithConfig(&TranslationServiceConfig{
+		APIKey:  "sk-revoked",
+		APIKeys: []WeightedKey{{Key: "sk-valid"}},
+		BaseURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewTranslatorWithConfig() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "ZH"}); err != nil {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	}
+	revoked := 0
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/sk-revoked") {
+			revoked++
+		}
+	}
+	if revoked != 1 {
+		t.Errorf("被拒绝的密钥请求了 %d 次，隔离后不应再使用", revoked)
+	}
+}
@@ -0,0 +1,220 @@
+package deeplx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+)
+
+// 大模型提供商默认配置
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	openAIChatPath       = "/chat/completions"
+
+	// DefaultSystemPrompt 默认的系统提示词模板
+	DefaultSystemPrompt = "You are a professional translator. Translate the user's text{{if .SourceLang}} from {{.SourceLang}}{{end}} into {{.TargetLang}}." +
+		"{{if .Formality}} Use a {{.Formality}} tone.{{end}} Keep the original formatting and line breaks, and reply with the translation only."
+	// DefaultUserPrompt 默认的用户提示词模板
+	DefaultUserPrompt = "{{.Text}}"
+)
+
+// PromptTemplate 大模型提供商的提示词模板 (text/template 语法)，为空的字段使用默认模板，可用字段见 PromptData
+type PromptTemplate struct {
+	System string
+	User   string
+}
+
+// PromptData 渲染提示词模板的数据，参数: 无，返回: 无
+type PromptData struct {
+	Text       string // 待翻译文本
+	SourceLang string // 源语言英文名称 (如 German)，自动检测时为空
+	TargetLang string // 目标语言英文名称 (如 Simplified Chinese)
+	SourceCode string // 源语言代码 (谷歌格式)，自动检测时为空
+	TargetCode string // 目标语言代码 (谷歌格式，如 zh-CN)
+	Formality  string // 语气 (more/less/prefer_more/prefer_less 对应 formal/informal)，未指定时为空
+}
+
+// prompt 解析后的提示词模板
+type prompt struct {
+	system *template.Template
+	user   *template.Template
+}
+
+// parsePrompt 解析提示词模板，参数: 模板 (为空的字段使用默认模板)，返回: 解析后的模板与错误
+func parsePrompt(t PromptTemplate) (*prompt, error) {
+	system, err := parsePromptPart("system", t.System, DefaultSystemPrompt)
+	if err != nil {
+		return nil, err
+	}
+	user, err := parsePromptPart("user", t.User, DefaultUserPrompt)
+	if err != nil {
+		return nil, err
+	}
+	return &prompt{system: system, user: user}, nil
+}
+
+// parsePromptPart 解析一段提示词模板，参数: 名称、模板文本与默认模板，返回: 模板与错误
+// 引用 PromptData 中不存在的字段在解析时无法发现，渲染时报错
+func parsePromptPart(name, text, fallback string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 %s 提示词模板: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// render 渲染系统与用户提示词，参数: 模板数据，返回: 系统提示词、用户提示词与错误
+func (p *prompt) render(data PromptData) (string, string, error) {
+	var system, user strings.Builder
+	if err := p.system.Execute(&system, data); err != nil {
+		return "", "", fmt.Errorf("渲染 system 提示词失败: %w", err)
+	}
+	if err := p.user.Execute(&user, data); err != nil {
+		return "", "", fmt.Errorf("渲染 user 提示词失败: %w", err)
+	}
+	return system.String(), user.String(), nil
+}
+
+// promptData 从 DeepL 格式的翻译请求构建模板数据，参数: 翻译请求，返回: 模板数据
+func promptData(req TranslationRequest) PromptData {
+	data := PromptData{
+		Text:       req.Text,
+		SourceCode: langutil.DeepL.Internal(req.SourceLang),
+		TargetCode: langutil.DeepL.Internal(req.TargetLang),
+	}
+	if data.SourceCode != "" {
+		data.SourceLang = langutil.DisplayName(data.SourceCode, "en")
+	}
+	data.TargetLang = langutil.DisplayName(data.TargetCode, "en")
+	switch req.Formality {
+	case "more", "prefer_more":
+		data.Formality = "formal"
+	case "less", "prefer_less":
+		data.Formality = "informal"
+	}
+	return data
+}
+
+// openAIMessage 对话消息
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIChatRequest 对话补全请求
+type openAIChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+// openAIChatResponse 对话补全响应 (只解析用到的字段)
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// OpenAITransport OpenAI 兼容的对话补全接口传输 (OpenAI、Ollama 的 /v1、Gemini 的 OpenAI 兼容接口等)，按提示词模板构造消息
+type OpenAITransport struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	prompt  *prompt
+}
+
+// NewOpenAITransport 创建 OpenAI 兼容传输，参数: HTTP 客户端、基础 URL (如 https://api.openai.com/v1)、API 密钥 (可为空) 与提示词模板，返回: 传输或模板错误
+func NewOpenAITransport(client *http.Client, baseURL, apiKey string, tmpl PromptTemplate) (*OpenAITransport, error) {
+	p, err := parsePrompt(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = defaultHTTPClient(defaultClientTimeout)
+	}
+	return &OpenAITransport{client: client, baseURL: baseURL, apiKey: apiKey, prompt: p}, nil
+}
+
+// RoundTrip 实现 Transport 接口，译文取第一个候选的消息内容；大模型不报告源语言，SourceLang 为请求的源语言
+func (o *OpenAITransport) RoundTrip(ctx context.Context, req TranslationRequest, model string) (*TranslationResponse, error) {
+	if model == "" {
+		return nil, &TransportError{Message: "大模型提供商需要配置 model"}
+	}
+	system, user, err := o.prompt.render(promptData(req))
+	if err != nil {
+		return nil, &TransportError{Message: err.Error(), Err: err}
+	}
+
+	var headers map[string]string
+	if o.apiKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + o.apiKey}
+	}
+	body, err := postJSON(ctx, o.client, o.chatURL(), headers, openAIChatRequest{
+		Model: model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var chat openAIChatResponse
+	if err := json.Unmarshal(body, &chat); err != nil {
+		return nil, &TransportError{Message: fmt.Sprintf("解析响应失败: %v", err), Retryable: true, Err: err}
+	}
+	if len(chat.Choices) == 0 {
+		return nil, &TransportError{Message: "上游没有返回候选译文", Retryable: true}
+	}
+	return &TranslationResponse{
+		Code:       http.StatusOK,
+		Data:       strings.TrimSpace(chat.Choices[0].Message.Content),
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+	}, nil
+}
+
+// chatURL 返回对话补全地址，基础 URL 已以 /chat/completions 结尾时原样使用，参数: 无，返回: URL
+func (o *OpenAITransport) chatURL() string {
+	url := strings.TrimSuffix(o.baseURL, "/")
+	if strings.HasSuffix(url, openAIChatPath) {
+		return url
+	}
+	return url + openAIChatPath
+}
+
+// createOpenAIService 创建 OpenAI 兼容的大模型翻译服务，参数: 配置，返回: 翻译服务或错误
+// 未配置地址时使用 OpenAI 官方地址，未限制语言时不按 DeepL 语言列表校验
+func createOpenAIService(config *TranslationServiceConfig) (TranslationService, error) {
+	p, err := parsePrompt(config.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	cfg := *config
+	if strings.TrimSpace(cfg.BaseURL) == "" && len(cfg.BaseURLs) == 0 {
+		cfg.BaseURL = defaultOpenAIBaseURL
+	}
+	if strings.TrimSpace(cfg.Name) == "" {
+		cfg.Name = "OpenAI"
+	}
+	service, err := NewGoogleTranslatorWithConfig(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建 OpenAI 服务失败: %w", err)
+	}
+	if len(cfg.Languages) == 0 {
+		service.caps.Languages = []string{AnyLanguage}
+	}
+	service.translator.newHTTPTransport = func(client *http.Client, baseURL, apiKey string) Transport {
+		return &OpenAITransport{client: client, baseURL: baseURL, apiKey: apiKey, prompt: p}
+	}
+	return service, nil
+}
@@ -0,0 +1,97 @@
+package deeplx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestOpenAIService 测试大模型提供商按提示词模板构造对话请求并取第一个候选为译文，参数: 测试实例，返回: 无
+func TestOpenAIService(t *testing.T) {
+	var got openAIChatRequest
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" 你好，世界 \n"}}]}`))
+	}))
+	defer server.Close()
+
+	service, err := NewFactory().CreateService(ServiceTypeOpenAI, &TranslationServiceConfig{
+		APIKey:  "sk-openai",
+		BaseURL: server.URL + "/v1",
+		Prompt: PromptTemplate{
+			System: "Translate{{if .SourceLang}} from {{.SourceLang}} ({{.SourceCode}}){{end}} to {{.TargetLang}} ({{.TargetCode}}).{{if .Formality}} {{.Formality}}{{end}}",
+			User:   "<text>{{.Text}}</text>",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if !service.Capabilities().SupportsLanguage("sw") {
+		t.Error("openai 提供商默认应不限制语言")
+	}
+
+	ctx := WithFormality(context.Background(), "less")
+	resp, err := service.TranslateWithModel(ctx, "Hello, world", "de", "zh-TW", []string{"t"}, "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("TranslateWithModel() error = %v", err)
+	}
+	if path != "/v1/chat/completions" || auth != "Bearer sk-openai" || got.Model != "gpt-4o-mini" {
+		t.Errorf("path = %q, auth = %q, model = %q", path, auth, got.Model)
+	}
+	wantMessages := []openAIMessage{
+		{Role: "system", Content: "Translate from German (de) to Traditional Chinese (zh-TW). informal"},
+		{Role: "user", Content: "<text>Hello, world</text>"},
+	}
+	if len(got.Messages) != 2 || got.Messages[0] != wantMessages[0] || got.Messages[1] != wantMessages[1] {
+		t.Errorf("messages = %+v, want %+v", got.Messages, wantMessages)
+	}
+	if len(resp.Sentences) == 0 || resp.Sentences[0].Trans != "你好，世界" {
+		t.Errorf("Translate() = %+v", resp.Sentences)
+	}
+
+	// 自动检测时模板中的源语言为空
+	if _, err := service.TranslateWithModel(context.Background(), "Hello", "auto", "en", nil, "gpt-4o-mini"); err != nil {
+		t.Fatalf("TranslateWithModel() error = %v", err)
+	}
+	if want := "Translate to English (en)."; got.Messages[0].Content != want {
+		t.Errorf("system prompt = %q, want %q", got.Messages[0].Content, want)
+	}
+}
+
+// TestOpenAIServiceErrors 测试无效模板在创建时报错、未配置模型时不请求上游，参数: 测试实例，返回: 无
+func TestOpenAIServiceErrors(t *testing.T) {
+	if _, err := NewFactory().CreateService(ServiceTypeOpenAI, &TranslationServiceConfig{
+		APIKey: "sk-openai",
+		Prompt: PromptTemplate{System: "{{.TargetLang"},
+	}); err == nil {
+		t.Fatal("CreateService() 应拒绝无效模板")
+	}
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+	defer server.Close()
+	service, err := NewFactory().CreateService(ServiceTypeOpenAI, &TranslationServiceConfig{BaseURL: server.URL, FailOnError: true})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if _, err := service.Translate(context.Background(), "Hello", "en", "de", nil); !errors.Is(err, ErrTranslationFailed) || calls != 0 {
+		t.Errorf("Translate() error = %v, calls = %d", err, calls)
+	}
+
+	// 模板引用不存在的字段时渲染失败
+	transport, err := NewOpenAITransport(nil, server.URL, "", PromptTemplate{User: "{{.Missing}}"})
+	if err != nil {
+		t.Fatalf("NewOpenAITransport() error = %v", err)
+	}
+	if _, err := transport.RoundTrip(context.Background(), TranslationRequest{Text: "Hello", TargetLang: "DE"}, "m"); err == nil || calls != 0 {
+		t.Errorf("RoundTrip() error = %v, calls = %d", err, calls)
+	}
+}
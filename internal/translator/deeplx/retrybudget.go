@@ -0,0 +1,67 @@
+package deeplx
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget 跨请求共享的重试预算 (令牌桶)：每个首次请求存入 ratio 个令牌，每次重试取出一个令牌
+// 另外每秒补充 minPerSecond 个令牌，保证低流量时仍可重试；上游整体故障时重试量被限制在流量的 ratio 左右，
+// 而不是把请求量放大到 (1 + 最大重试次数) 倍。并发安全
+type RetryBudget struct {
+	mu           sync.Mutex
+	ratio        float64
+	minPerSecond float64
+	max          float64
+	tokens       float64
+	last         time.Time
+	now          func() time.Time
+	onDenied     func()
+}
+
+// NewRetryBudget 创建重试预算，初始令牌为满，参数: 每个请求存入的令牌数、每秒补充的令牌数、令牌上限与预算耗尽时的回调 (可为 nil)，返回: RetryBudget 指针
+func NewRetryBudget(ratio, minPerSecond, max float64, onDenied func()) *RetryBudget {
+	b := &RetryBudget{
+		ratio:        ratio,
+		minPerSecond: minPerSecond,
+		max:          max,
+		tokens:       max,
+		now:          time.Now,
+		onDenied:     onDenied,
+	}
+	b.last = b.now()
+	return b
+}
+
+// refill 按经过的时间补充令牌，调用方需持有锁，参数: 无，返回: 无
+func (b *RetryBudget) refill() {
+	now := b.now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.max, b.tokens+elapsed.Seconds()*b.minPerSecond)
+	}
+	b.last = now
+}
+
+// Deposit 记录一次首次请求并存入令牌，参数: 无，返回: 无
+func (b *RetryBudget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+// Withdraw 为一次重试取出令牌，参数: 无，返回: 是否允许重试
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+		b.mu.Unlock()
+		return true
+	}
+	b.mu.Unlock()
+	if b.onDenied != nil {
+		b.onDenied()
+	}
+	return false
+}
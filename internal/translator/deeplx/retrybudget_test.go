@@ -0,0 +1,54 @@
+package deeplx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRetryBudget 测试令牌耗尽后拒绝重试、首次请求按比例存入与按时间补充，参数: 测试实例，返回: 无
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(0, 0)
+	denied := 0
+	budget := NewRetryBudget(0.5, 1, 2, func() { denied++ })
+	budget.now = func() time.Time { return now }
+	budget.last = now
+
+	if !budget.Withdraw() || !budget.Withdraw() {
+		t.Fatal("初始令牌为满时应允许重试")
+	}
+	if budget.Withdraw() || denied != 1 {
+		t.Fatalf("令牌耗尽后应拒绝重试, denied = %d", denied)
+	}
+
+	budget.Deposit()
+	budget.Deposit()
+	if !budget.Withdraw() || budget.Withdraw() {
+		t.Error("两个请求各存入 0.5 个令牌，应只允许一次重试")
+	}
+
+	now = now.Add(5 * time.Second)
+	if !budget.Withdraw() || !budget.Withdraw() || budget.Withdraw() {
+		t.Error("按时间补充的令牌不应超过上限")
+	}
+}
+
+// TestRetryBudgetLimitsTranslator 测试预算耗尽时可重试的失败不再重试，参数: 测试实例，返回: 无
+func TestRetryBudgetLimitsTranslator(t *testing.T) {
+	transport := &fakeTransport{errs: []error{
+		&TransportError{Message: "unavailable", Retryable: true},
+		&TransportError{Message: "unavailable", Retryable: true},
+		&TransportError{Message: "unavailable", Retryable: true},
+	}}
+	budget := NewRetryBudget(0, 0, 1, nil)
+	translator, err := NewTranslatorWithConfig(&TranslationServiceConfig{APIKey: testAPIKey, Transport: transport, RetryBudget: budget})
+	if err != nil {
+		t.Fatalf("NewTranslatorWithConfig() error = %v", err)
+	}
+	if _, err := translator.Translate(context.Background(), Request{Text: "hi", Target: "ZH"}); err == nil {
+		t.Fatal("期望翻译失败")
+	}
+	if transport.calls != 2 {
+		t.Errorf("上游调用次数 = %d, want 2 (预算只够一次重试)", transport.calls)
+	}
+}
@@ -0,0 +1,20 @@
+package deeplx
+
+import "time"
+
+// AdaptiveTimeout 按文本长度计算的单次请求超时：Base + 每 KB 增加 PerKB，不超过 Max
+// 短文本很快失败重试，长文本不会因为固定超时被误判为失败
+type AdaptiveTimeout struct {
+	Base  time.Duration // 基础超时
+	PerKB time.Duration // 每 1024 字节 (UTF-8) 增加的超时
+	Max   time.Duration // 上限 (<=0 不限制)
+}
+
+// For 计算指定长度文本的超时，参数: 文本字节数，返回: 超时时长
+func (a *AdaptiveTimeout) For(size int) time.Duration {
+	timeout := a.Base + time.Duration(float64(a.PerKB)*float64(size)/1024)
+	if a.Max > 0 && timeout > a.Max {
+		return a.Max
+	}
+	return timeout
+}
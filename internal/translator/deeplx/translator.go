@@ -1,15 +1,18 @@
 package deeplx
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net"
 	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/XgzK/translate-services/internal/langutil"
 )
 
 // TranslationRequest 翻译请求结构，参数: 无，返回: 无
@@ -17,6 +20,7 @@ type TranslationRequest struct {
 	Text       string `json:"text"`
 	SourceLang string `json:"source_lang,omitempty"` // omitempty: 为空时不发送
 	TargetLang string `json:"target_lang"`
+	Formality  string `json:"formality,omitempty"` // 语气 (more/less/prefer_more/prefer_less)，为空时不发送
 }
 
 // TranslationResponse DeepLX API 响应结构，参数: 无，返回: 无
@@ -28,31 +32,52 @@ type TranslationResponse struct {
 	Method       string   `json:"method"`
 	SourceLang   string   `json:"source_lang"`
 	TargetLang   string   `json:"target_lang"`
+	Confidence   float64  `json:"confidence,omitempty"` // 源语言检测置信度 (0-1)，由转发 Azure/Google 等检测结果的兼容上游提供
+}
+
+// Request 一次翻译请求，语言代码可为任意写法，发送前按 langutil.DeepL 转换为 DeepL 代码，参数: 无，返回: 无
+type Request struct {
+	Text    string
+	Source  string // 源语言，为空或 auto 时由上游自动检测
+	Target  string
+	Model   string // 模型名称，为空时使用上游默认模型
+	Options Options
+}
+
+// Options 翻译选项，新增的上游参数在这里扩展，零值表示不传递，参数: 无，返回: 无
+type Options struct {
+	Formality string // 语气 (取值见 NormalizeFormality)
 }
 
-// TranslationResult 翻译结果封装，参数: 无，返回: 无
+// TranslationResult 翻译成功的结果，失败时以错误返回，语言代码为内部代码 (谷歌格式)，参数: 无，返回: 无
 type TranslationResult struct {
-	Success        bool
 	TranslatedText string
 	SourceLang     string
+	SourceScore    float64 // 上游报告的源语言置信度，0 表示未报告
 	TargetLang     string
-	ErrorMessage   string
 	RawResponse    *TranslationResponse
 }
 
-// Translator 翻译器接口，参数: 无，返回: 无
+// Translator 翻译器接口，失败时返回的错误可用 errors.Is 判断 ErrTimeout、ErrUnauthorized、ErrRateLimited 等类别，参数: 无，返回: 无
 type Translator interface {
-	Translate(text, targetLang string, sourceLang ...string) *TranslationResult
-	TranslateWithModel(text, targetLang, model string, sourceLang ...string) *TranslationResult
+	Translate(ctx context.Context, req Request) (*TranslationResult, error)
 }
 
 // DeepLXTranslator DeepLX 翻译器实现，参数: 无，返回: 无
 type DeepLXTranslator struct {
 	apiKey          string
 	baseURL         string
-	httpClient      *http.Client // 复用 HTTP 客户端，提高性能喵
+	httpClient      *http.Client  // 复用 HTTP 客户端，提高性能喵
+	transport       Transport     // 自定义传输 (为空时使用 HTTP 传输)
+	keys            *KeyPool      // 多密钥轮换池 (只配置单个密钥时为 nil)
+	endpoints       *EndpointPool // 多中继地址池 (只配置单个地址时为 nil)
 	requestTimeout  time.Duration
+	adaptive        *AdaptiveTimeout // 按文本长度计算超时 (为 nil 时使用 requestTimeout)
+	retryBudget     *RetryBudget     // 共享的重试预算 (为 nil 时不限制)
 	maxRetryAttempt int
+
+	// newHTTPTransport 按地址与密钥创建每次尝试使用的 HTTP 传输 (为 nil 时使用 DeepLX 协议的 NewHTTPTransport)
+	newHTTPTransport func(client *http.Client, baseURL, apiKey string) Transport
 }
 
 // 默认配置常量
@@ -63,26 +88,27 @@ const (
 	defaultMaxRetryAttempt = 2
 )
 
+// minUntranslatedLetters 译文与原文相同时视为上游未翻译所需的最少字母数，更短的文本 (专有名词、缩写等) 可能本就原样保留
+const minUntranslatedLetters = 10
+
 // defaultHTTPClient 创建带连接池优化的默认 HTTP 客户端
+// 请求经 otelhttp 包装：记录客户端 span 并向上游传递 traceparent (未启用追踪时为 noop)
 func defaultHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout: timeout,
-		Transport: &http.Transport{
+		Transport: otelhttp.NewTransport(&http.Transport{
 			MaxIdleConns:        100,              // 最大空闲连接数
 			MaxIdleConnsPerHost: 10,               // 每个主机的最大空闲连接数
 			IdleConnTimeout:     90 * time.Second, // 空闲连接超时
 			DisableCompression:  false,            // 启用压缩
 			ForceAttemptHTTP2:   true,             // 优先使用 HTTP/2
-		},
+		}),
 	}
 }
 
-// NewTranslator 创建翻译器实例，参数: API 密钥，返回: DeepLXTranslator 指针或错误
+// NewTranslator 创建翻译器实例，参数: API 密钥 (无需密钥的自建中继可为空)，返回: DeepLXTranslator 指针或错误
+// 不校验密钥格式，需要校验时使用 NewTranslatorWithConfig 并设置 KeyPattern
 func NewTranslator(apiKey string) (*DeepLXTranslator, error) {
-	if apiKey == "" || !strings.HasPrefix(apiKey, "sk-") {
-		return nil, fmt.Errorf("API 密钥必须以 sk- 开头")
-	}
-
 	return &DeepLXTranslator{
 		apiKey:          apiKey,
 		baseURL:         defaultBaseURL,
@@ -97,8 +123,17 @@ func NewTranslatorWithConfig(config *TranslationServiceConfig) (*DeepLXTranslato
 	if config == nil {
 		return nil, fmt.Errorf("配置不能为空")
 	}
-	if config.APIKey == "" || !strings.HasPrefix(config.APIKey, "sk-") {
-		return nil, fmt.Errorf("API 密钥必须以 sk- 开头")
+	keys := make([]WeightedKey, 0, len(config.APIKeys)+1)
+	if config.APIKey != "" {
+		keys = append(keys, WeightedKey{Key: config.APIKey, Weight: 1})
+	}
+	keys = append(keys, config.APIKeys...)
+	if err := validateKeys(keys, config.KeyPattern); err != nil {
+		return nil, err
+	}
+	apiKey := ""
+	if len(keys) > 0 {
+		apiKey = keys[0].Key
 	}
 
 	// 应用超时配置
@@ -108,28 +143,45 @@ func NewTranslatorWithConfig(config *TranslationServiceConfig) (*DeepLXTranslato
 		requestTimeout = time.Duration(config.Timeout) * time.Second
 		clientTimeout = requestTimeout * 3 // HTTP 客户端超时设为请求超时的 3 倍
 	}
+	if adaptive := config.AdaptiveTimeout; adaptive != nil {
+		// HTTP 客户端超时不能低于最长的自适应超时，否则长文本仍会被客户端截断
+		clientTimeout = max(clientTimeout, adaptive.Max)
+	}
 
-	// 应用 BaseURL 配置
+	// 应用 BaseURL 配置，BaseURLs 与 BaseURL 合并去重
+	var baseURLs []string
+	for _, u := range append([]string{config.BaseURL}, config.BaseURLs...) {
+		u = strings.TrimSuffix(strings.TrimSpace(u), "/")
+		if u != "" && !slices.Contains(baseURLs, u) {
+			baseURLs = append(baseURLs, u)
+		}
+	}
 	baseURL := defaultBaseURL
-	if config.BaseURL != "" {
-		baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if len(baseURLs) > 0 {
+		baseURL = baseURLs[0]
 	}
 
-	return &DeepLXTranslator{
-		apiKey:          config.APIKey,
+	translator := &DeepLXTranslator{
+		apiKey:          apiKey,
 		baseURL:         baseURL,
 		httpClient:      defaultHTTPClient(clientTimeout),
+		transport:       config.Transport,
 		requestTimeout:  requestTimeout,
+		adaptive:        config.AdaptiveTimeout,
+		retryBudget:     config.RetryBudget,
 		maxRetryAttempt: defaultMaxRetryAttempt,
-	}, nil
+	}
+	if len(keys) > 1 {
+		translator.keys = NewKeyPool(keys, config.KeyStrategy, config.KeyQuarantine)
+	}
+	if len(baseURLs) > 1 {
+		translator.endpoints = NewEndpointPool(baseURLs, config.EndpointOptions)
+	}
+	return translator, nil
 }
 
-// NewTranslatorWithClient 使用自定义客户端创建翻译器，参数: API 密钥与 HTTP 客户端，返回: DeepLXTranslator 指针或错误
+// NewTranslatorWithClient 使用自定义客户端创建翻译器，参数: API 密钥 (可为空) 与 HTTP 客户端，返回: DeepLXTranslator 指针或错误
 func NewTranslatorWithClient(apiKey string, client *http.Client) (*DeepLXTranslator, error) {
-	if apiKey == "" || !strings.HasPrefix(apiKey, "sk-") {
-		return nil, fmt.Errorf("API 密钥必须以 sk- 开头")
-	}
-
 	return &DeepLXTranslator{
 		apiKey:          apiKey,
 		baseURL:         defaultBaseURL,
@@ -139,42 +191,46 @@ func NewTranslatorWithClient(apiKey string, client *http.Client) (*DeepLXTransla
 	}, nil
 }
 
-// Translate 执行翻译，参数: 文本、目标语言、可选源语言，返回: 翻译结果
-func (t *DeepLXTranslator) Translate(text, targetLang string, sourceLang ...string) *TranslationResult {
-	return t.TranslateWithContext(context.Background(), text, targetLang, sourceLang...)
+// NewTranslatorWithTransport 使用自定义传输创建翻译器，参数: API 密钥与传输实现，返回: DeepLXTranslator 指针或错误
+func NewTranslatorWithTransport(apiKey string, transport Transport) (*DeepLXTranslator, error) {
+	translator, err := NewTranslator(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	translator.transport = transport
+	return translator, nil
 }
 
-// TranslateWithModel 使用指定模型翻译，参数: 文本、目标语言、模型、可选源语言，返回: 翻译结果
-func (t *DeepLXTranslator) TranslateWithModel(text, targetLang, model string, sourceLang ...string) *TranslationResult {
-	return t.TranslateWithModelContext(context.Background(), text, targetLang, model, sourceLang...)
+// Translate 执行翻译，参数: 上下文 (取消或超时时中止请求与重试) 与翻译请求，返回: 翻译结果与错误
+func (t *DeepLXTranslator) Translate(ctx context.Context, req Request) (*TranslationResult, error) {
+	upstreamReq := TranslationRequest{
+		Text:       req.Text,
+		SourceLang: langutil.DeepL.Source(req.Source),
+		TargetLang: langutil.DeepL.Target(req.Target),
+		Formality:  req.Options.Formality,
+	}
+	return t.doRequest(ctx, upstreamReq, req.Model)
 }
 
-// TranslateWithContext 带 context 的翻译请求，参数: 上下文、文本、目标语言、可选源语言，返回: 翻译结果
-func (t *DeepLXTranslator) TranslateWithContext(ctx context.Context, text, targetLang string, sourceLang ...string) *TranslationResult {
-	req := TranslationRequest{
-		Text:       text,
-		TargetLang: strings.ToUpper(targetLang),
+// validateKeys 按格式校验 API 密钥，参数: 密钥列表与格式 (正则表达式，为空时不校验)，返回: 错误
+// 设置了格式而没有任何密钥时按空密钥校验，格式不接受空字符串即表示必须配置密钥
+func validateKeys(keys []WeightedKey, pattern string) error {
+	if pattern == "" {
+		return nil
 	}
-
-	if len(sourceLang) > 0 && sourceLang[0] != "" {
-		req.SourceLang = strings.ToUpper(sourceLang[0])
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("无效的 API 密钥格式 %q: %w", pattern, err)
 	}
-
-	return t.doRequest(ctx, req, "")
-}
-
-// TranslateWithModelContext 带 context 的模型翻译请求，参数: 上下文、文本、目标语言、模型、可选源语言，返回: 翻译结果
-func (t *DeepLXTranslator) TranslateWithModelContext(ctx context.Context, text, targetLang, model string, sourceLang ...string) *TranslationResult {
-	req := TranslationRequest{
-		Text:       text,
-		TargetLang: strings.ToUpper(targetLang),
+	if len(keys) == 0 && !re.MatchString("") {
+		return fmt.Errorf("未配置 API 密钥")
 	}
-
-	if len(sourceLang) > 0 && sourceLang[0] != "" {
-		req.SourceLang = strings.ToUpper(sourceLang[0])
+	for _, k := range keys {
+		if !re.MatchString(k.Key) {
+			return fmt.Errorf("API 密钥不符合格式 %s", pattern)
+		}
 	}
-
-	return t.doRequest(ctx, req, model)
+	return nil
 }
 
 // SetBaseURL 设置自定义基础 URL，参数: 新的基础地址，返回: 无
@@ -182,157 +238,152 @@ func (t *DeepLXTranslator) SetBaseURL(baseURL string) {
 	t.baseURL = strings.TrimSuffix(baseURL, "/")
 }
 
-// doRequest 执行 HTTP 请求，参数: 上下文、翻译请求、模型名称，返回: 翻译结果
-func (t *DeepLXTranslator) doRequest(ctx context.Context, req TranslationRequest, model string) *TranslationResult {
-	// 构建 URL
-	url := t.buildURL(model)
+// SetTransport 替换底层传输 (如 gRPC、本地推理库)，参数: 传输实现 (nil 恢复 HTTP)，返回: 无
+func (t *DeepLXTranslator) SetTransport(transport Transport) {
+	t.transport = transport
+}
 
-	// 序列化请求体
-	jsonData, err := json.Marshal(req)
+// currentTransport 返回本次尝试使用的传输，参数: 无，返回: 传输实现、使用的轮换密钥 (未轮换时为空)、使用的中继地址 (只有一个地址时为空) 与错误
+// 配置多密钥或多个中继地址时每次尝试都重新选择，重试自然落在其他密钥或地址上
+func (t *DeepLXTranslator) currentTransport() (Transport, string, string, error) {
+	if t.transport != nil {
+		return t.transport, "", "", nil
+	}
+	baseURL, endpoint := t.baseURL, ""
+	if t.endpoints != nil {
+		endpoint = t.endpoints.Next()
+		baseURL = endpoint
+	}
+	if t.keys == nil {
+		return t.httpTransport(baseURL, t.apiKey), "", endpoint, nil
+	}
+	key, err := t.keys.Next()
 	if err != nil {
-		return &TranslationResult{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("序列化请求失败: %v", err),
-		}
+		return nil, "", "", err
 	}
+	return t.httpTransport(baseURL, key), key, endpoint, nil
+}
 
-	if ctx == nil {
-		ctx = context.Background()
+// httpTransport 创建一次尝试使用的 HTTP 传输，参数: 地址与密钥，返回: 传输实现
+func (t *DeepLXTranslator) httpTransport(baseURL, apiKey string) Transport {
+	if t.newHTTPTransport != nil {
+		return t.newHTTPTransport(t.httpClient, baseURL, apiKey)
 	}
+	return NewHTTPTransport(t.httpClient, baseURL, apiKey)
+}
 
-	var lastErr string
+// doRequest 通过传输层执行请求并统一处理重试与超时，参数: 上下文、翻译请求、模型名称，返回: 翻译结果与错误 (按 classifyError 归类)
+func (t *DeepLXTranslator) doRequest(ctx context.Context, req TranslationRequest, model string) (*TranslationResult, error) {
+	var lastErr error
+	requestTimeout := t.requestTimeout
+	if t.adaptive != nil {
+		requestTimeout = t.adaptive.For(len(req.Text))
+	}
+	if t.retryBudget != nil {
+		t.retryBudget.Deposit()
+	}
 
+	emptyRetried := false
 	for attempt := 0; attempt <= t.maxRetryAttempt; attempt++ {
 		if err := ctx.Err(); err != nil {
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: fmt.Sprintf("请求已取消: %v", err),
-			}
-		}
-
-		reqCtx := ctx
-		var cancel context.CancelFunc
-		if t.requestTimeout > 0 {
-			reqCtx, cancel = context.WithTimeout(ctx, t.requestTimeout)
+			return nil, classifyError(fmt.Errorf("请求已取消: %w", err))
 		}
 
-		// 创建 HTTP 请求
-		httpReq, err := http.NewRequestWithContext(reqCtx, "POST", url, bytes.NewBuffer(jsonData))
+		transport, key, endpoint, err := t.currentTransport()
 		if err != nil {
-			if cancel != nil {
-				cancel()
-			}
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: fmt.Sprintf("创建请求失败: %v", err),
-			}
+			return nil, err
 		}
 
-		httpReq.Header.Set("Content-Type", "application/json")
-
-		// 发送请求
-		resp, err := t.httpClient.Do(httpReq)
-		if err != nil {
-			if cancel != nil {
-				cancel()
-			}
-			lastErr = fmt.Sprintf("请求失败: %v", err)
-			if t.shouldRetry(err) && attempt < t.maxRetryAttempt {
-				time.Sleep(t.backoff(attempt))
-				continue
-			}
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: lastErr,
-			}
+		reqCtx := ctx
+		cancel := context.CancelFunc(func() {})
+		if requestTimeout > 0 {
+			reqCtx, cancel = context.WithTimeout(ctx, requestTimeout)
 		}
 
-		// 使用闭包确保 Body 正确关闭
-		body, readErr := func() ([]byte, error) {
-			defer resp.Body.Close()
-			return io.ReadAll(resp.Body)
-		}()
-		if cancel != nil {
-			cancel()
+		start := time.Now()
+		translationResp, err := transport.RoundTrip(reqCtx, req, model)
+		cancel()
+		// 空译文只重试一次，仍为空时作为错误返回，不把原文当作译文
+		if err == nil && isEmptyResult(req, translationResp) {
+			err = &TransportError{Message: ErrEmptyResult.Error(), Retryable: !emptyRetried, Err: ErrEmptyResult}
+			emptyRetried = true
 		}
-		if readErr != nil {
-			lastErr = fmt.Sprintf("读取响应失败: %v", readErr)
-			if attempt < t.maxRetryAttempt {
-				time.Sleep(t.backoff(attempt))
-				continue
-			}
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: lastErr,
+		if endpoint != "" {
+			if err == nil {
+				t.endpoints.Success(endpoint, time.Since(start))
+			} else if isEndpointFailure(err) && ctx.Err() == nil {
+				t.endpoints.Failure(endpoint)
 			}
 		}
-
-		// 检查状态码
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
-			if t.shouldRetryStatus(resp.StatusCode) && attempt < t.maxRetryAttempt {
-				time.Sleep(t.backoff(attempt))
-				continue
-			}
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: lastErr,
+		if err != nil {
+			lastErr = err
+			// 密钥被拒绝时隔离该密钥并立即换用下一个
+			if key != "" && isKeyRejected(err) {
+				t.keys.Quarantine(key)
+				if attempt < t.maxRetryAttempt && t.keys.Available() > 0 {
+					continue
+				}
 			}
-		}
-
-		// 解析响应
-		var translationResp TranslationResponse
-		if err := json.Unmarshal(body, &translationResp); err != nil {
-			lastErr = fmt.Sprintf("解析响应失败: %v", err)
-			if attempt < t.maxRetryAttempt {
+			// 中继地址故障时换用其他地址重试 (连接被拒绝等错误本身不可重试)
+			retryable := isRetryable(err) || (endpoint != "" && isEndpointFailure(err) && t.endpoints.Available() > 0)
+			if retryable && attempt < t.maxRetryAttempt && t.allowRetry() {
 				time.Sleep(t.backoff(attempt))
 				continue
 			}
-			return &TranslationResult{
-				Success:      false,
-				ErrorMessage: lastErr,
-			}
+			return nil, classifyError(err)
 		}
 
 		return &TranslationResult{
-			Success:        true,
 			TranslatedText: translationResp.Data,
-			SourceLang:     translationResp.SourceLang,
-			TargetLang:     translationResp.TargetLang,
-			RawResponse:    &translationResp,
-		}
+			SourceLang:     langutil.DeepL.Internal(translationResp.SourceLang),
+			SourceScore:    translationResp.Confidence,
+			TargetLang:     langutil.DeepL.Internal(translationResp.TargetLang),
+			RawResponse:    translationResp,
+		}, nil
 	}
 
-	return &TranslationResult{
-		Success:      false,
-		ErrorMessage: lastErr,
-	}
+	return nil, classifyError(lastErr)
 }
 
-// buildURL 构建请求 URL，参数: 模型名称，返回: 完整 URL 字符串
-func (t *DeepLXTranslator) buildURL(model string) string {
-	if model != "" {
-		return fmt.Sprintf("%s/%s/%s", t.baseURL, t.apiKey, model)
+// isEmptyResult 判断上游是否返回了成功但无效的译文，参数: 翻译请求与上游响应，返回: 布尔
+// 译文为空时总是无效；译文与原文相同时，只有源语言已知且与目标语言不同、原文至少含 minUntranslatedLetters 个字母才视为无效
+func isEmptyResult(req TranslationRequest, resp *TranslationResponse) bool {
+	if resp == nil || strings.TrimSpace(resp.Data) == "" {
+		return strings.TrimSpace(req.Text) != ""
 	}
-	return fmt.Sprintf("%s/%s", t.baseURL, t.apiKey)
-}
-
-// shouldRetry 判断错误是否需重试，参数: 错误对象，返回: 布尔
-func (t *DeepLXTranslator) shouldRetry(err error) bool {
-	if err == nil {
+	if strings.TrimSpace(resp.Data) != strings.TrimSpace(req.Text) {
 		return false
 	}
-	// 注意: net.Error.Temporary() 从 Go 1.18 起已废弃，仅检查超时错误
-	if ne, ok := err.(net.Error); ok && ne.Timeout() {
-		return true
+	source := req.SourceLang
+	if source == "" {
+		source = resp.SourceLang
+	}
+	if source == "" || primaryLanguage(source) == primaryLanguage(req.TargetLang) {
+		return false
+	}
+	letters := 0
+	for _, r := range req.Text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
 	}
-	return false
+	return letters >= minUntranslatedLetters
 }
 
-// shouldRetryStatus 判断状态码是否需重试，参数: 状态码，返回: 布尔
-func (t *DeepLXTranslator) shouldRetryStatus(status int) bool {
-	// 对 5xx 等服务器错误进行重试
-	return status >= 500 && status < 600
+// primaryLanguage 返回语言代码的主语言部分 (如 EN-US → EN)，参数: 语言代码，返回: 大写的主语言
+func primaryLanguage(code string) string {
+	primary, _, _ := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	return strings.ToUpper(primary)
+}
+
+// allowRetry 判断重试预算是否允许再重试一次，参数: 无，返回: 布尔 (未配置预算时总是允许)
+func (t *DeepLXTranslator) allowRetry() bool {
+	return t.retryBudget == nil || t.retryBudget.Withdraw()
+}
+
+// buildURL 构建 HTTP 传输的请求 URL，参数: 模型名称，返回: 完整 URL 字符串
+func (t *DeepLXTranslator) buildURL(model string) string {
+	return NewHTTPTransport(t.httpClient, t.baseURL, t.apiKey).buildURL(model)
 }
 
 // backoff 计算退避时间，参数: 重试次数，返回: 时间间隔
@@ -1,13 +1,19 @@
 package deeplx
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/XgzK/translate-services/internal/langutil"
+	"github.com/XgzK/translate-services/internal/requestid"
 )
 
 // 测试用的 API 密钥常量
@@ -26,14 +32,14 @@ func TestNewTranslator(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "空 API 密钥",
+			name:    "无需密钥的中继",
 			apiKey:  "",
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name:    "无效前缀的 API 密钥",
-			apiKey:  "invalid-key",
-			wantErr: true,
+			name:    "其他格式的 API 密钥",
+			apiKey:  "relay-token",
+			wantErr: false,
 		},
 	}
 
@@ -51,6 +57,29 @@ func TestNewTranslator(t *testing.T) {
 	}
 }
 
+// TestKeyPattern 测试按配置的格式校验 API 密钥，参数: 测试实例，返回: 无
+func TestKeyPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TranslationServiceConfig
+		wantErr bool
+	}{
+		{name: "未设置格式", config: TranslationServiceConfig{APIKey: "any"}},
+		{name: "符合格式", config: TranslationServiceConfig{APIKey: "sk-a", KeyPattern: "^sk-"}},
+		{name: "轮换密钥不符合格式", config: TranslationServiceConfig{APIKey: "sk-a", APIKeys: []WeightedKey{{Key: "bad", Weight: 1}}, KeyPattern: "^sk-"}, wantErr: true},
+		{name: "格式要求密钥但未配置", config: TranslationServiceConfig{KeyPattern: "^sk-"}, wantErr: true},
+		{name: "格式允许空密钥", config: TranslationServiceConfig{KeyPattern: "^(sk-.+)?$"}},
+		{name: "无效的格式", config: TranslationServiceConfig{APIKey: "sk-a", KeyPattern: "("}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTranslatorWithConfig(&tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("NewTranslatorWithConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestNewTranslatorWithClient 测试使用自定义客户端创建翻译器，参数: 测试实例，返回: 无
 func TestNewTranslatorWithClient(t *testing.T) {
 	customClient := &http.Client{
@@ -114,6 +143,12 @@ func TestBuildURL(t *testing.T) {
 			}
 		})
 	}
+
+	keyless, _ := NewTranslator("")
+	keyless.SetBaseURL("http://localhost:1188/translate")
+	if url := keyless.buildURL(""); url != "http://localhost:1188/translate" {
+		t.Errorf("无密钥时 buildURL() = %v", url)
+	}
 }
 
 // mockServerHandler 模拟服务器处理函数，参数: 响应与请求，返回: 无
@@ -195,23 +230,19 @@ func TestTranslate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var result *TranslationResult
-			if tt.sourceLang != "" {
-				result = translator.Translate(tt.text, tt.targetLang, tt.sourceLang)
-			} else {
-				result = translator.Translate(tt.text, tt.targetLang)
-			}
+			var err error
+			result, err = translator.Translate(context.Background(), Request{Text: tt.text, Source: tt.sourceLang, Target: tt.targetLang})
 
-			if result.Success != !tt.wantErr {
-				t.Errorf("Translate() success = %v, wantErr %v, error: %s",
-					result.Success, tt.wantErr, result.ErrorMessage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Translate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if result.Success {
+			if err == nil {
 				if result.TranslatedText == "" {
 					t.Error("翻译结果为空")
 				}
-				if result.TargetLang != strings.ToUpper(tt.targetLang) {
+				if result.TargetLang != langutil.NormalizeLanguageCode(tt.targetLang) {
 					t.Errorf("目标语言 = %v, want %v", result.TargetLang, tt.targetLang)
 				}
 			}
@@ -227,10 +258,8 @@ func TestTranslateWithModel(t *testing.T) {
 	translator, _ := NewTranslator(testAPIKey)
 	translator.SetBaseURL(server.URL)
 
-	result := translator.TranslateWithModel("Test text", "ZH", "gpt-4", "EN")
-
-	if !result.Success {
-		t.Errorf("TranslateWithModel() failed: %s", result.ErrorMessage)
+	if _, err := translator.Translate(context.Background(), Request{Text: "Test text", Source: "EN", Target: "ZH", Model: "gpt-4"}); err != nil {
+		t.Errorf("TranslateWithModel() failed: %v", err)
 	}
 }
 
@@ -245,14 +274,15 @@ func TestTranslateError(t *testing.T) {
 	translator, _ := NewTranslator(testAPIKey)
 	translator.SetBaseURL(errorServer.URL)
 
-	result := translator.Translate("Test", "ZH")
+	result, err := translator.Translate(context.Background(), Request{Text: "Test", Target: "ZH"})
 
-	if result.Success {
-		t.Error("应该返回错误但返回了成功")
+	if err == nil || result != nil {
+		t.Fatalf("Translate() = %+v, 应该返回错误但返回了成功", result)
 	}
 
-	if result.ErrorMessage == "" {
-		t.Error("错误信息为空")
+	var te *TransportError
+	if !errors.As(err, &te) || te.StatusCode != http.StatusInternalServerError {
+		t.Errorf("错误未保留上游状态码: %v", err)
 	}
 }
 
@@ -272,14 +302,272 @@ func TestTranslateTimeout(t *testing.T) {
 	translator, _ := NewTranslatorWithClient(testAPIKey, shortTimeoutClient)
 	translator.SetBaseURL(timeoutServer.URL)
 
-	result := translator.Translate("Test", "ZH")
+	_, err := translator.Translate(context.Background(), Request{Text: "Test", Target: "ZH"})
+
+	if err == nil {
+		t.Fatal("应该因为超时而失败")
+	}
+
+	if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "请求失败") {
+		t.Errorf("错误信息不符合预期: %v", err)
+	}
+}
+
+// TestTranslateErrorKinds 测试上游错误按类别包装且保留原始错误，参数: 测试实例，返回: 无
+func TestTranslateErrorKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		want    error
+	}{
+		{name: "密钥无效", status: http.StatusUnauthorized, want: ErrUnauthorized},
+		{name: "密钥被禁止", status: http.StatusForbidden, want: ErrUnauthorized},
+		{name: "请求过于频繁", status: http.StatusTooManyRequests, want: ErrRateLimited},
+		{name: "额度耗尽", status: statusQuotaExceeded, want: ErrQuotaExceeded},
+		{name: "不支持的语言", status: http.StatusBadRequest, message: `HTTP 400: {"message":"Invalid target language"}`, want: ErrUnsupportedLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &fakeTransport{errs: []error{&TransportError{Message: "rejected" + tt.message, StatusCode: tt.status}}}
+			translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
 
-	if result.Success {
-		t.Error("应该因为超时而失败")
+			_, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "zh"})
+			var te *TransportError
+			if !errors.Is(err, tt.want) || !errors.As(err, &te) || te.StatusCode != tt.status {
+				t.Fatalf("Translate() error = %v, want %v", err, tt.want)
+			}
+		})
 	}
 
-	if !strings.Contains(result.ErrorMessage, "请求失败") {
-		t.Errorf("错误信息不符合预期: %s", result.ErrorMessage)
+	t.Run("其他 4xx 不归类", func(t *testing.T) {
+		transport := &fakeTransport{errs: []error{&TransportError{Message: "HTTP 400: bad json", StatusCode: http.StatusBadRequest}}}
+		translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+		_, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "zh"})
+		if err == nil || errors.Is(err, ErrUnsupportedLanguage) {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	})
+
+	t.Run("透传 Retry-After", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		translator, _ := NewTranslator(testAPIKey)
+		translator.SetBaseURL(server.URL)
+		_, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "zh"})
+		if !errors.Is(err, ErrRateLimited) || RetryAfter(err) != 7*time.Second {
+			t.Fatalf("Translate() error = %v, RetryAfter = %v", err, RetryAfter(err))
+		}
+	})
+
+	t.Run("错误类别名称", func(t *testing.T) {
+		cases := map[error]string{
+			nil:                                 "",
+			fmt.Errorf("%w: x", ErrTimeout):     ErrorClassTimeout,
+			fmt.Errorf("%w: x", ErrRateLimited): ErrorClassRateLimited,
+			fmt.Errorf("%w: x", ErrEmptyResult): ErrorClassEmptyResult,
+			fmt.Errorf("请求已取消: %w", context.Canceled): ErrorClassCanceled,
+			errors.New("connection refused"):          ErrorClassOther,
+		}
+		for err, want := range cases {
+			if got := ErrorClass(err); got != want {
+				t.Errorf("ErrorClass(%v) = %q, want %q", err, got, want)
+			}
+		}
+	})
+
+	t.Run("适配器保留错误类别", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		adapter, _ := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{
+			APIKey:      testAPIKey,
+			Transport:   &fakeTransport{},
+			FailOnError: true,
+		})
+		_, err := adapter.Translate(ctx, "Hello", "en", "zh", []string{"t"})
+		if !errors.Is(err, ErrTranslationFailed) || !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	})
+}
+
+// fakeTransport 按预设错误序列与响应序列返回结果的测试传输
+type fakeTransport struct {
+	errs      []error
+	responses []*TranslationResponse // 错误用完后依次返回，用完后返回默认译文
+	calls     int
+	model     string
+	last      TranslationRequest
+}
+
+// RoundTrip 实现 Transport 接口
+func (f *fakeTransport) RoundTrip(ctx context.Context, req TranslationRequest, model string) (*TranslationResponse, error) {
+	f.calls++
+	f.model = model
+	f.last = req
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	if len(f.responses) > 0 {
+		resp := f.responses[0]
+		f.responses = f.responses[1:]
+		return resp, nil
+	}
+	return &TranslationResponse{Code: 200, Data: "译文:" + req.Text, SourceLang: "EN", TargetLang: req.TargetLang}, nil
+}
+
+// TestEmptyResult 测试上游返回空译文或原文时重试一次并在仍无效时返回 ErrEmptyResult，参数: 测试实例，返回: 无
+func TestEmptyResult(t *testing.T) {
+	const text = "Hello, how are you today?"
+	empty := &TranslationResponse{Code: 200, Data: " ", SourceLang: "EN"}
+	echoed := &TranslationResponse{Code: 200, Data: text, SourceLang: "EN"}
+
+	t.Run("重试一次后成功", func(t *testing.T) {
+		transport := &fakeTransport{responses: []*TranslationResponse{empty}}
+		translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+		result, err := translator.Translate(context.Background(), Request{Text: text, Target: "zh"})
+		if err != nil || result.TranslatedText != "译文:"+text || transport.calls != 2 {
+			t.Fatalf("Translate() = %+v, %v, calls = %d", result, err, transport.calls)
+		}
+	})
+
+	t.Run("持续为空只重试一次", func(t *testing.T) {
+		transport := &fakeTransport{responses: []*TranslationResponse{empty, echoed, empty}}
+		translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+		_, err := translator.Translate(context.Background(), Request{Text: text, Target: "zh"})
+		if !errors.Is(err, ErrEmptyResult) || transport.calls != 2 {
+			t.Fatalf("Translate() error = %v, calls = %d", err, transport.calls)
+		}
+	})
+
+	t.Run("未开启 FailOnError 也返回错误", func(t *testing.T) {
+		adapter, _ := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{
+			APIKey:    testAPIKey,
+			Transport: &fakeTransport{responses: []*TranslationResponse{echoed, echoed}},
+		})
+		_, err := adapter.Translate(context.Background(), text, "en", "zh", []string{"t"})
+		if !errors.Is(err, ErrTranslationFailed) || !errors.Is(err, ErrEmptyResult) {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		req  TranslationRequest
+		resp *TranslationResponse
+		want bool
+	}{
+		{"译文为空", TranslationRequest{Text: "Hi", TargetLang: "ZH"}, &TranslationResponse{}, true},
+		{"原文为空", TranslationRequest{Text: "", TargetLang: "ZH"}, &TranslationResponse{}, false},
+		{"与原文相同", TranslationRequest{Text: text, TargetLang: "ZH"}, echoed, true},
+		{"短文本原样保留", TranslationRequest{Text: "iPhone", TargetLang: "ZH"}, &TranslationResponse{Data: "iPhone", SourceLang: "EN"}, false},
+		{"数字原样保留", TranslationRequest{Text: "2024-01-01 12:00:00", TargetLang: "ZH"}, &TranslationResponse{Data: "2024-01-01 12:00:00", SourceLang: "EN"}, false},
+		{"源语言与目标语言相同", TranslationRequest{Text: text, SourceLang: "EN-GB", TargetLang: "EN-US"}, echoed, false},
+		{"源语言未知", TranslationRequest{Text: text, TargetLang: "ZH"}, &TranslationResponse{Data: text}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEmptyResult(tt.req, tt.resp); got != tt.want {
+				t.Fatalf("isEmptyResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTranslateWithCustomTransport 测试自定义传输与统一重试逻辑，参数: 测试实例，返回: 无
+func TestTranslateWithCustomTransport(t *testing.T) {
+	t.Run("可重试错误后成功", func(t *testing.T) {
+		transport := &fakeTransport{errs: []error{&TransportError{Message: "unavailable", Retryable: true}}}
+		translator, err := NewTranslatorWithTransport(testAPIKey, transport)
+		if err != nil {
+			t.Fatalf("NewTranslatorWithTransport() error = %v", err)
+		}
+
+		result, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "zh", Model: "local-model"})
+		if err != nil || result.TranslatedText != "译文:Hello" {
+			t.Fatalf("TranslateWithModel() = %+v, %v", result, err)
+		}
+		if transport.calls != 2 || transport.model != "local-model" {
+			t.Fatalf("calls = %d, model = %q", transport.calls, transport.model)
+		}
+	})
+
+	t.Run("不可重试错误直接返回", func(t *testing.T) {
+		transport := &fakeTransport{errs: []error{&TransportError{Message: "bad request"}}}
+		translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+
+		result, err := translator.Translate(context.Background(), Request{Text: "Hello", Target: "zh"})
+		if err == nil || err.Error() != "bad request" {
+			t.Fatalf("Translate() = %+v, %v", result, err)
+		}
+		if transport.calls != 1 {
+			t.Fatalf("不可重试错误被重试了 %d 次", transport.calls-1)
+		}
+	})
+}
+
+// TestFormality 测试语气参数的规范化与经上下文传给上游，参数: 测试实例，返回: 无
+func TestFormality(t *testing.T) {
+	tests := map[string]struct {
+		want string
+		ok   bool
+	}{
+		"":            {"", true},
+		"default":     {"", true},
+		"More":        {FormalityMore, true},
+		"prefer-less": {FormalityPreferLess, true},
+		"formal":      {FormalityPreferMore, true},
+		"informal":    {FormalityPreferLess, true},
+		"polite":      {"", false},
+	}
+	for input, tt := range tests {
+		if got, ok := NormalizeFormality(input); got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeFormality(%q) = %q, %v, want %q, %v", input, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	transport := &fakeTransport{}
+	translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+	translator.Translate(context.Background(), Request{Text: "Hello", Target: "de", Options: Options{Formality: FormalityLess}})
+	if transport.last.Formality != FormalityLess {
+		t.Errorf("formality = %q, want %q", transport.last.Formality, FormalityLess)
+	}
+	translator.Translate(context.Background(), Request{Text: "Hello", Target: "de"})
+	if transport.last.Formality != "" {
+		t.Errorf("formality = %q, want empty", transport.last.Formality)
+	}
+
+	adapter, _ := NewGoogleTranslatorWithConfig(&TranslationServiceConfig{APIKey: testAPIKey, Transport: transport})
+	adapter.Translate(WithFormality(context.Background(), FormalityMore), "Hello", "auto", "de", nil)
+	if transport.last.Formality != FormalityMore || transport.last.SourceLang != "" {
+		t.Errorf("适配器请求 = %+v, want formality %q 且不传 auto", transport.last, FormalityMore)
+	}
+}
+
+// TestTranslateLanguageCodes 测试请求语言代码转换为 DeepL 代码、响应代码转回内部代码，参数: 测试实例，返回: 无
+func TestTranslateLanguageCodes(t *testing.T) {
+	transport := &fakeTransport{}
+	translator, _ := NewTranslatorWithTransport(testAPIKey, transport)
+	result, err := translator.Translate(context.Background(), Request{Text: "Hello", Source: "en-GB", Target: "zh-TW"})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if transport.last.SourceLang != "EN" || transport.last.TargetLang != "ZH-HANT" {
+		t.Errorf("上游请求语言 = %q → %q, want EN → ZH-HANT", transport.last.SourceLang, transport.last.TargetLang)
+	}
+	if result.SourceLang != "en" || result.TargetLang != "zh-TW" {
+		t.Errorf("结果语言 = %q → %q, want en → zh-TW", result.SourceLang, result.TargetLang)
+	}
+
+	translator.Translate(context.Background(), Request{Text: "Hello", Source: "iw", Target: "no"})
+	if transport.last.SourceLang != "HE" || transport.last.TargetLang != "NB" {
+		t.Errorf("上游请求语言 = %q → %q, want HE → NB", transport.last.SourceLang, transport.last.TargetLang)
 	}
 }
 
@@ -293,7 +581,7 @@ func BenchmarkTranslate(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		translator.Translate("Benchmark test", "ZH", "EN")
+		translator.Translate(context.Background(), Request{Text: "Benchmark test", Source: "EN", Target: "ZH"})
 	}
 }
 
@@ -311,8 +599,69 @@ func ExampleDeepLXTranslator_Translate() {
 	}
 
 	// 基本翻译
-	result := translator.Translate("Hello, world!", "ZH", "EN")
-	if result.Success {
+	result, err := translator.Translate(context.Background(), Request{Text: "Hello, world!", Source: "EN", Target: "ZH"})
+	if err == nil {
 		println(result.TranslatedText)
 	}
 }
+
+// TestHTTPTransportRequestID 测试请求 ID 随上游调用转发，参数: 测试实例，返回: 无
+func TestHTTPTransportRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requestid.Header)
+		mockServerHandler(w, r)
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.Client(), server.URL, testAPIKey)
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	if _, err := transport.RoundTrip(ctx, TranslationRequest{Text: "hi", TargetLang: "ZH"}, ""); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if got != "req-123" {
+		t.Errorf("%s = %q, want req-123", requestid.Header, got)
+	}
+}
+
+// deadlineTransport 记录每次调用上下文剩余时间的传输
+type deadlineTransport struct {
+	remaining []time.Duration
+}
+
+// RoundTrip 记录剩余时间并返回成功，参数: 上下文、翻译请求、模型名称，返回: 翻译响应与错误
+func (d *deadlineTransport) RoundTrip(ctx context.Context, req TranslationRequest, model string) (*TranslationResponse, error) {
+	deadline, _ := ctx.Deadline()
+	d.remaining = append(d.remaining, time.Until(deadline))
+	return &TranslationResponse{Code: http.StatusOK, Data: req.Text}, nil
+}
+
+// TestAdaptiveTimeout 测试按文本长度计算超时与上限，并确认翻译器按文本长度设置单次请求超时，参数: 测试实例，返回: 无
+func TestAdaptiveTimeout(t *testing.T) {
+	adaptive := &AdaptiveTimeout{Base: time.Second, PerKB: 2 * time.Second, Max: 10 * time.Second}
+	tests := []struct {
+		size int
+		want time.Duration
+	}{
+		{0, time.Second},
+		{512, 2 * time.Second},
+		{2048, 5 * time.Second},
+		{100 * 1024, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := adaptive.For(tt.size); got != tt.want {
+			t.Errorf("For(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+
+	transport := &deadlineTransport{}
+	translator, err := NewTranslatorWithConfig(&TranslationServiceConfig{APIKey: testAPIKey, Transport: transport, AdaptiveTimeout: adaptive})
+	if err != nil {
+		t.Fatalf("NewTranslatorWithConfig() error = %v", err)
+	}
+	translator.Translate(context.Background(), Request{Text: "hi", Target: "ZH"})
+	translator.Translate(context.Background(), Request{Text: strings.Repeat("a", 4096), Target: "ZH"})
+	if len(transport.remaining) != 2 || transport.remaining[0] > 2*time.Second || transport.remaining[1] < 8*time.Second {
+		t.Errorf("单次请求超时 = %v", transport.remaining)
+	}
+}
@@ -0,0 +1,171 @@
+package deeplx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XgzK/translate-services/internal/requestid"
+)
+
+// Transport 翻译请求的底层传输 (HTTP 为默认实现，可替换为 gRPC、WebSocket 或本地推理库)
+// 传输层只负责完成一次调用；重试、退避与单次请求超时由 DeepLXTranslator 统一处理
+type Transport interface {
+	// RoundTrip 执行一次翻译调用，参数: 上下文、翻译请求、模型名称，返回: DeepLX 格式响应与错误
+	// 需要重试的失败应返回 Retryable 为 true 的 *TransportError
+	RoundTrip(ctx context.Context, req TranslationRequest, model string) (*TranslationResponse, error)
+}
+
+// TransportError 传输层错误，参数: 无，返回: 无
+type TransportError struct {
+	Message    string        // 面向调用方的错误描述
+	Retryable  bool          // 是否值得重试
+	StatusCode int           // 上游 HTTP 状态码 (可选)，用于识别密钥被拒绝
+	RetryAfter time.Duration // 上游 Retry-After 响应头给出的等待时间 (可选)
+	Err        error         // 原始错误 (可选)
+}
+
+// Error 实现 error 接口，参数: 无，返回: 错误描述
+func (e *TransportError) Error() string {
+	return e.Message
+}
+
+// Unwrap 返回原始错误，参数: 无，返回: 原始错误
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// isRetryable 判断传输错误是否需重试，参数: 错误对象，返回: 布尔
+func isRetryable(err error) bool {
+	var te *TransportError
+	return errors.As(err, &te) && te.Retryable
+}
+
+// HTTPTransport 基于 HTTP 的 DeepLX 传输实现，参数: 无，返回: 无
+type HTTPTransport struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// NewHTTPTransport 创建 HTTP 传输，参数: HTTP 客户端、基础 URL、API 密钥，返回: HTTPTransport 指针
+func NewHTTPTransport(client *http.Client, baseURL, apiKey string) *HTTPTransport {
+	if client == nil {
+		client = defaultHTTPClient(defaultClientTimeout)
+	}
+	return &HTTPTransport{
+		client:  client,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+	}
+}
+
+// RoundTrip 实现 Transport 接口
+func (h *HTTPTransport) RoundTrip(ctx context.Context, req TranslationRequest, model string) (*TranslationResponse, error) {
+	body, err := postJSON(ctx, h.client, h.buildURL(model), nil, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var translationResp TranslationResponse
+	if err := json.Unmarshal(body, &translationResp); err != nil {
+		return nil, &TransportError{Message: fmt.Sprintf("解析响应失败: %v", err), Retryable: true, Err: err}
+	}
+	return &translationResp, nil
+}
+
+// postJSON 以 JSON 发送 POST 请求并读取 200 响应体，失败时返回 *TransportError (网络超时、5xx 与读取失败可重试)
+// 参数: 上下文、HTTP 客户端、地址、额外请求头 (可为 nil) 与请求体，返回: 响应体与错误
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) ([]byte, error) {
+	// 序列化请求体
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, &TransportError{Message: fmt.Sprintf("序列化请求失败: %v", err), Err: err}
+	}
+
+	// 创建 HTTP 请求
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, &TransportError{Message: fmt.Sprintf("创建请求失败: %v", err), Err: err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		httpReq.Header.Set(name, value)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		httpReq.Header.Set(requestid.Header, id)
+	}
+
+	// 发送请求
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, &TransportError{
+			Message:   fmt.Sprintf("请求失败: %v", err),
+			Retryable: isTimeout(err),
+			Err:       err,
+		}
+	}
+
+	// 使用闭包确保 Body 正确关闭
+	body, err := func() ([]byte, error) {
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}()
+	if err != nil {
+		return nil, &TransportError{Message: fmt.Sprintf("读取响应失败: %v", err), Retryable: true, Err: err}
+	}
+
+	// 检查状态码，对 5xx 等服务器错误进行重试
+	if resp.StatusCode != http.StatusOK {
+		return nil, &TransportError{
+			Message:    fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body)),
+			Retryable:  resp.StatusCode >= 500 && resp.StatusCode < 600,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return body, nil
+}
+
+// buildURL 构建请求 URL，密钥与模型为空时省略对应路径段，参数: 模型名称，返回: 完整 URL 字符串
+func (h *HTTPTransport) buildURL(model string) string {
+	url := h.baseURL
+	if h.apiKey != "" {
+		url += "/" + h.apiKey
+	}
+	if model != "" {
+		url += "/" + model
+	}
+	return url
+}
+
+// parseRetryAfter 解析 Retry-After 响应头 (秒数或 HTTP 日期)，参数: 响应头取值与当前时间，返回: 等待时间 (缺失、无效或已过期时为 0)
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// isTimeout 判断错误是否为网络超时，参数: 错误对象，返回: 布尔
+func isTimeout(err error) bool {
+	// 注意: net.Error.Temporary() 从 Go 1.18 起已废弃，仅检查超时错误
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
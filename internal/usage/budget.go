@@ -0,0 +1,240 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBudgetRefresh 从账本重新读取周期费用的间隔，两次读取之间在本地累加新产生的费用
+const defaultBudgetRefresh = 10 * time.Second
+
+// Budget 一个提供商的费用预算
+type Budget struct {
+	Provider     string  // 提供商名称
+	Daily        float64 // 每日预算，0 表示不限
+	Monthly      float64 // 每月预算，0 表示不限
+	AlertPercent float64 // 费用达到预算的该百分比时告警，0 表示只在用尽时告警
+}
+
+// limit 返回周期的预算，参数: 周期，返回: 预算 (0 表示不限)
+func (b Budget) limit(period string) float64 {
+	if period == PeriodDay {
+		return b.Daily
+	}
+	return b.Monthly
+}
+
+// Alert 预算告警，同一提供商、周期与日期的每个级别只告警一次
+type Alert struct {
+	Provider string  `json:"provider"`
+	Period   string  `json:"period"` // day 或 month
+	Date     string  `json:"date"`   // 2006-01-02 或 2006-01 (UTC)
+	Budget   float64 `json:"budget"`
+	Spent    float64 `json:"spent"`
+	Percent  float64 `json:"percent"`  // 已用费用占预算的百分比
+	Exceeded bool    `json:"exceeded"` // 是否已用尽 (否则为达到告警阈值)
+}
+
+// ExceededError 提供商本周期的预算已用尽
+type ExceededError struct {
+	Provider string
+	Period   string
+	Budget   float64
+	Spent    float64
+}
+
+// Error 实现 error 接口，参数: 无，返回: 错误字符串
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("提供商 %s 的%s预算已用尽: %.4f / %.4f", e.Provider, periodName(e.Period), e.Spent, e.Budget)
+}
+
+// periodName 返回周期的中文名称，参数: 周期，返回: 名称
+func periodName(period string) string {
+	if period == PeriodDay {
+		return "每日"
+	}
+	return "每月"
+}
+
+// periodSpending 一个周期内各提供商的费用缓存
+type periodSpending struct {
+	date      string
+	fetchedAt time.Time
+	costs     map[string]float64 // 小写提供商名称 → 费用
+}
+
+// BudgetTracker 按提供商检查当日与当月费用是否超出预算，并在达到告警阈值时回调
+// 费用来自账本 (多实例共享 Redis 时包含其他实例的费用)，每隔一段时间重新读取，期间在本地累加本实例新产生的费用
+type BudgetTracker struct {
+	ledger  *Ledger
+	budgets map[string]Budget // 小写提供商名称 → 预算
+	onAlert func(Alert)
+	refresh time.Duration
+
+	mu      sync.Mutex
+	periods map[string]*periodSpending // 周期 → 费用缓存
+	alerted map[alertKey]bool          // 已告警的级别
+}
+
+// alertKey 告警去重的键
+type alertKey struct {
+	provider string
+	period   string
+	date     string
+	exceeded bool
+}
+
+// NewBudgetTracker 创建预算跟踪器，同一提供商的预算后者覆盖前者，参数: 账本、预算列表与告警回调 (可为 nil，同步调用，不应阻塞)，返回: BudgetTracker 指针
+func NewBudgetTracker(ledger *Ledger, budgets []Budget, onAlert func(Alert)) *BudgetTracker {
+	t := &BudgetTracker{
+		ledger:  ledger,
+		budgets: make(map[string]Budget, len(budgets)),
+		onAlert: onAlert,
+		refresh: defaultBudgetRefresh,
+		periods: make(map[string]*periodSpending),
+		alerted: make(map[alertKey]bool),
+	}
+	for _, b := range budgets {
+		t.budgets[strings.ToLower(strings.TrimSpace(b.Provider))] = b
+	}
+	return t
+}
+
+// Has 判断提供商是否配置了预算，参数: 提供商名称，返回: 布尔
+func (t *BudgetTracker) Has(provider string) bool {
+	_, ok := t.budgets[strings.ToLower(provider)]
+	return ok
+}
+
+// Check 检查提供商本周期的预算，参数: 上下文与提供商名称，返回: 预算已用尽时为 *ExceededError，否则为 nil
+// 读取账本失败时沿用上次的费用，不因统计故障拒绝请求
+func (t *BudgetTracker) Check(ctx context.Context, provider string) error {
+	name := strings.ToLower(provider)
+	budget, ok := t.budgets[name]
+	if !ok {
+		return nil
+	}
+
+	var exceeded error
+	var alerts []Alert
+	t.mu.Lock()
+	for _, period := range []string{PeriodDay, PeriodMonth} {
+		limit := budget.limit(period)
+		if limit <= 0 {
+			continue
+		}
+		spending := t.spending(ctx, period)
+		spent := spending.costs[name]
+		alerts = t.evaluate(alerts, budget, period, spending.date, spent)
+		if spent >= limit && exceeded == nil {
+			exceeded = &ExceededError{Provider: budget.Provider, Period: period, Budget: limit, Spent: spent}
+		}
+	}
+	t.mu.Unlock()
+
+	t.notify(alerts)
+	return exceeded
+}
+
+// Add 累加提供商新产生的费用并检查告警阈值，参数: 提供商名称与费用，返回: 无
+// 账本写入由调用方负责，这里只更新本地缓存，使下次从账本读取前的检查也能看到这笔费用
+func (t *BudgetTracker) Add(provider string, cost float64) {
+	name := strings.ToLower(provider)
+	budget, ok := t.budgets[name]
+	if !ok || cost <= 0 {
+		return
+	}
+
+	var alerts []Alert
+	t.mu.Lock()
+	for _, period := range []string{PeriodDay, PeriodMonth} {
+		spending, ok := t.periods[period]
+		if !ok || spending.date != t.date(period) {
+			continue
+		}
+		spending.costs[name] += cost
+		alerts = t.evaluate(alerts, budget, period, spending.date, spending.costs[name])
+	}
+	t.mu.Unlock()
+
+	t.notify(alerts)
+}
+
+// spending 返回周期的费用缓存，缓存过期或跨周期时从账本重新读取，调用方需持有锁，参数: 上下文与周期，返回: 费用缓存
+func (t *BudgetTracker) spending(ctx context.Context, period string) *periodSpending {
+	now := t.ledger.now()
+	date := t.date(period)
+	spending, ok := t.periods[period]
+	if ok && spending.date == date && now.Sub(spending.fetchedAt) < t.refresh {
+		return spending
+	}
+	if !ok || spending.date != date {
+		spending = &periodSpending{date: date, costs: make(map[string]float64)}
+		t.periods[period] = spending
+		for key := range t.alerted {
+			if key.period == period && key.date != date {
+				delete(t.alerted, key)
+			}
+		}
+	}
+
+	report, err := t.ledger.Report(ctx, period, date)
+	if err != nil {
+		return spending
+	}
+	spending.fetchedAt = now
+	spending.costs = make(map[string]float64, len(report.ByProvider))
+	for provider, counts := range report.ByProvider {
+		spending.costs[strings.ToLower(provider)] += counts.Cost
+	}
+	return spending
+}
+
+// date 返回周期的当前日期 (UTC)，参数: 周期，返回: 日期
+func (t *BudgetTracker) date(period string) string {
+	if period == PeriodDay {
+		return t.ledger.now().UTC().Format("2006-01-02")
+	}
+	return t.ledger.now().UTC().Format("2006-01")
+}
+
+// evaluate 费用首次达到告警阈值或用尽预算时生成告警，调用方需持有锁，参数: 告警列表、预算、周期、日期与已用费用，返回: 追加后的告警列表
+func (t *BudgetTracker) evaluate(alerts []Alert, budget Budget, period, date string, spent float64) []Alert {
+	limit := budget.limit(period)
+	if limit <= 0 {
+		return alerts
+	}
+	percent := spent / limit * 100
+	exceeded := spent >= limit
+	if !exceeded && (budget.AlertPercent <= 0 || percent < budget.AlertPercent) {
+		return alerts
+	}
+
+	key := alertKey{provider: strings.ToLower(budget.Provider), period: period, date: date, exceeded: exceeded}
+	if t.alerted[key] {
+		return alerts
+	}
+	t.alerted[key] = true
+	return append(alerts, Alert{
+		Provider: budget.Provider,
+		Period:   period,
+		Date:     date,
+		Budget:   limit,
+		Spent:    spent,
+		Percent:  percent,
+		Exceeded: exceeded,
+	})
+}
+
+// notify 在锁外调用告警回调，参数: 告警列表，返回: 无
+func (t *BudgetTracker) notify(alerts []Alert) {
+	if t.onAlert == nil {
+		return
+	}
+	for _, alert := range alerts {
+		t.onAlert(alert)
+	}
+}
@@ -0,0 +1,54 @@
+package usage
+
+import "strings"
+
+// defaultCharsPerToken 未配置时每个 token 对应的字符数 (英文文本的常见估计)
+const defaultCharsPerToken = 4
+
+// Price 一个提供商 (或其某个模型) 的价格，按字符计费与按 token 计费可以同时设置
+type Price struct {
+	Provider               string  // 提供商名称
+	Model                  string  // 模型名称，为空时适用于该提供商的全部模型
+	PerMillionChars        float64 // 每百万原文字符的价格
+	PerMillionInputTokens  float64 // 每百万输入 token 的价格
+	PerMillionOutputTokens float64 // 每百万输出 token 的价格
+	CharsPerToken          float64 // 估算 token 数时每个 token 对应的字符数，默认 4
+}
+
+// Pricing 价格表，构建后只读，并发安全
+type Pricing struct {
+	prices map[string]Price // 小写的 provider 与 model 组成的键 → 价格
+}
+
+// NewPricing 创建价格表，同一提供商与模型的价格后者覆盖前者，参数: 价格列表，返回: Pricing 指针
+func NewPricing(prices []Price) *Pricing {
+	p := &Pricing{prices: make(map[string]Price, len(prices))}
+	for _, price := range prices {
+		p.prices[priceKey(price.Provider, price.Model)] = price
+	}
+	return p
+}
+
+// priceKey 生成价格表键，参数: 提供商与模型，返回: 键
+func priceKey(provider, model string) string {
+	return strings.ToLower(strings.TrimSpace(provider)) + "\x1f" + strings.ToLower(strings.TrimSpace(model))
+}
+
+// Cost 估算一次上游调用的费用，优先使用模型的价格，其次使用提供商的价格，参数: 提供商、模型、原文字符数与译文字符数，返回: 费用与是否找到价格
+// token 数按字符数除以 chars_per_token 估算，输入按原文、输出按译文计算
+func (p *Pricing) Cost(provider, model string, inputChars, outputChars int64) (float64, bool) {
+	price, ok := p.prices[priceKey(provider, model)]
+	if !ok {
+		if price, ok = p.prices[priceKey(provider, "")]; !ok {
+			return 0, false
+		}
+	}
+	charsPerToken := price.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = defaultCharsPerToken
+	}
+	cost := float64(inputChars) * price.PerMillionChars / 1e6
+	cost += float64(inputChars) / charsPerToken * price.PerMillionInputTokens / 1e6
+	cost += float64(outputChars) / charsPerToken * price.PerMillionOutputTokens / 1e6
+	return cost, true
+}
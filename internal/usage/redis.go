@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore 基于 Redis 的用量存储，每个 bucket 是三个哈希 (<bucket>:chars、<bucket>:requests 与 <bucket>:cost)，字段为编码后的维度
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建 Redis 用量存储，参数: Redis 客户端，返回: RedisStore 指针
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Add 累加用量并刷新过期时间，参数: 上下文、bucket、维度、用量与保留时间，返回: 错误
+func (r *RedisStore) Add(ctx context.Context, bucket string, key Key, counts Counts, ttl time.Duration) error {
+	field := key.field()
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(ctx, bucket+":chars", field, counts.Characters)
+	pipe.HIncrBy(ctx, bucket+":requests", field, counts.Requests)
+	pipe.Expire(ctx, bucket+":chars", ttl)
+	pipe.Expire(ctx, bucket+":requests", ttl)
+	if counts.Cost != 0 {
+		pipe.HIncrByFloat(ctx, bucket+":cost", field, counts.Cost)
+		pipe.Expire(ctx, bucket+":cost", ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// List 返回 bucket 中的全部用量，参数: 上下文与 bucket，返回: 用量列表与错误
+func (r *RedisStore) List(ctx context.Context, bucket string) ([]Entry, error) {
+	pipe := r.client.Pipeline()
+	chars := pipe.HGetAll(ctx, bucket+":chars")
+	requests := pipe.HGetAll(ctx, bucket+":requests")
+	costs := pipe.HGetAll(ctx, bucket+":cost")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(chars.Val()))
+	for field, value := range chars.Val() {
+		key, ok := parseField(field)
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseInt(value, 10, 64)
+		m, _ := strconv.ParseInt(requests.Val()[field], 10, 64)
+		cost, _ := strconv.ParseFloat(costs.Val()[field], 64)
+		entries = append(entries, Entry{Key: key, Counts: Counts{Characters: n, Requests: m, Cost: cost}})
+	}
+	return entries, nil
+}
@@ -0,0 +1,213 @@
+// Package usage 按提供商、模型与客户端统计发往上游的字符数、请求数与估算费用，按日与按月滚动汇总，用于核对上游账单
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 统计周期
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// 各周期计数的保留时长
+const (
+	dayRetention   = 35 * 24 * time.Hour
+	monthRetention = 400 * 24 * time.Hour
+)
+
+// Key 一条用量记录的维度
+type Key struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Client   string `json:"client"`
+}
+
+// field 把维度编码为存储字段，参数: 无，返回: 字段名
+func (k Key) field() string {
+	return strings.Join([]string{k.Provider, k.Model, k.Client}, "\x1f")
+}
+
+// parseField 把存储字段解码为维度，参数: 字段名，返回: 维度与是否合法
+func parseField(field string) (Key, bool) {
+	parts := strings.Split(field, "\x1f")
+	if len(parts) != 3 {
+		return Key{}, false
+	}
+	return Key{Provider: parts[0], Model: parts[1], Client: parts[2]}, true
+}
+
+// Counts 字符数、请求数与估算费用
+type Counts struct {
+	Characters int64   `json:"characters"`
+	Requests   int64   `json:"requests"`
+	Cost       float64 `json:"cost"` // 按价格表估算的费用，未配置价格时为 0
+}
+
+// Entry 一个维度组合在某个周期内的用量
+type Entry struct {
+	Key
+	Counts
+}
+
+// Store 用量计数存储接口，支持内存、Redis 等实现
+type Store interface {
+	// Add 为 bucket 中的维度累加用量，ttl 为该 bucket 的保留时间
+	Add(ctx context.Context, bucket string, key Key, counts Counts, ttl time.Duration) error
+
+	// List 返回 bucket 中的全部用量，bucket 不存在时返回空列表
+	List(ctx context.Context, bucket string) ([]Entry, error)
+}
+
+// Ledger 用量账本，每次记录同时累加到当日与当月的 bucket
+type Ledger struct {
+	store  Store
+	prefix string
+	now    func() time.Time
+}
+
+// NewLedger 创建用量账本，参数: 存储实现 (为 nil 时使用内存存储)，返回: Ledger 指针
+func NewLedger(store Store) *Ledger {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Ledger{store: store, prefix: "usage", now: time.Now}
+}
+
+// Record 记录一次上游调用，参数: 上下文、维度与字符数，返回: 错误
+func (l *Ledger) Record(ctx context.Context, key Key, chars int64) error {
+	return l.RecordCost(ctx, key, chars, 0)
+}
+
+// RecordCost 记录一次上游调用及其估算费用，参数: 上下文、维度、字符数与费用，返回: 错误
+func (l *Ledger) RecordCost(ctx context.Context, key Key, chars int64, cost float64) error {
+	now := l.now().UTC()
+	counts := Counts{Characters: chars, Requests: 1, Cost: cost}
+	if err := l.store.Add(ctx, l.bucket(PeriodDay, now.Format("2006-01-02")), key, counts, dayRetention); err != nil {
+		return fmt.Errorf("记录当日用量失败: %w", err)
+	}
+	if err := l.store.Add(ctx, l.bucket(PeriodMonth, now.Format("2006-01")), key, counts, monthRetention); err != nil {
+		return fmt.Errorf("记录当月用量失败: %w", err)
+	}
+	return nil
+}
+
+// Report 某个周期的用量报表
+type Report struct {
+	Period     string            `json:"period"`      // day 或 month
+	Date       string            `json:"date"`        // 2006-01-02 或 2006-01
+	Entries    []Entry           `json:"entries"`     // 按提供商、模型、客户端排序
+	ByProvider map[string]Counts `json:"by_provider"` // 按提供商汇总
+	Total      Counts            `json:"total"`
+}
+
+// Report 查询周期用量，参数: 上下文、周期 (day/month) 与日期 (为空表示当前周期)，返回: 报表与错误
+func (l *Ledger) Report(ctx context.Context, period, date string) (*Report, error) {
+	now := l.now().UTC()
+	layout := ""
+	switch period {
+	case PeriodDay:
+		layout = "2006-01-02"
+	case PeriodMonth:
+		layout = "2006-01"
+	default:
+		return nil, fmt.Errorf("未知的统计周期: %q", period)
+	}
+	if date == "" {
+		date = now.Format(layout)
+	} else if _, err := time.Parse(layout, date); err != nil {
+		return nil, fmt.Errorf("日期格式应为 %s: %q", layout, date)
+	}
+
+	entries, err := l.store.List(ctx, l.bucket(period, date))
+	if err != nil {
+		return nil, fmt.Errorf("查询用量失败: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].field() < entries[j].field()
+	})
+	report := &Report{Period: period, Date: date, Entries: entries, ByProvider: make(map[string]Counts)}
+	for _, entry := range entries {
+		provider := report.ByProvider[entry.Provider]
+		provider.Characters += entry.Characters
+		provider.Requests += entry.Requests
+		provider.Cost += entry.Cost
+		report.ByProvider[entry.Provider] = provider
+		report.Total.Characters += entry.Characters
+		report.Total.Requests += entry.Requests
+		report.Total.Cost += entry.Cost
+	}
+	if report.Entries == nil {
+		report.Entries = []Entry{}
+	}
+	return report, nil
+}
+
+// bucket 生成周期 bucket 名称，参数: 周期与日期，返回: bucket 名称
+func (l *Ledger) bucket(period, date string) string {
+	return fmt.Sprintf("%s:%s:%s", l.prefix, period, date)
+}
+
+// MemoryStore 进程内用量存储，重启后清零
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	counts    map[Key]Counts
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建内存用量存储，参数: 无，返回: MemoryStore 指针
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+// Add 累加用量，参数: 上下文、bucket、维度、用量与保留时间，返回: 错误
+func (m *MemoryStore) Add(_ context.Context, bucket string, key Key, counts Counts, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for name, b := range m.buckets {
+		if now.After(b.expiresAt) {
+			delete(m.buckets, name)
+		}
+	}
+	b, ok := m.buckets[bucket]
+	if !ok {
+		b = &memoryBucket{counts: make(map[Key]Counts)}
+		m.buckets[bucket] = b
+	}
+	current := b.counts[key]
+	current.Characters += counts.Characters
+	current.Requests += counts.Requests
+	current.Cost += counts.Cost
+	b.counts[key] = current
+	b.expiresAt = now.Add(ttl)
+	return nil
+}
+
+// List 返回 bucket 中的全部用量，参数: 上下文与 bucket，返回: 用量列表与错误
+func (m *MemoryStore) List(_ context.Context, bucket string) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[bucket]
+	if !ok || m.now().After(b.expiresAt) {
+		return nil, nil
+	}
+	entries := make([]Entry, 0, len(b.counts))
+	for key, counts := range b.counts {
+		entries = append(entries, Entry{Key: key, Counts: counts})
+	}
+	return entries, nil
+}
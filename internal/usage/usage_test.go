@@ -0,0 +1,143 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLedgerReport 测试按日与按月累加及按提供商汇总，参数: 测试实例，返回: 无
+func TestLedgerReport(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewLedger(nil)
+	now := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return now }
+
+	deeplx := Key{Provider: "deeplx", Model: "default", Client: "app-a"}
+	backup := Key{Provider: "backup", Model: "gpt-4o", Client: "app-b"}
+	_ = ledger.Record(ctx, deeplx, 10)
+	_ = ledger.Record(ctx, deeplx, 5)
+	_ = ledger.Record(ctx, backup, 7)
+	now = now.Add(2 * time.Hour) // 跨入下一天与下一个月
+	_ = ledger.Record(ctx, deeplx, 3)
+
+	day, err := ledger.Report(ctx, PeriodDay, "2026-03-31")
+	if err != nil {
+		t.Fatalf("Report(day) error = %v", err)
+	}
+	if len(day.Entries) != 2 || day.Total != (Counts{Characters: 22, Requests: 3}) {
+		t.Errorf("day report = %+v", day)
+	}
+	if day.ByProvider["deeplx"] != (Counts{Characters: 15, Requests: 2}) {
+		t.Errorf("deeplx = %+v", day.ByProvider["deeplx"])
+	}
+
+	month, _ := ledger.Report(ctx, PeriodMonth, "")
+	if month.Date != "2026-04" || month.Total != (Counts{Characters: 3, Requests: 1}) {
+		t.Errorf("current month report = %+v", month)
+	}
+	march, _ := ledger.Report(ctx, PeriodMonth, "2026-03")
+	if march.Total.Characters != 22 {
+		t.Errorf("march report = %+v", march)
+	}
+
+	empty, _ := ledger.Report(ctx, PeriodDay, "2025-01-01")
+	if len(empty.Entries) != 0 || empty.Entries == nil {
+		t.Errorf("empty report entries = %#v", empty.Entries)
+	}
+	if _, err := ledger.Report(ctx, "week", ""); err == nil {
+		t.Error("Report(week) error = nil")
+	}
+	if _, err := ledger.Report(ctx, PeriodDay, "2026-03"); err == nil {
+		t.Error("Report(day, bad date) error = nil")
+	}
+}
+
+// TestPricingCost 测试模型价格优先、提供商价格兜底与 token 估算，参数: 测试实例，返回: 无
+func TestPricingCost(t *testing.T) {
+	pricing := NewPricing([]Price{
+		{Provider: "deeplx", PerMillionChars: 20},
+		{Provider: "openai", PerMillionInputTokens: 1, PerMillionOutputTokens: 4},
+		{Provider: "OpenAI", Model: "gpt-4o", PerMillionInputTokens: 5, PerMillionOutputTokens: 20, CharsPerToken: 2},
+	})
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		in, out  int64
+		want     float64
+		ok       bool
+	}{
+		{name: "按字符计费", provider: "deeplx", model: "default", in: 500000, out: 600000, want: 10, ok: true},
+		{name: "提供商价格", provider: "openai", model: "gpt-4o-mini", in: 4000000, out: 4000000, want: 5, ok: true},
+		{name: "模型价格优先", provider: "openai", model: "GPT-4o", in: 2000000, out: 1000000, want: 15, ok: true},
+		{name: "未配置价格", provider: "gtx", model: "default", in: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pricing.Cost(tt.provider, tt.model, tt.in, tt.out)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	ledger := NewLedger(nil)
+	_ = ledger.RecordCost(context.Background(), Key{Provider: "deeplx", Model: "default", Client: "a"}, 10, 0.25)
+	_ = ledger.RecordCost(context.Background(), Key{Provider: "deeplx", Model: "default", Client: "b"}, 10, 0.5)
+	report, _ := ledger.Report(context.Background(), PeriodDay, "")
+	if report.Total.Cost != 0.75 || report.ByProvider["deeplx"].Cost != 0.75 {
+		t.Errorf("report cost = %+v", report.Total)
+	}
+}
+
+// TestBudgetTracker 测试预算用尽判断、告警阈值只告警一次与跨日重置，参数: 测试实例，返回: 无
+func TestBudgetTracker(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewLedger(nil)
+	now := time.Date(2026, 3, 30, 12, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return now }
+
+	var alerts []Alert
+	tracker := NewBudgetTracker(ledger, []Budget{{Provider: "OpenAI", Daily: 10, Monthly: 100, AlertPercent: 80}}, func(a Alert) {
+		alerts = append(alerts, a)
+	})
+	openai := Key{Provider: "openai", Model: "gpt-4o", Client: "app"}
+
+	_ = ledger.RecordCost(ctx, openai, 100, 7)
+	if err := tracker.Check(ctx, "openai"); err != nil || len(alerts) != 0 {
+		t.Fatalf("Check() at 70%% = %v, alerts = %+v", err, alerts)
+	}
+
+	tracker.Add("openai", 1.5)
+	tracker.Add("openai", 0.5)
+	if len(alerts) != 1 || alerts[0].Period != PeriodDay || alerts[0].Exceeded || alerts[0].Date != "2026-03-30" {
+		t.Fatalf("alerts after 90%% = %+v", alerts)
+	}
+
+	tracker.Add("openai", 1)
+	var exceeded *ExceededError
+	err := tracker.Check(ctx, "openai")
+	if !errors.As(err, &exceeded) || exceeded.Period != PeriodDay || exceeded.Budget != 10 {
+		t.Fatalf("Check() over budget = %v", err)
+	}
+	if len(alerts) != 2 || !alerts[1].Exceeded {
+		t.Errorf("alerts after exceeding = %+v", alerts)
+	}
+	if err := tracker.Check(ctx, "deeplx"); err != nil {
+		t.Errorf("Check(unbudgeted) = %v", err)
+	}
+
+	// 次日从账本重新读取当日费用，月度费用仍累计
+	now = now.Add(24 * time.Hour)
+	_ = ledger.RecordCost(ctx, openai, 100, 1)
+	if err := tracker.Check(ctx, "openai"); err != nil {
+		t.Errorf("Check() next day = %v", err)
+	}
+	if len(alerts) != 2 {
+		t.Errorf("alerts next day = %+v", alerts)
+	}
+}
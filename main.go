@@ -13,6 +13,7 @@ import (
 	"github.com/XgzK/translate-services/internal/config"
 	"github.com/XgzK/translate-services/internal/logging"
 	"github.com/XgzK/translate-services/internal/server"
+	"github.com/XgzK/translate-services/internal/tracing"
 )
 
 // main 是服务的入口函数，参数: 无，返回: 无
@@ -33,23 +34,34 @@ func main() {
 	logger := logging.New(logging.Options{
 		Debug:   cfg.Debug,
 		Service: "deeplx-server",
+		Format:  cfg.Logging.Format,
+		Output:  cfg.Logging.Output,
 	})
 
 	logger.Info().
 		Str("port", cfg.Port).
+		Str("profile", cfg.Profile).
 		Bool("debug", cfg.Debug).
 		Str("service_type", cfg.Translation.ServiceType).
 		Bool("has_api_key", cfg.Translation.APIKey != "").
 		Bool("custom_base_url", cfg.Translation.BaseURL != "").
 		Msg("配置加载成功")
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("初始化分布式追踪失败")
+	}
+	if cfg.Tracing.Enabled {
+		logger.Info().Str("endpoint", cfg.Tracing.Endpoint).Float64("sample_ratio", cfg.Tracing.GetSampleRatio()).Msg("分布式追踪已启用")
+	}
+
 	srv, err := server.New(cfg, logger, nil)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("创建服务器失败")
 	}
 
 	addr := fmt.Sprintf(":%s", cfg.Port)
-	logger.Info().Str("address", addr).Msg("服务启动中")
+	logger.Info().Str("address", addr).Bool("tls", cfg.Server.TLS.Enabled).Msg("服务启动中")
 
 	serverErr := make(chan error, 1)
 	go func() {
@@ -69,6 +81,9 @@ func main() {
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			logger.Error().Err(err).Msg("优雅停机失败")
 		}
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Warn().Err(err).Msg("刷新追踪数据失败")
+		}
 		err := <-serverErr
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Error().Err(err).Msg("服务器关闭时出现错误")